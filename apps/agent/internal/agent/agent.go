@@ -1,12 +1,16 @@
 // Package agent wires the configured inputs to the Logify SDK: it fans events
 // from every source into a parse-and-ship pipeline and manages graceful
-// startup/shutdown and offset checkpointing.
+// startup/shutdown and offset checkpointing. Events are acknowledged to their
+// input only once delivered, or once written to the disk queue when one is
+// configured, so delivery is at least once.
 package agent
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"path/filepath"
 	"sync"
 	"time"
@@ -15,7 +19,9 @@ import (
 
 	"github.com/indalyadav56/logify/apps/agent/internal/config"
 	"github.com/indalyadav56/logify/apps/agent/internal/input"
+	"github.com/indalyadav56/logify/apps/agent/internal/metrics"
 	"github.com/indalyadav56/logify/apps/agent/internal/parse"
+	"github.com/indalyadav56/logify/apps/agent/internal/queue"
 	"github.com/indalyadav56/logify/apps/agent/internal/registry"
 )
 
@@ -23,6 +29,8 @@ const (
 	registryFlushInterval = 5 * time.Second
 	shutdownDrainTimeout  = 15 * time.Second
 	perSendTimeout        = 5 * time.Second
+	retryBaseDelay        = 1 * time.Second
+	retryMaxDelay         = 30 * time.Second
 )
 
 // Agent owns the running pipeline for one configuration.
@@ -32,6 +40,16 @@ type Agent struct {
 	reg    *registry.Registry
 	inputs []input.Input
 	log    *slog.Logger
+
+	// queue, when configured, sits between the inputs and the SDK: events
+	// are acknowledged once written to it and shipped from it.
+	queue   *queue.Queue
+	metrics *metrics.Registry
+
+	// inflight holds a slot per event shipped but not yet delivered (or
+	// dropped), bounding the events held for retries; inputs block once
+	// it is full.
+	inflight chan struct{}
 }
 
 // New constructs an Agent from a validated config: it builds the SDK client,
@@ -47,13 +65,44 @@ func New(cfg *config.Config, log *slog.Logger) (*Agent, error) {
 		return nil, err
 	}
 
-	a := &Agent{cfg: cfg, client: client, reg: reg, log: log}
+	a := &Agent{cfg: cfg, client: client, reg: reg, log: log, metrics: metrics.NewRegistry()}
+	if q := cfg.Delivery.Queue; q.Type == config.QueueDisk {
+		a.queue, err = queue.Open(queue.Options{
+			Dir:           q.Dir,
+			MaxSize:       int64(q.MaxSize),
+			SegmentSize:   int64(q.SegmentSize),
+			SegmentAge:    q.SegmentAge.Std(),
+			Overflow:      q.Overflow,
+			FSync:         q.FSync,
+			FSyncInterval: q.FSyncInterval.Std(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("open queue: %w", err)
+		}
+	}
+	a.registerMetrics()
 	if err := a.buildInputs(); err != nil {
 		return nil, err
 	}
 	return a, nil
 }
 
+func (a *Agent) registerMetrics() {
+	if a.queue == nil {
+		a.metrics.Gauge("logify_agent_queue_depth", "Logs waiting for delivery.",
+			func() float64 { return float64(len(a.inflight)) })
+		return
+	}
+	a.metrics.Gauge("logify_agent_queue_depth", "Logs waiting for delivery.",
+		func() float64 { return float64(a.queue.Stats().Depth) })
+	a.metrics.Gauge("logify_agent_queue_bytes", "Disk used by the queue, in bytes.",
+		func() float64 { return float64(a.queue.Stats().Bytes) })
+	a.metrics.Gauge("logify_agent_queue_segments", "Segment files of the queue.",
+		func() float64 { return float64(a.queue.Stats().Segments) })
+	a.metrics.Counter("logify_agent_queue_dropped_total", "Logs dropped because the queue was full.",
+		func() float64 { return float64(a.queue.Stats().Dropped) })
+}
+
 func newClient(cfg *config.Config, log *slog.Logger) (*logify.Client, error) {
 	opts := []logify.Option{
 		logify.WithBaseURL(cfg.Backend.URL),
@@ -99,7 +148,17 @@ func (a *Agent) buildInputs() error {
 		case "stdin":
 			a.inputs = append(a.inputs, input.NewStdinInput(in.Name, dec, nil))
 		case "journald":
-			a.inputs = append(a.inputs, input.NewJournaldInput(in.Name, in.Units, dec, cursorDir, a.log))
+			a.inputs = append(a.inputs, input.NewJournaldInput(in.Name, in.Units, dec, a.reg, cursorDir, a.log))
+		case "syslog":
+			a.inputs = append(a.inputs, input.NewSyslogInput(in, dec, a.log))
+		case "kubernetes":
+			a.inputs = append(a.inputs, input.NewKubernetesInput(in, dec, a.reg, a.log))
+		case "http":
+			a.inputs = append(a.inputs, input.NewHTTPInput(in, dec, a.log))
+		case "otlp":
+			a.inputs = append(a.inputs, input.NewOTLPInput(in, dec, a.log))
+		case "forward":
+			a.inputs = append(a.inputs, input.NewForwardInput(in, dec, a.log))
 		default:
 			return fmt.Errorf("unsupported input type %q", in.Type)
 		}
@@ -111,8 +170,18 @@ func (a *Agent) buildInputs() error {
 // cancelled, then drains buffered logs and flushes offsets before returning.
 func (a *Agent) Run(ctx context.Context) error {
 	events := make(chan input.Event, a.cfg.Delivery.BufferSize)
+	a.inflight = make(chan struct{}, a.cfg.Delivery.BufferSize)
 
-	// Pipeline consumers: parse each event and hand it to the SDK.
+	if addr := a.cfg.Metrics.Listen; addr != "" {
+		go func() {
+			if err := metrics.Serve(ctx, addr, a.metrics, a.log); err != nil {
+				a.log.Error("metrics server stopped", "err", err)
+			}
+		}()
+	}
+
+	// Pipeline consumers: parse each event and hand it to the SDK, or to
+	// the queue.
 	var consumers sync.WaitGroup
 	workers := a.cfg.Delivery.Workers
 	if workers < 2 {
@@ -122,10 +191,29 @@ func (a *Agent) Run(ctx context.Context) error {
 		consumers.Add(1)
 		go func() {
 			defer consumers.Done()
-			a.consume(events)
+			if a.queue != nil {
+				a.enqueue(ctx, events)
+			} else {
+				a.consume(ctx, events)
+			}
 		}()
 	}
 
+	// Shippers: hand queued records to the SDK. They outlive ctx until the
+	// consumers are done, then stop so what is left stays queued.
+	shipCtx, stopShipping := context.WithCancel(context.Background())
+	defer stopShipping()
+	var shippers sync.WaitGroup
+	if a.queue != nil {
+		for i := 0; i < workers; i++ {
+			shippers.Add(1)
+			go func() {
+				defer shippers.Done()
+				a.replay(shipCtx)
+			}()
+		}
+	}
+
 	// Periodic offset checkpointing.
 	flushStop := make(chan struct{})
 	var flushWg sync.WaitGroup
@@ -151,6 +239,8 @@ func (a *Agent) Run(ctx context.Context) error {
 	inputs.Wait() // returns once ctx is cancelled and every input has exited
 	close(events) // no more events will be produced
 	consumers.Wait()
+	stopShipping()
+	shippers.Wait()
 
 	close(flushStop)
 	flushWg.Wait()
@@ -161,23 +251,134 @@ func (a *Agent) Run(ctx context.Context) error {
 	if err := a.client.Close(drainCtx); err != nil {
 		a.log.Warn("drain incomplete", "err", err)
 	}
+	if a.queue != nil {
+		if err := a.queue.Close(); err != nil {
+			a.log.Warn("closing queue failed", "err", err)
+		}
+	}
 	if err := a.reg.Flush(); err != nil {
 		a.log.Warn("final registry flush failed", "err", err)
 	}
 	return nil
 }
 
-func (a *Agent) consume(events <-chan input.Event) {
+func (a *Agent) consume(ctx context.Context, events <-chan input.Event) {
+	for ev := range events {
+		select {
+		case a.inflight <- struct{}{}:
+		default:
+			// Every slot is held by an undelivered event: wait, unless the
+			// agent is stopping, in which case the event is read again
+			// after a restart.
+			select {
+			case a.inflight <- struct{}{}:
+			case <-ctx.Done():
+				continue
+			}
+		}
+		a.ship(ev, parse.ToEntry(ev), 0)
+	}
+}
+
+// enqueue writes each event to the disk queue and acknowledges it to its
+// input. An event the full queue refuses is dropped; one not written because
+// the agent is stopping is read again after a restart.
+func (a *Agent) enqueue(ctx context.Context, events <-chan input.Event) {
 	for ev := range events {
-		entry := parse.ToEntry(ev)
-		// Detached, bounded context so events still buffer into the SDK during
-		// shutdown without blocking forever on a full queue.
-		ctx, cancel := context.WithTimeout(context.Background(), perSendTimeout)
-		if err := a.client.Send(ctx, entry); err != nil {
+		err := a.queue.Push(ctx, queue.Record{Input: ev.Input, Entry: parse.ToEntry(ev)})
+		switch {
+		case err == nil:
+		case errors.Is(err, queue.ErrFull):
 			a.log.Debug("dropped event", "input", ev.Input, "err", err)
+		default:
+			if ctx.Err() == nil {
+				a.log.Warn("queueing event failed", "input", ev.Input, "err", err)
+			}
+			continue
+		}
+		if ev.Ack != nil {
+			ev.Ack()
+		}
+	}
+}
+
+// replay ships records from the disk queue, oldest first, until ctx is
+// cancelled. A record is acknowledged to the queue once delivered.
+func (a *Agent) replay(ctx context.Context) {
+	for {
+		select {
+		case a.inflight <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+		rec, ack, err := a.queue.Pop(ctx)
+		if err != nil {
+			<-a.inflight
+			if ctx.Err() != nil || errors.Is(err, queue.ErrClosed) {
+				return
+			}
+			a.log.Error("reading queue failed", "err", err)
+			select {
+			case <-time.After(retryBaseDelay):
+			case <-ctx.Done():
+				return
+			}
+			continue
 		}
-		cancel()
+		a.ship(input.Event{Input: rec.Input, Ack: ack}, rec.Entry, 0)
+	}
+}
+
+// ship hands an event's entry to the SDK. The outcome of the delivery comes
+// back to delivered, which acknowledges the event or retries.
+func (a *Agent) ship(ev input.Event, entry logify.Entry, attempt int) {
+	// Detached, bounded context so events still buffer into the SDK during
+	// shutdown without blocking forever on a full queue.
+	ctx, cancel := context.WithTimeout(context.Background(), perSendTimeout)
+	defer cancel()
+	err := a.client.SendWithAck(ctx, entry, func(err error) { a.delivered(ev, entry, attempt, err) })
+	if err != nil {
+		a.delivered(ev, entry, attempt, err)
+	}
+}
+
+// delivered acknowledges an event to its input once it is delivered, or
+// rejected in a way a retry cannot fix, and otherwise sends it again after a
+// backoff. Once the client is closed the event is left unacknowledged, for
+// the input to read again after a restart.
+func (a *Agent) delivered(ev input.Event, entry logify.Entry, attempt int, err error) {
+	switch {
+	case err == nil:
+	case errors.Is(err, logify.ErrClosed):
+		a.log.Debug("event left for replay", "input", ev.Input)
+		<-a.inflight
+		return
+	case permanent(err):
+		a.log.Warn("dropping rejected event", "input", ev.Input, "err", err)
+	default:
+		delay := min(retryBaseDelay<<min(attempt, 5), retryMaxDelay)
+		a.log.Debug("retrying event", "input", ev.Input, "attempt", attempt+1, "in", delay, "err", err)
+		time.AfterFunc(delay, func() { a.ship(ev, entry, attempt+1) })
+		return
+	}
+	<-a.inflight
+	if ev.Ack != nil {
+		ev.Ack()
+	}
+}
+
+// permanent reports whether the backend rejected an entry for what it is, so
+// sending it again would fail the same way. Throttling and timeouts are not.
+func permanent(err error) bool {
+	var apiErr *logify.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return false
 	}
+	return apiErr.StatusCode >= 400 && apiErr.StatusCode < 500
 }
 
 func (a *Agent) flushLoop(stop <-chan struct{}) {
@@ -5,7 +5,9 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
@@ -33,6 +35,53 @@ func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
 	return nil
 }
 
+// ByteSize is a size in bytes that (un)marshals from a string like "512MiB"
+// or "16MB", or a plain number of bytes.
+type ByteSize int64
+
+func (b *ByteSize) UnmarshalYAML(value *yaml.Node) error {
+	var s string
+	if err := value.Decode(&s); err != nil {
+		return err
+	}
+	n, err := ParseByteSize(s)
+	if err != nil {
+		return err
+	}
+	*b = ByteSize(n)
+	return nil
+}
+
+var byteUnits = []struct {
+	suffix string
+	mult   int64
+}{
+	{"KiB", 1 << 10}, {"MiB", 1 << 20}, {"GiB", 1 << 30},
+	{"KB", 1e3}, {"MB", 1e6}, {"GB", 1e9},
+	{"B", 1},
+}
+
+// ParseByteSize parses a size such as "512MiB", "16MB" or "4096". An empty
+// string is zero.
+func ParseByteSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	mult := int64(1)
+	for _, u := range byteUnits {
+		if strings.HasSuffix(s, u.suffix) {
+			s, mult = strings.TrimSpace(strings.TrimSuffix(s, u.suffix)), u.mult
+			break
+		}
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return n * mult, nil
+}
+
 // Config is the fully-parsed agent configuration.
 type Config struct {
 	Backend  Backend       `yaml:"backend"`
@@ -40,9 +89,18 @@ type Config struct {
 	Delivery Delivery      `yaml:"delivery"`
 	Registry string        `yaml:"registry_file"`
 	LogLevel string        `yaml:"log_level"`
+	Metrics  Metrics       `yaml:"metrics"`
 	Inputs   []InputConfig `yaml:"inputs"`
 }
 
+// Metrics exposes the agent's own metrics (queue depth, disk usage, ...) in
+// the Prometheus text format.
+type Metrics struct {
+	// Listen is the address of the /metrics endpoint, e.g. "127.0.0.1:9109";
+	// empty disables it.
+	Listen string `yaml:"listen"`
+}
+
 // Backend describes how to reach the Logify ingest API.
 type Backend struct {
 	URL        string   `yaml:"url"`
@@ -63,16 +121,45 @@ type Defaults struct {
 
 // Delivery controls the SDK's async shipping behaviour.
 type Delivery struct {
-	Async      bool `yaml:"async"`
-	BufferSize int  `yaml:"buffer_size"`
-	Workers    int  `yaml:"workers"`
+	Async      bool  `yaml:"async"`
+	BufferSize int   `yaml:"buffer_size"`
+	Workers    int   `yaml:"workers"`
+	Queue      Queue `yaml:"queue"`
+}
+
+// Queue types.
+const (
+	QueueMemory = "memory"
+	QueueDisk   = "disk"
+)
+
+// Queue selects where logs wait for delivery. The memory queue loses what it
+// holds when the agent stops or the backend is down for long; the disk queue
+// is a write-ahead log that survives both.
+type Queue struct {
+	Type string `yaml:"type"` // memory (default) | disk
+	Dir  string `yaml:"dir"`  // disk: defaults to "queue" beside the registry file
+
+	// MaxSize caps the disk used; Overflow decides what happens at the cap.
+	MaxSize  ByteSize `yaml:"max_size"`
+	Overflow string   `yaml:"overflow"` // block (default) | drop_oldest | drop_newest
+
+	// A segment file is closed and a new one started once it holds
+	// SegmentSize bytes or is SegmentAge old; delivered segments are deleted.
+	SegmentSize ByteSize `yaml:"segment_size"`
+	SegmentAge  Duration `yaml:"segment_age"`
+
+	// FSync is when writes are flushed to disk: always (every log), interval
+	// (default, every FSyncInterval) or never (left to the OS).
+	FSync         string   `yaml:"fsync"`
+	FSyncInterval Duration `yaml:"fsync_interval"`
 }
 
 // InputConfig is one log source. Type selects the reader; the remaining fields
 // are interpreted per-type (paths/json/multiline for "file", units for
 // "journald", etc.).
 type InputConfig struct {
-	Type string `yaml:"type"` // file | stdin | journald
+	Type string `yaml:"type"` // file | stdin | journald | syslog | kubernetes | http | otlp | forward
 	Name string `yaml:"name"`
 
 	// Per-input label overrides (fall back to Defaults when empty).
@@ -91,6 +178,39 @@ type InputConfig struct {
 
 	// journald
 	Units []string `yaml:"units"`
+
+	// syslog, and the address of the receivers (http, otlp and forward)
+	Protocol string     `yaml:"protocol"` // udp (default) | tcp | tls
+	Address  string     `yaml:"address"`  // listen address, default 127.0.0.1:514 (:6514 for tls)
+	TLS      *TLSConfig `yaml:"tls"`
+
+	// kubernetes (also uses paths, from_beginning and multiline)
+	Kubernetes Kubernetes `yaml:"kubernetes"`
+}
+
+// Kubernetes says where the kubernetes input looks up the pods whose
+// container logs it tails.
+type Kubernetes struct {
+	// Metadata is where pod labels and annotations come from: "api" (the
+	// API server), "kubelet" (the node's kubelet, sparing the API server) or
+	// "none".
+	Metadata string `yaml:"metadata"`
+	// URL of the API server or kubelet. Defaults to the in-cluster API
+	// server, or the kubelet at $NODE_IP (else 127.0.0.1) on port 10250.
+	URL                string   `yaml:"url"`
+	TokenFile          string   `yaml:"token_file"`
+	CAFile             string   `yaml:"ca_file"`
+	InsecureSkipVerify bool     `yaml:"insecure_skip_verify"` // kubelets often serve self-signed certificates
+	CacheTTL           Duration `yaml:"cache_ttl"`
+}
+
+// TLSConfig is the server side of a TLS listener.
+type TLSConfig struct {
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+	// ClientCAFile, when set, requires clients to present a certificate
+	// signed by one of its CAs.
+	ClientCAFile string `yaml:"client_ca_file"`
 }
 
 // Multiline joins continuation lines (e.g. stack traces) into one event,
@@ -148,11 +268,71 @@ func (c *Config) ApplyDefaults() {
 	if c.LogLevel == "" {
 		c.LogLevel = "info"
 	}
+	if q := &c.Delivery.Queue; q.Type == QueueDisk {
+		if q.Dir == "" {
+			q.Dir = filepath.Join(filepath.Dir(c.Registry), "queue")
+		}
+		if q.MaxSize == 0 {
+			q.MaxSize = 1 << 30
+		}
+		if q.Overflow == "" {
+			q.Overflow = "block"
+		}
+		if q.SegmentSize == 0 {
+			q.SegmentSize = 16 << 20
+		}
+		if q.SegmentAge == 0 {
+			q.SegmentAge = Duration(time.Hour)
+		}
+		if q.FSync == "" {
+			q.FSync = "interval"
+		}
+		if q.FSyncInterval == 0 {
+			q.FSyncInterval = Duration(time.Second)
+		}
+	}
 	for i := range c.Inputs {
 		in := &c.Inputs[i]
 		if in.Name == "" {
 			in.Name = fmt.Sprintf("%s-%d", in.Type, i)
 		}
+		if in.Type == "kubernetes" {
+			if len(in.Paths) == 0 {
+				in.Paths = []string{"/var/log/containers/*.log"}
+			}
+			k := &in.Kubernetes
+			if k.Metadata == "" {
+				k.Metadata = "api"
+			}
+			if k.TokenFile == "" {
+				k.TokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+			}
+			if k.CAFile == "" {
+				k.CAFile = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+			}
+			if k.CacheTTL == 0 {
+				k.CacheTTL = Duration(5 * time.Minute)
+			}
+		}
+		if in.Type == "syslog" && in.Protocol == "" {
+			in.Protocol = "udp"
+		}
+		if in.Address == "" {
+			// Receivers listen on loopback only unless told otherwise.
+			switch in.Type {
+			case "http":
+				in.Address = "127.0.0.1:8088"
+			case "otlp":
+				in.Address = "127.0.0.1:4318"
+			case "forward":
+				in.Address = "127.0.0.1:24224"
+			case "syslog":
+				in.Address = "127.0.0.1:514"
+				if in.Protocol == "tls" {
+					in.Address = "127.0.0.1:6514"
+				}
+			}
+		}
 		if in.Multiline != nil {
 			if in.Multiline.Match == "" {
 				in.Multiline.Match = "after"
@@ -172,6 +352,9 @@ func (c *Config) Validate() error {
 	if len(c.Inputs) == 0 {
 		return fmt.Errorf("at least one input is required")
 	}
+	if err := c.Delivery.Queue.validate(); err != nil {
+		return err
+	}
 	seen := map[string]bool{}
 	for i, in := range c.Inputs {
 		switch in.Type {
@@ -179,10 +362,26 @@ func (c *Config) Validate() error {
 			if len(in.Paths) == 0 {
 				return fmt.Errorf("input %q (#%d): type file requires at least one path", in.Name, i)
 			}
-		case "stdin", "journald":
+		case "stdin", "journald", "http", "otlp", "forward":
 			// no required fields
+		case "syslog":
+			switch in.Protocol {
+			case "udp", "tcp":
+			case "tls":
+				if in.TLS == nil || in.TLS.CertFile == "" || in.TLS.KeyFile == "" {
+					return fmt.Errorf("input %q (#%d): protocol tls requires tls.cert_file and tls.key_file", in.Name, i)
+				}
+			default:
+				return fmt.Errorf("input %q (#%d): syslog protocol must be udp, tcp or tls", in.Name, i)
+			}
+		case "kubernetes":
+			switch in.Kubernetes.Metadata {
+			case "api", "kubelet", "none":
+			default:
+				return fmt.Errorf("input %q (#%d): kubernetes.metadata must be api, kubelet or none", in.Name, i)
+			}
 		case "":
-			return fmt.Errorf("input #%d: type is required (file|stdin|journald)", i)
+			return fmt.Errorf("input #%d: type is required (file|stdin|journald|syslog|kubernetes|http|otlp|forward)", i)
 		default:
 			return fmt.Errorf("input %q (#%d): unknown type %q", in.Name, i, in.Type)
 		}
@@ -197,6 +396,30 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+func (q Queue) validate() error {
+	switch q.Type {
+	case "", QueueMemory:
+		return nil
+	case QueueDisk:
+	default:
+		return fmt.Errorf("delivery.queue.type must be %q or %q", QueueMemory, QueueDisk)
+	}
+	switch q.Overflow {
+	case "block", "drop_oldest", "drop_newest":
+	default:
+		return fmt.Errorf("delivery.queue.overflow must be block, drop_oldest or drop_newest")
+	}
+	switch q.FSync {
+	case "always", "interval", "never":
+	default:
+		return fmt.Errorf("delivery.queue.fsync must be always, interval or never")
+	}
+	if q.SegmentSize <= 0 || q.SegmentSize > q.MaxSize {
+		return fmt.Errorf("delivery.queue.segment_size must be positive and at most max_size")
+	}
+	return nil
+}
+
 // DefaultConfigPath is the conventional location of the agent config per-OS.
 func DefaultConfigPath() string {
 	if runtime.GOOS == "darwin" {
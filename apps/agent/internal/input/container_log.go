@@ -0,0 +1,97 @@
+package input
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// maxContainerLine bounds a line reassembled from partial lines; the rest of
+// a longer one becomes the next event.
+const maxContainerLine = 1024 * 1024
+
+// containerLog decodes the lines a container runtime writes for a container:
+// CRI ("<time> <stream> <P|F> <log>") or Docker's json-file
+// ({"log": ..., "stream": ..., "time": ...}). Runtimes split long lines, CRI
+// marking all but the last piece P and Docker leaving the newline off; the
+// pieces are joined back, per stream as stdout and stderr interleave.
+type containerLog struct {
+	partial map[string]*partialLine
+}
+
+type partialLine struct {
+	text  strings.Builder
+	start int64 // file offset of the first piece
+	at    time.Time
+}
+
+func newContainerLog() *containerLog {
+	return &containerLog{partial: map[string]*partialLine{}}
+}
+
+// decode returns the log line of the file line starting at offset start,
+// with the time it was logged. It reports false for a piece of a split line,
+// held until the line is complete, and for lines in neither format.
+func (c *containerLog) decode(line string, start int64) (string, time.Time, bool) {
+	at, stream, text, complete, ok := parseContainerLine(line)
+	if !ok {
+		return "", time.Time{}, false
+	}
+	p := c.partial[stream]
+	if p == nil {
+		if complete {
+			return text, at, true
+		}
+		p = &partialLine{start: start, at: at}
+		c.partial[stream] = p
+	}
+	p.text.WriteString(text)
+	if !complete && p.text.Len() < maxContainerLine {
+		return "", time.Time{}, false
+	}
+	delete(c.partial, stream)
+	return p.text.String(), p.at, true
+}
+
+// commitOffset caps the offset an event may commit at the start of any line
+// still being reassembled, so a restart reads it again whole.
+func (c *containerLog) commitOffset(end int64) int64 {
+	for _, p := range c.partial {
+		end = min(end, p.start)
+	}
+	return end
+}
+
+func (c *containerLog) reset() {
+	clear(c.partial)
+}
+
+func parseContainerLine(line string) (at time.Time, stream, text string, complete, ok bool) {
+	if strings.HasPrefix(line, "{") {
+		var rec struct {
+			Log    string    `json:"log"`
+			Stream string    `json:"stream"`
+			Time   time.Time `json:"time"`
+		}
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			return time.Time{}, "", "", false, false
+		}
+		text, complete = strings.CutSuffix(rec.Log, "\n")
+		return rec.Time, rec.Stream, text, complete, true
+	}
+
+	parts := strings.SplitN(line, " ", 4)
+	if len(parts) < 3 {
+		return time.Time{}, "", "", false, false
+	}
+	at, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return time.Time{}, "", "", false, false
+	}
+	if len(parts) == 4 {
+		text = parts[3]
+	}
+	// Tags are colon-separated; the first is P (partial) or F (full).
+	tag, _, _ := strings.Cut(parts[2], ":")
+	return at, parts[1], text, tag != "P", true
+}
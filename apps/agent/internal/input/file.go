@@ -24,7 +24,8 @@ const (
 
 // FileInput tails one or more glob patterns, following appends and surviving
 // log rotation. Offsets are checkpointed in the shared registry so restarts
-// resume in place.
+// resume in place; a file's offset only moves past lines whose events were
+// delivered, so a crash re-ships rather than loses them.
 type FileInput struct {
 	name       string
 	patterns   []string
@@ -35,6 +36,14 @@ type FileInput struct {
 	log        *slog.Logger
 
 	active map[string]*tailedFile
+
+	// setup, when set, adapts each newly opened file, e.g. to decode the
+	// container runtime's framing or decorate it per pod.
+	setup func(tf *tailedFile)
+	// newFilesFromStart reads files that appear after the first scan from
+	// their start, as they are new rather than merely first seen.
+	newFilesFromStart bool
+	scanned           bool
 }
 
 // NewFileInput builds a file tailer from its config.
@@ -77,6 +86,7 @@ func (f *FileInput) Run(ctx context.Context, out chan<- Event) error {
 					}
 					f.log.Warn("read error, dropping file until rediscovered", "path", path, "err", err)
 					tf.close()
+					tf.tracker.Close()
 					delete(f.active, path)
 				}
 			}
@@ -88,6 +98,7 @@ func (f *FileInput) Run(ctx context.Context, out chan<- Event) error {
 // scan discovers files then immediately reads any backlog.
 func (f *FileInput) scan(ctx context.Context, out chan<- Event) {
 	f.discover()
+	f.scanned = true
 	for _, tf := range f.active {
 		_ = f.read(ctx, tf, out)
 	}
@@ -133,7 +144,7 @@ func (f *FileInput) open(path string, info os.FileInfo) (*tailedFile, error) {
 	var offset int64
 	if st, ok := f.reg.Get(path); ok && st.Inode == ino && st.Device == dev && st.Offset <= info.Size() {
 		offset = st.Offset // resume in place
-	} else if !f.fromBeginning {
+	} else if !f.fromBeginning && !(f.newFilesFromStart && f.scanned) {
 		offset = info.Size() // new file: skip existing content, follow appends
 	}
 
@@ -152,7 +163,10 @@ func (f *FileInput) open(path string, info os.FileInfo) (*tailedFile, error) {
 	if f.ml != nil {
 		tf.ml = newMultiline(f.ml)
 	}
-	f.reg.Set(path, registry.State{Offset: offset, Inode: ino, Device: dev})
+	if f.setup != nil {
+		f.setup(tf)
+	}
+	f.track(tf)
 	return tf, nil
 }
 
@@ -171,12 +185,14 @@ func (f *FileInput) read(ctx context.Context, tf *tailedFile, out chan<- Event)
 		if err := tf.reopen(ino, dev); err != nil {
 			return err
 		}
+		f.track(tf)
 	} else if info.Size() < tf.offset {
 		// Truncated in place. Restart at 0.
 		f.log.Info("file truncated, rewinding", "path", tf.path)
 		if err := tf.rewind(); err != nil {
 			return err
 		}
+		f.track(tf)
 	}
 
 	for {
@@ -199,21 +215,36 @@ func (f *FileInput) read(ctx context.Context, tf *tailedFile, out chan<- Event)
 		}
 		// Advance by the full bytes of the completed line. Any partial that was
 		// stashed on a previous pass was never committed, so it's counted here.
+		start := tf.offset
 		tf.offset += int64(len(line))
 
 		text := trimLineEnding(string(line))
+		var at time.Time
+		if tf.container != nil {
+			var ok bool
+			if text, at, ok = tf.container.decode(text, start); !ok {
+				continue // the start of a split line, or not a container log line
+			}
+		}
 		if tf.ml != nil {
-			if joined, ok := tf.ml.push(text); ok {
-				if err := f.emit(ctx, out, joined); err != nil {
+			// The joined event takes the time of its first line.
+			first := tf.mlStart
+			if len(tf.ml.buf) == 0 {
+				first = at
+			}
+			joined, end, ok := tf.ml.push(text, tf.offset)
+			if len(tf.ml.buf) == 1 {
+				tf.mlStart = at
+			}
+			if ok {
+				if err := f.emit(ctx, out, tf, joined, first, end); err != nil {
 					return err
 				}
 			}
-		} else if err := f.emit(ctx, out, text); err != nil {
+		} else if err := f.emit(ctx, out, tf, text, at, tf.offset); err != nil {
 			return err
 		}
 	}
-
-	f.reg.Set(tf.path, registry.State{Offset: tf.offset, Inode: tf.inode, Device: tf.device})
 	return nil
 }
 
@@ -224,14 +255,26 @@ func (f *FileInput) flushIdleMultiline(ctx context.Context, out chan<- Event) {
 		if tf.ml == nil {
 			continue
 		}
-		if joined, ok := tf.ml.flushIfIdle(multilineFlushAfter); ok {
-			_ = f.emit(ctx, out, joined)
+		if joined, end, ok := tf.ml.flushIfIdle(multilineFlushAfter); ok {
+			_ = f.emit(ctx, out, tf, joined, tf.mlStart, end)
 		}
 	}
 }
 
-func (f *FileInput) emit(ctx context.Context, out chan<- Event, line string) error {
-	ev := Event{Input: f.name, Line: line, Decoration: f.dec}
+// emit sends the event of a line (or joined lines) of tf that ends at offset
+// end; once it is delivered, the file's offset may advance to end. at is the
+// time the line was logged, if the file records it.
+func (f *FileInput) emit(ctx context.Context, out chan<- Event, tf *tailedFile, line string, at time.Time, end int64) error {
+	dec := f.dec
+	if tf.dec != nil {
+		dec = *tf.dec
+	}
+	if tf.container != nil {
+		// Never past the start of a line still being reassembled.
+		end = tf.container.commitOffset(end)
+	}
+	ack := tf.tracker.Add(registry.State{Offset: end, Inode: tf.inode, Device: tf.device})
+	ev := Event{Input: f.name, Line: line, Time: at, Decoration: dec, Ack: ack}
 	select {
 	case out <- ev:
 		return nil
@@ -240,6 +283,17 @@ func (f *FileInput) emit(ctx context.Context, out chan<- Event, line string) err
 	}
 }
 
+// track starts checkpointing tf from its current offset. Events of what the
+// path held before, still in flight, no longer move its offset.
+func (f *FileInput) track(tf *tailedFile) {
+	if tf.tracker != nil {
+		tf.tracker.Close()
+	}
+	tf.tracker = f.reg.Track(tf.path, registry.State{Offset: tf.offset, Inode: tf.inode, Device: tf.device})
+}
+
+// closeAll closes every file on shutdown. Their trackers stay open: events
+// still draining from the pipeline may yet be delivered and committed.
 func (f *FileInput) closeAll() {
 	for _, tf := range f.active {
 		tf.close()
@@ -257,6 +311,11 @@ type tailedFile struct {
 	device  uint64
 	partial []byte
 	ml      *multilineBuf
+	tracker *registry.Tracker
+
+	mlStart   time.Time     // time of the first line in ml
+	dec       *Decoration   // overrides the input's decoration
+	container *containerLog // decodes container runtime log lines
 }
 
 func (tf *tailedFile) reopen(ino, dev uint64) error {
@@ -271,6 +330,9 @@ func (tf *tailedFile) reopen(ino, dev uint64) error {
 	tf.inode = ino
 	tf.device = dev
 	tf.partial = nil
+	if tf.container != nil {
+		tf.container.reset()
+	}
 	return nil
 }
 
@@ -281,6 +343,9 @@ func (tf *tailedFile) rewind() error {
 	tf.reader.Reset(tf.fh)
 	tf.offset = 0
 	tf.partial = nil
+	if tf.container != nil {
+		tf.container.reset()
+	}
 	return nil
 }
 
@@ -319,6 +384,7 @@ type multilineBuf struct {
 	after    bool // match == "after"
 	maxLines int
 	buf      []string
+	end      int64 // file offset just past the last buffered line
 	lastAt   time.Time
 	hasTime  bool
 }
@@ -332,10 +398,12 @@ func newMultiline(m *config.Multiline) *multilineBuf {
 	return &multilineBuf{re: re, negate: m.Negate, after: m.Match == "after", maxLines: m.MaxLines}
 }
 
-// push feeds a line and returns a joined event when one is complete.
-func (m *multilineBuf) push(line string) (string, bool) {
+// push feeds a line, ending at file offset end, and returns a joined event,
+// with the offset it ends at, when one is complete.
+func (m *multilineBuf) push(line string, end int64) (string, int64, bool) {
 	matches := m.re.MatchString(line) != m.negate
 	var out string
+	var outEnd int64
 	var ready bool
 
 	if m.after {
@@ -344,7 +412,7 @@ func (m *multilineBuf) push(line string) (string, bool) {
 			m.buf = append(m.buf, line)
 		} else {
 			if len(m.buf) > 0 {
-				out, ready = m.join(), true
+				out, outEnd, ready = m.join(), m.end, true
 			}
 			m.buf = []string{line}
 		}
@@ -352,27 +420,28 @@ func (m *multilineBuf) push(line string) (string, bool) {
 		// match == before: matching lines attach to the following line.
 		m.buf = append(m.buf, line)
 		if !matches {
-			out, ready = m.join(), true
+			out, outEnd, ready = m.join(), end, true
 		}
 	}
 
+	m.end = end
 	m.lastAt = time.Now()
 	m.hasTime = true
 	if !ready && len(m.buf) >= m.maxLines {
-		return m.join(), true
+		return m.join(), end, true
 	}
-	return out, ready
+	return out, outEnd, ready
 }
 
 // flushIfIdle emits a pending buffer that has been quiet for at least d.
-func (m *multilineBuf) flushIfIdle(d time.Duration) (string, bool) {
+func (m *multilineBuf) flushIfIdle(d time.Duration) (string, int64, bool) {
 	if len(m.buf) == 0 || !m.hasTime {
-		return "", false
+		return "", 0, false
 	}
 	if time.Since(m.lastAt) < d {
-		return "", false
+		return "", 0, false
 	}
-	return m.join(), true
+	return m.join(), m.end, true
 }
 
 func (m *multilineBuf) join() string {
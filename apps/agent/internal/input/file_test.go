@@ -0,0 +1,83 @@
+package input
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/indalyadav56/logify/apps/agent/internal/config"
+	"github.com/indalyadav56/logify/apps/agent/internal/registry"
+)
+
+func TestFileInputCommitsDeliveredLinesOnly(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	lines := "first\nsecond\nthird\n"
+	if err := os.WriteFile(path, []byte(lines), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	reg, err := registry.Open(filepath.Join(dir, "registry.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	in := NewFileInput(config.InputConfig{Name: "app", Paths: []string{path}, FromBeginning: true},
+		Decoration{}, reg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx, cancel := context.WithCancel(context.Background())
+	out := make(chan Event, 3)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		in.Run(ctx, out)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	var events []Event
+	for len(events) < 3 {
+		select {
+		case ev := <-out:
+			events = append(events, ev)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("got %d events, want 3", len(events))
+		}
+	}
+	offset := func() int64 {
+		s, _ := reg.Get(path)
+		return s.Offset
+	}
+
+	// Lines read but not delivered are not checkpointed.
+	events[2].Ack()
+	if got := offset(); got != 0 {
+		t.Fatalf("offset = %d with the first lines undelivered, want 0", got)
+	}
+	events[0].Ack()
+	if got := offset(); got != int64(len("first\n")) {
+		t.Fatalf("offset = %d, want the end of the first line", got)
+	}
+	events[1].Ack()
+	if got := offset(); got != int64(len(lines)) {
+		t.Fatalf("offset = %d, want the end of the file", got)
+	}
+}
+
+func TestMultilineEndsAtLastJoinedLine(t *testing.T) {
+	m := newMultiline(&config.Multiline{Pattern: `^\s`, Match: "after", MaxLines: 500})
+	if _, _, ok := m.push("panic: boom", 12); ok {
+		t.Fatal("first line emitted before its continuation")
+	}
+	if _, _, ok := m.push("\tat main.go:1", 26); ok {
+		t.Fatal("continuation emitted on its own")
+	}
+	joined, end, ok := m.push("next record", 38)
+	if !ok || joined != "panic: boom\n\tat main.go:1" || end != 26 {
+		t.Fatalf("got (%q, %d, %v), want the stack trace ending at 26", joined, end, ok)
+	}
+}
@@ -0,0 +1,237 @@
+package input
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"sync"
+	"sync/atomic"
+
+	"github.com/indalyadav56/logify/apps/agent/internal/config"
+)
+
+// maxForwardMessage bounds one Forward protocol message, after inflating.
+const maxForwardMessage = 16 << 20
+
+// ForwardInput speaks the Fluent Forward protocol over TCP, so Fluentd,
+// Fluent Bit and the Fluent logger libraries can send to the agent. It takes
+// the Message, Forward and (compressed) PackedForward modes. Each record's
+// fields become the log's, with its tag as fluent_tag. When a sender asks for
+// acknowledgements (require_ack_response), a chunk is acknowledged once the
+// agent has delivered or queued every one of its events, so a sender retries
+// what was lost. Shared-key authentication is not supported.
+type ForwardInput struct {
+	name    string
+	address string
+	dec     Decoration
+	log     *slog.Logger
+}
+
+// NewForwardInput builds a Fluent Forward server from its input config.
+func NewForwardInput(in config.InputConfig, dec Decoration, log *slog.Logger) *ForwardInput {
+	return &ForwardInput{name: in.Name, address: in.Address, dec: dec, log: log.With("input", in.Name)}
+}
+
+func (f *ForwardInput) Name() string { return f.name }
+
+func (f *ForwardInput) Run(ctx context.Context, out chan<- Event) error {
+	ln, err := net.Listen("tcp", f.address)
+	if err != nil {
+		return err
+	}
+	return serveConns(ctx, ln, f.log, func(conn net.Conn) error {
+		return f.serve(ctx, conn, out)
+	})
+}
+
+// serve reads messages from a connection until it ends.
+func (f *ForwardInput) serve(ctx context.Context, conn io.ReadWriter, out chan<- Event) error {
+	d := newMsgpackDecoder(conn)
+	var writeMu sync.Mutex // acknowledgements are written as events are acked
+	for {
+		d.left = maxForwardMessage
+		msg, err := d.value()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		events, chunk, err := f.decodeMessage(msg)
+		if err != nil {
+			return err
+		}
+		if chunk != "" {
+			ack := appendMsgpackString(append([]byte{0x81}, 0xa3, 'a', 'c', 'k'), chunk)
+			writeAck := func() {
+				writeMu.Lock()
+				defer writeMu.Unlock()
+				if _, err := conn.Write(ack); err != nil {
+					f.log.Debug("acknowledging chunk failed", "chunk", chunk, "err", err)
+				}
+			}
+			if len(events) == 0 {
+				writeAck()
+			}
+			// Acks run on the output's goroutine, which a slow sender must
+			// not hold up.
+			ackAfterAll(events, func() { go writeAck() })
+		}
+		if err := sendAll(ctx, nil, out, events); err != nil {
+			return nil
+		}
+	}
+}
+
+// ackAfterAll sets the Ack of every event so that fn runs once all of them
+// have been acknowledged.
+func ackAfterAll(events []Event, fn func()) {
+	var left atomic.Int64
+	left.Store(int64(len(events)))
+	for i := range events {
+		events[i].Ack = func() {
+			if left.Add(-1) == 0 {
+				fn()
+			}
+		}
+	}
+}
+
+// decodeMessage turns one message, [tag, time, record, option?],
+// [tag, [[time, record], ...], option?] or [tag, packed entries, option?],
+// into events. It also returns the chunk to acknowledge, if any.
+func (f *ForwardInput) decodeMessage(msg any) ([]Event, string, error) {
+	parts, ok := msg.([]any)
+	if !ok || len(parts) < 2 {
+		return nil, "", errors.New("forward message is not an array of at least 2 items")
+	}
+	tag, ok := parts[0].(string)
+	if !ok {
+		return nil, "", errors.New("forward message has no tag")
+	}
+
+	var (
+		events []Event
+		option map[string]any
+		err    error
+	)
+	optionAt := func(i int) {
+		if len(parts) > i {
+			option, _ = parts[i].(map[string]any)
+		}
+	}
+	switch entries := parts[1].(type) {
+	case []any: // Forward
+		optionAt(2)
+		for _, entry := range entries {
+			pair, ok := entry.([]any)
+			if !ok || len(pair) < 2 {
+				return nil, "", errors.New("forward entry is not a [time, record] pair")
+			}
+			ev, err := f.event(tag, pair[0], pair[1])
+			if err != nil {
+				return nil, "", err
+			}
+			events = append(events, ev)
+		}
+	case []byte, string: // PackedForward
+		optionAt(2)
+		events, err = f.decodePacked(tag, entries, option)
+	default: // Message
+		if len(parts) < 3 {
+			return nil, "", errors.New("forward message has no record")
+		}
+		optionAt(3)
+		var ev Event
+		ev, err = f.event(tag, parts[1], parts[2])
+		events = []Event{ev}
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	chunk, _ := option["chunk"].(string)
+	return events, chunk, nil
+}
+
+// decodePacked decodes the entries of a PackedForward message: [time, record]
+// pairs concatenated, gzipped when the option says so.
+func (f *ForwardInput) decodePacked(tag string, packed any, option map[string]any) ([]Event, error) {
+	var r io.Reader
+	switch p := packed.(type) {
+	case []byte:
+		r = bytes.NewReader(p)
+	case string:
+		r = bytes.NewReader([]byte(p))
+	}
+	if option["compressed"] == "gzip" {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	d := newMsgpackDecoder(r)
+	d.left = maxForwardMessage
+	var events []Event
+	for d.more() {
+		entry, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		pair, ok := entry.([]any)
+		if !ok || len(pair) < 2 {
+			return nil, errors.New("packed entry is not a [time, record] pair")
+		}
+		ev, err := f.event(tag, pair[0], pair[1])
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, ev)
+	}
+	return events, nil
+}
+
+// event builds the event for one record.
+func (f *ForwardInput) event(tag string, at, record any) (Event, error) {
+	rec, ok := record.(map[string]any)
+	if !ok {
+		return Event{}, fmt.Errorf("record is a %T, not a map", record)
+	}
+	t, ok := msgpackTime(at)
+	if !ok {
+		return Event{}, fmt.Errorf("invalid event time %v", at)
+	}
+
+	fields := make(map[string]any, len(rec)+1)
+	for k, v := range rec {
+		// Older senders write strings as raw bytes.
+		if b, ok := v.([]byte); ok {
+			v = string(b)
+		}
+		fields[k] = v
+	}
+	line, _ := firstOf(fields, "message", "log", "msg").(string)
+	if line == "" {
+		b, _ := json.Marshal(fields)
+		line = string(b)
+	}
+	fields["fluent_tag"] = tag
+	return Event{Input: f.name, Line: line, Time: t, Fields: fields, Decoration: f.dec}, nil
+}
+
+func firstOf(m map[string]any, keys ...string) any {
+	for _, k := range keys {
+		if v, ok := m[k]; ok {
+			return v
+		}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package input
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/indalyadav56/logify/apps/agent/internal/config"
+)
+
+// Msgpack encoding helpers for building messages by hand.
+func mpArray(items ...[]byte) []byte {
+	return append([]byte{0xdc, 0, byte(len(items))}, bytes.Join(items, nil)...)
+}
+
+func mpMap(kvs ...[]byte) []byte {
+	return append([]byte{0xde, 0, byte(len(kvs) / 2)}, bytes.Join(kvs, nil)...)
+}
+
+func mpStr(s string) []byte { return appendMsgpackString(nil, s) }
+
+func mpBin(b []byte) []byte {
+	return append(binary.BigEndian.AppendUint32([]byte{0xc6}, uint32(len(b))), b...)
+}
+
+func mpInt(v int64) []byte { return binary.BigEndian.AppendUint64([]byte{0xd3}, uint64(v)) }
+
+func mpEventTime(t time.Time) []byte {
+	b := []byte{0xd7, 0}
+	b = binary.BigEndian.AppendUint32(b, uint32(t.Unix()))
+	return binary.BigEndian.AppendUint32(b, uint32(t.Nanosecond()))
+}
+
+func TestForwardInputModes(t *testing.T) {
+	t1 := time.Date(2026, 10, 19, 10, 0, 0, 250, time.UTC)
+	record := func(msg string) []byte {
+		return mpMap(mpStr("log"), mpStr(msg), mpStr("stream"), mpBin([]byte("stderr")))
+	}
+
+	var packed bytes.Buffer
+	gz := gzip.NewWriter(&packed)
+	gz.Write(mpArray(mpInt(t1.Unix()), record("packed 1")))
+	gz.Write(mpArray(mpEventTime(t1), record("packed 2")))
+	gz.Close()
+
+	stream := bytes.Join([][]byte{
+		mpArray(mpStr("app.web"), mpEventTime(t1), record("message mode")),
+		mpArray(mpStr("app.web"), mpArray(mpArray(mpInt(t1.Unix()), record("forward mode"))),
+			mpMap(mpStr("chunk"), mpStr("chunk-1"))),
+		mpArray(mpStr("app.db"), mpBin(packed.Bytes()),
+			mpMap(mpStr("compressed"), mpStr("gzip"), mpStr("chunk"), mpStr("chunk-2"))),
+	}, nil)
+
+	f := NewForwardInput(config.InputConfig{Name: "forward"}, Decoration{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	out := make(chan Event, 8)
+	server, client := net.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- f.serve(context.Background(), server, out)
+		server.Close()
+	}()
+
+	go func() {
+		client.Write(stream)
+	}()
+	var got []Event
+	for len(got) < 4 {
+		select {
+		case ev := <-out:
+			got = append(got, ev)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("got %d events, want 4", len(got))
+		}
+	}
+
+	// Nothing is acknowledged until the pipeline acks the chunk's events.
+	client.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+	if n, err := client.Read(make([]byte, 1)); err == nil {
+		t.Fatalf("read %d bytes of an ack before the events were delivered", n)
+	}
+	if got[0].Ack != nil {
+		t.Error("an event the sender wants no ack of carries an Ack")
+	}
+	for i, chunk := range []string{"chunk-1", "chunk-2"} {
+		if i == 0 {
+			got[1].Ack()
+		} else {
+			got[3].Ack()
+			got[2].Ack()
+		}
+		want := append([]byte{0x81}, append(mpStr("ack"), mpStr(chunk)...)...)
+		buf := make([]byte, len(want))
+		client.SetReadDeadline(time.Now().Add(5 * time.Second))
+		if _, err := io.ReadFull(client, buf); err != nil {
+			t.Fatalf("reading ack for %s: %v", chunk, err)
+		}
+		if !bytes.Equal(buf, want) {
+			t.Fatalf("ack = %x, want %x", buf, want)
+		}
+	}
+	client.Close()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"message mode", "forward mode", "packed 1", "packed 2"}
+	if len(got) != len(want) {
+		t.Fatalf("got %d events, want %d", len(got), len(want))
+	}
+	for i, ev := range got {
+		if ev.Line != want[i] || ev.Fields["log"] != want[i] || ev.Fields["stream"] != "stderr" {
+			t.Errorf("event %d = %q, fields %v", i, ev.Line, ev.Fields)
+		}
+	}
+	if got[0].Fields["fluent_tag"] != "app.web" || got[3].Fields["fluent_tag"] != "app.db" {
+		t.Errorf("tags = %v, %v", got[0].Fields["fluent_tag"], got[3].Fields["fluent_tag"])
+	}
+	if !got[0].Time.Equal(t1) || !got[1].Time.Equal(t1.Truncate(time.Second)) {
+		t.Errorf("times = %v, %v", got[0].Time, got[1].Time)
+	}
+}
+
+func TestMsgpackDecoderRefusesHugeLengths(t *testing.T) {
+	d := newMsgpackDecoder(bytes.NewReader([]byte{0xdd, 0xff, 0xff, 0xff, 0xff}))
+	d.left = maxForwardMessage
+	if _, err := d.value(); err != errMsgpackTooLarge {
+		t.Fatalf("err = %v, want %v", err, errMsgpackTooLarge)
+	}
+}
@@ -0,0 +1,134 @@
+package input
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/indalyadav56/logify/apps/agent/internal/config"
+)
+
+// HTTPInput receives logs from apps on the host in Logify's own ingest
+// format, so an app or SDK can point at the agent instead of the backend:
+// POST /v1/logs takes one log, POST /v1/logs/batch an array of them (either
+// path takes either). The agent adds its API key when shipping, so apps need
+// none. A 202 means the logs were handed to the agent, not yet delivered.
+type HTTPInput struct {
+	name    string
+	address string
+	dec     Decoration
+	log     *slog.Logger
+}
+
+// NewHTTPInput builds a Logify JSON receiver from its input config.
+func NewHTTPInput(in config.InputConfig, dec Decoration, log *slog.Logger) *HTTPInput {
+	return &HTTPInput{name: in.Name, address: in.Address, dec: dec, log: log.With("input", in.Name)}
+}
+
+func (h *HTTPInput) Name() string { return h.name }
+
+func (h *HTTPInput) Run(ctx context.Context, out chan<- Event) error {
+	mux := http.NewServeMux()
+	handler := h.handle(ctx, out)
+	mux.Handle("POST /v1/logs", handler)
+	mux.Handle("POST /v1/logs/batch", handler)
+	return serveHTTP(ctx, h.address, mux, h.log)
+}
+
+// logEntry mirrors the backend's CreateLogRequest.
+type logEntry struct {
+	ProjectID   string            `json:"project_id"`
+	Level       string            `json:"level"`
+	Timestamp   string            `json:"timestamp"`
+	Service     string            `json:"service"`
+	Namespace   string            `json:"service_namespace"`
+	Hostname    string            `json:"hostname"`
+	Environment string            `json:"environment"`
+	Message     string            `json:"message"`
+	TraceID     string            `json:"trace_id"`
+	SpanID      string            `json:"span_id"`
+	RequestID   string            `json:"request_id"`
+	UserID      string            `json:"user_id"`
+	Source      string            `json:"source"`
+	Tags        map[string]string `json:"tags"`
+	Metadata    map[string]any    `json:"metadata"`
+}
+
+func (h *HTTPInput) handle(ctx context.Context, out chan<- Event) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := readBody(w, r)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+			return
+		}
+		var entries []logEntry
+		body = bytes.TrimSpace(body)
+		if len(body) > 0 && body[0] == '[' {
+			err = json.Unmarshal(body, &entries)
+		} else {
+			entries = make([]logEntry, 1)
+			err = json.Unmarshal(body, &entries[0])
+		}
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid JSON: " + err.Error()})
+			return
+		}
+
+		events := make([]Event, 0, len(entries))
+		for _, e := range entries {
+			events = append(events, h.toEvent(e))
+		}
+		if err := sendAll(r.Context(), ctx.Done(), out, events); err != nil {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]any{"error": "agent is shutting down"})
+			return
+		}
+		writeJSON(w, http.StatusAccepted, map[string]any{"accepted": len(events)})
+	})
+}
+
+// toEvent converts an entry. Like the backend, a timestamp that is not
+// RFC 3339 is ignored and the log takes the time it arrived.
+func (h *HTTPInput) toEvent(e logEntry) Event {
+	ev := Event{
+		Input:     h.name,
+		Line:      e.Message,
+		Hostname:  e.Hostname,
+		TraceID:   e.TraceID,
+		SpanID:    e.SpanID,
+		RequestID: e.RequestID,
+		UserID:    e.UserID,
+		Decoration: h.dec.with(labels{
+			Service:     e.Service,
+			Namespace:   e.Namespace,
+			Environment: e.Environment,
+			Source:      e.Source,
+			ProjectID:   e.ProjectID,
+			Tags:        e.Tags,
+		}),
+	}
+	if t, err := time.Parse(time.RFC3339Nano, e.Timestamp); err == nil {
+		ev.Time = t
+	}
+	if e.Level != "" || len(e.Metadata) > 0 {
+		ev.Fields = make(map[string]any, len(e.Metadata)+2)
+		for k, v := range e.Metadata {
+			ev.Fields[k] = v
+		}
+		if e.Level != "" {
+			ev.Fields["level"] = e.Level
+		}
+		if e.Message != "" {
+			ev.Fields["message"] = e.Message
+		}
+	}
+	return ev
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
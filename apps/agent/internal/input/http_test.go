@@ -0,0 +1,81 @@
+package input
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/indalyadav56/logify/apps/agent/internal/config"
+)
+
+func TestHTTPInputAcceptsOneOrMany(t *testing.T) {
+	h := NewHTTPInput(config.InputConfig{Name: "http"}, Decoration{Service: "host-default", Tags: map[string]string{"node": "n1"}},
+		slog.New(slog.NewTextHandler(io.Discard, nil)))
+	out := make(chan Event, 4)
+	handler := h.handle(context.Background(), out)
+
+	one := `{"level": "error", "message": "charge failed", "service": "billing", "timestamp": "2026-10-19T10:00:00.5Z",
+		"trace_id": "abc", "user_id": "u1", "tags": {"region": "eu"}, "metadata": {"amount": 12}}`
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/logs", strings.NewReader(one)))
+	if rec.Code != http.StatusAccepted || !strings.Contains(rec.Body.String(), `"accepted":1`) {
+		t.Fatalf("single log: %d %s", rec.Code, rec.Body)
+	}
+	ev := <-out
+	if ev.Line != "charge failed" || ev.Fields["level"] != "error" || ev.Fields["amount"] != float64(12) {
+		t.Errorf("event = %q, fields %v", ev.Line, ev.Fields)
+	}
+	if want := time.Date(2026, 10, 19, 10, 0, 0, 5e8, time.UTC); !ev.Time.Equal(want) {
+		t.Errorf("time = %v, want %v", ev.Time, want)
+	}
+	if ev.TraceID != "abc" || ev.UserID != "u1" {
+		t.Errorf("ids = %q %q", ev.TraceID, ev.UserID)
+	}
+	d := ev.Decoration
+	if d.Service != "billing" || d.Tags["region"] != "eu" || d.Tags["node"] != "n1" {
+		t.Errorf("decoration = %+v", d)
+	}
+	if _, ok := h.dec.Tags["region"]; ok {
+		t.Error("a log's tags leaked into the input's decoration")
+	}
+
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	io.WriteString(w, `[{"message": "a"}, {"message": "b"}]`)
+	w.Close()
+	req := httptest.NewRequest(http.MethodPost, "/v1/logs/batch", &gz)
+	req.Header.Set("Content-Encoding", "gzip")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("gzipped batch: %d %s", rec.Code, rec.Body)
+	}
+	if a, b := <-out, <-out; a.Line != "a" || b.Line != "b" || a.Decoration.Service != "host-default" {
+		t.Errorf("batch = %q %q, service %q", a.Line, b.Line, a.Decoration.Service)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/logs", strings.NewReader(`[{"message": "late", "timestamp": "yesterday"}]`)))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("unparseable timestamp: status %d, want 202", rec.Code)
+	}
+	if ev := <-out; ev.Line != "late" || !ev.Time.IsZero() {
+		t.Errorf("unparseable timestamp: line %q, time %v; want it to take the arrival time", ev.Line, ev.Time)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/logs", strings.NewReader(`{"message": `)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("truncated JSON: status %d, want 400", rec.Code)
+	}
+	if len(out) != 0 {
+		t.Errorf("%d events sent from a rejected request", len(out))
+	}
+}
@@ -1,5 +1,6 @@
-// Package input provides log sources (file tail, stdin, journald) that emit
-// raw Events onto a shared channel for the agent pipeline to parse and ship.
+// Package input provides log sources (file tail, stdin, journald, syslog,
+// kubernetes, and local receivers for apps) that emit raw Events onto a
+// shared channel for the agent pipeline to parse and ship.
 package input
 
 import (
@@ -48,8 +49,17 @@ type Event struct {
 	Input      string         // name of the producing input
 	Line       string         // raw text (possibly multiple joined lines)
 	Time       time.Time      // event time if the source knows it (else zero)
+	Hostname   string         // originating host if not this one (e.g. a syslog sender)
 	Fields     map[string]any // pre-structured fields (e.g. journald); optional
 	Decoration Decoration     // labels to apply to the resulting log
+
+	// Correlation IDs, for sources that carry them apart from the line.
+	TraceID, SpanID, RequestID, UserID string
+
+	// Ack, when set, tells the input the event was delivered (or rejected
+	// for good), so it may checkpoint past it. The pipeline calls it at most
+	// once; an event never acknowledged is read again after a restart.
+	Ack func()
 }
 
 // Input is a long-running log source. Run blocks until ctx is cancelled,
@@ -6,33 +6,41 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/indalyadav56/logify/apps/agent/internal/registry"
 )
 
 // JournaldInput streams the systemd journal by following `journalctl -o json`.
-// It relies on journalctl's own --cursor-file to resume after a restart, so no
-// offset bookkeeping is needed here. Linux/systemd only.
+// The cursor of the last delivered record is checkpointed in the registry and
+// passed back with --after-cursor on restart. Linux/systemd only.
 type JournaldInput struct {
-	name       string
-	units      []string
-	dec        Decoration
-	cursorFile string
-	log        *slog.Logger
+	name  string
+	units []string
+	dec   Decoration
+	reg   *registry.Registry
+	log   *slog.Logger
+
+	// legacyCursorFile is where journalctl's --cursor-file kept the cursor
+	// before the registry did; it seeds the registry once.
+	legacyCursorFile string
 }
 
-// NewJournaldInput builds a journald follower. cursorDir is where the resume
-// cursor file is kept (typically alongside the registry).
-func NewJournaldInput(name string, units []string, dec Decoration, cursorDir string, log *slog.Logger) *JournaldInput {
+// NewJournaldInput builds a journald follower. cursorDir is where earlier
+// versions kept the resume cursor file (alongside the registry).
+func NewJournaldInput(name string, units []string, dec Decoration, reg *registry.Registry, cursorDir string, log *slog.Logger) *JournaldInput {
 	return &JournaldInput{
-		name:       name,
-		units:      units,
-		dec:        dec,
-		cursorFile: filepath.Join(cursorDir, "journald-"+name+".cursor"),
-		log:        log.With("input", name),
+		name:             name,
+		units:            units,
+		dec:              dec,
+		reg:              reg,
+		log:              log.With("input", name),
+		legacyCursorFile: filepath.Join(cursorDir, "journald-"+name+".cursor"),
 	}
 }
 
@@ -43,7 +51,19 @@ func (j *JournaldInput) Run(ctx context.Context, out chan<- Event) error {
 		return fmt.Errorf("journalctl not found (journald input requires systemd): %w", err)
 	}
 
-	args := []string{"--follow", "--output=json", "--no-pager", "--cursor-file=" + j.cursorFile}
+	key := "journald:" + j.name
+	state, ok := j.reg.Get(key)
+	if !ok {
+		if raw, err := os.ReadFile(j.legacyCursorFile); err == nil {
+			state.Cursor = strings.TrimSpace(string(raw))
+		}
+	}
+	tracker := j.reg.Track(key, state)
+
+	args := []string{"--follow", "--output=json", "--no-pager"}
+	if state.Cursor != "" {
+		args = append(args, "--after-cursor="+state.Cursor)
+	}
 	for _, u := range j.units {
 		args = append(args, "--unit="+u)
 	}
@@ -60,10 +80,13 @@ func (j *JournaldInput) Run(ctx context.Context, out chan<- Event) error {
 	sc := bufio.NewScanner(stdout)
 	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
 	for sc.Scan() {
-		ev, ok := j.toEvent(sc.Bytes())
+		ev, cursor, ok := j.toEvent(sc.Bytes())
 		if !ok {
 			continue
 		}
+		if cursor != "" {
+			ev.Ack = tracker.Add(registry.State{Cursor: cursor})
+		}
 		select {
 		case out <- ev:
 		case <-ctx.Done():
@@ -83,11 +106,12 @@ func (j *JournaldInput) Run(ctx context.Context, out chan<- Event) error {
 }
 
 // toEvent maps a journald JSON record onto an Event with canonical fields, and
-// stows the remaining journal metadata in Fields for the parser.
-func (j *JournaldInput) toEvent(raw []byte) (Event, bool) {
+// stows the remaining journal metadata in Fields for the parser. It also
+// returns the record's cursor.
+func (j *JournaldInput) toEvent(raw []byte) (Event, string, bool) {
 	var rec map[string]any
 	if err := json.Unmarshal(raw, &rec); err != nil {
-		return Event{}, false
+		return Event{}, "", false
 	}
 
 	fields := make(map[string]any, len(rec))
@@ -118,7 +142,8 @@ func (j *JournaldInput) toEvent(raw []byte) (Event, bool) {
 			ev.Time = time.UnixMicro(usec)
 		}
 	}
-	return ev, true
+	cursor, _ := journalString(rec["__CURSOR"])
+	return ev, cursor, true
 }
 
 // journalString coerces a journal field (string, or array of bytes for binary
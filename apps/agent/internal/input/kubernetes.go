@@ -0,0 +1,412 @@
+package input
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/indalyadav56/logify/apps/agent/internal/config"
+	"github.com/indalyadav56/logify/apps/agent/internal/registry"
+)
+
+const (
+	kubernetesRequestTimeout = 5 * time.Second
+	// kubernetesLookupTimeout bounds how long opening a container's log waits
+	// for its pod's metadata: the input's tail loop waits with it.
+	kubernetesLookupTimeout = 2 * time.Second
+	// kubeletRelistAfter is how long after listing the kubelet's pods, or
+	// failing to, a pod missing from the list is looked up again.
+	kubeletRelistAfter = 5 * time.Second
+)
+
+// Pod annotations that override how a pod's logs are read and labelled.
+const (
+	annotationPrefix           = "logify.io/"
+	annotationService          = annotationPrefix + "service"
+	annotationProjectID        = annotationPrefix + "project-id"
+	annotationJSON             = annotationPrefix + "json"
+	annotationMultilinePattern = annotationPrefix + "multiline-pattern"
+	annotationMultilineNegate  = annotationPrefix + "multiline-negate"
+	annotationMultilineMatch   = annotationPrefix + "multiline-match"
+)
+
+var (
+	// <pod>_<namespace>_<container>-<container id>.log, in /var/log/containers.
+	containerLogName = regexp.MustCompile(`^([^_]+)_([^_]+)_(.+)-([0-9a-f]{64})\.log$`)
+	// <namespace>_<pod>_<uid>, the directory of a pod in /var/log/pods.
+	podLogDir = regexp.MustCompile(`^([^_]+)_([^_]+)_([0-9a-fA-F-]+)$`)
+)
+
+// KubernetesInput tails the container logs of the pods on a node, as written
+// by the container runtime (CRI or Docker json-file), and labels each event
+// with its pod: namespace, pod, container and pod UID from the file names,
+// and the pod's labels and annotations from the API server or kubelet.
+// Annotations under logify.io/ override the service, project and parsing of
+// a pod's logs. Containers started after the agent are read from their
+// first line.
+type KubernetesInput struct {
+	*FileInput
+	service string // the input's own service, if configured
+	pods    *podMetadata
+}
+
+// NewKubernetesInput builds a container log tailer from its config.
+func NewKubernetesInput(in config.InputConfig, dec Decoration, reg *registry.Registry, log *slog.Logger) *KubernetesInput {
+	k := &KubernetesInput{
+		FileInput: NewFileInput(in, dec, reg, log),
+		service:   in.Service,
+		pods:      newPodMetadata(in.Kubernetes, log.With("input", in.Name)),
+	}
+	k.FileInput.setup = k.setup
+	k.FileInput.newFilesFromStart = true
+	return k
+}
+
+// containerRef identifies the container a log file belongs to.
+type containerRef struct {
+	Namespace, Pod, PodUID, Container, ContainerID string
+}
+
+// parseContainerPath reads a container's identity from the path of its log,
+// either /var/log/containers/<pod>_<namespace>_<container>-<id>.log, which
+// links into /var/log/pods, or /var/log/pods/<namespace>_<pod>_<uid>/<container>/<n>.log.
+func parseContainerPath(path string) (containerRef, bool) {
+	var ref containerRef
+	if m := containerLogName.FindStringSubmatch(filepath.Base(path)); m != nil {
+		ref = containerRef{Pod: m[1], Namespace: m[2], Container: m[3], ContainerID: m[4]}
+		if target, err := filepath.EvalSymlinks(path); err == nil {
+			path = target
+		}
+	}
+	dir := filepath.Dir(path)
+	if m := podLogDir.FindStringSubmatch(filepath.Base(filepath.Dir(dir))); m != nil {
+		if ref.Pod == "" {
+			ref.Namespace, ref.Pod, ref.Container = m[1], m[2], filepath.Base(dir)
+		}
+		if ref.Namespace == m[1] && ref.Pod == m[2] {
+			ref.PodUID = m[3]
+		}
+	}
+	return ref, ref.Pod != ""
+}
+
+// setup decodes a newly opened file as a container log and labels its events
+// with the container's pod.
+func (k *KubernetesInput) setup(tf *tailedFile) {
+	tf.container = newContainerLog()
+	ref, ok := parseContainerPath(tf.path)
+	if !ok {
+		return
+	}
+
+	dec := k.dec
+	dec.Tags = maps.Clone(dec.Tags)
+	if dec.Tags == nil {
+		dec.Tags = map[string]string{}
+	}
+	for key, v := range map[string]string{
+		"k8s.namespace":    ref.Namespace,
+		"k8s.pod":          ref.Pod,
+		"k8s.pod_uid":      ref.PodUID,
+		"k8s.container":    ref.Container,
+		"k8s.container_id": ref.ContainerID,
+	} {
+		if v != "" {
+			dec.Tags[key] = v
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), kubernetesLookupTimeout)
+	defer cancel()
+	pod, _ := k.pods.get(ctx, ref.Namespace, ref.Pod)
+	if dec.Tags["k8s.pod_uid"] == "" && pod.UID != "" {
+		dec.Tags["k8s.pod_uid"] = pod.UID
+	}
+	for key, v := range pod.Labels {
+		dec.Tags["k8s.label."+key] = v
+	}
+	for key, v := range pod.Annotations {
+		if strings.HasPrefix(key, annotationPrefix) || key == "kubectl.kubernetes.io/last-applied-configuration" {
+			continue
+		}
+		dec.Tags["k8s.annotation."+key] = v
+	}
+
+	// The service is, by priority, the pod's annotation, the input's own,
+	// the pod's app name and the container's name.
+	dec.Service = firstNonEmpty(pod.Annotations[annotationService], k.service,
+		pod.Labels["app.kubernetes.io/name"], pod.Labels["app"], ref.Container)
+	if v := pod.Annotations[annotationProjectID]; v != "" {
+		dec.ProjectID = v
+	}
+	if v, err := strconv.ParseBool(pod.Annotations[annotationJSON]); err == nil {
+		dec.JSON = v
+	}
+	if pattern := pod.Annotations[annotationMultilinePattern]; pattern != "" {
+		ml := &config.Multiline{Pattern: pattern, Match: "after", MaxLines: 500}
+		if m := pod.Annotations[annotationMultilineMatch]; m == "before" {
+			ml.Match = m
+		}
+		ml.Negate, _ = strconv.ParseBool(pod.Annotations[annotationMultilineNegate])
+		tf.ml = newMultiline(ml)
+	}
+	tf.dec = &dec
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// podInfo is the metadata of a pod the input labels its logs with.
+type podInfo struct {
+	UID         string            `json:"uid"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+type cachedPod struct {
+	pod     podInfo
+	found   bool
+	fetched time.Time
+}
+
+// podMetadata looks pods up in the API server, one at a time, or in the
+// kubelet's list of the node's pods, caching what it learns for ttl.
+// Failures are cached too, so an unreachable server is not asked for every
+// new file. Entries older than ttl are dropped, so the pods that left the
+// node do not pile up.
+type podMetadata struct {
+	source    string
+	url       string
+	tokenFile string
+	ttl       time.Duration
+	client    *http.Client
+	log       *slog.Logger
+
+	mu       sync.Mutex
+	pods     map[string]cachedPod     // by namespace/name
+	inflight map[string]chan struct{} // lookups running, by pod; "" is the kubelet list
+	listed   time.Time                // kubelet: when the pod list was last asked for
+	swept    time.Time                // when expired entries were last dropped
+}
+
+func newPodMetadata(cfg config.Kubernetes, log *slog.Logger) *podMetadata {
+	p := &podMetadata{
+		source:    cfg.Metadata,
+		url:       strings.TrimSuffix(cfg.URL, "/"),
+		tokenFile: cfg.TokenFile,
+		ttl:       cfg.CacheTTL.Std(),
+		log:       log,
+		pods:      map[string]cachedPod{},
+		inflight:  map[string]chan struct{}{},
+	}
+	if p.url == "" {
+		p.url = defaultKubernetesURL(p.source)
+	}
+
+	tlsCfg := &tls.Config{InsecureSkipVerify: cfg.InsecureSkipVerify}
+	if pem, err := os.ReadFile(cfg.CAFile); err == nil {
+		tlsCfg.RootCAs = x509.NewCertPool()
+		tlsCfg.RootCAs.AppendCertsFromPEM(pem)
+	}
+	p.client = &http.Client{
+		Timeout:   kubernetesRequestTimeout,
+		Transport: &http.Transport{TLSClientConfig: tlsCfg, Proxy: http.ProxyFromEnvironment},
+	}
+	return p
+}
+
+func defaultKubernetesURL(source string) string {
+	if source == "kubelet" {
+		host := os.Getenv("NODE_IP")
+		if host == "" {
+			host = "127.0.0.1"
+		}
+		return "https://" + net.JoinHostPort(host, "10250")
+	}
+	if host := os.Getenv("KUBERNETES_SERVICE_HOST"); host != "" {
+		return "https://" + net.JoinHostPort(host, os.Getenv("KUBERNETES_SERVICE_PORT"))
+	}
+	return "https://kubernetes.default.svc"
+}
+
+// get returns the metadata of a pod, and whether it was found. The lock is
+// not held while a pod is looked up: one lookup of a pod, or one kubelet
+// list, runs at a time, and other callers needing it wait for its result.
+func (p *podMetadata) get(ctx context.Context, namespace, name string) (podInfo, bool) {
+	if p.source == "none" {
+		return podInfo{}, false
+	}
+	key := namespace + "/" + name
+	flight := key
+	if p.source == "kubelet" {
+		flight = ""
+	}
+
+	p.mu.Lock()
+	now := time.Now()
+	p.sweep(now)
+	if c, ok := p.cached(key, now); ok {
+		p.mu.Unlock()
+		return c.pod, c.found
+	}
+	if wait, ok := p.inflight[flight]; ok {
+		p.mu.Unlock()
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return podInfo{}, false
+		}
+		p.mu.Lock()
+		c := p.pods[key]
+		p.mu.Unlock()
+		return c.pod, c.found
+	}
+	done := make(chan struct{})
+	p.inflight[flight] = done
+	p.mu.Unlock()
+
+	p.lookup(ctx, namespace, name)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.inflight, flight)
+	close(done)
+	c := p.pods[key]
+	return c.pod, c.found
+}
+
+// cached returns the entry of key if it can be used without a lookup. A pod
+// missing from the kubelet's list is not looked up again until the list is
+// old enough to miss a pod started since.
+func (p *podMetadata) cached(key string, now time.Time) (cachedPod, bool) {
+	c, ok := p.pods[key]
+	if ok && now.Sub(c.fetched) < p.ttl && (c.found || p.source != "kubelet") {
+		return c, true
+	}
+	if p.source == "kubelet" && now.Sub(p.listed) < kubeletRelistAfter {
+		return c, true
+	}
+	return cachedPod{}, false
+}
+
+// sweep drops the entries older than ttl, at most once per ttl.
+func (p *podMetadata) sweep(now time.Time) {
+	if now.Sub(p.swept) < p.ttl {
+		return
+	}
+	p.swept = now
+	for key, c := range p.pods {
+		if now.Sub(c.fetched) >= p.ttl {
+			delete(p.pods, key)
+		}
+	}
+}
+
+// lookup fetches a pod, or the kubelet's list of the node's pods, into the
+// cache. A failed kubelet list keeps the pods already known.
+func (p *podMetadata) lookup(ctx context.Context, namespace, name string) {
+	key := namespace + "/" + name
+	if p.source == "kubelet" {
+		pods, err := p.listKubelet(ctx)
+		if err != nil {
+			p.log.Warn("listing pods from the kubelet failed", "err", err)
+		}
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.listed = time.Now()
+		if err == nil {
+			p.pods = pods
+		}
+		if _, ok := p.pods[key]; !ok {
+			p.pods[key] = cachedPod{fetched: p.listed}
+		}
+		return
+	}
+
+	pod, found, err := p.fetchPod(ctx, namespace, name)
+	if err != nil {
+		p.log.Warn("looking up pod failed", "pod", key, "err", err)
+	}
+	p.mu.Lock()
+	p.pods[key] = cachedPod{pod: pod, found: found, fetched: time.Now()}
+	p.mu.Unlock()
+}
+
+type podObject struct {
+	Metadata struct {
+		Namespace string `json:"namespace"`
+		Name      string `json:"name"`
+		podInfo
+	} `json:"metadata"`
+}
+
+func (p *podMetadata) fetchPod(ctx context.Context, namespace, name string) (podInfo, bool, error) {
+	var pod podObject
+	status, err := p.getJSON(ctx, "/api/v1/namespaces/"+url.PathEscape(namespace)+"/pods/"+url.PathEscape(name), &pod)
+	if status == http.StatusNotFound {
+		return podInfo{}, false, nil
+	}
+	if err != nil {
+		return podInfo{}, false, err
+	}
+	return pod.Metadata.podInfo, true, nil
+}
+
+// listKubelet returns the kubelet's list of the node's pods, by
+// namespace/name.
+func (p *podMetadata) listKubelet(ctx context.Context) (map[string]cachedPod, error) {
+	var list struct {
+		Items []podObject `json:"items"`
+	}
+	if _, err := p.getJSON(ctx, "/pods", &list); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	pods := make(map[string]cachedPod, len(list.Items))
+	for _, item := range list.Items {
+		key := item.Metadata.Namespace + "/" + item.Metadata.Name
+		pods[key] = cachedPod{pod: item.Metadata.podInfo, found: true, fetched: now}
+	}
+	return pods, nil
+}
+
+func (p *podMetadata) getJSON(ctx context.Context, path string, v any) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url+path, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+	// Projected service account tokens rotate, so the file is read each time.
+	if token, err := os.ReadFile(p.tokenFile); err == nil {
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, fmt.Errorf("GET %s: %s", path, resp.Status)
+	}
+	return resp.StatusCode, json.NewDecoder(resp.Body).Decode(v)
+}
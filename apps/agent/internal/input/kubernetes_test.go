@@ -0,0 +1,253 @@
+package input
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/indalyadav56/logify/apps/agent/internal/config"
+	"github.com/indalyadav56/logify/apps/agent/internal/registry"
+)
+
+const (
+	testPodUID      = "4a1c2e6f-0d3b-4c8e-9f7a-2b5d6e8f1a3c"
+	testContainerID = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+)
+
+// installContainerLog lays a fixture out as the kubelet does: the log in
+// /var/log/pods and a link to it in /var/log/containers.
+func installContainerLog(t *testing.T, root, fixture, namespace, pod, container string) {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.Join("testdata", "kubernetes", fixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(root, "pods", namespace+"_"+pod+"_"+testPodUID, container)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	target := filepath.Join(dir, "0.log")
+	if err := os.WriteFile(target, raw, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "containers"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(root, "containers", pod+"_"+namespace+"_"+container+"-"+testContainerID+".log")
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseContainerPath(t *testing.T) {
+	root := t.TempDir()
+	installContainerLog(t, root, "cri.log", "shop", "checkout-7d9f-x2x4q", "app")
+
+	ref, ok := parseContainerPath(filepath.Join(root, "containers", "checkout-7d9f-x2x4q_shop_app-"+testContainerID+".log"))
+	want := containerRef{Namespace: "shop", Pod: "checkout-7d9f-x2x4q", PodUID: testPodUID, Container: "app", ContainerID: testContainerID}
+	if !ok || ref != want {
+		t.Fatalf("ref = %+v, want %+v", ref, want)
+	}
+
+	ref, ok = parseContainerPath(filepath.Join(root, "pods", "shop_checkout-7d9f-x2x4q_"+testPodUID, "app", "0.log"))
+	want.ContainerID = ""
+	if !ok || ref != want {
+		t.Fatalf("ref from the pods directory = %+v, want %+v", ref, want)
+	}
+}
+
+func TestKubernetesInputReassemblesAndEnriches(t *testing.T) {
+	root := t.TempDir()
+	installContainerLog(t, root, "cri.log", "shop", "checkout-7d9f-x2x4q", "app")
+	installContainerLog(t, root, "docker.log", "shop", "checkout-7d9f-x2x4q", "proxy")
+
+	var requests atomic.Int32
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path != "/api/v1/namespaces/shop/pods/checkout-7d9f-x2x4q" || r.Header.Get("Authorization") != "Bearer test-token" {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, `{"metadata": {"name": "checkout-7d9f-x2x4q", "namespace": "shop", "uid": "`+testPodUID+`",
+			"labels": {"app": "checkout", "team": "payments"},
+			"annotations": {"logify.io/service": "checkout-api", "logify.io/json": "true", "prometheus.io/scrape": "true"}}}`)
+	}))
+	defer api.Close()
+
+	tokenFile := filepath.Join(root, "token")
+	if err := os.WriteFile(tokenFile, []byte("test-token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	reg, err := registry.Open(filepath.Join(root, "registry.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := NewKubernetesInput(config.InputConfig{
+		Name:          "pods",
+		Paths:         []string{filepath.Join(root, "containers", "*.log")},
+		FromBeginning: true,
+		Kubernetes:    config.Kubernetes{Metadata: "api", URL: api.URL, TokenFile: tokenFile, CacheTTL: config.Duration(time.Minute)},
+	}, Decoration{Service: "node-default"}, reg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := make(chan Event, 16)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		in.Run(ctx, out)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	byLine := map[string]Event{}
+	for len(byLine) < 5 {
+		select {
+		case ev := <-out:
+			byLine[ev.Line] = ev
+		case <-time.After(5 * time.Second):
+			t.Fatalf("got %d events, want 5: %v", len(byLine), byLine)
+		}
+	}
+
+	joined, ok := byLine[`{"level":"error","msg":"payment failed"}`]
+	if !ok {
+		t.Fatalf("partial CRI line not reassembled: %v", byLine)
+	}
+	if want := time.Date(2026, 10, 19, 10, 0, 1, 0, time.UTC); !joined.Time.Equal(want) {
+		t.Errorf("reassembled line time = %v, want its first piece's %v", joined.Time, want)
+	}
+	if _, ok := byLine["panic: card declined"]; !ok {
+		t.Errorf("interleaved stderr line missing: %v", byLine)
+	}
+	if _, ok := byLine["a line split by docker"]; !ok {
+		t.Errorf("partial docker line not reassembled: %v", byLine)
+	}
+
+	d := joined.Decoration
+	if d.Service != "checkout-api" || !d.JSON {
+		t.Errorf("annotations not applied: service %q, json %v", d.Service, d.JSON)
+	}
+	wantTags := map[string]string{
+		"k8s.namespace":                       "shop",
+		"k8s.pod":                             "checkout-7d9f-x2x4q",
+		"k8s.pod_uid":                         testPodUID,
+		"k8s.container":                       "app",
+		"k8s.label.team":                      "payments",
+		"k8s.annotation.prometheus.io/scrape": "true",
+	}
+	for k, v := range wantTags {
+		if d.Tags[k] != v {
+			t.Errorf("tag %s = %q, want %q", k, d.Tags[k], v)
+		}
+	}
+	for k := range d.Tags {
+		if strings.HasPrefix(k, "k8s.annotation.logify.io/") {
+			t.Errorf("override annotation %s copied into tags", k)
+		}
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("API server asked %d times for one pod, want 1", got)
+	}
+}
+
+func TestContainerLogCommitsBeforeUnfinishedLine(t *testing.T) {
+	c := newContainerLog()
+	if _, _, ok := c.decode("2026-10-19T10:00:00Z stdout P first half ", 100); ok {
+		t.Fatal("partial line decoded on its own")
+	}
+	if got := c.commitOffset(180); got != 100 {
+		t.Fatalf("commit offset = %d while a line is unfinished, want 100", got)
+	}
+	text, _, ok := c.decode("2026-10-19T10:00:00Z stdout F second half", 140)
+	if !ok || text != "first half second half" {
+		t.Fatalf("decoded %q, %v", text, ok)
+	}
+	if got := c.commitOffset(180); got != 180 {
+		t.Fatalf("commit offset = %d once the line is complete, want 180", got)
+	}
+}
+
+func newTestPodMetadata(source, url string, ttl time.Duration) *podMetadata {
+	return newPodMetadata(config.Kubernetes{Metadata: source, URL: url, CacheTTL: config.Duration(ttl)},
+		slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestPodMetadataThrottlesFailedKubeletLists(t *testing.T) {
+	var requests atomic.Int32
+	kubelet := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer kubelet.Close()
+
+	p := newTestPodMetadata("kubelet", kubelet.URL, time.Minute)
+	for _, pod := range []string{"a", "b", "c"} {
+		if _, found := p.get(context.Background(), "shop", pod); found {
+			t.Fatalf("pod %s found with the kubelet down", pod)
+		}
+	}
+	if got := requests.Load(); got != 1 {
+		t.Fatalf("kubelet asked %d times for three new pods, want 1 until the list may be retried", got)
+	}
+}
+
+func TestPodMetadataDropsExpiredEntries(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	defer api.Close()
+
+	p := newTestPodMetadata("api", api.URL, 20*time.Millisecond)
+	p.get(context.Background(), "shop", "gone")
+	time.Sleep(30 * time.Millisecond)
+	p.get(context.Background(), "shop", "new")
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.pods["shop/gone"]; ok || len(p.pods) != 1 {
+		t.Fatalf("cache = %v, want only shop/new once shop/gone expired", p.pods)
+	}
+}
+
+func TestPodMetadataLookupDoesNotBlockCachedPods(t *testing.T) {
+	release := make(chan struct{})
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/slow") {
+			<-release
+		}
+		io.WriteString(w, `{"metadata": {"uid": "`+testPodUID+`"}}`)
+	}))
+	defer api.Close()
+	defer close(release)
+
+	p := newTestPodMetadata("api", api.URL, time.Minute)
+	if _, found := p.get(context.Background(), "shop", "fast"); !found {
+		t.Fatal("pod not found")
+	}
+	go p.get(context.Background(), "shop", "slow")
+	time.Sleep(20 * time.Millisecond) // let the slow lookup start
+
+	got := make(chan bool, 1)
+	go func() {
+		_, found := p.get(context.Background(), "shop", "fast")
+		got <- found
+	}()
+	select {
+	case found := <-got:
+		if !found {
+			t.Fatal("cached pod not found")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("a cached pod waited on another pod's lookup")
+	}
+}
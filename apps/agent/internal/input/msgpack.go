@@ -0,0 +1,257 @@
+package input
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// maxMsgpackDepth bounds how deeply arrays and maps may nest in a value.
+const maxMsgpackDepth = 64
+
+var errMsgpackTooLarge = errors.New("msgpack message too large")
+
+// msgpackExt is a msgpack extension value the decoder does not interpret.
+type msgpackExt struct {
+	Type int8
+	Data []byte
+}
+
+// msgpackDecoder reads msgpack values from a stream into nil, bool, int64,
+// uint64, float64, string, []byte, []any, map[string]any and msgpackExt.
+// It reads at most left bytes, so a hostile length cannot make it allocate
+// more than that; callers reset left for each message.
+type msgpackDecoder struct {
+	r    *bufio.Reader
+	left int
+}
+
+func newMsgpackDecoder(r io.Reader) *msgpackDecoder {
+	return &msgpackDecoder{r: bufio.NewReader(r)}
+}
+
+// more reports whether another value follows before the end of the stream.
+func (d *msgpackDecoder) more() bool {
+	_, err := d.r.Peek(1)
+	return err == nil
+}
+
+func (d *msgpackDecoder) take(n int) error {
+	if n < 0 || n > d.left {
+		return errMsgpackTooLarge
+	}
+	d.left -= n
+	return nil
+}
+
+func (d *msgpackDecoder) bytes(n int) ([]byte, error) {
+	if err := d.take(n); err != nil {
+		return nil, err
+	}
+	b := make([]byte, n)
+	_, err := io.ReadFull(d.r, b)
+	return b, unexpectedEOF(err)
+}
+
+// uint reads a big-endian unsigned integer of size bytes.
+func (d *msgpackDecoder) uint(size int) (uint64, error) {
+	b, err := d.bytes(size)
+	if err != nil {
+		return 0, err
+	}
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v, nil
+}
+
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// value decodes the next value. At the end of the stream it returns io.EOF.
+func (d *msgpackDecoder) value() (any, error) {
+	return d.decode(0)
+}
+
+func (d *msgpackDecoder) decode(depth int) (any, error) {
+	if depth > maxMsgpackDepth {
+		return nil, errors.New("msgpack values nested too deeply")
+	}
+	if err := d.take(1); err != nil {
+		return nil, err
+	}
+	c, err := d.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c >= 0x80 && c <= 0x8f:
+		return d.mapOf(int(c&0x0f), depth)
+	case c >= 0x90 && c <= 0x9f:
+		return d.arrayOf(int(c&0x0f), depth)
+	case c >= 0xa0 && c <= 0xbf:
+		b, err := d.bytes(int(c & 0x1f))
+		return string(b), err
+	}
+
+	// sized reads the length that follows c, then applies fn to it.
+	sized := func(size int, fn func(n int) (any, error)) (any, error) {
+		n, err := d.uint(size)
+		if err != nil {
+			return nil, err
+		}
+		if n > math.MaxInt32 {
+			return nil, errMsgpackTooLarge
+		}
+		return fn(int(n))
+	}
+	str := func(n int) (any, error) {
+		b, err := d.bytes(n)
+		return string(b), err
+	}
+	bin := func(n int) (any, error) { return d.bytes(n) }
+	array := func(n int) (any, error) { return d.arrayOf(n, depth) }
+	mapping := func(n int) (any, error) { return d.mapOf(n, depth) }
+	ext := func(n int) (any, error) { return d.ext(n) }
+
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		return sized(1<<(c-0xc4), bin)
+	case 0xc7, 0xc8, 0xc9:
+		return sized(1<<(c-0xc7), ext)
+	case 0xca:
+		v, err := d.uint(4)
+		return float64(math.Float32frombits(uint32(v))), err
+	case 0xcb:
+		v, err := d.uint(8)
+		return math.Float64frombits(v), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		v, err := d.uint(1 << (c - 0xcc))
+		if v > math.MaxInt64 {
+			return v, err
+		}
+		return int64(v), err
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (c - 0xd0)
+		v, err := d.uint(size)
+		shift := 64 - 8*size // sign-extend
+		return int64(v<<shift) >> shift, err
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.ext(1 << (c - 0xd4))
+	case 0xd9, 0xda, 0xdb:
+		return sized(1<<(c-0xd9), str)
+	case 0xdc, 0xdd:
+		return sized(2<<(c-0xdc), array)
+	case 0xde, 0xdf:
+		return sized(2<<(c-0xde), mapping)
+	}
+	return nil, fmt.Errorf("invalid msgpack byte 0x%02x", c)
+}
+
+func (d *msgpackDecoder) arrayOf(n, depth int) (any, error) {
+	// Every element takes at least a byte.
+	if n > d.left {
+		return nil, errMsgpackTooLarge
+	}
+	values := make([]any, n)
+	for i := range values {
+		v, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+func (d *msgpackDecoder) mapOf(n, depth int) (any, error) {
+	if n > d.left/2 {
+		return nil, errMsgpackTooLarge
+	}
+	values := make(map[string]any, n)
+	for range n {
+		k, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		v, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		switch k := k.(type) {
+		case string:
+			values[k] = v
+		case []byte:
+			values[string(k)] = v
+		default:
+			values[fmt.Sprint(k)] = v
+		}
+	}
+	return values, nil
+}
+
+func (d *msgpackDecoder) ext(n int) (any, error) {
+	b, err := d.bytes(n + 1)
+	if err != nil {
+		return nil, err
+	}
+	return msgpackExt{Type: int8(b[0]), Data: b[1:]}, nil
+}
+
+// msgpackTime reads a Fluent event time: seconds as an integer or float, or
+// an EventTime, extension type 0 holding seconds and nanoseconds.
+func msgpackTime(v any) (time.Time, bool) {
+	switch v := v.(type) {
+	case int64:
+		return time.Unix(v, 0).UTC(), true
+	case uint64:
+		return time.Unix(int64(v), 0).UTC(), true
+	case float64:
+		sec, frac := math.Modf(v)
+		return time.Unix(int64(sec), int64(frac*1e9)).UTC(), true
+	case msgpackExt:
+		if v.Type == 0 && len(v.Data) == 8 {
+			sec := binary.BigEndian.Uint32(v.Data[:4])
+			nsec := binary.BigEndian.Uint32(v.Data[4:])
+			return time.Unix(int64(sec), int64(nsec)).UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// appendMsgpackString appends s to b encoded as a msgpack string.
+func appendMsgpackString(b []byte, s string) []byte {
+	switch n := len(s); {
+	case n < 32:
+		b = append(b, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		b = append(b, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		b = append(b, 0xda)
+		b = binary.BigEndian.AppendUint16(b, uint16(n))
+	default:
+		b = append(b, 0xdb)
+		b = binary.BigEndian.AppendUint32(b, uint32(n))
+	}
+	return append(b, s...)
+}
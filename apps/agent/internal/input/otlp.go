@@ -0,0 +1,489 @@
+package input
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/indalyadav56/logify/apps/agent/internal/config"
+)
+
+// OTLPInput receives logs over OTLP/HTTP (POST /v1/logs), encoded as
+// protobuf or JSON and optionally gzipped, so apps instrumented with
+// OpenTelemetry can export to the agent. Resource attributes fill in the
+// service, namespace, environment and host; log attributes become metadata.
+type OTLPInput struct {
+	name    string
+	address string
+	dec     Decoration
+	log     *slog.Logger
+}
+
+// NewOTLPInput builds an OTLP/HTTP logs receiver from its input config.
+func NewOTLPInput(in config.InputConfig, dec Decoration, log *slog.Logger) *OTLPInput {
+	return &OTLPInput{name: in.Name, address: in.Address, dec: dec, log: log.With("input", in.Name)}
+}
+
+func (o *OTLPInput) Name() string { return o.name }
+
+func (o *OTLPInput) Run(ctx context.Context, out chan<- Event) error {
+	mux := http.NewServeMux()
+	mux.Handle("POST /v1/logs", o.handle(ctx, out))
+	return serveHTTP(ctx, o.address, mux, o.log)
+}
+
+func (o *OTLPInput) handle(ctx context.Context, out chan<- Event) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if mediaType != "application/x-protobuf" && mediaType != "application/json" {
+			http.Error(w, "content type must be application/x-protobuf or application/json", http.StatusUnsupportedMediaType)
+			return
+		}
+		body, err := readBody(w, r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var req otlpRequest
+		if mediaType == "application/json" {
+			err = json.Unmarshal(body, &req)
+		} else {
+			req, err = decodeOTLPProto(body)
+		}
+		if err != nil {
+			http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := sendAll(r.Context(), ctx.Done(), out, o.events(req)); err != nil {
+			http.Error(w, "agent is shutting down", http.StatusServiceUnavailable)
+			return
+		}
+
+		// An empty ExportLogsServiceResponse: every record was accepted.
+		w.Header().Set("Content-Type", mediaType)
+		w.WriteHeader(http.StatusOK)
+		if mediaType == "application/json" {
+			w.Write([]byte("{}"))
+		}
+	})
+}
+
+// events turns an export request into one event per log record.
+func (o *OTLPInput) events(req otlpRequest) []Event {
+	var events []Event
+	for _, rl := range req.ResourceLogs {
+		var l labels
+		var host, env string
+		resource := map[string]any{}
+		for _, kv := range rl.Resource.Attributes {
+			s, _ := kv.Value.v.(string)
+			switch kv.Key {
+			case "service.name":
+				l.Service = s
+			case "service.namespace":
+				l.Namespace = s
+			case "deployment.environment.name":
+				l.Environment = s
+			case "deployment.environment": // before semantic conventions 1.27
+				env = s
+			case "host.name":
+				host = s
+			default:
+				resource["resource."+kv.Key] = kv.Value.v
+			}
+		}
+		l.Environment = firstNonEmpty(l.Environment, env)
+		dec := o.dec.with(l)
+
+		for _, sl := range rl.ScopeLogs {
+			for _, rec := range sl.LogRecords {
+				ev := Event{
+					Input:      o.name,
+					Hostname:   host,
+					Decoration: dec,
+					Fields:     make(map[string]any, len(resource)+len(rec.Attributes)+2),
+					TraceID:    nonZeroID(rec.TraceID),
+					SpanID:     nonZeroID(rec.SpanID),
+				}
+				if ns := firstNonZero(uint64(rec.TimeUnixNano), uint64(rec.ObservedTimeUnixNano)); ns != 0 {
+					ev.Time = time.Unix(0, int64(ns)).UTC()
+				}
+				for k, v := range resource {
+					ev.Fields[k] = v
+				}
+				for _, kv := range rec.Attributes {
+					ev.Fields[kv.Key] = kv.Value.v
+				}
+				switch body := rec.Body.v.(type) {
+				case nil:
+				case string:
+					ev.Line = body
+					ev.Fields["message"] = body
+				case map[string]any:
+					// A structured body: its fields are the record's.
+					for k, v := range body {
+						ev.Fields[k] = v
+					}
+					b, _ := json.Marshal(body)
+					ev.Line = string(b)
+				default:
+					b, _ := json.Marshal(body)
+					ev.Line = string(b)
+				}
+				if level := severityLevel(rec.SeverityNumber, rec.SeverityText); level != "" {
+					ev.Fields["level"] = level
+				}
+				events = append(events, ev)
+			}
+		}
+	}
+	return events
+}
+
+// severityLevel maps an OTLP severity number onto a level name, falling back
+// to the record's severity text.
+func severityLevel(number int, text string) string {
+	switch {
+	case number >= 1 && number <= 4:
+		return "TRACE"
+	case number >= 5 && number <= 8:
+		return "DEBUG"
+	case number >= 9 && number <= 12:
+		return "INFO"
+	case number >= 13 && number <= 16:
+		return "WARN"
+	case number >= 17 && number <= 20:
+		return "ERROR"
+	case number >= 21 && number <= 24:
+		return "FATAL"
+	}
+	return text
+}
+
+func nonZeroID(id string) string {
+	if strings.Trim(id, "0") == "" {
+		return ""
+	}
+	return id
+}
+
+func firstNonZero(a, b uint64) uint64 {
+	if a != 0 {
+		return a
+	}
+	return b
+}
+
+// The parts of an ExportLogsServiceRequest the agent reads. The JSON tags
+// follow the OTLP/JSON encoding, which both encodings are decoded into.
+type (
+	otlpRequest struct {
+		ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+	}
+	otlpResourceLogs struct {
+		Resource struct {
+			Attributes []otlpKeyValue `json:"attributes"`
+		} `json:"resource"`
+		ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+	}
+	otlpScopeLogs struct {
+		LogRecords []otlpLogRecord `json:"logRecords"`
+	}
+	otlpLogRecord struct {
+		TimeUnixNano         otlpUint64     `json:"timeUnixNano"`
+		ObservedTimeUnixNano otlpUint64     `json:"observedTimeUnixNano"`
+		SeverityNumber       int            `json:"severityNumber"`
+		SeverityText         string         `json:"severityText"`
+		Body                 otlpValue      `json:"body"`
+		Attributes           []otlpKeyValue `json:"attributes"`
+		TraceID              string         `json:"traceId"` // hex
+		SpanID               string         `json:"spanId"`  // hex
+	}
+	otlpKeyValue struct {
+		Key   string    `json:"key"`
+		Value otlpValue `json:"value"`
+	}
+)
+
+// otlpUint64 is a 64-bit integer, which OTLP/JSON writes as a string.
+type otlpUint64 uint64
+
+func (u *otlpUint64) UnmarshalJSON(b []byte) error {
+	n, err := strconv.ParseUint(strings.Trim(string(b), `"`), 10, 64)
+	if err != nil {
+		return err
+	}
+	*u = otlpUint64(n)
+	return nil
+}
+
+// otlpValue is an AnyValue as a Go value: a string, bool, int64, float64,
+// []any, map[string]any or, for bytes, their base64 encoding.
+type otlpValue struct{ v any }
+
+func (o *otlpValue) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		StringValue *string         `json:"stringValue"`
+		BoolValue   *bool           `json:"boolValue"`
+		IntValue    json.RawMessage `json:"intValue"`
+		DoubleValue *float64        `json:"doubleValue"`
+		BytesValue  *string         `json:"bytesValue"`
+		ArrayValue  *struct {
+			Values []otlpValue `json:"values"`
+		} `json:"arrayValue"`
+		KvlistValue *struct {
+			Values []otlpKeyValue `json:"values"`
+		} `json:"kvlistValue"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	switch {
+	case raw.StringValue != nil:
+		o.v = *raw.StringValue
+	case raw.BoolValue != nil:
+		o.v = *raw.BoolValue
+	case raw.IntValue != nil:
+		n, err := strconv.ParseInt(strings.Trim(string(raw.IntValue), `"`), 10, 64)
+		if err != nil {
+			return err
+		}
+		o.v = n
+	case raw.DoubleValue != nil:
+		o.v = *raw.DoubleValue
+	case raw.BytesValue != nil:
+		o.v = *raw.BytesValue
+	case raw.ArrayValue != nil:
+		values := make([]any, len(raw.ArrayValue.Values))
+		for i, v := range raw.ArrayValue.Values {
+			values[i] = v.v
+		}
+		o.v = values
+	case raw.KvlistValue != nil:
+		values := make(map[string]any, len(raw.KvlistValue.Values))
+		for _, kv := range raw.KvlistValue.Values {
+			values[kv.Key] = kv.Value.v
+		}
+		o.v = values
+	}
+	return nil
+}
+
+// Protobuf wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// maxValueDepth bounds how deeply arrays and maps may nest in an AnyValue.
+const maxValueDepth = 64
+
+var (
+	errProtoTruncated = errors.New("truncated protobuf message")
+	errValueTooDeep   = errors.New("attribute values nested too deeply")
+)
+
+// walkProto calls fn for each field of a protobuf message. For varint and
+// fixed-width fields v holds the value; for length-delimited ones, data.
+func walkProto(b []byte, fn func(num, typ int, v uint64, data []byte) error) error {
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		if n <= 0 {
+			return errProtoTruncated
+		}
+		b = b[n:]
+		num, typ := int(key>>3), int(key&7)
+
+		var v uint64
+		var data []byte
+		switch typ {
+		case wireVarint:
+			v, n = binary.Uvarint(b)
+			if n <= 0 {
+				return errProtoTruncated
+			}
+			b = b[n:]
+		case wireFixed64:
+			if len(b) < 8 {
+				return errProtoTruncated
+			}
+			v, b = binary.LittleEndian.Uint64(b), b[8:]
+		case wireFixed32:
+			if len(b) < 4 {
+				return errProtoTruncated
+			}
+			v, b = uint64(binary.LittleEndian.Uint32(b)), b[4:]
+		case wireBytes:
+			size, n := binary.Uvarint(b)
+			if n <= 0 || size > uint64(len(b)-n) {
+				return errProtoTruncated
+			}
+			data, b = b[n:n+int(size)], b[n+int(size):]
+		default:
+			return fmt.Errorf("unsupported protobuf wire type %d", typ)
+		}
+		if err := fn(num, typ, v, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeOTLPProto decodes a protobuf ExportLogsServiceRequest. Fields the
+// agent does not read are skipped.
+func decodeOTLPProto(b []byte) (otlpRequest, error) {
+	var req otlpRequest
+	err := walkProto(b, func(num, typ int, _ uint64, data []byte) error {
+		if num != 1 || typ != wireBytes {
+			return nil
+		}
+		rl, err := decodeProtoResourceLogs(data)
+		req.ResourceLogs = append(req.ResourceLogs, rl)
+		return err
+	})
+	return req, err
+}
+
+func decodeProtoResourceLogs(b []byte) (otlpResourceLogs, error) {
+	var rl otlpResourceLogs
+	err := walkProto(b, func(num, typ int, _ uint64, data []byte) error {
+		if typ != wireBytes {
+			return nil
+		}
+		switch num {
+		case 1: // resource
+			return walkProto(data, func(num, typ int, _ uint64, data []byte) error {
+				if num != 1 || typ != wireBytes {
+					return nil
+				}
+				kv, err := decodeProtoKeyValue(data, 0)
+				rl.Resource.Attributes = append(rl.Resource.Attributes, kv)
+				return err
+			})
+		case 2: // scope_logs
+			var sl otlpScopeLogs
+			err := walkProto(data, func(num, typ int, _ uint64, data []byte) error {
+				if num != 2 || typ != wireBytes {
+					return nil
+				}
+				rec, err := decodeProtoLogRecord(data)
+				sl.LogRecords = append(sl.LogRecords, rec)
+				return err
+			})
+			rl.ScopeLogs = append(rl.ScopeLogs, sl)
+			return err
+		}
+		return nil
+	})
+	return rl, err
+}
+
+func decodeProtoLogRecord(b []byte) (otlpLogRecord, error) {
+	var rec otlpLogRecord
+	err := walkProto(b, func(num, typ int, v uint64, data []byte) error {
+		switch {
+		case num == 1 && typ == wireFixed64:
+			rec.TimeUnixNano = otlpUint64(v)
+		case num == 11 && typ == wireFixed64:
+			rec.ObservedTimeUnixNano = otlpUint64(v)
+		case num == 2 && typ == wireVarint:
+			rec.SeverityNumber = int(v)
+		case num == 3 && typ == wireBytes:
+			rec.SeverityText = string(data)
+		case num == 5 && typ == wireBytes:
+			value, err := decodeProtoAnyValue(data, 0)
+			rec.Body = value
+			return err
+		case num == 6 && typ == wireBytes:
+			kv, err := decodeProtoKeyValue(data, 0)
+			rec.Attributes = append(rec.Attributes, kv)
+			return err
+		case num == 9 && typ == wireBytes:
+			rec.TraceID = hex.EncodeToString(data)
+		case num == 10 && typ == wireBytes:
+			rec.SpanID = hex.EncodeToString(data)
+		}
+		return nil
+	})
+	return rec, err
+}
+
+func decodeProtoKeyValue(b []byte, depth int) (otlpKeyValue, error) {
+	var kv otlpKeyValue
+	err := walkProto(b, func(num, typ int, _ uint64, data []byte) error {
+		if typ != wireBytes {
+			return nil
+		}
+		switch num {
+		case 1:
+			kv.Key = string(data)
+		case 2:
+			value, err := decodeProtoAnyValue(data, depth)
+			kv.Value = value
+			return err
+		}
+		return nil
+	})
+	return kv, err
+}
+
+func decodeProtoAnyValue(b []byte, depth int) (otlpValue, error) {
+	var o otlpValue
+	if depth > maxValueDepth {
+		return o, errValueTooDeep
+	}
+	err := walkProto(b, func(num, typ int, v uint64, data []byte) error {
+		switch {
+		case num == 1 && typ == wireBytes:
+			o.v = string(data)
+		case num == 2 && typ == wireVarint:
+			o.v = v != 0
+		case num == 3 && typ == wireVarint:
+			o.v = int64(v)
+		case num == 4 && typ == wireFixed64:
+			o.v = math.Float64frombits(v)
+		case num == 7 && typ == wireBytes:
+			o.v = base64.StdEncoding.EncodeToString(data)
+		case num == 5 && typ == wireBytes: // ArrayValue
+			values := []any{}
+			err := walkProto(data, func(num, typ int, _ uint64, data []byte) error {
+				if num != 1 || typ != wireBytes {
+					return nil
+				}
+				value, err := decodeProtoAnyValue(data, depth+1)
+				values = append(values, value.v)
+				return err
+			})
+			o.v = values
+			return err
+		case num == 6 && typ == wireBytes: // KeyValueList
+			values := map[string]any{}
+			err := walkProto(data, func(num, typ int, _ uint64, data []byte) error {
+				if num != 1 || typ != wireBytes {
+					return nil
+				}
+				kv, err := decodeProtoKeyValue(data, depth+1)
+				values[kv.Key] = kv.Value.v
+				return err
+			})
+			o.v = values
+			return err
+		}
+		return nil
+	})
+	return o, err
+}
@@ -0,0 +1,129 @@
+package input
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/indalyadav56/logify/apps/agent/internal/config"
+)
+
+// Protobuf encoding helpers for building requests by hand.
+func pbKey(b []byte, num, typ int) []byte { return binary.AppendUvarint(b, uint64(num<<3|typ)) }
+
+func pbBytes(num int, data ...[]byte) []byte {
+	joined := bytes.Join(data, nil)
+	b := pbKey(nil, num, wireBytes)
+	b = binary.AppendUvarint(b, uint64(len(joined)))
+	return append(b, joined...)
+}
+
+func pbString(num int, s string) []byte { return pbBytes(num, []byte(s)) }
+
+func pbVarint(num int, v uint64) []byte { return binary.AppendUvarint(pbKey(nil, num, wireVarint), v) }
+
+func pbFixed64(num int, v uint64) []byte {
+	return binary.LittleEndian.AppendUint64(pbKey(nil, num, wireFixed64), v)
+}
+
+func pbAttr(key string, value []byte) []byte { return append(pbString(1, key), pbBytes(2, value)...) }
+
+func newTestOTLPInput() *OTLPInput {
+	return NewOTLPInput(config.InputConfig{Name: "otlp"}, Decoration{Service: "host-default"},
+		slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestOTLPInputProtobuf(t *testing.T) {
+	ts := time.Date(2026, 10, 19, 10, 0, 0, 123, time.UTC)
+	record := bytes.Join([][]byte{
+		pbFixed64(1, uint64(ts.UnixNano())),
+		pbVarint(2, 17), // SEVERITY_NUMBER_ERROR
+		pbString(3, "oops"),
+		pbBytes(5, pbString(1, "payment failed")),
+		pbBytes(6, pbAttr("http.status_code", pbVarint(3, 502))),
+		pbBytes(6, pbAttr("retry", pbVarint(2, 1))),
+		pbBytes(6, pbAttr("ratio", pbFixed64(4, math.Float64bits(0.5)))),
+		pbBytes(6, pbAttr("route", pbBytes(6, pbBytes(1, pbAttr("path", pbString(1, "/pay")))))),
+		pbBytes(9, []byte{0x5b, 0x8e, 0xff, 0xf7, 0x98, 0x03, 0x81, 0x03, 0xd2, 0x69, 0xb6, 0x33, 0x81, 0x3f, 0xc6, 0x0c}),
+		pbBytes(10, make([]byte, 8)), // an all-zero span ID means none
+	}, nil)
+	resource := bytes.Join([][]byte{
+		pbBytes(1, pbAttr("service.name", pbString(1, "checkout"))),
+		pbBytes(1, pbAttr("deployment.environment", pbString(1, "staging"))),
+		pbBytes(1, pbAttr("deployment.environment.name", pbString(1, "production"))),
+		pbBytes(1, pbAttr("host.name", pbString(1, "web-3"))),
+		pbBytes(1, pbAttr("k8s.pod.name", pbString(1, "checkout-1"))),
+	}, nil)
+	body := pbBytes(1, pbBytes(1, resource), pbBytes(2, pbBytes(1, pbString(1, "my.scope")), pbBytes(2, record)))
+
+	out := make(chan Event, 1)
+	req := httptest.NewRequest(http.MethodPost, "/v1/logs", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	rec := httptest.NewRecorder()
+	newTestOTLPInput().handle(context.Background(), out).ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+
+	ev := <-out
+	if ev.Line != "payment failed" || !ev.Time.Equal(ts) || ev.Hostname != "web-3" {
+		t.Errorf("event = %q at %v from %q", ev.Line, ev.Time, ev.Hostname)
+	}
+	if ev.TraceID != "5b8efff798038103d269b633813fc60c" || ev.SpanID != "" {
+		t.Errorf("trace/span = %q/%q", ev.TraceID, ev.SpanID)
+	}
+	if d := ev.Decoration; d.Service != "checkout" || d.Environment != "production" {
+		t.Errorf("service/environment = %q/%q", d.Service, d.Environment)
+	}
+	f := ev.Fields
+	if f["level"] != "ERROR" || f["http.status_code"] != int64(502) || f["retry"] != true || f["ratio"] != 0.5 {
+		t.Errorf("fields = %v", f)
+	}
+	if route, _ := f["route"].(map[string]any); route["path"] != "/pay" {
+		t.Errorf("route = %v", f["route"])
+	}
+	if f["resource.k8s.pod.name"] != "checkout-1" {
+		t.Errorf("resource attribute missing: %v", f)
+	}
+}
+
+func TestOTLPInputJSON(t *testing.T) {
+	body := `{"resourceLogs": [{"resource": {"attributes": [{"key": "service.name", "value": {"stringValue": "api"}}]},
+		"scopeLogs": [{"logRecords": [
+			{"observedTimeUnixNano": "1791540000000000000", "severityText": "Warning",
+			 "body": {"kvlistValue": {"values": [{"key": "msg", "value": {"stringValue": "slow query"}},
+				{"key": "ms", "value": {"intValue": "1500"}}]}},
+			 "traceId": "5b8efff798038103d269b633813fc60c", "spanId": "eee19b7ec3c1b174"}
+		]}]}]}`
+	out := make(chan Event, 1)
+	req := httptest.NewRequest(http.MethodPost, "/v1/logs", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	rec := httptest.NewRecorder()
+	newTestOTLPInput().handle(context.Background(), out).ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || rec.Body.String() != "{}" {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+
+	ev := <-out
+	if ev.Fields["msg"] != "slow query" || ev.Fields["ms"] != int64(1500) || ev.Fields["level"] != "Warning" {
+		t.Errorf("fields = %v", ev.Fields)
+	}
+	if ev.Time.UnixNano() != 1791540000000000000 || ev.SpanID != "eee19b7ec3c1b174" || ev.Decoration.Service != "api" {
+		t.Errorf("event = %+v", ev)
+	}
+}
+
+func TestWalkProtoRejectsTruncated(t *testing.T) {
+	body := pbBytes(1, pbBytes(2, pbBytes(2, pbString(3, "INFO"))))
+	if _, err := decodeOTLPProto(body[:len(body)-2]); err == nil {
+		t.Fatal("decoded a truncated request")
+	}
+}
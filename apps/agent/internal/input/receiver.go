@@ -0,0 +1,202 @@
+package input
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// maxRequestBody bounds a request to one of the HTTP receivers.
+	maxRequestBody = 10 << 20
+
+	// connIdleTimeout closes a stream connection that has sent nothing for
+	// this long; senders reconnect when they have more to say.
+	connIdleTimeout = 5 * time.Minute
+
+	// maxConns bounds the connections a stream receiver serves at once.
+	// Connections beyond it are closed as soon as they are accepted.
+	maxConns = 1024
+)
+
+// labels are what a log sent to a receiver says about where it comes from.
+type labels struct {
+	Service, Namespace, Environment, Source, ProjectID string
+	Tags                                               map[string]string
+}
+
+// with returns d with the labels set in l, which win, and l's tags added.
+func (d Decoration) with(l labels) Decoration {
+	pick := func(a, b string) string {
+		if a != "" {
+			return a
+		}
+		return b
+	}
+	d.Service = pick(l.Service, d.Service)
+	d.Namespace = pick(l.Namespace, d.Namespace)
+	d.Environment = pick(l.Environment, d.Environment)
+	d.Source = pick(l.Source, d.Source)
+	d.ProjectID = pick(l.ProjectID, d.ProjectID)
+	if len(l.Tags) > 0 {
+		tags := maps.Clone(d.Tags)
+		if tags == nil {
+			tags = make(map[string]string, len(l.Tags))
+		}
+		maps.Copy(tags, l.Tags)
+		d.Tags = tags
+	}
+	return d
+}
+
+// sendAll hands events to the pipeline, giving up once ctx or done is.
+func sendAll(ctx context.Context, done <-chan struct{}, out chan<- Event, events []Event) error {
+	for _, ev := range events {
+		select {
+		case out <- ev:
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-done:
+			return context.Canceled
+		}
+	}
+	return nil
+}
+
+// readBody reads a request's body, inflating it if gzipped. Bodies larger
+// than maxRequestBody, before or after inflating, are refused.
+func readBody(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	var body io.Reader = http.MaxBytesReader(w, r.Body, maxRequestBody)
+	switch enc := r.Header.Get("Content-Encoding"); enc {
+	case "", "identity":
+	case "gzip":
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		body = io.LimitReader(gz, maxRequestBody+1)
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", enc)
+	}
+	b, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > maxRequestBody {
+		return nil, fmt.Errorf("request body larger than %d bytes", maxRequestBody)
+	}
+	return b, nil
+}
+
+// serveHTTP serves h on addr until ctx is cancelled.
+func serveHTTP(ctx context.Context, addr string, h http.Handler, log *slog.Logger) error {
+	srv := &http.Server{Addr: addr, Handler: h, ReadHeaderTimeout: 10 * time.Second}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	log.Info("listening", "addr", ln.Addr().String())
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return ctx.Err()
+}
+
+// serveConns accepts connections on ln until ctx is cancelled, handling each
+// on its own goroutine. A connection idle for connIdleTimeout is closed, as
+// are those beyond maxConns. On shutdown it closes them and waits for handle
+// to return.
+func serveConns(ctx context.Context, ln net.Listener, log *slog.Logger, handle func(net.Conn) error) error {
+	return serveConnsLimited(ctx, ln, log, maxConns, connIdleTimeout, handle)
+}
+
+func serveConnsLimited(ctx context.Context, ln net.Listener, log *slog.Logger, limit int, idle time.Duration, handle func(net.Conn) error) error {
+	var (
+		mu    sync.Mutex
+		conns = map[net.Conn]struct{}{}
+		wg    sync.WaitGroup
+	)
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+		mu.Lock()
+		for c := range conns {
+			c.Close()
+		}
+		mu.Unlock()
+	}()
+	log.Info("listening", "addr", ln.Addr().String())
+
+	defer wg.Wait()
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		mu.Lock()
+		if ctx.Err() != nil {
+			mu.Unlock()
+			conn.Close()
+			return ctx.Err()
+		}
+		if len(conns) >= limit {
+			mu.Unlock()
+			log.Warn("too many connections, refusing one", "peer", conn.RemoteAddr().String(), "limit", limit)
+			conn.Close()
+			continue
+		}
+		conns[conn] = struct{}{}
+		mu.Unlock()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := handle(idleConn{Conn: conn, idle: idle})
+			switch {
+			case err == nil || ctx.Err() != nil:
+			case errors.Is(err, os.ErrDeadlineExceeded):
+				log.Debug("idle connection closed", "peer", conn.RemoteAddr().String())
+			default:
+				log.Warn("connection closed", "peer", conn.RemoteAddr().String(), "err", err)
+			}
+			conn.Close()
+			mu.Lock()
+			delete(conns, conn)
+			mu.Unlock()
+		}()
+	}
+}
+
+// idleConn is a connection whose reads fail once it has been idle for the
+// given time.
+type idleConn struct {
+	net.Conn
+	idle time.Duration
+}
+
+func (c idleConn) Read(p []byte) (int, error) {
+	if err := c.Conn.SetReadDeadline(time.Now().Add(c.idle)); err != nil {
+		return 0, err
+	}
+	return c.Conn.Read(p)
+}
@@ -0,0 +1,195 @@
+package input
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/indalyadav56/logify/apps/agent/internal/config"
+)
+
+// maxSyslogMessage bounds a message read from a stream or a datagram.
+const maxSyslogMessage = 64 * 1024
+
+// SyslogInput is a syslog server for network gear and daemons that speak
+// nothing else. It listens on UDP, TCP or TLS and parses RFC 5424 and BSD
+// (RFC 3164) messages. Streams may frame messages by octet counting or by
+// newlines (RFC 6587). Syslog senders do not resend, so events are not
+// acknowledged.
+type SyslogInput struct {
+	name     string
+	protocol string
+	address  string
+	tls      *config.TLSConfig
+	dec      Decoration
+	log      *slog.Logger
+}
+
+// NewSyslogInput builds a syslog server from its input config.
+func NewSyslogInput(in config.InputConfig, dec Decoration, log *slog.Logger) *SyslogInput {
+	return &SyslogInput{
+		name:     in.Name,
+		protocol: in.Protocol,
+		address:  in.Address,
+		tls:      in.TLS,
+		dec:      dec,
+		log:      log.With("input", in.Name),
+	}
+}
+
+func (s *SyslogInput) Name() string { return s.name }
+
+func (s *SyslogInput) Run(ctx context.Context, out chan<- Event) error {
+	if s.protocol == "udp" {
+		conn, err := net.ListenPacket("udp", s.address)
+		if err != nil {
+			return err
+		}
+		return s.serveUDP(ctx, conn, out)
+	}
+
+	ln, err := net.Listen("tcp", s.address)
+	if err != nil {
+		return err
+	}
+	if s.protocol == "tls" {
+		cfg, err := serverTLSConfig(s.tls)
+		if err != nil {
+			ln.Close()
+			return err
+		}
+		ln = tls.NewListener(ln, cfg)
+	}
+	return s.serveStream(ctx, ln, out)
+}
+
+func serverTLSConfig(c *config.TLSConfig) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load tls certificate: %w", err)
+	}
+	cfg := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	if c.ClientCAFile != "" {
+		pem, err := os.ReadFile(c.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read client CA: %w", err)
+		}
+		cfg.ClientCAs = x509.NewCertPool()
+		if !cfg.ClientCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in %s", c.ClientCAFile)
+		}
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return cfg, nil
+}
+
+func (s *SyslogInput) serveUDP(ctx context.Context, conn net.PacketConn, out chan<- Event) error {
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+	s.log.Info("listening for syslog", "protocol", "udp", "addr", conn.LocalAddr().String())
+
+	buf := make([]byte, maxSyslogMessage)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		if !s.emit(ctx, out, string(buf[:n]), addr) {
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *SyslogInput) serveStream(ctx context.Context, ln net.Listener, out chan<- Event) error {
+	return serveConns(ctx, ln, s.log.With("protocol", s.protocol), func(conn net.Conn) error {
+		return s.readStream(ctx, conn, conn.RemoteAddr(), out)
+	})
+}
+
+// readStream reads messages from a stream until it ends. Each message is
+// framed either by octet counting ("<length> <message>") or by a trailing
+// newline, told apart by its first byte: a digit or '<'.
+func (s *SyslogInput) readStream(ctx context.Context, r io.Reader, peer net.Addr, out chan<- Event) error {
+	br := bufio.NewReaderSize(r, maxSyslogMessage)
+	for {
+		first, err := br.Peek(1)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		var msg string
+		if first[0] >= '0' && first[0] <= '9' {
+			prefix, err := br.ReadString(' ')
+			if err != nil {
+				return err
+			}
+			n, err := strconv.Atoi(prefix[:len(prefix)-1])
+			if err != nil || n <= 0 || n > maxSyslogMessage {
+				return fmt.Errorf("invalid message length %q", prefix)
+			}
+			buf := make([]byte, n)
+			if _, err := io.ReadFull(br, buf); err != nil {
+				return err
+			}
+			msg = string(buf)
+		} else {
+			line, err := br.ReadSlice('\n')
+			switch {
+			case errors.Is(err, bufio.ErrBufferFull):
+				return fmt.Errorf("message longer than %d bytes", maxSyslogMessage)
+			case errors.Is(err, io.EOF) && len(line) > 0:
+			case err != nil:
+				return err
+			}
+			msg = string(line)
+		}
+		if !s.emit(ctx, out, msg, peer) {
+			return nil
+		}
+	}
+}
+
+// emit parses a message and sends it on. It reports false once ctx is done.
+func (s *SyslogInput) emit(ctx context.Context, out chan<- Event, raw string, peer net.Addr) bool {
+	m := parseSyslog(raw, time.Now())
+	if m.Message == "" && len(m.Structured) == 0 {
+		return true
+	}
+	ev := Event{
+		Input:      s.name,
+		Line:       m.Message,
+		Time:       m.Time,
+		Hostname:   m.Hostname,
+		Fields:     m.fields(),
+		Decoration: s.dec,
+	}
+	if ev.Hostname == "" && peer != nil {
+		// The sender did not name itself: use its address.
+		if host, _, err := net.SplitHostPort(peer.String()); err == nil {
+			ev.Hostname = host
+		}
+	}
+	select {
+	case out <- ev:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
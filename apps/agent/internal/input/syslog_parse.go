@@ -0,0 +1,236 @@
+package input
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// syslogMessage is a parsed syslog message in either format.
+type syslogMessage struct {
+	Facility int
+	Severity int
+	Time     time.Time // zero if the message carries none
+	Hostname string
+	AppName  string
+	ProcID   string
+	MsgID    string
+	// Structured maps each RFC 5424 SD-ID to its parameters.
+	Structured map[string]map[string]string
+	Message    string
+}
+
+var facilityNames = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console", "solaris-cron",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+
+var severityNames = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+var errNoPriority = errors.New("syslog: missing priority")
+
+// parseSyslog parses an RFC 5424 message, falling back to the BSD format of
+// RFC 3164, which is loose enough that anything with a priority parses.
+// Messages without a priority are user.notice, per RFC 3164.
+func parseSyslog(raw string, now time.Time) syslogMessage {
+	raw = strings.TrimRight(raw, "\r\n\x00")
+	pri, rest, err := parsePriority(raw)
+	if err != nil {
+		return syslogMessage{Facility: 1, Severity: 5, Message: raw}
+	}
+	m := syslogMessage{Facility: pri / 8, Severity: pri % 8}
+	if body, ok := strings.CutPrefix(rest, "1 "); ok && parseRFC5424(&m, body) {
+		return m
+	}
+	parseRFC3164(&m, rest, now)
+	return m
+}
+
+func parsePriority(s string) (int, string, error) {
+	if !strings.HasPrefix(s, "<") {
+		return 0, s, errNoPriority
+	}
+	end := strings.IndexByte(s, '>')
+	if end < 2 || end > 4 {
+		return 0, s, errNoPriority
+	}
+	pri, err := strconv.Atoi(s[1:end])
+	if err != nil || pri > 191 {
+		return 0, s, errNoPriority
+	}
+	return pri, s[end+1:], nil
+}
+
+// parseRFC5424 parses what follows "<PRI>1 ": TIMESTAMP HOSTNAME APP-NAME
+// PROCID MSGID STRUCTURED-DATA [MSG], where "-" stands for a missing value.
+func parseRFC5424(m *syslogMessage, s string) bool {
+	var header [5]string
+	for i := range header {
+		var ok bool
+		header[i], s, ok = strings.Cut(s, " ")
+		if !ok || header[i] == "" {
+			return false
+		}
+	}
+	if header[0] != "-" {
+		t, err := time.Parse(time.RFC3339Nano, header[0])
+		if err != nil {
+			return false
+		}
+		m.Time = t
+	}
+	m.Hostname, m.AppName, m.ProcID, m.MsgID = nilValue(header[1]), nilValue(header[2]), nilValue(header[3]), nilValue(header[4])
+
+	if rest, ok := strings.CutPrefix(s, "-"); ok {
+		s = rest
+	} else {
+		sd, rest, ok := parseStructuredData(s)
+		if !ok {
+			return false
+		}
+		m.Structured, s = sd, rest
+	}
+	if s != "" && s[0] != ' ' {
+		return false
+	}
+	msg := strings.TrimPrefix(s, " ")
+	m.Message = strings.TrimPrefix(msg, "\ufeff") // UTF-8 byte order mark
+	return true
+}
+
+func nilValue(s string) string {
+	if s == "-" {
+		return ""
+	}
+	return s
+}
+
+// parseStructuredData parses one or more [SD-ID PARAM="VALUE" ...] elements.
+// In values, '"', '\' and ']' are escaped with a backslash.
+func parseStructuredData(s string) (map[string]map[string]string, string, bool) {
+	sd := map[string]map[string]string{}
+	for strings.HasPrefix(s, "[") {
+		s = s[1:]
+		end := strings.IndexAny(s, " ]")
+		if end <= 0 {
+			return nil, "", false
+		}
+		id := s[:end]
+		params := map[string]string{}
+		s = s[end:]
+		for strings.HasPrefix(s, " ") {
+			s = s[1:]
+			name, rest, ok := strings.Cut(s, `="`)
+			if !ok || name == "" {
+				return nil, "", false
+			}
+			var value strings.Builder
+			i := 0
+			for ; i < len(rest) && rest[i] != '"'; i++ {
+				if rest[i] == '\\' && i+1 < len(rest) && strings.IndexByte(`"\]`, rest[i+1]) >= 0 {
+					i++
+				}
+				value.WriteByte(rest[i])
+			}
+			if i == len(rest) {
+				return nil, "", false
+			}
+			params[name] = value.String()
+			s = rest[i+1:]
+		}
+		if !strings.HasPrefix(s, "]") {
+			return nil, "", false
+		}
+		s = s[1:]
+		sd[id] = params
+	}
+	return sd, s, true
+}
+
+// bsdLayouts are the timestamps of RFC 3164 messages, which lack a year.
+// Many daemons send an RFC 3339 timestamp in its place, which is tried first.
+var bsdLayouts = []string{time.StampMicro, time.StampMilli, time.Stamp}
+
+// parseRFC3164 parses what follows "<PRI>": TIMESTAMP HOSTNAME TAG: MSG. Each
+// part is optional in the wild; what cannot be recognised is left in the
+// message.
+func parseRFC3164(m *syslogMessage, s string, now time.Time) {
+	if t, rest, ok := bsdTimestamp(s, now); ok {
+		m.Time, s = t, strings.TrimPrefix(rest, " ")
+
+		// A hostname follows the timestamp unless the next word is the tag.
+		if word, rest, ok := strings.Cut(s, " "); ok && word != "" && !isTag(word) {
+			m.Hostname, s = word, rest
+		}
+	}
+	if word, rest, ok := strings.Cut(s, " "); ok && isTag(word) {
+		tag := strings.TrimSuffix(word, ":")
+		if name, pid, ok := strings.Cut(tag, "["); ok {
+			m.AppName, m.ProcID = name, strings.TrimSuffix(pid, "]")
+		} else {
+			m.AppName = tag
+		}
+		s = rest
+	}
+	m.Message = s
+}
+
+func bsdTimestamp(s string, now time.Time) (time.Time, string, bool) {
+	if word, rest, ok := strings.Cut(s, " "); ok {
+		if t, err := time.Parse(time.RFC3339Nano, word); err == nil {
+			return t, " " + rest, true
+		}
+	}
+	for _, layout := range bsdLayouts {
+		// Days are space-padded: "Jan  2 15:04:05".
+		if len(s) < len(layout) {
+			continue
+		}
+		t, err := time.ParseInLocation(layout, s[:len(layout)], now.Location())
+		if err != nil {
+			continue
+		}
+		// No year: take the one that puts the message closest to now, so
+		// December's messages read in January land in the year before.
+		t = t.AddDate(now.Year(), 0, 0)
+		if t.After(now.AddDate(0, 1, 0)) {
+			t = t.AddDate(-1, 0, 0)
+		}
+		return t, s[len(layout):], true
+	}
+	return time.Time{}, s, false
+}
+
+// isTag reports whether a word is an RFC 3164 tag such as "sshd[42]:" or
+// "cron:".
+func isTag(word string) bool {
+	name, ok := strings.CutSuffix(word, ":")
+	return ok && name != "" && !strings.ContainsAny(name, ":/")
+}
+
+// fields returns the message's parts for the parser, besides the message and
+// time: level, facility and severity names, the RFC 5424 header and the
+// structured data keyed by SD-ID.
+func (m syslogMessage) fields() map[string]any {
+	fields := map[string]any{
+		"message":  m.Message,
+		"level":    priorityToLevel(strconv.Itoa(m.Severity)),
+		"facility": facilityNames[m.Facility],
+		"severity": severityNames[m.Severity],
+	}
+	for k, v := range map[string]string{"app_name": m.AppName, "proc_id": m.ProcID, "msg_id": m.MsgID} {
+		if v != "" {
+			fields[k] = v
+		}
+	}
+	for id, params := range m.Structured {
+		values := make(map[string]any, len(params))
+		for k, v := range params {
+			values[k] = v
+		}
+		fields[id] = values
+	}
+	return fields
+}
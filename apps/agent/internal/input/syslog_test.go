@@ -0,0 +1,182 @@
+package input
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/indalyadav56/logify/apps/agent/internal/config"
+)
+
+func TestParseSyslogRFC5424(t *testing.T) {
+	raw := `<165>1 2026-10-11T22:14:15.003Z mymachine.example.com evntslog 42 ID47 ` +
+		`[exampleSDID@32473 iut="3" eventSource="App\"lication\]"][origin ip="192.0.2.1"] ` +
+		"\ufeffAn application event log entry..."
+	m := parseSyslog(raw, time.Now())
+
+	if m.Facility != 20 || m.Severity != 5 {
+		t.Fatalf("facility/severity = %d/%d, want 20/5", m.Facility, m.Severity)
+	}
+	want := time.Date(2026, 10, 11, 22, 14, 15, 3_000_000, time.UTC)
+	if !m.Time.Equal(want) {
+		t.Errorf("time = %v, want %v", m.Time, want)
+	}
+	if m.Hostname != "mymachine.example.com" || m.AppName != "evntslog" || m.ProcID != "42" || m.MsgID != "ID47" {
+		t.Errorf("header = %q %q %q %q", m.Hostname, m.AppName, m.ProcID, m.MsgID)
+	}
+	if got := m.Structured["exampleSDID@32473"]["eventSource"]; got != `App"lication]` {
+		t.Errorf("eventSource = %q", got)
+	}
+	if got := m.Structured["origin"]["ip"]; got != "192.0.2.1" {
+		t.Errorf("origin ip = %q", got)
+	}
+	if m.Message != "An application event log entry..." {
+		t.Errorf("message = %q", m.Message)
+	}
+
+	fields := m.fields()
+	if fields["level"] != "INFO" || fields["facility"] != "local4" || fields["severity"] != "notice" {
+		t.Errorf("fields = %v", fields)
+	}
+}
+
+func TestParseSyslogRFC5424NilValues(t *testing.T) {
+	m := parseSyslog("<11>1 - - - - - -", time.Now())
+	if !m.Time.IsZero() || m.Hostname != "" || m.Message != "" || m.Severity != 3 {
+		t.Fatalf("parsed = %+v", m)
+	}
+}
+
+func TestParseSyslogRFC3164(t *testing.T) {
+	now := time.Date(2026, 1, 3, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		raw                     string
+		host, app, pid, message string
+		time                    time.Time
+	}{
+		{
+			raw:  "<34>Oct 11 22:14:15 mymachine su: 'su root' failed for lonvick on /dev/pts/8",
+			host: "mymachine", app: "su", message: "'su root' failed for lonvick on /dev/pts/8",
+			time: time.Date(2025, 10, 11, 22, 14, 15, 0, time.UTC), // last year's October
+		},
+		{
+			raw: "<86>Jan  3 11:59:58 sshd[1234]: Accepted publickey for deploy",
+			app: "sshd", pid: "1234", message: "Accepted publickey for deploy",
+			time: time.Date(2026, 1, 3, 11, 59, 58, 0, time.UTC),
+		},
+		{
+			raw:  "<13>2026-01-03T11:00:00Z router1 link down on ge-0/0/1",
+			host: "router1", message: "link down on ge-0/0/1",
+			time: time.Date(2026, 1, 3, 11, 0, 0, 0, time.UTC),
+		},
+		{raw: "no priority at all", message: "no priority at all"},
+	}
+	for _, c := range cases {
+		m := parseSyslog(c.raw, now)
+		if m.Hostname != c.host || m.AppName != c.app || m.ProcID != c.pid || m.Message != c.message {
+			t.Errorf("%q: parsed host=%q app=%q pid=%q message=%q", c.raw, m.Hostname, m.AppName, m.ProcID, m.Message)
+		}
+		if !m.Time.Equal(c.time) {
+			t.Errorf("%q: time = %v, want %v", c.raw, m.Time, c.time)
+		}
+	}
+}
+
+func TestSyslogStreamFraming(t *testing.T) {
+	msg := "<14>1 2026-10-11T22:14:15Z host app - - - octet\ncounted"
+	stream := "<13>Oct 11 22:14:15 web1 app: first\n" +
+		strconv.Itoa(len(msg)) + " " + msg +
+		"<13>Oct 11 22:14:16 web1 app: last"
+
+	s := NewSyslogInput(config.InputConfig{Name: "syslog"}, Decoration{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	out := make(chan Event, 3)
+	peer := &net.TCPAddr{IP: net.ParseIP("192.0.2.7"), Port: 514}
+	if err := s.readStream(context.Background(), strings.NewReader(stream), peer, out); err != nil {
+		t.Fatal(err)
+	}
+	close(out)
+
+	var got []Event
+	for ev := range out {
+		got = append(got, ev)
+	}
+	if len(got) != 3 {
+		t.Fatalf("got %d events, want 3", len(got))
+	}
+	if got[0].Line != "first" || got[0].Hostname != "web1" {
+		t.Errorf("first event = %q from %q", got[0].Line, got[0].Hostname)
+	}
+	if got[1].Line != "octet\ncounted" || got[1].Hostname != "host" {
+		t.Errorf("octet-counted event = %q from %q", got[1].Line, got[1].Hostname)
+	}
+	if got[2].Line != "last" {
+		t.Errorf("unterminated last event = %q", got[2].Line)
+	}
+}
+
+func TestSyslogUsesPeerWithoutHostname(t *testing.T) {
+	s := NewSyslogInput(config.InputConfig{Name: "syslog"}, Decoration{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	out := make(chan Event, 1)
+	s.emit(context.Background(), out, "<13>link flapped", &net.UDPAddr{IP: net.ParseIP("192.0.2.9"), Port: 40000})
+	if ev := <-out; ev.Hostname != "192.0.2.9" {
+		t.Fatalf("hostname = %q, want the sender's address", ev.Hostname)
+	}
+}
+
+func TestStreamConnectionsAreCappedAndClosedWhenIdle(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	accepted := make(chan struct{}, 2)
+	done := make(chan error, 1)
+	go func() {
+		done <- serveConnsLimited(ctx, ln, slog.New(slog.NewTextHandler(io.Discard, nil)), 1, 100*time.Millisecond, func(conn net.Conn) error {
+			accepted <- struct{}{}
+			_, err := io.Copy(io.Discard, conn)
+			return err
+		})
+	}()
+
+	first, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer first.Close()
+	<-accepted
+	start := time.Now()
+
+	second, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer second.Close()
+	second.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := second.Read(make([]byte, 1)); err != io.EOF {
+		t.Errorf("connection over the cap: read err = %v, want EOF", err)
+	}
+	select {
+	case <-accepted:
+		t.Error("a connection over the cap was handled")
+	default:
+	}
+
+	first.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := first.Read(make([]byte, 1)); err != io.EOF {
+		t.Errorf("idle connection: read err = %v, want EOF", err)
+	}
+	if waited := time.Since(start); waited < 100*time.Millisecond {
+		t.Errorf("idle connection closed after %v, before the idle timeout", waited)
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("serveConns returned %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+// Package metrics exposes the agent's own health (queue depth, disk usage,
+// drops) in the Prometheus text format. Values are read when scraped, from
+// functions registered by the parts of the agent that own them.
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ContentType is the content type of Write's output.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+type metric struct {
+	name, help, kind string
+	value            func() float64
+}
+
+// Registry is a set of metrics. It is safe for concurrent use.
+type Registry struct {
+	mu      sync.Mutex
+	metrics []metric
+}
+
+func NewRegistry() *Registry { return &Registry{} }
+
+// Gauge registers a metric whose value may go up and down.
+func (r *Registry) Gauge(name, help string, value func() float64) {
+	r.add(metric{name: name, help: help, kind: "gauge", value: value})
+}
+
+// Counter registers a metric whose value only goes up.
+func (r *Registry) Counter(name, help string, value func() float64) {
+	r.add(metric{name: name, help: help, kind: "counter", value: value})
+}
+
+func (r *Registry) add(m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics = append(r.metrics, m)
+}
+
+// Write renders every metric, in registration order.
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	metrics := append([]metric(nil), r.metrics...)
+	r.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	for _, m := range metrics {
+		bw.WriteString("# HELP " + m.name + " " + m.help + "\n")
+		bw.WriteString("# TYPE " + m.name + " " + m.kind + "\n")
+		bw.WriteString(m.name + " " + strconv.FormatFloat(m.value(), 'g', -1, 64) + "\n")
+	}
+	return bw.Flush()
+}
+
+// ServeHTTP serves the metrics.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	r.Write(w)
+}
+
+// Serve exposes reg on addr at /metrics until ctx is cancelled.
+func Serve(ctx context.Context, addr string, reg *Registry, log *slog.Logger) error {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", reg)
+	srv := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	log.Info("serving metrics", "addr", addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
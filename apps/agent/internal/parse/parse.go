@@ -37,6 +37,11 @@ func ToEntry(ev input.Event) logify.Entry {
 		Source:      d.Source,
 		ProjectID:   d.ProjectID,
 		Timestamp:   ev.Time,
+		Hostname:    ev.Hostname,
+		TraceID:     ev.TraceID,
+		SpanID:      ev.SpanID,
+		RequestID:   ev.RequestID,
+		UserID:      ev.UserID,
 		Tags:        d.Tags,
 		Message:     ev.Line,
 	}
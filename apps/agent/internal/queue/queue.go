@@ -0,0 +1,625 @@
+// Package queue is the agent's on-disk write-ahead queue. Logs read by the
+// inputs are appended to segment files and shipped from there, so they
+// survive a backend outage of any length and a restart of the agent.
+//
+// A segment is a sequence of frames, each a 4-byte length, a 4-byte CRC-32
+// of the payload and the JSON payload. Records are handed out in the order
+// they were pushed; the cursor marks how far they were delivered and is
+// checkpointed beside the segments. Fully delivered segments are deleted.
+package queue
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	logify "github.com/indalyadav56/logify/sdks/go"
+)
+
+var (
+	// ErrFull is returned by Push when the queue is at its size limit and
+	// the overflow policy is to drop the new record, or the record alone is
+	// larger than the limit.
+	ErrFull = errors.New("queue: full")
+	// ErrClosed is returned once the queue is closed.
+	ErrClosed = errors.New("queue: closed")
+)
+
+// Overflow policies: what Push does when the queue is at its size limit.
+const (
+	OverflowBlock      = "block"       // wait until delivered segments free space
+	OverflowDropOldest = "drop_oldest" // delete the oldest segment, delivered or not
+	OverflowDropNewest = "drop_newest" // refuse the new record
+)
+
+// FSync policies: when appended records are flushed to disk.
+const (
+	FSyncAlways   = "always"
+	FSyncInterval = "interval"
+	FSyncNever    = "never"
+)
+
+const (
+	frameHeader = 8
+	maxPayload  = 64 << 20 // anything larger is a torn or corrupt header
+	segmentExt  = ".seg"
+	cursorFile  = "cursor.json"
+)
+
+// Record is one log waiting for delivery.
+type Record struct {
+	Input string       `json:"input"`
+	Entry logify.Entry `json:"entry"`
+}
+
+// Options configures a Queue.
+type Options struct {
+	Dir           string
+	MaxSize       int64
+	SegmentSize   int64
+	SegmentAge    time.Duration
+	Overflow      string
+	FSync         string
+	FSyncInterval time.Duration
+}
+
+// Stats is a snapshot of the queue's size.
+type Stats struct {
+	Depth    int   // records not yet delivered
+	Bytes    int64 // bytes on disk, delivered records included
+	Segments int
+	Dropped  uint64 // records lost to the overflow policy
+}
+
+type segment struct {
+	id        uint64
+	size      int64
+	records   int
+	committed int // records delivered, or skipped by drop_oldest
+	created   time.Time
+}
+
+func (s *segment) path(dir string) string {
+	return filepath.Join(dir, fmt.Sprintf("%020d%s", s.id, segmentExt))
+}
+
+// position is a place in the queue: a segment and a byte offset within it.
+type position struct {
+	Segment uint64 `json:"segment"`
+	Offset  int64  `json:"offset"`
+}
+
+type popped struct {
+	seg   *segment
+	end   int64
+	acked bool
+}
+
+// Queue is a persistent FIFO of Records. It is safe for concurrent use.
+type Queue struct {
+	opts Options
+
+	mu       sync.Mutex
+	segments []*segment // oldest first; the last one is written to
+	writer   *os.File
+	dirty    bool // appended since the last fsync
+
+	read       position
+	reader     *os.File
+	readerSeg  uint64
+	cursor     position // everything before it is delivered
+	cursorSync position // cursor as last written to disk
+	pending    []popped // popped but not committed, in pop order
+	first      uint64   // sequence number of pending[0]
+	next       uint64
+	depth      int
+	bytes      int64
+	closed     bool
+	wake       chan struct{} // closed and replaced whenever Push or Pop may proceed
+
+	dropped atomic.Uint64
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// Open opens the queue in opts.Dir, creating it if needed. Records left
+// undelivered by the previous run come out of Pop first, in order. A record
+// torn by a crash mid-write is discarded.
+func Open(opts Options) (*Queue, error) {
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return nil, err
+	}
+	q := &Queue{
+		opts: opts,
+		wake: make(chan struct{}),
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	if err := q.recover(); err != nil {
+		return nil, err
+	}
+	go q.flushLoop()
+	return q, nil
+}
+
+func (q *Queue) recover() error {
+	ids, err := q.segmentIDs()
+	if err != nil {
+		return err
+	}
+	if raw, err := os.ReadFile(filepath.Join(q.opts.Dir, cursorFile)); err == nil {
+		if err := json.Unmarshal(raw, &q.cursor); err != nil {
+			return fmt.Errorf("queue: read cursor: %w", err)
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	for _, id := range ids {
+		s := &segment{id: id, created: time.Now()}
+		if id < q.cursor.Segment {
+			// Delivered before the crash, but not yet deleted.
+			if err := os.Remove(s.path(q.opts.Dir)); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := q.scan(s); err != nil {
+			return err
+		}
+		q.segments = append(q.segments, s)
+		q.bytes += s.size
+		q.depth += s.records - s.committed
+	}
+	// The cursor's segment may have been fully delivered and deleted.
+	if len(q.segments) == 0 {
+		q.cursor.Offset = 0
+	} else if q.segments[0].id != q.cursor.Segment {
+		q.cursor = position{Segment: q.segments[0].id}
+	}
+	q.read, q.cursorSync = q.cursor, q.cursor
+
+	// Append to a fresh segment rather than after whatever the crash left.
+	next := q.cursor.Segment
+	if n := len(q.segments); n > 0 {
+		next = q.segments[n-1].id + 1
+	}
+	return q.startSegment(next)
+}
+
+func (q *Queue) segmentIDs() ([]uint64, error) {
+	entries, err := os.ReadDir(q.opts.Dir)
+	if err != nil {
+		return nil, err
+	}
+	var ids []uint64
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), segmentExt)
+		if !ok {
+			continue
+		}
+		id, err := strconv.ParseUint(name, 10, 64)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
+
+// scan counts the records of a segment, and those before the cursor, and
+// truncates it after the last intact frame.
+func (q *Queue) scan(s *segment) error {
+	f, err := os.OpenFile(s.path(q.opts.Dir), os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var off int64
+	for {
+		_, n, err := readFrame(f, off)
+		if err != nil {
+			break
+		}
+		off += n
+		s.records++
+		if s.id == q.cursor.Segment && off <= q.cursor.Offset {
+			s.committed++
+		}
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() != off {
+		if err := f.Truncate(off); err != nil {
+			return err
+		}
+	}
+	s.size = off
+	return nil
+}
+
+// readFrame reads the frame at off, returning its payload and length.
+func readFrame(r io.ReaderAt, off int64) ([]byte, int64, error) {
+	var header [frameHeader]byte
+	if _, err := r.ReadAt(header[:], off); err != nil {
+		return nil, 0, err
+	}
+	size := binary.BigEndian.Uint32(header[:4])
+	if size > maxPayload {
+		return nil, 0, errors.New("queue: corrupt frame")
+	}
+	payload := make([]byte, size)
+	if _, err := r.ReadAt(payload, off+frameHeader); err != nil {
+		return nil, 0, err
+	}
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:]) {
+		return nil, 0, errors.New("queue: checksum mismatch")
+	}
+	return payload, frameHeader + int64(size), nil
+}
+
+func (q *Queue) startSegment(id uint64) error {
+	s := &segment{id: id, created: time.Now()}
+	f, err := os.OpenFile(s.path(q.opts.Dir), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if q.writer != nil {
+		q.syncLocked()
+		q.writer.Close()
+	}
+	q.writer = f
+	q.segments = append(q.segments, s)
+	return nil
+}
+
+func (q *Queue) head() *segment { return q.segments[len(q.segments)-1] }
+
+// Push appends a record. At the size limit it follows the overflow policy:
+// block waits for space until ctx is done, drop_newest returns ErrFull and
+// drop_oldest discards the oldest segment to make room.
+func (q *Queue) Push(ctx context.Context, rec Record) error {
+	payload, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	frame := make([]byte, frameHeader+len(payload))
+	binary.BigEndian.PutUint32(frame[:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(frame[4:8], crc32.ChecksumIEEE(payload))
+	copy(frame[frameHeader:], payload)
+	size := int64(len(frame))
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if size > q.opts.MaxSize {
+		q.dropped.Add(1)
+		return ErrFull
+	}
+	for {
+		if q.closed {
+			return ErrClosed
+		}
+		if q.bytes+size <= q.opts.MaxSize {
+			break
+		}
+		switch q.opts.Overflow {
+		case OverflowDropNewest:
+			q.dropped.Add(1)
+			return ErrFull
+		case OverflowDropOldest:
+			if err := q.dropOldest(); err != nil {
+				return err
+			}
+			continue
+		}
+		// A delivered head segment is only deleted once it is no longer
+		// written to.
+		if h := q.head(); h.size > 0 && h.committed == h.records {
+			if err := q.startSegment(h.id + 1); err != nil {
+				return err
+			}
+			q.gc()
+			continue
+		}
+		wake := q.wake
+		q.mu.Unlock()
+		select {
+		case <-wake:
+			q.mu.Lock()
+		case <-ctx.Done():
+			q.mu.Lock()
+			return ctx.Err()
+		}
+	}
+
+	if h := q.head(); h.size > 0 && h.size+size > q.opts.SegmentSize {
+		if err := q.startSegment(h.id + 1); err != nil {
+			return err
+		}
+	}
+	if _, err := q.writer.Write(frame); err != nil {
+		// Whatever part of the frame was written is torn; start afresh so
+		// the segment stays readable.
+		q.writer.Truncate(q.head().size)
+		q.writer.Seek(q.head().size, io.SeekStart)
+		return err
+	}
+	h := q.head()
+	h.size += size
+	h.records++
+	q.bytes += size
+	q.depth++
+	q.dirty = true
+	if q.opts.FSync == FSyncAlways {
+		q.syncLocked()
+	}
+	q.broadcast()
+	return nil
+}
+
+// dropOldest deletes the oldest segment along with its undelivered records,
+// starting a new head segment first if it is the only one.
+func (q *Queue) dropOldest() error {
+	if len(q.segments) == 1 {
+		if err := q.startSegment(q.head().id + 1); err != nil {
+			return err
+		}
+	}
+	s := q.segments[0]
+	lost := s.records - s.committed
+	q.dropped.Add(uint64(lost))
+	q.depth -= lost
+
+	// Its popped records are a prefix of pending; their acks become no-ops.
+	n := 0
+	for n < len(q.pending) && q.pending[n].seg == s {
+		n++
+	}
+	q.pending = q.pending[n:]
+	q.first += uint64(n)
+
+	next := position{Segment: q.segments[1].id}
+	if q.read.Segment == s.id {
+		q.read = next
+	}
+	q.cursor = next
+	q.remove(s)
+	return nil
+}
+
+// remove deletes the oldest segment.
+func (q *Queue) remove(s *segment) {
+	if q.reader != nil && q.readerSeg == s.id {
+		q.reader.Close()
+		q.reader = nil
+	}
+	os.Remove(s.path(q.opts.Dir))
+	q.bytes -= s.size
+	q.segments = q.segments[1:]
+	q.broadcast()
+}
+
+// gc deletes the delivered segments no longer written to. The cursor only
+// moves if it pointed into a deleted segment: one ack may commit records in
+// several segments, leaving it well past the new head's start.
+func (q *Queue) gc() {
+	for len(q.segments) > 1 && q.segments[0].committed == q.segments[0].records {
+		q.remove(q.segments[0])
+		if q.cursor.Segment < q.segments[0].id {
+			q.cursor = position{Segment: q.segments[0].id}
+		}
+	}
+}
+
+// Pop returns the next record, waiting for one until ctx is done. The
+// returned function acknowledges its delivery; the queue only moves past a
+// record once it and every record before it are acknowledged, so records
+// popped but never acknowledged are handed out again after a restart.
+func (q *Queue) Pop(ctx context.Context) (Record, func(), error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for {
+		if q.closed {
+			return Record{}, nil, ErrClosed
+		}
+		i := slices.IndexFunc(q.segments, func(s *segment) bool { return s.id == q.read.Segment })
+		if i < 0 {
+			q.read = position{Segment: q.segments[0].id}
+			continue
+		}
+		if q.read.Offset < q.segments[i].size {
+			break
+		}
+		if i < len(q.segments)-1 {
+			q.read = position{Segment: q.segments[i+1].id}
+			continue
+		}
+		wake := q.wake
+		q.mu.Unlock()
+		select {
+		case <-wake:
+			q.mu.Lock()
+		case <-ctx.Done():
+			q.mu.Lock()
+			return Record{}, nil, ctx.Err()
+		}
+	}
+
+	if q.reader == nil || q.readerSeg != q.read.Segment {
+		if q.reader != nil {
+			q.reader.Close()
+		}
+		f, err := os.Open((&segment{id: q.read.Segment}).path(q.opts.Dir))
+		if err != nil {
+			q.reader = nil
+			return Record{}, nil, err
+		}
+		q.reader, q.readerSeg = f, q.read.Segment
+	}
+	payload, n, err := readFrame(q.reader, q.read.Offset)
+	if err != nil {
+		return Record{}, nil, err
+	}
+	var rec Record
+	if err := json.Unmarshal(payload, &rec); err != nil {
+		return Record{}, nil, err
+	}
+	q.read.Offset += n
+
+	seq := q.next
+	q.next++
+	q.pending = append(q.pending, popped{seg: q.segmentOf(q.read.Segment), end: q.read.Offset})
+	var once sync.Once
+	return rec, func() { once.Do(func() { q.ack(seq) }) }, nil
+}
+
+func (q *Queue) segmentOf(id uint64) *segment {
+	for _, s := range q.segments {
+		if s.id == id {
+			return s
+		}
+	}
+	return nil
+}
+
+func (q *Queue) ack(seq uint64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed || seq < q.first {
+		return
+	}
+	q.pending[seq-q.first].acked = true
+
+	n := 0
+	for n < len(q.pending) && q.pending[n].acked {
+		p := q.pending[n]
+		p.seg.committed++
+		q.depth--
+		q.cursor = position{Segment: p.seg.id, Offset: p.end}
+		n++
+	}
+	if n == 0 {
+		return
+	}
+	q.pending = q.pending[n:]
+	q.first += uint64(n)
+	q.gc()
+	q.broadcast() // a push blocked on a delivered head segment may proceed
+}
+
+// Stats returns the queue's current size.
+func (q *Queue) Stats() Stats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return Stats{Depth: q.depth, Bytes: q.bytes, Segments: len(q.segments), Dropped: q.dropped.Load()}
+}
+
+func (q *Queue) broadcast() {
+	close(q.wake)
+	q.wake = make(chan struct{})
+}
+
+func (q *Queue) syncLocked() {
+	if q.dirty {
+		q.writer.Sync()
+		q.dirty = false
+	}
+}
+
+// flushLoop fsyncs appended records under the interval policy, starts a new
+// segment once the head is SegmentAge old so delivered records are deleted
+// in time, and checkpoints the cursor.
+func (q *Queue) flushLoop() {
+	defer close(q.done)
+	interval := q.opts.FSyncInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-q.stop:
+			return
+		case <-t.C:
+		}
+		q.mu.Lock()
+		if q.opts.FSync == FSyncInterval {
+			q.syncLocked()
+		}
+		if h := q.head(); h.size > 0 && q.opts.SegmentAge > 0 && time.Since(h.created) >= q.opts.SegmentAge {
+			if err := q.startSegment(h.id + 1); err == nil {
+				q.gc()
+			}
+		}
+		q.saveCursor()
+		q.mu.Unlock()
+	}
+}
+
+// saveCursor atomically writes the cursor if it moved.
+func (q *Queue) saveCursor() error {
+	if q.cursor == q.cursorSync {
+		return nil
+	}
+	raw, err := json.Marshal(q.cursor)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(q.opts.Dir, cursorFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	q.cursorSync = q.cursor
+	return nil
+}
+
+// Close flushes the queue to disk and releases its files. Blocked Push and
+// Pop calls return ErrClosed.
+func (q *Queue) Close() error {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return nil
+	}
+	q.closed = true
+	q.broadcast()
+	q.mu.Unlock()
+
+	close(q.stop)
+	<-q.done
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.reader != nil {
+		q.reader.Close()
+	}
+	if q.opts.FSync != FSyncNever {
+		q.syncLocked()
+	}
+	err := q.writer.Close()
+	if cerr := q.saveCursor(); err == nil {
+		err = cerr
+	}
+	return err
+}
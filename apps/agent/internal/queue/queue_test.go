@@ -0,0 +1,227 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	logify "github.com/indalyadav56/logify/sdks/go"
+)
+
+func openQueue(t *testing.T, dir string, opts Options) *Queue {
+	t.Helper()
+	opts.Dir = dir
+	if opts.MaxSize == 0 {
+		opts.MaxSize = 1 << 20
+	}
+	if opts.SegmentSize == 0 {
+		opts.SegmentSize = 1 << 10
+	}
+	if opts.Overflow == "" {
+		opts.Overflow = OverflowBlock
+	}
+	if opts.FSync == "" {
+		opts.FSync = FSyncAlways
+	}
+	q, err := Open(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return q
+}
+
+func record(i int) Record {
+	return Record{Input: "app", Entry: logify.Entry{Level: logify.LevelInfo, Message: fmt.Sprintf("line %d", i)}}
+}
+
+func push(t *testing.T, q *Queue, from, to int) {
+	t.Helper()
+	for i := from; i < to; i++ {
+		if err := q.Push(context.Background(), record(i)); err != nil {
+			t.Fatalf("push %d: %v", i, err)
+		}
+	}
+}
+
+func pop(t *testing.T, q *Queue) (string, func()) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	rec, ack, err := q.Pop(ctx)
+	if err != nil {
+		t.Fatalf("pop: %v", err)
+	}
+	return rec.Entry.Message, ack
+}
+
+func TestQueueReplaysUndeliveredRecordsInOrder(t *testing.T) {
+	dir := t.TempDir()
+	q := openQueue(t, dir, Options{})
+	push(t, q, 0, 50) // spans several segments
+
+	var acks []func()
+	for i := 0; i < 20; i++ {
+		msg, ack := pop(t, q)
+		if want := fmt.Sprintf("line %d", i); msg != want {
+			t.Fatalf("pop %d = %q, want %q", i, msg, want)
+		}
+		acks = append(acks, ack)
+	}
+	// Only records 0-9 are delivered; 11-19 wait on 10.
+	for i, ack := range acks {
+		if i < 10 || i > 10 {
+			ack()
+		}
+	}
+	if got := q.Stats().Depth; got != 40 {
+		t.Fatalf("depth = %d, want 40", got)
+	}
+	if err := q.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	q = openQueue(t, dir, Options{})
+	defer q.Close()
+	if got := q.Stats().Depth; got != 40 {
+		t.Fatalf("depth after reopen = %d, want 40", got)
+	}
+	for i := 10; i < 50; i++ {
+		msg, ack := pop(t, q)
+		if want := fmt.Sprintf("line %d", i); msg != want {
+			t.Fatalf("pop after reopen = %q, want %q", msg, want)
+		}
+		ack()
+	}
+	if s := q.Stats(); s.Depth != 0 || s.Segments != 1 {
+		t.Fatalf("stats once delivered = %+v, want no depth and only the head segment", s)
+	}
+}
+
+func TestQueueAckSpanningSegmentsSurvivesReopen(t *testing.T) {
+	dir := t.TempDir()
+	q := openQueue(t, dir, Options{})
+	push(t, q, 0, 20) // spans several segments
+
+	var acks []func()
+	for i := 0; i < 20; i++ {
+		_, ack := pop(t, q)
+		acks = append(acks, ack)
+	}
+	for _, ack := range acks[1:] {
+		ack()
+	}
+	acks[0]() // commits all 20, across every segment
+	if s := q.Stats(); s.Depth != 0 {
+		t.Fatalf("depth = %d, want 0", s.Depth)
+	}
+	if err := q.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	q = openQueue(t, dir, Options{})
+	defer q.Close()
+	if got := q.Stats().Depth; got != 0 {
+		t.Fatalf("depth after reopen = %d, want 0", got)
+	}
+}
+
+func TestQueueDiscardsTornRecord(t *testing.T) {
+	dir := t.TempDir()
+	q := openQueue(t, dir, Options{SegmentSize: 1 << 20})
+	push(t, q, 0, 3)
+	q.Close()
+
+	// Simulate a crash halfway through appending a record.
+	path := filepath.Join(dir, fmt.Sprintf("%020d%s", 0, segmentExt))
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte{0, 0, 0, 40, 1, 2})
+	f.Close()
+
+	q = openQueue(t, dir, Options{SegmentSize: 1 << 20})
+	defer q.Close()
+	if got := q.Stats().Depth; got != 3 {
+		t.Fatalf("depth = %d, want 3", got)
+	}
+	push(t, q, 3, 4)
+	for i := 0; i < 4; i++ {
+		if msg, _ := pop(t, q); msg != fmt.Sprintf("line %d", i) {
+			t.Fatalf("pop %d = %q", i, msg)
+		}
+	}
+}
+
+func TestQueueOverflow(t *testing.T) {
+	frame := func() int64 {
+		q := openQueue(t, t.TempDir(), Options{})
+		defer q.Close()
+		push(t, q, 0, 1)
+		return q.Stats().Bytes
+	}()
+	// Two records per segment, six at most in the queue.
+	opts := Options{MaxSize: 6 * frame, SegmentSize: 2 * frame}
+
+	t.Run("drop_newest", func(t *testing.T) {
+		opts := opts
+		opts.Overflow = OverflowDropNewest
+		q := openQueue(t, t.TempDir(), opts)
+		defer q.Close()
+		push(t, q, 0, 6)
+		if err := q.Push(context.Background(), record(6)); !errors.Is(err, ErrFull) {
+			t.Fatalf("push when full = %v, want ErrFull", err)
+		}
+		if msg, _ := pop(t, q); msg != "line 0" {
+			t.Fatalf("oldest record = %q, want line 0", msg)
+		}
+		if got := q.Stats().Dropped; got != 1 {
+			t.Fatalf("dropped = %d, want 1", got)
+		}
+	})
+
+	t.Run("drop_oldest", func(t *testing.T) {
+		opts := opts
+		opts.Overflow = OverflowDropOldest
+		q := openQueue(t, t.TempDir(), opts)
+		defer q.Close()
+		push(t, q, 0, 7)
+		if msg, _ := pop(t, q); msg != "line 2" {
+			t.Fatalf("oldest record = %q, want line 2 once the first segment is dropped", msg)
+		}
+		if s := q.Stats(); s.Dropped != 2 || s.Depth != 5 {
+			t.Fatalf("stats = %+v, want 2 dropped and a depth of 5", s)
+		}
+	})
+
+	t.Run("block", func(t *testing.T) {
+		q := openQueue(t, t.TempDir(), opts)
+		defer q.Close()
+		push(t, q, 0, 6)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		if err := q.Push(ctx, record(6)); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("push when full = %v, want it to block until the deadline", err)
+		}
+
+		pushed := make(chan error, 1)
+		go func() { pushed <- q.Push(context.Background(), record(6)) }()
+		for i := 0; i < 2; i++ {
+			_, ack := pop(t, q)
+			ack()
+		}
+		select {
+		case err := <-pushed:
+			if err != nil {
+				t.Fatal(err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("push still blocked once the oldest segment was delivered")
+		}
+	})
+}
@@ -1,5 +1,6 @@
-// Package registry persists file-tail read offsets so the agent resumes where
-// it left off across restarts instead of re-shipping or skipping logs.
+// Package registry persists input read positions (file offsets, journal
+// cursors) so the agent resumes where it left off across restarts instead of
+// re-shipping or skipping logs. Positions only advance past delivered logs.
 package registry
 
 import (
@@ -10,11 +11,13 @@ import (
 )
 
 // State is the per-file checkpoint. Inode/Device fingerprint the physical file
-// so we can detect rotation (same path, new file) and truncation.
+// so we can detect rotation (same path, new file) and truncation. Sources
+// without offsets, like the journal, keep an opaque Cursor instead.
 type State struct {
 	Offset int64  `json:"offset"`
 	Inode  uint64 `json:"inode"`
 	Device uint64 `json:"device"`
+	Cursor string `json:"cursor,omitempty"`
 }
 
 // Registry is an in-memory map of file path -> State backed by a JSON file.
@@ -0,0 +1,79 @@
+package registry
+
+import "sync"
+
+// Tracker checkpoints one source whose events are delivered out of order.
+// Each event is registered, in read order, with the state to commit once it
+// is delivered; the registry only ever holds the state of the latest event
+// that was delivered along with every event before it. A crash therefore
+// replays undelivered events rather than losing them.
+type Tracker struct {
+	reg *Registry
+	key string
+
+	mu      sync.Mutex
+	pending []pendingEvent
+	first   uint64 // sequence number of pending[0]
+	next    uint64
+	closed  bool
+}
+
+type pendingEvent struct {
+	state State
+	acked bool
+}
+
+// Track starts tracking key from s, which is committed at once.
+func (r *Registry) Track(key string, s State) *Tracker {
+	r.Set(key, s)
+	return &Tracker{reg: r, key: key}
+}
+
+// Add registers the next event read from the source, which s follows. The
+// returned function acknowledges its delivery; calling it more than once, or
+// after Close, has no effect.
+func (t *Tracker) Add(s State) func() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	seq := t.next
+	t.next++
+	t.pending = append(t.pending, pendingEvent{state: s})
+	var once sync.Once
+	return func() { once.Do(func() { t.ack(seq) }) }
+}
+
+func (t *Tracker) ack(seq uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed || seq < t.first {
+		return
+	}
+	t.pending[seq-t.first].acked = true
+
+	n := 0
+	for n < len(t.pending) && t.pending[n].acked {
+		n++
+	}
+	if n == 0 {
+		return
+	}
+	t.reg.Set(t.key, t.pending[n-1].state)
+	t.pending = append(t.pending[:0], t.pending[n:]...)
+	t.first += uint64(n)
+}
+
+// Pending returns the number of events not yet committed.
+func (t *Tracker) Pending() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.pending)
+}
+
+// Close stops committing, e.g. once the file behind the source was rotated
+// and its events no longer describe what the key refers to.
+func (t *Tracker) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+	t.pending = nil
+}
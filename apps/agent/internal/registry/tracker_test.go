@@ -0,0 +1,43 @@
+package registry
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestTrackerCommitsContiguousAcks(t *testing.T) {
+	reg, err := Open(filepath.Join(t.TempDir(), "registry.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := reg.Track("app.log", State{Offset: 10, Inode: 7})
+	offset := func() int64 {
+		s, _ := reg.Get("app.log")
+		return s.Offset
+	}
+
+	a := tr.Add(State{Offset: 20, Inode: 7})
+	b := tr.Add(State{Offset: 30, Inode: 7})
+	c := tr.Add(State{Offset: 40, Inode: 7})
+
+	c()
+	b()
+	if got := offset(); got != 10 {
+		t.Fatalf("offset = %d before the first event is acknowledged, want 10", got)
+	}
+	a()
+	if got := offset(); got != 40 {
+		t.Fatalf("offset = %d once every event is acknowledged, want 40", got)
+	}
+	a() // acknowledging twice is harmless
+	if tr.Pending() != 0 {
+		t.Fatalf("pending = %d, want 0", tr.Pending())
+	}
+
+	d := tr.Add(State{Offset: 50, Inode: 7})
+	tr.Close()
+	d()
+	if got := offset(); got != 40 {
+		t.Fatalf("offset = %d after an ack on a closed tracker, want 40", got)
+	}
+}
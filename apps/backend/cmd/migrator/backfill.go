@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+)
+
+const dateLayout = "2006-01-02"
+
+// backfills are the ClickHouse-only commands that rebuild derived tables
+// from stored logs, by name.
+var backfills = map[string]func(ctx context.Context, db *sql.DB, args []string) error{
+	"backfill-rollups": runBackfillRollups,
+	"backfill-fields":  runBackfillFields,
+}
+
+// The rollup materialized view only counts logs inserted after it was
+// created. backfill-rollups recounts whole UTC days of stored logs into the
+// rollups, replacing whatever they held for those days, so it is safe to
+// re-run. Logs inserted into a day while it is being recounted may be
+// counted twice; backfill closed days, which is the default upper bound.
+const (
+	deleteRollupDay = `ALTER TABLE logify.logs_rollup_1m DELETE WHERE minute >= ? AND minute < ?`
+
+	insertRollupDay = `INSERT INTO logify.logs_rollup_1m
+SELECT
+	tenant_id,
+	project_id,
+	toStartOfMinute(timestamp) AS minute,
+	service,
+	level,
+	environment,
+	host,
+	count() AS logs,
+	max(retention_days) AS retention_days,
+	sum(sample_rate) AS weighted_logs
+FROM logify.logs
+WHERE timestamp >= ? AND timestamp < ?
+GROUP BY tenant_id, project_id, minute, service, level, environment, host`
+)
+
+// The field catalog's materialized views likewise only see logs inserted
+// after they were created. backfill-fields rebuilds the catalog of whole UTC
+// days from the stored logs in the same way, with the same caveats.
+const (
+	deleteFieldsDay = `ALTER TABLE logify.log_fields DELETE WHERE day >= toDate(?) AND day < toDate(?)`
+
+	insertAttributeFieldsDay = `INSERT INTO logify.log_fields
+SELECT
+	tenant_id,
+	project_id,
+	toDate(timestamp) AS day,
+	concat('attributes.', key) AS field,
+	multiIf(mapContains(attributes_number, key), 'number', mapContains(attributes_bool, key), 'bool', 'string') AS type,
+	min(timestamp) AS first_seen,
+	max(timestamp) AS last_seen,
+	count() AS logs,
+	uniqState(value) AS distinct_values,
+	max(retention_days) AS retention_days
+FROM logify.logs
+ARRAY JOIN mapKeys(attributes) AS key, mapValues(attributes) AS value
+WHERE timestamp >= ? AND timestamp < ?
+GROUP BY tenant_id, project_id, day, field, type`
+
+	insertTagFieldsDay = `INSERT INTO logify.log_fields
+SELECT
+	tenant_id,
+	project_id,
+	toDate(timestamp) AS day,
+	concat('tags.', key) AS field,
+	'string' AS type,
+	min(timestamp) AS first_seen,
+	max(timestamp) AS last_seen,
+	count() AS logs,
+	uniqState(value) AS distinct_values,
+	max(retention_days) AS retention_days
+FROM logify.logs
+ARRAY JOIN mapKeys(tags) AS key, mapValues(tags) AS value
+WHERE timestamp >= ? AND timestamp < ?
+GROUP BY tenant_id, project_id, day, field, type`
+)
+
+// runBackfillRollups recounts the days [from, to); to defaults to today.
+func runBackfillRollups(ctx context.Context, db *sql.DB, args []string) error {
+	return backfillDays(ctx, db, "backfill-rollups", "rollups", args, deleteRollupDay, insertRollupDay)
+}
+
+// runBackfillFields rebuilds the field catalog of the days [from, to); to
+// defaults to today.
+func runBackfillFields(ctx context.Context, db *sql.DB, args []string) error {
+	return backfillDays(ctx, db, "backfill-fields", "fields", args, deleteFieldsDay, insertAttributeFieldsDay, insertTagFieldsDay)
+}
+
+// backfillDays clears each day of [from, to) with del and refills it with
+// inserts, all of which take the day's bounds as their two arguments.
+func backfillDays(ctx context.Context, db *sql.DB, cmd, what string, args []string, del string, inserts ...string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("usage: migrator -driver clickhouse %s <from YYYY-MM-DD> [to YYYY-MM-DD]", cmd)
+	}
+	from, err := time.Parse(dateLayout, args[0])
+	if err != nil {
+		return fmt.Errorf("invalid from date %q: %w", args[0], err)
+	}
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	if len(args) >= 2 {
+		if to, err = time.Parse(dateLayout, args[1]); err != nil {
+			return fmt.Errorf("invalid to date %q: %w", args[1], err)
+		}
+	}
+	if !from.Before(to) {
+		return fmt.Errorf("from %s must be before to %s", from.Format(dateLayout), to.Format(dateLayout))
+	}
+
+	// Wait for each day's delete so its recount is not deleted with it.
+	ctx = clickhouse.Context(ctx, clickhouse.WithSettings(clickhouse.Settings{"mutations_sync": 2}))
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		next := day.AddDate(0, 0, 1)
+		if _, err := db.ExecContext(ctx, del, day, next); err != nil {
+			return fmt.Errorf("clear %s of %s: %w", what, day.Format(dateLayout), err)
+		}
+		for _, insert := range inserts {
+			if _, err := db.ExecContext(ctx, insert, day, next); err != nil {
+				return fmt.Errorf("backfill %s of %s: %w", what, day.Format(dateLayout), err)
+			}
+		}
+		fmt.Printf("backfilled %s of %s\n", what, day.Format(dateLayout))
+	}
+	return nil
+}
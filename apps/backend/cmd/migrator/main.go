@@ -18,6 +18,13 @@
 //	status                Print migration status
 //	version               Print the current database version
 //	create <name> [type]  Create a new migration file (type: sql | go, default: sql)
+//	backfill-rollups <from> [to]
+//	                      Recount stored logs of the UTC days [from, to) into
+//	                      the ClickHouse rollups (dates YYYY-MM-DD, to defaults
+//	                      to today; clickhouse driver only)
+//	backfill-fields <from> [to]
+//	                      Rebuild the ClickHouse field catalog of the UTC days
+//	                      [from, to) from stored logs (as backfill-rollups)
 //
 // Flags:
 //
@@ -158,6 +165,13 @@ func run() error {
 		return fmt.Errorf("ping %s db: %w", profile.name, err)
 	}
 
+	if backfill, ok := backfills[cmd]; ok {
+		if profile.name != driverClickHouse {
+			return fmt.Errorf("%s requires -driver %s", cmd, driverClickHouse)
+		}
+		return backfill(ctx, db, args)
+	}
+
 	if err := goose.SetDialect(profile.gooseDialect); err != nil {
 		return fmt.Errorf("set dialect %q: %w", profile.gooseDialect, err)
 	}
@@ -278,6 +292,10 @@ func printUsage(fs *flag.FlagSet) {
 		"  status                Print migration status",
 		"  version               Print the current database version",
 		"  create <name> [type]  Create a new migration file (type: sql | go)",
+		"  backfill-rollups <from> [to]",
+		"                        Recount logs of days [from, to) into the rollups (clickhouse)",
+		"  backfill-fields <from> [to]",
+		"                        Rebuild the field catalog of days [from, to) (clickhouse)",
 	} {
 		fmt.Fprintln(os.Stderr, line)
 	}
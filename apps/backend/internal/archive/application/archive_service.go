@@ -0,0 +1,626 @@
+package application
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/archive/domain"
+	roleDomain "github.com/indalyadav56/logify/apps/backend/internal/role/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
+	"github.com/indalyadav56/logify/apps/backend/pkg/objectstore"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 200
+
+	// insertBatch bounds the rows held in memory while rehydrating.
+	insertBatch = 10_000
+
+	// staleJobAfter is when a running job is presumed dead (its worker
+	// crashed) and may be claimed again.
+	staleJobAfter = time.Hour
+
+	// ingestSettle is how long after its ingestion time a log is taken to be
+	// visible in ClickHouse; younger logs are left to the next export.
+	ingestSettle = time.Minute
+)
+
+// ObjectStore holds the archives. It is satisfied by objectstore.Client.
+type ObjectStore interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, sha256Hex, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, *objectstore.ObjectInfo, error)
+	Head(ctx context.Context, key string) (*objectstore.ObjectInfo, error)
+}
+
+// ProjectAccess decides whether the caller may read a project's logs. It is
+// satisfied by role/application.PermissionResolver.
+type ProjectAccess interface {
+	HasPermission(ctx context.Context, userID, tenantID uuid.UUID, projectID *uuid.UUID, resource, action string) (bool, error)
+}
+
+// Config tunes the archiver. A day is archived once its end is After old;
+// the worker looks Lookback further back for days it has not archived yet,
+// so Lookback must stay below the shortest log retention.
+type Config struct {
+	Prefix            string
+	After             time.Duration
+	Lookback          time.Duration
+	Interval          time.Duration
+	PollInterval      time.Duration
+	RehydrateTTL      time.Duration
+	MaxRehydrateRange time.Duration
+	MaxAttempts       int
+}
+
+func (c Config) withDefaults() Config {
+	if c.After <= 0 {
+		c.After = 24 * time.Hour
+	}
+	if c.Lookback <= 0 {
+		c.Lookback = 3 * 24 * time.Hour
+	}
+	if c.Interval <= 0 {
+		c.Interval = time.Hour
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = 10 * time.Second
+	}
+	if c.RehydrateTTL <= 0 {
+		c.RehydrateTTL = 24 * time.Hour
+	}
+	if c.MaxRehydrateRange <= 0 {
+		c.MaxRehydrateRange = 31 * 24 * time.Hour
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = 3
+	}
+	return c
+}
+
+type ArchiveService interface {
+	// Run archives due days and processes rehydrations until ctx is done.
+	Run(ctx context.Context)
+
+	// RequestRehydration queues the load of a project's archived logs into
+	// a temporary table that search can query until the job expires.
+	RequestRehydration(ctx context.Context, input RehydrateInput) (*domain.Job, error)
+	// DropRehydration drops a rehydrated table before it expires.
+	DropRehydration(ctx context.Context, id uuid.UUID) (*domain.Job, error)
+	GetJob(ctx context.Context, id uuid.UUID) (*domain.Job, error)
+	ListJobs(ctx context.Context, input ListJobsInput) (*ListJobsOutput, error)
+	// ListSegments returns the caller's tenant archive index for [from, to).
+	ListSegments(ctx context.Context, from, to time.Time) (*ListSegmentsOutput, error)
+
+	// Rehydration returns a ready rehydration of the project; search uses
+	// it to query the rehydrated table.
+	Rehydration(ctx context.Context, tenantID, projectID, id uuid.UUID) (*domain.Job, error)
+}
+
+type archiveService struct {
+	jobs     domain.JobRepository
+	segments domain.SegmentRepository
+	logs     domain.LogStore
+	store    ObjectStore
+	access   ProjectAccess
+	cfg      Config
+	logger   *zap.Logger
+	now      func() time.Time
+}
+
+// NewArchiveService wires the archiver. With a nil store archival is
+// disabled: Run returns at once and rehydrations are refused.
+func NewArchiveService(
+	jobs domain.JobRepository,
+	segments domain.SegmentRepository,
+	logs domain.LogStore,
+	store ObjectStore,
+	access ProjectAccess,
+	cfg Config,
+	logger *zap.Logger,
+) ArchiveService {
+	return &archiveService{
+		jobs:     jobs,
+		segments: segments,
+		logs:     logs,
+		store:    store,
+		access:   access,
+		cfg:      cfg.withDefaults(),
+		logger:   logger.Named("archive_service"),
+		now:      time.Now,
+	}
+}
+
+func (s *archiveService) Run(ctx context.Context) {
+	if s.store == nil {
+		return
+	}
+	archiveTicker := time.NewTicker(s.cfg.Interval)
+	defer archiveTicker.Stop()
+	pollTicker := time.NewTicker(s.cfg.PollInterval)
+	defer pollTicker.Stop()
+
+	s.archiveDue(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-archiveTicker.C:
+			s.archiveDue(ctx)
+		case <-pollTicker.C:
+			s.rehydratePending(ctx)
+			s.expireRehydrations(ctx)
+		}
+	}
+}
+
+// archiveDue archives every tenant-day in the lookback window that is old
+// enough and not archived yet, or that has gained logs since it was. It
+// reports how many days it wrote a segment for.
+func (s *archiveService) archiveDue(ctx context.Context) int {
+	to := truncateDay(s.now().Add(-s.cfg.After))
+	from := to.Add(-s.cfg.Lookback)
+	days, err := s.logs.Days(ctx, from, to)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.Warn("list archivable days failed", zap.Error(err))
+		}
+		return 0
+	}
+
+	archived := 0
+	for _, day := range days {
+		if ctx.Err() != nil {
+			break
+		}
+		ok, err := s.archiveDay(ctx, day)
+		if err != nil {
+			s.logger.Error("archive failed",
+				zap.String("tenant_id", day.TenantID.String()),
+				zap.Time("day", day.Day),
+				zap.Error(err),
+			)
+		}
+		if ok {
+			archived++
+		}
+	}
+	return archived
+}
+
+// archiveDay exports the logs of one tenant-day ingested since its last
+// export, unless another worker has it or it is done. It reports whether
+// this call wrote a segment.
+func (s *archiveService) archiveDay(ctx context.Context, day domain.TenantDay) (bool, error) {
+	job, err := s.jobs.ClaimArchive(ctx, day, s.cfg.MaxAttempts, s.now().Add(-staleJobAfter))
+	if err != nil || job == nil {
+		return false, err
+	}
+
+	// ingestion_time has a resolution of a second.
+	until := s.now().UTC().Add(-ingestSettle).Truncate(time.Second)
+	segment, exportErr := s.export(ctx, job, day, until)
+	if exportErr != nil {
+		job.Status, job.Error = domain.JobFailed, exportErr.Error()
+	} else {
+		job.Status, job.IngestedThrough = domain.JobSucceeded, &until
+		if segment.Rows > 0 {
+			job.Rows += segment.Rows
+			job.Bytes += segment.Bytes
+			job.Segments++
+		}
+	}
+	// Record the outcome even when ctx was cancelled mid-export.
+	if err := s.jobs.Finish(context.WithoutCancel(ctx), job); err != nil {
+		return false, errors.Join(exportErr, err)
+	}
+	if exportErr != nil {
+		return false, exportErr
+	}
+	if segment.Rows == 0 {
+		return false, nil
+	}
+	s.logger.Info("day archived",
+		zap.String("tenant_id", day.TenantID.String()),
+		zap.Time("day", day.Day),
+		zap.Int("seq", segment.Seq),
+		zap.Uint64("rows", segment.Rows),
+		zap.Int64("bytes", segment.Bytes),
+		zap.String("object_key", segment.ObjectKey),
+	)
+	return true, nil
+}
+
+// export writes the logs of a tenant-day ingested after the job's
+// IngestedThrough and up to until as the day's next segment: gzipped NDJSON
+// written to a temporary file while hashing it, uploaded signed over that
+// hash and checked, then the manifest is written and the segment indexed.
+func (s *archiveService) export(ctx context.Context, job *domain.Job, day domain.TenantDay, until time.Time) (*domain.Segment, error) {
+	tmp, err := os.CreateTemp("", "logify-archive-*."+domain.FormatNDJSONGzip)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	objectKey, manifestKey := domain.SegmentKeys(s.cfg.Prefix, day.TenantID, day.Day, job.Segments)
+	segment := &domain.Segment{
+		TenantID:    day.TenantID,
+		Day:         truncateDay(day.Day),
+		Seq:         job.Segments,
+		ObjectKey:   objectKey,
+		ManifestKey: manifestKey,
+		Format:      domain.FormatNDJSONGzip,
+		Projects:    []string{},
+		JobID:       job.ID,
+	}
+
+	hash := sha256.New()
+	gz := gzip.NewWriter(io.MultiWriter(tmp, hash))
+	enc := json.NewEncoder(gz)
+	err = s.logs.Export(ctx, day, job.IngestedThrough, until, func(r *domain.Record) error {
+		segment.Rows++
+		if segment.MinTime == nil || r.Timestamp.Before(*segment.MinTime) {
+			ts := r.Timestamp
+			segment.MinTime = &ts
+		}
+		if segment.MaxTime == nil || r.Timestamp.After(*segment.MaxTime) {
+			ts := r.Timestamp
+			segment.MaxTime = &ts
+		}
+		if !slices.Contains(segment.Projects, r.ProjectID) {
+			segment.Projects = append(segment.Projects, r.ProjectID)
+		}
+		return enc.Encode(r)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	if segment.Rows == 0 {
+		// Nothing new is settled yet, or the day expired between listing and
+		// export; there is no segment to write.
+		return segment, nil
+	}
+
+	segment.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	if segment.Bytes, err = tmp.Seek(0, io.SeekCurrent); err != nil {
+		return nil, err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if err := s.store.Put(ctx, objectKey, tmp, segment.Bytes, segment.SHA256, "application/gzip"); err != nil {
+		return nil, err
+	}
+	info, err := s.store.Head(ctx, objectKey)
+	if err != nil {
+		return nil, err
+	}
+	if info.Size != segment.Bytes || (info.SHA256 != "" && info.SHA256 != segment.SHA256) {
+		return nil, fmt.Errorf("%w: %s stored %d bytes, wrote %d", domain.ErrChecksumMismatch, objectKey, info.Size, segment.Bytes)
+	}
+
+	segment.CreatedAt = s.now().UTC()
+	manifest, err := json.MarshalIndent(domain.Manifest{Version: domain.ManifestVersion, Segment: *segment}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(manifest)
+	if err := s.store.Put(ctx, manifestKey, bytes.NewReader(manifest), int64(len(manifest)), hex.EncodeToString(sum[:]), "application/json"); err != nil {
+		return nil, err
+	}
+	if err := s.segments.Save(ctx, segment); err != nil {
+		return nil, err
+	}
+	return segment, nil
+}
+
+// rehydratePending runs queued rehydrations until none is left.
+func (s *archiveService) rehydratePending(ctx context.Context) {
+	for ctx.Err() == nil {
+		job, err := s.jobs.ClaimRehydration(ctx)
+		if err != nil {
+			s.logger.Warn("claim rehydration failed", zap.Error(err))
+			return
+		}
+		if job == nil {
+			return
+		}
+
+		if err := s.rehydrate(ctx, job); err != nil {
+			job.Status, job.Error = domain.JobFailed, err.Error()
+			if dropErr := s.logs.DropTable(context.WithoutCancel(ctx), job.Table); dropErr != nil {
+				s.logger.Warn("drop failed rehydration table", zap.String("table", job.Table), zap.Error(dropErr))
+			}
+			s.logger.Error("rehydration failed", zap.String("job_id", job.ID.String()), zap.Error(err))
+		} else {
+			job.Status = domain.JobSucceeded
+			expires := s.now().UTC().Add(s.cfg.RehydrateTTL)
+			job.ExpiresAt = &expires
+			s.logger.Info("rehydration ready",
+				zap.String("job_id", job.ID.String()),
+				zap.Uint64("rows", job.Rows),
+				zap.Int("segments", job.Segments),
+			)
+		}
+		if err := s.jobs.Finish(context.WithoutCancel(ctx), job); err != nil {
+			s.logger.Error("record rehydration outcome failed", zap.String("job_id", job.ID.String()), zap.Error(err))
+		}
+	}
+}
+
+// rehydrate loads the job's project and range from every segment that may
+// hold it. Each segment is hashed as it is read and the job fails if a hash
+// does not match the index.
+func (s *archiveService) rehydrate(ctx context.Context, job *domain.Job) error {
+	segments, err := s.segments.List(ctx, job.TenantID, truncateDay(job.From), truncateDay(job.To.Add(-time.Nanosecond)).AddDate(0, 0, 1))
+	if err != nil {
+		return err
+	}
+	if err := s.logs.CreateTable(ctx, job.Table); err != nil {
+		return err
+	}
+
+	project := job.ProjectID.String()
+	job.Rows, job.Bytes, job.Segments = 0, 0, 0
+	for _, segment := range segments {
+		if !slices.Contains(segment.Projects, project) {
+			continue
+		}
+		rows, err := s.loadSegment(ctx, job, segment)
+		if err != nil {
+			return fmt.Errorf("segment %s: %w", segment.ObjectKey, err)
+		}
+		job.Rows += rows
+		job.Bytes += segment.Bytes
+		job.Segments++
+	}
+	return nil
+}
+
+func (s *archiveService) loadSegment(ctx context.Context, job *domain.Job, segment *domain.Segment) (uint64, error) {
+	body, _, err := s.store.Get(ctx, segment.ObjectKey)
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+
+	hash := sha256.New()
+	raw := io.TeeReader(body, hash)
+	gz, err := gzip.NewReader(raw)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", domain.ErrChecksumMismatch, err)
+	}
+	dec := json.NewDecoder(gz)
+
+	project := job.ProjectID.String()
+	var (
+		loaded uint64
+		batch  = make([]*domain.Record, 0, insertBatch)
+	)
+	flush := func() error {
+		if err := s.logs.Insert(ctx, job.Table, batch); err != nil {
+			return err
+		}
+		loaded += uint64(len(batch))
+		batch = batch[:0]
+		return nil
+	}
+	for {
+		var r domain.Record
+		if err := dec.Decode(&r); err == io.EOF {
+			break
+		} else if err != nil {
+			return 0, fmt.Errorf("%w: %v", domain.ErrChecksumMismatch, err)
+		}
+		if r.ProjectID != project || r.Timestamp.Before(job.From) || !r.Timestamp.Before(job.To) {
+			continue
+		}
+		batch = append(batch, &r)
+		if len(batch) == insertBatch {
+			if err := flush(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return 0, err
+	}
+
+	// Hash whatever follows the gzip stream too, then compare.
+	if _, err := io.Copy(io.Discard, raw); err != nil {
+		return 0, err
+	}
+	if sum := hex.EncodeToString(hash.Sum(nil)); sum != segment.SHA256 {
+		return 0, fmt.Errorf("%w: got %s, indexed %s", domain.ErrChecksumMismatch, sum, segment.SHA256)
+	}
+	return loaded, nil
+}
+
+// expireRehydrations drops the tables of rehydrations past their expiry.
+func (s *archiveService) expireRehydrations(ctx context.Context) {
+	jobs, err := s.jobs.Expired(ctx, s.now().UTC())
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.Warn("list expired rehydrations failed", zap.Error(err))
+		}
+		return
+	}
+	for _, job := range jobs {
+		if err := s.expire(ctx, job); err != nil {
+			s.logger.Warn("expire rehydration failed", zap.String("job_id", job.ID.String()), zap.Error(err))
+		}
+	}
+}
+
+func (s *archiveService) expire(ctx context.Context, job *domain.Job) error {
+	if err := s.logs.DropTable(ctx, job.Table); err != nil {
+		return err
+	}
+	job.Status = domain.JobExpired
+	return s.jobs.Finish(ctx, job)
+}
+
+func (s *archiveService) RequestRehydration(ctx context.Context, input RehydrateInput) (*domain.Job, error) {
+	if s.store == nil {
+		return nil, domain.ErrDisabled
+	}
+	userID, tenantID, err := callerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if input.ProjectID == uuid.Nil {
+		return nil, domain.ErrProjectRequired
+	}
+	if !input.From.Before(input.To) {
+		return nil, domain.ErrInvalidRange
+	}
+	if input.To.Sub(input.From) > s.cfg.MaxRehydrateRange {
+		return nil, domain.ErrRangeTooLarge
+	}
+	allowed, err := s.access.HasPermission(ctx, userID, tenantID, &input.ProjectID, roleDomain.ResourceLog, roleDomain.ActionRead)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, domain.ErrProjectNotFound
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, err
+	}
+	job := &domain.Job{
+		ID:          id,
+		TenantID:    tenantID,
+		Kind:        domain.JobRehydrate,
+		Status:      domain.JobPending,
+		ProjectID:   &input.ProjectID,
+		From:        input.From.UTC(),
+		To:          input.To.UTC(),
+		Table:       domain.RehydrationTable(id),
+		RequestedBy: &userID,
+	}
+	if err := s.jobs.Create(ctx, job); err != nil {
+		return nil, err
+	}
+	s.logger.Info("rehydration requested",
+		zap.String("job_id", job.ID.String()),
+		zap.String("tenant_id", tenantID.String()),
+		zap.String("project_id", input.ProjectID.String()),
+		zap.Time("from", job.From),
+		zap.Time("to", job.To),
+	)
+	return job, nil
+}
+
+func (s *archiveService) DropRehydration(ctx context.Context, id uuid.UUID) (*domain.Job, error) {
+	job, err := s.GetJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Kind != domain.JobRehydrate {
+		return nil, domain.ErrJobNotFound
+	}
+	if job.Status != domain.JobSucceeded {
+		return nil, domain.ErrNotReady
+	}
+	if err := s.expire(ctx, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (s *archiveService) GetJob(ctx context.Context, id uuid.UUID) (*domain.Job, error) {
+	_, tenantID, err := callerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.jobs.Get(ctx, tenantID, id)
+}
+
+func (s *archiveService) ListJobs(ctx context.Context, input ListJobsInput) (*ListJobsOutput, error) {
+	_, tenantID, err := callerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	limit = min(limit, maxListLimit)
+	jobs, err := s.jobs.List(ctx, domain.JobFilter{TenantID: tenantID, Kind: input.Kind, Status: input.Status, Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	return &ListJobsOutput{Jobs: jobs}, nil
+}
+
+func (s *archiveService) ListSegments(ctx context.Context, from, to time.Time) (*ListSegmentsOutput, error) {
+	_, tenantID, err := callerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !from.Before(to) {
+		return nil, domain.ErrInvalidRange
+	}
+	segments, err := s.segments.List(ctx, tenantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	out := &ListSegmentsOutput{Segments: segments}
+	for _, segment := range segments {
+		out.TotalRows += segment.Rows
+		out.TotalBytes += segment.Bytes
+	}
+	return out, nil
+}
+
+func (s *archiveService) Rehydration(ctx context.Context, tenantID, projectID, id uuid.UUID) (*domain.Job, error) {
+	job, err := s.jobs.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Kind != domain.JobRehydrate || job.ProjectID == nil || *job.ProjectID != projectID {
+		return nil, domain.ErrJobNotFound
+	}
+	if job.Status != domain.JobSucceeded {
+		return nil, domain.ErrNotReady
+	}
+	return job, nil
+}
+
+// callerFromContext returns the caller as set by AuthMiddleware. The tenant
+// is never taken from request input.
+func callerFromContext(ctx context.Context) (userID, tenantID uuid.UUID, err error) {
+	userID, ok := middleware.UserUUIDFromContext(ctx)
+	if !ok {
+		return uuid.Nil, uuid.Nil, domain.ErrTenantRequired
+	}
+	tenantID, ok = middleware.GetTenantUUIDFromContext(ctx)
+	if !ok {
+		return uuid.Nil, uuid.Nil, domain.ErrTenantRequired
+	}
+	return userID, tenantID, nil
+}
+
+func truncateDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,418 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/archive/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
+	"github.com/indalyadav56/logify/apps/backend/pkg/objectstore"
+)
+
+type memJobs struct {
+	jobs map[uuid.UUID]*domain.Job
+}
+
+func (r *memJobs) Create(_ context.Context, job *domain.Job) error {
+	job.CreatedAt = time.Now()
+	cp := *job
+	r.jobs[job.ID] = &cp
+	return nil
+}
+
+func (r *memJobs) Get(_ context.Context, tenantID, id uuid.UUID) (*domain.Job, error) {
+	job, ok := r.jobs[id]
+	if !ok || job.TenantID != tenantID {
+		return nil, domain.ErrJobNotFound
+	}
+	cp := *job
+	return &cp, nil
+}
+
+func (r *memJobs) List(_ context.Context, f domain.JobFilter) ([]*domain.Job, error) {
+	var out []*domain.Job
+	for _, job := range r.jobs {
+		if job.TenantID == f.TenantID && (f.Kind == "" || job.Kind == f.Kind) {
+			cp := *job
+			out = append(out, &cp)
+		}
+	}
+	return out, nil
+}
+
+func (r *memJobs) ClaimArchive(_ context.Context, day domain.TenantDay, maxAttempts int, _ time.Time) (*domain.Job, error) {
+	for _, job := range r.jobs {
+		if job.Kind == domain.JobArchive && job.TenantID == day.TenantID && job.From.Equal(day.Day) {
+			switch {
+			case job.Status == domain.JobSucceeded && job.IngestedThrough != nil && job.IngestedThrough.Before(day.LastIngested):
+				job.Attempts = 0
+			case job.Status != domain.JobFailed || job.Attempts >= maxAttempts:
+				return nil, nil
+			}
+			job.Status, job.Attempts = domain.JobRunning, job.Attempts+1
+			cp := *job
+			return &cp, nil
+		}
+	}
+	job := &domain.Job{ID: uuid.New(), TenantID: day.TenantID, Kind: domain.JobArchive, Status: domain.JobRunning, From: day.Day, To: day.Day.AddDate(0, 0, 1), Attempts: 1}
+	r.jobs[job.ID] = job
+	cp := *job
+	return &cp, nil
+}
+
+func (r *memJobs) ClaimRehydration(_ context.Context) (*domain.Job, error) {
+	for _, job := range r.jobs {
+		if job.Kind == domain.JobRehydrate && job.Status == domain.JobPending {
+			job.Status = domain.JobRunning
+			cp := *job
+			return &cp, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memJobs) Finish(_ context.Context, job *domain.Job) error {
+	cp := *job
+	r.jobs[job.ID] = &cp
+	return nil
+}
+
+func (r *memJobs) Expired(_ context.Context, now time.Time) ([]*domain.Job, error) {
+	var out []*domain.Job
+	for _, job := range r.jobs {
+		if job.Status == domain.JobSucceeded && job.ExpiresAt != nil && !job.ExpiresAt.After(now) {
+			cp := *job
+			out = append(out, &cp)
+		}
+	}
+	return out, nil
+}
+
+type memSegments struct {
+	segments []*domain.Segment
+}
+
+func (r *memSegments) Save(_ context.Context, s *domain.Segment) error {
+	for i, existing := range r.segments {
+		if existing.TenantID == s.TenantID && existing.Day.Equal(s.Day) && existing.Seq == s.Seq {
+			r.segments[i] = s
+			return nil
+		}
+	}
+	r.segments = append(r.segments, s)
+	return nil
+}
+
+func (r *memSegments) List(_ context.Context, tenantID uuid.UUID, from, to time.Time) ([]*domain.Segment, error) {
+	var out []*domain.Segment
+	for _, s := range r.segments {
+		if s.TenantID == tenantID && !s.Day.Before(truncateDay(from)) && s.Day.Before(to) {
+			out = append(out, s)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].Day.Equal(out[j].Day) {
+			return out[i].Day.Before(out[j].Day)
+		}
+		return out[i].Seq < out[j].Seq
+	})
+	return out, nil
+}
+
+// memLogs plays ClickHouse: logify.logs and the rehydration tables.
+type memLogs struct {
+	logs   []*domain.Record
+	tables map[string][]*domain.Record
+}
+
+func (l *memLogs) Days(_ context.Context, from, to time.Time) ([]domain.TenantDay, error) {
+	last := map[domain.TenantDay]time.Time{}
+	for _, r := range l.logs {
+		if r.Timestamp.Before(from) || !r.Timestamp.Before(to) {
+			continue
+		}
+		day := domain.TenantDay{TenantID: uuid.MustParse(r.TenantID), Day: truncateDay(r.Timestamp)}
+		if r.IngestionTime.After(last[day]) {
+			last[day] = r.IngestionTime
+		}
+	}
+	var days []domain.TenantDay
+	for day, at := range last {
+		day.LastIngested = at
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+func (l *memLogs) Export(_ context.Context, day domain.TenantDay, since *time.Time, until time.Time, fn func(*domain.Record) error) error {
+	for _, r := range l.logs {
+		if since != nil && !r.IngestionTime.After(*since) || r.IngestionTime.After(until) {
+			continue
+		}
+		if r.TenantID == day.TenantID.String() && truncateDay(r.Timestamp).Equal(day.Day) {
+			if err := fn(r); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (l *memLogs) CreateTable(_ context.Context, table string) error {
+	l.tables[table] = []*domain.Record{}
+	return nil
+}
+
+func (l *memLogs) Insert(_ context.Context, table string, records []*domain.Record) error {
+	l.tables[table] = append(l.tables[table], records...)
+	return nil
+}
+
+func (l *memLogs) DropTable(_ context.Context, table string) error {
+	delete(l.tables, table)
+	return nil
+}
+
+type memObjects struct {
+	objects map[string][]byte
+}
+
+func (s *memObjects) Put(_ context.Context, key string, body io.Reader, _ int64, sha256Hex, _ string) error {
+	b, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	s.objects[key] = b
+	return nil
+}
+
+func (s *memObjects) Get(_ context.Context, key string) (io.ReadCloser, *objectstore.ObjectInfo, error) {
+	b, ok := s.objects[key]
+	if !ok {
+		return nil, nil, objectstore.ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(b)), &objectstore.ObjectInfo{Key: key, Size: int64(len(b))}, nil
+}
+
+func (s *memObjects) Head(_ context.Context, key string) (*objectstore.ObjectInfo, error) {
+	b, ok := s.objects[key]
+	if !ok {
+		return nil, objectstore.ErrNotFound
+	}
+	return &objectstore.ObjectInfo{Key: key, Size: int64(len(b))}, nil
+}
+
+type allowProjects map[uuid.UUID]bool
+
+func (a allowProjects) HasPermission(_ context.Context, _, _ uuid.UUID, projectID *uuid.UUID, _, _ string) (bool, error) {
+	return a[*projectID], nil
+}
+
+type archiveFixture struct {
+	svc      *archiveService
+	jobs     *memJobs
+	segments *memSegments
+	logs     *memLogs
+	objects  *memObjects
+	tenantID uuid.UUID
+	project  uuid.UUID
+	ctx      context.Context
+	now      time.Time
+}
+
+func newArchiveFixture(t *testing.T) *archiveFixture {
+	t.Helper()
+	f := &archiveFixture{
+		jobs:     &memJobs{jobs: map[uuid.UUID]*domain.Job{}},
+		segments: &memSegments{},
+		logs:     &memLogs{tables: map[string][]*domain.Record{}},
+		objects:  &memObjects{objects: map[string][]byte{}},
+		tenantID: uuid.New(),
+		project:  uuid.New(),
+		now:      time.Date(2026, 7, 10, 12, 0, 0, 0, time.UTC),
+	}
+	f.svc = NewArchiveService(f.jobs, f.segments, f.logs, f.objects, allowProjects{f.project: true}, Config{Prefix: "logs"}, zap.NewNop()).(*archiveService)
+	f.svc.now = func() time.Time { return f.now }
+	f.ctx = middleware.WithClaims(context.Background(), &middleware.Claims{UserID: uuid.NewString(), TenantID: f.tenantID.String()})
+	return f
+}
+
+// addLog ingests a log ten minutes before the fixture's now, long enough ago
+// to be exported.
+func (f *archiveFixture) addLog(project uuid.UUID, at time.Time, msg string) {
+	f.logs.logs = append(f.logs.logs, &domain.Record{
+		ID: uuid.New(), TenantID: f.tenantID.String(), ProjectID: project.String(),
+		Timestamp: at, Level: "info", Message: msg, RetentionDays: 7, IngestionTime: f.now.Add(-10 * time.Minute),
+	})
+}
+
+func TestArchiveThenRehydrate(t *testing.T) {
+	f := newArchiveFixture(t)
+	other := uuid.New()
+	july8 := time.Date(2026, 7, 8, 0, 0, 0, 0, time.UTC)
+	f.addLog(f.project, july8.Add(time.Hour), "a")
+	f.addLog(f.project, july8.Add(20*time.Hour), "b")
+	f.addLog(other, july8.Add(2*time.Hour), "other project")
+	f.addLog(f.project, july8.Add(27*time.Hour), "July 9 is still open") // within After
+
+	require.Equal(t, 1, f.svc.archiveDue(context.Background()))
+	require.Equal(t, 0, f.svc.archiveDue(context.Background()), "archived days are not exported twice")
+
+	require.Len(t, f.segments.segments, 1)
+	segment := f.segments.segments[0]
+	assert.Equal(t, "logs/tenant="+f.tenantID.String()+"/dt=2026-07-08/logs.ndjson.gz", segment.ObjectKey)
+	assert.EqualValues(t, 3, segment.Rows)
+	assert.Len(t, segment.SHA256, 64)
+	assert.ElementsMatch(t, []string{f.project.String(), other.String()}, segment.Projects)
+	assert.Contains(t, f.objects.objects, segment.ManifestKey)
+
+	job, err := f.svc.RequestRehydration(f.ctx, RehydrateInput{ProjectID: f.project, From: july8, To: july8.Add(12 * time.Hour)})
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobPending, job.Status)
+
+	_, err = f.svc.Rehydration(context.Background(), f.tenantID, f.project, job.ID)
+	assert.ErrorIs(t, err, domain.ErrNotReady)
+
+	f.svc.rehydratePending(context.Background())
+	ready, err := f.svc.Rehydration(context.Background(), f.tenantID, f.project, job.ID)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, ready.Rows, "only the project's logs within the range are loaded")
+	loaded := f.logs.tables[ready.Table]
+	require.Len(t, loaded, 1)
+	assert.Equal(t, "a", loaded[0].Message)
+	assert.EqualValues(t, 7, loaded[0].RetentionDays)
+
+	_, err = f.svc.Rehydration(context.Background(), f.tenantID, other, job.ID)
+	assert.ErrorIs(t, err, domain.ErrJobNotFound)
+
+	f.now = f.now.Add(25 * time.Hour)
+	f.svc.expireRehydrations(context.Background())
+	assert.NotContains(t, f.logs.tables, ready.Table)
+	got, err := f.svc.GetJob(f.ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobExpired, got.Status)
+}
+
+func TestArchiveAddsSegmentForLateLogs(t *testing.T) {
+	f := newArchiveFixture(t)
+	july8 := time.Date(2026, 7, 8, 0, 0, 0, 0, time.UTC)
+	f.addLog(f.project, july8.Add(time.Hour), "a")
+	require.Equal(t, 1, f.svc.archiveDue(context.Background()))
+
+	// The archived log expires; a late one for the same day arrives.
+	f.logs.logs = nil
+	f.now = f.now.Add(time.Hour)
+	f.addLog(f.project, july8.Add(2*time.Hour), "replayed after an outage")
+	require.Equal(t, 1, f.svc.archiveDue(context.Background()), "a day with late logs is archived again")
+	require.Equal(t, 0, f.svc.archiveDue(context.Background()))
+
+	require.Len(t, f.segments.segments, 2)
+	first, late := f.segments.segments[0], f.segments.segments[1]
+	assert.EqualValues(t, 1, first.Rows, "the first segment still holds the expired log")
+	assert.Equal(t, 1, late.Seq)
+	assert.EqualValues(t, 1, late.Rows, "the late segment only holds the late log")
+	assert.Equal(t, "logs/tenant="+f.tenantID.String()+"/dt=2026-07-08/logs-0001.ndjson.gz", late.ObjectKey)
+	assert.NotEqual(t, first.ManifestKey, late.ManifestKey)
+
+	jobs, err := f.svc.jobs.List(context.Background(), domain.JobFilter{TenantID: f.tenantID, Kind: domain.JobArchive})
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, domain.JobSucceeded, jobs[0].Status)
+	assert.EqualValues(t, 2, jobs[0].Rows)
+	assert.Equal(t, 2, jobs[0].Segments)
+
+	job, err := f.svc.RequestRehydration(f.ctx, RehydrateInput{ProjectID: f.project, From: july8, To: july8.AddDate(0, 0, 1)})
+	require.NoError(t, err)
+	f.svc.rehydratePending(context.Background())
+	ready, err := f.svc.Rehydration(context.Background(), f.tenantID, f.project, job.ID)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, ready.Rows, "both segments are loaded")
+}
+
+func TestArchiveLeavesUnsettledLogsForLater(t *testing.T) {
+	f := newArchiveFixture(t)
+	july8 := time.Date(2026, 7, 8, 0, 0, 0, 0, time.UTC)
+	f.addLog(f.project, july8.Add(time.Hour), "a")
+	f.logs.logs = append(f.logs.logs, &domain.Record{
+		ID: uuid.New(), TenantID: f.tenantID.String(), ProjectID: f.project.String(),
+		Timestamp: july8.Add(3 * time.Hour), Message: "just ingested", IngestionTime: f.now,
+	})
+
+	require.Equal(t, 1, f.svc.archiveDue(context.Background()))
+	require.Len(t, f.segments.segments, 1)
+	assert.EqualValues(t, 1, f.segments.segments[0].Rows)
+
+	f.now = f.now.Add(time.Hour)
+	require.Equal(t, 1, f.svc.archiveDue(context.Background()))
+	require.Len(t, f.segments.segments, 2)
+	assert.EqualValues(t, 1, f.segments.segments[1].Rows)
+}
+
+func TestArchiveEmptyDayRecordsNoSegment(t *testing.T) {
+	f := newArchiveFixture(t)
+	july8 := time.Date(2026, 7, 8, 0, 0, 0, 0, time.UTC)
+	day := domain.TenantDay{TenantID: f.tenantID, Day: july8, LastIngested: f.now.Add(-time.Hour)} // expired before export
+
+	archived, err := f.svc.archiveDay(context.Background(), day)
+	require.NoError(t, err)
+	assert.False(t, archived)
+	jobs, err := f.svc.jobs.List(context.Background(), domain.JobFilter{TenantID: f.tenantID, Kind: domain.JobArchive})
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, 0, jobs[0].Segments)
+	assert.Empty(t, f.segments.segments)
+
+	f.now = f.now.Add(time.Hour)
+	f.addLog(f.project, july8.Add(time.Hour), "arrived later")
+	require.Equal(t, 1, f.svc.archiveDue(context.Background()))
+	require.Len(t, f.segments.segments, 1)
+	assert.Equal(t, 0, f.segments.segments[0].Seq)
+	assert.EqualValues(t, 1, f.segments.segments[0].Rows)
+}
+
+func TestRehydrateRejectsCorruptArchive(t *testing.T) {
+	f := newArchiveFixture(t)
+	july8 := time.Date(2026, 7, 8, 0, 0, 0, 0, time.UTC)
+	f.addLog(f.project, july8.Add(time.Hour), "a")
+	require.Equal(t, 1, f.svc.archiveDue(context.Background()))
+
+	key := f.segments.segments[0].ObjectKey
+	f.objects.objects[key] = append(f.objects.objects[key], 0)
+
+	job, err := f.svc.RequestRehydration(f.ctx, RehydrateInput{ProjectID: f.project, From: july8, To: july8.AddDate(0, 0, 1)})
+	require.NoError(t, err)
+	f.svc.rehydratePending(context.Background())
+
+	got, err := f.svc.GetJob(f.ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobFailed, got.Status)
+	assert.Contains(t, got.Error, domain.ErrChecksumMismatch.Error())
+	assert.NotContains(t, f.logs.tables, got.Table, "a failed load leaves no table behind")
+}
+
+func TestRequestRehydrationValidates(t *testing.T) {
+	f := newArchiveFixture(t)
+	from := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := f.svc.RequestRehydration(context.Background(), RehydrateInput{ProjectID: f.project, From: from, To: from.Add(time.Hour)})
+	assert.ErrorIs(t, err, domain.ErrTenantRequired)
+	_, err = f.svc.RequestRehydration(f.ctx, RehydrateInput{ProjectID: uuid.New(), From: from, To: from.Add(time.Hour)})
+	assert.ErrorIs(t, err, domain.ErrProjectNotFound)
+	_, err = f.svc.RequestRehydration(f.ctx, RehydrateInput{ProjectID: f.project, From: from, To: from})
+	assert.ErrorIs(t, err, domain.ErrInvalidRange)
+	_, err = f.svc.RequestRehydration(f.ctx, RehydrateInput{ProjectID: f.project, From: from, To: from.AddDate(0, 2, 0)})
+	assert.ErrorIs(t, err, domain.ErrRangeTooLarge)
+
+	disabled := NewArchiveService(f.jobs, f.segments, f.logs, nil, allowProjects{}, Config{}, zap.NewNop())
+	_, err = disabled.RequestRehydration(f.ctx, RehydrateInput{ProjectID: f.project, From: from, To: from.Add(time.Hour)})
+	assert.ErrorIs(t, err, domain.ErrDisabled)
+}
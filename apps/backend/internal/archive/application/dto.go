@@ -0,0 +1,35 @@
+package application
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/archive/domain"
+)
+
+// RehydrateInput asks for a project's archived logs in [From, To) to be
+// loaded back into ClickHouse.
+type RehydrateInput struct {
+	ProjectID uuid.UUID `json:"project_id" validate:"required"`
+	From      time.Time `json:"from"       validate:"required"`
+	To        time.Time `json:"to"         validate:"required"`
+}
+
+// ListJobsInput filters the caller's tenant jobs.
+type ListJobsInput struct {
+	Kind   domain.JobKind
+	Status domain.JobStatus
+	Limit  int
+}
+
+type ListJobsOutput struct {
+	Jobs []*domain.Job `json:"jobs"`
+}
+
+// ListSegmentsOutput is the archive index of a time range.
+type ListSegmentsOutput struct {
+	Segments   []*domain.Segment `json:"segments"`
+	TotalRows  uint64            `json:"total_rows"`
+	TotalBytes int64             `json:"total_bytes"`
+}
@@ -0,0 +1,22 @@
+package domain
+
+import "errors"
+
+// Domain errors. Service / transport layers compare against these with
+// errors.Is to map them onto HTTP status codes.
+var (
+	ErrTenantRequired  = errors.New("tenant is required")
+	ErrProjectRequired = errors.New("project_id is required")
+	ErrProjectNotFound = errors.New("project not found")
+	ErrJobNotFound     = errors.New("archive job not found")
+	ErrInvalidRange    = errors.New("from must be before to")
+	// ErrRangeTooLarge is a rehydration spanning more days than allowed.
+	ErrRangeTooLarge = errors.New("time range is too large to rehydrate")
+	// ErrNotReady is a rehydration that is still loading, failed or expired.
+	ErrNotReady = errors.New("rehydration is not ready")
+	// ErrDisabled is returned when no object storage is configured.
+	ErrDisabled = errors.New("archival is not enabled")
+	// ErrChecksumMismatch is an archive whose content does not match the
+	// checksum recorded when it was written.
+	ErrChecksumMismatch = errors.New("archive checksum mismatch")
+)
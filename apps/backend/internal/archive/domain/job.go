@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type JobKind string
+
+const (
+	// JobArchive exports one tenant-day to object storage.
+	JobArchive JobKind = "archive"
+	// JobRehydrate loads a project's archived range into a ClickHouse table.
+	JobRehydrate JobKind = "rehydrate"
+)
+
+func (k JobKind) IsValid() bool {
+	return k == JobArchive || k == JobRehydrate
+}
+
+type JobStatus string
+
+const (
+	JobPending   JobStatus = "pending"
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+	// JobExpired is a rehydration whose table has been dropped.
+	JobExpired JobStatus = "expired"
+)
+
+func (s JobStatus) IsValid() bool {
+	switch s {
+	case JobPending, JobRunning, JobSucceeded, JobFailed, JobExpired:
+		return true
+	}
+	return false
+}
+
+// Job tracks an archive or rehydration. For archive jobs From is the day
+// archived, Rows, Bytes and Segments add up every segment of the day, and
+// IngestedThrough is how far the day's logs, by ingestion time, have been
+// exported; for rehydrations Table is where the logs are loaded and
+// ExpiresAt when it is dropped.
+type Job struct {
+	ID        uuid.UUID  `json:"id"`
+	TenantID  uuid.UUID  `json:"tenant_id"`
+	Kind      JobKind    `json:"kind"`
+	Status    JobStatus  `json:"status"`
+	ProjectID *uuid.UUID `json:"project_id,omitempty"`
+	From      time.Time  `json:"from"`
+	To        time.Time  `json:"to"`
+	Table     string     `json:"-"`
+	Rows      uint64     `json:"rows"`
+	Bytes     int64      `json:"bytes"`
+	Segments  int        `json:"segments"`
+	Error     string     `json:"error,omitempty"`
+	Attempts  int        `json:"attempts"`
+	// IngestedThrough is only set on archive jobs.
+	IngestedThrough *time.Time `json:"ingested_through,omitempty"`
+	RequestedBy     *uuid.UUID `json:"requested_by,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	StartedAt       *time.Time `json:"started_at,omitempty"`
+	FinishedAt      *time.Time `json:"finished_at,omitempty"`
+	ExpiresAt       *time.Time `json:"expires_at,omitempty"`
+}
+
+// RehydrationTable names the ClickHouse table a rehydration loads into. The
+// name derives from the job id only, so it is safe to splice into SQL.
+func RehydrationTable(jobID uuid.UUID) string {
+	return "logify.rehydrated_" + strings.ReplaceAll(jobID.String(), "-", "")
+}
+
+// JobFilter selects a tenant's jobs, newest first.
+type JobFilter struct {
+	TenantID uuid.UUID
+	Kind     JobKind
+	Status   JobStatus
+	Limit    int
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Record is one archived log: a row of logify.logs. It is written to the
+// archive as a line of NDJSON and read back into ClickHouse on rehydration,
+// hence both tag sets.
+type Record struct {
+	ID            uuid.UUID         `ch:"id"             json:"id"`
+	TenantID      string            `ch:"tenant_id"      json:"tenant_id"`
+	ProjectID     string            `ch:"project_id"     json:"project_id"`
+	Timestamp     time.Time         `ch:"timestamp"      json:"timestamp"`
+	Level         string            `ch:"level"          json:"level"`
+	Service       string            `ch:"service"        json:"service"`
+	Namespace     string            `ch:"namespace"      json:"namespace,omitempty"`
+	Environment   string            `ch:"environment"    json:"environment,omitempty"`
+	Host          string            `ch:"host"           json:"host,omitempty"`
+	Source        string            `ch:"source"         json:"source,omitempty"`
+	TraceID       string            `ch:"trace_id"       json:"trace_id,omitempty"`
+	SpanID        string            `ch:"span_id"        json:"span_id,omitempty"`
+	RequestID     string            `ch:"request_id"     json:"request_id,omitempty"`
+	UserID        string            `ch:"user_id"        json:"user_id,omitempty"`
+	Message       string            `ch:"message"        json:"message"`
+	Tags          map[string]string `ch:"tags"           json:"tags,omitempty"`
+	Attributes    map[string]string `ch:"attributes"     json:"attributes,omitempty"`
+	IngestionTime time.Time         `ch:"ingestion_time" json:"ingestion_time"`
+	RetentionDays uint16            `ch:"retention_days" json:"retention_days"`
+	SampleRate    uint32            `ch:"sample_rate"    json:"sample_rate,omitempty"`
+}
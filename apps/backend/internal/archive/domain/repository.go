@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SegmentRepository is the archive index (Postgres).
+type SegmentRepository interface {
+	// Save upserts on the tenant, day and sequence number.
+	Save(ctx context.Context, s *Segment) error
+	// List returns the tenant's segments whose day falls in [from, to),
+	// oldest first.
+	List(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]*Segment, error)
+}
+
+// JobRepository tracks archive and rehydration jobs (Postgres). Claims are
+// safe across replicas: a job is handed to one worker at a time.
+type JobRepository interface {
+	Create(ctx context.Context, job *Job) error
+	Get(ctx context.Context, tenantID, id uuid.UUID) (*Job, error)
+	List(ctx context.Context, f JobFilter) ([]*Job, error)
+	// ClaimArchive starts the archive job of a tenant-day. It returns nil
+	// when the day is archived through day.LastIngested, being archived, or
+	// has failed maxAttempts times. Running jobs started before staleBefore
+	// are taken over, and an archived day that has since gained late logs is
+	// claimed again so they are archived in a further segment. The job keeps
+	// the totals and IngestedThrough of the segments written so far.
+	ClaimArchive(ctx context.Context, day TenantDay, maxAttempts int, staleBefore time.Time) (*Job, error)
+	// ClaimRehydration starts the oldest pending rehydration, or returns nil.
+	ClaimRehydration(ctx context.Context) (*Job, error)
+	// Finish records the outcome of a claimed job.
+	Finish(ctx context.Context, job *Job) error
+	// Expired returns successful rehydrations whose table is due to be
+	// dropped.
+	Expired(ctx context.Context, now time.Time) ([]*Job, error)
+}
+
+// LogStore reads logs out of and loads them back into ClickHouse.
+type LogStore interface {
+	// Days returns the tenant-days with logs in [from, to).
+	Days(ctx context.Context, from, to time.Time) ([]TenantDay, error)
+	// Export streams, in timestamp order, a tenant-day's logs ingested after
+	// since (from the start when nil) and no later than until.
+	Export(ctx context.Context, day TenantDay, since *time.Time, until time.Time, fn func(*Record) error) error
+	// CreateTable creates an empty table shaped like logify.logs, without
+	// its TTL, so old logs loaded into it are not expired at once.
+	CreateTable(ctx context.Context, table string) error
+	Insert(ctx context.Context, table string, records []*Record) error
+	DropTable(ctx context.Context, table string) error
+}
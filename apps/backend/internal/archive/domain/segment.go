@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FormatNDJSONGzip is the archive format: one JSON Record per line, gzipped.
+const FormatNDJSONGzip = "ndjson.gz"
+
+// ManifestVersion is bumped when Record or the layout changes.
+const ManifestVersion = 2
+
+// Segment is an archive of one tenant's logs for one UTC day. The first
+// export of a day is segment 0; logs ingested for the day afterwards are
+// archived as further segments, so an export never replaces logs that may
+// have expired from ClickHouse since. The segments table is the index
+// rehydration reads; each segment also has a manifest object next to its
+// data so the bucket is self-describing.
+type Segment struct {
+	TenantID    uuid.UUID  `json:"tenant_id"`
+	Day         time.Time  `json:"day"`
+	Seq         int        `json:"seq"`
+	ObjectKey   string     `json:"object_key"`
+	ManifestKey string     `json:"manifest_key"`
+	Format      string     `json:"format"`
+	Rows        uint64     `json:"rows"`
+	Bytes       int64      `json:"bytes"`
+	SHA256      string     `json:"sha256"`
+	MinTime     *time.Time `json:"min_time,omitempty"`
+	MaxTime     *time.Time `json:"max_time,omitempty"`
+	// Projects lists the projects with logs in the segment.
+	Projects  []string  `json:"projects"`
+	JobID     uuid.UUID `json:"job_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Manifest is the JSON object written next to a segment's data.
+type Manifest struct {
+	Version int `json:"version"`
+	Segment
+}
+
+// SegmentKeys returns where a segment's data and manifest live. The layout is
+// Hive-style so external engines can read the bucket directly.
+func SegmentKeys(prefix string, tenantID uuid.UUID, day time.Time, seq int) (object, manifest string) {
+	dir := fmt.Sprintf("tenant=%s/dt=%s", tenantID, day.UTC().Format(time.DateOnly))
+	if prefix != "" {
+		dir = prefix + "/" + dir
+	}
+	if seq == 0 {
+		return dir + "/logs." + FormatNDJSONGzip, dir + "/manifest.json"
+	}
+	return fmt.Sprintf("%s/logs-%04d.%s", dir, seq, FormatNDJSONGzip), fmt.Sprintf("%s/manifest-%04d.json", dir, seq)
+}
+
+// TenantDay is a tenant's logs of one UTC day, the unit of archival.
+type TenantDay struct {
+	TenantID uuid.UUID
+	Day      time.Time
+	// LastIngested is the ingestion time of the day's newest log.
+	LastIngested time.Time
+}
@@ -0,0 +1,153 @@
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	ch "github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/google/uuid"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/archive/domain"
+)
+
+const (
+	logsTable = "logify.logs"
+
+	recordColumns = `id, tenant_id, project_id, timestamp, level, service, namespace,
+		environment, host, source, trace_id, span_id, request_id, user_id,
+		message, tags, attributes, ingestion_time, retention_days, sample_rate`
+)
+
+// tableName guards the identifiers spliced into DDL; rehydration tables are
+// named by domain.RehydrationTable.
+var tableName = regexp.MustCompile(`^logify\.rehydrated_[0-9a-f]{32}$`)
+
+type LogStore struct {
+	conn ch.Conn
+}
+
+func NewLogStore(conn ch.Conn) *LogStore {
+	return &LogStore{conn: conn}
+}
+
+func (s *LogStore) Days(ctx context.Context, from, to time.Time) ([]domain.TenantDay, error) {
+	query := fmt.Sprintf(`
+		SELECT tenant_id, toDate(timestamp) AS day, max(ingestion_time)
+		FROM %s
+		WHERE timestamp >= ? AND timestamp < ?
+		GROUP BY tenant_id, day
+		ORDER BY day, tenant_id`, logsTable)
+	rows, err := s.conn.Query(ctx, query, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("clickhouse archive days: %w", err)
+	}
+	defer rows.Close()
+
+	var days []domain.TenantDay
+	for rows.Next() {
+		var (
+			tenant string
+			day    time.Time
+			last   time.Time
+		)
+		if err := rows.Scan(&tenant, &day, &last); err != nil {
+			return nil, fmt.Errorf("clickhouse scan archive day: %w", err)
+		}
+		tenantID, err := uuid.Parse(tenant)
+		if err != nil {
+			// Rows without a valid tenant are unreachable by search and
+			// are not archived either.
+			continue
+		}
+		days = append(days, domain.TenantDay{TenantID: tenantID, Day: day.UTC(), LastIngested: last.UTC()})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("clickhouse archive days rows: %w", err)
+	}
+	return days, nil
+}
+
+func (s *LogStore) Export(ctx context.Context, day domain.TenantDay, since *time.Time, until time.Time, fn func(*domain.Record) error) error {
+	start := day.Day.UTC().Truncate(24 * time.Hour)
+	args := []any{day.TenantID.String(), start, start.AddDate(0, 0, 1), until.UTC()}
+	ingested := "ingestion_time <= ?"
+	if since != nil {
+		ingested += " AND ingestion_time > ?"
+		args = append(args, since.UTC())
+	}
+	query := fmt.Sprintf(`
+		SELECT %s FROM %s
+		WHERE tenant_id = ? AND timestamp >= ? AND timestamp < ? AND %s
+		ORDER BY timestamp`, recordColumns, logsTable, ingested)
+	rows, err := s.conn.Query(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("clickhouse archive export: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r domain.Record
+		if err := rows.ScanStruct(&r); err != nil {
+			return fmt.Errorf("clickhouse scan archive record: %w", err)
+		}
+		if err := fn(&r); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("clickhouse archive export rows: %w", err)
+	}
+	return nil
+}
+
+// CreateTable copies the columns of logify.logs but not its engine clause,
+// which carries the TTL.
+func (s *LogStore) CreateTable(ctx context.Context, table string) error {
+	if !tableName.MatchString(table) {
+		return fmt.Errorf("clickhouse: invalid rehydration table %q", table)
+	}
+	query := fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s AS %s ENGINE = MergeTree ORDER BY (tenant_id, project_id, timestamp)",
+		table, logsTable,
+	)
+	if err := s.conn.Exec(ctx, query); err != nil {
+		return fmt.Errorf("clickhouse create rehydration table: %w", err)
+	}
+	return nil
+}
+
+func (s *LogStore) Insert(ctx context.Context, table string, records []*domain.Record) error {
+	if !tableName.MatchString(table) {
+		return fmt.Errorf("clickhouse: invalid rehydration table %q", table)
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	batch, err := s.conn.PrepareBatch(ctx, fmt.Sprintf("INSERT INTO %s (%s)", table, recordColumns))
+	if err != nil {
+		return fmt.Errorf("clickhouse: prepare rehydration batch: %w", err)
+	}
+	for _, r := range records {
+		// Archives written before sampling carry no rate.
+		r.SampleRate = max(r.SampleRate, 1)
+		if err := batch.AppendStruct(r); err != nil {
+			return fmt.Errorf("clickhouse: append rehydration record: %w", err)
+		}
+	}
+	if err := batch.Send(); err != nil {
+		return fmt.Errorf("clickhouse: send rehydration batch: %w", err)
+	}
+	return nil
+}
+
+func (s *LogStore) DropTable(ctx context.Context, table string) error {
+	if !tableName.MatchString(table) {
+		return fmt.Errorf("clickhouse: invalid rehydration table %q", table)
+	}
+	if err := s.conn.Exec(ctx, fmt.Sprintf("DROP TABLE IF EXISTS %s", table)); err != nil {
+		return fmt.Errorf("clickhouse drop rehydration table: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/archive/domain"
+)
+
+const jobColumns = `
+	id, tenant_id, kind, status, project_id, range_from, range_to, table_name,
+	rows, bytes, segments, error, attempts, requested_by,
+	created_at, started_at, finished_at, expires_at, ingested_through`
+
+type jobRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewJobRepository(db *pgxpool.Pool) domain.JobRepository {
+	return &jobRepository{db: db}
+}
+
+func (r *jobRepository) Create(ctx context.Context, job *domain.Job) error {
+	const query = `
+		INSERT INTO archive_jobs (id, tenant_id, kind, status, project_id, range_from, range_to, table_name, requested_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING created_at
+	`
+	return r.db.QueryRow(ctx, query,
+		job.ID, job.TenantID, job.Kind, job.Status, job.ProjectID, job.From, job.To, job.Table, job.RequestedBy,
+	).Scan(&job.CreatedAt)
+}
+
+func (r *jobRepository) Get(ctx context.Context, tenantID, id uuid.UUID) (*domain.Job, error) {
+	query := fmt.Sprintf(`SELECT %s FROM archive_jobs WHERE id = $1 AND tenant_id = $2`, jobColumns)
+	job, err := scanJob(r.db.QueryRow(ctx, query, id, tenantID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrJobNotFound
+	}
+	return job, err
+}
+
+func (r *jobRepository) List(ctx context.Context, f domain.JobFilter) ([]*domain.Job, error) {
+	conds := []string{"tenant_id = $1"}
+	args := []any{f.TenantID}
+	if f.Kind != "" {
+		args = append(args, f.Kind)
+		conds = append(conds, fmt.Sprintf("kind = $%d", len(args)))
+	}
+	if f.Status != "" {
+		args = append(args, f.Status)
+		conds = append(conds, fmt.Sprintf("status = $%d", len(args)))
+	}
+	args = append(args, f.Limit)
+	query := fmt.Sprintf(`
+		SELECT %s FROM archive_jobs
+		WHERE %s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d
+	`, jobColumns, strings.Join(conds, " AND "), len(args))
+	return r.queryJobs(ctx, query, args...)
+}
+
+func (r *jobRepository) ClaimArchive(ctx context.Context, day domain.TenantDay, maxAttempts int, staleBefore time.Time) (*domain.Job, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO archive_jobs (tenant_id, kind, status, range_from, range_to, attempts, started_at)
+		VALUES ($1, 'archive', 'running', $2, $3, 1, now() AT TIME ZONE 'utc')
+		ON CONFLICT (tenant_id, range_from) WHERE kind = 'archive' DO UPDATE
+		SET status      = 'running',
+		    attempts    = CASE WHEN archive_jobs.status = 'succeeded' THEN 1 ELSE archive_jobs.attempts + 1 END,
+		    error       = '',
+		    started_at  = now() AT TIME ZONE 'utc',
+		    finished_at = NULL
+		WHERE (archive_jobs.status = 'failed' AND archive_jobs.attempts < $4)
+		   OR (archive_jobs.status = 'running' AND archive_jobs.started_at < $5)
+		   OR (archive_jobs.status = 'succeeded' AND archive_jobs.ingested_through < $6)
+		RETURNING %s
+	`, jobColumns)
+	job, err := scanJob(r.db.QueryRow(ctx, query,
+		day.TenantID, day.Day.UTC(), day.Day.UTC().AddDate(0, 0, 1), maxAttempts, staleBefore, day.LastIngested.UTC(),
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return job, err
+}
+
+func (r *jobRepository) ClaimRehydration(ctx context.Context) (*domain.Job, error) {
+	query := fmt.Sprintf(`
+		UPDATE archive_jobs
+		SET status = 'running', attempts = attempts + 1, started_at = now() AT TIME ZONE 'utc'
+		WHERE id = (
+			SELECT id FROM archive_jobs
+			WHERE kind = 'rehydrate' AND status = 'pending'
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING %s
+	`, jobColumns)
+	job, err := scanJob(r.db.QueryRow(ctx, query))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return job, err
+}
+
+func (r *jobRepository) Finish(ctx context.Context, job *domain.Job) error {
+	const query = `
+		UPDATE archive_jobs
+		SET status = $2, rows = $3, bytes = $4, segments = $5, error = $6,
+		    finished_at = now() AT TIME ZONE 'utc', expires_at = $7, ingested_through = $8
+		WHERE id = $1
+		RETURNING finished_at
+	`
+	return r.db.QueryRow(ctx, query,
+		job.ID, job.Status, int64(job.Rows), job.Bytes, job.Segments, job.Error, job.ExpiresAt, job.IngestedThrough,
+	).Scan(&job.FinishedAt)
+}
+
+func (r *jobRepository) Expired(ctx context.Context, now time.Time) ([]*domain.Job, error) {
+	query := fmt.Sprintf(`
+		SELECT %s FROM archive_jobs
+		WHERE kind = 'rehydrate' AND status = 'succeeded' AND expires_at <= $1
+		ORDER BY expires_at
+	`, jobColumns)
+	return r.queryJobs(ctx, query, now)
+}
+
+func (r *jobRepository) queryJobs(ctx context.Context, query string, args ...any) ([]*domain.Job, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := make([]*domain.Job, 0)
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+func scanJob(row pgx.Row) (*domain.Job, error) {
+	var (
+		j     domain.Job
+		count int64
+	)
+	err := row.Scan(
+		&j.ID, &j.TenantID, &j.Kind, &j.Status, &j.ProjectID, &j.From, &j.To, &j.Table,
+		&count, &j.Bytes, &j.Segments, &j.Error, &j.Attempts, &j.RequestedBy,
+		&j.CreatedAt, &j.StartedAt, &j.FinishedAt, &j.ExpiresAt, &j.IngestedThrough,
+	)
+	if err != nil {
+		return nil, err
+	}
+	j.Rows = uint64(count)
+	return &j, nil
+}
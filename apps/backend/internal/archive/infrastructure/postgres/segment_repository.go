@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/archive/domain"
+)
+
+type segmentRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewSegmentRepository(db *pgxpool.Pool) domain.SegmentRepository {
+	return &segmentRepository{db: db}
+}
+
+// Save upserts: a segment rewritten by a job taken over from a dead worker
+// replaces the one it left.
+func (r *segmentRepository) Save(ctx context.Context, s *domain.Segment) error {
+	const query = `
+		INSERT INTO archive_segments (
+			tenant_id, day, seq, object_key, manifest_key, format, rows, bytes, sha256,
+			min_time, max_time, projects, job_id
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (tenant_id, day, seq) DO UPDATE
+		SET object_key   = EXCLUDED.object_key,
+		    manifest_key = EXCLUDED.manifest_key,
+		    format       = EXCLUDED.format,
+		    rows         = EXCLUDED.rows,
+		    bytes        = EXCLUDED.bytes,
+		    sha256       = EXCLUDED.sha256,
+		    min_time     = EXCLUDED.min_time,
+		    max_time     = EXCLUDED.max_time,
+		    projects     = EXCLUDED.projects,
+		    job_id       = EXCLUDED.job_id,
+		    created_at   = now() AT TIME ZONE 'utc'
+		RETURNING created_at
+	`
+	return r.db.QueryRow(ctx, query,
+		s.TenantID, s.Day, s.Seq, s.ObjectKey, s.ManifestKey, s.Format, int64(s.Rows), s.Bytes, s.SHA256,
+		s.MinTime, s.MaxTime, s.Projects, s.JobID,
+	).Scan(&s.CreatedAt)
+}
+
+func (r *segmentRepository) List(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]*domain.Segment, error) {
+	const query = `
+		SELECT tenant_id, day, seq, object_key, manifest_key, format, rows, bytes, sha256,
+		       min_time, max_time, projects, job_id, created_at
+		FROM archive_segments
+		WHERE tenant_id = $1 AND day >= $2::date AND day < $3::date
+		ORDER BY day, seq
+	`
+	rows, err := r.db.Query(ctx, query, tenantID, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	segments := make([]*domain.Segment, 0)
+	for rows.Next() {
+		var (
+			s     domain.Segment
+			count int64
+		)
+		if err := rows.Scan(
+			&s.TenantID, &s.Day, &s.Seq, &s.ObjectKey, &s.ManifestKey, &s.Format, &count, &s.Bytes, &s.SHA256,
+			&s.MinTime, &s.MaxTime, &s.Projects, &s.JobID, &s.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		s.Rows = uint64(count)
+		segments = append(segments, &s)
+	}
+	return segments, rows.Err()
+}
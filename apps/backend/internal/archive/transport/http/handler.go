@@ -0,0 +1,211 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/archive/application"
+	"github.com/indalyadav56/logify/apps/backend/internal/archive/domain"
+	"github.com/indalyadav56/logify/apps/backend/pkg/response"
+	"github.com/indalyadav56/logify/apps/backend/pkg/validator"
+)
+
+type ArchiveHandler struct {
+	service application.ArchiveService
+}
+
+func NewArchiveHandler(service application.ArchiveService) *ArchiveHandler {
+	return &ArchiveHandler{service: service}
+}
+
+// RequestRehydration queues the load of archived logs into ClickHouse.
+// @Summary      Rehydrate archived logs
+// @Description  Queue the load of a project's archived logs in [from, to) into a temporary table. Poll the returned job; once it has succeeded, pass its id as rehydration_id to /v1/logs/search or /v1/logs/aggregate until expires_at.
+// @Tags         archive
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      application.RehydrateInput  true  "Project and time range"
+// @Success      202      {object}  response.APIResponse{data=domain.Job} "Rehydration queued"
+// @Failure      400      {object}  response.APIResponse "Invalid request"
+// @Failure      404      {object}  response.APIResponse "Project not found"
+// @Failure      503      {object}  response.APIResponse "Archival not enabled"
+// @Router       /v1/archive/rehydrations [post]
+func (h *ArchiveHandler) RequestRehydration(c *gin.Context) {
+	var input application.RehydrateInput
+	if !validator.ValidateRequest(c, &input) {
+		return
+	}
+
+	job, err := h.service.RequestRehydration(c.Request.Context(), input)
+	if err != nil {
+		writeError(c, err, "Failed to queue rehydration")
+		return
+	}
+	response.Success(c, http.StatusAccepted, "Rehydration queued", job)
+}
+
+// DropRehydration drops a rehydrated table before it expires.
+// @Summary      Drop rehydrated logs
+// @Description  Drop the temporary table of a finished rehydration.
+// @Tags         archive
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Job ID (UUID)"
+// @Success      200  {object}  response.APIResponse{data=domain.Job} "Rehydration dropped"
+// @Failure      404  {object}  response.APIResponse "Job not found"
+// @Failure      409  {object}  response.APIResponse "Rehydration not ready"
+// @Router       /v1/archive/rehydrations/{id} [delete]
+func (h *ArchiveHandler) DropRehydration(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	job, err := h.service.DropRehydration(c.Request.Context(), id)
+	if err != nil {
+		writeError(c, err, "Failed to drop rehydration")
+		return
+	}
+	response.OK(c, "Rehydration dropped", job)
+}
+
+// GetJob returns an archive or rehydration job.
+// @Summary      Get archive job
+// @Description  Return the status of an archive or rehydration job of the caller's tenant.
+// @Tags         archive
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Job ID (UUID)"
+// @Success      200  {object}  response.APIResponse{data=domain.Job} "Job retrieved successfully"
+// @Failure      404  {object}  response.APIResponse "Job not found"
+// @Router       /v1/archive/jobs/{id} [get]
+func (h *ArchiveHandler) GetJob(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	job, err := h.service.GetJob(c.Request.Context(), id)
+	if err != nil {
+		writeError(c, err, "Failed to retrieve job")
+		return
+	}
+	response.OK(c, "Job retrieved successfully", job)
+}
+
+// ListJobs lists the caller's tenant archive and rehydration jobs.
+// @Summary      List archive jobs
+// @Description  Archive and rehydration jobs of the caller's tenant, newest first.
+// @Tags         archive
+// @Produce      json
+// @Security     BearerAuth
+// @Param        kind    query     string  false  "archive or rehydrate"
+// @Param        status  query     string  false  "pending, running, succeeded, failed or expired"
+// @Param        limit   query     int     false  "Page size (default 50, max 200)"
+// @Success      200  {object}  response.APIResponse{data=application.ListJobsOutput} "Jobs retrieved successfully"
+// @Failure      400  {object}  response.APIResponse "Invalid query"
+// @Router       /v1/archive/jobs [get]
+func (h *ArchiveHandler) ListJobs(c *gin.Context) {
+	input := application.ListJobsInput{
+		Kind:   domain.JobKind(c.Query("kind")),
+		Status: domain.JobStatus(c.Query("status")),
+	}
+	if input.Kind != "" && !input.Kind.IsValid() {
+		response.BadRequest(c, "kind must be archive or rehydrate")
+		return
+	}
+	if input.Status != "" && !input.Status.IsValid() {
+		response.BadRequest(c, "Invalid status")
+		return
+	}
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			response.BadRequest(c, "limit must be a positive integer")
+			return
+		}
+		input.Limit = limit
+	}
+
+	out, err := h.service.ListJobs(c.Request.Context(), input)
+	if err != nil {
+		writeError(c, err, "Failed to list jobs")
+		return
+	}
+	response.OK(c, "Jobs retrieved successfully", out)
+}
+
+// ListSegments returns the archive index of a time range.
+// @Summary      List archived segments
+// @Description  The caller's tenant archive: one segment per UTC day, with its object key, row count, size and SHA-256.
+// @Tags         archive
+// @Produce      json
+// @Security     BearerAuth
+// @Param        from  query     string  true  "Earliest day, RFC 3339"
+// @Param        to    query     string  true  "Latest time (exclusive), RFC 3339"
+// @Success      200  {object}  response.APIResponse{data=application.ListSegmentsOutput} "Segments retrieved successfully"
+// @Failure      400  {object}  response.APIResponse "Invalid query"
+// @Router       /v1/archive/segments [get]
+func (h *ArchiveHandler) ListSegments(c *gin.Context) {
+	from, ok := timeQuery(c, "from")
+	if !ok {
+		return
+	}
+	to, ok := timeQuery(c, "to")
+	if !ok {
+		return
+	}
+
+	out, err := h.service.ListSegments(c.Request.Context(), from, to)
+	if err != nil {
+		writeError(c, err, "Failed to list segments")
+		return
+	}
+	response.OK(c, "Segments retrieved successfully", out)
+}
+
+func timeQuery(c *gin.Context, name string) (time.Time, bool) {
+	t, err := time.Parse(time.RFC3339, c.Query(name))
+	if err != nil {
+		response.BadRequest(c, name+" must be an RFC 3339 time")
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+func parseUUIDParam(c *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		response.BadRequest(c, "Invalid "+name+" format")
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// writeError maps domain errors to HTTP responses with a consistent envelope.
+func writeError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrTenantRequired):
+		response.Unauthorized(c, "Tenant context required")
+	case errors.Is(err, domain.ErrProjectNotFound):
+		response.NotFound(c, "Project not found")
+	case errors.Is(err, domain.ErrJobNotFound):
+		response.NotFound(c, "Job not found")
+	case errors.Is(err, domain.ErrNotReady):
+		response.Conflict(c, err.Error())
+	case errors.Is(err, domain.ErrProjectRequired),
+		errors.Is(err, domain.ErrInvalidRange),
+		errors.Is(err, domain.ErrRangeTooLarge):
+		response.BadRequest(c, err.Error())
+	case errors.Is(err, domain.ErrDisabled):
+		response.Error(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "Archival is not enabled")
+	default:
+		response.InternalServerError(c, fallback)
+	}
+}
@@ -0,0 +1,24 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+
+	roleDomain "github.com/indalyadav56/logify/apps/backend/internal/role/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
+)
+
+// RegisterRoutes mounts the archive routes. The group is expected to be
+// already authenticated (see di.RegisterAllRoutes). Rehydrations are further
+// checked against the project in the service.
+func RegisterRoutes(router *gin.RouterGroup, handler *ArchiveHandler, authz *middleware.Authorizer) {
+	canRead := authz.RequirePermission(roleDomain.ResourceLog, roleDomain.ActionRead)
+
+	g := router.Group("/v1/archive")
+	{
+		g.GET("/segments", canRead, handler.ListSegments)
+		g.GET("/jobs", canRead, handler.ListJobs)
+		g.GET("/jobs/:id", canRead, handler.GetJob)
+		g.POST("/rehydrations", canRead, handler.RequestRehydration)
+		g.DELETE("/rehydrations/:id", canRead, handler.DropRehydration)
+	}
+}
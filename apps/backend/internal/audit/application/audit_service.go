@@ -0,0 +1,302 @@
+package application
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/audit/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware/requestctx"
+)
+
+const (
+	defaultRetention     = 365 * 24 * time.Hour
+	defaultPurgeInterval = time.Hour
+	purgeBatch           = 1000
+
+	defaultListLimit = 50
+	maxListLimit     = 500
+
+	// recordTimeout bounds the write of one event; it runs detached from
+	// the request so a client hanging up cannot drop the record.
+	recordTimeout = 5 * time.Second
+)
+
+// AuditService keeps the audit trail: it records events from the other
+// contexts and from AuditLog, serves the query API and enforces retention.
+type AuditService interface {
+	domain.Recorder
+	middleware.RequestAuditor
+	// ListEvents returns events of the caller's tenant, newest first.
+	ListEvents(ctx context.Context, input ListEventsInput) (*ListEventsOutput, error)
+	// ListAllEvents returns events of every tenant, or of input.TenantID,
+	// for platform operators.
+	ListAllEvents(ctx context.Context, input ListEventsInput) (*ListEventsOutput, error)
+	// Purge deletes events older than the retention period.
+	Purge(ctx context.Context) (int64, error)
+	// Run purges on an interval until ctx is cancelled.
+	Run(ctx context.Context)
+}
+
+type auditService struct {
+	repo      domain.Repository
+	retention time.Duration
+	interval  time.Duration
+	logger    *zap.Logger
+	now       func() time.Time
+}
+
+// NewAuditService keeps events for retention (a year when zero), purging
+// every interval.
+func NewAuditService(repo domain.Repository, retention, interval time.Duration, logger *zap.Logger) AuditService {
+	if retention <= 0 {
+		retention = defaultRetention
+	}
+	if interval <= 0 {
+		interval = defaultPurgeInterval
+	}
+	return &auditService{
+		repo:      repo,
+		retention: retention,
+		interval:  interval,
+		logger:    logger.Named("audit_service"),
+		now:       func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Record fills in what the caller left empty from the request context: the
+// actor and tenant from the claims, the client and the request id.
+func (s *auditService) Record(ctx context.Context, e domain.Event) {
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = s.now()
+	}
+	if e.Outcome == "" {
+		e.Outcome = domain.OutcomeSuccess
+	}
+	claims, authenticated := middleware.ClaimsFromContext(ctx)
+	if e.ActorType == "" {
+		switch {
+		case authenticated:
+			e.ActorType = domain.ActorUser
+			if claims.TokenID != "" {
+				e.ActorType = domain.ActorAccessToken
+				e.Metadata = withValue(e.Metadata, "token_id", claims.TokenID)
+			}
+			if e.ActorID == uuid.Nil {
+				e.ActorID, _ = uuid.Parse(claims.UserID)
+			}
+		case e.ActorID != uuid.Nil:
+			// A user identified by other means, e.g. an invitation token.
+			e.ActorType = domain.ActorUser
+		default:
+			e.ActorType = domain.ActorAnonymous
+		}
+	}
+	if e.TenantID == uuid.Nil && authenticated {
+		e.TenantID, _ = uuid.Parse(claims.TenantID)
+	}
+	if e.RequestID == "" {
+		e.RequestID = requestctx.GetRequestID(ctx)
+	}
+	if e.IPAddress == "" {
+		client := requestctx.GetClient(ctx)
+		e.IPAddress, e.UserAgent = client.IP, client.UserAgent
+	}
+
+	writeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordTimeout)
+	defer cancel()
+	if err := s.repo.Append(writeCtx, &e); err != nil {
+		// The log line is the last copy of the event; keep it complete.
+		s.logger.Error("audit event lost",
+			zap.String("action", e.Action),
+			zap.String("outcome", e.Outcome),
+			zap.String("actor_id", e.ActorID.String()),
+			zap.String("tenant_id", e.TenantID.String()),
+			zap.String("target", e.TargetType+"/"+e.TargetID),
+			zap.String("request_id", e.RequestID),
+			zap.Error(err),
+		)
+	}
+}
+
+func (s *auditService) RecordRequest(ctx context.Context, r middleware.AuditedRequest) {
+	action := r.Action
+	if action == "" {
+		action = domain.ActionAPIRequest
+	}
+	var metadata map[string]any
+	for k, v := range r.Params {
+		if k != "id" {
+			metadata = withValue(metadata, k, v)
+		}
+	}
+	metadata = withValue(metadata, "duration_ms", r.Duration.Milliseconds())
+
+	s.Record(ctx, domain.Event{
+		Action:     action,
+		Outcome:    outcomeOf(r.Status),
+		TargetType: resourceOf(r.Route),
+		TargetID:   r.Params["id"],
+		Metadata:   metadata,
+		Method:     r.Method,
+		Route:      r.Route,
+		Status:     r.Status,
+	})
+}
+
+func (s *auditService) ListEvents(ctx context.Context, input ListEventsInput) (*ListEventsOutput, error) {
+	tenantID, ok := middleware.GetTenantUUIDFromContext(ctx)
+	if !ok {
+		return nil, domain.ErrTenantRequired
+	}
+	input.TenantID = &tenantID
+	return s.list(ctx, input)
+}
+
+func (s *auditService) ListAllEvents(ctx context.Context, input ListEventsInput) (*ListEventsOutput, error) {
+	return s.list(ctx, input)
+}
+
+func (s *auditService) list(ctx context.Context, input ListEventsInput) (*ListEventsOutput, error) {
+	if input.From != nil && input.To != nil && !input.From.Before(*input.To) {
+		return nil, domain.ErrInvalidRange
+	}
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	limit = min(limit, maxListLimit)
+
+	filter := domain.Filter{
+		TenantID:   input.TenantID,
+		ActorID:    input.ActorID,
+		ProjectID:  input.ProjectID,
+		Action:     strings.TrimSpace(input.Action),
+		Outcome:    input.Outcome,
+		TargetType: input.TargetType,
+		TargetID:   input.TargetID,
+		RequestID:  input.RequestID,
+		From:       input.From,
+		To:         input.To,
+		// One extra row tells whether there is a next page.
+		Limit: limit + 1,
+	}
+	if input.Cursor != "" {
+		cursor, err := decodeCursor(input.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		filter.After = cursor
+	}
+
+	events, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	out := &ListEventsOutput{Events: events}
+	if len(events) > limit {
+		out.Events = events[:limit]
+		last := out.Events[limit-1]
+		out.NextCursor = encodeCursor(domain.Cursor{OccurredAt: last.OccurredAt, ID: last.ID})
+	}
+	return out, nil
+}
+
+func (s *auditService) Purge(ctx context.Context) (int64, error) {
+	cutoff := s.now().Add(-s.retention)
+	var total int64
+	for {
+		n, err := s.repo.Purge(ctx, cutoff, purgeBatch)
+		total += n
+		if err != nil || n < purgeBatch {
+			return total, err
+		}
+	}
+}
+
+func (s *auditService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		n, err := s.Purge(ctx)
+		switch {
+		case err != nil && ctx.Err() == nil:
+			s.logger.Warn("audit purge failed", zap.Error(err))
+		case n > 0:
+			s.logger.Info("audit events purged", zap.Int64("count", n), zap.Duration("retention", s.retention))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func outcomeOf(status int) string {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return domain.OutcomeDenied
+	case status >= http.StatusBadRequest:
+		return domain.OutcomeFailure
+	default:
+		return domain.OutcomeSuccess
+	}
+}
+
+// resourceOf names the resource a route acts on: its first literal segment
+// after the version and the admin prefix, e.g. "roles" for
+// /v1/admin/roles/:id/permissions.
+func resourceOf(route string) string {
+	for _, seg := range strings.Split(strings.Trim(route, "/"), "/") {
+		switch {
+		case seg == "v1", seg == "admin", strings.HasPrefix(seg, ":"), strings.HasPrefix(seg, "*"):
+			continue
+		default:
+			return seg
+		}
+	}
+	return ""
+}
+
+func withValue(m map[string]any, key string, value any) map[string]any {
+	if m == nil {
+		m = make(map[string]any)
+	}
+	m[key] = value
+	return m
+}
+
+func encodeCursor(c domain.Cursor) string {
+	raw := strconv.FormatInt(c.OccurredAt.UnixNano(), 10) + "." + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(s string) (*domain.Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+	nanos, id, ok := strings.Cut(string(raw), ".")
+	if !ok {
+		return nil, domain.ErrInvalidCursor
+	}
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidCursor, err)
+	}
+	return &domain.Cursor{OccurredAt: time.Unix(0, n).UTC(), ID: uid}, nil
+}
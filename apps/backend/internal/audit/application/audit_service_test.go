@@ -0,0 +1,209 @@
+package application
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/audit/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware/requestctx"
+)
+
+// memEvents mirrors the Postgres repository: newest first, keyset paging
+// on (occurred_at, id) and tenant filtering.
+type memEvents struct {
+	events []domain.Event
+}
+
+func (r *memEvents) Append(_ context.Context, e *domain.Event) error {
+	e.ID = uuid.New()
+	r.events = append(r.events, *e)
+	return nil
+}
+
+func (r *memEvents) List(_ context.Context, f domain.Filter) ([]*domain.Event, error) {
+	sorted := append([]domain.Event(nil), r.events...)
+	sort.Slice(sorted, func(i, j int) bool { return newer(sorted[i], sorted[j].OccurredAt, sorted[j].ID) })
+
+	out := make([]*domain.Event, 0)
+	for i := range sorted {
+		e := sorted[i]
+		if f.TenantID != nil && e.TenantID != *f.TenantID {
+			continue
+		}
+		if f.After != nil && newer(e, f.After.OccurredAt, f.After.ID) {
+			continue
+		}
+		if f.After != nil && e.ID == f.After.ID {
+			continue
+		}
+		if len(out) == f.Limit {
+			break
+		}
+		out = append(out, &e)
+	}
+	return out, nil
+}
+
+func (r *memEvents) Purge(_ context.Context, before time.Time, limit int) (int64, error) {
+	var kept []domain.Event
+	var n int64
+	for _, e := range r.events {
+		if e.OccurredAt.Before(before) && n < int64(limit) {
+			n++
+			continue
+		}
+		kept = append(kept, e)
+	}
+	r.events = kept
+	return n, nil
+}
+
+func newer(e domain.Event, at time.Time, id uuid.UUID) bool {
+	if !e.OccurredAt.Equal(at) {
+		return e.OccurredAt.After(at)
+	}
+	return e.ID.String() > id.String()
+}
+
+func newTestAuditService() (*auditService, *memEvents) {
+	repo := &memEvents{}
+	svc := NewAuditService(repo, 24*time.Hour, 0, zap.NewNop()).(*auditService)
+	return svc, repo
+}
+
+func requestContext(userID, tenantID uuid.UUID, tokenID string) context.Context {
+	ctx := middleware.WithClaims(context.Background(), &middleware.Claims{
+		UserID:   userID.String(),
+		TenantID: tenantID.String(),
+		TokenID:  tokenID,
+	})
+	ctx = requestctx.SetRequestID(ctx, "req-1")
+	return requestctx.SetClient(ctx, requestctx.Client{IP: "203.0.113.7", UserAgent: "curl/8"})
+}
+
+func TestRecordAttributesEventToCaller(t *testing.T) {
+	svc, repo := newTestAuditService()
+	userID, tenantID := uuid.New(), uuid.New()
+
+	svc.Record(requestContext(userID, tenantID, ""), domain.Event{Action: domain.ActionRoleCreated, TargetType: "role", TargetID: "r1"})
+	svc.Record(requestContext(userID, tenantID, "tok-1"), domain.Event{Action: domain.ActionRoleDeleted})
+	svc.Record(context.Background(), domain.Event{Action: domain.ActionLoginFailed, Outcome: domain.OutcomeFailure})
+
+	require.Len(t, repo.events, 3)
+	e := repo.events[0]
+	assert.Equal(t, domain.ActorUser, e.ActorType)
+	assert.Equal(t, userID, e.ActorID)
+	assert.Equal(t, tenantID, e.TenantID)
+	assert.Equal(t, domain.OutcomeSuccess, e.Outcome)
+	assert.Equal(t, "req-1", e.RequestID)
+	assert.Equal(t, "203.0.113.7", e.IPAddress)
+	assert.Equal(t, "curl/8", e.UserAgent)
+	assert.False(t, e.OccurredAt.IsZero())
+
+	assert.Equal(t, domain.ActorAccessToken, repo.events[1].ActorType)
+	assert.Equal(t, "tok-1", repo.events[1].Metadata["token_id"])
+
+	assert.Equal(t, domain.ActorAnonymous, repo.events[2].ActorType)
+	assert.Equal(t, uuid.Nil, repo.events[2].TenantID)
+	assert.Equal(t, domain.OutcomeFailure, repo.events[2].Outcome)
+}
+
+func TestRecordRequest(t *testing.T) {
+	svc, repo := newTestAuditService()
+	ctx := requestContext(uuid.New(), uuid.New(), "")
+
+	svc.RecordRequest(ctx, middleware.AuditedRequest{
+		Method: http.MethodDelete,
+		Route:  "/v1/admin/roles/:id/permissions/:resource/:action",
+		Params: map[string]string{"id": "r1", "resource": "log", "action": "read"},
+		Status: http.StatusNoContent,
+	})
+	svc.RecordRequest(ctx, middleware.AuditedRequest{Method: http.MethodPut, Route: "/v1/projects/:id", Status: http.StatusForbidden})
+	svc.RecordRequest(ctx, middleware.AuditedRequest{Action: domain.ActionLogExport, Method: http.MethodPost, Route: "/v1/logs/export", Status: http.StatusBadRequest})
+
+	require.Len(t, repo.events, 3)
+	e := repo.events[0]
+	assert.Equal(t, domain.ActionAPIRequest, e.Action)
+	assert.Equal(t, "roles", e.TargetType)
+	assert.Equal(t, "r1", e.TargetID)
+	assert.Equal(t, "log", e.Metadata["resource"])
+	assert.NotContains(t, e.Metadata, "id")
+	assert.Equal(t, http.StatusNoContent, e.Status)
+	assert.Equal(t, domain.OutcomeSuccess, e.Outcome)
+
+	assert.Equal(t, domain.OutcomeDenied, repo.events[1].Outcome)
+	assert.Equal(t, "projects", repo.events[1].TargetType)
+
+	assert.Equal(t, domain.ActionLogExport, repo.events[2].Action)
+	assert.Equal(t, domain.OutcomeFailure, repo.events[2].Outcome)
+}
+
+func TestListEventsIsTenantScopedAndPaged(t *testing.T) {
+	svc, _ := newTestAuditService()
+	tenantA, tenantB := uuid.New(), uuid.New()
+	clock := time.Date(2026, 7, 4, 9, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { clock = clock.Add(time.Second); return clock }
+
+	for i := 0; i < 5; i++ {
+		svc.Record(requestContext(uuid.New(), tenantA, ""), domain.Event{Action: domain.ActionRoleUpdated})
+	}
+	svc.Record(requestContext(uuid.New(), tenantB, ""), domain.Event{Action: domain.ActionRoleUpdated})
+
+	_, err := svc.ListEvents(context.Background(), ListEventsInput{})
+	assert.ErrorIs(t, err, domain.ErrTenantRequired)
+
+	// A tenant filter in the input is ignored; the claims decide.
+	ctx := requestContext(uuid.New(), tenantA, "")
+	var seen []time.Time
+	input := ListEventsInput{TenantID: &tenantB, Limit: 2}
+	for page := 0; ; page++ {
+		require.Less(t, page, 5)
+		out, err := svc.ListEvents(ctx, input)
+		require.NoError(t, err)
+		for _, e := range out.Events {
+			assert.Equal(t, tenantA, e.TenantID)
+			seen = append(seen, e.OccurredAt)
+		}
+		if out.NextCursor == "" {
+			break
+		}
+		input.Cursor = out.NextCursor
+	}
+	require.Len(t, seen, 5)
+	assert.True(t, sort.SliceIsSorted(seen, func(i, j int) bool { return seen[i].After(seen[j]) }))
+
+	all, err := svc.ListAllEvents(context.Background(), ListEventsInput{TenantID: &tenantB})
+	require.NoError(t, err)
+	assert.Len(t, all.Events, 1)
+
+	_, err = svc.ListEvents(ctx, ListEventsInput{Cursor: "not-a-cursor"})
+	assert.ErrorIs(t, err, domain.ErrInvalidCursor)
+	from, to := clock, clock.Add(-time.Hour)
+	_, err = svc.ListEvents(ctx, ListEventsInput{From: &from, To: &to})
+	assert.ErrorIs(t, err, domain.ErrInvalidRange)
+}
+
+func TestPurgeKeepsEventsWithinRetention(t *testing.T) {
+	svc, repo := newTestAuditService()
+	now := time.Date(2026, 7, 4, 9, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	for i := 0; i < purgeBatch+5; i++ {
+		repo.events = append(repo.events, domain.Event{ID: uuid.New(), OccurredAt: now.Add(-48 * time.Hour)})
+	}
+	repo.events = append(repo.events, domain.Event{ID: uuid.New(), OccurredAt: now.Add(-time.Hour)})
+
+	n, err := svc.Purge(context.Background())
+	require.NoError(t, err)
+	assert.EqualValues(t, purgeBatch+5, n)
+	assert.Len(t, repo.events, 1)
+}
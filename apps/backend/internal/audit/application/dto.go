@@ -0,0 +1,33 @@
+package application
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/audit/domain"
+)
+
+// ListEventsInput filters the audit trail. TenantID is honoured by
+// ListAllEvents only; ListEvents always uses the caller's tenant. Action
+// ending in "*" matches by prefix.
+type ListEventsInput struct {
+	TenantID   *uuid.UUID
+	ActorID    *uuid.UUID
+	ProjectID  *uuid.UUID
+	Action     string
+	Outcome    string
+	TargetType string
+	TargetID   string
+	RequestID  string
+	From       *time.Time
+	To         *time.Time
+	Cursor     string
+	Limit      int
+}
+
+type ListEventsOutput struct {
+	Events []*domain.Event `json:"events"`
+	// NextCursor fetches the next (older) page; empty on the last one.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,9 @@
+package domain
+
+import "errors"
+
+var (
+	ErrTenantRequired = errors.New("tenant is required")
+	ErrInvalidCursor  = errors.New("invalid cursor")
+	ErrInvalidRange   = errors.New("from must be before to")
+)
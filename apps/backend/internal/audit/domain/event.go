@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Actor types.
+const (
+	ActorUser = "user"
+	// ActorAccessToken is a principal (user or service account) acting
+	// through a personal access token; the token id is in the metadata.
+	ActorAccessToken = "access_token"
+	ActorAnonymous   = "anonymous"
+	ActorSystem      = "system"
+)
+
+// Outcomes.
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+	// OutcomeDenied is a request refused by authentication or authorization.
+	OutcomeDenied = "denied"
+)
+
+// Well-known actions. Other contexts may record their own (the membership
+// actions live in user/application), named <resource>.<verb>.
+const (
+	// ActionAPIRequest is a mutating API call with no more specific action.
+	ActionAPIRequest = "api.request"
+
+	ActionLogin        = "auth.login"
+	ActionLoginFailed  = "auth.login_failed"
+	ActionLockedOut    = "auth.locked_out"
+	ActionMFAFailed    = "auth.mfa_failed"
+	ActionMFAEnabled   = "auth.mfa_enabled"
+	ActionMFADisabled  = "auth.mfa_disabled"
+	ActionMFAReset     = "auth.mfa_reset"
+	ActionRecoveryUsed = "auth.recovery_code_used"
+
+	ActionAccessTokenCreated    = "access_token.created"
+	ActionAccessTokenRevoked    = "access_token.revoked"
+	ActionServiceAccountCreated = "service_account.created"
+	ActionServiceAccountUpdated = "service_account.updated"
+	ActionServiceAccountDeleted = "service_account.deleted"
+
+	ActionRoleCreated            = "role.created"
+	ActionRoleUpdated            = "role.updated"
+	ActionRoleDeleted            = "role.deleted"
+	ActionRolePermissionsChanged = "role.permissions_changed"
+
+	ActionProjectCreated = "project.created"
+	ActionProjectUpdated = "project.updated"
+	ActionProjectDeleted = "project.deleted"
+
+	ActionTenantCreated       = "tenant.created"
+	ActionTenantUpdated       = "tenant.updated"
+	ActionTenantDeleted       = "tenant.deleted"
+	ActionTenantStatusChanged = "tenant.status_changed"
+	ActionTenantPlanChanged   = "tenant.plan_changed"
+	ActionTenantQuotaChanged  = "tenant.quota_changed"
+
+	// ActionLogSearch is a read of a sensitive project's logs.
+	ActionLogSearch = "log.search"
+	ActionLogExport = "log.export"
+)
+
+// Event is one audit record. Zero ids are stored as NULL. Before and After
+// hold the changed attributes only; Metadata holds anything else worth
+// keeping (never secrets).
+type Event struct {
+	ID         uuid.UUID      `json:"id"`
+	OccurredAt time.Time      `json:"occurred_at"`
+	Action     string         `json:"action"`
+	Outcome    string         `json:"outcome"`
+	ActorType  string         `json:"actor_type"`
+	ActorID    uuid.UUID      `json:"actor_id"`
+	TenantID   uuid.UUID      `json:"tenant_id"`
+	ProjectID  uuid.UUID      `json:"project_id"`
+	TargetType string         `json:"target_type,omitempty"`
+	TargetID   string         `json:"target_id,omitempty"`
+	Before     map[string]any `json:"before,omitempty"`
+	After      map[string]any `json:"after,omitempty"`
+	Metadata   map[string]any `json:"metadata,omitempty"`
+	IPAddress  string         `json:"ip_address,omitempty"`
+	UserAgent  string         `json:"user_agent,omitempty"`
+	RequestID  string         `json:"request_id,omitempty"`
+	Method     string         `json:"method,omitempty"`
+	Route      string         `json:"route,omitempty"`
+	Status     int            `json:"status,omitempty"`
+}
+
+// Filter selects events of a tenant, or of every tenant when TenantID is
+// nil (platform queries only). Action matches exactly, or by prefix when it
+// ends in "*". Results are newest first; After continues a previous page.
+type Filter struct {
+	TenantID   *uuid.UUID
+	ActorID    *uuid.UUID
+	ProjectID  *uuid.UUID
+	Action     string
+	Outcome    string
+	TargetType string
+	TargetID   string
+	RequestID  string
+	From       *time.Time
+	To         *time.Time
+	After      *Cursor
+	Limit      int
+}
+
+// Cursor is the position of the last event of a page.
+type Cursor struct {
+	OccurredAt time.Time
+	ID         uuid.UUID
+}
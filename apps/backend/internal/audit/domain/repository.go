@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Repository stores the audit trail. It only appends; Purge is the one way
+// records leave it.
+type Repository interface {
+	Append(ctx context.Context, event *Event) error
+	List(ctx context.Context, filter Filter) ([]*Event, error)
+	// Purge deletes up to limit events older than before and returns how
+	// many it deleted.
+	Purge(ctx context.Context, before time.Time, limit int) (int64, error)
+}
+
+// Recorder receives audit events from the other bounded contexts. It is
+// implemented by audit/application.AuditService; recording never fails the
+// caller.
+type Recorder interface {
+	Record(ctx context.Context, event Event)
+}
+
+// Discard is a Recorder that drops every event, for services built without
+// an audit trail (tests, tools).
+var Discard Recorder = discard{}
+
+type discard struct{}
+
+func (discard) Record(context.Context, Event) {}
@@ -0,0 +1,241 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/audit/domain"
+)
+
+const eventColumns = `
+	id, occurred_at, action, outcome, actor_type, actor_id, tenant_id, project_id,
+	target_type, target_id, before, after, metadata,
+	COALESCE(host(ip_address), ''), user_agent, request_id, method, route, status`
+
+type EventRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewEventRepository(db *pgxpool.Pool) *EventRepository {
+	return &EventRepository{db: db}
+}
+
+func (r *EventRepository) Append(ctx context.Context, e *domain.Event) error {
+	before, err := marshalJSON(e.Before)
+	if err != nil {
+		return err
+	}
+	after, err := marshalJSON(e.After)
+	if err != nil {
+		return err
+	}
+	metadata, err := marshalJSON(e.Metadata)
+	if err != nil {
+		return err
+	}
+
+	const query = `
+		INSERT INTO audit.events (
+			occurred_at, action, outcome, actor_type, actor_id, tenant_id, project_id,
+			target_type, target_id, before, after, metadata,
+			ip_address, user_agent, request_id, method, route, status
+		)
+		VALUES (
+			$1, $2, $3, $4, $5, $6, $7,
+			$8, $9, $10, $11, $12,
+			NULLIF($13, '')::inet, $14, $15, $16, $17, NULLIF($18, 0)
+		)
+		RETURNING id
+	`
+	return r.db.QueryRow(ctx, query,
+		e.OccurredAt, e.Action, e.Outcome, e.ActorType, nullableUUID(e.ActorID), nullableUUID(e.TenantID), nullableUUID(e.ProjectID),
+		e.TargetType, truncate(e.TargetID, 255), before, after, metadata,
+		e.IPAddress, e.UserAgent, truncate(e.RequestID, 100), e.Method, truncate(e.Route, 255), e.Status,
+	).Scan(&e.ID)
+}
+
+func (r *EventRepository) List(ctx context.Context, f domain.Filter) ([]*domain.Event, error) {
+	var (
+		conds []string
+		args  []any
+	)
+	add := func(cond string, val any) {
+		args = append(args, val)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if f.TenantID != nil {
+		add("tenant_id = $%d", *f.TenantID)
+	}
+	if f.ActorID != nil {
+		add("actor_id = $%d", *f.ActorID)
+	}
+	if f.ProjectID != nil {
+		add("project_id = $%d", *f.ProjectID)
+	}
+	if prefix, ok := strings.CutSuffix(f.Action, "*"); ok {
+		add("action LIKE $%d || '%%'", escapeLike(prefix))
+	} else if f.Action != "" {
+		add("action = $%d", f.Action)
+	}
+	if f.Outcome != "" {
+		add("outcome = $%d", f.Outcome)
+	}
+	if f.TargetType != "" {
+		add("target_type = $%d", f.TargetType)
+	}
+	if f.TargetID != "" {
+		add("target_id = $%d", f.TargetID)
+	}
+	if f.RequestID != "" {
+		add("request_id = $%d", f.RequestID)
+	}
+	if f.From != nil {
+		add("occurred_at >= $%d", *f.From)
+	}
+	if f.To != nil {
+		add("occurred_at < $%d", *f.To)
+	}
+	if f.After != nil {
+		args = append(args, f.After.OccurredAt, f.After.ID)
+		conds = append(conds, fmt.Sprintf("(occurred_at, id) < ($%d, $%d)", len(args)-1, len(args)))
+	}
+
+	where := ""
+	if len(conds) > 0 {
+		where = "WHERE " + strings.Join(conds, " AND ")
+	}
+	args = append(args, f.Limit)
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM audit.events
+		%s
+		ORDER BY occurred_at DESC, id DESC
+		LIMIT $%d
+	`, eventColumns, where, len(args))
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]*domain.Event, 0)
+	for rows.Next() {
+		e, err := scanEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// Purge opts into deletion for its own transaction only; every other
+// DELETE is rejected by the append-only trigger.
+func (r *EventRepository) Purge(ctx context.Context, before time.Time, limit int) (int64, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SET LOCAL audit.allow_purge = 'on'`); err != nil {
+		return 0, err
+	}
+	const query = `
+		DELETE FROM audit.events
+		WHERE id IN (
+			SELECT id FROM audit.events
+			WHERE occurred_at < $1
+			ORDER BY occurred_at
+			LIMIT $2
+		)
+	`
+	tag, err := tx.Exec(ctx, query, before, limit)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func scanEvent(row pgx.Row) (*domain.Event, error) {
+	var (
+		e                          domain.Event
+		actorID, tenantID, project *uuid.UUID
+		before, after, metadata    []byte
+		status                     *int
+	)
+	err := row.Scan(
+		&e.ID, &e.OccurredAt, &e.Action, &e.Outcome, &e.ActorType, &actorID, &tenantID, &project,
+		&e.TargetType, &e.TargetID, &before, &after, &metadata,
+		&e.IPAddress, &e.UserAgent, &e.RequestID, &e.Method, &e.Route, &status,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if actorID != nil {
+		e.ActorID = *actorID
+	}
+	if tenantID != nil {
+		e.TenantID = *tenantID
+	}
+	if project != nil {
+		e.ProjectID = *project
+	}
+	if status != nil {
+		e.Status = *status
+	}
+	for _, f := range []struct {
+		raw []byte
+		dst *map[string]any
+	}{{before, &e.Before}, {after, &e.After}, {metadata, &e.Metadata}} {
+		if len(f.raw) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(f.raw, f.dst); err != nil {
+			return nil, err
+		}
+	}
+	return &e, nil
+}
+
+func marshalJSON(m map[string]any) ([]byte, error) {
+	if len(m) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(m)
+}
+
+func nullableUUID(id uuid.UUID) any {
+	if id == uuid.Nil {
+		return nil
+	}
+	return id
+}
+
+// truncate cuts s to at most n bytes without splitting a character.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,167 @@
+package http
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/audit/application"
+	"github.com/indalyadav56/logify/apps/backend/internal/audit/domain"
+	"github.com/indalyadav56/logify/apps/backend/pkg/response"
+)
+
+type AuditHandler struct {
+	service application.AuditService
+}
+
+func NewAuditHandler(service application.AuditService) *AuditHandler {
+	return &AuditHandler{service: service}
+}
+
+// ListEvents returns the audit trail of the caller's tenant.
+// @Summary      List audit events
+// @Description  Mutating API calls and security events of the caller's tenant, newest first. Pass next_cursor back as cursor for the next page. action ending in * matches by prefix (e.g. auth.*).
+// @Tags         audit
+// @Produce      json
+// @Security     BearerAuth
+// @Param        actor_id     query     string  false  "Acting user or service account (UUID)"
+// @Param        project_id   query     string  false  "Project (UUID)"
+// @Param        action       query     string  false  "Action, e.g. role.updated or auth.*"
+// @Param        outcome      query     string  false  "success, failure or denied"
+// @Param        target_type  query     string  false  "Target resource type"
+// @Param        target_id    query     string  false  "Target resource id"
+// @Param        request_id   query     string  false  "Request id"
+// @Param        from         query     string  false  "Earliest time, RFC 3339"
+// @Param        to           query     string  false  "Latest time (exclusive), RFC 3339"
+// @Param        cursor       query     string  false  "Cursor from the previous page"
+// @Param        limit        query     int     false  "Page size (default 50, max 500)"
+// @Success      200  {object}  response.APIResponse{data=application.ListEventsOutput} "Audit events retrieved successfully"
+// @Failure      400  {object}  response.APIResponse "Invalid query"
+// @Failure      401  {object}  response.APIResponse "Unauthorized"
+// @Failure      403  {object}  response.APIResponse "Forbidden"
+// @Failure      500  {object}  response.APIResponse "Internal server error"
+// @Router       /v1/audit-events [get]
+func (h *AuditHandler) ListEvents(c *gin.Context) {
+	input, ok := listInput(c)
+	if !ok {
+		return
+	}
+	out, err := h.service.ListEvents(c.Request.Context(), input)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	response.OK(c, "Audit events retrieved successfully", out)
+}
+
+// ListAllEvents returns the audit trail across tenants.
+// @Summary      List audit events of every tenant (admin)
+// @Description  Like /v1/audit-events, across tenants; tenant_id narrows to one. Includes events with no tenant, such as failed logins of unknown accounts.
+// @Tags         audit
+// @Produce      json
+// @Security     BearerAuth
+// @Param        tenant_id    query     string  false  "Tenant (UUID)"
+// @Param        actor_id     query     string  false  "Acting user or service account (UUID)"
+// @Param        project_id   query     string  false  "Project (UUID)"
+// @Param        action       query     string  false  "Action, e.g. role.updated or auth.*"
+// @Param        outcome      query     string  false  "success, failure or denied"
+// @Param        target_type  query     string  false  "Target resource type"
+// @Param        target_id    query     string  false  "Target resource id"
+// @Param        request_id   query     string  false  "Request id"
+// @Param        from         query     string  false  "Earliest time, RFC 3339"
+// @Param        to           query     string  false  "Latest time (exclusive), RFC 3339"
+// @Param        cursor       query     string  false  "Cursor from the previous page"
+// @Param        limit        query     int     false  "Page size (default 50, max 500)"
+// @Success      200  {object}  response.APIResponse{data=application.ListEventsOutput} "Audit events retrieved successfully"
+// @Failure      400  {object}  response.APIResponse "Invalid query"
+// @Failure      403  {object}  response.APIResponse "Forbidden"
+// @Failure      500  {object}  response.APIResponse "Internal server error"
+// @Router       /v1/admin/audit-events [get]
+func (h *AuditHandler) ListAllEvents(c *gin.Context) {
+	input, ok := listInput(c)
+	if !ok {
+		return
+	}
+	if input.TenantID, ok = uuidQuery(c, "tenant_id"); !ok {
+		return
+	}
+	out, err := h.service.ListAllEvents(c.Request.Context(), input)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	response.OK(c, "Audit events retrieved successfully", out)
+}
+
+func listInput(c *gin.Context) (application.ListEventsInput, bool) {
+	input := application.ListEventsInput{
+		Action:     c.Query("action"),
+		Outcome:    c.Query("outcome"),
+		TargetType: c.Query("target_type"),
+		TargetID:   c.Query("target_id"),
+		RequestID:  c.Query("request_id"),
+		Cursor:     c.Query("cursor"),
+	}
+	var ok bool
+	if input.ActorID, ok = uuidQuery(c, "actor_id"); !ok {
+		return input, false
+	}
+	if input.ProjectID, ok = uuidQuery(c, "project_id"); !ok {
+		return input, false
+	}
+	if input.From, ok = timeQuery(c, "from"); !ok {
+		return input, false
+	}
+	if input.To, ok = timeQuery(c, "to"); !ok {
+		return input, false
+	}
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			response.BadRequest(c, "limit must be a positive integer")
+			return input, false
+		}
+		input.Limit = limit
+	}
+	return input, true
+}
+
+func uuidQuery(c *gin.Context, name string) (*uuid.UUID, bool) {
+	v := c.Query(name)
+	if v == "" {
+		return nil, true
+	}
+	id, err := uuid.Parse(v)
+	if err != nil {
+		response.BadRequest(c, "Invalid "+name+" format")
+		return nil, false
+	}
+	return &id, true
+}
+
+func timeQuery(c *gin.Context, name string) (*time.Time, bool) {
+	v := c.Query(name)
+	if v == "" {
+		return nil, true
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		response.BadRequest(c, name+" must be an RFC 3339 time")
+		return nil, false
+	}
+	return &t, true
+}
+
+func writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrTenantRequired):
+		response.Unauthorized(c, "Tenant context required")
+	case errors.Is(err, domain.ErrInvalidCursor), errors.Is(err, domain.ErrInvalidRange):
+		response.BadRequest(c, err.Error())
+	default:
+		response.InternalServerError(c, "Failed to list audit events")
+	}
+}
@@ -0,0 +1,15 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+
+	roleDomain "github.com/indalyadav56/logify/apps/backend/internal/role/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
+)
+
+// RegisterRoutes mounts the audit query API. The group is expected to be
+// already authenticated (see di.RegisterAllRoutes).
+func RegisterRoutes(router *gin.RouterGroup, handler *AuditHandler, authz *middleware.Authorizer) {
+	router.GET("/v1/audit-events", authz.RequirePermission(roleDomain.ResourceAudit, roleDomain.ActionRead), handler.ListEvents)
+	router.GET("/v1/admin/audit-events", authz.RequirePermission(roleDomain.ResourcePlatformAudit, roleDomain.ActionRead), handler.ListAllEvents)
+}
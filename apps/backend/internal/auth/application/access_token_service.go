@@ -0,0 +1,244 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	auditDomain "github.com/indalyadav56/logify/apps/backend/internal/audit/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/auth/domain"
+	roleDomain "github.com/indalyadav56/logify/apps/backend/internal/role/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
+	"github.com/indalyadav56/logify/apps/backend/pkg/oidc"
+)
+
+const (
+	// defaultAccessTokenTTL applies when a token is created without an
+	// expiry; maxAccessTokenTTL caps the one requested.
+	defaultAccessTokenTTL = 90 * 24 * time.Hour
+	maxAccessTokenTTL     = 365 * 24 * time.Hour
+
+	// accessTokenTouchInterval limits last_used_at writes to one per token
+	// per interval.
+	accessTokenTouchInterval = time.Minute
+)
+
+// AccessTokenService manages the caller's personal access tokens and
+// authenticates requests bearing any access token.
+type AccessTokenService interface {
+	// CreateToken mints a token acting as the caller in their current
+	// tenant. Requests authenticated by an access token get
+	// ErrTokenCannotMintTokens.
+	CreateToken(ctx context.Context, input CreateAccessTokenInput) (*CreatedAccessTokenOutput, error)
+	ListTokens(ctx context.Context) ([]*AccessTokenOutput, error)
+	RevokeToken(ctx context.Context, tokenID uuid.UUID) error
+
+	// Authenticate satisfies middleware.TokenAuthenticator.
+	Authenticate(ctx context.Context, raw string) (*middleware.Claims, error)
+}
+
+type accessTokenService struct {
+	repo   domain.AccessTokenRepository
+	audit  auditDomain.Recorder
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+func NewAccessTokenService(repo domain.AccessTokenRepository, audit auditDomain.Recorder, logger *zap.Logger) AccessTokenService {
+	if audit == nil {
+		audit = auditDomain.Discard
+	}
+	return &accessTokenService{
+		repo:   repo,
+		audit:  audit,
+		logger: logger.Named("access_token_service"),
+		now:    time.Now,
+	}
+}
+
+func (s *accessTokenService) CreateToken(ctx context.Context, input CreateAccessTokenInput) (*CreatedAccessTokenOutput, error) {
+	userID, tenantID, err := tokenIssuer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	owner := domain.TokenOwner{UserID: &userID}
+	out, err := issueAccessToken(ctx, s.repo, s.now(), tenantID, owner, userID, input)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, tokenEvent(auditDomain.ActionAccessTokenCreated, out.ID, map[string]any{"scopes": out.Scopes}))
+	s.logger.Info("personal access token created",
+		zap.String("user_id", userID.String()),
+		zap.String("token_id", out.ID.String()),
+		zap.Strings("scopes", out.Scopes),
+	)
+	return out, nil
+}
+
+func (s *accessTokenService) ListTokens(ctx context.Context) ([]*AccessTokenOutput, error) {
+	userID, ok := middleware.UserUUIDFromContext(ctx)
+	if !ok {
+		return nil, domain.ErrNotAuthenticated
+	}
+	tokens, err := s.repo.List(ctx, domain.TokenOwner{UserID: &userID})
+	if err != nil {
+		return nil, err
+	}
+	return toAccessTokenOutputs(tokens), nil
+}
+
+func (s *accessTokenService) RevokeToken(ctx context.Context, tokenID uuid.UUID) error {
+	userID, ok := middleware.UserUUIDFromContext(ctx)
+	if !ok {
+		return domain.ErrNotAuthenticated
+	}
+	if err := s.repo.Revoke(ctx, domain.TokenOwner{UserID: &userID}, tokenID); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, tokenEvent(auditDomain.ActionAccessTokenRevoked, tokenID, nil))
+	s.logger.Info("personal access token revoked",
+		zap.String("user_id", userID.String()),
+		zap.String("token_id", tokenID.String()),
+	)
+	return nil
+}
+
+func (s *accessTokenService) Authenticate(ctx context.Context, raw string) (*middleware.Claims, error) {
+	token, err := s.repo.GetByHash(ctx, domain.HashAccessToken(raw))
+	if err != nil {
+		if errors.Is(err, domain.ErrAccessTokenNotFound) {
+			return nil, middleware.ErrInvalidToken
+		}
+		return nil, err
+	}
+	now := s.now()
+	if !token.IsUsable(now) {
+		return nil, middleware.ErrInvalidToken
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= accessTokenTouchInterval {
+		// Bookkeeping only; a failed write must not fail the request.
+		if err := s.repo.Touch(ctx, token.ID, now); err != nil {
+			s.logger.Warn("record access token use failed", zap.String("token_id", token.ID.String()), zap.Error(err))
+		}
+	}
+
+	return &middleware.Claims{
+		UserID:   token.Owner.PrincipalID().String(),
+		TenantID: token.TenantID.String(),
+		TokenID:  token.ID.String(),
+		Scopes:   token.Scopes,
+	}, nil
+}
+
+// tokenIssuer returns the caller and tenant a new token is created for.
+// Tokens are minted from interactive sessions only, so that a leaked token
+// cannot be used to outlive its own revocation.
+func tokenIssuer(ctx context.Context) (uuid.UUID, uuid.UUID, error) {
+	if claims, ok := middleware.ClaimsFromContext(ctx); ok && claims.TokenID != "" {
+		return uuid.Nil, uuid.Nil, domain.ErrTokenCannotMintTokens
+	}
+	return callerTenant(ctx)
+}
+
+// issueAccessToken stores a new token for owner and returns it with its
+// plaintext.
+func issueAccessToken(
+	ctx context.Context,
+	repo domain.AccessTokenRepository,
+	now time.Time,
+	tenantID uuid.UUID,
+	owner domain.TokenOwner,
+	createdBy uuid.UUID,
+	input CreateAccessTokenInput,
+) (*CreatedAccessTokenOutput, error) {
+	scopes, err := normalizeScopes(input.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	ttl := defaultAccessTokenTTL
+	if input.ExpiresInDays != 0 {
+		ttl = time.Duration(input.ExpiresInDays) * 24 * time.Hour
+	}
+	if ttl <= 0 || ttl > maxAccessTokenTTL {
+		return nil, domain.ErrInvalidTokenExpiry
+	}
+
+	secret, err := oidc.RandomString(32)
+	if err != nil {
+		return nil, err
+	}
+	plain := domain.AccessTokenPrefix + secret
+	token := &domain.AccessToken{
+		TenantID:  tenantID,
+		Owner:     owner,
+		Name:      strings.TrimSpace(input.Name),
+		Hash:      domain.HashAccessToken(plain),
+		Prefix:    domain.AccessTokenDisplayPrefix(plain),
+		Scopes:    scopes,
+		CreatedBy: createdBy,
+		ExpiresAt: now.Add(ttl),
+	}
+	if err := repo.Create(ctx, token); err != nil {
+		return nil, err
+	}
+	return &CreatedAccessTokenOutput{AccessTokenOutput: *toAccessTokenOutput(token), Token: plain}, nil
+}
+
+// tokenEvent builds the audit event of an action on an access token.
+func tokenEvent(action string, tokenID uuid.UUID, metadata map[string]any) auditDomain.Event {
+	return auditDomain.Event{
+		Action:     action,
+		TargetType: "access_token",
+		TargetID:   tokenID.String(),
+		Metadata:   metadata,
+	}
+}
+
+// normalizeScopes validates scopes as permission keys and returns them in
+// canonical form without duplicates.
+func normalizeScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, domain.ErrInvalidTokenScope
+	}
+	out := make([]string, 0, len(scopes))
+	seen := make(map[string]bool, len(scopes))
+	for _, raw := range scopes {
+		p, ok := roleDomain.ParsePermissionKey(strings.TrimSpace(raw))
+		if !ok {
+			return nil, domain.ErrInvalidTokenScope
+		}
+		if key := p.Key(); !seen[key] {
+			seen[key] = true
+			out = append(out, key)
+		}
+	}
+	return out, nil
+}
+
+func toAccessTokenOutput(t *domain.AccessToken) *AccessTokenOutput {
+	return &AccessTokenOutput{
+		ID:               t.ID,
+		Name:             t.Name,
+		Prefix:           t.Prefix,
+		Scopes:           t.Scopes,
+		ServiceAccountID: t.Owner.ServiceAccountID,
+		CreatedBy:        t.CreatedBy,
+		ExpiresAt:        t.ExpiresAt,
+		LastUsedAt:       t.LastUsedAt,
+		RevokedAt:        t.RevokedAt,
+		CreatedAt:        t.CreatedAt,
+	}
+}
+
+func toAccessTokenOutputs(tokens []*domain.AccessToken) []*AccessTokenOutput {
+	out := make([]*AccessTokenOutput, len(tokens))
+	for i, t := range tokens {
+		out[i] = toAccessTokenOutput(t)
+	}
+	return out
+}
@@ -0,0 +1,130 @@
+package application
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/auth/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
+)
+
+type memAccessTokens struct {
+	mu     sync.Mutex
+	byHash map[string]*domain.AccessToken
+	active bool
+}
+
+func (r *memAccessTokens) Create(_ context.Context, t *domain.AccessToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t.ID = uuid.New()
+	cp := *t
+	r.byHash[t.Hash] = &cp
+	return nil
+}
+
+func (r *memAccessTokens) GetByHash(_ context.Context, hash string) (*domain.AccessToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.byHash[hash]
+	if !ok {
+		return nil, domain.ErrAccessTokenNotFound
+	}
+	cp := *t
+	cp.PrincipalActive = r.active
+	return &cp, nil
+}
+
+func (r *memAccessTokens) List(_ context.Context, owner domain.TokenOwner) ([]*domain.AccessToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []*domain.AccessToken
+	for _, t := range r.byHash {
+		if t.Owner.PrincipalID() == owner.PrincipalID() {
+			cp := *t
+			out = append(out, &cp)
+		}
+	}
+	return out, nil
+}
+
+func (r *memAccessTokens) Revoke(_ context.Context, owner domain.TokenOwner, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, t := range r.byHash {
+		if t.ID == id && t.Owner.PrincipalID() == owner.PrincipalID() && t.RevokedAt == nil {
+			now := time.Now()
+			t.RevokedAt = &now
+			return nil
+		}
+	}
+	return domain.ErrAccessTokenNotFound
+}
+
+func (r *memAccessTokens) Touch(_ context.Context, id uuid.UUID, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, t := range r.byHash {
+		if t.ID == id {
+			t.LastUsedAt = &at
+		}
+	}
+	return nil
+}
+
+func TestAccessTokenLifecycle(t *testing.T) {
+	repo := &memAccessTokens{byHash: map[string]*domain.AccessToken{}, active: true}
+	svc := NewAccessTokenService(repo, nil, zap.NewNop()).(*accessTokenService)
+	userID, tenantID := uuid.New(), uuid.New()
+	ctx := middleware.WithClaims(context.Background(), &middleware.Claims{
+		UserID:   userID.String(),
+		TenantID: tenantID.String(),
+	})
+
+	_, err := svc.CreateToken(ctx, CreateAccessTokenInput{Name: "ci", Scopes: []string{"log"}})
+	assert.ErrorIs(t, err, domain.ErrInvalidTokenScope)
+	_, err = svc.CreateToken(ctx, CreateAccessTokenInput{Name: "ci", Scopes: []string{"log.read"}, ExpiresInDays: 400})
+	assert.ErrorIs(t, err, domain.ErrInvalidTokenExpiry)
+
+	created, err := svc.CreateToken(ctx, CreateAccessTokenInput{Name: "ci", Scopes: []string{"Log.Read", "log.read"}})
+	require.NoError(t, err)
+	assert.True(t, domain.IsAccessToken(created.Token))
+	assert.Equal(t, []string{"log.read"}, created.Scopes)
+	assert.NotContains(t, repo.byHash, created.Token, "only the hash is stored")
+
+	claims, err := svc.Authenticate(context.Background(), created.Token)
+	require.NoError(t, err)
+	assert.Equal(t, userID.String(), claims.UserID)
+	assert.Equal(t, tenantID.String(), claims.TenantID)
+	assert.Equal(t, created.ID.String(), claims.TokenID)
+	assert.Equal(t, []string{"log.read"}, claims.Scopes)
+
+	// A token cannot mint another one.
+	_, err = svc.CreateToken(middleware.WithClaims(context.Background(), claims), CreateAccessTokenInput{Name: "x", Scopes: []string{"log.read"}})
+	assert.ErrorIs(t, err, domain.ErrTokenCannotMintTokens)
+
+	_, err = svc.Authenticate(context.Background(), domain.AccessTokenPrefix+"unknown")
+	assert.ErrorIs(t, err, middleware.ErrInvalidToken)
+
+	repo.active = false
+	_, err = svc.Authenticate(context.Background(), created.Token)
+	assert.ErrorIs(t, err, middleware.ErrInvalidToken, "disabled principal")
+	repo.active = true
+
+	svc.now = func() time.Time { return time.Now().Add(91 * 24 * time.Hour) }
+	_, err = svc.Authenticate(context.Background(), created.Token)
+	assert.ErrorIs(t, err, middleware.ErrInvalidToken, "expired")
+	svc.now = time.Now
+
+	require.NoError(t, svc.RevokeToken(ctx, created.ID))
+	_, err = svc.Authenticate(context.Background(), created.Token)
+	assert.ErrorIs(t, err, middleware.ErrInvalidToken, "revoked")
+	assert.ErrorIs(t, svc.RevokeToken(ctx, created.ID), domain.ErrAccessTokenNotFound)
+}
@@ -0,0 +1,66 @@
+package application
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+
+	auditDomain "github.com/indalyadav56/logify/apps/backend/internal/audit/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/auth/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
+)
+
+// Login methods recorded with auth.login.
+const (
+	loginPassword = "password"
+	loginMFA      = "mfa"
+	loginSSO      = "sso"
+)
+
+// auditUser records a security event of the user acting on their own
+// account. Before authentication there are no claims to take the tenant
+// from; the event then belongs to the personal tenant, where every session
+// starts.
+func (s *authService) auditUser(ctx context.Context, action, outcome string, userID uuid.UUID, metadata map[string]any) {
+	event := auditDomain.Event{
+		Action:     action,
+		Outcome:    outcome,
+		ActorType:  auditDomain.ActorUser,
+		ActorID:    userID,
+		TargetType: "user",
+		TargetID:   userID.String(),
+		Metadata:   metadata,
+	}
+	if _, ok := middleware.ClaimsFromContext(ctx); !ok {
+		event.TenantID = userID
+	}
+	s.audit.Record(ctx, event)
+}
+
+// auditLoginFailure records a refused password login. The account may not
+// exist, so the target is the email that was tried.
+func (s *authService) auditLoginFailure(ctx context.Context, email string, err error) {
+	action, outcome := auditDomain.ActionLoginFailed, auditDomain.OutcomeFailure
+	if errors.Is(err, domain.ErrAccountLocked) {
+		outcome = auditDomain.OutcomeDenied
+	}
+	s.audit.Record(ctx, auditDomain.Event{
+		Action:     action,
+		Outcome:    outcome,
+		TargetType: "user",
+		TargetID:   email,
+		Metadata:   map[string]any{"method": loginPassword, "reason": err.Error()},
+	})
+}
+
+// secondFactorFailed counts a wrong code against the user, audits it and
+// returns ErrInvalidMFACode, or ErrAccountLocked when it locked the user.
+func (s *authService) secondFactorFailed(ctx context.Context, userID uuid.UUID) error {
+	err := s.recordFailure(ctx, mfaAttemptKey(userID), domain.ErrInvalidMFACode)
+	s.auditUser(ctx, auditDomain.ActionMFAFailed, auditDomain.OutcomeFailure, userID, nil)
+	if errors.Is(err, domain.ErrAccountLocked) {
+		s.auditUser(ctx, auditDomain.ActionLockedOut, auditDomain.OutcomeDenied, userID, map[string]any{"factor": loginMFA})
+	}
+	return err
+}
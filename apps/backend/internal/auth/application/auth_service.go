@@ -9,17 +9,71 @@ import (
 	"net"
 	"time"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 
+	auditDomain "github.com/indalyadav56/logify/apps/backend/internal/audit/domain"
 	"github.com/indalyadav56/logify/apps/backend/internal/auth/domain"
 	userApp "github.com/indalyadav56/logify/apps/backend/internal/user/application"
 	userDomain "github.com/indalyadav56/logify/apps/backend/internal/user/domain"
 	"github.com/indalyadav56/logify/apps/backend/pkg/jwt"
 )
 
+// defaultRefreshTokenTTL is used when the config leaves refresh_token_ttl unset.
+const defaultRefreshTokenTTL = 30 * 24 * time.Hour
+
 type AuthService interface {
 	Register(ctx context.Context, input RegisterInput) (*TokenOutput, error)
 	Login(ctx context.Context, input LoginInput) (*TokenOutput, error)
+	// Refresh rotates a refresh token. Presenting an already-rotated token is
+	// treated as theft: the whole session (token family) is revoked.
+	Refresh(ctx context.Context, input RefreshInput) (*TokenOutput, error)
+	// Logout revokes the session the refresh token belongs to.
+	Logout(ctx context.Context, input LogoutInput) error
+	ListSessions(ctx context.Context, userID, currentSessionID uuid.UUID) ([]*SessionOutput, error)
+	RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error
+	// RevokeAllSessions logs the user out everywhere.
+	RevokeAllSessions(ctx context.Context, userID uuid.UUID) error
+	// SwitchTenant points the session at another tenant the user belongs to
+	// and returns an access token for it. Later refreshes keep the tenant.
+	SwitchTenant(ctx context.Context, input SwitchTenantInput) (*TokenOutput, error)
+	// SSOAuthorize starts an OIDC login and returns the provider URL to send
+	// the user to. ErrSSODisabled when no provider is configured.
+	SSOAuthorize(ctx context.Context) (*SSOAuthorizeOutput, error)
+	// SSOCallback completes an OIDC login with the code and state the
+	// provider redirected back with, provisioning the account on first use.
+	SSOCallback(ctx context.Context, input SSOCallbackInput) (*TokenOutput, error)
+
+	// VerifyMFA completes a password login that returned an MFA challenge.
+	// For a challenge requiring enrolment, the first valid code enables MFA
+	// and the output carries the recovery codes.
+	VerifyMFA(ctx context.Context, input VerifyMFAInput) (*TokenOutput, error)
+	// StartChallengeEnrollment starts enrolment for a user whose tenant
+	// requires MFA, authenticated by the login's challenge.
+	StartChallengeEnrollment(ctx context.Context, mfaToken string) (*MFAEnrollmentOutput, error)
+	MFAStatus(ctx context.Context, userID uuid.UUID) (*MFAStatusOutput, error)
+	// StartMFAEnrollment issues a new secret; MFA is on once EnableMFA
+	// verifies a code from it.
+	StartMFAEnrollment(ctx context.Context, userID uuid.UUID) (*MFAEnrollmentOutput, error)
+	EnableMFA(ctx context.Context, input MFACodeInput) (*RecoveryCodesOutput, error)
+	DisableMFA(ctx context.Context, input MFACodeInput) error
+	// RegenerateRecoveryCodes replaces every recovery code.
+	RegenerateRecoveryCodes(ctx context.Context, input MFACodeInput) (*RecoveryCodesOutput, error)
+	// ResetMFA removes a user's second factor and lockout, for an
+	// administrator helping a user who lost both device and codes.
+	ResetMFA(ctx context.Context, userID uuid.UUID) error
+}
+
+// TenantProvisioner creates the personal tenant a user owns. It is
+// implemented by tenant/application.TenantService.
+type TenantProvisioner interface {
+	EnsurePersonalTenant(ctx context.Context, userID uuid.UUID, name string) error
+}
+
+// TenantMembership tells whether a user may act in a tenant other than their
+// personal one. It is implemented by user/application.MembershipService.
+type TenantMembership interface {
+	IsTenantMember(ctx context.Context, userID, tenantID uuid.UUID) (bool, error)
 }
 
 type authService struct {
@@ -28,6 +82,12 @@ type authService struct {
 	tokenRepo   domain.RefreshTokenRepository
 	sessionRepo domain.SessionRepository
 	userSrv     userApp.UserService
+	tenants     TenantProvisioner
+	members     TenantMembership
+	sso         *SSO
+	mfa         *MFA
+	audit       auditDomain.Recorder
+	refreshTTL  time.Duration
 	now         func() time.Time
 }
 
@@ -37,13 +97,31 @@ func NewAuthService(
 	tokenRepo domain.RefreshTokenRepository,
 	sessionRepo domain.SessionRepository,
 	userSrv userApp.UserService,
+	tenants TenantProvisioner,
+	members TenantMembership,
+	sso *SSO,
+	mfa *MFA,
+	audit auditDomain.Recorder,
+	refreshTTL time.Duration,
 ) AuthService {
+	if refreshTTL <= 0 {
+		refreshTTL = defaultRefreshTokenTTL
+	}
+	if audit == nil {
+		audit = auditDomain.Discard
+	}
 	return &authService{
 		logger:      logger.Named("auth_service"),
 		tokens:      tokens,
 		tokenRepo:   tokenRepo,
 		sessionRepo: sessionRepo,
 		userSrv:     userSrv,
+		tenants:     tenants,
+		members:     members,
+		sso:         sso,
+		mfa:         mfa,
+		audit:       audit,
+		refreshTTL:  refreshTTL,
 		now:         time.Now,
 	}
 }
@@ -62,81 +140,353 @@ func (s *authService) Register(ctx context.Context, input RegisterInput) (*Token
 		return nil, err
 	}
 
-	// session
-	session := &domain.Session{
-		UserID:    user.ID,
-		UserAgent: "test-agent",
-		IPAddress: net.IPv4(0, 0, 0, 0),
+	// The tenant_id claim is the user's personal tenant; it must exist before
+	// the first token is handed out or ingest and search reject the caller.
+	if err := s.tenants.EnsurePersonalTenant(ctx, user.ID, user.FullName); err != nil {
+		return nil, err
 	}
-	err = s.sessionRepo.Create(ctx, session)
+
+	output, err := s.startSession(ctx, user.ID, input.Client)
 	if err != nil {
 		return nil, err
 	}
 
-	output := &TokenOutput{}
-	output.TokenType = "Bearer"
+	s.logger.Info("user registered", zap.String("user_id", user.ID.String()))
+	return output, nil
+}
 
-	accessToken, err := s.tokens.GenerateToken(map[string]interface{}{
-		"sub":       user.ID.String(),
-		"tenant_id": user.ID.String(), // placeholder until a real tenant model exists
-	})
-	output.AccessToken = accessToken
+func (s *authService) Login(ctx context.Context, input LoginInput) (*TokenOutput, error) {
+	attemptKey := loginAttemptKey(input.Email)
+	if err := s.checkLockout(ctx, attemptKey); err != nil {
+		if errors.Is(err, domain.ErrAccountLocked) {
+			s.auditLoginFailure(ctx, input.Email, err)
+		}
+		return nil, err
+	}
+	user, err := s.userSrv.Authenticate(ctx, input.Email, input.Password)
+	if err != nil {
+		if errors.Is(err, userDomain.ErrInvalidCredentials) {
+			err = s.recordFailure(ctx, attemptKey, domain.ErrInvalidCredentials)
+			s.auditLoginFailure(ctx, input.Email, err)
+			if errors.Is(err, domain.ErrAccountLocked) {
+				s.audit.Record(ctx, auditDomain.Event{
+					Action:     auditDomain.ActionLockedOut,
+					Outcome:    auditDomain.OutcomeDenied,
+					TargetType: "user",
+					TargetID:   input.Email,
+					Metadata:   map[string]any{"factor": loginPassword},
+				})
+			}
+			return nil, err
+		}
+		s.logger.Error("login: authenticate failed", zap.String("email", input.Email), zap.Error(err))
+		return nil, err
+	}
+	s.resetAttempts(ctx, attemptKey)
+	if err := s.checkPasswordLogin(ctx, user.ID); err != nil {
+		if errors.Is(err, domain.ErrSSORequired) {
+			s.auditUser(ctx, auditDomain.ActionLoginFailed, auditDomain.OutcomeDenied, user.ID, map[string]any{"method": loginPassword, "reason": err.Error()})
+		}
+		return nil, err
+	}
 
-	plainRefreshToken, err := s.generateRandomToken()
+	// Idempotent; repairs users whose registration failed half-way.
+	if err := s.tenants.EnsurePersonalTenant(ctx, user.ID, user.FullName); err != nil {
+		return nil, err
+	}
+
+	challenge, err := s.mfaChallenge(ctx, user.ID)
 	if err != nil {
-		return nil, fmt.Errorf("generate refresh token: %w", err)
+		return nil, err
+	}
+	if challenge != nil {
+		s.logger.Info("login awaiting second factor", zap.String("user_id", user.ID.String()))
+		return challenge, nil
 	}
-	output.RefreshToken = plainRefreshToken
 
-	rt := domain.NewRefreshToken(user.ID, session.ID, plainRefreshToken)
-	if err := s.tokenRepo.Create(ctx, rt); err != nil {
-		s.logger.Error("persist refresh token failed", zap.String("user_id", user.ID.String()), zap.Error(err))
+	output, err := s.startSession(ctx, user.ID, input.Client)
+	if err != nil {
 		return nil, err
 	}
 
-	s.logger.Info("user registered", zap.String("user_id", user.ID.String()))
+	s.auditUser(ctx, auditDomain.ActionLogin, auditDomain.OutcomeSuccess, user.ID, map[string]any{"method": loginPassword})
+	s.logger.Info("user logged in", zap.String("user_id", user.ID.String()))
 	return output, nil
 }
 
-func (s *authService) Login(ctx context.Context, input LoginInput) (*TokenOutput, error) {
-	user, err := s.userSrv.Authenticate(ctx, input.Email, input.Password)
+func (s *authService) Refresh(ctx context.Context, input RefreshInput) (*TokenOutput, error) {
+	current, err := s.tokenRepo.GetByToken(ctx, domain.HashRefreshToken(input.RefreshToken))
 	if err != nil {
-		if errors.Is(err, userDomain.ErrInvalidCredentials) {
-			return nil, domain.ErrInvalidCredentials
+		return nil, err
+	}
+	if current.Revoked {
+		s.revokeFamily(ctx, current)
+		return nil, domain.ErrTokenAlreadyUsed
+	}
+
+	now := s.now()
+	if current.IsExpired(now) {
+		return nil, domain.ErrTokenExpired
+	}
+
+	session, err := s.sessionRepo.GetByID(ctx, current.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.RevokedAt != nil {
+		return nil, domain.ErrSessionRevoked
+	}
+	if !session.IsActive(now) {
+		return nil, domain.ErrSessionExpired
+	}
+
+	plain, err := s.generateRandomToken()
+	if err != nil {
+		return nil, fmt.Errorf("generate refresh token: %w", err)
+	}
+	next := domain.NewRefreshToken(current.UserID, session.ID, plain)
+	next.ExpiresAt = now.Add(s.refreshTTL)
+
+	if err := s.tokenRepo.Rotate(ctx, current, next); err != nil {
+		if errors.Is(err, domain.ErrTokenAlreadyUsed) {
+			// Lost a race against another refresh of the same token.
+			s.revokeFamily(ctx, current)
 		}
-		s.logger.Error("login: authenticate failed", zap.String("email", input.Email), zap.Error(err))
 		return nil, err
 	}
 
-	output := &TokenOutput{}
+	if err := s.sessionRepo.Touch(ctx, session.ID, domain.ClientInfo(input.Client)); err != nil {
+		s.logger.Warn("touch session failed", zap.String("session_id", session.ID.String()), zap.Error(err))
+	}
 
-	accessToken, err := s.tokens.GenerateToken(map[string]interface{}{
-		"sub":       user.ID.String(),
-		"tenant_id": user.ID.String(), // placeholder until a real tenant model exists
-	})
-	output.AccessToken = accessToken
-	output.TokenType = "Bearer"
+	accessToken, err := s.accessToken(current.UserID, session.ID, s.sessionTenant(ctx, session))
+	if err != nil {
+		return nil, err
+	}
+	return &TokenOutput{AccessToken: accessToken, RefreshToken: plain, TokenType: "Bearer"}, nil
+}
+
+func (s *authService) SwitchTenant(ctx context.Context, input SwitchTenantInput) (*TokenOutput, error) {
+	var tenant *uuid.UUID
+	if input.TenantID != input.UserID {
+		ok, err := s.members.IsTenantMember(ctx, input.UserID, input.TenantID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, domain.ErrNotTenantMember
+		}
+		tenant = &input.TenantID
+	}
+
+	if err := s.sessionRepo.SetTenant(ctx, input.UserID, input.SessionID, tenant); err != nil {
+		return nil, err
+	}
+	accessToken, err := s.accessToken(input.UserID, input.SessionID, input.TenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("session switched tenant",
+		zap.String("user_id", input.UserID.String()),
+		zap.String("session_id", input.SessionID.String()),
+		zap.String("tenant_id", input.TenantID.String()),
+	)
+	return &TokenOutput{AccessToken: accessToken, TokenType: "Bearer"}, nil
+}
+
+// checkPasswordLogin refuses password logins of users belonging to a tenant
+// that enforces SSO. Enforcement needs SSO to be configured.
+func (s *authService) checkPasswordLogin(ctx context.Context, userID uuid.UUID) error {
+	if s.sso == nil {
+		return nil
+	}
+	required, err := s.sso.Identities.RequiresSSO(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if required {
+		return domain.ErrSSORequired
+	}
+	return nil
+}
+
+// sessionTenant returns the tenant a refreshed session acts in. A session
+// whose user has since lost every membership in it falls back to the
+// personal tenant.
+func (s *authService) sessionTenant(ctx context.Context, session *domain.Session) uuid.UUID {
+	if session.TenantID == nil || *session.TenantID == session.UserID {
+		return session.UserID
+	}
+	ok, err := s.members.IsTenantMember(ctx, session.UserID, *session.TenantID)
+	if err != nil {
+		// Permissions are still checked per request; keep the tenant.
+		s.logger.Warn("tenant membership check failed", zap.String("session_id", session.ID.String()), zap.Error(err))
+		return *session.TenantID
+	}
+	if ok {
+		return *session.TenantID
+	}
+	if err := s.sessionRepo.SetTenant(ctx, session.UserID, session.ID, nil); err != nil {
+		s.logger.Warn("reset session tenant failed", zap.String("session_id", session.ID.String()), zap.Error(err))
+	}
+	return session.UserID
+}
+
+func (s *authService) Logout(ctx context.Context, input LogoutInput) error {
+	rt, err := s.tokenRepo.GetByToken(ctx, domain.HashRefreshToken(input.RefreshToken))
+	if err != nil {
+		if errors.Is(err, domain.ErrTokenNotFound) {
+			// Unknown tokens are already as logged-out as they can be.
+			return nil
+		}
+		return err
+	}
+	if err := s.sessionRepo.Revoke(ctx, rt.UserID, rt.SessionID); err != nil && !errors.Is(err, domain.ErrSessionNotFound) {
+		return err
+	}
+	if err := s.tokenRepo.RevokeBySession(ctx, rt.SessionID); err != nil {
+		return err
+	}
+	s.logger.Info("user logged out",
+		zap.String("user_id", rt.UserID.String()),
+		zap.String("session_id", rt.SessionID.String()),
+	)
+	return nil
+}
+
+func (s *authService) ListSessions(ctx context.Context, userID, currentSessionID uuid.UUID) ([]*SessionOutput, error) {
+	sessions, err := s.sessionRepo.ListActive(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*SessionOutput, len(sessions))
+	for i, sess := range sessions {
+		out[i] = toSessionOutput(sess, currentSessionID)
+	}
+	return out, nil
+}
+
+func (s *authService) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	if err := s.sessionRepo.Revoke(ctx, userID, sessionID); err != nil {
+		return err
+	}
+	if err := s.tokenRepo.RevokeBySession(ctx, sessionID); err != nil {
+		return err
+	}
+	s.logger.Info("session revoked",
+		zap.String("user_id", userID.String()),
+		zap.String("session_id", sessionID.String()),
+	)
+	return nil
+}
+
+func (s *authService) RevokeAllSessions(ctx context.Context, userID uuid.UUID) error {
+	if err := s.sessionRepo.RevokeAll(ctx, userID); err != nil {
+		return err
+	}
+	if err := s.tokenRepo.RevokeByUser(ctx, userID); err != nil {
+		return err
+	}
+	s.logger.Info("all sessions revoked", zap.String("user_id", userID.String()))
+	return nil
+}
+
+// startSession opens a new session for the user and issues the first
+// access/refresh token pair of its rotation family.
+func (s *authService) startSession(ctx context.Context, userID uuid.UUID, client ClientInput) (*TokenOutput, error) {
+	now := s.now()
+	expiresAt := now.Add(s.refreshTTL)
+
+	ip := client.IPAddress
+	if ip == nil {
+		ip = net.IPv4zero
+	}
+	session := &domain.Session{
+		UserID:    userID,
+		UserAgent: client.UserAgent,
+		IPAddress: ip,
+		ExpiresAt: &expiresAt,
+	}
+	if err := s.sessionRepo.Create(ctx, session); err != nil {
+		s.logger.Error("create session failed", zap.String("user_id", userID.String()), zap.Error(err))
+		return nil, err
+	}
+
+	accessToken, err := s.accessToken(userID, session.ID, userID)
+	if err != nil {
+		return nil, err
+	}
 
 	plainRefreshToken, err := s.generateRandomToken()
 	if err != nil {
 		return nil, fmt.Errorf("generate refresh token: %w", err)
 	}
-	output.RefreshToken = plainRefreshToken
 
-	// rt := domain.NewRefreshToken(user.ID, session.ID, plainRefreshToken)
-	// if err := s.tokenRepo.Create(ctx, rt); err != nil {
-	// 	s.logger.Error("persist refresh token failed", zap.String("user_id", user.ID.String()), zap.Error(err))
-	// 	return nil, err
-	// }
+	rt := domain.NewRefreshToken(userID, session.ID, plainRefreshToken)
+	rt.ExpiresAt = expiresAt
+	if err := s.tokenRepo.Create(ctx, rt); err != nil {
+		s.logger.Error("persist refresh token failed", zap.String("user_id", userID.String()), zap.Error(err))
+		return nil, err
+	}
 
-	s.logger.Info("user logged in", zap.String("user_id", user.ID.String()))
-	return output, nil
+	return &TokenOutput{
+		AccessToken:  accessToken,
+		RefreshToken: plainRefreshToken,
+		TokenType:    "Bearer",
+	}, nil
+}
+
+// accessToken signs a JWT for the user bound to the given session and
+// tenant. The "sid" claim lets revocation take effect once the short-lived
+// token expires.
+func (s *authService) accessToken(userID, sessionID, tenantID uuid.UUID) (string, error) {
+	token, err := s.tokens.GenerateToken(map[string]interface{}{
+		"sub":       userID.String(),
+		"sid":       sessionID.String(),
+		"tenant_id": tenantID.String(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("generate access token: %w", err)
+	}
+	return token, nil
+}
+
+// revokeFamily revokes the session and every refresh token descended from it
+// after a rotated token was presented again.
+func (s *authService) revokeFamily(ctx context.Context, rt *domain.RefreshToken) {
+	s.logger.Warn("refresh token reuse detected, revoking session",
+		zap.String("user_id", rt.UserID.String()),
+		zap.String("session_id", rt.SessionID.String()),
+	)
+	if err := s.sessionRepo.Revoke(ctx, rt.UserID, rt.SessionID); err != nil && !errors.Is(err, domain.ErrSessionNotFound) {
+		s.logger.Error("revoke session failed", zap.String("session_id", rt.SessionID.String()), zap.Error(err))
+	}
+	if err := s.tokenRepo.RevokeBySession(ctx, rt.SessionID); err != nil {
+		s.logger.Error("revoke token family failed", zap.String("session_id", rt.SessionID.String()), zap.Error(err))
+	}
 }
 
 func (s *authService) generateRandomToken() (string, error) {
-	b := make([]byte, 16) // 128 bits
+	b := make([]byte, 32) // 256 bits
 	if _, err := rand.Read(b); err != nil {
 		return "", err
 	}
 	return base64.RawURLEncoding.EncodeToString(b), nil
 }
+
+func toSessionOutput(s *domain.Session, currentSessionID uuid.UUID) *SessionOutput {
+	ip := ""
+	if s.IPAddress != nil {
+		ip = s.IPAddress.String()
+	}
+	return &SessionOutput{
+		ID:        s.ID,
+		IPAddress: ip,
+		UserAgent: s.UserAgent,
+		CreatedAt: s.CreatedAt,
+		LastUsed:  s.RefreshAt,
+		ExpiresAt: s.ExpiresAt,
+		Current:   s.ID == currentSessionID,
+	}
+}
@@ -7,6 +7,7 @@ package application
 import (
 	"context"
 
+	"github.com/google/uuid"
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -166,3 +167,993 @@ func (_c *MockAuthService_Login_Call) RunAndReturn(run func(ctx context.Context,
 	_c.Call.Return(run)
 	return _c
 }
+
+// Refresh provides a mock function for the type MockAuthService
+func (_mock *MockAuthService) Refresh(ctx context.Context, input RefreshInput) (*TokenOutput, error) {
+	ret := _mock.Called(ctx, input)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Refresh")
+	}
+
+	var r0 *TokenOutput
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, RefreshInput) (*TokenOutput, error)); ok {
+		return returnFunc(ctx, input)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, RefreshInput) *TokenOutput); ok {
+		r0 = returnFunc(ctx, input)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*TokenOutput)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, RefreshInput) error); ok {
+		r1 = returnFunc(ctx, input)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAuthService_Refresh_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Refresh'
+type MockAuthService_Refresh_Call struct {
+	*mock.Call
+}
+
+// Refresh is a helper method to define mock.On call
+//   - ctx context.Context
+//   - input RefreshInput
+func (_e *MockAuthService_Expecter) Refresh(ctx interface{}, input interface{}) *MockAuthService_Refresh_Call {
+	return &MockAuthService_Refresh_Call{Call: _e.mock.On("Refresh", ctx, input)}
+}
+
+func (_c *MockAuthService_Refresh_Call) Run(run func(ctx context.Context, input RefreshInput)) *MockAuthService_Refresh_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 RefreshInput
+		if args[1] != nil {
+			arg1 = args[1].(RefreshInput)
+		}
+		run(arg0, arg1)
+	})
+	return _c
+}
+
+func (_c *MockAuthService_Refresh_Call) Return(v *TokenOutput, err error) *MockAuthService_Refresh_Call {
+	_c.Call.Return(v, err)
+	return _c
+}
+
+func (_c *MockAuthService_Refresh_Call) RunAndReturn(run func(ctx context.Context, input RefreshInput) (*TokenOutput, error)) *MockAuthService_Refresh_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Logout provides a mock function for the type MockAuthService
+func (_mock *MockAuthService) Logout(ctx context.Context, input LogoutInput) error {
+	ret := _mock.Called(ctx, input)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Logout")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, LogoutInput) error); ok {
+		r0 = returnFunc(ctx, input)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAuthService_Logout_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Logout'
+type MockAuthService_Logout_Call struct {
+	*mock.Call
+}
+
+// Logout is a helper method to define mock.On call
+//   - ctx context.Context
+//   - input LogoutInput
+func (_e *MockAuthService_Expecter) Logout(ctx interface{}, input interface{}) *MockAuthService_Logout_Call {
+	return &MockAuthService_Logout_Call{Call: _e.mock.On("Logout", ctx, input)}
+}
+
+func (_c *MockAuthService_Logout_Call) Run(run func(ctx context.Context, input LogoutInput)) *MockAuthService_Logout_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 LogoutInput
+		if args[1] != nil {
+			arg1 = args[1].(LogoutInput)
+		}
+		run(arg0, arg1)
+	})
+	return _c
+}
+
+func (_c *MockAuthService_Logout_Call) Return(err error) *MockAuthService_Logout_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAuthService_Logout_Call) RunAndReturn(run func(ctx context.Context, input LogoutInput) error) *MockAuthService_Logout_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListSessions provides a mock function for the type MockAuthService
+func (_mock *MockAuthService) ListSessions(ctx context.Context, userID uuid.UUID, currentSessionID uuid.UUID) ([]*SessionOutput, error) {
+	ret := _mock.Called(ctx, userID, currentSessionID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListSessions")
+	}
+
+	var r0 []*SessionOutput
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) ([]*SessionOutput, error)); ok {
+		return returnFunc(ctx, userID, currentSessionID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) []*SessionOutput); ok {
+		r0 = returnFunc(ctx, userID, currentSessionID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*SessionOutput)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
+		r1 = returnFunc(ctx, userID, currentSessionID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAuthService_ListSessions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListSessions'
+type MockAuthService_ListSessions_Call struct {
+	*mock.Call
+}
+
+// ListSessions is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uuid.UUID
+//   - currentSessionID uuid.UUID
+func (_e *MockAuthService_Expecter) ListSessions(ctx interface{}, userID interface{}, currentSessionID interface{}) *MockAuthService_ListSessions_Call {
+	return &MockAuthService_ListSessions_Call{Call: _e.mock.On("ListSessions", ctx, userID, currentSessionID)}
+}
+
+func (_c *MockAuthService_ListSessions_Call) Run(run func(ctx context.Context, userID uuid.UUID, currentSessionID uuid.UUID)) *MockAuthService_ListSessions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 uuid.UUID
+		if args[2] != nil {
+			arg2 = args[2].(uuid.UUID)
+		}
+		run(arg0, arg1, arg2)
+	})
+	return _c
+}
+
+func (_c *MockAuthService_ListSessions_Call) Return(v []*SessionOutput, err error) *MockAuthService_ListSessions_Call {
+	_c.Call.Return(v, err)
+	return _c
+}
+
+func (_c *MockAuthService_ListSessions_Call) RunAndReturn(run func(ctx context.Context, userID uuid.UUID, currentSessionID uuid.UUID) ([]*SessionOutput, error)) *MockAuthService_ListSessions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeSession provides a mock function for the type MockAuthService
+func (_mock *MockAuthService) RevokeSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) error {
+	ret := _mock.Called(ctx, userID, sessionID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeSession")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
+		r0 = returnFunc(ctx, userID, sessionID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAuthService_RevokeSession_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeSession'
+type MockAuthService_RevokeSession_Call struct {
+	*mock.Call
+}
+
+// RevokeSession is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uuid.UUID
+//   - sessionID uuid.UUID
+func (_e *MockAuthService_Expecter) RevokeSession(ctx interface{}, userID interface{}, sessionID interface{}) *MockAuthService_RevokeSession_Call {
+	return &MockAuthService_RevokeSession_Call{Call: _e.mock.On("RevokeSession", ctx, userID, sessionID)}
+}
+
+func (_c *MockAuthService_RevokeSession_Call) Run(run func(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID)) *MockAuthService_RevokeSession_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 uuid.UUID
+		if args[2] != nil {
+			arg2 = args[2].(uuid.UUID)
+		}
+		run(arg0, arg1, arg2)
+	})
+	return _c
+}
+
+func (_c *MockAuthService_RevokeSession_Call) Return(err error) *MockAuthService_RevokeSession_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAuthService_RevokeSession_Call) RunAndReturn(run func(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) error) *MockAuthService_RevokeSession_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeAllSessions provides a mock function for the type MockAuthService
+func (_mock *MockAuthService) RevokeAllSessions(ctx context.Context, userID uuid.UUID) error {
+	ret := _mock.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeAllSessions")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+		r0 = returnFunc(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAuthService_RevokeAllSessions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeAllSessions'
+type MockAuthService_RevokeAllSessions_Call struct {
+	*mock.Call
+}
+
+// RevokeAllSessions is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uuid.UUID
+func (_e *MockAuthService_Expecter) RevokeAllSessions(ctx interface{}, userID interface{}) *MockAuthService_RevokeAllSessions_Call {
+	return &MockAuthService_RevokeAllSessions_Call{Call: _e.mock.On("RevokeAllSessions", ctx, userID)}
+}
+
+func (_c *MockAuthService_RevokeAllSessions_Call) Run(run func(ctx context.Context, userID uuid.UUID)) *MockAuthService_RevokeAllSessions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		run(arg0, arg1)
+	})
+	return _c
+}
+
+func (_c *MockAuthService_RevokeAllSessions_Call) Return(err error) *MockAuthService_RevokeAllSessions_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAuthService_RevokeAllSessions_Call) RunAndReturn(run func(ctx context.Context, userID uuid.UUID) error) *MockAuthService_RevokeAllSessions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SwitchTenant provides a mock function for the type MockAuthService
+func (_mock *MockAuthService) SwitchTenant(ctx context.Context, input SwitchTenantInput) (*TokenOutput, error) {
+	ret := _mock.Called(ctx, input)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SwitchTenant")
+	}
+
+	var r0 *TokenOutput
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, SwitchTenantInput) (*TokenOutput, error)); ok {
+		return returnFunc(ctx, input)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, SwitchTenantInput) *TokenOutput); ok {
+		r0 = returnFunc(ctx, input)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*TokenOutput)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, SwitchTenantInput) error); ok {
+		r1 = returnFunc(ctx, input)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAuthService_SwitchTenant_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SwitchTenant'
+type MockAuthService_SwitchTenant_Call struct {
+	*mock.Call
+}
+
+// SwitchTenant is a helper method to define mock.On call
+//   - ctx context.Context
+//   - input SwitchTenantInput
+func (_e *MockAuthService_Expecter) SwitchTenant(ctx interface{}, input interface{}) *MockAuthService_SwitchTenant_Call {
+	return &MockAuthService_SwitchTenant_Call{Call: _e.mock.On("SwitchTenant", ctx, input)}
+}
+
+func (_c *MockAuthService_SwitchTenant_Call) Run(run func(ctx context.Context, input SwitchTenantInput)) *MockAuthService_SwitchTenant_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 SwitchTenantInput
+		if args[1] != nil {
+			arg1 = args[1].(SwitchTenantInput)
+		}
+		run(arg0, arg1)
+	})
+	return _c
+}
+
+func (_c *MockAuthService_SwitchTenant_Call) Return(v *TokenOutput, err error) *MockAuthService_SwitchTenant_Call {
+	_c.Call.Return(v, err)
+	return _c
+}
+
+func (_c *MockAuthService_SwitchTenant_Call) RunAndReturn(run func(ctx context.Context, input SwitchTenantInput) (*TokenOutput, error)) *MockAuthService_SwitchTenant_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SSOAuthorize provides a mock function for the type MockAuthService
+func (_mock *MockAuthService) SSOAuthorize(ctx context.Context) (*SSOAuthorizeOutput, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SSOAuthorize")
+	}
+
+	var r0 *SSOAuthorizeOutput
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) (*SSOAuthorizeOutput, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) *SSOAuthorizeOutput); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*SSOAuthorizeOutput)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAuthService_SSOAuthorize_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SSOAuthorize'
+type MockAuthService_SSOAuthorize_Call struct {
+	*mock.Call
+}
+
+// SSOAuthorize is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockAuthService_Expecter) SSOAuthorize(ctx interface{}) *MockAuthService_SSOAuthorize_Call {
+	return &MockAuthService_SSOAuthorize_Call{Call: _e.mock.On("SSOAuthorize", ctx)}
+}
+
+func (_c *MockAuthService_SSOAuthorize_Call) Run(run func(ctx context.Context)) *MockAuthService_SSOAuthorize_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(arg0)
+	})
+	return _c
+}
+
+func (_c *MockAuthService_SSOAuthorize_Call) Return(v *SSOAuthorizeOutput, err error) *MockAuthService_SSOAuthorize_Call {
+	_c.Call.Return(v, err)
+	return _c
+}
+
+func (_c *MockAuthService_SSOAuthorize_Call) RunAndReturn(run func(ctx context.Context) (*SSOAuthorizeOutput, error)) *MockAuthService_SSOAuthorize_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SSOCallback provides a mock function for the type MockAuthService
+func (_mock *MockAuthService) SSOCallback(ctx context.Context, input SSOCallbackInput) (*TokenOutput, error) {
+	ret := _mock.Called(ctx, input)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SSOCallback")
+	}
+
+	var r0 *TokenOutput
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, SSOCallbackInput) (*TokenOutput, error)); ok {
+		return returnFunc(ctx, input)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, SSOCallbackInput) *TokenOutput); ok {
+		r0 = returnFunc(ctx, input)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*TokenOutput)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, SSOCallbackInput) error); ok {
+		r1 = returnFunc(ctx, input)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAuthService_SSOCallback_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SSOCallback'
+type MockAuthService_SSOCallback_Call struct {
+	*mock.Call
+}
+
+// SSOCallback is a helper method to define mock.On call
+//   - ctx context.Context
+//   - input SSOCallbackInput
+func (_e *MockAuthService_Expecter) SSOCallback(ctx interface{}, input interface{}) *MockAuthService_SSOCallback_Call {
+	return &MockAuthService_SSOCallback_Call{Call: _e.mock.On("SSOCallback", ctx, input)}
+}
+
+func (_c *MockAuthService_SSOCallback_Call) Run(run func(ctx context.Context, input SSOCallbackInput)) *MockAuthService_SSOCallback_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 SSOCallbackInput
+		if args[1] != nil {
+			arg1 = args[1].(SSOCallbackInput)
+		}
+		run(arg0, arg1)
+	})
+	return _c
+}
+
+func (_c *MockAuthService_SSOCallback_Call) Return(v *TokenOutput, err error) *MockAuthService_SSOCallback_Call {
+	_c.Call.Return(v, err)
+	return _c
+}
+
+func (_c *MockAuthService_SSOCallback_Call) RunAndReturn(run func(ctx context.Context, input SSOCallbackInput) (*TokenOutput, error)) *MockAuthService_SSOCallback_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// VerifyMFA provides a mock function for the type MockAuthService
+func (_mock *MockAuthService) VerifyMFA(ctx context.Context, input VerifyMFAInput) (*TokenOutput, error) {
+	ret := _mock.Called(ctx, input)
+
+	if len(ret) == 0 {
+		panic("no return value specified for VerifyMFA")
+	}
+
+	var r0 *TokenOutput
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, VerifyMFAInput) (*TokenOutput, error)); ok {
+		return returnFunc(ctx, input)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, VerifyMFAInput) *TokenOutput); ok {
+		r0 = returnFunc(ctx, input)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*TokenOutput)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, VerifyMFAInput) error); ok {
+		r1 = returnFunc(ctx, input)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAuthService_VerifyMFA_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'VerifyMFA'
+type MockAuthService_VerifyMFA_Call struct {
+	*mock.Call
+}
+
+// VerifyMFA is a helper method to define mock.On call
+//   - ctx context.Context
+//   - input VerifyMFAInput
+func (_e *MockAuthService_Expecter) VerifyMFA(ctx interface{}, input interface{}) *MockAuthService_VerifyMFA_Call {
+	return &MockAuthService_VerifyMFA_Call{Call: _e.mock.On("VerifyMFA", ctx, input)}
+}
+
+func (_c *MockAuthService_VerifyMFA_Call) Run(run func(ctx context.Context, input VerifyMFAInput)) *MockAuthService_VerifyMFA_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 VerifyMFAInput
+		if args[1] != nil {
+			arg1 = args[1].(VerifyMFAInput)
+		}
+		run(arg0, arg1)
+	})
+	return _c
+}
+
+func (_c *MockAuthService_VerifyMFA_Call) Return(v *TokenOutput, err error) *MockAuthService_VerifyMFA_Call {
+	_c.Call.Return(v, err)
+	return _c
+}
+
+func (_c *MockAuthService_VerifyMFA_Call) RunAndReturn(run func(ctx context.Context, input VerifyMFAInput) (*TokenOutput, error)) *MockAuthService_VerifyMFA_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// StartChallengeEnrollment provides a mock function for the type MockAuthService
+func (_mock *MockAuthService) StartChallengeEnrollment(ctx context.Context, mfaToken string) (*MFAEnrollmentOutput, error) {
+	ret := _mock.Called(ctx, mfaToken)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StartChallengeEnrollment")
+	}
+
+	var r0 *MFAEnrollmentOutput
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*MFAEnrollmentOutput, error)); ok {
+		return returnFunc(ctx, mfaToken)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *MFAEnrollmentOutput); ok {
+		r0 = returnFunc(ctx, mfaToken)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*MFAEnrollmentOutput)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, mfaToken)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAuthService_StartChallengeEnrollment_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'StartChallengeEnrollment'
+type MockAuthService_StartChallengeEnrollment_Call struct {
+	*mock.Call
+}
+
+// StartChallengeEnrollment is a helper method to define mock.On call
+//   - ctx context.Context
+//   - mfaToken string
+func (_e *MockAuthService_Expecter) StartChallengeEnrollment(ctx interface{}, mfaToken interface{}) *MockAuthService_StartChallengeEnrollment_Call {
+	return &MockAuthService_StartChallengeEnrollment_Call{Call: _e.mock.On("StartChallengeEnrollment", ctx, mfaToken)}
+}
+
+func (_c *MockAuthService_StartChallengeEnrollment_Call) Run(run func(ctx context.Context, mfaToken string)) *MockAuthService_StartChallengeEnrollment_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(arg0, arg1)
+	})
+	return _c
+}
+
+func (_c *MockAuthService_StartChallengeEnrollment_Call) Return(v *MFAEnrollmentOutput, err error) *MockAuthService_StartChallengeEnrollment_Call {
+	_c.Call.Return(v, err)
+	return _c
+}
+
+func (_c *MockAuthService_StartChallengeEnrollment_Call) RunAndReturn(run func(ctx context.Context, mfaToken string) (*MFAEnrollmentOutput, error)) *MockAuthService_StartChallengeEnrollment_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// MFAStatus provides a mock function for the type MockAuthService
+func (_mock *MockAuthService) MFAStatus(ctx context.Context, userID uuid.UUID) (*MFAStatusOutput, error) {
+	ret := _mock.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MFAStatus")
+	}
+
+	var r0 *MFAStatusOutput
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID) (*MFAStatusOutput, error)); ok {
+		return returnFunc(ctx, userID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID) *MFAStatusOutput); ok {
+		r0 = returnFunc(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*MFAStatusOutput)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = returnFunc(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAuthService_MFAStatus_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MFAStatus'
+type MockAuthService_MFAStatus_Call struct {
+	*mock.Call
+}
+
+// MFAStatus is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uuid.UUID
+func (_e *MockAuthService_Expecter) MFAStatus(ctx interface{}, userID interface{}) *MockAuthService_MFAStatus_Call {
+	return &MockAuthService_MFAStatus_Call{Call: _e.mock.On("MFAStatus", ctx, userID)}
+}
+
+func (_c *MockAuthService_MFAStatus_Call) Run(run func(ctx context.Context, userID uuid.UUID)) *MockAuthService_MFAStatus_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		run(arg0, arg1)
+	})
+	return _c
+}
+
+func (_c *MockAuthService_MFAStatus_Call) Return(v *MFAStatusOutput, err error) *MockAuthService_MFAStatus_Call {
+	_c.Call.Return(v, err)
+	return _c
+}
+
+func (_c *MockAuthService_MFAStatus_Call) RunAndReturn(run func(ctx context.Context, userID uuid.UUID) (*MFAStatusOutput, error)) *MockAuthService_MFAStatus_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// StartMFAEnrollment provides a mock function for the type MockAuthService
+func (_mock *MockAuthService) StartMFAEnrollment(ctx context.Context, userID uuid.UUID) (*MFAEnrollmentOutput, error) {
+	ret := _mock.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StartMFAEnrollment")
+	}
+
+	var r0 *MFAEnrollmentOutput
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID) (*MFAEnrollmentOutput, error)); ok {
+		return returnFunc(ctx, userID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID) *MFAEnrollmentOutput); ok {
+		r0 = returnFunc(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*MFAEnrollmentOutput)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = returnFunc(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAuthService_StartMFAEnrollment_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'StartMFAEnrollment'
+type MockAuthService_StartMFAEnrollment_Call struct {
+	*mock.Call
+}
+
+// StartMFAEnrollment is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uuid.UUID
+func (_e *MockAuthService_Expecter) StartMFAEnrollment(ctx interface{}, userID interface{}) *MockAuthService_StartMFAEnrollment_Call {
+	return &MockAuthService_StartMFAEnrollment_Call{Call: _e.mock.On("StartMFAEnrollment", ctx, userID)}
+}
+
+func (_c *MockAuthService_StartMFAEnrollment_Call) Run(run func(ctx context.Context, userID uuid.UUID)) *MockAuthService_StartMFAEnrollment_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		run(arg0, arg1)
+	})
+	return _c
+}
+
+func (_c *MockAuthService_StartMFAEnrollment_Call) Return(v *MFAEnrollmentOutput, err error) *MockAuthService_StartMFAEnrollment_Call {
+	_c.Call.Return(v, err)
+	return _c
+}
+
+func (_c *MockAuthService_StartMFAEnrollment_Call) RunAndReturn(run func(ctx context.Context, userID uuid.UUID) (*MFAEnrollmentOutput, error)) *MockAuthService_StartMFAEnrollment_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// EnableMFA provides a mock function for the type MockAuthService
+func (_mock *MockAuthService) EnableMFA(ctx context.Context, input MFACodeInput) (*RecoveryCodesOutput, error) {
+	ret := _mock.Called(ctx, input)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EnableMFA")
+	}
+
+	var r0 *RecoveryCodesOutput
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, MFACodeInput) (*RecoveryCodesOutput, error)); ok {
+		return returnFunc(ctx, input)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, MFACodeInput) *RecoveryCodesOutput); ok {
+		r0 = returnFunc(ctx, input)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*RecoveryCodesOutput)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, MFACodeInput) error); ok {
+		r1 = returnFunc(ctx, input)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAuthService_EnableMFA_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'EnableMFA'
+type MockAuthService_EnableMFA_Call struct {
+	*mock.Call
+}
+
+// EnableMFA is a helper method to define mock.On call
+//   - ctx context.Context
+//   - input MFACodeInput
+func (_e *MockAuthService_Expecter) EnableMFA(ctx interface{}, input interface{}) *MockAuthService_EnableMFA_Call {
+	return &MockAuthService_EnableMFA_Call{Call: _e.mock.On("EnableMFA", ctx, input)}
+}
+
+func (_c *MockAuthService_EnableMFA_Call) Run(run func(ctx context.Context, input MFACodeInput)) *MockAuthService_EnableMFA_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 MFACodeInput
+		if args[1] != nil {
+			arg1 = args[1].(MFACodeInput)
+		}
+		run(arg0, arg1)
+	})
+	return _c
+}
+
+func (_c *MockAuthService_EnableMFA_Call) Return(v *RecoveryCodesOutput, err error) *MockAuthService_EnableMFA_Call {
+	_c.Call.Return(v, err)
+	return _c
+}
+
+func (_c *MockAuthService_EnableMFA_Call) RunAndReturn(run func(ctx context.Context, input MFACodeInput) (*RecoveryCodesOutput, error)) *MockAuthService_EnableMFA_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DisableMFA provides a mock function for the type MockAuthService
+func (_mock *MockAuthService) DisableMFA(ctx context.Context, input MFACodeInput) error {
+	ret := _mock.Called(ctx, input)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DisableMFA")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, MFACodeInput) error); ok {
+		r0 = returnFunc(ctx, input)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAuthService_DisableMFA_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DisableMFA'
+type MockAuthService_DisableMFA_Call struct {
+	*mock.Call
+}
+
+// DisableMFA is a helper method to define mock.On call
+//   - ctx context.Context
+//   - input MFACodeInput
+func (_e *MockAuthService_Expecter) DisableMFA(ctx interface{}, input interface{}) *MockAuthService_DisableMFA_Call {
+	return &MockAuthService_DisableMFA_Call{Call: _e.mock.On("DisableMFA", ctx, input)}
+}
+
+func (_c *MockAuthService_DisableMFA_Call) Run(run func(ctx context.Context, input MFACodeInput)) *MockAuthService_DisableMFA_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 MFACodeInput
+		if args[1] != nil {
+			arg1 = args[1].(MFACodeInput)
+		}
+		run(arg0, arg1)
+	})
+	return _c
+}
+
+func (_c *MockAuthService_DisableMFA_Call) Return(err error) *MockAuthService_DisableMFA_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAuthService_DisableMFA_Call) RunAndReturn(run func(ctx context.Context, input MFACodeInput) error) *MockAuthService_DisableMFA_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RegenerateRecoveryCodes provides a mock function for the type MockAuthService
+func (_mock *MockAuthService) RegenerateRecoveryCodes(ctx context.Context, input MFACodeInput) (*RecoveryCodesOutput, error) {
+	ret := _mock.Called(ctx, input)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RegenerateRecoveryCodes")
+	}
+
+	var r0 *RecoveryCodesOutput
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, MFACodeInput) (*RecoveryCodesOutput, error)); ok {
+		return returnFunc(ctx, input)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, MFACodeInput) *RecoveryCodesOutput); ok {
+		r0 = returnFunc(ctx, input)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*RecoveryCodesOutput)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, MFACodeInput) error); ok {
+		r1 = returnFunc(ctx, input)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAuthService_RegenerateRecoveryCodes_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RegenerateRecoveryCodes'
+type MockAuthService_RegenerateRecoveryCodes_Call struct {
+	*mock.Call
+}
+
+// RegenerateRecoveryCodes is a helper method to define mock.On call
+//   - ctx context.Context
+//   - input MFACodeInput
+func (_e *MockAuthService_Expecter) RegenerateRecoveryCodes(ctx interface{}, input interface{}) *MockAuthService_RegenerateRecoveryCodes_Call {
+	return &MockAuthService_RegenerateRecoveryCodes_Call{Call: _e.mock.On("RegenerateRecoveryCodes", ctx, input)}
+}
+
+func (_c *MockAuthService_RegenerateRecoveryCodes_Call) Run(run func(ctx context.Context, input MFACodeInput)) *MockAuthService_RegenerateRecoveryCodes_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 MFACodeInput
+		if args[1] != nil {
+			arg1 = args[1].(MFACodeInput)
+		}
+		run(arg0, arg1)
+	})
+	return _c
+}
+
+func (_c *MockAuthService_RegenerateRecoveryCodes_Call) Return(v *RecoveryCodesOutput, err error) *MockAuthService_RegenerateRecoveryCodes_Call {
+	_c.Call.Return(v, err)
+	return _c
+}
+
+func (_c *MockAuthService_RegenerateRecoveryCodes_Call) RunAndReturn(run func(ctx context.Context, input MFACodeInput) (*RecoveryCodesOutput, error)) *MockAuthService_RegenerateRecoveryCodes_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ResetMFA provides a mock function for the type MockAuthService
+func (_mock *MockAuthService) ResetMFA(ctx context.Context, userID uuid.UUID) error {
+	ret := _mock.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResetMFA")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+		r0 = returnFunc(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAuthService_ResetMFA_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ResetMFA'
+type MockAuthService_ResetMFA_Call struct {
+	*mock.Call
+}
+
+// ResetMFA is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uuid.UUID
+func (_e *MockAuthService_Expecter) ResetMFA(ctx interface{}, userID interface{}) *MockAuthService_ResetMFA_Call {
+	return &MockAuthService_ResetMFA_Call{Call: _e.mock.On("ResetMFA", ctx, userID)}
+}
+
+func (_c *MockAuthService_ResetMFA_Call) Run(run func(ctx context.Context, userID uuid.UUID)) *MockAuthService_ResetMFA_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		run(arg0, arg1)
+	})
+	return _c
+}
+
+func (_c *MockAuthService_ResetMFA_Call) Return(err error) *MockAuthService_ResetMFA_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAuthService_ResetMFA_Call) RunAndReturn(run func(ctx context.Context, userID uuid.UUID) error) *MockAuthService_ResetMFA_Call {
+	_c.Call.Return(run)
+	return _c
+}
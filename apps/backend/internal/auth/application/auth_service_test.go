@@ -1 +1,282 @@
 package application
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/auth/domain"
+	"github.com/indalyadav56/logify/apps/backend/pkg/jwt"
+)
+
+type memTokenRepo struct {
+	mu     sync.Mutex
+	tokens map[string]*domain.RefreshToken
+}
+
+func (r *memTokenRepo) Create(_ context.Context, t *domain.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cp := *t
+	r.tokens[t.TokenHash] = &cp
+	return nil
+}
+
+func (r *memTokenRepo) GetByToken(_ context.Context, hash string) (*domain.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tokens[hash]
+	if !ok {
+		return nil, domain.ErrTokenNotFound
+	}
+	cp := *t
+	return &cp, nil
+}
+
+func (r *memTokenRepo) Rotate(_ context.Context, current, next *domain.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := r.tokens[current.TokenHash]
+	if stored == nil || stored.Revoked {
+		return domain.ErrTokenAlreadyUsed
+	}
+	stored.Revoked = true
+	next.Parent = current.TokenHash
+	cp := *next
+	r.tokens[next.TokenHash] = &cp
+	return nil
+}
+
+func (r *memTokenRepo) RevokeBySession(_ context.Context, sessionID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, t := range r.tokens {
+		if t.SessionID == sessionID {
+			t.Revoked = true
+		}
+	}
+	return nil
+}
+
+func (r *memTokenRepo) RevokeByUser(_ context.Context, userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, t := range r.tokens {
+		if t.UserID == userID {
+			t.Revoked = true
+		}
+	}
+	return nil
+}
+
+type memSessionRepo struct {
+	mu       sync.Mutex
+	sessions map[uuid.UUID]*domain.Session
+}
+
+func (r *memSessionRepo) Create(_ context.Context, s *domain.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s.ID = uuid.New()
+	cp := *s
+	r.sessions[s.ID] = &cp
+	return nil
+}
+
+func (r *memSessionRepo) GetByID(_ context.Context, id uuid.UUID) (*domain.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.sessions[id]
+	if !ok {
+		return nil, domain.ErrSessionNotFound
+	}
+	cp := *s
+	return &cp, nil
+}
+
+func (r *memSessionRepo) ListActive(_ context.Context, userID uuid.UUID) ([]*domain.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []*domain.Session
+	for _, s := range r.sessions {
+		if s.UserID == userID && s.IsActive(time.Now()) {
+			cp := *s
+			out = append(out, &cp)
+		}
+	}
+	return out, nil
+}
+
+func (r *memSessionRepo) Touch(context.Context, uuid.UUID, domain.ClientInfo) error { return nil }
+
+func (r *memSessionRepo) Revoke(_ context.Context, userID, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.sessions[id]
+	if !ok || s.UserID != userID || s.RevokedAt != nil {
+		return domain.ErrSessionNotFound
+	}
+	now := time.Now()
+	s.RevokedAt = &now
+	return nil
+}
+
+func (r *memSessionRepo) RevokeAll(_ context.Context, userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for _, s := range r.sessions {
+		if s.UserID == userID && s.RevokedAt == nil {
+			s.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (r *memSessionRepo) SetTenant(_ context.Context, userID, id uuid.UUID, tenantID *uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.sessions[id]
+	if !ok || s.UserID != userID || s.RevokedAt != nil {
+		return domain.ErrSessionNotFound
+	}
+	s.TenantID = tenantID
+	return nil
+}
+
+type memMembership struct {
+	tenants map[uuid.UUID]bool
+}
+
+func (m *memMembership) IsTenantMember(_ context.Context, _, tenantID uuid.UUID) (bool, error) {
+	return m.tenants[tenantID], nil
+}
+
+func claim(t *testing.T, svc *authService, accessToken, name string) string {
+	t.Helper()
+	token, err := svc.tokens.ValidateToken(accessToken)
+	require.NoError(t, err)
+	claims, err := svc.tokens.GetClaims(token)
+	require.NoError(t, err)
+	return claims[name].(string)
+}
+
+func newTestAuthService() (*authService, *memTokenRepo, *memSessionRepo) {
+	tokens := &memTokenRepo{tokens: map[string]*domain.RefreshToken{}}
+	sessions := &memSessionRepo{sessions: map[uuid.UUID]*domain.Session{}}
+	signer := jwt.New(jwt.JWTConfig{
+		SecretKey:        []byte("test-secret"),
+		SigningAlgorithm: gojwt.SigningMethodHS256,
+		TokenDuration:    time.Minute,
+	})
+	svc := NewAuthService(zap.NewNop(), signer, tokens, sessions, nil, nil, nil, nil, nil, nil, time.Hour).(*authService)
+	return svc, tokens, sessions
+}
+
+func TestRefreshRotatesToken(t *testing.T) {
+	svc, _, _ := newTestAuthService()
+	ctx := context.Background()
+
+	first, err := svc.startSession(ctx, uuid.New(), ClientInput{})
+	require.NoError(t, err)
+
+	second, err := svc.Refresh(ctx, RefreshInput{RefreshToken: first.RefreshToken})
+	require.NoError(t, err)
+	assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+
+	_, err = svc.Refresh(ctx, RefreshInput{RefreshToken: second.RefreshToken})
+	assert.NoError(t, err)
+}
+
+func TestRefreshReuseRevokesSession(t *testing.T) {
+	svc, _, sessions := newTestAuthService()
+	ctx := context.Background()
+	userID := uuid.New()
+
+	first, err := svc.startSession(ctx, userID, ClientInput{})
+	require.NoError(t, err)
+	second, err := svc.Refresh(ctx, RefreshInput{RefreshToken: first.RefreshToken})
+	require.NoError(t, err)
+
+	// Replaying the rotated token must kill the whole family, including the
+	// token the legitimate client currently holds.
+	_, err = svc.Refresh(ctx, RefreshInput{RefreshToken: first.RefreshToken})
+	assert.ErrorIs(t, err, domain.ErrTokenAlreadyUsed)
+
+	_, err = svc.Refresh(ctx, RefreshInput{RefreshToken: second.RefreshToken})
+	assert.ErrorIs(t, err, domain.ErrTokenAlreadyUsed)
+
+	active, err := sessions.ListActive(ctx, userID)
+	require.NoError(t, err)
+	assert.Empty(t, active)
+}
+
+func TestRefreshExpiredToken(t *testing.T) {
+	svc, _, _ := newTestAuthService()
+	ctx := context.Background()
+
+	out, err := svc.startSession(ctx, uuid.New(), ClientInput{})
+	require.NoError(t, err)
+
+	svc.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	_, err = svc.Refresh(ctx, RefreshInput{RefreshToken: out.RefreshToken})
+	assert.ErrorIs(t, err, domain.ErrTokenExpired)
+}
+
+func TestLogoutRevokesSession(t *testing.T) {
+	svc, _, sessions := newTestAuthService()
+	ctx := context.Background()
+	userID := uuid.New()
+
+	out, err := svc.startSession(ctx, userID, ClientInput{})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.Logout(ctx, LogoutInput{RefreshToken: out.RefreshToken}))
+	require.NoError(t, svc.Logout(ctx, LogoutInput{RefreshToken: "unknown"}))
+
+	_, err = svc.Refresh(ctx, RefreshInput{RefreshToken: out.RefreshToken})
+	assert.Error(t, err)
+
+	active, err := sessions.ListActive(ctx, userID)
+	require.NoError(t, err)
+	assert.Empty(t, active)
+}
+
+func TestSwitchTenant(t *testing.T) {
+	svc, _, _ := newTestAuthService()
+	members := &memMembership{tenants: map[uuid.UUID]bool{}}
+	svc.members = members
+	ctx := context.Background()
+	userID, tenantID := uuid.New(), uuid.New()
+
+	out, err := svc.startSession(ctx, userID, ClientInput{})
+	require.NoError(t, err)
+	sessionID := uuid.MustParse(claim(t, svc, out.AccessToken, "sid"))
+
+	_, err = svc.SwitchTenant(ctx, SwitchTenantInput{UserID: userID, SessionID: sessionID, TenantID: tenantID})
+	assert.ErrorIs(t, err, domain.ErrNotTenantMember)
+
+	members.tenants[tenantID] = true
+	switched, err := svc.SwitchTenant(ctx, SwitchTenantInput{UserID: userID, SessionID: sessionID, TenantID: tenantID})
+	require.NoError(t, err)
+	assert.Empty(t, switched.RefreshToken)
+	assert.Equal(t, tenantID.String(), claim(t, svc, switched.AccessToken, "tenant_id"))
+
+	// The session remembers the tenant across refreshes until the
+	// membership is gone.
+	refreshed, err := svc.Refresh(ctx, RefreshInput{RefreshToken: out.RefreshToken})
+	require.NoError(t, err)
+	assert.Equal(t, tenantID.String(), claim(t, svc, refreshed.AccessToken, "tenant_id"))
+
+	members.tenants[tenantID] = false
+	refreshed, err = svc.Refresh(ctx, RefreshInput{RefreshToken: refreshed.RefreshToken})
+	require.NoError(t, err)
+	assert.Equal(t, userID.String(), claim(t, svc, refreshed.AccessToken, "tenant_id"))
+}
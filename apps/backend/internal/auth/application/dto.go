@@ -1,18 +1,58 @@
 package application
 
 import (
+	"net"
+	"time"
+
 	"github.com/google/uuid"
 )
 
 type RegisterInput struct {
-	FullName string `json:"full_name" validate:"required,min=2,max=100"`
-	Email    string `json:"email"     validate:"required,email"`
-	Password string `json:"password"  validate:"required,min=8,max=72"`
+	FullName string      `json:"full_name" validate:"required,min=2,max=100"`
+	Email    string      `json:"email"     validate:"required,email"`
+	Password string      `json:"password"  validate:"required,min=8,max=72"`
+	Client   ClientInput `json:"-"`
 }
 
 type LoginInput struct {
-	Email    string `json:"email"    validate:"required,email"`
-	Password string `json:"password" validate:"required"`
+	Email    string      `json:"email"    validate:"required,email"`
+	Password string      `json:"password" validate:"required"`
+	Client   ClientInput `json:"-"`
+}
+
+type RefreshInput struct {
+	RefreshToken string      `json:"refresh_token" validate:"required"`
+	Client       ClientInput `json:"-"`
+}
+
+type LogoutInput struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// SwitchTenantInput selects the tenant a session acts in. UserID and
+// SessionID come from the access token, never from the request body.
+type SwitchTenantInput struct {
+	UserID    uuid.UUID
+	SessionID uuid.UUID
+	TenantID  uuid.UUID
+}
+
+// SSOCallbackInput carries what the provider redirected back with.
+type SSOCallbackInput struct {
+	Code   string      `json:"code"  validate:"required"`
+	State  string      `json:"state" validate:"required"`
+	Client ClientInput `json:"-"`
+}
+
+type SSOAuthorizeOutput struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+// ClientInput carries the caller's network metadata; it is filled in by the
+// transport layer, never from the request body.
+type ClientInput struct {
+	IPAddress net.IP
+	UserAgent string
 }
 
 type CreateUserInput struct {
@@ -28,10 +68,19 @@ type UserOutput struct {
 	Role     string
 }
 
+// TokenOutput carries either tokens or, when a password login needs a
+// second factor, the MFA challenge to answer at /v1/auth/mfa/verify.
 type TokenOutput struct {
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
 	TokenType    string `json:"token_type"`
+
+	MFARequired           bool   `json:"mfa_required,omitempty"`
+	MFAToken              string `json:"mfa_token,omitempty"`
+	MFAEnrollmentRequired bool   `json:"mfa_enrollment_required,omitempty"`
+	// RecoveryCodes is set when the login enabled MFA; they are not shown
+	// again.
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
 }
 
 type AuthUserView struct {
@@ -40,3 +89,100 @@ type AuthUserView struct {
 	FullName string    `json:"full_name"`
 	Role     string    `json:"role"`
 }
+
+type SessionOutput struct {
+	ID        uuid.UUID  `json:"id"`
+	IPAddress string     `json:"ip_address"`
+	UserAgent string     `json:"user_agent"`
+	CreatedAt time.Time  `json:"created_at"`
+	LastUsed  *time.Time `json:"last_used_at,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Current   bool       `json:"current"`
+}
+
+// CreateAccessTokenInput describes a new access token. Scopes are
+// permission keys such as "log.read"; a token never exceeds the permissions
+// its owner holds, whatever its scopes. ExpiresInDays defaults to 90.
+type CreateAccessTokenInput struct {
+	Name          string   `json:"name"            validate:"required,max=100"`
+	Scopes        []string `json:"scopes"          validate:"required,min=1"`
+	ExpiresInDays int      `json:"expires_in_days" validate:"omitempty,min=1,max=365"`
+}
+
+type AccessTokenOutput struct {
+	ID               uuid.UUID  `json:"id"`
+	Name             string     `json:"name"`
+	Prefix           string     `json:"prefix"`
+	Scopes           []string   `json:"scopes"`
+	ServiceAccountID *uuid.UUID `json:"service_account_id,omitempty"`
+	CreatedBy        uuid.UUID  `json:"created_by"`
+	ExpiresAt        time.Time  `json:"expires_at"`
+	LastUsedAt       *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// CreatedAccessTokenOutput carries the plaintext token, which is only ever
+// returned here.
+type CreatedAccessTokenOutput struct {
+	AccessTokenOutput
+	Token string `json:"token"`
+}
+
+type CreateServiceAccountInput struct {
+	Name        string    `json:"name"        validate:"required,max=100"`
+	Description string    `json:"description" validate:"max=500"`
+	RoleID      uuid.UUID `json:"role_id"     validate:"required"`
+}
+
+// UpdateServiceAccountInput changes only the fields that are set.
+type UpdateServiceAccountInput struct {
+	Name        *string    `json:"name"        validate:"omitempty,min=1,max=100"`
+	Description *string    `json:"description" validate:"omitempty,max=500"`
+	RoleID      *uuid.UUID `json:"role_id"`
+	Disabled    *bool      `json:"disabled"`
+}
+
+type ServiceAccountOutput struct {
+	ID          uuid.UUID  `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	RoleID      uuid.UUID  `json:"role_id"`
+	Role        string     `json:"role"`
+	CreatedBy   uuid.UUID  `json:"created_by"`
+	DisabledAt  *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// VerifyMFAInput answers the challenge of a password login. Code is a TOTP
+// code or a recovery code.
+type VerifyMFAInput struct {
+	MFAToken string
+	Code     string
+	Client   ClientInput
+}
+
+// MFACodeInput confirms an MFA change of UserID, who comes from the access
+// token, with a current code.
+type MFACodeInput struct {
+	UserID uuid.UUID
+	Code   string
+}
+
+// MFAEnrollmentOutput is what an authenticator app needs; ProvisioningURI is
+// usually rendered as a QR code.
+type MFAEnrollmentOutput struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type RecoveryCodesOutput struct {
+	Codes []string `json:"recovery_codes"`
+}
+
+type MFAStatusOutput struct {
+	Enabled                bool       `json:"enabled"`
+	EnabledAt              *time.Time `json:"enabled_at,omitempty"`
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining"`
+}
@@ -0,0 +1,411 @@
+package application
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	auditDomain "github.com/indalyadav56/logify/apps/backend/internal/audit/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/auth/domain"
+	"github.com/indalyadav56/logify/apps/backend/pkg/totp"
+)
+
+const (
+	// defaultMFAChallengeTTL bounds the time between password and code.
+	defaultMFAChallengeTTL = 5 * time.Minute
+
+	// totpSkew accepts codes one step either side of now for clock drift.
+	totpSkew = 1
+
+	recoveryCodeCount = 10
+	// recoveryAlphabet is lower-case base32; 32 symbols keep the byte to
+	// symbol mapping unbiased.
+	recoveryAlphabet = "abcdefghijklmnopqrstuvwxyz234567"
+)
+
+// SecretSealer encrypts TOTP secrets at rest. It is implemented by
+// pkg/secretbox.Box.
+type SecretSealer interface {
+	Seal(plain []byte) ([]byte, error)
+	Open(sealed []byte) ([]byte, error)
+}
+
+// MFA enables TOTP second factors and login lockout on the auth service.
+// Logins through SSO leave the second factor to the identity provider.
+type MFA struct {
+	Enrollments domain.MFARepository
+	Challenges  domain.MFAChallengeStore
+	// Attempts throttles both password and second factor guesses.
+	Attempts domain.AttemptLimiter
+	Secrets  SecretSealer
+	// Issuer names the account in authenticator apps.
+	Issuer       string
+	ChallengeTTL time.Duration
+}
+
+func loginAttemptKey(email string) string {
+	return "login:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func mfaAttemptKey(userID uuid.UUID) string {
+	return "mfa:" + userID.String()
+}
+
+// mfaChallenge returns the second step a password login of the user needs,
+// or nil when it needs none: the user has no second factor and no tenant of
+// theirs requires one.
+func (s *authService) mfaChallenge(ctx context.Context, userID uuid.UUID) (*TokenOutput, error) {
+	if s.mfa == nil {
+		return nil, nil
+	}
+	enrolled := false
+	e, err := s.mfa.Enrollments.Get(ctx, userID)
+	switch {
+	case err == nil:
+		enrolled = e.Enabled()
+	case !errors.Is(err, domain.ErrMFANotEnrolled):
+		return nil, err
+	}
+	if !enrolled {
+		required, err := s.mfa.Enrollments.RequiresMFA(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !required {
+			return nil, nil
+		}
+	}
+
+	token, err := s.generateRandomToken()
+	if err != nil {
+		return nil, err
+	}
+	ttl := s.mfa.ChallengeTTL
+	if ttl <= 0 {
+		ttl = defaultMFAChallengeTTL
+	}
+	challenge := &domain.MFAChallenge{UserID: userID, Enroll: !enrolled, CreatedAt: s.now()}
+	if err := s.mfa.Challenges.Save(ctx, token, challenge, ttl); err != nil {
+		return nil, err
+	}
+	return &TokenOutput{MFARequired: true, MFAToken: token, MFAEnrollmentRequired: !enrolled}, nil
+}
+
+func (s *authService) VerifyMFA(ctx context.Context, input VerifyMFAInput) (*TokenOutput, error) {
+	if s.mfa == nil {
+		return nil, domain.ErrMFADisabled
+	}
+	challenge, err := s.mfa.Challenges.Get(ctx, input.MFAToken)
+	if err != nil {
+		return nil, err
+	}
+	userID := challenge.UserID
+
+	var recovery []string
+	if challenge.Enroll {
+		recovery, err = s.enableMFA(ctx, userID, input.Code)
+	} else {
+		err = s.checkSecondFactor(ctx, userID, input.Code)
+	}
+	if err != nil {
+		if errors.Is(err, domain.ErrAccountLocked) {
+			// Make the user start over with their password.
+			_ = s.mfa.Challenges.Delete(ctx, input.MFAToken)
+		}
+		return nil, err
+	}
+
+	if err := s.mfa.Challenges.Delete(ctx, input.MFAToken); err != nil {
+		return nil, err
+	}
+	output, err := s.startSession(ctx, userID, input.Client)
+	if err != nil {
+		return nil, err
+	}
+	output.RecoveryCodes = recovery
+
+	s.auditUser(ctx, auditDomain.ActionLogin, auditDomain.OutcomeSuccess, userID, map[string]any{"method": loginMFA})
+	s.logger.Info("user logged in", zap.String("user_id", userID.String()), zap.Bool("mfa", true))
+	return output, nil
+}
+
+func (s *authService) StartChallengeEnrollment(ctx context.Context, mfaToken string) (*MFAEnrollmentOutput, error) {
+	if s.mfa == nil {
+		return nil, domain.ErrMFADisabled
+	}
+	challenge, err := s.mfa.Challenges.Get(ctx, mfaToken)
+	if err != nil {
+		return nil, err
+	}
+	if !challenge.Enroll {
+		return nil, domain.ErrMFAAlreadyEnabled
+	}
+	return s.StartMFAEnrollment(ctx, challenge.UserID)
+}
+
+func (s *authService) MFAStatus(ctx context.Context, userID uuid.UUID) (*MFAStatusOutput, error) {
+	if s.mfa == nil {
+		return nil, domain.ErrMFADisabled
+	}
+	out := &MFAStatusOutput{}
+	e, err := s.mfa.Enrollments.Get(ctx, userID)
+	if errors.Is(err, domain.ErrMFANotEnrolled) {
+		return out, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	out.Enabled = e.Enabled()
+	out.EnabledAt = e.EnabledAt
+	if out.Enabled {
+		if out.RecoveryCodesRemaining, err = s.mfa.Enrollments.RemainingRecoveryCodes(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func (s *authService) StartMFAEnrollment(ctx context.Context, userID uuid.UUID) (*MFAEnrollmentOutput, error) {
+	if s.mfa == nil {
+		return nil, domain.ErrMFADisabled
+	}
+	user, err := s.userSrv.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := s.mfa.Secrets.Seal([]byte(secret))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfa.Enrollments.SavePending(ctx, userID, sealed); err != nil {
+		return nil, err
+	}
+	return &MFAEnrollmentOutput{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(s.mfa.Issuer, user.Email, secret),
+	}, nil
+}
+
+func (s *authService) EnableMFA(ctx context.Context, input MFACodeInput) (*RecoveryCodesOutput, error) {
+	if s.mfa == nil {
+		return nil, domain.ErrMFADisabled
+	}
+	codes, err := s.enableMFA(ctx, input.UserID, input.Code)
+	if err != nil {
+		return nil, err
+	}
+	return &RecoveryCodesOutput{Codes: codes}, nil
+}
+
+func (s *authService) DisableMFA(ctx context.Context, input MFACodeInput) error {
+	if s.mfa == nil {
+		return domain.ErrMFADisabled
+	}
+	if err := s.checkSecondFactor(ctx, input.UserID, input.Code); err != nil {
+		return err
+	}
+	if err := s.mfa.Enrollments.Delete(ctx, input.UserID); err != nil {
+		return err
+	}
+	s.auditUser(ctx, auditDomain.ActionMFADisabled, auditDomain.OutcomeSuccess, input.UserID, nil)
+	s.logger.Info("mfa disabled", zap.String("user_id", input.UserID.String()))
+	return nil
+}
+
+func (s *authService) RegenerateRecoveryCodes(ctx context.Context, input MFACodeInput) (*RecoveryCodesOutput, error) {
+	if s.mfa == nil {
+		return nil, domain.ErrMFADisabled
+	}
+	if err := s.checkSecondFactor(ctx, input.UserID, input.Code); err != nil {
+		return nil, err
+	}
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfa.Enrollments.ReplaceRecoveryCodes(ctx, input.UserID, hashes); err != nil {
+		return nil, err
+	}
+	s.logger.Info("mfa recovery codes regenerated", zap.String("user_id", input.UserID.String()))
+	return &RecoveryCodesOutput{Codes: codes}, nil
+}
+
+func (s *authService) ResetMFA(ctx context.Context, userID uuid.UUID) error {
+	if s.mfa == nil {
+		return domain.ErrMFADisabled
+	}
+	if _, err := s.userSrv.GetUser(ctx, userID); err != nil {
+		return err
+	}
+	if err := s.mfa.Enrollments.Delete(ctx, userID); err != nil {
+		return err
+	}
+	if err := s.mfa.Attempts.Reset(ctx, mfaAttemptKey(userID)); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, auditDomain.Event{
+		Action:     auditDomain.ActionMFAReset,
+		TargetType: "user",
+		TargetID:   userID.String(),
+	})
+	s.logger.Warn("mfa reset by administrator", zap.String("user_id", userID.String()))
+	return nil
+}
+
+// enableMFA turns a pending enrolment on with its first code and returns
+// fresh recovery codes.
+func (s *authService) enableMFA(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	key := mfaAttemptKey(userID)
+	if err := s.checkLockout(ctx, key); err != nil {
+		return nil, err
+	}
+	e, err := s.mfa.Enrollments.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if e.Enabled() {
+		return nil, domain.ErrMFAAlreadyEnabled
+	}
+	secret, err := s.mfa.Secrets.Open(e.Secret)
+	if err != nil {
+		return nil, err
+	}
+	step, ok := totp.Validate(string(secret), normalizeCode(code), s.now(), totpSkew)
+	if !ok {
+		return nil, s.secondFactorFailed(ctx, userID)
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfa.Enrollments.Enable(ctx, userID, step, hashes); err != nil {
+		return nil, err
+	}
+	s.resetAttempts(ctx, key)
+	s.auditUser(ctx, auditDomain.ActionMFAEnabled, auditDomain.OutcomeSuccess, userID, nil)
+	s.logger.Info("mfa enabled", zap.String("user_id", userID.String()))
+	return codes, nil
+}
+
+// checkSecondFactor accepts a current TOTP code or an unused recovery code
+// of an enabled enrolment. Each is good once.
+func (s *authService) checkSecondFactor(ctx context.Context, userID uuid.UUID, code string) error {
+	key := mfaAttemptKey(userID)
+	if err := s.checkLockout(ctx, key); err != nil {
+		return err
+	}
+	e, err := s.mfa.Enrollments.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !e.Enabled() {
+		return domain.ErrMFANotEnrolled
+	}
+
+	code = normalizeCode(code)
+	var ok bool
+	if len(code) == totp.Digits {
+		secret, err := s.mfa.Secrets.Open(e.Secret)
+		if err != nil {
+			return err
+		}
+		if step, valid := totp.Validate(string(secret), code, s.now(), totpSkew); valid {
+			if ok, err = s.mfa.Enrollments.UseStep(ctx, userID, step); err != nil {
+				return err
+			}
+		}
+	} else {
+		if ok, err = s.mfa.Enrollments.UseRecoveryCode(ctx, userID, domain.HashRecoveryCode(code)); err != nil {
+			return err
+		}
+		if ok {
+			s.auditUser(ctx, auditDomain.ActionRecoveryUsed, auditDomain.OutcomeSuccess, userID, nil)
+			s.logger.Info("mfa recovery code used", zap.String("user_id", userID.String()))
+		}
+	}
+	if !ok {
+		return s.secondFactorFailed(ctx, userID)
+	}
+	s.resetAttempts(ctx, key)
+	return nil
+}
+
+// checkLockout returns ErrAccountLocked while key is locked out. Without
+// MFA configured there is no limiter and nothing is ever locked.
+func (s *authService) checkLockout(ctx context.Context, key string) error {
+	if s.mfa == nil || s.mfa.Attempts == nil {
+		return nil
+	}
+	remaining, err := s.mfa.Attempts.Locked(ctx, key)
+	if err != nil {
+		return err
+	}
+	if remaining > 0 {
+		return domain.ErrAccountLocked
+	}
+	return nil
+}
+
+// recordFailure counts a failed attempt against key and returns failure, or
+// ErrAccountLocked when the attempt locked the key.
+func (s *authService) recordFailure(ctx context.Context, key string, failure error) error {
+	if s.mfa == nil || s.mfa.Attempts == nil {
+		return failure
+	}
+	locked, err := s.mfa.Attempts.Fail(ctx, key)
+	if err != nil {
+		s.logger.Error("record failed attempt", zap.String("key", key), zap.Error(err))
+		return failure
+	}
+	if locked {
+		s.logger.Warn("locked out after repeated failures", zap.String("key", key))
+		return domain.ErrAccountLocked
+	}
+	return failure
+}
+
+func (s *authService) resetAttempts(ctx context.Context, key string) {
+	if s.mfa == nil || s.mfa.Attempts == nil {
+		return
+	}
+	if err := s.mfa.Attempts.Reset(ctx, key); err != nil {
+		s.logger.Warn("reset failed attempts", zap.String("key", key), zap.Error(err))
+	}
+}
+
+func normalizeCode(code string) string {
+	return strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+}
+
+// newRecoveryCodes returns plaintext codes formatted as xxxxx-xxxxx and their
+// hashes.
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	buf := make([]byte, 10)
+	for i := range codes {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		var b strings.Builder
+		for j, c := range buf {
+			if j == 5 {
+				b.WriteByte('-')
+			}
+			b.WriteByte(recoveryAlphabet[c&31])
+		}
+		codes[i] = b.String()
+		hashes[i] = domain.HashRecoveryCode(codes[i])
+	}
+	return codes, hashes, nil
+}
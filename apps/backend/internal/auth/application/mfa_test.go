@@ -0,0 +1,364 @@
+package application
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	auditDomain "github.com/indalyadav56/logify/apps/backend/internal/audit/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/auth/domain"
+	userApp "github.com/indalyadav56/logify/apps/backend/internal/user/application"
+	"github.com/indalyadav56/logify/apps/backend/pkg/secretbox"
+	"github.com/indalyadav56/logify/apps/backend/pkg/totp"
+)
+
+type memMFA struct {
+	mu       sync.Mutex
+	enrolled map[uuid.UUID]*domain.MFAEnrollment
+	recovery map[uuid.UUID]map[string]bool // hash -> used
+	required map[uuid.UUID]bool
+}
+
+func (r *memMFA) Get(_ context.Context, userID uuid.UUID) (*domain.MFAEnrollment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.enrolled[userID]
+	if !ok {
+		return nil, domain.ErrMFANotEnrolled
+	}
+	cp := *e
+	return &cp, nil
+}
+
+func (r *memMFA) SavePending(_ context.Context, userID uuid.UUID, secret []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if e, ok := r.enrolled[userID]; ok && e.Enabled() {
+		return domain.ErrMFAAlreadyEnabled
+	}
+	r.enrolled[userID] = &domain.MFAEnrollment{UserID: userID, Secret: secret}
+	return nil
+}
+
+func (r *memMFA) Enable(_ context.Context, userID uuid.UUID, step int64, hashes []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	e := r.enrolled[userID]
+	e.EnabledAt, e.LastUsedStep = &now, &step
+	r.replace(userID, hashes)
+	return nil
+}
+
+func (r *memMFA) UseStep(_ context.Context, userID uuid.UUID, step int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e := r.enrolled[userID]
+	if e.LastUsedStep != nil && *e.LastUsedStep >= step {
+		return false, nil
+	}
+	e.LastUsedStep = &step
+	return true, nil
+}
+
+func (r *memMFA) UseRecoveryCode(_ context.Context, userID uuid.UUID, hash string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	used, ok := r.recovery[userID][hash]
+	if !ok || used {
+		return false, nil
+	}
+	r.recovery[userID][hash] = true
+	return true, nil
+}
+
+func (r *memMFA) ReplaceRecoveryCodes(_ context.Context, userID uuid.UUID, hashes []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.replace(userID, hashes)
+	return nil
+}
+
+func (r *memMFA) replace(userID uuid.UUID, hashes []string) {
+	r.recovery[userID] = map[string]bool{}
+	for _, h := range hashes {
+		r.recovery[userID][h] = false
+	}
+}
+
+func (r *memMFA) RemainingRecoveryCodes(_ context.Context, userID uuid.UUID) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, used := range r.recovery[userID] {
+		if !used {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *memMFA) Delete(_ context.Context, userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.enrolled, userID)
+	delete(r.recovery, userID)
+	return nil
+}
+
+func (r *memMFA) RequiresMFA(_ context.Context, userID uuid.UUID) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.required[userID], nil
+}
+
+type memChallenges struct {
+	mu         sync.Mutex
+	challenges map[string]*domain.MFAChallenge
+}
+
+func (s *memChallenges) Save(_ context.Context, token string, c *domain.MFAChallenge, _ time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.challenges[token] = c
+	return nil
+}
+
+func (s *memChallenges) Get(_ context.Context, token string) (*domain.MFAChallenge, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.challenges[token]
+	if !ok {
+		return nil, domain.ErrInvalidMFAChallenge
+	}
+	return c, nil
+}
+
+func (s *memChallenges) Delete(_ context.Context, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.challenges, token)
+	return nil
+}
+
+// memAttempts locks a key after max failures until Reset.
+type memAttempts struct {
+	mu       sync.Mutex
+	max      int
+	failures map[string]int
+}
+
+func (l *memAttempts) Locked(_ context.Context, key string) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.failures[key] >= l.max {
+		return time.Minute, nil
+	}
+	return 0, nil
+}
+
+func (l *memAttempts) Fail(_ context.Context, key string) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.failures[key]++
+	return l.failures[key] >= l.max, nil
+}
+
+func (l *memAttempts) Reset(_ context.Context, key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.failures, key)
+	return nil
+}
+
+type mfaFixture struct {
+	svc   *authService
+	repo  *memMFA
+	users *memUsers
+	audit *memRecorder
+	clock time.Time
+}
+
+func newMFAFixture(t *testing.T) *mfaFixture {
+	t.Helper()
+	box, err := secretbox.New("test-key")
+	require.NoError(t, err)
+
+	svc, _, _ := newTestAuthService()
+	f := &mfaFixture{
+		svc:   svc,
+		repo:  &memMFA{enrolled: map[uuid.UUID]*domain.MFAEnrollment{}, recovery: map[uuid.UUID]map[string]bool{}, required: map[uuid.UUID]bool{}},
+		users: &memUsers{byEmail: map[string]*userApp.UserOutput{}},
+		audit: &memRecorder{},
+		clock: time.Date(2026, 7, 2, 9, 0, 0, 0, time.UTC),
+	}
+	svc.userSrv = f.users
+	svc.tenants = noopTenants{}
+	svc.now = func() time.Time { return f.clock }
+	svc.audit = f.audit
+	svc.mfa = &MFA{
+		Enrollments: f.repo,
+		Challenges:  &memChallenges{challenges: map[string]*domain.MFAChallenge{}},
+		Attempts:    &memAttempts{max: 3, failures: map[string]int{}},
+		Secrets:     box,
+		Issuer:      "Logify",
+	}
+	return f
+}
+
+func (f *mfaFixture) user(t *testing.T, email string) uuid.UUID {
+	t.Helper()
+	out, err := f.users.ProvisionUser(context.Background(), userApp.ProvisionUserInput{Email: email, FullName: "Test"})
+	require.NoError(t, err)
+	return out.ID
+}
+
+func (f *mfaFixture) code(t *testing.T, secret string) string {
+	t.Helper()
+	code, err := totp.Code(secret, totp.Step(f.clock))
+	require.NoError(t, err)
+	return code
+}
+
+// enrol turns MFA on for the user and returns the secret and recovery codes.
+func (f *mfaFixture) enrol(t *testing.T, userID uuid.UUID) (string, []string) {
+	t.Helper()
+	ctx := context.Background()
+	enrolment, err := f.svc.StartMFAEnrollment(ctx, userID)
+	require.NoError(t, err)
+	assert.Contains(t, enrolment.ProvisioningURI, "otpauth://totp/")
+
+	codes, err := f.svc.EnableMFA(ctx, MFACodeInput{UserID: userID, Code: f.code(t, enrolment.Secret)})
+	require.NoError(t, err)
+	require.Len(t, codes.Codes, recoveryCodeCount)
+	return enrolment.Secret, codes.Codes
+}
+
+func TestMFALoginRequiresSecondStep(t *testing.T) {
+	f := newMFAFixture(t)
+	ctx := context.Background()
+	userID := f.user(t, "ann@example.com")
+	login := LoginInput{Email: "ann@example.com", Password: "secret"}
+
+	tokens, err := f.svc.Login(ctx, login)
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken, "no second factor, no challenge")
+
+	secret, _ := f.enrol(t, userID)
+	f.clock = f.clock.Add(totp.Period)
+
+	challenge, err := f.svc.Login(ctx, login)
+	require.NoError(t, err)
+	assert.True(t, challenge.MFARequired)
+	assert.Empty(t, challenge.AccessToken)
+	assert.Empty(t, challenge.RefreshToken)
+
+	_, err = f.svc.VerifyMFA(ctx, VerifyMFAInput{MFAToken: challenge.MFAToken, Code: "000000"})
+	assert.ErrorIs(t, err, domain.ErrInvalidMFACode)
+
+	tokens, err = f.svc.VerifyMFA(ctx, VerifyMFAInput{MFAToken: challenge.MFAToken, Code: f.code(t, secret)})
+	require.NoError(t, err)
+	assert.Equal(t, userID.String(), claim(t, f.svc, tokens.AccessToken, "sub"))
+
+	// The challenge is spent, and a code is good only once even with a new
+	// challenge.
+	_, err = f.svc.VerifyMFA(ctx, VerifyMFAInput{MFAToken: challenge.MFAToken, Code: f.code(t, secret)})
+	assert.ErrorIs(t, err, domain.ErrInvalidMFAChallenge)
+	challenge, err = f.svc.Login(ctx, login)
+	require.NoError(t, err)
+	_, err = f.svc.VerifyMFA(ctx, VerifyMFAInput{MFAToken: challenge.MFAToken, Code: f.code(t, secret)})
+	assert.ErrorIs(t, err, domain.ErrInvalidMFACode)
+}
+
+func TestMFARecoveryCodesAreSingleUse(t *testing.T) {
+	f := newMFAFixture(t)
+	ctx := context.Background()
+	userID := f.user(t, "ben@example.com")
+	_, codes := f.enrol(t, userID)
+
+	require.NoError(t, f.svc.checkSecondFactor(ctx, userID, codes[0]))
+	assert.ErrorIs(t, f.svc.checkSecondFactor(ctx, userID, codes[0]), domain.ErrInvalidMFACode)
+
+	status, err := f.svc.MFAStatus(ctx, userID)
+	require.NoError(t, err)
+	assert.True(t, status.Enabled)
+	assert.Equal(t, recoveryCodeCount-1, status.RecoveryCodesRemaining)
+}
+
+func TestMFATenantRequirementEnrolsAtLogin(t *testing.T) {
+	f := newMFAFixture(t)
+	ctx := context.Background()
+	userID := f.user(t, "cat@example.com")
+	f.repo.required[userID] = true
+
+	challenge, err := f.svc.Login(ctx, LoginInput{Email: "cat@example.com", Password: "secret"})
+	require.NoError(t, err)
+	assert.True(t, challenge.MFAEnrollmentRequired)
+
+	enrolment, err := f.svc.StartChallengeEnrollment(ctx, challenge.MFAToken)
+	require.NoError(t, err)
+	tokens, err := f.svc.VerifyMFA(ctx, VerifyMFAInput{MFAToken: challenge.MFAToken, Code: f.code(t, enrolment.Secret)})
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+	assert.Len(t, tokens.RecoveryCodes, recoveryCodeCount)
+}
+
+func TestMFALockoutAndAdminReset(t *testing.T) {
+	f := newMFAFixture(t)
+	ctx := context.Background()
+	userID := f.user(t, "dan@example.com")
+	secret, _ := f.enrol(t, userID)
+	f.clock = f.clock.Add(totp.Period)
+
+	for i := 0; i < 2; i++ {
+		assert.ErrorIs(t, f.svc.checkSecondFactor(ctx, userID, "000000"), domain.ErrInvalidMFACode)
+	}
+	assert.ErrorIs(t, f.svc.checkSecondFactor(ctx, userID, "000000"), domain.ErrAccountLocked)
+	// Locked out even with the right code.
+	assert.ErrorIs(t, f.svc.checkSecondFactor(ctx, userID, f.code(t, secret)), domain.ErrAccountLocked)
+
+	require.NoError(t, f.svc.ResetMFA(ctx, userID))
+	tokens, err := f.svc.Login(ctx, LoginInput{Email: "dan@example.com", Password: "secret"})
+	require.NoError(t, err)
+	assert.False(t, tokens.MFARequired)
+
+	// Wrong passwords lock the email out too.
+	for i := 0; i < 3; i++ {
+		_, err = f.svc.Login(ctx, LoginInput{Email: "dan@example.com", Password: "wrong"})
+	}
+	assert.ErrorIs(t, err, domain.ErrAccountLocked)
+	_, err = f.svc.Login(ctx, LoginInput{Email: "dan@example.com", Password: "secret"})
+	assert.ErrorIs(t, err, domain.ErrAccountLocked)
+
+	assert.Equal(t, []string{
+		auditDomain.ActionMFAEnabled,
+		auditDomain.ActionMFAFailed, auditDomain.ActionMFAFailed,
+		auditDomain.ActionMFAFailed, auditDomain.ActionLockedOut,
+		auditDomain.ActionMFAReset,
+		auditDomain.ActionLogin,
+		auditDomain.ActionLoginFailed, auditDomain.ActionLoginFailed,
+		auditDomain.ActionLoginFailed, auditDomain.ActionLockedOut,
+		auditDomain.ActionLoginFailed,
+	}, f.audit.actions())
+}
+
+type memRecorder struct {
+	events []auditDomain.Event
+}
+
+func (r *memRecorder) Record(_ context.Context, e auditDomain.Event) {
+	r.events = append(r.events, e)
+}
+
+func (r *memRecorder) actions() []string {
+	actions := make([]string, len(r.events))
+	for i, e := range r.events {
+		actions[i] = e.Action
+	}
+	return actions
+}
@@ -0,0 +1,220 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	auditDomain "github.com/indalyadav56/logify/apps/backend/internal/audit/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/auth/domain"
+	userApp "github.com/indalyadav56/logify/apps/backend/internal/user/application"
+	userDomain "github.com/indalyadav56/logify/apps/backend/internal/user/domain"
+	"github.com/indalyadav56/logify/apps/backend/pkg/oidc"
+)
+
+// defaultSSOStateTTL bounds how long a user may spend at the provider.
+const defaultSSOStateTTL = 10 * time.Minute
+
+// OIDCProvider is the relying party side of the SSO flow; it is implemented
+// by pkg/oidc.Provider.
+type OIDCProvider interface {
+	Issuer() string
+	AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error)
+	Exchange(ctx context.Context, code, verifier string) (*oidc.Token, error)
+	Verify(ctx context.Context, rawIDToken, nonce string) (*oidc.IDToken, error)
+}
+
+// PermissionInvalidator drops cached permissions after group memberships
+// change. It is implemented by role/application.PermissionResolver.
+type PermissionInvalidator interface {
+	InvalidateUser(userID uuid.UUID)
+}
+
+// GroupMapping grants Role on a project of Tenant to members of an identity
+// provider group. A zero Project means every project of the tenant.
+type GroupMapping struct {
+	Group   string
+	Tenant  uuid.UUID
+	Project uuid.UUID
+	Role    string
+}
+
+// SSO enables OIDC login on the auth service.
+type SSO struct {
+	Provider    OIDCProvider
+	States      domain.SSOStateStore
+	Identities  domain.IdentityRepository
+	Permissions PermissionInvalidator
+	// GroupsClaim names the ID token claim listing the user's groups.
+	GroupsClaim string
+	Mappings    []GroupMapping
+	StateTTL    time.Duration
+}
+
+func (s *authService) SSOAuthorize(ctx context.Context) (*SSOAuthorizeOutput, error) {
+	if s.sso == nil {
+		return nil, domain.ErrSSODisabled
+	}
+
+	var values [3]string
+	for i := range values {
+		v, err := oidc.RandomString(32)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	state, nonce, verifier := values[0], values[1], values[2]
+
+	authURL, err := s.sso.Provider.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		s.logger.Error("sso: build authorization url failed", zap.Error(err))
+		return nil, fmt.Errorf("%w: %v", domain.ErrSSOFailed, err)
+	}
+
+	ttl := s.sso.StateTTL
+	if ttl <= 0 {
+		ttl = defaultSSOStateTTL
+	}
+	pending := &domain.SSOState{Nonce: nonce, Verifier: verifier, CreatedAt: s.now()}
+	if err := s.sso.States.Save(ctx, state, pending, ttl); err != nil {
+		return nil, err
+	}
+	return &SSOAuthorizeOutput{AuthorizationURL: authURL}, nil
+}
+
+func (s *authService) SSOCallback(ctx context.Context, input SSOCallbackInput) (*TokenOutput, error) {
+	if s.sso == nil {
+		return nil, domain.ErrSSODisabled
+	}
+
+	pending, err := s.sso.States.Take(ctx, input.State)
+	if err != nil {
+		return nil, err
+	}
+	tok, err := s.sso.Provider.Exchange(ctx, input.Code, pending.Verifier)
+	if err != nil {
+		s.logger.Warn("sso: code exchange failed", zap.Error(err))
+		return nil, fmt.Errorf("%w: %v", domain.ErrSSOFailed, err)
+	}
+	claims, err := s.sso.Provider.Verify(ctx, tok.IDToken, pending.Nonce)
+	if err != nil {
+		s.logger.Warn("sso: id token rejected", zap.Error(err))
+		return nil, fmt.Errorf("%w: %v", domain.ErrSSOFailed, err)
+	}
+
+	user, err := s.ssoUser(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, domain.ErrAccountDisabled
+	}
+
+	if err := s.tenants.EnsurePersonalTenant(ctx, user.ID, user.FullName); err != nil {
+		return nil, err
+	}
+	groups := claims.Strings(s.sso.GroupsClaim)
+	if err := s.sso.Identities.SyncGroupGrants(ctx, user.ID, s.groupGrants(groups)); err != nil {
+		s.logger.Error("sso: sync group memberships failed", zap.String("user_id", user.ID.String()), zap.Error(err))
+		return nil, err
+	}
+	if s.sso.Permissions != nil {
+		s.sso.Permissions.InvalidateUser(user.ID)
+	}
+
+	output, err := s.startSession(ctx, user.ID, input.Client)
+	if err != nil {
+		return nil, err
+	}
+
+	s.auditUser(ctx, auditDomain.ActionLogin, auditDomain.OutcomeSuccess, user.ID, map[string]any{"method": loginSSO, "issuer": claims.Issuer})
+	s.logger.Info("user logged in with sso",
+		zap.String("user_id", user.ID.String()),
+		zap.String("issuer", claims.Issuer),
+		zap.Strings("groups", groups),
+	)
+	return output, nil
+}
+
+// ssoUser finds the account linked to the provider subject. An unlinked
+// subject is linked to the account with its verified email, which is
+// provisioned on first login.
+func (s *authService) ssoUser(ctx context.Context, claims *oidc.IDToken) (*userApp.UserOutput, error) {
+	now := s.now()
+	identity := &domain.Identity{
+		Issuer:      claims.Issuer,
+		Subject:     claims.Subject,
+		Email:       claims.Email,
+		LastLoginAt: &now,
+	}
+
+	linked, err := s.sso.Identities.Get(ctx, claims.Issuer, claims.Subject)
+	switch {
+	case err == nil:
+		identity.UserID = linked.UserID
+	case errors.Is(err, domain.ErrCredentialNotFound):
+		if claims.Email == "" || !claims.EmailVerified {
+			return nil, domain.ErrSSOEmailUnverified
+		}
+		user, err := s.provisionSSOUser(ctx, claims)
+		if err != nil {
+			return nil, err
+		}
+		identity.UserID = user.ID
+	default:
+		return nil, err
+	}
+
+	userID, err := s.sso.Identities.Link(ctx, identity)
+	if err != nil {
+		return nil, err
+	}
+	return s.userSrv.GetUser(ctx, userID)
+}
+
+func (s *authService) provisionSSOUser(ctx context.Context, claims *oidc.IDToken) (*userApp.UserOutput, error) {
+	existing, err := s.userSrv.GetUserByEmail(ctx, claims.Email)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, userDomain.ErrUserNotFound) {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(claims.Name)
+	if name == "" {
+		name, _, _ = strings.Cut(claims.Email, "@")
+	}
+	user, err := s.userSrv.ProvisionUser(ctx, userApp.ProvisionUserInput{Email: claims.Email, FullName: name})
+	if errors.Is(err, userDomain.ErrUserAlreadyExists) {
+		// Provisioned by a concurrent login.
+		return s.userSrv.GetUserByEmail(ctx, claims.Email)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Info("sso: user provisioned", zap.String("user_id", user.ID.String()), zap.String("issuer", claims.Issuer))
+	return user, nil
+}
+
+// groupGrants returns the grants of the configured mappings matching groups,
+// in mapping order.
+func (s *authService) groupGrants(groups []string) []domain.GroupGrant {
+	member := make(map[string]bool, len(groups))
+	for _, g := range groups {
+		member[g] = true
+	}
+	var grants []domain.GroupGrant
+	for _, m := range s.sso.Mappings {
+		if member[m.Group] {
+			grants = append(grants, domain.GroupGrant{TenantID: m.Tenant, ProjectID: m.Project, RoleKey: m.Role})
+		}
+	}
+	return grants
+}
@@ -0,0 +1,244 @@
+package application
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/auth/domain"
+	userApp "github.com/indalyadav56/logify/apps/backend/internal/user/application"
+	userDomain "github.com/indalyadav56/logify/apps/backend/internal/user/domain"
+	"github.com/indalyadav56/logify/apps/backend/pkg/oidc"
+	"github.com/indalyadav56/logify/apps/backend/pkg/oidc/oidctest"
+)
+
+type memStates struct {
+	mu     sync.Mutex
+	states map[string]*domain.SSOState
+}
+
+func (s *memStates) Save(_ context.Context, state string, v *domain.SSOState, _ time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states[state] = v
+	return nil
+}
+
+func (s *memStates) Take(_ context.Context, state string) (*domain.SSOState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.states[state]
+	if !ok {
+		return nil, domain.ErrInvalidSSOState
+	}
+	delete(s.states, state)
+	return v, nil
+}
+
+type memIdentities struct {
+	mu         sync.Mutex
+	links      map[string]uuid.UUID
+	grants     map[uuid.UUID][]domain.GroupGrant
+	enforceFor map[uuid.UUID]bool
+}
+
+func (r *memIdentities) Get(_ context.Context, issuer, subject string) (*domain.Identity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	userID, ok := r.links[issuer+"|"+subject]
+	if !ok {
+		return nil, domain.ErrCredentialNotFound
+	}
+	return &domain.Identity{UserID: userID, Issuer: issuer, Subject: subject}, nil
+}
+
+func (r *memIdentities) Link(_ context.Context, id *domain.Identity) (uuid.UUID, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := id.Issuer + "|" + id.Subject
+	if userID, ok := r.links[key]; ok {
+		return userID, nil
+	}
+	r.links[key] = id.UserID
+	return id.UserID, nil
+}
+
+func (r *memIdentities) SyncGroupGrants(_ context.Context, userID uuid.UUID, grants []domain.GroupGrant) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.grants[userID] = grants
+	return nil
+}
+
+func (r *memIdentities) RequiresSSO(_ context.Context, userID uuid.UUID) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.enforceFor[userID], nil
+}
+
+// memUsers implements the parts of the user service the auth flows use.
+type memUsers struct {
+	userApp.UserService
+	mu      sync.Mutex
+	byEmail map[string]*userApp.UserOutput
+}
+
+func (u *memUsers) ProvisionUser(_ context.Context, in userApp.ProvisionUserInput) (*userApp.UserOutput, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if _, ok := u.byEmail[in.Email]; ok {
+		return nil, userDomain.ErrUserAlreadyExists
+	}
+	out := &userApp.UserOutput{ID: uuid.New(), Email: in.Email, FullName: in.FullName, IsActive: true}
+	u.byEmail[in.Email] = out
+	return out, nil
+}
+
+func (u *memUsers) GetUserByEmail(_ context.Context, email string) (*userApp.UserOutput, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if out, ok := u.byEmail[email]; ok {
+		return out, nil
+	}
+	return nil, userDomain.ErrUserNotFound
+}
+
+func (u *memUsers) GetUser(_ context.Context, id uuid.UUID) (*userApp.UserOutput, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for _, out := range u.byEmail {
+		if out.ID == id {
+			return out, nil
+		}
+	}
+	return nil, userDomain.ErrUserNotFound
+}
+
+// Every account in memUsers accepts the password "secret".
+func (u *memUsers) Authenticate(ctx context.Context, email, password string) (*userApp.UserOutput, error) {
+	out, err := u.GetUserByEmail(ctx, email)
+	if err != nil || password != "secret" {
+		return nil, userDomain.ErrInvalidCredentials
+	}
+	return out, nil
+}
+
+type noopTenants struct{}
+
+func (noopTenants) EnsurePersonalTenant(context.Context, uuid.UUID, string) error { return nil }
+
+type ssoFixture struct {
+	svc        *authService
+	idp        *oidctest.Provider
+	users      *memUsers
+	identities *memIdentities
+	adminsOf   uuid.UUID
+}
+
+func newSSOFixture(t *testing.T) *ssoFixture {
+	svc, _, _ := newTestAuthService()
+	idp := oidctest.New(t)
+	f := &ssoFixture{
+		svc:        svc,
+		idp:        idp,
+		users:      &memUsers{byEmail: map[string]*userApp.UserOutput{}},
+		identities: &memIdentities{links: map[string]uuid.UUID{}, grants: map[uuid.UUID][]domain.GroupGrant{}, enforceFor: map[uuid.UUID]bool{}},
+		adminsOf:   uuid.New(),
+	}
+	svc.userSrv = f.users
+	svc.tenants = noopTenants{}
+	svc.sso = &SSO{
+		Provider:    oidc.New(idp.Config()),
+		States:      &memStates{states: map[string]*domain.SSOState{}},
+		Identities:  f.identities,
+		GroupsClaim: "groups",
+		Mappings: []GroupMapping{
+			{Group: "logify-admins", Tenant: f.adminsOf, Role: "admin"},
+			{Group: "everyone", Tenant: f.adminsOf, Role: "viewer"},
+		},
+	}
+	return f
+}
+
+// login runs the browser side of the flow and the callback.
+func (f *ssoFixture) login(t *testing.T) (*TokenOutput, error) {
+	t.Helper()
+	ctx := context.Background()
+	out, err := f.svc.SSOAuthorize(ctx)
+	require.NoError(t, err)
+	code, state := f.idp.Authorize(out.AuthorizationURL)
+	return f.svc.SSOCallback(ctx, SSOCallbackInput{Code: code, State: state})
+}
+
+func TestSSOProvisionsAndLinksUser(t *testing.T) {
+	f := newSSOFixture(t)
+	f.idp.SignIn(map[string]any{
+		"sub":            "idp-user-1",
+		"email":          "jane@example.com",
+		"email_verified": true,
+		"name":           "Jane Doe",
+		"groups":         []string{"everyone", "logify-admins"},
+	})
+
+	tokens, err := f.login(t)
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.RefreshToken)
+
+	user, err := f.users.GetUserByEmail(context.Background(), "jane@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "Jane Doe", user.FullName)
+	assert.Equal(t, user.ID.String(), claim(t, f.svc, tokens.AccessToken, "sub"))
+	// Mapping order decides between overlapping grants.
+	assert.Equal(t, []domain.GroupGrant{
+		{TenantID: f.adminsOf, RoleKey: "admin"},
+		{TenantID: f.adminsOf, RoleKey: "viewer"},
+	}, f.identities.grants[user.ID])
+
+	// The subject stays linked even after the email changes at the provider,
+	// and losing a group drops its grant.
+	f.idp.SignIn(map[string]any{
+		"sub":            "idp-user-1",
+		"email":          "jane.doe@example.com",
+		"email_verified": true,
+		"groups":         []string{"everyone"},
+	})
+	tokens, err = f.login(t)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID.String(), claim(t, f.svc, tokens.AccessToken, "sub"))
+	assert.Equal(t, []domain.GroupGrant{{TenantID: f.adminsOf, RoleKey: "viewer"}}, f.identities.grants[user.ID])
+}
+
+func TestSSORejectsReplayAndUnverifiedEmail(t *testing.T) {
+	f := newSSOFixture(t)
+	ctx := context.Background()
+	f.idp.SignIn(map[string]any{"sub": "idp-user-2", "email": "bob@example.com"})
+
+	out, err := f.svc.SSOAuthorize(ctx)
+	require.NoError(t, err)
+	code, state := f.idp.Authorize(out.AuthorizationURL)
+
+	_, err = f.svc.SSOCallback(ctx, SSOCallbackInput{Code: code, State: state})
+	assert.ErrorIs(t, err, domain.ErrSSOEmailUnverified)
+
+	_, err = f.svc.SSOCallback(ctx, SSOCallbackInput{Code: code, State: state})
+	assert.ErrorIs(t, err, domain.ErrInvalidSSOState)
+}
+
+func TestSSOEnforcementBlocksPasswordLogin(t *testing.T) {
+	f := newSSOFixture(t)
+	ctx := context.Background()
+	user, err := f.users.ProvisionUser(ctx, userApp.ProvisionUserInput{Email: "amy@example.com", FullName: "Amy"})
+	require.NoError(t, err)
+
+	_, err = f.svc.Login(ctx, LoginInput{Email: "amy@example.com", Password: "secret"})
+	require.NoError(t, err)
+
+	f.identities.enforceFor[user.ID] = true
+	_, err = f.svc.Login(ctx, LoginInput{Email: "amy@example.com", Password: "secret"})
+	assert.ErrorIs(t, err, domain.ErrSSORequired)
+}
@@ -17,4 +17,25 @@ var (
 	ErrPasswordTooLong    = errors.New("password must be at most 72 characters")
 	ErrMFAEnabled         = errors.New("MFA required")
 	ErrInvalidMFACode     = errors.New("invalid MFA code")
+	ErrNotTenantMember    = errors.New("user is not a member of the tenant")
+	ErrSSODisabled        = errors.New("single sign-on is not configured")
+	ErrSSORequired        = errors.New("password login is disabled; sign in with SSO")
+	ErrInvalidSSOState    = errors.New("sso login state is invalid or expired")
+	ErrSSOFailed          = errors.New("sso login failed")
+	ErrSSOEmailUnverified = errors.New("identity provider did not supply a verified email")
+	ErrAccountDisabled    = errors.New("account is disabled")
+
+	ErrServiceAccountNotFound = errors.New("service account not found")
+	ErrServiceAccountExists   = errors.New("a service account with this name already exists")
+	ErrRoleNotAssignable      = errors.New("role cannot be assigned in this tenant")
+	ErrAccessTokenNotFound    = errors.New("access token not found")
+	ErrInvalidTokenScope      = errors.New("token scopes must be permission keys")
+	ErrInvalidTokenExpiry     = errors.New("token expiry is out of range")
+	ErrTokenCannotMintTokens  = errors.New("access tokens cannot create access tokens")
+	ErrNotAuthenticated       = errors.New("caller is not authenticated")
+
+	ErrMFADisabled         = errors.New("MFA is not configured")
+	ErrMFANotEnrolled      = errors.New("MFA is not enrolled")
+	ErrMFAAlreadyEnabled   = errors.New("MFA is already enabled")
+	ErrInvalidMFAChallenge = errors.New("MFA challenge is invalid or expired")
 )
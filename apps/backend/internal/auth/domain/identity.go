@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Identity links an account to a subject at an external identity provider.
+// A user may have several, one per provider.
+type Identity struct {
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	Issuer      string
+	Subject     string
+	Email       string
+	CreatedAt   time.Time
+	LastLoginAt *time.Time
+}
+
+// SSOState is what an SSO login remembers between sending the user to the
+// provider and the callback. It is looked up by the random state parameter
+// and can be used once.
+type SSOState struct {
+	Nonce string `json:"nonce"`
+	// Verifier is the PKCE code verifier; only its challenge leaves the
+	// server before the code exchange.
+	Verifier  string    `json:"verifier"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// GroupGrant is a project membership an identity provider group confers.
+// A zero ProjectID grants the role on every project of the tenant.
+type GroupGrant struct {
+	TenantID  uuid.UUID
+	ProjectID uuid.UUID
+	RoleKey   string
+}
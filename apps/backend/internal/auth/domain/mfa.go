@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MFAEnrollment is a user's TOTP second factor. Secret is encrypted at rest;
+// an enrolment is pending until EnabledAt is set by a verified code.
+type MFAEnrollment struct {
+	UserID       uuid.UUID
+	Secret       []byte
+	EnabledAt    *time.Time
+	LastUsedStep *int64
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func (e *MFAEnrollment) Enabled() bool {
+	return e.EnabledAt != nil
+}
+
+// MFAChallenge is the pending second step of a password login. Enroll is set
+// when a tenant requires MFA and the user has not enrolled yet: the
+// challenge then allows enrolment, and its first valid code enables it.
+type MFAChallenge struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Enroll    bool      `json:"enroll"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// HashRecoveryCode returns the stored form of a recovery code. Codes are
+// compared case-insensitively and without the dash.
+func HashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -11,12 +11,18 @@ import (
 
 var ErrRefreshTokenNotFound = errors.New("refresh token not found")
 
+// RefreshToken is one link in a session's rotation chain. Only the SHA-256 of
+// the raw token is stored; Parent holds the hash of the token it replaced.
 type RefreshToken struct {
-	ID        uuid.UUID `json:"id"`
-	UserID    uuid.UUID `json:"user_id"`
-	TokenHash string    `json:"token"`
-	SessionID uuid.UUID `json:"session_id"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        uuid.UUID  `json:"id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	TokenHash string     `json:"token"`
+	SessionID uuid.UUID  `json:"session_id"`
+	Parent    string     `json:"parent,omitempty"`
+	Revoked   bool       `json:"revoked"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
 }
 
 func HashRefreshToken(raw string) string {
@@ -32,3 +38,9 @@ func NewRefreshToken(userID, sessionID uuid.UUID, rawToken string) *RefreshToken
 		SessionID: sessionID,
 	}
 }
+
+// IsExpired reports whether the token is past its expiry at the given time.
+// Tokens without an expiry (issued before rotation existed) never expire.
+func (t *RefreshToken) IsExpired(now time.Time) bool {
+	return !t.ExpiresAt.IsZero() && now.After(t.ExpiresAt)
+}
@@ -2,12 +2,138 @@ package domain
 
 import (
 	"context"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 type RefreshTokenRepository interface {
 	Create(ctx context.Context, refreshToken *RefreshToken) error
-	GetByToken(ctx context.Context, token string) (*RefreshToken, error)
+	// GetByToken looks a token up by its hash, including revoked ones so
+	// callers can detect reuse. Returns ErrTokenNotFound when absent.
+	GetByToken(ctx context.Context, tokenHash string) (*RefreshToken, error)
+	// Rotate marks current as used and inserts next in one transaction.
+	// Returns ErrTokenAlreadyUsed if current was already rotated or revoked.
+	Rotate(ctx context.Context, current *RefreshToken, next *RefreshToken) error
+	// RevokeBySession revokes every token in a session's rotation family.
+	RevokeBySession(ctx context.Context, sessionID uuid.UUID) error
+	// RevokeByUser revokes every token belonging to the user.
+	RevokeByUser(ctx context.Context, userID uuid.UUID) error
 }
+
 type SessionRepository interface {
 	Create(ctx context.Context, session *Session) error
+	GetByID(ctx context.Context, id uuid.UUID) (*Session, error)
+	// ListActive returns the user's non-revoked, non-expired sessions,
+	// most recently refreshed first.
+	ListActive(ctx context.Context, userID uuid.UUID) ([]*Session, error)
+	// Touch records a refresh against the session.
+	Touch(ctx context.Context, id uuid.UUID, client ClientInfo) error
+	// Revoke revokes a single session owned by userID. Returns
+	// ErrSessionNotFound when no active session matches.
+	Revoke(ctx context.Context, userID, id uuid.UUID) error
+	// RevokeAll revokes every active session of the user.
+	RevokeAll(ctx context.Context, userID uuid.UUID) error
+	// SetTenant records the tenant an active session of userID acts in; nil
+	// selects the personal tenant. Returns ErrSessionNotFound when no active
+	// session matches.
+	SetTenant(ctx context.Context, userID, id uuid.UUID, tenantID *uuid.UUID) error
+}
+
+// IdentityRepository stores links to external identity providers and the
+// memberships their groups grant.
+type IdentityRepository interface {
+	// Get returns ErrCredentialNotFound when no account is linked to the
+	// provider subject.
+	Get(ctx context.Context, issuer, subject string) (*Identity, error)
+	// Link records the identity, or refreshes email and last login of an
+	// existing link, and returns the user the subject is linked to. A subject
+	// linked concurrently keeps its first user.
+	Link(ctx context.Context, identity *Identity) (uuid.UUID, error)
+	// SyncGroupGrants makes the user's SSO-granted project memberships match
+	// grants: missing ones are added, roles updated and the rest marked left.
+	// Memberships added by hand are never touched.
+	SyncGroupGrants(ctx context.Context, userID uuid.UUID, grants []GroupGrant) error
+	// RequiresSSO reports whether any tenant the user owns or belongs to
+	// enforces SSO.
+	RequiresSSO(ctx context.Context, userID uuid.UUID) (bool, error)
+}
+
+// SSOStateStore keeps pending SSO logins.
+type SSOStateStore interface {
+	Save(ctx context.Context, state string, value *SSOState, ttl time.Duration) error
+	// Take returns and deletes the state; ErrInvalidSSOState when it is
+	// unknown or expired.
+	Take(ctx context.Context, state string) (*SSOState, error)
+}
+
+type ServiceAccountRepository interface {
+	// Create returns ErrServiceAccountExists on a duplicate name and
+	// ErrRoleNotAssignable unless the role is a global role other than owner
+	// or one of the tenant's own.
+	Create(ctx context.Context, account *ServiceAccount) error
+	Get(ctx context.Context, tenantID, id uuid.UUID) (*ServiceAccount, error)
+	List(ctx context.Context, tenantID uuid.UUID) ([]*ServiceAccount, error)
+	// Update writes name, description, role and disabled state, with the
+	// same errors as Create.
+	Update(ctx context.Context, account *ServiceAccount) error
+	// Delete removes the account together with its tokens.
+	Delete(ctx context.Context, tenantID, id uuid.UUID) error
+}
+
+type AccessTokenRepository interface {
+	Create(ctx context.Context, token *AccessToken) error
+	// GetByHash returns the token with PrincipalActive filled in, including
+	// revoked and expired tokens. ErrAccessTokenNotFound when absent.
+	GetByHash(ctx context.Context, hash string) (*AccessToken, error)
+	// List returns the owner's tokens, newest first, revoked ones included.
+	List(ctx context.Context, owner TokenOwner) ([]*AccessToken, error)
+	// Revoke revokes an active token of the owner; ErrAccessTokenNotFound
+	// when none matches.
+	Revoke(ctx context.Context, owner TokenOwner, id uuid.UUID) error
+	// Touch records use of the token.
+	Touch(ctx context.Context, id uuid.UUID, at time.Time) error
+}
+
+type MFARepository interface {
+	// Get returns ErrMFANotEnrolled when the user has no enrolment, pending
+	// or enabled.
+	Get(ctx context.Context, userID uuid.UUID) (*MFAEnrollment, error)
+	// SavePending stores a new, not yet enabled secret, replacing a pending
+	// one. ErrMFAAlreadyEnabled when MFA is on.
+	SavePending(ctx context.Context, userID uuid.UUID, secret []byte) error
+	// Enable turns a pending enrolment on, consuming step, and replaces the
+	// recovery codes.
+	Enable(ctx context.Context, userID uuid.UUID, step int64, recoveryHashes []string) error
+	// UseStep records a code's time step; false when that step or a later
+	// one was already used.
+	UseStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error)
+	// UseRecoveryCode marks an unused code as used; false when none matches.
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, hash string) (bool, error)
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, hashes []string) error
+	RemainingRecoveryCodes(ctx context.Context, userID uuid.UUID) (int, error)
+	// Delete removes the enrolment and the recovery codes.
+	Delete(ctx context.Context, userID uuid.UUID) error
+	// RequiresMFA reports whether the user belongs to a tenant requiring MFA.
+	RequiresMFA(ctx context.Context, userID uuid.UUID) (bool, error)
+}
+
+// MFAChallengeStore keeps pending second login steps until they expire.
+type MFAChallengeStore interface {
+	Save(ctx context.Context, token string, challenge *MFAChallenge, ttl time.Duration) error
+	// Get returns ErrInvalidMFAChallenge when the token is unknown or
+	// expired.
+	Get(ctx context.Context, token string) (*MFAChallenge, error)
+	Delete(ctx context.Context, token string) error
+}
+
+// AttemptLimiter locks a key (an email, a user) out after too many failed
+// attempts within a window.
+type AttemptLimiter interface {
+	// Locked returns how much longer the key is locked out; zero when it
+	// is not.
+	Locked(ctx context.Context, key string) (time.Duration, error)
+	// Fail records a failed attempt and reports whether it locked the key.
+	Fail(ctx context.Context, key string) (bool, error)
+	Reset(ctx context.Context, key string) error
 }
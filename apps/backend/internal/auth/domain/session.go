@@ -8,12 +8,31 @@ import (
 )
 
 type Session struct {
-	ID           uuid.UUID `json:"id"`
-	UserID       uuid.UUID `json:"user_id"`
-	RefreshToken string    `json:"refresh_token"`
-	IPAddress    net.IP    `json:"ip_address"`
-	UserAgent    string    `json:"user_agent"`
-	RefreshAt    time.Time `json:"refresh_at"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+	// TenantID is the tenant the session acts in; nil is the user's personal
+	// tenant.
+	TenantID     *uuid.UUID `json:"tenant_id,omitempty"`
+	RefreshToken string     `json:"refresh_token"`
+	IPAddress    net.IP     `json:"ip_address"`
+	UserAgent    string     `json:"user_agent"`
+	RefreshAt    *time.Time `json:"refresh_at"`
+	ExpiresAt    *time.Time `json:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// ClientInfo is the request metadata recorded against a session.
+type ClientInfo struct {
+	IPAddress net.IP
+	UserAgent string
+}
+
+// IsActive reports whether the session can still be refreshed at the given time.
+func (s *Session) IsActive(now time.Time) bool {
+	if s.RevokedAt != nil {
+		return false
+	}
+	return s.ExpiresAt == nil || now.Before(*s.ExpiresAt)
 }
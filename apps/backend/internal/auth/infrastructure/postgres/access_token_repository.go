@@ -0,0 +1,144 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/auth/domain"
+)
+
+type AccessTokenRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAccessTokenRepository(db *pgxpool.Pool) *AccessTokenRepository {
+	return &AccessTokenRepository{db: db}
+}
+
+const accessTokenColumns = `
+	t.id, t.tenant_id, t.user_id, t.service_account_id, t.name, t.token_hash, t.token_prefix,
+	t.scopes, t.created_by, t.expires_at, t.last_used_at, t.revoked_at, t.created_at
+`
+
+// ownerMatch restricts a query to the tokens of the owner in $1 (user) and
+// $2 (service account); exactly one of them is non-null.
+const ownerMatch = `(t.user_id = $1 OR t.service_account_id = $2)`
+
+func (r *AccessTokenRepository) Create(ctx context.Context, t *domain.AccessToken) error {
+	const query = `
+		INSERT INTO auth.access_tokens (
+			tenant_id, user_id, service_account_id, name, token_hash, token_prefix,
+			scopes, created_by, expires_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at
+	`
+	return r.db.QueryRow(ctx, query,
+		t.TenantID,
+		t.Owner.UserID,
+		t.Owner.ServiceAccountID,
+		t.Name,
+		t.Hash,
+		t.Prefix,
+		t.Scopes,
+		t.CreatedBy,
+		t.ExpiresAt,
+	).Scan(&t.ID, &t.CreatedAt)
+}
+
+// GetByHash reports the principal as active when the owning user is active
+// and not deleted, or the owning service account is enabled.
+func (r *AccessTokenRepository) GetByHash(ctx context.Context, hash string) (*domain.AccessToken, error) {
+	query := `
+		SELECT ` + accessTokenColumns + `,
+		       COALESCE(u.is_active AND u.deleted_at IS NULL, sa.disabled_at IS NULL, FALSE)
+		FROM auth.access_tokens t
+		LEFT JOIN auth.users u ON u.id = t.user_id
+		LEFT JOIN auth.service_accounts sa ON sa.id = t.service_account_id
+		WHERE t.token_hash = $1
+	`
+	var t domain.AccessToken
+	err := r.db.QueryRow(ctx, query, hash).Scan(append(accessTokenDest(&t), &t.PrincipalActive)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrAccessTokenNotFound
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *AccessTokenRepository) List(ctx context.Context, owner domain.TokenOwner) ([]*domain.AccessToken, error) {
+	query := `
+		SELECT ` + accessTokenColumns + `
+		FROM auth.access_tokens t
+		WHERE ` + ownerMatch + `
+		ORDER BY t.created_at DESC
+	`
+	rows, err := r.db.Query(ctx, query, owner.UserID, owner.ServiceAccountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]*domain.AccessToken, 0)
+	for rows.Next() {
+		var t domain.AccessToken
+		if err := rows.Scan(accessTokenDest(&t)...); err != nil {
+			return nil, err
+		}
+		out = append(out, &t)
+	}
+	return out, rows.Err()
+}
+
+func (r *AccessTokenRepository) Revoke(ctx context.Context, owner domain.TokenOwner, id uuid.UUID) error {
+	query := `
+		UPDATE auth.access_tokens t
+		SET revoked_at = now()
+		WHERE ` + ownerMatch + `
+		  AND t.id = $3
+		  AND t.revoked_at IS NULL
+	`
+	tag, err := r.db.Exec(ctx, query, owner.UserID, owner.ServiceAccountID, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrAccessTokenNotFound
+	}
+	return nil
+}
+
+func (r *AccessTokenRepository) Touch(ctx context.Context, id uuid.UUID, at time.Time) error {
+	const query = `
+		UPDATE auth.access_tokens
+		SET last_used_at = $2
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < $2)
+	`
+	_, err := r.db.Exec(ctx, query, id, at)
+	return err
+}
+
+func accessTokenDest(t *domain.AccessToken) []any {
+	return []any{
+		&t.ID,
+		&t.TenantID,
+		&t.Owner.UserID,
+		&t.Owner.ServiceAccountID,
+		&t.Name,
+		&t.Hash,
+		&t.Prefix,
+		&t.Scopes,
+		&t.CreatedBy,
+		&t.ExpiresAt,
+		&t.LastUsedAt,
+		&t.RevokedAt,
+		&t.CreatedAt,
+	}
+}
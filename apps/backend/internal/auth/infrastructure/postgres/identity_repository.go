@@ -0,0 +1,138 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/auth/domain"
+)
+
+type IdentityRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewIdentityRepository(db *pgxpool.Pool) *IdentityRepository {
+	return &IdentityRepository{db: db}
+}
+
+func (r *IdentityRepository) Get(ctx context.Context, issuer, subject string) (*domain.Identity, error) {
+	const query = `
+		SELECT id, user_id, issuer, subject, COALESCE(email, ''), created_at, last_login_at
+		FROM auth.user_identities
+		WHERE issuer = $1 AND subject = $2
+	`
+	var id domain.Identity
+	err := r.db.QueryRow(ctx, query, issuer, subject).Scan(
+		&id.ID, &id.UserID, &id.Issuer, &id.Subject, &id.Email, &id.CreatedAt, &id.LastLoginAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrCredentialNotFound
+		}
+		return nil, err
+	}
+	return &id, nil
+}
+
+func (r *IdentityRepository) Link(ctx context.Context, id *domain.Identity) (uuid.UUID, error) {
+	const query = `
+		INSERT INTO auth.user_identities (user_id, issuer, subject, email, last_login_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5)
+		ON CONFLICT (issuer, subject) DO UPDATE
+		SET email = EXCLUDED.email,
+		    last_login_at = EXCLUDED.last_login_at
+		RETURNING id, user_id, created_at
+	`
+	err := r.db.QueryRow(ctx, query, id.UserID, id.Issuer, id.Subject, id.Email, id.LastLoginAt).
+		Scan(&id.ID, &id.UserID, &id.CreatedAt)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return id.UserID, nil
+}
+
+// SyncGroupGrants runs as one statement. When several grants cover the same
+// project the earliest one wins. The role is looked up among the global
+// roles and the tenant's own (which take precedence); owner is never granted
+// and grants naming an unknown role are skipped.
+func (r *IdentityRepository) SyncGroupGrants(ctx context.Context, userID uuid.UUID, grants []domain.GroupGrant) error {
+	tenants := make([]uuid.UUID, len(grants))
+	projects := make([]uuid.UUID, len(grants))
+	roles := make([]string, len(grants))
+	for i, g := range grants {
+		tenants[i], projects[i], roles[i] = g.TenantID, g.ProjectID, g.RoleKey
+	}
+
+	const query = `
+		WITH grants AS (
+			SELECT *
+			FROM unnest($2::uuid[], $3::uuid[], $4::text[]) WITH ORDINALITY AS g (tenant_id, project_id, role_key, ord)
+		),
+		wanted AS (
+			SELECT DISTINCT ON (p.id) p.id AS project_id, r.id AS role_id
+			FROM grants g
+			JOIN projects p
+			  ON p.tenant_id = g.tenant_id
+			 AND (g.project_id = '00000000-0000-0000-0000-000000000000' OR p.id = g.project_id)
+			 AND p.deleted_at IS NULL
+			JOIN LATERAL (
+				SELECT id FROM auth.roles
+				WHERE key = g.role_key
+				  AND key <> 'owner'
+				  AND (tenant_id IS NULL OR tenant_id = p.tenant_id)
+				ORDER BY tenant_id NULLS LAST
+				LIMIT 1
+			) r ON TRUE
+			ORDER BY p.id, g.ord
+		),
+		granted AS (
+			INSERT INTO project_members (project_id, user_id, role_id, status, source)
+			SELECT project_id, $1, role_id, 'active', 'sso' FROM wanted
+			ON CONFLICT (project_id, user_id) DO UPDATE
+			SET role_id = EXCLUDED.role_id,
+			    status = CASE WHEN project_members.status = 'left' THEN 'active' ELSE project_members.status END,
+			    joined_at = CASE WHEN project_members.status = 'left' THEN now() ELSE project_members.joined_at END,
+			    source = 'sso',
+			    updated_at = now()
+			WHERE project_members.source = 'sso' OR project_members.status = 'left'
+			RETURNING project_id
+		)
+		UPDATE project_members
+		SET status = 'left', updated_at = now()
+		WHERE user_id = $1
+		  AND source = 'sso'
+		  AND status <> 'left'
+		  AND project_id NOT IN (SELECT project_id FROM wanted)
+	`
+	_, err := r.db.Exec(ctx, query, userID, tenants, projects, roles)
+	return err
+}
+
+func (r *IdentityRepository) RequiresSSO(ctx context.Context, userID uuid.UUID) (bool, error) {
+	const query = `
+		SELECT EXISTS (
+			SELECT 1 FROM tenants t
+			WHERE t.sso_enforced
+			  AND t.deleted_at IS NULL
+			  AND (
+			    t.id = $1
+			    OR EXISTS (
+			      SELECT 1
+			      FROM project_members pm
+			      JOIN projects p ON p.id = pm.project_id
+			      WHERE pm.user_id = $1
+			        AND p.tenant_id = t.id
+			        AND pm.status <> 'left'
+			        AND p.deleted_at IS NULL
+			    )
+			  )
+		)
+	`
+	var required bool
+	err := r.db.QueryRow(ctx, query, userID).Scan(&required)
+	return required, err
+}
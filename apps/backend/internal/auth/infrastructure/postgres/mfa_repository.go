@@ -0,0 +1,172 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/auth/domain"
+)
+
+type MFARepository struct {
+	db *pgxpool.Pool
+}
+
+func NewMFARepository(db *pgxpool.Pool) *MFARepository {
+	return &MFARepository{db: db}
+}
+
+func (r *MFARepository) Get(ctx context.Context, userID uuid.UUID) (*domain.MFAEnrollment, error) {
+	const query = `
+		SELECT user_id, secret, enabled_at, last_used_step, created_at, updated_at
+		FROM auth.user_mfa
+		WHERE user_id = $1
+	`
+	var e domain.MFAEnrollment
+	err := r.db.QueryRow(ctx, query, userID).Scan(
+		&e.UserID, &e.Secret, &e.EnabledAt, &e.LastUsedStep, &e.CreatedAt, &e.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrMFANotEnrolled
+		}
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (r *MFARepository) SavePending(ctx context.Context, userID uuid.UUID, secret []byte) error {
+	const query = `
+		INSERT INTO auth.user_mfa (user_id, secret)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret = EXCLUDED.secret,
+		    last_used_step = NULL,
+		    created_at = now(),
+		    updated_at = now()
+		WHERE auth.user_mfa.enabled_at IS NULL
+	`
+	tag, err := r.db.Exec(ctx, query, userID, secret)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrMFAAlreadyEnabled
+	}
+	return nil
+}
+
+// Enable runs as one statement so the codes never exist without the factor
+// being on.
+func (r *MFARepository) Enable(ctx context.Context, userID uuid.UUID, step int64, recoveryHashes []string) error {
+	const query = `
+		WITH enabled AS (
+			UPDATE auth.user_mfa
+			SET enabled_at = now(), last_used_step = $2, updated_at = now()
+			WHERE user_id = $1 AND enabled_at IS NULL
+			RETURNING user_id
+		),
+		cleared AS (
+			DELETE FROM auth.mfa_recovery_codes
+			WHERE user_id IN (SELECT user_id FROM enabled)
+		)
+		INSERT INTO auth.mfa_recovery_codes (user_id, code_hash)
+		SELECT e.user_id, h FROM enabled e, unnest($3::text[]) AS h
+	`
+	tag, err := r.db.Exec(ctx, query, userID, step, recoveryHashes)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrMFANotEnrolled
+	}
+	return nil
+}
+
+func (r *MFARepository) UseStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	const query = `
+		UPDATE auth.user_mfa
+		SET last_used_step = $2, updated_at = now()
+		WHERE user_id = $1
+		  AND enabled_at IS NOT NULL
+		  AND (last_used_step IS NULL OR last_used_step < $2)
+	`
+	tag, err := r.db.Exec(ctx, query, userID, step)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *MFARepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, hash string) (bool, error) {
+	const query = `
+		UPDATE auth.mfa_recovery_codes
+		SET used_at = now()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`
+	tag, err := r.db.Exec(ctx, query, userID, hash)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *MFARepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, hashes []string) error {
+	const query = `
+		WITH cleared AS (
+			DELETE FROM auth.mfa_recovery_codes WHERE user_id = $1
+		)
+		INSERT INTO auth.mfa_recovery_codes (user_id, code_hash)
+		SELECT $1, h FROM unnest($2::text[]) AS h
+	`
+	_, err := r.db.Exec(ctx, query, userID, hashes)
+	return err
+}
+
+func (r *MFARepository) RemainingRecoveryCodes(ctx context.Context, userID uuid.UUID) (int, error) {
+	const query = `SELECT count(*) FROM auth.mfa_recovery_codes WHERE user_id = $1 AND used_at IS NULL`
+	var n int
+	err := r.db.QueryRow(ctx, query, userID).Scan(&n)
+	return n, err
+}
+
+func (r *MFARepository) Delete(ctx context.Context, userID uuid.UUID) error {
+	const query = `
+		WITH cleared AS (
+			DELETE FROM auth.mfa_recovery_codes WHERE user_id = $1
+		)
+		DELETE FROM auth.user_mfa WHERE user_id = $1
+	`
+	_, err := r.db.Exec(ctx, query, userID)
+	return err
+}
+
+// RequiresMFA mirrors IdentityRepository.RequiresSSO: the personal tenant
+// counts, as does any tenant where the user holds a project membership.
+func (r *MFARepository) RequiresMFA(ctx context.Context, userID uuid.UUID) (bool, error) {
+	const query = `
+		SELECT EXISTS (
+			SELECT 1 FROM tenants t
+			WHERE t.mfa_required
+			  AND t.deleted_at IS NULL
+			  AND (
+			    t.id = $1
+			    OR EXISTS (
+			      SELECT 1
+			      FROM project_members pm
+			      JOIN projects p ON p.id = pm.project_id
+			      WHERE pm.user_id = $1
+			        AND p.tenant_id = t.id
+			        AND pm.status <> 'left'
+			        AND p.deleted_at IS NULL
+			    )
+			  )
+		)
+	`
+	var required bool
+	err := r.db.QueryRow(ctx, query, userID).Scan(&required)
+	return required, err
+}
@@ -2,9 +2,14 @@ package postgres
 
 import (
 	"context"
+	"errors"
+	"time"
 
-	"github.com/indalyadav56/logify/apps/backend/internal/auth/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/auth/domain"
 )
 
 type RefreshTokenRepository struct {
@@ -16,27 +21,108 @@ func NewRefreshTokenRepository(db *pgxpool.Pool) *RefreshTokenRepository {
 }
 
 func (r *RefreshTokenRepository) Create(ctx context.Context, refreshToken *domain.RefreshToken) error {
+	return insertRefreshToken(ctx, r.db, refreshToken)
+}
+
+func (r *RefreshTokenRepository) GetByToken(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
 	query := `
-		INSERT INTO auth.refresh_tokens (id, user_id, token, session_id)
-		VALUES ($1, $2, $3, $4)
+		SELECT id, user_id, session_id, token, COALESCE(parent, ''), COALESCE(revoked, false),
+		       expires_at, used_at, created_at
+		FROM auth.refresh_tokens
+		WHERE token = $1
 	`
-	_, err := r.db.Exec(ctx, query, refreshToken.ID, refreshToken.UserID, refreshToken.TokenHash, refreshToken.SessionID)
+	var (
+		row       domain.RefreshToken
+		expiresAt *time.Time
+	)
+	err := r.db.QueryRow(ctx, query, tokenHash).Scan(
+		&row.ID,
+		&row.UserID,
+		&row.SessionID,
+		&row.TokenHash,
+		&row.Parent,
+		&row.Revoked,
+		&expiresAt,
+		&row.UsedAt,
+		&row.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrTokenNotFound
+		}
+		return nil, err
+	}
+	if expiresAt != nil {
+		row.ExpiresAt = *expiresAt
+	}
+	return &row, nil
+}
+
+func (r *RefreshTokenRepository) Rotate(ctx context.Context, current *domain.RefreshToken, next *domain.RefreshToken) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// The revoked = false guard makes rotation single-use even under
+	// concurrent refreshes of the same token: only one caller wins.
+	tag, err := tx.Exec(ctx, `
+		UPDATE auth.refresh_tokens
+		SET revoked = true,
+		    used_at = (now() AT TIME ZONE 'utc'),
+		    updated_at = (now() AT TIME ZONE 'utc')
+		WHERE id = $1 AND revoked = false
+	`, current.ID)
 	if err != nil {
 		return err
 	}
-	return nil
-}
-
-func (r *RefreshTokenRepository) GetByToken(ctx context.Context, token string) (*domain.RefreshToken, error) {
-	// query := `
-	// 	SELECT id, user_id, token, created_at
-	// 	FROM auth.refresh_tokens
-	// 	WHERE token = $1 AND expires_at > $2
-	// `
-	// var row domain.RefreshToken
-	// err := r.db.QueryRow(ctx, query, token, time.Now().UTC()).Scan(&row.ID, &row.UserID, &row.TokenHash, &row.ExpiresAt, &row.CreatedAt)
-	// if err != nil {
-	// 	return nil, err
-	// }
-	return nil, nil
+	if tag.RowsAffected() == 0 {
+		return domain.ErrTokenAlreadyUsed
+	}
+
+	next.Parent = current.TokenHash
+	if err := insertRefreshToken(ctx, tx, next); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *RefreshTokenRepository) RevokeBySession(ctx context.Context, sessionID uuid.UUID) error {
+	query := `
+		UPDATE auth.refresh_tokens
+		SET revoked = true, updated_at = (now() AT TIME ZONE 'utc')
+		WHERE session_id = $1 AND revoked = false
+	`
+	_, err := r.db.Exec(ctx, query, sessionID)
+	return err
+}
+
+func (r *RefreshTokenRepository) RevokeByUser(ctx context.Context, userID uuid.UUID) error {
+	query := `
+		UPDATE auth.refresh_tokens
+		SET revoked = true, updated_at = (now() AT TIME ZONE 'utc')
+		WHERE user_id = $1 AND revoked = false
+	`
+	_, err := r.db.Exec(ctx, query, userID)
+	return err
+}
+
+// execer is the subset of pgxpool.Pool / pgx.Tx used by insertRefreshToken.
+type execer interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func insertRefreshToken(ctx context.Context, db execer, t *domain.RefreshToken) error {
+	query := `
+		INSERT INTO auth.refresh_tokens (id, user_id, token, session_id, parent, expires_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6)
+		RETURNING created_at
+	`
+	var expiresAt *time.Time
+	if !t.ExpiresAt.IsZero() {
+		expiresAt = &t.ExpiresAt
+	}
+	return db.QueryRow(ctx, query, t.ID, t.UserID, t.TokenHash, t.SessionID, t.Parent, expiresAt).
+		Scan(&t.CreatedAt)
 }
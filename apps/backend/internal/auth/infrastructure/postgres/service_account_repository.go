@@ -0,0 +1,157 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/auth/domain"
+)
+
+const pgUniqueViolation = "23505"
+
+type ServiceAccountRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewServiceAccountRepository(db *pgxpool.Pool) *ServiceAccountRepository {
+	return &ServiceAccountRepository{db: db}
+}
+
+// assignableRole matches a role a service account of tenant $1 may hold:
+// one of the tenant's own, or a global workspace role other than owner.
+const assignableRole = `(
+	r.tenant_id = $1
+	OR (r.tenant_id IS NULL AND r.scope = 'workspace' AND r.key <> 'owner')
+)`
+
+const serviceAccountColumns = `
+	sa.id, sa.tenant_id, sa.name, sa.description, sa.role_id, r.key,
+	sa.created_by, sa.disabled_at, sa.created_at, sa.updated_at
+`
+
+func (r *ServiceAccountRepository) Create(ctx context.Context, sa *domain.ServiceAccount) error {
+	query := `
+		INSERT INTO auth.service_accounts (tenant_id, name, description, role_id, created_by)
+		SELECT $1, $2, $3, r.id, $5
+		FROM auth.roles r
+		WHERE r.id = $4 AND ` + assignableRole + `
+		RETURNING id, created_at, updated_at
+	`
+	err := r.db.QueryRow(ctx, query, sa.TenantID, sa.Name, sa.Description, sa.RoleID, sa.CreatedBy).
+		Scan(&sa.ID, &sa.CreatedAt, &sa.UpdatedAt)
+	return serviceAccountWriteErr(err)
+}
+
+func (r *ServiceAccountRepository) Get(ctx context.Context, tenantID, id uuid.UUID) (*domain.ServiceAccount, error) {
+	query := `
+		SELECT ` + serviceAccountColumns + `
+		FROM auth.service_accounts sa
+		JOIN auth.roles r ON r.id = sa.role_id
+		WHERE sa.tenant_id = $1 AND sa.id = $2
+	`
+	sa, err := scanServiceAccount(r.db.QueryRow(ctx, query, tenantID, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrServiceAccountNotFound
+	}
+	return sa, err
+}
+
+func (r *ServiceAccountRepository) List(ctx context.Context, tenantID uuid.UUID) ([]*domain.ServiceAccount, error) {
+	query := `
+		SELECT ` + serviceAccountColumns + `
+		FROM auth.service_accounts sa
+		JOIN auth.roles r ON r.id = sa.role_id
+		WHERE sa.tenant_id = $1
+		ORDER BY lower(sa.name)
+	`
+	rows, err := r.db.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]*domain.ServiceAccount, 0)
+	for rows.Next() {
+		sa, err := scanServiceAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, sa)
+	}
+	return out, rows.Err()
+}
+
+// Update returns ErrServiceAccountNotFound when the account is missing and
+// ErrRoleNotAssignable when it exists but the role does not qualify.
+func (r *ServiceAccountRepository) Update(ctx context.Context, sa *domain.ServiceAccount) error {
+	query := `
+		UPDATE auth.service_accounts
+		SET name = $3,
+		    description = $4,
+		    role_id = r.id,
+		    disabled_at = $6,
+		    updated_at = now()
+		FROM auth.roles r
+		WHERE auth.service_accounts.tenant_id = $1
+		  AND auth.service_accounts.id = $2
+		  AND r.id = $5 AND ` + assignableRole + `
+		RETURNING auth.service_accounts.updated_at
+	`
+	err := r.db.QueryRow(ctx, query, sa.TenantID, sa.ID, sa.Name, sa.Description, sa.RoleID, sa.DisabledAt).
+		Scan(&sa.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		if _, err := r.Get(ctx, sa.TenantID, sa.ID); err != nil {
+			return err
+		}
+		return domain.ErrRoleNotAssignable
+	}
+	return serviceAccountWriteErr(err)
+}
+
+func (r *ServiceAccountRepository) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	const query = `DELETE FROM auth.service_accounts WHERE tenant_id = $1 AND id = $2`
+	tag, err := r.db.Exec(ctx, query, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrServiceAccountNotFound
+	}
+	return nil
+}
+
+func serviceAccountWriteErr(err error) error {
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return domain.ErrRoleNotAssignable
+	case errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation:
+		return domain.ErrServiceAccountExists
+	}
+	return err
+}
+
+func scanServiceAccount(row pgx.Row) (*domain.ServiceAccount, error) {
+	var sa domain.ServiceAccount
+	err := row.Scan(
+		&sa.ID,
+		&sa.TenantID,
+		&sa.Name,
+		&sa.Description,
+		&sa.RoleID,
+		&sa.RoleKey,
+		&sa.CreatedBy,
+		&sa.DisabledAt,
+		&sa.CreatedAt,
+		&sa.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &sa, nil
+}
@@ -2,10 +2,13 @@ package postgres
 
 import (
 	"context"
-	"time"
+	"errors"
 
-	"github.com/indalyadav56/logify/apps/backend/internal/auth/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/auth/domain"
 )
 
 type SessionRepository struct {
@@ -16,13 +19,15 @@ func NewSessionRepository(db *pgxpool.Pool) *SessionRepository {
 	return &SessionRepository{db: db}
 }
 
+const sessionColumns = `id, user_id, tenant_id, ip_address, COALESCE(user_agent, ''), refresh_at, expires_at, revoked_at, created_at, updated_at`
+
 func (s *SessionRepository) Create(ctx context.Context, session *domain.Session) error {
 	query := `
-		INSERT INTO auth.sessions (user_id, ip_address, user_agent)
-		VALUES ($1, $2, $3)
+		INSERT INTO auth.sessions (user_id, ip_address, user_agent, expires_at)
+		VALUES ($1, $2, $3, $4)
 		RETURNING id, created_at, updated_at
 	`
-	err := s.db.QueryRow(ctx, query, session.UserID, session.IPAddress, session.UserAgent).
+	err := s.db.QueryRow(ctx, query, session.UserID, session.IPAddress, session.UserAgent, session.ExpiresAt).
 		Scan(&session.ID, &session.CreatedAt, &session.UpdatedAt)
 	if err != nil {
 		return err
@@ -30,16 +35,115 @@ func (s *SessionRepository) Create(ctx context.Context, session *domain.Session)
 	return nil
 }
 
-func (s *SessionRepository) GetByToken(ctx context.Context, token string) (*domain.Session, error) {
+func (s *SessionRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM auth.sessions WHERE id = $1`
+	row, err := scanSession(s.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrSessionNotFound
+		}
+		return nil, err
+	}
+	return row, nil
+}
+
+func (s *SessionRepository) ListActive(ctx context.Context, userID uuid.UUID) ([]*domain.Session, error) {
 	query := `
-		SELECT id, user_id, ip_address, user_agent, refresh_at, created_at
+		SELECT ` + sessionColumns + `
 		FROM auth.sessions
-		WHERE token = $1 AND refresh_at > $2
+		WHERE user_id = $1
+		  AND revoked_at IS NULL
+		  AND (expires_at IS NULL OR expires_at > now())
+		ORDER BY COALESCE(refresh_at, created_at) DESC
+	`
+	rows, err := s.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]*domain.Session, 0)
+	for rows.Next() {
+		row, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
+
+func (s *SessionRepository) Touch(ctx context.Context, id uuid.UUID, client domain.ClientInfo) error {
+	query := `
+		UPDATE auth.sessions
+		SET refresh_at = (now() AT TIME ZONE 'utc'),
+		    ip_address = COALESCE($2, ip_address),
+		    user_agent = COALESCE(NULLIF($3, ''), user_agent),
+		    updated_at = (now() AT TIME ZONE 'utc')
+		WHERE id = $1
+	`
+	_, err := s.db.Exec(ctx, query, id, client.IPAddress, client.UserAgent)
+	return err
+}
+
+func (s *SessionRepository) Revoke(ctx context.Context, userID, id uuid.UUID) error {
+	query := `
+		UPDATE auth.sessions
+		SET revoked_at = (now() AT TIME ZONE 'utc'), updated_at = (now() AT TIME ZONE 'utc')
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
 	`
-	var row domain.Session
-	err := s.db.QueryRow(ctx, query, token, time.Now().UTC()).Scan(&row.ID, &row.UserID, &row.IPAddress, &row.UserAgent, &row.RefreshAt, &row.CreatedAt)
+	tag, err := s.db.Exec(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrSessionNotFound
+	}
+	return nil
+}
+
+func (s *SessionRepository) RevokeAll(ctx context.Context, userID uuid.UUID) error {
+	query := `
+		UPDATE auth.sessions
+		SET revoked_at = (now() AT TIME ZONE 'utc'), updated_at = (now() AT TIME ZONE 'utc')
+		WHERE user_id = $1 AND revoked_at IS NULL
+	`
+	_, err := s.db.Exec(ctx, query, userID)
+	return err
+}
+
+func (s *SessionRepository) SetTenant(ctx context.Context, userID, id uuid.UUID, tenantID *uuid.UUID) error {
+	query := `
+		UPDATE auth.sessions
+		SET tenant_id = $3, updated_at = (now() AT TIME ZONE 'utc')
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+	`
+	tag, err := s.db.Exec(ctx, query, id, userID, tenantID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrSessionNotFound
+	}
+	return nil
+}
+
+func scanSession(row pgx.Row) (*domain.Session, error) {
+	var s domain.Session
+	err := row.Scan(
+		&s.ID,
+		&s.UserID,
+		&s.TenantID,
+		&s.IPAddress,
+		&s.UserAgent,
+		&s.RefreshAt,
+		&s.ExpiresAt,
+		&s.RevokedAt,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	)
 	if err != nil {
 		return nil, err
 	}
-	return &row, nil
+	return &s, nil
 }
@@ -0,0 +1,124 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/auth/domain"
+)
+
+const (
+	mfaChallengePrefix = "auth:mfa:challenge:"
+	attemptsPrefix     = "auth:attempts:"
+	lockoutPrefix      = "auth:lockout:"
+)
+
+// MFAChallengeStore keeps pending second login steps in Redis so the
+// verification may land on any server instance.
+type MFAChallengeStore struct {
+	client redis.UniversalClient
+}
+
+func NewMFAChallengeStore(client redis.UniversalClient) *MFAChallengeStore {
+	return &MFAChallengeStore{client: client}
+}
+
+func (s *MFAChallengeStore) Save(ctx context.Context, token string, value *domain.MFAChallenge, ttl time.Duration) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, mfaChallengePrefix+token, b, ttl).Err()
+}
+
+func (s *MFAChallengeStore) Get(ctx context.Context, token string) (*domain.MFAChallenge, error) {
+	b, err := s.client.Get(ctx, mfaChallengePrefix+token).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, domain.ErrInvalidMFAChallenge
+		}
+		return nil, err
+	}
+	var value domain.MFAChallenge
+	if err := json.Unmarshal(b, &value); err != nil {
+		return nil, domain.ErrInvalidMFAChallenge
+	}
+	return &value, nil
+}
+
+func (s *MFAChallengeStore) Delete(ctx context.Context, token string) error {
+	return s.client.Del(ctx, mfaChallengePrefix+token).Err()
+}
+
+const defaultAttemptWindow = 15 * time.Minute
+
+// AttemptLimiter counts failures per key in a fixed window and locks the
+// key out for a while once MaxAttempts is reached.
+type AttemptLimiter struct {
+	client      redis.UniversalClient
+	maxAttempts int64
+	window      time.Duration
+	lockout     time.Duration
+}
+
+// NewAttemptLimiter falls back to defaultAttemptWindow for a zero window or
+// lockout; a zero lockout would otherwise lock the key forever.
+func NewAttemptLimiter(client redis.UniversalClient, maxAttempts int, window, lockout time.Duration) *AttemptLimiter {
+	if window <= 0 {
+		window = defaultAttemptWindow
+	}
+	if lockout <= 0 {
+		lockout = defaultAttemptWindow
+	}
+	return &AttemptLimiter{
+		client:      client,
+		maxAttempts: int64(maxAttempts),
+		window:      window,
+		lockout:     lockout,
+	}
+}
+
+func (l *AttemptLimiter) Locked(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := l.client.PTTL(ctx, lockoutPrefix+key).Result()
+	if err != nil {
+		return 0, err
+	}
+	// Negative values mean the key is missing or has no expiry.
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+func (l *AttemptLimiter) Fail(ctx context.Context, key string) (bool, error) {
+	counter := attemptsPrefix + key
+	n, err := l.client.Incr(ctx, counter).Result()
+	if err != nil {
+		return false, err
+	}
+	if n == 1 {
+		// The first failure opens the window.
+		if err := l.client.Expire(ctx, counter, l.window).Err(); err != nil {
+			return false, err
+		}
+	}
+	if n < l.maxAttempts {
+		return false, nil
+	}
+
+	pipe := l.client.TxPipeline()
+	pipe.Set(ctx, lockoutPrefix+key, 1, l.lockout)
+	pipe.Del(ctx, counter)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (l *AttemptLimiter) Reset(ctx context.Context, key string) error {
+	return l.client.Del(ctx, attemptsPrefix+key, lockoutPrefix+key).Err()
+}
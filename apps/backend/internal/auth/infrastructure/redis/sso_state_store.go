@@ -0,0 +1,49 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/auth/domain"
+)
+
+const ssoStatePrefix = "auth:sso:state:"
+
+// SSOStateStore keeps pending SSO logins in Redis so the callback may land
+// on any server instance.
+type SSOStateStore struct {
+	client redis.UniversalClient
+}
+
+func NewSSOStateStore(client redis.UniversalClient) *SSOStateStore {
+	return &SSOStateStore{client: client}
+}
+
+func (s *SSOStateStore) Save(ctx context.Context, state string, value *domain.SSOState, ttl time.Duration) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, ssoStatePrefix+state, b, ttl).Err()
+}
+
+// Take uses GETDEL so a state is redeemed at most once even when the
+// callback is replayed concurrently.
+func (s *SSOStateStore) Take(ctx context.Context, state string) (*domain.SSOState, error) {
+	b, err := s.client.GetDel(ctx, ssoStatePrefix+state).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, domain.ErrInvalidSSOState
+		}
+		return nil, err
+	}
+	var value domain.SSOState
+	if err := json.Unmarshal(b, &value); err != nil {
+		return nil, domain.ErrInvalidSSOState
+	}
+	return &value, nil
+}
@@ -0,0 +1,325 @@
+package http
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/auth/application"
+	"github.com/indalyadav56/logify/apps/backend/internal/auth/domain"
+	"github.com/indalyadav56/logify/apps/backend/pkg/response"
+	"github.com/indalyadav56/logify/apps/backend/pkg/validator"
+)
+
+// AccessTokenHandler serves personal access tokens and service accounts.
+type AccessTokenHandler interface {
+	CreateToken(c *gin.Context)
+	ListTokens(c *gin.Context)
+	RevokeToken(c *gin.Context)
+
+	CreateServiceAccount(c *gin.Context)
+	ListServiceAccounts(c *gin.Context)
+	GetServiceAccount(c *gin.Context)
+	UpdateServiceAccount(c *gin.Context)
+	DeleteServiceAccount(c *gin.Context)
+	CreateServiceAccountToken(c *gin.Context)
+	ListServiceAccountTokens(c *gin.Context)
+	RevokeServiceAccountToken(c *gin.Context)
+}
+
+type accessTokenHandler struct {
+	tokens   application.AccessTokenService
+	accounts application.ServiceAccountService
+}
+
+func NewAccessTokenHandler(tokens application.AccessTokenService, accounts application.ServiceAccountService) AccessTokenHandler {
+	return &accessTokenHandler{tokens: tokens, accounts: accounts}
+}
+
+// CreateToken handles POST /v1/auth/tokens
+// @Summary      Create a personal access token
+// @Description  Mint a token that acts as the caller in their current tenant, limited to the given permission scopes. The token is only returned in this response. Requests authenticated with an access token cannot create tokens.
+// @Tags         access-tokens
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      application.CreateAccessTokenInput  true  "Token payload"
+// @Success      201      {object}  response.APIResponse{data=application.CreatedAccessTokenOutput} "Access token created successfully"
+// @Failure      400      {object}  response.APIResponse "Invalid scopes or expiry"
+// @Failure      403      {object}  response.APIResponse "Access tokens cannot create access tokens"
+// @Router       /v1/auth/tokens [post]
+func (h *accessTokenHandler) CreateToken(c *gin.Context) {
+	var input application.CreateAccessTokenInput
+	if !validator.ValidateRequest(c, &input) {
+		return
+	}
+	out, err := h.tokens.CreateToken(c.Request.Context(), input)
+	if err != nil {
+		writeAccessTokenError(c, err, "Failed to create access token")
+		return
+	}
+	response.Created(c, "Access token created successfully", out)
+}
+
+// ListTokens handles GET /v1/auth/tokens
+// @Summary      List personal access tokens
+// @Description  Return the caller's tokens, newest first, including revoked and expired ones.
+// @Tags         access-tokens
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.APIResponse{data=[]application.AccessTokenOutput} "Access tokens retrieved successfully"
+// @Router       /v1/auth/tokens [get]
+func (h *accessTokenHandler) ListTokens(c *gin.Context) {
+	out, err := h.tokens.ListTokens(c.Request.Context())
+	if err != nil {
+		writeAccessTokenError(c, err, "Failed to list access tokens")
+		return
+	}
+	response.OK(c, "Access tokens retrieved successfully", out)
+}
+
+// RevokeToken handles DELETE /v1/auth/tokens/:id
+// @Summary      Revoke a personal access token
+// @Tags         access-tokens
+// @Security     BearerAuth
+// @Param        id   path  string  true  "Token ID (UUID)"
+// @Success      204  "No content"
+// @Failure      404  {object}  response.APIResponse "Access token not found"
+// @Router       /v1/auth/tokens/{id} [delete]
+func (h *accessTokenHandler) RevokeToken(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.tokens.RevokeToken(c.Request.Context(), id); err != nil {
+		writeAccessTokenError(c, err, "Failed to revoke access token")
+		return
+	}
+	response.NoContent(c)
+}
+
+// CreateServiceAccount handles POST /v1/service-accounts
+// @Summary      Create a service account
+// @Description  Create a non-human principal of the caller's tenant holding the given tenant-wide role. Owner cannot be assigned.
+// @Tags         service-accounts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      application.CreateServiceAccountInput  true  "Service account payload"
+// @Success      201      {object}  response.APIResponse{data=application.ServiceAccountOutput} "Service account created successfully"
+// @Failure      400      {object}  response.APIResponse "Invalid request or role"
+// @Failure      409      {object}  response.APIResponse "Name already in use"
+// @Router       /v1/service-accounts [post]
+func (h *accessTokenHandler) CreateServiceAccount(c *gin.Context) {
+	var input application.CreateServiceAccountInput
+	if !validator.ValidateRequest(c, &input) {
+		return
+	}
+	out, err := h.accounts.Create(c.Request.Context(), input)
+	if err != nil {
+		writeAccessTokenError(c, err, "Failed to create service account")
+		return
+	}
+	response.Created(c, "Service account created successfully", out)
+}
+
+// ListServiceAccounts handles GET /v1/service-accounts
+// @Summary      List service accounts
+// @Tags         service-accounts
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.APIResponse{data=[]application.ServiceAccountOutput} "Service accounts retrieved successfully"
+// @Router       /v1/service-accounts [get]
+func (h *accessTokenHandler) ListServiceAccounts(c *gin.Context) {
+	out, err := h.accounts.List(c.Request.Context())
+	if err != nil {
+		writeAccessTokenError(c, err, "Failed to list service accounts")
+		return
+	}
+	response.OK(c, "Service accounts retrieved successfully", out)
+}
+
+// GetServiceAccount handles GET /v1/service-accounts/:id
+// @Summary      Get a service account
+// @Tags         service-accounts
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Service account ID (UUID)"
+// @Success      200  {object}  response.APIResponse{data=application.ServiceAccountOutput} "Service account retrieved successfully"
+// @Failure      404  {object}  response.APIResponse "Service account not found"
+// @Router       /v1/service-accounts/{id} [get]
+func (h *accessTokenHandler) GetServiceAccount(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	out, err := h.accounts.Get(c.Request.Context(), id)
+	if err != nil {
+		writeAccessTokenError(c, err, "Failed to get service account")
+		return
+	}
+	response.OK(c, "Service account retrieved successfully", out)
+}
+
+// UpdateServiceAccount handles PATCH /v1/service-accounts/:id
+// @Summary      Update a service account
+// @Description  Rename, change the role of, disable or re-enable a service account. A disabled account's tokens stop working until it is enabled again.
+// @Tags         service-accounts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                                 true  "Service account ID (UUID)"
+// @Param        request  body      application.UpdateServiceAccountInput  true  "Fields to change"
+// @Success      200      {object}  response.APIResponse{data=application.ServiceAccountOutput} "Service account updated successfully"
+// @Failure      400      {object}  response.APIResponse "Invalid request or role"
+// @Failure      404      {object}  response.APIResponse "Service account not found"
+// @Failure      409      {object}  response.APIResponse "Name already in use"
+// @Router       /v1/service-accounts/{id} [patch]
+func (h *accessTokenHandler) UpdateServiceAccount(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var input application.UpdateServiceAccountInput
+	if !validator.ValidateRequest(c, &input) {
+		return
+	}
+	out, err := h.accounts.Update(c.Request.Context(), id, input)
+	if err != nil {
+		writeAccessTokenError(c, err, "Failed to update service account")
+		return
+	}
+	response.OK(c, "Service account updated successfully", out)
+}
+
+// DeleteServiceAccount handles DELETE /v1/service-accounts/:id
+// @Summary      Delete a service account
+// @Description  Delete the account together with its tokens.
+// @Tags         service-accounts
+// @Security     BearerAuth
+// @Param        id   path  string  true  "Service account ID (UUID)"
+// @Success      204  "No content"
+// @Failure      404  {object}  response.APIResponse "Service account not found"
+// @Router       /v1/service-accounts/{id} [delete]
+func (h *accessTokenHandler) DeleteServiceAccount(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.accounts.Delete(c.Request.Context(), id); err != nil {
+		writeAccessTokenError(c, err, "Failed to delete service account")
+		return
+	}
+	response.NoContent(c)
+}
+
+// CreateServiceAccountToken handles POST /v1/service-accounts/:id/tokens
+// @Summary      Create a service account token
+// @Description  Mint a token acting as the service account. The token is only returned in this response.
+// @Tags         service-accounts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                              true  "Service account ID (UUID)"
+// @Param        request  body      application.CreateAccessTokenInput  true  "Token payload"
+// @Success      201      {object}  response.APIResponse{data=application.CreatedAccessTokenOutput} "Access token created successfully"
+// @Failure      400      {object}  response.APIResponse "Invalid scopes or expiry"
+// @Failure      403      {object}  response.APIResponse "Access tokens cannot create access tokens"
+// @Failure      404      {object}  response.APIResponse "Service account not found"
+// @Router       /v1/service-accounts/{id}/tokens [post]
+func (h *accessTokenHandler) CreateServiceAccountToken(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var input application.CreateAccessTokenInput
+	if !validator.ValidateRequest(c, &input) {
+		return
+	}
+	out, err := h.accounts.CreateToken(c.Request.Context(), id, input)
+	if err != nil {
+		writeAccessTokenError(c, err, "Failed to create access token")
+		return
+	}
+	response.Created(c, "Access token created successfully", out)
+}
+
+// ListServiceAccountTokens handles GET /v1/service-accounts/:id/tokens
+// @Summary      List service account tokens
+// @Tags         service-accounts
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Service account ID (UUID)"
+// @Success      200  {object}  response.APIResponse{data=[]application.AccessTokenOutput} "Access tokens retrieved successfully"
+// @Failure      404  {object}  response.APIResponse "Service account not found"
+// @Router       /v1/service-accounts/{id}/tokens [get]
+func (h *accessTokenHandler) ListServiceAccountTokens(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	out, err := h.accounts.ListTokens(c.Request.Context(), id)
+	if err != nil {
+		writeAccessTokenError(c, err, "Failed to list access tokens")
+		return
+	}
+	response.OK(c, "Access tokens retrieved successfully", out)
+}
+
+// RevokeServiceAccountToken handles DELETE /v1/service-accounts/:id/tokens/:token_id
+// @Summary      Revoke a service account token
+// @Tags         service-accounts
+// @Security     BearerAuth
+// @Param        id        path  string  true  "Service account ID (UUID)"
+// @Param        token_id  path  string  true  "Token ID (UUID)"
+// @Success      204  "No content"
+// @Failure      404  {object}  response.APIResponse "Service account or access token not found"
+// @Router       /v1/service-accounts/{id}/tokens/{token_id} [delete]
+func (h *accessTokenHandler) RevokeServiceAccountToken(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	tokenID, ok := parseUUIDParam(c, "token_id")
+	if !ok {
+		return
+	}
+	if err := h.accounts.RevokeToken(c.Request.Context(), id, tokenID); err != nil {
+		writeAccessTokenError(c, err, "Failed to revoke access token")
+		return
+	}
+	response.NoContent(c)
+}
+
+func writeAccessTokenError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrNotAuthenticated):
+		response.Unauthorized(c, "User not authenticated")
+	case errors.Is(err, domain.ErrTokenCannotMintTokens):
+		response.Forbidden(c, "Access tokens cannot create access tokens; sign in to create one")
+	case errors.Is(err, domain.ErrInvalidTokenScope):
+		response.BadRequest(c, "Scopes must be permission keys such as log.read")
+	case errors.Is(err, domain.ErrInvalidTokenExpiry):
+		response.BadRequest(c, "expires_in_days must be between 1 and 365")
+	case errors.Is(err, domain.ErrAccessTokenNotFound):
+		response.NotFound(c, "Access token not found")
+	case errors.Is(err, domain.ErrServiceAccountNotFound):
+		response.NotFound(c, "Service account not found")
+	case errors.Is(err, domain.ErrServiceAccountExists):
+		response.Conflict(c, "A service account with this name already exists")
+	case errors.Is(err, domain.ErrRoleNotAssignable):
+		response.BadRequest(c, "Role cannot be assigned to service accounts")
+	default:
+		response.InternalServerError(c, fallback)
+	}
+}
+
+func parseUUIDParam(c *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		response.BadRequest(c, "Invalid "+name+" format")
+		return uuid.Nil, false
+	}
+	return id, true
+}
@@ -13,15 +13,42 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+// SSOCallbackRequest carries the query parameters of the identity provider
+// redirect.
+type SSOCallbackRequest struct {
+	Code  string `json:"code"  binding:"required"`
+	State string `json:"state" binding:"required"`
+}
+
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code"      binding:"required"`
+}
+
+type MFAChallengeRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+}
+
+// MFACodeRequest confirms an MFA change with a TOTP or recovery code.
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
 type RefreshRequest struct {
 	RefreshToken string `json:"refresh_token" binding:"required"`
 }
 
-// TokenResponse mirrors application.TokenOutput.
+// TokenResponse mirrors application.TokenOutput. RefreshToken is empty
+// after a tenant switch, which keeps the session's refresh token. A login
+// that needs a second factor carries only the MFA fields.
 type TokenResponse struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
-	TokenType    string `json:"token_type"`
+	AccessToken           string   `json:"access_token,omitempty"`
+	RefreshToken          string   `json:"refresh_token,omitempty"`
+	TokenType             string   `json:"token_type,omitempty"`
+	MFARequired           bool     `json:"mfa_required,omitempty"`
+	MFAToken              string   `json:"mfa_token,omitempty"`
+	MFAEnrollmentRequired bool     `json:"mfa_enrollment_required,omitempty"`
+	RecoveryCodes         []string `json:"recovery_codes,omitempty"`
 }
 
 type UserResponse struct {
@@ -32,11 +59,13 @@ type UserResponse struct {
 }
 
 type SessionResponse struct {
-	ID        string    `json:"id"`
-	IPAddress string    `json:"ip_address"`
-	UserAgent string    `json:"user_agent"`
-	CreatedAt time.Time `json:"created_at"`
-	Current   bool      `json:"current"`
+	ID         string     `json:"id"`
+	IPAddress  string     `json:"ip_address"`
+	UserAgent  string     `json:"user_agent"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	Current    bool       `json:"current"`
 }
 
 type ErrorResponse struct {
@@ -52,6 +81,10 @@ type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" binding:"required"`
 }
 
+type SwitchTenantRequest struct {
+	TenantID string `json:"tenant_id" binding:"required"`
+}
+
 type LogoutRequest struct {
 	RefreshToken string `json:"refresh_token" binding:"required"`
 }
@@ -2,12 +2,15 @@ package http
 
 import (
 	"errors"
+	"net"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 
 	"github.com/indalyadav56/logify/apps/backend/internal/auth/application"
 	"github.com/indalyadav56/logify/apps/backend/internal/auth/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
 	"github.com/indalyadav56/logify/apps/backend/pkg/response"
 )
 
@@ -42,6 +45,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		FullName: req.FullName,
 		Email:    req.Email,
 		Password: req.Password,
+		Client:   clientInput(c),
 	})
 	if err != nil {
 		switch {
@@ -62,7 +66,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 // Login handles user authentication.
 // @Summary      Login a user
-// @Description  Authenticate user credentials and return JWT tokens.
+// @Description  Authenticate user credentials and return JWT tokens. When the user has MFA enabled, or a tenant of theirs requires it, no tokens are issued; the response carries an mfa_token to complete at /v1/auth/mfa/verify.
 // @Tags         auth
 // @Accept       json
 // @Produce      json
@@ -70,6 +74,8 @@ func (h *AuthHandler) Register(c *gin.Context) {
 // @Success      200      {object}  response.APIResponse{data=TokenResponse} "Successfully authenticated"
 // @Failure      400      {object}  response.APIResponse{error=string} "Invalid input format"
 // @Failure      401      {object}  response.APIResponse{error=string} "Invalid credentials"
+// @Failure      403      {object}  response.APIResponse{error=string} "Password login disabled by SSO enforcement"
+// @Failure      429      {object}  response.APIResponse{error=string} "Too many failed attempts"
 // @Failure      500      {object}  response.APIResponse{error=string} "Internal server error"
 // @Router       /v1/auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
@@ -82,11 +88,16 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	tokens, err := h.svc.Login(c.Request.Context(), application.LoginInput{
 		Email:    req.Email,
 		Password: req.Password,
+		Client:   clientInput(c),
 	})
 	if err != nil {
 		switch {
 		case errors.Is(err, domain.ErrInvalidCredentials):
 			response.Unauthorized(c, "Invalid email or password")
+		case errors.Is(err, domain.ErrSSORequired):
+			response.Forbidden(c, "Password login is disabled for your organization; sign in with SSO")
+		case errors.Is(err, domain.ErrAccountLocked):
+			tooManyAttempts(c)
 		default:
 			response.InternalServerError(c, "Failed to authenticate")
 		}
@@ -98,13 +109,15 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 // RefreshToken handles refreshing JWT access tokens.
 // @Summary      Refresh token
-// @Description  Generate a new access token using a valid refresh token.
+// @Description  Rotate a refresh token and return a new access/refresh token pair. Re-using a rotated token revokes the whole session.
 // @Tags         auth
 // @Accept       json
 // @Produce      json
 // @Param        request  body      RefreshTokenRequest  true  "Refresh token payload"
 // @Success      200      {object}  response.APIResponse{data=TokenResponse} "Token refreshed successfully"
 // @Failure      400      {object}  response.APIResponse{error=string} "Invalid request body"
+// @Failure      401      {object}  response.APIResponse{error=string} "Refresh token invalid, expired, reused or revoked"
+// @Failure      500      {object}  response.APIResponse{error=string} "Internal server error"
 // @Router       /v1/auth/refresh-token [post]
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	var req RefreshTokenRequest
@@ -112,6 +125,27 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		response.BadRequest(c, err.Error())
 		return
 	}
+
+	tokens, err := h.svc.Refresh(c.Request.Context(), application.RefreshInput{
+		RefreshToken: req.RefreshToken,
+		Client:       clientInput(c),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrTokenNotFound),
+			errors.Is(err, domain.ErrTokenExpired),
+			errors.Is(err, domain.ErrTokenAlreadyUsed),
+			errors.Is(err, domain.ErrSessionNotFound),
+			errors.Is(err, domain.ErrSessionExpired),
+			errors.Is(err, domain.ErrSessionRevoked):
+			response.Unauthorized(c, "Invalid or expired refresh token")
+		default:
+			response.InternalServerError(c, "Failed to refresh token")
+		}
+		return
+	}
+
+	response.OK(c, "Token refreshed successfully", toTokenResponse(tokens))
 }
 
 // Logout invalidates a user session.
@@ -123,6 +157,7 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 // @Param        request  body      LogoutRequest  true  "Logout payload"
 // @Success      200      {object}  response.APIResponse "Logged out successfully"
 // @Failure      400      {object}  response.APIResponse{error=string} "Invalid request body"
+// @Failure      500      {object}  response.APIResponse{error=string} "Internal server error"
 // @Router       /v1/auth/logout [post]
 func (h *AuthHandler) Logout(c *gin.Context) {
 	var req LogoutRequest
@@ -130,23 +165,266 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 		response.BadRequest(c, err.Error())
 		return
 	}
-	// err := h.svc.Logout(c.Request.Context(), application.LogoutInput{
-	// 	RefreshToken: req.RefreshToken,
-	// })
-	// if err != nil {
-	// 	response.InternalServerError(c, "Failed to logout")
-	// 	return
-	// }
+	err := h.svc.Logout(c.Request.Context(), application.LogoutInput{
+		RefreshToken: req.RefreshToken,
+	})
+	if err != nil {
+		response.InternalServerError(c, "Failed to logout")
+		return
+	}
 	response.OK(c, "Logout successful", nil)
 }
 
+// ListSessions returns the caller's active sessions.
+// @Summary      List sessions
+// @Description  Return the authenticated user's active sessions; the one backing the current token is flagged.
+// @Tags         auth
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.APIResponse{data=[]SessionResponse} "Sessions retrieved successfully"
+// @Failure      401  {object}  response.APIResponse{error=string} "User not authenticated"
+// @Failure      500  {object}  response.APIResponse{error=string} "Internal server error"
+// @Router       /v1/auth/sessions [get]
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	userID, ok := middleware.UserUUIDFromContext(c.Request.Context())
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	current, _ := middleware.SessionUUIDFromContext(c.Request.Context())
+
+	sessions, err := h.svc.ListSessions(c.Request.Context(), userID, current)
+	if err != nil {
+		response.InternalServerError(c, "Failed to list sessions")
+		return
+	}
+
+	out := make([]SessionResponse, len(sessions))
+	for i, s := range sessions {
+		out[i] = toSessionResponse(s)
+	}
+	response.OK(c, "Sessions retrieved successfully", out)
+}
+
+// RevokeSession revokes one of the caller's sessions.
+// @Summary      Revoke session
+// @Description  Revoke a single session and every refresh token issued for it.
+// @Tags         auth
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Session ID (UUID)"
+// @Success      204  "No content"
+// @Failure      400  {object}  response.APIResponse{error=string} "Invalid id format"
+// @Failure      401  {object}  response.APIResponse{error=string} "User not authenticated"
+// @Failure      404  {object}  response.APIResponse{error=string} "Session not found"
+// @Failure      500  {object}  response.APIResponse{error=string} "Internal server error"
+// @Router       /v1/auth/sessions/{id} [delete]
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	userID, ok := middleware.UserUUIDFromContext(c.Request.Context())
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid id format")
+		return
+	}
+
+	if err := h.svc.RevokeSession(c.Request.Context(), userID, sessionID); err != nil {
+		if errors.Is(err, domain.ErrSessionNotFound) {
+			response.NotFound(c, "Session not found")
+			return
+		}
+		response.InternalServerError(c, "Failed to revoke session")
+		return
+	}
+	response.NoContent(c)
+}
+
+// RevokeAllSessions logs the caller out everywhere.
+// @Summary      Revoke all sessions
+// @Description  Revoke every session of the authenticated user, including the current one.
+// @Tags         auth
+// @Produce      json
+// @Security     BearerAuth
+// @Success      204  "No content"
+// @Failure      401  {object}  response.APIResponse{error=string} "User not authenticated"
+// @Failure      500  {object}  response.APIResponse{error=string} "Internal server error"
+// @Router       /v1/auth/sessions [delete]
+func (h *AuthHandler) RevokeAllSessions(c *gin.Context) {
+	userID, ok := middleware.UserUUIDFromContext(c.Request.Context())
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	if err := h.svc.RevokeAllSessions(c.Request.Context(), userID); err != nil {
+		response.InternalServerError(c, "Failed to revoke sessions")
+		return
+	}
+	response.NoContent(c)
+}
+
+// SwitchTenant points the caller's session at another tenant.
+// @Summary      Switch tenant
+// @Description  Act in another tenant: the caller's own, or one where they are an active project member. Returns an access token for that tenant; the session's refresh token stays valid and later refreshes keep the tenant.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      SwitchTenantRequest  true  "Target tenant"
+// @Success      200      {object}  response.APIResponse{data=TokenResponse} "Tenant switched successfully"
+// @Failure      400      {object}  response.APIResponse{error=string} "Invalid request body"
+// @Failure      401      {object}  response.APIResponse{error=string} "User not authenticated or session revoked"
+// @Failure      403      {object}  response.APIResponse{error=string} "Not a member of the tenant"
+// @Failure      500      {object}  response.APIResponse{error=string} "Internal server error"
+// @Router       /v1/auth/switch-tenant [post]
+func (h *AuthHandler) SwitchTenant(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID, ok := middleware.UserUUIDFromContext(ctx)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	sessionID, ok := middleware.SessionUUIDFromContext(ctx)
+	if !ok {
+		response.Unauthorized(c, "Token is not bound to a session")
+		return
+	}
+	var req SwitchTenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	tenantID, err := uuid.Parse(req.TenantID)
+	if err != nil {
+		response.BadRequest(c, "Invalid tenant_id format")
+		return
+	}
+
+	tokens, err := h.svc.SwitchTenant(ctx, application.SwitchTenantInput{
+		UserID:    userID,
+		SessionID: sessionID,
+		TenantID:  tenantID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotTenantMember):
+			response.Forbidden(c, "You are not a member of this tenant")
+		case errors.Is(err, domain.ErrSessionNotFound):
+			response.Unauthorized(c, "Session revoked")
+		default:
+			response.InternalServerError(c, "Failed to switch tenant")
+		}
+		return
+	}
+	response.OK(c, "Tenant switched successfully", toTokenResponse(tokens))
+}
+
+// clientInput captures the caller's address and user agent for the session
+// record. gin's ClientIP honours the engine's trusted-proxy settings.
+// SSOAuthorize starts a single sign-on login.
+// @Summary      Start SSO login
+// @Description  Create a pending OIDC login (state, nonce and PKCE verifier are kept server-side) and return the identity provider URL to send the user to.
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object}  response.APIResponse{data=application.SSOAuthorizeOutput} "Authorization URL created"
+// @Failure      404  {object}  response.APIResponse{error=string} "SSO is not configured"
+// @Failure      502  {object}  response.APIResponse{error=string} "Identity provider unavailable"
+// @Failure      500  {object}  response.APIResponse{error=string} "Internal server error"
+// @Router       /v1/auth/sso/authorize [post]
+func (h *AuthHandler) SSOAuthorize(c *gin.Context) {
+	out, err := h.svc.SSOAuthorize(c.Request.Context())
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrSSODisabled):
+			response.NotFound(c, "SSO is not configured")
+		case errors.Is(err, domain.ErrSSOFailed):
+			response.Error(c, http.StatusBadGateway, "BAD_GATEWAY", "Identity provider unavailable")
+		default:
+			response.InternalServerError(c, "Failed to start SSO login")
+		}
+		return
+	}
+	response.OK(c, "Authorization URL created", out)
+}
+
+// SSOCallback completes a single sign-on login.
+// @Summary      Complete SSO login
+// @Description  Exchange the code and state the identity provider redirected back with for JWT tokens. The account is provisioned on first login and project memberships follow the identity provider groups.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      SSOCallbackRequest  true  "Code and state from the provider redirect"
+// @Success      200      {object}  response.APIResponse{data=TokenResponse} "Successfully authenticated"
+// @Failure      400      {object}  response.APIResponse{error=string} "Invalid request body or expired login"
+// @Failure      401      {object}  response.APIResponse{error=string} "Identity provider rejected the login"
+// @Failure      403      {object}  response.APIResponse{error=string} "Account disabled or email not verified"
+// @Failure      404      {object}  response.APIResponse{error=string} "SSO is not configured"
+// @Failure      500      {object}  response.APIResponse{error=string} "Internal server error"
+// @Router       /v1/auth/sso/callback [post]
+func (h *AuthHandler) SSOCallback(c *gin.Context) {
+	var req SSOCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	tokens, err := h.svc.SSOCallback(c.Request.Context(), application.SSOCallbackInput{
+		Code:   req.Code,
+		State:  req.State,
+		Client: clientInput(c),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrSSODisabled):
+			response.NotFound(c, "SSO is not configured")
+		case errors.Is(err, domain.ErrInvalidSSOState):
+			response.BadRequest(c, "SSO login expired or already used; start again")
+		case errors.Is(err, domain.ErrSSOFailed):
+			response.Unauthorized(c, "SSO login failed")
+		case errors.Is(err, domain.ErrSSOEmailUnverified):
+			response.Forbidden(c, "The identity provider did not supply a verified email address")
+		case errors.Is(err, domain.ErrAccountDisabled):
+			response.Forbidden(c, "Account is disabled")
+		default:
+			response.InternalServerError(c, "Failed to complete SSO login")
+		}
+		return
+	}
+	response.OK(c, "Login successful", toTokenResponse(tokens))
+}
+
+func clientInput(c *gin.Context) application.ClientInput {
+	return application.ClientInput{
+		IPAddress: net.ParseIP(c.ClientIP()),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
+
+func toSessionResponse(s *application.SessionOutput) SessionResponse {
+	return SessionResponse{
+		ID:         s.ID.String(),
+		IPAddress:  s.IPAddress,
+		UserAgent:  s.UserAgent,
+		CreatedAt:  s.CreatedAt,
+		LastUsedAt: s.LastUsed,
+		ExpiresAt:  s.ExpiresAt,
+		Current:    s.Current,
+	}
+}
+
 func toTokenResponse(t *application.TokenOutput) TokenResponse {
 	if t == nil {
 		return TokenResponse{}
 	}
 	return TokenResponse{
-		AccessToken:  t.AccessToken,
-		RefreshToken: t.RefreshToken,
-		TokenType:    t.TokenType,
+		AccessToken:           t.AccessToken,
+		RefreshToken:          t.RefreshToken,
+		TokenType:             t.TokenType,
+		MFARequired:           t.MFARequired,
+		MFAToken:              t.MFAToken,
+		MFAEnrollmentRequired: t.MFAEnrollmentRequired,
+		RecoveryCodes:         t.RecoveryCodes,
 	}
 }
@@ -0,0 +1,297 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/auth/application"
+	"github.com/indalyadav56/logify/apps/backend/internal/auth/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
+	userDomain "github.com/indalyadav56/logify/apps/backend/internal/user/domain"
+	"github.com/indalyadav56/logify/apps/backend/pkg/response"
+)
+
+// VerifyMFA completes a password login with the second factor.
+// @Summary      Verify MFA
+// @Description  Exchange the mfa_token returned by login and a TOTP or recovery code for tokens. For a challenge requiring enrolment the code must come from the secret of /v1/auth/mfa/challenge/enroll; MFA is then enabled and the response carries the recovery codes.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      MFAVerifyRequest  true  "Challenge and code"
+// @Success      200      {object}  response.APIResponse{data=TokenResponse} "Successfully authenticated"
+// @Failure      400      {object}  response.APIResponse{error=string} "Invalid input format or enrolment not started"
+// @Failure      401      {object}  response.APIResponse{error=string} "Invalid code or expired challenge"
+// @Failure      404      {object}  response.APIResponse{error=string} "MFA is not configured"
+// @Failure      429      {object}  response.APIResponse{error=string} "Too many failed attempts"
+// @Failure      500      {object}  response.APIResponse{error=string} "Internal server error"
+// @Router       /v1/auth/mfa/verify [post]
+func (h *AuthHandler) VerifyMFA(c *gin.Context) {
+	var req MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	tokens, err := h.svc.VerifyMFA(c.Request.Context(), application.VerifyMFAInput{
+		MFAToken: req.MFAToken,
+		Code:     req.Code,
+		Client:   clientInput(c),
+	})
+	if err != nil {
+		writeMFAError(c, err, "Failed to verify MFA")
+		return
+	}
+	response.OK(c, "Login successful", toTokenResponse(tokens))
+}
+
+// StartChallengeEnrollment issues a TOTP secret during login.
+// @Summary      Enrol MFA during login
+// @Description  For a login whose tenant requires MFA and who has none yet, issue a TOTP secret authenticated by the mfa_token. Finish with /v1/auth/mfa/verify.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      MFAChallengeRequest  true  "Login challenge"
+// @Success      200      {object}  response.APIResponse{data=application.MFAEnrollmentOutput} "Enrolment started"
+// @Failure      400      {object}  response.APIResponse{error=string} "Invalid input format"
+// @Failure      401      {object}  response.APIResponse{error=string} "Expired challenge"
+// @Failure      404      {object}  response.APIResponse{error=string} "MFA is not configured"
+// @Failure      409      {object}  response.APIResponse{error=string} "MFA already enabled"
+// @Failure      500      {object}  response.APIResponse{error=string} "Internal server error"
+// @Router       /v1/auth/mfa/challenge/enroll [post]
+func (h *AuthHandler) StartChallengeEnrollment(c *gin.Context) {
+	var req MFAChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	out, err := h.svc.StartChallengeEnrollment(c.Request.Context(), req.MFAToken)
+	if err != nil {
+		writeMFAError(c, err, "Failed to start MFA enrolment")
+		return
+	}
+	response.OK(c, "MFA enrolment started", out)
+}
+
+// MFAStatus reports the caller's second factor.
+// @Summary      MFA status
+// @Description  Return whether MFA is enabled for the authenticated user and how many recovery codes are left.
+// @Tags         auth
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.APIResponse{data=application.MFAStatusOutput} "MFA status retrieved"
+// @Failure      401  {object}  response.APIResponse{error=string} "User not authenticated"
+// @Failure      404  {object}  response.APIResponse{error=string} "MFA is not configured"
+// @Failure      500  {object}  response.APIResponse{error=string} "Internal server error"
+// @Router       /v1/auth/mfa [get]
+func (h *AuthHandler) MFAStatus(c *gin.Context) {
+	userID, ok := middleware.UserUUIDFromContext(c.Request.Context())
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	out, err := h.svc.MFAStatus(c.Request.Context(), userID)
+	if err != nil {
+		writeMFAError(c, err, "Failed to get MFA status")
+		return
+	}
+	response.OK(c, "MFA status retrieved", out)
+}
+
+// StartMFAEnrollment issues a TOTP secret to the caller.
+// @Summary      Start MFA enrolment
+// @Description  Issue a new TOTP secret and provisioning URI (render it as a QR code). MFA is enabled once /v1/auth/mfa/enable verifies a code from it.
+// @Tags         auth
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.APIResponse{data=application.MFAEnrollmentOutput} "Enrolment started"
+// @Failure      401  {object}  response.APIResponse{error=string} "User not authenticated or token not bound to a session"
+// @Failure      404  {object}  response.APIResponse{error=string} "MFA is not configured"
+// @Failure      409  {object}  response.APIResponse{error=string} "MFA already enabled"
+// @Failure      500  {object}  response.APIResponse{error=string} "Internal server error"
+// @Router       /v1/auth/mfa/enroll [post]
+func (h *AuthHandler) StartMFAEnrollment(c *gin.Context) {
+	userID, ok := sessionUser(c)
+	if !ok {
+		return
+	}
+
+	out, err := h.svc.StartMFAEnrollment(c.Request.Context(), userID)
+	if err != nil {
+		writeMFAError(c, err, "Failed to start MFA enrolment")
+		return
+	}
+	response.OK(c, "MFA enrolment started", out)
+}
+
+// EnableMFA turns on the caller's pending enrolment.
+// @Summary      Enable MFA
+// @Description  Verify a code from the secret of /v1/auth/mfa/enroll and enable MFA. The recovery codes are shown only in this response.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      MFACodeRequest  true  "TOTP code"
+// @Success      200      {object}  response.APIResponse{data=application.RecoveryCodesOutput} "MFA enabled"
+// @Failure      400      {object}  response.APIResponse{error=string} "Invalid input format or enrolment not started"
+// @Failure      401      {object}  response.APIResponse{error=string} "Invalid code"
+// @Failure      409      {object}  response.APIResponse{error=string} "MFA already enabled"
+// @Failure      429      {object}  response.APIResponse{error=string} "Too many failed attempts"
+// @Failure      500      {object}  response.APIResponse{error=string} "Internal server error"
+// @Router       /v1/auth/mfa/enable [post]
+func (h *AuthHandler) EnableMFA(c *gin.Context) {
+	input, ok := mfaCodeInput(c)
+	if !ok {
+		return
+	}
+
+	out, err := h.svc.EnableMFA(c.Request.Context(), input)
+	if err != nil {
+		writeMFAError(c, err, "Failed to enable MFA")
+		return
+	}
+	response.OK(c, "MFA enabled", out)
+}
+
+// DisableMFA turns off the caller's second factor.
+// @Summary      Disable MFA
+// @Description  Remove the authenticated user's second factor and recovery codes, confirmed with a current code. Logins to tenants that require MFA will ask to enrol again.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      MFACodeRequest  true  "TOTP or recovery code"
+// @Success      204  "No content"
+// @Failure      400  {object}  response.APIResponse{error=string} "Invalid input format or MFA not enabled"
+// @Failure      401  {object}  response.APIResponse{error=string} "Invalid code"
+// @Failure      429  {object}  response.APIResponse{error=string} "Too many failed attempts"
+// @Failure      500  {object}  response.APIResponse{error=string} "Internal server error"
+// @Router       /v1/auth/mfa/disable [post]
+func (h *AuthHandler) DisableMFA(c *gin.Context) {
+	input, ok := mfaCodeInput(c)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.DisableMFA(c.Request.Context(), input); err != nil {
+		writeMFAError(c, err, "Failed to disable MFA")
+		return
+	}
+	response.NoContent(c)
+}
+
+// RegenerateRecoveryCodes replaces the caller's recovery codes.
+// @Summary      Regenerate recovery codes
+// @Description  Invalidate every recovery code and issue a new set, confirmed with a current code.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      MFACodeRequest  true  "TOTP or recovery code"
+// @Success      200      {object}  response.APIResponse{data=application.RecoveryCodesOutput} "Recovery codes regenerated"
+// @Failure      400      {object}  response.APIResponse{error=string} "Invalid input format or MFA not enabled"
+// @Failure      401      {object}  response.APIResponse{error=string} "Invalid code"
+// @Failure      429      {object}  response.APIResponse{error=string} "Too many failed attempts"
+// @Failure      500      {object}  response.APIResponse{error=string} "Internal server error"
+// @Router       /v1/auth/mfa/recovery-codes [post]
+func (h *AuthHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	input, ok := mfaCodeInput(c)
+	if !ok {
+		return
+	}
+
+	out, err := h.svc.RegenerateRecoveryCodes(c.Request.Context(), input)
+	if err != nil {
+		writeMFAError(c, err, "Failed to regenerate recovery codes")
+		return
+	}
+	response.OK(c, "Recovery codes regenerated", out)
+}
+
+// ResetMFA removes a user's second factor.
+// @Summary      Reset a user's MFA
+// @Description  Remove the user's second factor, recovery codes and MFA lockout so they can sign in with their password and enrol again. Requires platform.users:manage.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "User ID (UUID)"
+// @Success      204  "No content"
+// @Failure      400  {object}  response.APIResponse{error=string} "Invalid id format"
+// @Failure      403  {object}  response.APIResponse{error=string} "Permission denied"
+// @Failure      404  {object}  response.APIResponse{error=string} "User not found"
+// @Failure      500  {object}  response.APIResponse{error=string} "Internal server error"
+// @Router       /v1/admin/users/{id}/mfa [delete]
+func (h *AuthHandler) ResetMFA(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid id format")
+		return
+	}
+
+	if err := h.svc.ResetMFA(c.Request.Context(), userID); err != nil {
+		if errors.Is(err, userDomain.ErrUserNotFound) {
+			response.NotFound(c, "User not found")
+			return
+		}
+		writeMFAError(c, err, "Failed to reset MFA")
+		return
+	}
+	response.NoContent(c)
+}
+
+// sessionUser returns the caller of a route that changes their second
+// factor. Access tokens are refused: a leaked token must not be able to
+// take over the account's MFA.
+func sessionUser(c *gin.Context) (uuid.UUID, bool) {
+	ctx := c.Request.Context()
+	userID, ok := middleware.UserUUIDFromContext(ctx)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return uuid.Nil, false
+	}
+	if _, ok := middleware.SessionUUIDFromContext(ctx); !ok {
+		response.Unauthorized(c, "Token is not bound to a session")
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+func mfaCodeInput(c *gin.Context) (application.MFACodeInput, bool) {
+	userID, ok := sessionUser(c)
+	if !ok {
+		return application.MFACodeInput{}, false
+	}
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return application.MFACodeInput{}, false
+	}
+	return application.MFACodeInput{UserID: userID, Code: req.Code}, true
+}
+
+func writeMFAError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrMFADisabled):
+		response.NotFound(c, "MFA is not configured")
+	case errors.Is(err, domain.ErrInvalidMFACode):
+		response.Unauthorized(c, "Invalid MFA code")
+	case errors.Is(err, domain.ErrInvalidMFAChallenge):
+		response.Unauthorized(c, "MFA challenge is invalid or expired; log in again")
+	case errors.Is(err, domain.ErrMFANotEnrolled):
+		response.BadRequest(c, "MFA is not enrolled")
+	case errors.Is(err, domain.ErrMFAAlreadyEnabled):
+		response.Conflict(c, "MFA is already enabled")
+	case errors.Is(err, domain.ErrAccountLocked):
+		tooManyAttempts(c)
+	default:
+		response.InternalServerError(c, fallback)
+	}
+}
+
+func tooManyAttempts(c *gin.Context) {
+	response.Error(c, http.StatusTooManyRequests, "TOO_MANY_REQUESTS", "Too many failed attempts; try again later")
+}
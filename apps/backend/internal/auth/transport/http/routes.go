@@ -2,6 +2,9 @@ package http
 
 import (
 	"github.com/gin-gonic/gin"
+
+	roleDomain "github.com/indalyadav56/logify/apps/backend/internal/role/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
 )
 
 // RegisterRoutes sets up the auth-related routes on the given router group.
@@ -12,5 +15,66 @@ func RegisterRoutes(router *gin.RouterGroup, handler *AuthHandler) {
 		auth.POST("/login", handler.Login)
 		auth.POST("/refresh-token", handler.RefreshToken)
 		auth.POST("/logout", handler.Logout)
+		auth.POST("/sso/authorize", handler.SSOAuthorize)
+		auth.POST("/sso/callback", handler.SSOCallback)
+		auth.POST("/mfa/verify", handler.VerifyMFA)
+		auth.POST("/mfa/challenge/enroll", handler.StartChallengeEnrollment)
+	}
+}
+
+// RegisterSecuredRoutes mounts the session management routes. The group is
+// expected to be already authenticated (see di.RegisterAllRoutes).
+func RegisterSecuredRoutes(router *gin.RouterGroup, handler *AuthHandler) {
+	router.POST("/v1/auth/switch-tenant", handler.SwitchTenant)
+
+	sessions := router.Group("/v1/auth/sessions")
+	{
+		sessions.GET("", handler.ListSessions)
+		sessions.DELETE("", handler.RevokeAllSessions)
+		sessions.DELETE("/:id", handler.RevokeSession)
+	}
+
+	mfa := router.Group("/v1/auth/mfa")
+	{
+		mfa.GET("", handler.MFAStatus)
+		mfa.POST("/enroll", handler.StartMFAEnrollment)
+		mfa.POST("/enable", handler.EnableMFA)
+		mfa.POST("/disable", handler.DisableMFA)
+		mfa.POST("/recovery-codes", handler.RegenerateRecoveryCodes)
+	}
+}
+
+// RegisterAdminRoutes mounts platform administration of accounts. The group
+// is expected to be already authenticated (see di.RegisterAllRoutes).
+func RegisterAdminRoutes(router *gin.RouterGroup, handler *AuthHandler, authz *middleware.Authorizer) {
+	canManage := authz.RequirePermission(roleDomain.ResourcePlatformUsers, roleDomain.ActionManage)
+
+	router.DELETE("/v1/admin/users/:id/mfa", canManage, handler.ResetMFA)
+}
+
+// RegisterAccessTokenRoutes mounts personal access tokens and service
+// accounts. The group is expected to be already authenticated (see
+// di.RegisterAllRoutes).
+func RegisterAccessTokenRoutes(router *gin.RouterGroup, handler AccessTokenHandler, authz *middleware.Authorizer) {
+	canRead := authz.RequirePermission(roleDomain.ResourceServiceAccount, roleDomain.ActionRead)
+	canManage := authz.RequirePermission(roleDomain.ResourceServiceAccount, roleDomain.ActionManage)
+
+	tokens := router.Group("/v1/auth/tokens")
+	{
+		tokens.GET("", handler.ListTokens)
+		tokens.POST("", handler.CreateToken)
+		tokens.DELETE("/:id", handler.RevokeToken)
+	}
+
+	accounts := router.Group("/v1/service-accounts")
+	{
+		accounts.GET("", canRead, handler.ListServiceAccounts)
+		accounts.POST("", canManage, handler.CreateServiceAccount)
+		accounts.GET("/:id", canRead, handler.GetServiceAccount)
+		accounts.PATCH("/:id", canManage, handler.UpdateServiceAccount)
+		accounts.DELETE("/:id", canManage, handler.DeleteServiceAccount)
+		accounts.GET("/:id/tokens", canRead, handler.ListServiceAccountTokens)
+		accounts.POST("/:id/tokens", canManage, handler.CreateServiceAccountToken)
+		accounts.DELETE("/:id/tokens/:token_id", canManage, handler.RevokeServiceAccountToken)
 	}
 }
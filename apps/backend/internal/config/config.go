@@ -24,6 +24,47 @@ type Config struct {
 	Ollama     Ollama        `mapstructure:"ollama"`
 	Kafka      Kafka         `mapstructure:"kafka"`
 	ClickHouse ClickHouse    `mapstructure:"clickhouse"`
+	Redis      Redis         `mapstructure:"redis"`
+	Ingest     Ingest        `mapstructure:"ingest"`
+	SMTP       SMTP          `mapstructure:"smtp"`
+	Invites    Invites       `mapstructure:"invites"`
+	OIDC       OIDC          `mapstructure:"oidc"`
+	MFA        MFA           `mapstructure:"mfa"`
+	Audit      Audit         `mapstructure:"audit"`
+	Archive    Archive       `mapstructure:"archive"`
+	Redaction  Redaction     `mapstructure:"redaction"`
+	LogMetrics LogMetrics    `mapstructure:"log_metrics"`
+}
+
+type Redis struct {
+	Host         string        `mapstructure:"host"`
+	Port         int           `mapstructure:"port"`
+	Password     string        `mapstructure:"password"`
+	DB           int           `mapstructure:"db"`
+	DialTimeout  time.Duration `mapstructure:"dial_timeout"`
+	ReadTimeout  time.Duration `mapstructure:"read_timeout"`
+	WriteTimeout time.Duration `mapstructure:"write_timeout"`
+	PoolSize     int           `mapstructure:"pool_size"`
+	MinIdleConns int           `mapstructure:"min_idle_conns"`
+}
+
+type Ingest struct {
+	Limits IngestLimits `mapstructure:"limits"`
+}
+
+// IngestLimits are the per-second ceilings applied to POST /v1/logs. A zero
+// limit disables that check. The daily volume cap comes from the tenant quota.
+type IngestLimits struct {
+	TenantBytesPerSecond   int64         `mapstructure:"tenant_bytes_per_second"`
+	TenantEventsPerSecond  int64         `mapstructure:"tenant_events_per_second"`
+	ProjectBytesPerSecond  int64         `mapstructure:"project_bytes_per_second"`
+	ProjectEventsPerSecond int64         `mapstructure:"project_events_per_second"`
+	Window                 time.Duration `mapstructure:"window"`
+	// DropBelowLevel, when set (e.g. "warn"), makes over-limit events below
+	// that level be dropped with 202 instead of rejected with 429, while
+	// events at or above it bypass the per-second limits.
+	DropBelowLevel     string        `mapstructure:"drop_below_level"`
+	UsageFlushInterval time.Duration `mapstructure:"usage_flush_interval"`
 }
 
 type ClickHouse struct {
@@ -94,6 +135,106 @@ type SMTP struct {
 	From     string `mapstructure:"from"`
 }
 
+// Invites configures project invitations. Tokens are signed with the JWT
+// secret; AcceptURL is the web page the emailed link points at and receives
+// the token as the "token" query parameter.
+type Invites struct {
+	TTL       time.Duration `mapstructure:"ttl"`
+	AcceptURL string        `mapstructure:"accept_url"`
+}
+
+// OIDC configures single sign-on; it is off while Issuer is empty.
+// RedirectURL is the web page the provider returns to, which posts the code
+// and state to /v1/auth/sso/callback.
+type OIDC struct {
+	Issuer       string   `mapstructure:"issuer"`
+	ClientID     string   `mapstructure:"client_id"`
+	ClientSecret string   `mapstructure:"client_secret"`
+	RedirectURL  string   `mapstructure:"redirect_url"`
+	Scopes       []string `mapstructure:"scopes"`
+	GroupsClaim  string   `mapstructure:"groups_claim"`
+	// CacheTTL is how long discovery and signing keys are cached.
+	CacheTTL      time.Duration      `mapstructure:"cache_ttl"`
+	StateTTL      time.Duration      `mapstructure:"state_ttl"`
+	GroupMappings []OIDCGroupMapping `mapstructure:"group_mappings"`
+}
+
+// OIDCGroupMapping grants Role on ProjectID (every project of TenantID when
+// empty) to members of an identity provider group.
+type OIDCGroupMapping struct {
+	Group     string `mapstructure:"group"`
+	TenantID  string `mapstructure:"tenant_id"`
+	ProjectID string `mapstructure:"project_id"`
+	Role      string `mapstructure:"role"`
+}
+
+// MFA configures TOTP second factors and login lockout. TOTP secrets are
+// encrypted with EncryptionKey, or with a key derived from the JWT secret
+// when it is empty; changing it makes every enrolled authenticator unusable. After MaxAttempts failed
+// passwords (per email) or codes (per user) within AttemptWindow, further
+// attempts are refused for LockoutDuration. A zero MaxAttempts disables the
+// lockout.
+type MFA struct {
+	Issuer          string        `mapstructure:"issuer"`
+	EncryptionKey   string        `mapstructure:"encryption_key"`
+	ChallengeTTL    time.Duration `mapstructure:"challenge_ttl"`
+	MaxAttempts     int           `mapstructure:"max_attempts"`
+	AttemptWindow   time.Duration `mapstructure:"attempt_window"`
+	LockoutDuration time.Duration `mapstructure:"lockout_duration"`
+}
+
+// Audit retention is independent of log retention: audit events are kept
+// for compliance long after the logs they mention have expired.
+type Audit struct {
+	Retention     time.Duration `mapstructure:"retention"`
+	PurgeInterval time.Duration `mapstructure:"purge_interval"`
+}
+
+// Archive configures cold storage of logs in an S3-compatible bucket. The
+// archiver exports each tenant's logs per UTC day once the day is After old,
+// looking Lookback further back for days it missed; Lookback must stay below
+// the shortest log retention or those logs expire unarchived. Rehydrated
+// logs stay queryable for RehydrateTTL.
+type Archive struct {
+	Enabled           bool          `mapstructure:"enabled"`
+	Endpoint          string        `mapstructure:"endpoint"`
+	Region            string        `mapstructure:"region"`
+	Bucket            string        `mapstructure:"bucket"`
+	AccessKey         string        `mapstructure:"access_key"`
+	SecretKey         string        `mapstructure:"secret_key"`
+	Prefix            string        `mapstructure:"prefix"`
+	After             time.Duration `mapstructure:"after"`
+	Lookback          time.Duration `mapstructure:"lookback"`
+	Interval          time.Duration `mapstructure:"interval"`
+	PollInterval      time.Duration `mapstructure:"poll_interval"`
+	RehydrateTTL      time.Duration `mapstructure:"rehydrate_ttl"`
+	MaxRehydrateRange time.Duration `mapstructure:"max_rehydrate_range"`
+	MaxAttempts       int           `mapstructure:"max_attempts"`
+}
+
+// Redaction configures the redaction of logs by the log-processor and the
+// embedding worker. Hashes are keyed with HashKey, or with a key derived from
+// the JWT secret when it is empty; the workers refuse to start when both are
+// empty. Changing the key changes every hash, so logs hashed before and after
+// no longer join. Set HashKey in production so rotating the JWT secret leaves
+// hashes alone. Rule changes reach the workers within RuleCacheTTL, and rule
+// hit counters are flushed every FlushInterval.
+type Redaction struct {
+	HashKey       string        `mapstructure:"hash_key"`
+	RuleCacheTTL  time.Duration `mapstructure:"rule_cache_ttl"`
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+}
+
+// LogMetrics configures the log-derived metrics of the log-processor. Rule
+// changes reach it within RuleCacheTTL; the series it counted are stored
+// every FlushInterval. A rule keeps at most MaxSeriesPerRule label sets, and
+// counts logs of any other in its overflow series.
+type LogMetrics struct {
+	RuleCacheTTL     time.Duration `mapstructure:"rule_cache_ttl"`
+	FlushInterval    time.Duration `mapstructure:"flush_interval"`
+	MaxSeriesPerRule int           `mapstructure:"max_series_per_rule"`
+}
+
 type Postgres struct {
 	Host            string        `mapstructure:"host"`
 	Port            int           `mapstructure:"port"`
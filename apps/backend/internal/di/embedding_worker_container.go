@@ -13,6 +13,8 @@ import (
 	embedderDomain "github.com/indalyadav56/logify/apps/backend/internal/embedder/domain"
 	embedderKafka "github.com/indalyadav56/logify/apps/backend/internal/embedder/infrastructure/kafka"
 	embedderPG "github.com/indalyadav56/logify/apps/backend/internal/embedder/infrastructure/postgres"
+	redactionApp "github.com/indalyadav56/logify/apps/backend/internal/redaction/application"
+	redactionPG "github.com/indalyadav56/logify/apps/backend/internal/redaction/infrastructure/postgres"
 	"github.com/indalyadav56/logify/apps/backend/pkg/ollama"
 	"github.com/indalyadav56/logify/apps/backend/pkg/postgres"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -32,6 +34,11 @@ type EmbeddingWorkerContainer struct {
 func NewEmbeddingWorkerContainer(ctx context.Context, cfg *config.Config, log *zap.Logger) (*EmbeddingWorkerContainer, error) {
 	c := &EmbeddingWorkerContainer{Config: cfg, Logger: log}
 
+	hashKey, err := redactionHashKey(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	pool, err := postgres.New(ctx, postgres.Config{
 		Host:         c.Config.Postgres.Host,
 		Port:         c.Config.Postgres.Port,
@@ -62,7 +69,7 @@ func NewEmbeddingWorkerContainer(ctx context.Context, cfg *config.Config, log *z
 		GroupID: groupID,
 	})
 
-	c.initEmbedder(log)
+	c.initEmbedder(log, hashKey)
 	return c, nil
 }
 
@@ -79,7 +86,7 @@ func (c *EmbeddingWorkerContainer) Close() error {
 	return errors.Join(errs...)
 }
 
-func (c *EmbeddingWorkerContainer) initEmbedder(log *zap.Logger) {
+func (c *EmbeddingWorkerContainer) initEmbedder(log *zap.Logger, hashKey []byte) {
 	c.OllamaClient = ollama.NewClient(ollama.Config{
 		BaseURL: c.Config.Ollama.BaseURL,
 		Model:   c.Config.Ollama.Model,
@@ -88,5 +95,8 @@ func (c *EmbeddingWorkerContainer) initEmbedder(log *zap.Logger) {
 
 	consumer := embedderKafka.NewLogConsumer(c.KafkaReader, log)
 	c.LogRepository = embedderPG.NewLogRepository(c.postgresDB)
-	c.EmbedderService = embedderApp.NewEmbedderService(consumer, c.OllamaClient, c.LogRepository, log)
+	// The log processor counts rule hits; counting here too would double them.
+	redactor := redactionApp.NewRedactionRunner(redactionPG.NewRuleRepository(c.postgresDB), nil,
+		hashKey, c.Config.Redaction.RuleCacheTTL, c.Config.Redaction.FlushInterval, log)
+	c.EmbedderService = embedderApp.NewEmbedderService(consumer, c.OllamaClient, c.LogRepository, redactor, log)
 }
@@ -0,0 +1,31 @@
+package di
+
+import (
+	"crypto/hkdf"
+	"crypto/sha256"
+	"errors"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/config"
+)
+
+// Contexts of the keys derived from the JWT secret. Each use gets its own,
+// so no two uses share a key and none uses the signing key itself.
+const (
+	inviteKeyContext    = "logify.invitation.v1"
+	mfaKeyContext       = "logify.mfa.v1"
+	redactionKeyContext = "logify.redaction.v1"
+)
+
+var errNoKeySource = errors.New("no dedicated key set and the jwt secret is empty")
+
+// deriveKey returns dedicated when it is set, and otherwise a key derived
+// from the JWT secret with HKDF under info.
+func deriveKey(cfg *config.Config, dedicated, info string) ([]byte, error) {
+	if dedicated != "" {
+		return []byte(dedicated), nil
+	}
+	if cfg.JWT.Secret == "" {
+		return nil, errNoKeySource
+	}
+	return hkdf.Key(sha256.New, []byte(cfg.JWT.Secret), nil, info, sha256.Size)
+}
@@ -0,0 +1,40 @@
+package di
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/config"
+)
+
+func TestDeriveKeyNeverReusesJWTSecret(t *testing.T) {
+	cfg := &config.Config{JWT: config.JWT{Secret: "jwt-signing-secret"}}
+
+	redaction, err := deriveKey(cfg, "", redactionKeyContext)
+	require.NoError(t, err)
+	assert.Len(t, redaction, 32)
+	assert.NotEqual(t, []byte(cfg.JWT.Secret), redaction)
+
+	again, err := deriveKey(cfg, "", redactionKeyContext)
+	require.NoError(t, err)
+	assert.Equal(t, redaction, again, "the derived key is stable")
+
+	invite, err := deriveKey(cfg, "", inviteKeyContext)
+	require.NoError(t, err)
+	assert.NotEqual(t, redaction, invite, "each use gets its own key")
+
+	dedicated, err := deriveKey(cfg, "dedicated-key", redactionKeyContext)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("dedicated-key"), dedicated)
+}
+
+func TestRedactionHashKeyRequiresAKeySource(t *testing.T) {
+	_, err := redactionHashKey(&config.Config{})
+	assert.ErrorIs(t, err, errNoKeySource)
+
+	key, err := redactionHashKey(&config.Config{Redaction: config.Redaction{HashKey: "dedicated-key"}})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("dedicated-key"), key)
+}
@@ -3,17 +3,26 @@ package di
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	ch "github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/segmentio/kafka-go"
 	"go.uber.org/zap"
 
 	"github.com/indalyadav56/logify/apps/backend/internal/config"
+	logmetricApp "github.com/indalyadav56/logify/apps/backend/internal/logmetric/application"
+	logmetricPG "github.com/indalyadav56/logify/apps/backend/internal/logmetric/infrastructure/postgres"
+	pipelineApp "github.com/indalyadav56/logify/apps/backend/internal/pipeline/application"
+	pipelinePG "github.com/indalyadav56/logify/apps/backend/internal/pipeline/infrastructure/postgres"
 	processorApp "github.com/indalyadav56/logify/apps/backend/internal/processor/application"
 	processorDomain "github.com/indalyadav56/logify/apps/backend/internal/processor/domain"
 	processorCH "github.com/indalyadav56/logify/apps/backend/internal/processor/infrastructure/clickhouse"
 	processorKafka "github.com/indalyadav56/logify/apps/backend/internal/processor/infrastructure/kafka"
+	redactionApp "github.com/indalyadav56/logify/apps/backend/internal/redaction/application"
+	redactionPG "github.com/indalyadav56/logify/apps/backend/internal/redaction/infrastructure/postgres"
 	pkgClickhouse "github.com/indalyadav56/logify/apps/backend/pkg/clickhouse"
+	"github.com/indalyadav56/logify/apps/backend/pkg/postgres"
 )
 
 type LogProcessorContainer struct {
@@ -22,13 +31,26 @@ type LogProcessorContainer struct {
 
 	KafkaReader      *kafka.Reader
 	ClickHouseDB     ch.Conn
+	PostgresDB       *pgxpool.Pool
 	ProcessorService *processorApp.ProcessorService
 	LogRepository    processorDomain.LogRepository
+	RedactionRunner  *redactionApp.RedactionRunner
+	MetricRunner     *logmetricApp.MetricRunner
+
+	cancelRedaction context.CancelFunc
+	redactionDone   chan struct{}
+	cancelMetrics   context.CancelFunc
+	metricsDone     chan struct{}
 }
 
 func NewLogProcessorContainer(ctx context.Context, cfg *config.Config, log *zap.Logger) (*LogProcessorContainer, error) {
 	c := &LogProcessorContainer{Config: cfg, Logger: log}
 
+	hashKey, err := redactionHashKey(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	topic := "logs"
 	if topic == "" {
 		topic = "logs"
@@ -48,18 +70,44 @@ func NewLogProcessorContainer(ctx context.Context, cfg *config.Config, log *zap.
 		GroupID: groupID,
 	})
 
-	var err error
 	c.ClickHouseDB, err = pkgClickhouse.NewClickHouseDB(c.Config.ClickHouse.DSN())
 	if err != nil {
 		return nil, err
 	}
 
-	c.initLogProcessor()
+	// Pipelines, redaction and metric rules are defined through the API and read from Postgres.
+	c.PostgresDB, err = postgres.New(ctx, postgres.Config{
+		Host:         c.Config.Postgres.Host,
+		Port:         c.Config.Postgres.Port,
+		User:         c.Config.Postgres.User,
+		Password:     c.Config.Postgres.Password,
+		Database:     c.Config.Postgres.Database,
+		SSLMode:      c.Config.Postgres.SSLMode,
+		MaxOpenConns: int32(c.Config.Postgres.MaxOpenConns),
+		MaxIdleConns: int32(c.Config.Postgres.MaxIdleConns),
+		MaxLifetime:  c.Config.Postgres.ConnMaxLifetime,
+		MaxIdleTime:  c.Config.Postgres.ConnMaxIdleTime,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("postgres: %w", err)
+	}
+
+	c.initLogProcessor(hashKey)
 	return c, nil
 }
 
 func (c *LogProcessorContainer) Close() error {
 	var errs []error
+	if c.cancelRedaction != nil {
+		c.cancelRedaction()
+		<-c.redactionDone
+	}
+	// Stop the metric runner before Postgres closes: its final flush stores
+	// the series counted since the last one.
+	if c.cancelMetrics != nil {
+		c.cancelMetrics()
+		<-c.metricsDone
+	}
 	if c.KafkaReader != nil {
 		if err := c.KafkaReader.Close(); err != nil {
 			errs = append(errs, err)
@@ -70,11 +118,47 @@ func (c *LogProcessorContainer) Close() error {
 			errs = append(errs, err)
 		}
 	}
+	if c.PostgresDB != nil {
+		c.PostgresDB.Close()
+	}
 	return errors.Join(errs...)
 }
 
-func (c *LogProcessorContainer) initLogProcessor() {
+func (c *LogProcessorContainer) initLogProcessor(hashKey []byte) {
 	consumer := processorKafka.NewLogConsumer(c.KafkaReader, c.Logger)
 	c.LogRepository = processorCH.NewLogRepository(c.ClickHouseDB)
-	c.ProcessorService = processorApp.NewProcessorService(consumer, c.LogRepository, c.Logger)
+	pipelines := pipelineApp.NewPipelineRunner(pipelinePG.NewPipelineRepository(c.PostgresDB), c.Logger)
+
+	rules := redactionPG.NewRuleRepository(c.PostgresDB)
+	c.RedactionRunner = redactionApp.NewRedactionRunner(rules, rules, hashKey,
+		c.Config.Redaction.RuleCacheTTL, c.Config.Redaction.FlushInterval, c.Logger)
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancelRedaction, c.redactionDone = cancel, make(chan struct{})
+	go func() {
+		defer close(c.redactionDone)
+		c.RedactionRunner.Run(ctx)
+	}()
+
+	metricRules := logmetricPG.NewRuleRepository(c.PostgresDB)
+	c.MetricRunner = logmetricApp.NewMetricRunner(metricRules, metricRules, c.Config.LogMetrics.RuleCacheTTL,
+		c.Config.LogMetrics.FlushInterval, c.Config.LogMetrics.MaxSeriesPerRule, c.Logger)
+	metricsCtx, cancelMetrics := context.WithCancel(context.Background())
+	c.cancelMetrics, c.metricsDone = cancelMetrics, make(chan struct{})
+	go func() {
+		defer close(c.metricsDone)
+		c.MetricRunner.Run(metricsCtx)
+	}()
+
+	c.ProcessorService = processorApp.NewProcessorService(consumer, c.LogRepository, pipelines, c.RedactionRunner,
+		c.MetricRunner, c.Logger)
+}
+
+// redactionHashKey returns the key of hashing redaction rules: the
+// configured one, or one derived from the JWT secret.
+func redactionHashKey(cfg *config.Config) ([]byte, error) {
+	key, err := deriveKey(cfg, cfg.Redaction.HashKey, redactionKeyContext)
+	if err != nil {
+		return nil, fmt.Errorf("redaction: hash key (set redaction.hash_key or auth.jwt.secret): %w", err)
+	}
+	return key, nil
 }
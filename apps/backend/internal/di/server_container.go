@@ -4,32 +4,55 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	ch "github.com/ClickHouse/clickhouse-go/v2"
 	"github.com/gin-gonic/gin"
 	gojwt "github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/indalyadav56/logify/apps/backend/internal/auth/application"
 	"github.com/indalyadav56/logify/apps/backend/internal/config"
 	processorApp "github.com/indalyadav56/logify/apps/backend/internal/processor/application"
 	searchApp "github.com/indalyadav56/logify/apps/backend/internal/search/application"
 	searchCH "github.com/indalyadav56/logify/apps/backend/internal/search/infrastructure/clickhouse"
+	searchRedis "github.com/indalyadav56/logify/apps/backend/internal/search/infrastructure/redis"
 	searchHTTP "github.com/indalyadav56/logify/apps/backend/internal/search/transport/http"
 	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
 	pkgClickhouse "github.com/indalyadav56/logify/apps/backend/pkg/clickhouse"
 	jwtpkg "github.com/indalyadav56/logify/apps/backend/pkg/jwt"
+	"github.com/indalyadav56/logify/apps/backend/pkg/mailer"
+	"github.com/indalyadav56/logify/apps/backend/pkg/objectstore"
+	"github.com/indalyadav56/logify/apps/backend/pkg/oidc"
 	"github.com/indalyadav56/logify/apps/backend/pkg/postgres"
+	pkgRedis "github.com/indalyadav56/logify/apps/backend/pkg/redis"
+	"github.com/indalyadav56/logify/apps/backend/pkg/secretbox"
 	"github.com/jackc/pgx/v5/pgxpool"
+	goredis "github.com/redis/go-redis/v9"
 	"github.com/segmentio/kafka-go"
 	"go.uber.org/zap"
 
+	// Archive
+	archiveApp "github.com/indalyadav56/logify/apps/backend/internal/archive/application"
+	archiveCH "github.com/indalyadav56/logify/apps/backend/internal/archive/infrastructure/clickhouse"
+	archivePG "github.com/indalyadav56/logify/apps/backend/internal/archive/infrastructure/postgres"
+	archiveHTTP "github.com/indalyadav56/logify/apps/backend/internal/archive/transport/http"
+
+	// Audit
+	auditApp "github.com/indalyadav56/logify/apps/backend/internal/audit/application"
+	auditPG "github.com/indalyadav56/logify/apps/backend/internal/audit/infrastructure/postgres"
+	auditHTTP "github.com/indalyadav56/logify/apps/backend/internal/audit/transport/http"
+
 	// Ingest
 	ingestApp "github.com/indalyadav56/logify/apps/backend/internal/ingest/application"
 	ingestKafka "github.com/indalyadav56/logify/apps/backend/internal/ingest/infrastructure/kafka"
+	ingestPG "github.com/indalyadav56/logify/apps/backend/internal/ingest/infrastructure/postgres"
+	ingestRedis "github.com/indalyadav56/logify/apps/backend/internal/ingest/infrastructure/redis"
 	ingestHTTP "github.com/indalyadav56/logify/apps/backend/internal/ingest/transport/http"
 
 	// Auth
 	authService "github.com/indalyadav56/logify/apps/backend/internal/auth/application"
 	authRepo "github.com/indalyadav56/logify/apps/backend/internal/auth/infrastructure/postgres"
+	authRedis "github.com/indalyadav56/logify/apps/backend/internal/auth/infrastructure/redis"
 	authHTTP "github.com/indalyadav56/logify/apps/backend/internal/auth/transport/http"
 
 	// User
@@ -50,6 +73,37 @@ import (
 	projectApp "github.com/indalyadav56/logify/apps/backend/internal/project/application"
 	projectPG "github.com/indalyadav56/logify/apps/backend/internal/project/infrastructure/postgres"
 	projectHTTP "github.com/indalyadav56/logify/apps/backend/internal/project/transport/http"
+
+	// Retention
+	retentionApp "github.com/indalyadav56/logify/apps/backend/internal/retention/application"
+	retentionCH "github.com/indalyadav56/logify/apps/backend/internal/retention/infrastructure/clickhouse"
+	retentionPG "github.com/indalyadav56/logify/apps/backend/internal/retention/infrastructure/postgres"
+	retentionHTTP "github.com/indalyadav56/logify/apps/backend/internal/retention/transport/http"
+
+	// Sampling
+	samplingApp "github.com/indalyadav56/logify/apps/backend/internal/sampling/application"
+	samplingPG "github.com/indalyadav56/logify/apps/backend/internal/sampling/infrastructure/postgres"
+	samplingHTTP "github.com/indalyadav56/logify/apps/backend/internal/sampling/transport/http"
+
+	// Pipeline
+	pipelineApp "github.com/indalyadav56/logify/apps/backend/internal/pipeline/application"
+	pipelinePG "github.com/indalyadav56/logify/apps/backend/internal/pipeline/infrastructure/postgres"
+	pipelineHTTP "github.com/indalyadav56/logify/apps/backend/internal/pipeline/transport/http"
+
+	// Redaction
+	redactionApp "github.com/indalyadav56/logify/apps/backend/internal/redaction/application"
+	redactionPG "github.com/indalyadav56/logify/apps/backend/internal/redaction/infrastructure/postgres"
+	redactionHTTP "github.com/indalyadav56/logify/apps/backend/internal/redaction/transport/http"
+
+	// Log metrics
+	logmetricApp "github.com/indalyadav56/logify/apps/backend/internal/logmetric/application"
+	logmetricPG "github.com/indalyadav56/logify/apps/backend/internal/logmetric/infrastructure/postgres"
+	logmetricHTTP "github.com/indalyadav56/logify/apps/backend/internal/logmetric/transport/http"
+
+	// Tenant
+	tenantApp "github.com/indalyadav56/logify/apps/backend/internal/tenant/application"
+	tenantPG "github.com/indalyadav56/logify/apps/backend/internal/tenant/infrastructure/postgres"
+	tenantHTTP "github.com/indalyadav56/logify/apps/backend/internal/tenant/transport/http"
 )
 
 type ServerContainer struct {
@@ -59,18 +113,34 @@ type ServerContainer struct {
 
 	KafkaWriter  *kafka.Writer
 	ClickHouseDB ch.Conn
+	Redis        *goredis.Client
+
+	// background is cancelled by Close to stop the workers started here.
+	background     context.Context
+	stopBackground context.CancelFunc
 
 	JWT *jwtpkg.JWT
 
+	// Audit trail, recorded by the other contexts and by middleware.AuditLog
+	AuditService auditApp.AuditService
+	AuditHandler *auditHTTP.AuditHandler
+
 	// Auth
 	AuthRepo    *authRepo.RefreshTokenRepository
 	SessionRepo *authRepo.SessionRepository
 	AuthService application.AuthService
 	AuthHandler *authHTTP.AuthHandler
 
+	// Personal access tokens and service accounts
+	AccessTokenService    application.AccessTokenService
+	ServiceAccountService application.ServiceAccountService
+	AccessTokenHandler    authHTTP.AccessTokenHandler
+
 	// Ingest bounded context
 	IngestService ingestApp.IngestService
 	IngestHandler ingestHTTP.IngestHandler
+	UsageService  ingestApp.UsageService
+	UsageHandler  ingestHTTP.UsageHandler
 
 	// Search bounded context
 	SearchService *searchApp.SearchService
@@ -82,11 +152,14 @@ type ServerContainer struct {
 	// User
 	UserService           userApplication.UserService
 	UserRepo              userDomain.UserRepository
+	MembershipService     userApplication.MembershipService
 	UserManagementHandler userHTTP.UserManagementHandler
 
 	// Role (RBAC) bounded context
-	RoleService roleApp.RoleService
-	RoleHandler roleHTTP.RoleHandler
+	PermissionResolver roleApp.PermissionResolver
+	Authorizer         *middleware.Authorizer
+	RoleService        roleApp.RoleService
+	RoleHandler        roleHTTP.RoleHandler
 
 	// Notification bounded context
 	NotificationDashboardHandler notificationHTTP.NotificationDashboardHandler
@@ -94,6 +167,34 @@ type ServerContainer struct {
 	// Project bounded context
 	ProjectService projectApp.ProjectService
 	ProjectHandler *projectHTTP.ProjectHandler
+
+	// Retention bounded context
+	RetentionService retentionApp.RetentionService
+	RetentionHandler *retentionHTTP.RetentionHandler
+
+	// Sampling bounded context; ingest applies the rules
+	SamplingService samplingApp.SamplingService
+	SamplingHandler *samplingHTTP.SamplingHandler
+
+	// Pipeline bounded context; the log-processor runs the pipelines
+	PipelineService pipelineApp.PipelineService
+	PipelineHandler *pipelineHTTP.PipelineHandler
+
+	// Redaction bounded context; the workers apply the rules
+	RedactionService redactionApp.RedactionService
+	RedactionHandler *redactionHTTP.RedactionHandler
+
+	// Log metrics bounded context; the log-processor counts the series
+	MetricService logmetricApp.MetricService
+	MetricHandler *logmetricHTTP.MetricHandler
+
+	// Archive bounded context
+	ArchiveService archiveApp.ArchiveService
+	ArchiveHandler *archiveHTTP.ArchiveHandler
+
+	// Tenant bounded context
+	TenantService tenantApp.TenantService
+	TenantHandler *tenantHTTP.TenantHandler
 }
 
 func NewServerContainer(ctx context.Context, cfg *config.Config, log *zap.Logger) (*ServerContainer, error) {
@@ -127,21 +228,55 @@ func NewServerContainer(ctx context.Context, cfg *config.Config, log *zap.Logger
 		return nil, err
 	}
 
+	c.Redis, err = pkgRedis.NewRedisClient(pkgRedis.Config{
+		Host:         c.Config.Redis.Host,
+		Port:         c.Config.Redis.Port,
+		Password:     c.Config.Redis.Password,
+		DB:           c.Config.Redis.DB,
+		DialTimeout:  c.Config.Redis.DialTimeout,
+		ReadTimeout:  c.Config.Redis.ReadTimeout,
+		WriteTimeout: c.Config.Redis.WriteTimeout,
+		PoolSize:     c.Config.Redis.PoolSize,
+		MinIdleConns: c.Config.Redis.MinIdleConns,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+
+	c.background, c.stopBackground = context.WithCancel(context.Background())
+
+	// Permissions first: ingest, search and every secured route consult them.
+	c.initPermissions()
+	c.initAudit()
+	c.initTenant()
+	c.initProject()
+	c.initRetention()
+	c.initSampling()
+	c.initPipeline()
+	c.initRedaction()
+	c.initLogMetric()
 	c.initIngest()
+	if err := c.initArchive(); err != nil {
+		return nil, err
+	}
 	c.initSearch()
-	c.initUser()
+	if err := c.initUser(); err != nil {
+		return nil, err
+	}
 
 	if err := c.initAuth(); err != nil {
 		return nil, err
 	}
 	c.initRole()
 	c.initNotification()
-	c.initProject()
 
 	return c, nil
 }
 
 func (c *ServerContainer) Close() error {
+	if c.stopBackground != nil {
+		c.stopBackground()
+	}
 	var errs []error
 	if c.KafkaWriter != nil {
 		if err := c.KafkaWriter.Close(); err != nil {
@@ -153,6 +288,11 @@ func (c *ServerContainer) Close() error {
 			errs = append(errs, err)
 		}
 	}
+	if c.Redis != nil {
+		if err := c.Redis.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
 	if c.postgresDB != nil {
 		c.postgresDB.Close()
 	}
@@ -160,14 +300,31 @@ func (c *ServerContainer) Close() error {
 }
 
 func (c *ServerContainer) initIngest() {
+	lc := c.Config.Ingest.Limits
+	rateLimiter := ingestRedis.NewRateLimiter(c.Redis)
+	limiter := ingestApp.NewIngestLimiter(rateLimiter, c.TenantService, ingestApp.LimitConfig{
+		TenantBytesPerSecond:   lc.TenantBytesPerSecond,
+		TenantEventsPerSecond:  lc.TenantEventsPerSecond,
+		ProjectBytesPerSecond:  lc.ProjectBytesPerSecond,
+		ProjectEventsPerSecond: lc.ProjectEventsPerSecond,
+		Window:                 lc.Window,
+		DropBelowLevel:         lc.DropBelowLevel,
+	}, c.Logger)
+
 	producer := ingestKafka.NewLogProducer(c.KafkaWriter, c.Logger)
-	c.IngestService = ingestApp.NewIngestService(producer)
+	c.IngestService = ingestApp.NewIngestService(producer, c.PermissionResolver, limiter, c.RetentionService, c.SamplingService)
 	c.IngestHandler = ingestHTTP.NewIngestHandler(c.IngestService)
+
+	c.UsageService = ingestApp.NewUsageService(rateLimiter, ingestPG.NewUsageRepository(c.postgresDB), lc.UsageFlushInterval, c.Logger)
+	c.UsageHandler = ingestHTTP.NewUsageHandler(c.UsageService)
+	go c.UsageService.Run(c.background)
 }
 
 func (c *ServerContainer) initSearch() {
 	repo := searchCH.NewSearchRepository(c.ClickHouseDB, c.Logger)
-	c.SearchService = searchApp.NewSearchService(repo, c.Logger)
+	cache := searchRedis.NewFieldCache(c.Redis)
+	limits := searchApp.NewQueryLimiter(searchRedis.NewQueryCounter(c.Redis), c.TenantService, c.Logger)
+	c.SearchService = searchApp.NewSearchService(repo, cache, c.PermissionResolver, limits, c.ProjectService, c.ArchiveService, c.AuditService, c.Logger)
 	c.SearchHandler = searchHTTP.NewHandler(c.SearchService, c.Logger)
 }
 
@@ -186,44 +343,295 @@ func (c *ServerContainer) initAuth() error {
 	c.SessionRepo = authRepo.NewSessionRepository(c.postgresDB)
 	c.AuthRepo = authRepo.NewRefreshTokenRepository(c.postgresDB)
 
-	c.AuthService = authService.NewAuthService(c.Logger, c.JWT, c.AuthRepo, c.SessionRepo, c.UserService)
+	sso, err := c.ssoConfig()
+	if err != nil {
+		return err
+	}
+
+	mfa, err := c.mfaConfig()
+	if err != nil {
+		return err
+	}
+
+	c.AuthService = authService.NewAuthService(c.Logger, c.JWT, c.AuthRepo, c.SessionRepo, c.UserService, c.TenantService, c.MembershipService, sso, mfa, c.AuditService, c.Config.JWT.RefreshTokenTTL)
 	c.AuthHandler = authHTTP.NewAuthHandler(c.AuthService)
+
+	tokens := authRepo.NewAccessTokenRepository(c.postgresDB)
+	c.AccessTokenService = authService.NewAccessTokenService(tokens, c.AuditService, c.Logger)
+	c.ServiceAccountService = authService.NewServiceAccountService(
+		authRepo.NewServiceAccountRepository(c.postgresDB),
+		tokens,
+		c.PermissionResolver,
+		c.AuditService,
+		c.Logger,
+	)
+	c.AccessTokenHandler = authHTTP.NewAccessTokenHandler(c.AccessTokenService, c.ServiceAccountService)
 	return nil
 }
 
-func (c *ServerContainer) initUser() {
+// ssoConfig builds the OIDC login wiring, or nil when no issuer is set.
+func (c *ServerContainer) ssoConfig() (*authService.SSO, error) {
+	cfg := c.Config.OIDC
+	if cfg.Issuer == "" {
+		return nil, nil
+	}
+	if cfg.ClientID == "" || cfg.RedirectURL == "" {
+		return nil, errors.New("auth: oidc client_id and redirect_url are required when an issuer is set")
+	}
+
+	mappings := make([]authService.GroupMapping, 0, len(cfg.GroupMappings))
+	for i, m := range cfg.GroupMappings {
+		tenantID, err := uuid.Parse(m.TenantID)
+		if err != nil {
+			return nil, fmt.Errorf("auth: oidc group_mappings[%d]: invalid tenant_id: %w", i, err)
+		}
+		var projectID uuid.UUID
+		if m.ProjectID != "" {
+			if projectID, err = uuid.Parse(m.ProjectID); err != nil {
+				return nil, fmt.Errorf("auth: oidc group_mappings[%d]: invalid project_id: %w", i, err)
+			}
+		}
+		if m.Group == "" || m.Role == "" {
+			return nil, fmt.Errorf("auth: oidc group_mappings[%d]: group and role are required", i)
+		}
+		mappings = append(mappings, authService.GroupMapping{Group: m.Group, Tenant: tenantID, Project: projectID, Role: m.Role})
+	}
+
+	return &authService.SSO{
+		Provider: oidc.New(oidc.Config{
+			Issuer:       cfg.Issuer,
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.RedirectURL,
+			Scopes:       cfg.Scopes,
+			CacheTTL:     cfg.CacheTTL,
+		}),
+		States:      authRedis.NewSSOStateStore(c.Redis),
+		Identities:  authRepo.NewIdentityRepository(c.postgresDB),
+		Permissions: c.PermissionResolver,
+		GroupsClaim: cfg.GroupsClaim,
+		Mappings:    mappings,
+		StateTTL:    cfg.StateTTL,
+	}, nil
+}
+
+// mfaConfig builds the TOTP and lockout wiring. Secrets are sealed with a
+// key derived from the JWT secret unless a dedicated key is set.
+func (c *ServerContainer) mfaConfig() (*authService.MFA, error) {
+	cfg := c.Config.MFA
+	key, err := deriveKey(c.Config, cfg.EncryptionKey, mfaKeyContext)
+	if err != nil {
+		return nil, fmt.Errorf("auth: mfa encryption key: %w", err)
+	}
+	box, err := secretbox.New(string(key))
+	if err != nil {
+		return nil, fmt.Errorf("auth: mfa encryption key: %w", err)
+	}
+
+	mfa := &authService.MFA{
+		Enrollments:  authRepo.NewMFARepository(c.postgresDB),
+		Challenges:   authRedis.NewMFAChallengeStore(c.Redis),
+		Secrets:      box,
+		Issuer:       cfg.Issuer,
+		ChallengeTTL: cfg.ChallengeTTL,
+	}
+	if cfg.MaxAttempts > 0 {
+		mfa.Attempts = authRedis.NewAttemptLimiter(c.Redis, cfg.MaxAttempts, cfg.AttemptWindow, cfg.LockoutDuration)
+	}
+	return mfa, nil
+}
+
+func (c *ServerContainer) initUser() error {
+	inviteKey, err := deriveKey(c.Config, "", inviteKeyContext)
+	if err != nil {
+		return errors.New("user: jwt secret is required to sign invitations (set auth.jwt.secret)")
+	}
+
 	c.UserRepo = userPG.NewUserRepository(c.postgresDB)
 	c.UserService = userApplication.NewUserService(c.UserRepo, c.Logger)
-	c.UserManagementHandler = userHTTP.NewUserManagementHandler()
+
+	smtp := c.Config.SMTP
+	mail := mailer.New(mailer.Config{
+		Host:     smtp.Host,
+		Port:     smtp.Port,
+		User:     smtp.User,
+		Password: smtp.Password,
+		From:     smtp.From,
+	}, c.Logger)
+	c.MembershipService = userApplication.NewMembershipService(
+		userPG.NewMemberRepository(c.postgresDB),
+		c.UserService,
+		mail,
+		c.TenantService,
+		c.PermissionResolver,
+		userApplication.NewTrailAuditor(c.AuditService),
+		userApplication.InviteConfig{
+			Secret:    inviteKey,
+			TTL:       c.Config.Invites.TTL,
+			AcceptURL: c.Config.Invites.AcceptURL,
+		},
+		c.Logger,
+	)
+	c.UserManagementHandler = userHTTP.NewUserManagementHandler(c.MembershipService)
+	return nil
+}
+
+func (c *ServerContainer) initPermissions() {
+	c.PermissionResolver = roleApp.NewPermissionResolver(rolePG.NewPermissionRepository(c.postgresDB), 0, c.Logger)
+	c.Authorizer = middleware.NewAuthorizer(c.PermissionResolver)
 }
 
 func (c *ServerContainer) initRole() {
 	repo := rolePG.NewRoleRepository(c.postgresDB)
-	c.RoleService = roleApp.NewRoleService(repo, c.Logger)
+	c.RoleService = roleApp.NewRoleService(repo, c.PermissionResolver, c.AuditService, c.Logger)
 	c.RoleHandler = roleHTTP.NewRoleHandler(c.RoleService)
 }
 
+func (c *ServerContainer) initAudit() {
+	repo := auditPG.NewEventRepository(c.postgresDB)
+	c.AuditService = auditApp.NewAuditService(repo, c.Config.Audit.Retention, c.Config.Audit.PurgeInterval, c.Logger)
+	c.AuditHandler = auditHTTP.NewAuditHandler(c.AuditService)
+	go c.AuditService.Run(c.background)
+}
+
 func (c *ServerContainer) initNotification() {
 	c.NotificationDashboardHandler = notificationHTTP.NewNotificationDashboardHandler()
 }
 
 func (c *ServerContainer) initProject() {
 	repo := projectPG.NewProjectRepository(c.postgresDB)
-	c.ProjectService = projectApp.NewProjectService(repo, c.Logger)
+	c.ProjectService = projectApp.NewProjectService(repo, c.PermissionResolver, c.TenantService, c.AuditService, c.Logger)
 	c.ProjectHandler = projectHTTP.NewProjectHandler(c.ProjectService)
 }
 
+func (c *ServerContainer) initRetention() {
+	repo := retentionPG.NewPolicyRepository(c.postgresDB)
+	store := retentionCH.NewLogStore(c.ClickHouseDB)
+	c.RetentionService = retentionApp.NewRetentionService(repo, store, c.TenantService, c.Logger)
+	c.RetentionHandler = retentionHTTP.NewRetentionHandler(c.RetentionService)
+}
+
+func (c *ServerContainer) initSampling() {
+	repo := samplingPG.NewPolicyRepository(c.postgresDB)
+	c.SamplingService = samplingApp.NewSamplingService(repo, c.Logger)
+	c.SamplingHandler = samplingHTTP.NewSamplingHandler(c.SamplingService)
+}
+
+func (c *ServerContainer) initPipeline() {
+	repo := pipelinePG.NewPipelineRepository(c.postgresDB)
+	c.PipelineService = pipelineApp.NewPipelineService(repo, c.Logger)
+	c.PipelineHandler = pipelineHTTP.NewPipelineHandler(c.PipelineService)
+}
+
+func (c *ServerContainer) initRedaction() {
+	repo := redactionPG.NewRuleRepository(c.postgresDB)
+	c.RedactionService = redactionApp.NewRedactionService(repo, repo, c.Logger)
+	c.RedactionHandler = redactionHTTP.NewRedactionHandler(c.RedactionService)
+}
+
+func (c *ServerContainer) initLogMetric() {
+	repo := logmetricPG.NewRuleRepository(c.postgresDB)
+	c.MetricService = logmetricApp.NewMetricService(repo, repo, c.Logger)
+	c.MetricHandler = logmetricHTTP.NewMetricHandler(c.MetricService)
+}
+
+func (c *ServerContainer) initArchive() error {
+	cfg := c.Config.Archive
+
+	// A nil store disables archival; it must be an untyped nil, not a nil
+	// *objectstore.Client.
+	var store archiveApp.ObjectStore
+	if cfg.Enabled {
+		client, err := objectstore.New(objectstore.Config{
+			Endpoint:  cfg.Endpoint,
+			Region:    cfg.Region,
+			Bucket:    cfg.Bucket,
+			AccessKey: cfg.AccessKey,
+			SecretKey: cfg.SecretKey,
+		})
+		if err != nil {
+			return fmt.Errorf("archive: %w", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		// An unreachable store is retried by every archive run; it must not
+		// keep the API from starting.
+		if err := client.EnsureBucket(ctx); err != nil {
+			c.Logger.Warn("archive bucket unavailable", zap.String("bucket", cfg.Bucket), zap.Error(err))
+		}
+		store = client
+	}
+
+	c.ArchiveService = archiveApp.NewArchiveService(
+		archivePG.NewJobRepository(c.postgresDB),
+		archivePG.NewSegmentRepository(c.postgresDB),
+		archiveCH.NewLogStore(c.ClickHouseDB),
+		store,
+		c.PermissionResolver,
+		archiveApp.Config{
+			Prefix:            cfg.Prefix,
+			After:             cfg.After,
+			Lookback:          cfg.Lookback,
+			Interval:          cfg.Interval,
+			PollInterval:      cfg.PollInterval,
+			RehydrateTTL:      cfg.RehydrateTTL,
+			MaxRehydrateRange: cfg.MaxRehydrateRange,
+			MaxAttempts:       cfg.MaxAttempts,
+		},
+		c.Logger,
+	)
+	c.ArchiveHandler = archiveHTTP.NewArchiveHandler(c.ArchiveService)
+
+	go c.ArchiveService.Run(c.background)
+	return nil
+}
+
+func (c *ServerContainer) initTenant() {
+	repo := tenantPG.NewTenantRepository(c.postgresDB)
+	c.TenantService = tenantApp.NewTenantService(repo, c.AuditService, c.Logger)
+	c.TenantHandler = tenantHTTP.NewTenantHandler(c.TenantService)
+}
+
 func (c *ServerContainer) RegisterAllRoutes(e *gin.Engine) {
+	// Every mutating call is audited, including those rejected by auth.
+	// Ingest is too voluminous to record; search, aggregate and field values
+	// are reads (searches of sensitive projects are recorded by the search
+	// service).
+	e.Use(middleware.AuditLog(c.AuditService, "/v1/logs", "/v1/logs/search", "/v1/logs/aggregate",
+		"/v1/logs/field-values"))
+
 	root := &e.RouterGroup
 
 	// Public routes — reachable without a token.
 	authHTTP.RegisterRoutes(root, c.AuthHandler)
+	userHTTP.RegisterInviteRoutes(root, c.UserManagementHandler)
 
 	// Protected routes — authentication is applied once here, so every context
 	// mounted on `secured` is authenticated by default. New contexts added here
 	// inherit auth automatically; do not re-add AuthMiddleware inside them.
-	secured := root.Group("", middleware.AuthMiddleware(c.JWT))
-	ingestHTTP.RegisterRoutes(secured, c.IngestHandler)
-	searchHTTP.RegisterRoutes(secured, c.SearchHandler)
-	projectHTTP.RegisterRoutes(secured, c.ProjectHandler)
+	secured := root.Group("", middleware.AuthMiddleware(c.JWT, c.AccessTokenService))
+	authHTTP.RegisterSecuredRoutes(secured, c.AuthHandler)
+	authHTTP.RegisterAccessTokenRoutes(secured, c.AccessTokenHandler, c.Authorizer)
+	authHTTP.RegisterAdminRoutes(secured, c.AuthHandler, c.Authorizer)
+	tenantHTTP.RegisterRoutes(secured, c.TenantHandler, c.Authorizer)
+	ingestHTTP.RegisterUsageRoutes(secured, c.UsageHandler, c.Authorizer)
+
+	// Data plane — suspended tenants keep access to their account and settings
+	// but can neither ingest nor query logs.
+	dataPlane := secured.Group("", middleware.RequireActiveTenant(c.TenantService))
+	ingestHTTP.RegisterRoutes(dataPlane, c.IngestHandler)
+	searchHTTP.RegisterRoutes(dataPlane, c.SearchHandler, c.Authorizer)
+	archiveHTTP.RegisterRoutes(dataPlane, c.ArchiveHandler, c.Authorizer)
+	logmetricHTTP.RegisterExpositionRoutes(dataPlane, c.MetricHandler, c.Authorizer)
+
+	projectHTTP.RegisterRoutes(secured, c.ProjectHandler, c.Authorizer)
+	retentionHTTP.RegisterRoutes(secured, c.RetentionHandler, c.Authorizer)
+	samplingHTTP.RegisterRoutes(secured, c.SamplingHandler, c.Authorizer)
+	pipelineHTTP.RegisterRoutes(secured, c.PipelineHandler, c.Authorizer)
+	redactionHTTP.RegisterRoutes(secured, c.RedactionHandler, c.Authorizer)
+	logmetricHTTP.RegisterRoutes(secured, c.MetricHandler, c.Authorizer)
+	userHTTP.RegisterManagementRoutes(secured, c.UserManagementHandler, c.Authorizer)
+	roleHTTP.RegisterRoutes(secured, c.RoleHandler, c.Authorizer)
+	notificationHTTP.RegisterRoutes(secured, c.NotificationDashboardHandler, c.Authorizer)
+	auditHTTP.RegisterRoutes(secured, c.AuditHandler, c.Authorizer)
 }
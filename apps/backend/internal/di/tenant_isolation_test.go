@@ -0,0 +1,887 @@
+package di
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	auditApp "github.com/indalyadav56/logify/apps/backend/internal/audit/application"
+	auditDomain "github.com/indalyadav56/logify/apps/backend/internal/audit/domain"
+	auditHTTP "github.com/indalyadav56/logify/apps/backend/internal/audit/transport/http"
+	authApp "github.com/indalyadav56/logify/apps/backend/internal/auth/application"
+	authHTTP "github.com/indalyadav56/logify/apps/backend/internal/auth/transport/http"
+	ingestApp "github.com/indalyadav56/logify/apps/backend/internal/ingest/application"
+	ingestDomain "github.com/indalyadav56/logify/apps/backend/internal/ingest/domain"
+	ingestHTTP "github.com/indalyadav56/logify/apps/backend/internal/ingest/transport/http"
+	notificationHTTP "github.com/indalyadav56/logify/apps/backend/internal/notification/transport/http"
+	projectApp "github.com/indalyadav56/logify/apps/backend/internal/project/application"
+	projectDomain "github.com/indalyadav56/logify/apps/backend/internal/project/domain"
+	projectHTTP "github.com/indalyadav56/logify/apps/backend/internal/project/transport/http"
+	roleApp "github.com/indalyadav56/logify/apps/backend/internal/role/application"
+	roleDomain "github.com/indalyadav56/logify/apps/backend/internal/role/domain"
+	roleHTTP "github.com/indalyadav56/logify/apps/backend/internal/role/transport/http"
+	searchApp "github.com/indalyadav56/logify/apps/backend/internal/search/application"
+	searchDomain "github.com/indalyadav56/logify/apps/backend/internal/search/domain"
+	searchHTTP "github.com/indalyadav56/logify/apps/backend/internal/search/transport/http"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
+	tenantApp "github.com/indalyadav56/logify/apps/backend/internal/tenant/application"
+	tenantDomain "github.com/indalyadav56/logify/apps/backend/internal/tenant/domain"
+	tenantHTTP "github.com/indalyadav56/logify/apps/backend/internal/tenant/transport/http"
+	userHTTP "github.com/indalyadav56/logify/apps/backend/internal/user/transport/http"
+	jwtpkg "github.com/indalyadav56/logify/apps/backend/pkg/jwt"
+)
+
+// The suite below drives the real route table (RegisterAllRoutes), handlers,
+// services and permission resolver. Only storage is replaced, by in-memory
+// repositories that implement the same tenant-scoped contracts as the SQL
+// ones: every lookup filters on the tenant it is given.
+
+type memProjects struct {
+	mu   sync.Mutex
+	byID map[uuid.UUID]*projectDomain.Project
+}
+
+func (r *memProjects) Create(_ context.Context, p *projectDomain.Project) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p.ID = uuid.New()
+	cp := *p
+	r.byID[p.ID] = &cp
+	return nil
+}
+
+func (r *memProjects) GetByID(_ context.Context, tenantID, id uuid.UUID) (*projectDomain.Project, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.byID[id]
+	if !ok || p.TenantID != tenantID {
+		return nil, projectDomain.ErrProjectNotFound
+	}
+	cp := *p
+	return &cp, nil
+}
+
+func (r *memProjects) List(_ context.Context, tenantID uuid.UUID) ([]*projectDomain.Project, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]*projectDomain.Project, 0)
+	for _, p := range r.byID {
+		if p.TenantID == tenantID {
+			cp := *p
+			out = append(out, &cp)
+		}
+	}
+	return out, nil
+}
+
+func (r *memProjects) Count(ctx context.Context, tenantID uuid.UUID) (int, error) {
+	items, err := r.List(ctx, tenantID)
+	return len(items), err
+}
+
+func (r *memProjects) Update(_ context.Context, p *projectDomain.Project) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.byID[p.ID]
+	if !ok || stored.TenantID != p.TenantID {
+		return projectDomain.ErrProjectNotFound
+	}
+	stored.Name, stored.Description, stored.Sensitive = p.Name, p.Description, p.Sensitive
+	return nil
+}
+
+func (r *memProjects) Delete(_ context.Context, tenantID, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.byID[id]
+	if !ok || p.TenantID != tenantID {
+		return projectDomain.ErrProjectNotFound
+	}
+	delete(r.byID, id)
+	return nil
+}
+
+func (r *memProjects) inTenant(tenantID, id uuid.UUID) bool {
+	_, err := r.GetByID(context.Background(), tenantID, id)
+	return err == nil
+}
+
+type memRoles struct {
+	mu   sync.Mutex
+	byID map[uuid.UUID]*roleDomain.Role
+}
+
+func (r *memRoles) visible(tenantID, id uuid.UUID) (*roleDomain.Role, bool) {
+	role, ok := r.byID[id]
+	if !ok || (role.TenantID != nil && *role.TenantID != tenantID) {
+		return nil, false
+	}
+	return role, true
+}
+
+func (r *memRoles) owned(tenantID, id uuid.UUID) (*roleDomain.Role, bool) {
+	role, ok := r.byID[id]
+	if !ok || role.TenantID == nil || *role.TenantID != tenantID {
+		return nil, false
+	}
+	return role, true
+}
+
+func (r *memRoles) Create(_ context.Context, role *roleDomain.Role) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cp := *role
+	r.byID[role.ID] = &cp
+	return nil
+}
+
+func (r *memRoles) GetByID(_ context.Context, tenantID, id uuid.UUID) (*roleDomain.Role, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	role, ok := r.visible(tenantID, id)
+	if !ok {
+		return nil, roleDomain.ErrRoleNotFound
+	}
+	cp := *role
+	return &cp, nil
+}
+
+func (r *memRoles) Update(_ context.Context, tenantID uuid.UUID, role *roleDomain.Role) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.owned(tenantID, role.ID)
+	if !ok {
+		return roleDomain.ErrRoleNotFound
+	}
+	stored.Name, stored.Description = role.Name, role.Description
+	return nil
+}
+
+func (r *memRoles) Delete(_ context.Context, tenantID, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.owned(tenantID, id); !ok {
+		return roleDomain.ErrRoleNotFound
+	}
+	delete(r.byID, id)
+	return nil
+}
+
+func (r *memRoles) List(_ context.Context, f roleDomain.ListFilter) ([]*roleDomain.Role, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]*roleDomain.Role, 0)
+	for id := range r.byID {
+		if role, ok := r.visible(f.TenantID, id); ok {
+			cp := *role
+			out = append(out, &cp)
+		}
+	}
+	return out, int64(len(out)), nil
+}
+
+func (r *memRoles) ListPermissions(_ context.Context, tenantID, roleID uuid.UUID) ([]roleDomain.Permission, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	role, ok := r.visible(tenantID, roleID)
+	if !ok {
+		return []roleDomain.Permission{}, nil
+	}
+	return role.Permissions, nil
+}
+
+func (r *memRoles) AddPermission(_ context.Context, tenantID, roleID uuid.UUID, perm roleDomain.Permission) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	role, ok := r.owned(tenantID, roleID)
+	if !ok {
+		return roleDomain.ErrRoleNotFound
+	}
+	role.Permissions = append(role.Permissions, perm)
+	return nil
+}
+
+func (r *memRoles) RemovePermission(_ context.Context, tenantID, roleID uuid.UUID, perm roleDomain.Permission) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	role, ok := r.owned(tenantID, roleID)
+	if !ok {
+		return roleDomain.ErrPermissionNotFound
+	}
+	for i, p := range role.Permissions {
+		if p == perm {
+			role.Permissions = append(role.Permissions[:i], role.Permissions[i+1:]...)
+			return nil
+		}
+	}
+	return roleDomain.ErrPermissionNotFound
+}
+
+func (r *memRoles) ReplacePermissions(_ context.Context, tenantID, roleID uuid.UUID, perms []roleDomain.Permission) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	role, ok := r.owned(tenantID, roleID)
+	if !ok {
+		return roleDomain.ErrRoleNotFound
+	}
+	role.Permissions = perms
+	return nil
+}
+
+type memLogs struct {
+	entries []searchDomain.LogEntry
+}
+
+func (r *memLogs) match(q searchDomain.Query) []searchDomain.LogEntry {
+	out := make([]searchDomain.LogEntry, 0)
+	for _, e := range r.entries {
+		if e.TenantID == q.TenantID && e.ProjectID == q.ProjectID {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func (r *memLogs) Search(_ context.Context, q searchDomain.Query) (*searchDomain.SearchResult, error) {
+	if q.TenantID == "" {
+		return nil, searchDomain.ErrTenantIDRequired
+	}
+	logs := r.match(q)
+	return &searchDomain.SearchResult{Logs: logs, Total: uint64(len(logs))}, nil
+}
+
+func (r *memLogs) GetByID(_ context.Context, tenantID, logID string) (*searchDomain.LogEntry, error) {
+	for _, e := range r.entries {
+		if e.TenantID == tenantID && e.LogID == logID {
+			cp := e
+			return &cp, nil
+		}
+	}
+	return nil, searchDomain.ErrLogNotFound
+}
+
+func (r *memLogs) Aggregate(_ context.Context, req searchDomain.AggregationRequest) (*searchDomain.AggregationResult, error) {
+	if req.Query.TenantID == "" {
+		return nil, searchDomain.ErrTenantIDRequired
+	}
+	n := uint64(len(r.match(req.Query)))
+	if n == 0 {
+		return &searchDomain.AggregationResult{}, nil
+	}
+	return &searchDomain.AggregationResult{Buckets: []searchDomain.AggBucket{{Key: "info", Count: n}}}, nil
+}
+
+func (r *memLogs) Fields(_ context.Context, q searchDomain.Query) ([]searchDomain.Field, error) {
+	if q.TenantID == "" {
+		return nil, searchDomain.ErrTenantIDRequired
+	}
+	if len(r.match(q)) == 0 {
+		return nil, nil
+	}
+	return []searchDomain.Field{{Name: "attributes.alpha_secret", Type: searchDomain.FieldString, Logs: 1}}, nil
+}
+
+func (r *memLogs) FieldValues(_ context.Context, req searchDomain.FieldValuesRequest) (*searchDomain.FieldValues, error) {
+	if req.Query.TenantID == "" {
+		return nil, searchDomain.ErrTenantIDRequired
+	}
+	values := &searchDomain.FieldValues{Field: req.Field}
+	for _, e := range r.match(req.Query) {
+		values.Values = append(values.Values, e.Body)
+		values.Logs++
+	}
+	return values, nil
+}
+
+type memProducer struct {
+	mu       sync.Mutex
+	produced []ingestDomain.Log
+	err      error
+}
+
+func (p *memProducer) Produce(_ context.Context, log ingestDomain.Log) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+	p.produced = append(p.produced, log)
+	return nil
+}
+
+// fixedAdmitter stands in for the Redis-backed IngestLimiter.
+type fixedAdmitter struct {
+	err      error
+	accepted int
+}
+
+func (a *fixedAdmitter) Admit(context.Context, uuid.UUID, uuid.UUID, ingestDomain.Log) error {
+	return a.err
+}
+
+func (a *fixedAdmitter) Accept(context.Context, uuid.UUID, uuid.UUID, ingestDomain.Log) {
+	a.accepted++
+}
+
+// memPermissions mirrors effectivePermissionsQuery: a user owns the personal
+// tenant whose id equals their own, a project member holds their role on
+// that project only, and a project scope outside the tenant grants nothing.
+type memPermissions struct {
+	projects *memProjects
+	owner    []roleDomain.Permission
+	// members maps a user to the permissions of their role per project.
+	members map[uuid.UUID]map[uuid.UUID][]roleDomain.Permission
+}
+
+func (r *memPermissions) EffectivePermissions(_ context.Context, scope roleDomain.PermissionScope) ([]roleDomain.Permission, error) {
+	if scope.ProjectID != nil && !r.projects.inTenant(scope.TenantID, *scope.ProjectID) {
+		return nil, nil
+	}
+	if scope.TenantID == scope.UserID {
+		return r.owner, nil
+	}
+	if scope.ProjectID != nil {
+		return r.members[scope.UserID][*scope.ProjectID], nil
+	}
+	return nil, nil
+}
+
+// memTenants answers the data-plane status check and quota lookups; every
+// tenant is active unless suspended here and has the free plan quota unless
+// overridden. The admin API is not exercised by this suite.
+type memTenants struct {
+	tenantApp.TenantService
+	mu        sync.Mutex
+	suspended map[uuid.UUID]bool
+	quotas    map[uuid.UUID]tenantDomain.Quota
+}
+
+func (t *memTenants) GetQuota(_ context.Context, tenantID uuid.UUID) (*tenantDomain.Quota, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	q, ok := t.quotas[tenantID]
+	if !ok {
+		q = tenantDomain.DefaultQuota(tenantID, tenantDomain.PlanFree)
+	}
+	return &q, nil
+}
+
+// memQueryCounter stands in for the Redis query counter. Its window never
+// rolls over.
+type memQueryCounter struct {
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+func (c *memQueryCounter) Incr(_ context.Context, tenantID string, _ time.Time, _ time.Duration) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts[tenantID]++
+	return c.counts[tenantID], nil
+}
+
+func (t *memTenants) IsTenantActive(_ context.Context, tenantID uuid.UUID) (bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return !t.suspended[tenantID], nil
+}
+
+type memAuditEvents struct {
+	mu     sync.Mutex
+	events []*auditDomain.Event
+}
+
+func (r *memAuditEvents) Append(_ context.Context, e *auditDomain.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e.ID = uuid.New()
+	cp := *e
+	r.events = append(r.events, &cp)
+	return nil
+}
+
+func (r *memAuditEvents) List(_ context.Context, f auditDomain.Filter) ([]*auditDomain.Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]*auditDomain.Event, 0)
+	for i := len(r.events) - 1; i >= 0 && len(out) < f.Limit; i-- {
+		if e := r.events[i]; f.TenantID == nil || e.TenantID == *f.TenantID {
+			cp := *e
+			out = append(out, &cp)
+		}
+	}
+	return out, nil
+}
+
+func (r *memAuditEvents) Purge(context.Context, time.Time, int) (int64, error) {
+	return 0, nil
+}
+
+type isolationFixture struct {
+	engine   *gin.Engine
+	jwt      *jwtpkg.JWT
+	projects *memProjects
+	roles    *memRoles
+	logs     *memLogs
+	producer *memProducer
+	tenants  *memTenants
+	admitter *fixedAdmitter
+	perms    *memPermissions
+}
+
+func newIsolationFixture(t *testing.T) *isolationFixture {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	logger := zap.NewNop()
+
+	f := &isolationFixture{
+		jwt: jwtpkg.New(jwtpkg.JWTConfig{
+			SecretKey:        []byte("isolation-test"),
+			SigningAlgorithm: gojwt.SigningMethodHS256,
+			TokenDuration:    time.Minute,
+		}),
+		projects: &memProjects{byID: map[uuid.UUID]*projectDomain.Project{}},
+		roles:    &memRoles{byID: map[uuid.UUID]*roleDomain.Role{}},
+		logs:     &memLogs{},
+		producer: &memProducer{},
+		tenants:  &memTenants{suspended: map[uuid.UUID]bool{}, quotas: map[uuid.UUID]tenantDomain.Quota{}},
+		admitter: &fixedAdmitter{},
+	}
+
+	var owner []roleDomain.Permission
+	for _, res := range []string{roleDomain.ResourceLog, roleDomain.ResourceProject, roleDomain.ResourceRole,
+		roleDomain.ResourceAlert, roleDomain.ResourceNotification, roleDomain.ResourceAudit} {
+		owner = append(owner, roleDomain.Permission{Resource: res, Action: roleDomain.ActionManage})
+	}
+	f.perms = &memPermissions{projects: f.projects, owner: owner, members: map[uuid.UUID]map[uuid.UUID][]roleDomain.Permission{}}
+	resolver := roleApp.NewPermissionResolver(f.perms, time.Minute, logger)
+
+	ingestSvc := ingestApp.NewIngestService(f.producer, resolver, f.admitter, nil, nil)
+	auditSvc := auditApp.NewAuditService(&memAuditEvents{}, 0, 0, logger)
+	projectSvc := projectApp.NewProjectService(f.projects, resolver, f.tenants, auditSvc, logger)
+	queryLimits := searchApp.NewQueryLimiter(&memQueryCounter{counts: map[string]int64{}}, f.tenants, logger)
+	searchSvc := searchApp.NewSearchService(f.logs, nil, resolver, queryLimits, projectSvc, nil, auditSvc, logger)
+	c := &ServerContainer{
+		Logger:                       logger,
+		JWT:                          f.jwt,
+		AuthHandler:                  authHTTP.NewAuthHandler(authApp.NewMockAuthService(t)),
+		PermissionResolver:           resolver,
+		Authorizer:                   middleware.NewAuthorizer(resolver),
+		IngestHandler:                ingestHTTP.NewIngestHandler(ingestSvc),
+		SearchHandler:                searchHTTP.NewHandler(searchSvc, logger),
+		ProjectHandler:               projectHTTP.NewProjectHandler(projectSvc),
+		RoleHandler:                  roleHTTP.NewRoleHandler(roleApp.NewRoleService(f.roles, resolver, auditSvc, logger)),
+		AuditService:                 auditSvc,
+		AuditHandler:                 auditHTTP.NewAuditHandler(auditSvc),
+		NotificationDashboardHandler: notificationHTTP.NewNotificationDashboardHandler(),
+		TenantService:                f.tenants,
+		TenantHandler:                tenantHTTP.NewTenantHandler(f.tenants),
+		UsageHandler:                 ingestHTTP.NewUsageHandler(nil),
+		UserManagementHandler:        userHTTP.NewUserManagementHandler(nil),
+		AccessTokenHandler:           authHTTP.NewAccessTokenHandler(nil, nil),
+	}
+	f.engine = gin.New()
+	c.RegisterAllRoutes(f.engine)
+	return f
+}
+
+// tenantUser returns a user and an access token for their personal tenant.
+func (f *isolationFixture) tenantUser(t *testing.T) (uuid.UUID, string) {
+	t.Helper()
+	id := uuid.New()
+	return id, f.token(t, id, id)
+}
+
+// token returns an access token of userID for tenantID, as issued at login
+// or by switching tenant.
+func (f *isolationFixture) token(t *testing.T, userID, tenantID uuid.UUID) string {
+	t.Helper()
+	token, err := f.jwt.GenerateToken(map[string]interface{}{
+		"sub":       userID.String(),
+		"tenant_id": tenantID.String(),
+	})
+	require.NoError(t, err)
+	return token
+}
+
+func (f *isolationFixture) do(t *testing.T, token, method, path string, body any, headers ...string) *httptest.ResponseRecorder {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&buf).Encode(body))
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	f.engine.ServeHTTP(w, req)
+	return w
+}
+
+func decodeData(t *testing.T, w *httptest.ResponseRecorder, v any) {
+	t.Helper()
+	var env struct {
+		Data json.RawMessage `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+	require.NoError(t, json.Unmarshal(env.Data, v))
+}
+
+// assertDenied accepts 403 (blocked by RequireProjectPermission) or 404
+// (blocked by a tenant-scoped lookup); both hide whether the id exists.
+func assertDenied(t *testing.T, w *httptest.ResponseRecorder, leak string) {
+	t.Helper()
+	assert.Contains(t, []int{http.StatusForbidden, http.StatusNotFound}, w.Code, w.Body.String())
+	if leak != "" {
+		assert.NotContains(t, w.Body.String(), leak)
+	}
+}
+
+func TestTenantIsolation(t *testing.T) {
+	f := newIsolationFixture(t)
+	userA, tokenA := f.tenantUser(t)
+	_, tokenB := f.tenantUser(t)
+
+	// Tenant A sets up a project, a role and some logs.
+	w := f.do(t, tokenA, http.MethodPost, "/v1/projects", map[string]string{"name": "alpha-secret"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var project projectApp.ProjectOutput
+	decodeData(t, w, &project)
+	require.Equal(t, userA, project.TenantID)
+	projectA := project.ID.String()
+
+	w = f.do(t, tokenA, http.MethodPost, "/v1/roles", map[string]any{
+		"name":        "alpha-oncall",
+		"permissions": []map[string]string{{"resource": "log", "action": "read"}},
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var role roleApp.RoleOutput
+	decodeData(t, w, &role)
+	roleA := role.ID.String()
+
+	logA := uuid.NewString()
+	f.logs.entries = append(f.logs.entries, searchDomain.LogEntry{
+		LogID: logA, TenantID: userA.String(), ProjectID: projectA, Body: "alpha-log-line",
+	})
+
+	now := time.Now().UTC()
+	window := map[string]time.Time{"from": now.Add(-time.Hour), "to": now}
+	searchBody := map[string]any{"project_id": projectA, "time_range": window}
+	aggBody := map[string]any{"project_id": projectA, "from": window["from"], "to": window["to"], "group_by": "level"}
+	valuesBody := map[string]any{"project_id": projectA, "time_range": window, "field": "service"}
+	ingestBody := map[string]any{"project_id": projectA, "tenant_id": userA.String(), "level": "info", "message": "x"}
+
+	t.Run("owner has access", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, f.do(t, tokenA, http.MethodGet, "/v1/projects/"+projectA, nil).Code)
+		assert.Equal(t, http.StatusOK, f.do(t, tokenA, http.MethodGet, "/v1/roles/"+roleA, nil).Code)
+		w := f.do(t, tokenA, http.MethodPost, "/v1/logs/search", searchBody)
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Contains(t, w.Body.String(), "alpha-log-line")
+		assert.Equal(t, http.StatusOK, f.do(t, tokenA, http.MethodGet, "/v1/logs/"+logA, nil).Code)
+		assert.Equal(t, http.StatusOK, f.do(t, tokenA, http.MethodPost, "/v1/logs/aggregate", aggBody).Code)
+		w = f.do(t, tokenA, http.MethodGet, "/v1/projects/"+projectA+"/fields", nil)
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Contains(t, w.Body.String(), "alpha_secret")
+		w = f.do(t, tokenA, http.MethodPost, "/v1/logs/field-values", valuesBody)
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Contains(t, w.Body.String(), "alpha-log-line")
+		assert.Equal(t, http.StatusAccepted, f.do(t, tokenA, http.MethodPost, "/v1/logs", ingestBody).Code)
+	})
+
+	f.producer.produced = nil
+
+	t.Run("projects", func(t *testing.T) {
+		assertDenied(t, f.do(t, tokenB, http.MethodGet, "/v1/projects/"+projectA, nil), "alpha-secret")
+		assertDenied(t, f.do(t, tokenB, http.MethodPut, "/v1/projects/"+projectA, map[string]string{"name": "pwned"}), "")
+		assertDenied(t, f.do(t, tokenB, http.MethodDelete, "/v1/projects/"+projectA, nil), "")
+
+		w := f.do(t, tokenB, http.MethodGet, "/v1/projects", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), projectA)
+
+		stored, err := f.projects.GetByID(context.Background(), userA, project.ID)
+		require.NoError(t, err, "project must survive cross-tenant delete")
+		assert.Equal(t, "alpha-secret", stored.Name, "project must survive cross-tenant update")
+	})
+
+	t.Run("search", func(t *testing.T) {
+		w := f.do(t, tokenB, http.MethodPost, "/v1/logs/search", searchBody)
+		assertDenied(t, w, "alpha-log-line")
+
+		// A spoofed tenant header is ignored; the token decides.
+		w = f.do(t, tokenB, http.MethodPost, "/v1/logs/search", searchBody, "X-Tenant-ID", userA.String())
+		assertDenied(t, w, "alpha-log-line")
+
+		assertDenied(t, f.do(t, tokenB, http.MethodGet, "/v1/logs/"+logA, nil), "alpha-log-line")
+		assertDenied(t, f.do(t, tokenB, http.MethodGet, "/v1/logs/"+logA+"?tenant_id="+userA.String(), nil), "alpha-log-line")
+		assertDenied(t, f.do(t, tokenB, http.MethodPost, "/v1/logs/aggregate", aggBody), "")
+		assertDenied(t, f.do(t, tokenB, http.MethodGet, "/v1/projects/"+projectA+"/fields", nil), "alpha_secret")
+		assertDenied(t, f.do(t, tokenB, http.MethodPost, "/v1/logs/field-values", valuesBody), "alpha-log-line")
+	})
+
+	t.Run("ingest", func(t *testing.T) {
+		assertDenied(t, f.do(t, tokenB, http.MethodPost, "/v1/logs", ingestBody), "")
+		assert.Empty(t, f.producer.produced, "nothing may be published into another tenant's project")
+	})
+
+	t.Run("roles", func(t *testing.T) {
+		assertDenied(t, f.do(t, tokenB, http.MethodGet, "/v1/roles/"+roleA, nil), "alpha-oncall")
+		assertDenied(t, f.do(t, tokenB, http.MethodPut, "/v1/roles/"+roleA, map[string]string{"name": "pwned"}), "")
+		assertDenied(t, f.do(t, tokenB, http.MethodDelete, "/v1/roles/"+roleA, nil), "")
+		assertDenied(t, f.do(t, tokenB, http.MethodGet, "/v1/roles/"+roleA+"/permissions", nil), "")
+		assertDenied(t, f.do(t, tokenB, http.MethodPost, "/v1/roles/"+roleA+"/permissions",
+			map[string]string{"resource": "log", "action": "write"}), "")
+		assertDenied(t, f.do(t, tokenB, http.MethodPut, "/v1/roles/"+roleA+"/permissions",
+			map[string]any{"permissions": []map[string]string{{"resource": "log", "action": "write"}}}), "")
+		assertDenied(t, f.do(t, tokenB, http.MethodDelete, "/v1/roles/"+roleA+"/permissions/log/read", nil), "")
+
+		w := f.do(t, tokenB, http.MethodGet, "/v1/roles", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), roleA)
+
+		stored, err := f.roles.GetByID(context.Background(), userA, role.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "alpha-oncall", stored.Name)
+		assert.Equal(t, []roleDomain.Permission{{Resource: "log", Action: "read"}}, stored.Permissions)
+	})
+
+	t.Run("effective permissions", func(t *testing.T) {
+		w := f.do(t, tokenB, http.MethodGet, "/v1/me/permissions?project_id="+projectA, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		var out struct {
+			Permissions []roleApp.PermissionInput `json:"permissions"`
+		}
+		decodeData(t, w, &out)
+		assert.Empty(t, out.Permissions)
+	})
+
+	t.Run("audit trail", func(t *testing.T) {
+		w := f.do(t, tokenA, http.MethodGet, "/v1/audit-events", nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var trail auditApp.ListEventsOutput
+		decodeData(t, w, &trail)
+		var created, projectCreated *auditDomain.Event
+		for _, e := range trail.Events {
+			switch e.Action {
+			case auditDomain.ActionRoleCreated:
+				created = e
+			case auditDomain.ActionProjectCreated:
+				projectCreated = e
+			}
+		}
+		require.NotNil(t, created)
+		assert.Equal(t, roleA, created.TargetID)
+		assert.Equal(t, userA, created.ActorID)
+		require.NotNil(t, projectCreated)
+		assert.Equal(t, projectA, projectCreated.TargetID)
+		assert.Equal(t, "alpha-secret", projectCreated.After["name"])
+
+		// B sees its own denied attempts, never A's records.
+		w = f.do(t, tokenB, http.MethodGet, "/v1/audit-events", nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.NotContains(t, w.Body.String(), "alpha-oncall")
+		assert.NotContains(t, w.Body.String(), userA.String())
+	})
+}
+
+// TestProjectMemberAccess covers a user invited to one project of another
+// tenant: they hold no tenant-level permission, only their project role.
+func TestProjectMemberAccess(t *testing.T) {
+	f := newIsolationFixture(t)
+	owner, ownerToken := f.tenantUser(t)
+
+	ids := map[string]string{}
+	for _, name := range []string{"alpha", "beta"} {
+		w := f.do(t, ownerToken, http.MethodPost, "/v1/projects", map[string]string{"name": name})
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var project projectApp.ProjectOutput
+		decodeData(t, w, &project)
+		ids[name] = project.ID.String()
+		f.logs.entries = append(f.logs.entries, searchDomain.LogEntry{
+			LogID: uuid.NewString(), TenantID: owner.String(), ProjectID: ids[name], Body: name + "-log-line",
+		})
+	}
+	alpha, beta := ids["alpha"], ids["beta"]
+	alphaLog, betaLog := f.logs.entries[0].LogID, f.logs.entries[1].LogID
+
+	member := uuid.New()
+	f.perms.members[member] = map[uuid.UUID][]roleDomain.Permission{
+		uuid.MustParse(alpha): {
+			{Resource: roleDomain.ResourceLog, Action: roleDomain.ActionRead},
+			{Resource: roleDomain.ResourceLog, Action: roleDomain.ActionWrite},
+			{Resource: roleDomain.ResourceProject, Action: roleDomain.ActionRead},
+		},
+	}
+	token := f.token(t, member, owner)
+
+	now := time.Now().UTC()
+	window := map[string]time.Time{"from": now.Add(-time.Hour), "to": now}
+	searchBody := func(project string) map[string]any {
+		return map[string]any{"project_id": project, "time_range": window}
+	}
+
+	t.Run("member project", func(t *testing.T) {
+		w := f.do(t, token, http.MethodPost, "/v1/logs/search", searchBody(alpha))
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Contains(t, w.Body.String(), "alpha-log-line")
+		assert.Equal(t, http.StatusOK, f.do(t, token, http.MethodGet, "/v1/logs/"+alphaLog, nil).Code)
+		assert.Equal(t, http.StatusOK, f.do(t, token, http.MethodPost, "/v1/logs/aggregate",
+			map[string]any{"project_id": alpha, "from": window["from"], "to": window["to"], "group_by": "level"}).Code)
+		assert.Equal(t, http.StatusOK, f.do(t, token, http.MethodGet, "/v1/projects/"+alpha+"/fields", nil).Code)
+		assert.Equal(t, http.StatusOK, f.do(t, token, http.MethodPost, "/v1/logs/field-values",
+			map[string]any{"project_id": alpha, "time_range": window, "field": "service"}).Code)
+		assert.Equal(t, http.StatusAccepted, f.do(t, token, http.MethodPost, "/v1/logs",
+			map[string]any{"project_id": alpha, "level": "info", "message": "x"}).Code)
+		assert.Equal(t, http.StatusOK, f.do(t, token, http.MethodGet, "/v1/projects/"+alpha, nil).Code)
+
+		w = f.do(t, token, http.MethodGet, "/v1/projects", nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Contains(t, w.Body.String(), alpha)
+		assert.NotContains(t, w.Body.String(), beta)
+	})
+
+	f.producer.produced = nil
+
+	t.Run("other projects", func(t *testing.T) {
+		assertDenied(t, f.do(t, token, http.MethodPost, "/v1/logs/search", searchBody(beta)), "beta-log-line")
+		assertDenied(t, f.do(t, token, http.MethodGet, "/v1/logs/"+betaLog, nil), "beta-log-line")
+		assertDenied(t, f.do(t, token, http.MethodGet, "/v1/projects/"+beta+"/fields", nil), "")
+		assertDenied(t, f.do(t, token, http.MethodPost, "/v1/logs",
+			map[string]any{"project_id": beta, "level": "info", "message": "x"}), "")
+		assert.Empty(t, f.producer.produced)
+		assertDenied(t, f.do(t, token, http.MethodGet, "/v1/projects/"+beta, nil), "")
+	})
+
+	t.Run("tenant-level routes", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, f.do(t, token, http.MethodPost, "/v1/projects", map[string]string{"name": "gamma"}).Code)
+		assert.Equal(t, http.StatusForbidden, f.do(t, token, http.MethodDelete, "/v1/projects/"+alpha, nil).Code)
+	})
+}
+
+func TestSuspendedTenantRejectedAtIngestAndSearch(t *testing.T) {
+	f := newIsolationFixture(t)
+	userA, tokenA := f.tenantUser(t)
+
+	w := f.do(t, tokenA, http.MethodPost, "/v1/projects", map[string]string{"name": "alpha"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var project projectApp.ProjectOutput
+	decodeData(t, w, &project)
+
+	now := time.Now().UTC()
+	searchBody := map[string]any{
+		"project_id": project.ID.String(),
+		"time_range": map[string]time.Time{"from": now.Add(-time.Hour), "to": now},
+	}
+	ingestBody := map[string]any{"project_id": project.ID.String(), "level": "info", "message": "x"}
+
+	require.Equal(t, http.StatusOK, f.do(t, tokenA, http.MethodPost, "/v1/logs/search", searchBody).Code)
+
+	f.tenants.suspended[userA] = true
+
+	assert.Equal(t, http.StatusForbidden, f.do(t, tokenA, http.MethodPost, "/v1/logs", ingestBody).Code)
+	assert.Empty(t, f.producer.produced)
+	assert.Equal(t, http.StatusForbidden, f.do(t, tokenA, http.MethodPost, "/v1/logs/search", searchBody).Code)
+	// The control plane stays reachable so the tenant can see why.
+	assert.Equal(t, http.StatusOK, f.do(t, tokenA, http.MethodGet, "/v1/projects", nil).Code)
+}
+
+func TestIngestOverLimit(t *testing.T) {
+	f := newIsolationFixture(t)
+	_, token := f.tenantUser(t)
+
+	w := f.do(t, token, http.MethodPost, "/v1/projects", map[string]string{"name": "alpha"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var project projectApp.ProjectOutput
+	decodeData(t, w, &project)
+	body := map[string]any{"project_id": project.ID.String(), "level": "debug", "message": "x"}
+
+	f.admitter.err = &ingestDomain.LimitExceededError{Reason: ingestDomain.ReasonTenantEvents, RetryAfter: 1200 * time.Millisecond}
+	w = f.do(t, token, http.MethodPost, "/v1/logs", body)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), ingestDomain.ReasonTenantEvents)
+
+	f.admitter.err = ingestDomain.ErrDropped
+	w = f.do(t, token, http.MethodPost, "/v1/logs", body)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Contains(t, w.Body.String(), `"dropped":true`)
+
+	assert.Empty(t, f.producer.produced)
+	assert.Zero(t, f.admitter.accepted)
+}
+
+func TestIngestCountsUsageOnlyOncePublished(t *testing.T) {
+	f := newIsolationFixture(t)
+	_, token := f.tenantUser(t)
+
+	w := f.do(t, token, http.MethodPost, "/v1/projects", map[string]string{"name": "alpha"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var project projectApp.ProjectOutput
+	decodeData(t, w, &project)
+	body := map[string]any{"project_id": project.ID.String(), "level": "info", "message": "x"}
+
+	f.producer.err = errors.New("kafka down")
+	assert.NotEqual(t, http.StatusAccepted, f.do(t, token, http.MethodPost, "/v1/logs", body).Code)
+	assert.Zero(t, f.admitter.accepted, "a log that was never published is not counted")
+
+	f.producer.err = nil
+	assert.Equal(t, http.StatusAccepted, f.do(t, token, http.MethodPost, "/v1/logs", body).Code)
+	assert.Equal(t, 1, f.admitter.accepted)
+}
+
+func TestTenantQuotas(t *testing.T) {
+	f := newIsolationFixture(t)
+	tenant, token := f.tenantUser(t)
+	quota := tenantDomain.DefaultQuota(tenant, tenantDomain.PlanFree)
+	quota.MaxProjects, quota.QueriesPerMinute = 1, 2
+	f.tenants.quotas[tenant] = quota
+
+	t.Run("max projects", func(t *testing.T) {
+		w := f.do(t, token, http.MethodPost, "/v1/projects", map[string]string{"name": "first"})
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		w = f.do(t, token, http.MethodPost, "/v1/projects", map[string]string{"name": "second"})
+		assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+	})
+
+	t.Run("queries per minute", func(t *testing.T) {
+		projects, err := f.projects.List(context.Background(), tenant)
+		require.NoError(t, err)
+		require.Len(t, projects, 1)
+		now := time.Now().UTC()
+		body := map[string]any{"project_id": projects[0].ID.String(),
+			"time_range": map[string]time.Time{"from": now.Add(-time.Hour), "to": now}}
+
+		for range 2 {
+			w := f.do(t, token, http.MethodPost, "/v1/logs/search", body)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		}
+		w := f.do(t, token, http.MethodPost, "/v1/logs/search", body)
+		assert.Equal(t, http.StatusTooManyRequests, w.Code, w.Body.String())
+		assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+		// Another tenant has a budget of its own.
+		other, otherToken := f.tenantUser(t)
+		w = f.do(t, otherToken, http.MethodPost, "/v1/projects", map[string]string{"name": "other"})
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		projects, err = f.projects.List(context.Background(), other)
+		require.NoError(t, err)
+		body["project_id"] = projects[0].ID.String()
+		assert.Equal(t, http.StatusOK, f.do(t, otherToken, http.MethodPost, "/v1/logs/search", body).Code)
+	})
+}
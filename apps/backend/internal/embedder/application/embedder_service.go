@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"strings"
 
+	"github.com/google/uuid"
 	ingestDomain "github.com/indalyadav56/logify/apps/backend/internal/ingest/domain"
 	"github.com/indalyadav56/logify/apps/backend/internal/embedder/domain"
+	redactionDomain "github.com/indalyadav56/logify/apps/backend/internal/redaction/domain"
 	"github.com/pgvector/pgvector-go"
 	"go.uber.org/zap"
 )
@@ -21,24 +23,35 @@ type Embedder interface {
 	Embed(ctx context.Context, text string) ([]float32, error)
 }
 
+// Redactor removes sensitive values from a log before it is embedded. It is
+// satisfied by redaction/application.RedactionRunner.
+type Redactor interface {
+	Redact(ctx context.Context, tenantID, projectID uuid.UUID, rec *redactionDomain.Record) error
+}
+
 // EmbedderService consumes logs, embeds them via Ollama, and stores rows in Postgres.
 type EmbedderService struct {
 	consumer LogConsumer
 	embedder Embedder
 	repo     domain.LogRepository
+	redactor Redactor
 	logger   *zap.Logger
 }
 
+// NewEmbedderService builds the service; redactor may be nil, in which case
+// logs are embedded as sent.
 func NewEmbedderService(
 	consumer LogConsumer,
 	embedder Embedder,
 	repo domain.LogRepository,
+	redactor Redactor,
 	logger *zap.Logger,
 ) *EmbedderService {
 	return &EmbedderService{
 		consumer: consumer,
 		embedder: embedder,
 		repo:     repo,
+		redactor: redactor,
 		logger:   logger.Named("embedder_service"),
 	}
 }
@@ -55,6 +68,12 @@ func (s *EmbedderService) handleMessage(ctx context.Context, msg []byte) error {
 		return nil
 	}
 
+	// Both the text sent to the model and the stored metadata must be redacted.
+	if err := s.redact(ctx, &log); err != nil {
+		s.logger.Error("redact log", zap.Error(err), zap.String("project_id", log.ProjectID))
+		return err
+	}
+
 	text := embeddingText(log)
 	vec, err := s.embedder.Embed(ctx, text)
 	if err != nil {
@@ -96,6 +115,56 @@ func (s *EmbedderService) handleMessage(ctx context.Context, msg []byte) error {
 	return nil
 }
 
+// redact applies the redaction rules to log. Metadata values that are not
+// strings are redacted in their JSON form and replaced by it when changed.
+func (s *EmbedderService) redact(ctx context.Context, log *ingestDomain.Log) error {
+	if s.redactor == nil {
+		return nil
+	}
+	tenantID, err := uuid.Parse(log.TenantID)
+	if err != nil {
+		return nil
+	}
+	projectID, err := uuid.Parse(log.ProjectID)
+	if err != nil {
+		return nil
+	}
+
+	attributes := make(map[string]string, len(log.Metadata))
+	for k, v := range log.Metadata {
+		if str, ok := v.(string); ok {
+			attributes[k] = str
+		} else if b, err := json.Marshal(v); err == nil {
+			attributes[k] = string(b)
+		}
+	}
+	original := make(map[string]string, len(attributes))
+	for k, v := range attributes {
+		original[k] = v
+	}
+
+	rec := &redactionDomain.Record{
+		Message:    log.Message,
+		UserID:     log.UserID,
+		Attributes: attributes,
+		Tags:       log.Tags,
+	}
+	if err := s.redactor.Redact(ctx, tenantID, projectID, rec); err != nil {
+		return err
+	}
+	log.Message, log.UserID, log.Tags = rec.Message, rec.UserID, rec.Tags
+	for k, before := range original {
+		after, ok := rec.Attributes[k]
+		switch {
+		case !ok:
+			delete(log.Metadata, k)
+		case after != before:
+			log.Metadata[k] = after
+		}
+	}
+	return nil
+}
+
 func embeddingText(log ingestDomain.Log) string {
 	if s := strings.TrimSpace(log.Message); s != "" {
 		return s
@@ -0,0 +1,141 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/ingest/domain"
+	tenantDomain "github.com/indalyadav56/logify/apps/backend/internal/tenant/domain"
+)
+
+const bytesPerGB = 1 << 30
+
+// QuotaSource supplies the tenant quota. It is satisfied by
+// tenant/application.TenantService.
+type QuotaSource interface {
+	GetQuota(ctx context.Context, tenantID uuid.UUID) (*tenantDomain.Quota, error)
+}
+
+// Admitter decides whether a log may be published. Admit returns nil to
+// publish, domain.ErrDropped to discard the log silently, or a
+// *domain.LimitExceededError to reject it. Accept counts an admitted log in
+// the usage once it has been published.
+type Admitter interface {
+	Admit(ctx context.Context, tenantID, projectID uuid.UUID, log domain.Log) error
+	Accept(ctx context.Context, tenantID, projectID uuid.UUID, log domain.Log)
+}
+
+// LimitConfig holds the per-second limits shared by every tenant and the
+// optional drop mode. Zero limits are disabled.
+type LimitConfig struct {
+	TenantBytesPerSecond   int64
+	TenantEventsPerSecond  int64
+	ProjectBytesPerSecond  int64
+	ProjectEventsPerSecond int64
+	Window                 time.Duration
+	// DropBelowLevel enables drop mode: over-limit logs below this level are
+	// discarded instead of rejected, and logs at or above it skip the
+	// per-second limits (the daily cap still applies).
+	DropBelowLevel string
+}
+
+// IngestLimiter enforces LimitConfig and the tenant's daily volume quota.
+//
+// It fails open: when Redis or the quota lookup is unavailable the log is
+// published and the failure logged, since losing logs is worse than a brief
+// overshoot.
+type IngestLimiter struct {
+	limiter  domain.RateLimiter
+	quotas   QuotaSource
+	cfg      LimitConfig
+	dropRank int
+	logger   *zap.Logger
+	now      func() time.Time
+}
+
+func NewIngestLimiter(limiter domain.RateLimiter, quotas QuotaSource, cfg LimitConfig, logger *zap.Logger) *IngestLimiter {
+	l := &IngestLimiter{
+		limiter:  limiter,
+		quotas:   quotas,
+		cfg:      cfg,
+		dropRank: -1,
+		logger:   logger.Named("ingest_limiter"),
+		now:      time.Now,
+	}
+	if cfg.DropBelowLevel != "" {
+		if !domain.IsKnownLevel(cfg.DropBelowLevel) {
+			l.logger.Warn("unknown drop_below_level; drop mode disabled", zap.String("level", cfg.DropBelowLevel))
+		} else {
+			l.dropRank = domain.LevelRank(cfg.DropBelowLevel)
+		}
+	}
+	return l
+}
+
+func (l *IngestLimiter) Admit(ctx context.Context, tenantID, projectID uuid.UUID, log domain.Log) error {
+	admission := l.admission(tenantID, projectID, log)
+
+	limits := domain.Limits{
+		TenantBytesPerSecond:   l.cfg.TenantBytesPerSecond,
+		TenantEventsPerSecond:  l.cfg.TenantEventsPerSecond,
+		ProjectBytesPerSecond:  l.cfg.ProjectBytesPerSecond,
+		ProjectEventsPerSecond: l.cfg.ProjectEventsPerSecond,
+		Window:                 l.cfg.Window,
+	}
+	if quota, err := l.quotas.GetQuota(ctx, tenantID); err != nil {
+		l.logger.Warn("quota lookup failed; daily cap not applied",
+			zap.String("tenant_id", tenantID.String()), zap.Error(err))
+	} else {
+		limits.DailyBytes = int64(quota.DailyIngestGB * bytesPerGB)
+	}
+
+	dropMode := l.dropRank >= 0
+	important := dropMode && domain.LevelRank(log.Level) >= l.dropRank
+	admission.SkipRateLimits = important
+
+	decision, err := l.limiter.Admit(ctx, admission, limits)
+	if err != nil {
+		l.logger.Warn("rate limiter unavailable; admitting log",
+			zap.String("tenant_id", tenantID.String()), zap.Error(err))
+		return nil
+	}
+	if decision.Allowed {
+		return nil
+	}
+
+	if dropMode && !important {
+		if err := l.limiter.RecordDropped(ctx, admission); err != nil {
+			l.logger.Warn("failed to record dropped log", zap.String("tenant_id", tenantID.String()), zap.Error(err))
+		}
+		return domain.ErrDropped
+	}
+	if err := l.limiter.RecordRejected(ctx, admission); err != nil {
+		l.logger.Warn("failed to record rejected log", zap.String("tenant_id", tenantID.String()), zap.Error(err))
+	}
+	return &domain.LimitExceededError{Reason: decision.Reason, RetryAfter: decision.RetryAfter}
+}
+
+// Accept records the log in the usage counters. A failure is logged and
+// otherwise ignored: the log is already published.
+func (l *IngestLimiter) Accept(ctx context.Context, tenantID, projectID uuid.UUID, log domain.Log) {
+	if err := l.limiter.RecordAccepted(ctx, l.admission(tenantID, projectID, log)); err != nil {
+		l.logger.Warn("failed to record accepted log", zap.String("tenant_id", tenantID.String()), zap.Error(err))
+	}
+}
+
+func (l *IngestLimiter) admission(tenantID, projectID uuid.UUID, log domain.Log) domain.Admission {
+	size := int64(1)
+	if b, err := log.ToJSON(); err == nil {
+		size = int64(len(b))
+	}
+	return domain.Admission{
+		TenantID:  tenantID,
+		ProjectID: projectID,
+		Bytes:     size,
+		Events:    1,
+		At:        l.now(),
+	}
+}
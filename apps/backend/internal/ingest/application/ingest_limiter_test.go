@@ -0,0 +1,123 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/ingest/domain"
+	tenantDomain "github.com/indalyadav56/logify/apps/backend/internal/tenant/domain"
+)
+
+type stubRateLimiter struct {
+	decision domain.Decision
+	err      error
+
+	admitted []domain.Admission
+	limits   []domain.Limits
+	accepted []domain.Admission
+	dropped  int
+	rejected int
+}
+
+func (s *stubRateLimiter) Admit(_ context.Context, a domain.Admission, l domain.Limits) (domain.Decision, error) {
+	s.admitted = append(s.admitted, a)
+	s.limits = append(s.limits, l)
+	if a.SkipRateLimits && s.decision.Reason != domain.ReasonDailyVolume {
+		return domain.Decision{Allowed: true}, s.err
+	}
+	return s.decision, s.err
+}
+
+func (s *stubRateLimiter) RecordAccepted(_ context.Context, a domain.Admission) error {
+	s.accepted = append(s.accepted, a)
+	return s.err
+}
+
+func (s *stubRateLimiter) RecordDropped(context.Context, domain.Admission) error {
+	s.dropped++
+	return nil
+}
+
+func (s *stubRateLimiter) RecordRejected(context.Context, domain.Admission) error {
+	s.rejected++
+	return nil
+}
+
+type stubQuotas struct {
+	quota *tenantDomain.Quota
+	err   error
+}
+
+func (s stubQuotas) GetQuota(context.Context, uuid.UUID) (*tenantDomain.Quota, error) {
+	return s.quota, s.err
+}
+
+func TestIngestLimiterRejectsWithRetryAfter(t *testing.T) {
+	rl := &stubRateLimiter{decision: domain.Decision{Reason: domain.ReasonProjectBytes, RetryAfter: 300 * time.Millisecond}}
+	quota := tenantDomain.DefaultQuota(uuid.Nil, tenantDomain.PlanFree)
+	l := NewIngestLimiter(rl, stubQuotas{quota: &quota}, LimitConfig{ProjectBytesPerSecond: 10}, zap.NewNop())
+
+	err := l.Admit(context.Background(), uuid.New(), uuid.New(), domain.Log{Level: "error", Message: "boom"})
+
+	var limitErr *domain.LimitExceededError
+	require.True(t, errors.As(err, &limitErr))
+	assert.ErrorIs(t, err, domain.ErrRateLimited)
+	assert.Equal(t, domain.ReasonProjectBytes, limitErr.Reason)
+	assert.Equal(t, 300*time.Millisecond, limitErr.RetryAfter)
+	assert.Equal(t, 1, rl.rejected)
+	assert.Equal(t, int64(1<<30), rl.limits[0].DailyBytes, "daily cap comes from the tenant quota")
+	assert.Positive(t, rl.admitted[0].Bytes)
+	assert.Empty(t, rl.accepted, "a rejected log is not counted as usage")
+}
+
+func TestIngestLimiterAcceptCountsUsage(t *testing.T) {
+	rl := &stubRateLimiter{decision: domain.Decision{Allowed: true}}
+	l := NewIngestLimiter(rl, stubQuotas{err: errors.New("db down")}, LimitConfig{}, zap.NewNop())
+	tenantID, projectID := uuid.New(), uuid.New()
+	log := domain.Log{Level: "info", Message: "hello"}
+
+	require.NoError(t, l.Admit(context.Background(), tenantID, projectID, log))
+	assert.Empty(t, rl.accepted, "admission alone counts no usage")
+
+	l.Accept(context.Background(), tenantID, projectID, log)
+	require.Len(t, rl.accepted, 1)
+	assert.Equal(t, rl.admitted[0].Bytes, rl.accepted[0].Bytes)
+	assert.Equal(t, int64(1), rl.accepted[0].Events)
+	assert.Equal(t, projectID, rl.accepted[0].ProjectID)
+}
+
+func TestIngestLimiterDropMode(t *testing.T) {
+	rl := &stubRateLimiter{decision: domain.Decision{Reason: domain.ReasonTenantEvents, RetryAfter: time.Second}}
+	l := NewIngestLimiter(rl, stubQuotas{err: errors.New("db down")}, LimitConfig{DropBelowLevel: "warn"}, zap.NewNop())
+	ctx := context.Background()
+	tenantID, projectID := uuid.New(), uuid.New()
+
+	err := l.Admit(ctx, tenantID, projectID, domain.Log{Level: "info"})
+	assert.ErrorIs(t, err, domain.ErrDropped)
+	assert.Equal(t, 1, rl.dropped)
+	assert.Zero(t, rl.rejected)
+
+	// Warnings and above skip the per-second limits.
+	require.NoError(t, l.Admit(ctx, tenantID, projectID, domain.Log{Level: "ERROR"}))
+	assert.True(t, rl.admitted[1].SkipRateLimits)
+
+	// ...but not the daily cap.
+	rl.decision = domain.Decision{Reason: domain.ReasonDailyVolume, RetryAfter: time.Hour}
+	err = l.Admit(ctx, tenantID, projectID, domain.Log{Level: "fatal"})
+	assert.ErrorIs(t, err, domain.ErrRateLimited)
+}
+
+func TestIngestLimiterFailsOpen(t *testing.T) {
+	rl := &stubRateLimiter{err: errors.New("redis down")}
+	l := NewIngestLimiter(rl, stubQuotas{err: errors.New("db down")}, LimitConfig{}, zap.NewNop())
+
+	assert.NoError(t, l.Admit(context.Background(), uuid.New(), uuid.New(), domain.Log{Level: "info"}))
+	assert.Zero(t, rl.limits[0].DailyBytes)
+}
@@ -4,26 +4,113 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/google/uuid"
+
 	"github.com/indalyadav56/logify/apps/backend/internal/ingest/domain"
+	roleDomain "github.com/indalyadav56/logify/apps/backend/internal/role/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
 )
 
 type IngestService interface {
 	Ingest(ctx context.Context, log domain.Log) error
 }
 
+// ProjectAccess decides whether the caller may write logs to a project. It is
+// satisfied by role/application.PermissionResolver, which also rejects
+// projects outside the caller's tenant.
+type ProjectAccess interface {
+	HasPermission(ctx context.Context, userID, tenantID uuid.UUID, projectID *uuid.UUID, resource, action string) (bool, error)
+}
+
+// RetentionSource decides how long a log is kept. It is satisfied by
+// retention/application.RetentionService.
+type RetentionSource interface {
+	RetentionDays(ctx context.Context, tenantID, projectID uuid.UUID, level string) (int, error)
+}
+
+// Sampler decides whether a log is kept. It is satisfied by
+// sampling/application.SamplingService.
+type Sampler interface {
+	SampleRate(ctx context.Context, tenantID, projectID uuid.UUID, log domain.Log) (uint32, error)
+}
+
 type ingestService struct {
 	logProducer domain.LogProducer
+	access      ProjectAccess
+	limits      Admitter
+	retention   RetentionSource
+	sampler     Sampler
 }
 
-func NewIngestService(logProducer domain.LogProducer) *ingestService {
+// NewIngestService wires the ingest path. retention and sampler may be nil,
+// in which case logs are stored with the default retention and unsampled.
+func NewIngestService(logProducer domain.LogProducer, access ProjectAccess, limits Admitter, retention RetentionSource, sampler Sampler) *ingestService {
 	return &ingestService{
 		logProducer: logProducer,
+		access:      access,
+		limits:      limits,
+		retention:   retention,
+		sampler:     sampler,
 	}
 }
 
+// Ingest publishes the log under the caller's tenant; any tenant_id supplied
+// by the client is overwritten. Logs over the ingest limits fail with a
+// *domain.LimitExceededError or, in drop mode, domain.ErrDropped. Logs the
+// sampling rules discard fail with domain.ErrSampledOut.
 func (i *ingestService) Ingest(ctx context.Context, log domain.Log) error {
+	userID, ok := middleware.UserUUIDFromContext(ctx)
+	if !ok {
+		return domain.ErrTenantIDRequired
+	}
+	tenantID, ok := middleware.GetTenantUUIDFromContext(ctx)
+	if !ok {
+		return domain.ErrTenantIDRequired
+	}
+	if log.ProjectID == "" {
+		return domain.ErrProjectIDRequired
+	}
+	projectID, err := uuid.Parse(log.ProjectID)
+	if err != nil {
+		return domain.ErrProjectNotFound
+	}
+
+	allowed, err := i.access.HasPermission(ctx, userID, tenantID, &projectID, roleDomain.ResourceLog, roleDomain.ActionWrite)
+	if err != nil {
+		return fmt.Errorf("check project access: %w", err)
+	}
+	if !allowed {
+		return domain.ErrProjectNotFound
+	}
+
+	log.TenantID = tenantID.String()
+	log.RetentionDays = 0
+	log.SampleRate = 1
+	if i.sampler != nil {
+		// Sampled-out logs are dropped before admission, so they count
+		// against no limit. A failed lookup keeps the log.
+		if rate, err := i.sampler.SampleRate(ctx, tenantID, projectID, log); err == nil {
+			if rate == 0 {
+				return domain.ErrSampledOut
+			}
+			log.SampleRate = rate
+		}
+	}
+	if i.retention != nil {
+		// A failed lookup stores the log with the default retention rather
+		// than rejecting it.
+		if days, err := i.retention.RetentionDays(ctx, tenantID, projectID, log.Level); err == nil {
+			log.RetentionDays = days
+		}
+	}
+	if err := i.limits.Admit(ctx, tenantID, projectID, log); err != nil {
+		return err
+	}
 	if err := i.logProducer.Produce(ctx, log); err != nil {
 		return fmt.Errorf("produce log: %w", err)
 	}
+	// Usage is only counted once the log is published, so a failed produce
+	// is not billed.
+	i.limits.Accept(ctx, tenantID, projectID, log)
 	return nil
 }
@@ -0,0 +1,101 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/ingest/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
+	"github.com/indalyadav56/logify/apps/backend/pkg/flushloop"
+)
+
+const (
+	defaultUsageFlushInterval = time.Minute
+	usageFlushBatch           = 500
+	maxUsageRangeDays         = 366
+)
+
+// ErrInvalidUsageRange is returned for an empty or over-long date range.
+var ErrInvalidUsageRange = errors.New("invalid usage date range")
+
+// UsageService exposes the ingest accounting to tenants and rolls the live
+// Redis counters up into the usage table.
+type UsageService interface {
+	// ListUsage returns the caller's tenant usage per project and UTC day.
+	// Today's row lags the live counters by up to one flush interval.
+	ListUsage(ctx context.Context, projectID *uuid.UUID, from, to time.Time) ([]domain.Usage, error)
+	// Flush copies every touched counter into the usage table.
+	Flush(ctx context.Context) error
+	// Run flushes on an interval until ctx is cancelled.
+	Run(ctx context.Context)
+}
+
+type usageService struct {
+	counters domain.UsageCounters
+	repo     domain.UsageRepository
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+func NewUsageService(counters domain.UsageCounters, repo domain.UsageRepository, interval time.Duration, logger *zap.Logger) UsageService {
+	if interval <= 0 {
+		interval = defaultUsageFlushInterval
+	}
+	return &usageService{
+		counters: counters,
+		repo:     repo,
+		interval: interval,
+		logger:   logger.Named("usage_service"),
+	}
+}
+
+func (s *usageService) ListUsage(ctx context.Context, projectID *uuid.UUID, from, to time.Time) ([]domain.Usage, error) {
+	tenantID, ok := middleware.GetTenantUUIDFromContext(ctx)
+	if !ok {
+		return nil, domain.ErrTenantIDRequired
+	}
+	from, to = truncateDay(from), truncateDay(to)
+	if to.Before(from) || to.Sub(from) > maxUsageRangeDays*24*time.Hour {
+		return nil, ErrInvalidUsageRange
+	}
+	return s.repo.List(ctx, domain.UsageFilter{
+		TenantID:  tenantID,
+		ProjectID: projectID,
+		From:      from,
+		To:        to,
+	})
+}
+
+func (s *usageService) Flush(ctx context.Context) error {
+	for {
+		rows, err := s.counters.DrainDirty(ctx, usageFlushBatch)
+		if upsertErr := s.repo.Upsert(ctx, rows); upsertErr != nil {
+			// The rows are already popped; put them back so the next flush
+			// retries them. Upsert stores cumulative values, so a retry
+			// never double counts.
+			if markErr := s.counters.MarkDirty(context.WithoutCancel(ctx), rows); markErr != nil {
+				return errors.Join(upsertErr, markErr)
+			}
+			return upsertErr
+		}
+		if err != nil {
+			return err
+		}
+		if len(rows) < usageFlushBatch {
+			return nil
+		}
+	}
+}
+
+func (s *usageService) Run(ctx context.Context) {
+	flushloop.Run(ctx, s.interval, s.Flush, "usage", s.logger)
+}
+
+func truncateDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
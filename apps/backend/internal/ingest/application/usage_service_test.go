@@ -0,0 +1,64 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/ingest/domain"
+)
+
+// memCounters mimics the Redis dirty set: DrainDirty pops, MarkDirty re-adds.
+type memCounters struct {
+	dirty []domain.Usage
+}
+
+func (c *memCounters) DrainDirty(_ context.Context, max int) ([]domain.Usage, error) {
+	n := min(max, len(c.dirty))
+	rows := c.dirty[:n:n]
+	c.dirty = c.dirty[n:]
+	return rows, nil
+}
+
+func (c *memCounters) MarkDirty(_ context.Context, rows []domain.Usage) error {
+	c.dirty = append(c.dirty, rows...)
+	return nil
+}
+
+type memUsageRepo struct {
+	err  error
+	rows []domain.Usage
+}
+
+func (r *memUsageRepo) Upsert(_ context.Context, rows []domain.Usage) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.rows = append(r.rows, rows...)
+	return nil
+}
+
+func (r *memUsageRepo) List(context.Context, domain.UsageFilter) ([]domain.Usage, error) {
+	return r.rows, nil
+}
+
+func TestUsageFlushKeepsRowsWhenUpsertFails(t *testing.T) {
+	row := domain.Usage{TenantID: uuid.New(), ProjectID: uuid.New(), Day: truncateDay(time.Now()), Bytes: 42, Events: 3}
+	counters := &memCounters{dirty: []domain.Usage{row}}
+	repo := &memUsageRepo{err: errors.New("db down")}
+	svc := NewUsageService(counters, repo, time.Minute, zap.NewNop())
+
+	require.Error(t, svc.Flush(context.Background()))
+	assert.Equal(t, []domain.Usage{row}, counters.dirty, "the popped row is put back")
+
+	repo.err = nil
+	require.NoError(t, svc.Flush(context.Background()))
+	assert.Equal(t, []domain.Usage{row}, repo.rows)
+	assert.Empty(t, counters.dirty)
+}
@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrTenantIDRequired  = errors.New("tenant_id is required")
+	ErrProjectIDRequired = errors.New("project_id is required")
+	// ErrProjectNotFound covers both unknown projects and projects the caller
+	// may not write to, so other tenants' project ids are not disclosed.
+	ErrProjectNotFound = errors.New("project not found")
+	ErrRateLimited     = errors.New("ingest limit exceeded")
+	// ErrDropped reports that the events were discarded by drop mode; the
+	// request itself succeeded.
+	ErrDropped = errors.New("log dropped: over ingest limit")
+	// ErrSampledOut reports that the project's sampling rules discarded the
+	// log; the request itself succeeded.
+	ErrSampledOut = errors.New("log dropped by sampling rules")
+)
+
+// LimitExceededError is returned when an admission is rejected. It matches
+// ErrRateLimited with errors.Is.
+type LimitExceededError struct {
+	Reason     string
+	RetryAfter time.Duration
+}
+
+func (e *LimitExceededError) Error() string {
+	return ErrRateLimited.Error() + ": " + e.Reason
+}
+
+func (e *LimitExceededError) Unwrap() error {
+	return ErrRateLimited
+}
@@ -0,0 +1,128 @@
+package domain
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Limits are the ingest ceilings that apply to one admission. A zero value
+// disables the corresponding check.
+type Limits struct {
+	TenantBytesPerSecond   int64
+	TenantEventsPerSecond  int64
+	ProjectBytesPerSecond  int64
+	ProjectEventsPerSecond int64
+	// Window is the sliding window the per-second limits are measured over.
+	Window time.Duration
+	// DailyBytes caps the tenant's volume per UTC day.
+	DailyBytes int64
+}
+
+// Admission is a batch of events a caller wants to publish.
+type Admission struct {
+	TenantID  uuid.UUID
+	ProjectID uuid.UUID
+	Bytes     int64
+	Events    int64
+	At        time.Time
+	// SkipRateLimits admits the batch past the per-second limits; the daily
+	// cap still applies. Used for high-severity events in drop mode.
+	SkipRateLimits bool
+}
+
+// Decision is the outcome of RateLimiter.Admit.
+type Decision struct {
+	Allowed    bool
+	Reason     string
+	RetryAfter time.Duration
+}
+
+// Rejection reasons reported in Decision.Reason.
+const (
+	ReasonTenantBytes   = "tenant_bytes_per_second"
+	ReasonTenantEvents  = "tenant_events_per_second"
+	ReasonProjectBytes  = "project_bytes_per_second"
+	ReasonProjectEvents = "project_events_per_second"
+	ReasonDailyVolume   = "daily_volume"
+)
+
+// RateLimiter checks an admission against its limits and, when it passes,
+// charges it to the rate windows and the daily cap — atomically, so
+// concurrent server instances share one budget.
+type RateLimiter interface {
+	Admit(ctx context.Context, a Admission, l Limits) (Decision, error)
+	// RecordAccepted counts an admitted batch in the usage counters once it
+	// has been published.
+	RecordAccepted(ctx context.Context, a Admission) error
+	// RecordDropped counts events discarded by drop mode.
+	RecordDropped(ctx context.Context, a Admission) error
+	// RecordRejected counts events refused with ErrRateLimited.
+	RecordRejected(ctx context.Context, a Admission) error
+}
+
+// Usage is the ingest accounting of one project for one UTC day.
+type Usage struct {
+	TenantID       uuid.UUID `json:"tenant_id"`
+	ProjectID      uuid.UUID `json:"project_id"`
+	Day            time.Time `json:"day"`
+	Bytes          int64     `json:"bytes"`
+	Events         int64     `json:"events"`
+	DroppedEvents  int64     `json:"dropped_events"`
+	RejectedEvents int64     `json:"rejected_events"`
+}
+
+// UsageFilter selects rows from the usage table. From and To are inclusive
+// UTC days.
+type UsageFilter struct {
+	TenantID  uuid.UUID
+	ProjectID *uuid.UUID
+	From      time.Time
+	To        time.Time
+}
+
+// UsageCounters is the live side of the accounting kept by the RateLimiter.
+type UsageCounters interface {
+	// DrainDirty returns up to max usage rows touched since they were last
+	// drained. Values are cumulative for the day.
+	DrainDirty(ctx context.Context, max int) ([]Usage, error)
+	// MarkDirty puts drained rows back so the next drain returns them again.
+	MarkDirty(ctx context.Context, rows []Usage) error
+}
+
+// UsageRepository is the durable, queryable side of the accounting.
+type UsageRepository interface {
+	// Upsert stores cumulative daily counters; a row never moves backwards.
+	Upsert(ctx context.Context, rows []Usage) error
+	List(ctx context.Context, filter UsageFilter) ([]Usage, error)
+}
+
+// levelRank orders severities; unknown levels rank as info.
+var levelRank = map[string]int{
+	"trace":    0,
+	"debug":    1,
+	"info":     2,
+	"notice":   2,
+	"warn":     3,
+	"warning":  3,
+	"error":    4,
+	"critical": 5,
+	"fatal":    5,
+	"panic":    5,
+}
+
+// LevelRank returns the severity rank of a log level.
+func LevelRank(level string) int {
+	if r, ok := levelRank[strings.ToLower(strings.TrimSpace(level))]; ok {
+		return r
+	}
+	return levelRank["info"]
+}
+
+// IsKnownLevel reports whether level is in the severity table.
+func IsKnownLevel(level string) bool {
+	_, ok := levelRank[strings.ToLower(strings.TrimSpace(level))]
+	return ok
+}
@@ -19,6 +19,13 @@ type Log struct {
 	Source      string                 `json:"source"`
 	Tags        map[string]string      `json:"tags"`
 	Metadata    map[string]interface{} `json:"metadata"`
+	// RetentionDays is stamped by ingest from the project's retention
+	// policy; any client value is overwritten.
+	RetentionDays int `json:"retention_days,omitempty"`
+	// SampleRate is stamped by ingest from the project's sampling rules:
+	// the number of logs this one stands for. Any client value is
+	// overwritten.
+	SampleRate uint32 `json:"sample_rate,omitempty"`
 }
 
 func (l *Log) ToJSON() ([]byte, error) {
@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/ingest/domain"
+)
+
+// usageRepository is a PostgreSQL-backed implementation of
+// domain.UsageRepository.
+type usageRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewUsageRepository constructs a usageRepository backed by the given pool.
+func NewUsageRepository(db *pgxpool.Pool) domain.UsageRepository {
+	return &usageRepository{db: db}
+}
+
+// Upsert takes the larger of the stored and incoming counters: the flusher
+// sends cumulative daily values, and a Redis restart must not roll them back.
+func (r *usageRepository) Upsert(ctx context.Context, rows []domain.Usage) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	const query = `
+		INSERT INTO ingest_usage_daily (
+			tenant_id, project_id, day, bytes, events, dropped_events, rejected_events
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (tenant_id, project_id, day) DO UPDATE
+		SET bytes           = GREATEST(ingest_usage_daily.bytes, EXCLUDED.bytes),
+		    events          = GREATEST(ingest_usage_daily.events, EXCLUDED.events),
+		    dropped_events  = GREATEST(ingest_usage_daily.dropped_events, EXCLUDED.dropped_events),
+		    rejected_events = GREATEST(ingest_usage_daily.rejected_events, EXCLUDED.rejected_events),
+		    updated_at      = (now() AT TIME ZONE 'utc')
+	`
+	batch := &pgx.Batch{}
+	for _, u := range rows {
+		batch.Queue(query, u.TenantID, u.ProjectID, u.Day, u.Bytes, u.Events, u.DroppedEvents, u.RejectedEvents)
+	}
+	return r.db.SendBatch(ctx, batch).Close()
+}
+
+func (r *usageRepository) List(ctx context.Context, f domain.UsageFilter) ([]domain.Usage, error) {
+	const query = `
+		SELECT tenant_id, project_id, day, bytes, events, dropped_events, rejected_events
+		FROM ingest_usage_daily
+		WHERE tenant_id = $1
+		  AND day BETWEEN $2 AND $3
+		  AND ($4::uuid IS NULL OR project_id = $4)
+		ORDER BY day DESC, project_id
+	`
+	rows, err := r.db.Query(ctx, query, f.TenantID, f.From, f.To, f.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]domain.Usage, 0)
+	for rows.Next() {
+		var u domain.Usage
+		if err := rows.Scan(
+			&u.TenantID,
+			&u.ProjectID,
+			&u.Day,
+			&u.Bytes,
+			&u.Events,
+			&u.DroppedEvents,
+			&u.RejectedEvents,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, u)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,261 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/ingest/domain"
+)
+
+const (
+	keyPrefix = "ingest:"
+	dirtySet  = keyPrefix + "usage:dirty"
+	dayLayout = "20060102"
+
+	// usageTTL keeps daily counters around long enough to be flushed even if
+	// the flusher was down over midnight.
+	usageTTL = 72 * time.Hour
+)
+
+// admitScript checks the four sliding-window limits and the daily cap, then
+// charges the admission to them; a rejection charges nothing. Usage is
+// counted separately by RecordAccepted once the batch is published. Everything happens in
+// one script so concurrent servers never overshoot a shared budget.
+//
+// Each per-second limit uses the sliding window counter approximation: the
+// previous bucket is weighted by how much of it still overlaps the window.
+// A limit only rejects when the window already holds traffic, so a single
+// batch larger than the limit is not rejected forever.
+//
+// KEYS[1..8]  current/previous bucket of tenant bytes, tenant events, project bytes, project events
+// KEYS[9]     tenant daily bytes
+// ARGV[1..2]  bytes, events
+// ARGV[3..4]  window ms, ms elapsed in the current bucket
+// ARGV[5..8]  limits in the same order as the keys (0 = off)
+// ARGV[9]     daily byte cap (0 = off)
+// ARGV[10]    ms until the next UTC day
+// ARGV[11]    daily counter ttl seconds
+// ARGV[12]    1 to skip the per-second limits
+//
+// Returns {allowed, reason index, retry after ms}; reason 5 is the daily cap.
+var admitScript = redis.NewScript(`
+local bytes = tonumber(ARGV[1])
+local events = tonumber(ARGV[2])
+local window = tonumber(ARGV[3])
+local elapsed = tonumber(ARGV[4])
+local cost = {bytes, events, bytes, events}
+
+if ARGV[12] ~= '1' then
+	for i = 1, 4 do
+		local limit = tonumber(ARGV[4 + i])
+		if limit > 0 then
+			local cur = tonumber(redis.call('GET', KEYS[2 * i - 1]) or '0')
+			local prev = tonumber(redis.call('GET', KEYS[2 * i]) or '0')
+			local est = prev * (window - elapsed) / window + cur
+			if est > 0 and est + cost[i] > limit then
+				return {0, i, window - elapsed}
+			end
+		end
+	end
+end
+
+local cap = tonumber(ARGV[9])
+if cap > 0 then
+	local used = tonumber(redis.call('GET', KEYS[9]) or '0')
+	if used + bytes > cap then
+		return {0, 5, tonumber(ARGV[10])}
+	end
+end
+
+for i = 1, 4 do
+	if tonumber(ARGV[4 + i]) > 0 then
+		redis.call('INCRBY', KEYS[2 * i - 1], cost[i])
+		redis.call('PEXPIRE', KEYS[2 * i - 1], window * 2)
+	end
+end
+redis.call('INCRBY', KEYS[9], bytes)
+redis.call('EXPIRE', KEYS[9], ARGV[11])
+return {1, 0, 0}
+`)
+
+var reasons = [...]string{
+	1: domain.ReasonTenantBytes,
+	2: domain.ReasonTenantEvents,
+	3: domain.ReasonProjectBytes,
+	4: domain.ReasonProjectEvents,
+	5: domain.ReasonDailyVolume,
+}
+
+// RateLimiter is a Redis-backed domain.RateLimiter and domain.UsageCounters.
+type RateLimiter struct {
+	client redis.UniversalClient
+}
+
+func NewRateLimiter(client redis.UniversalClient) *RateLimiter {
+	return &RateLimiter{client: client}
+}
+
+func (r *RateLimiter) Admit(ctx context.Context, a domain.Admission, l domain.Limits) (domain.Decision, error) {
+	window := l.Window
+	if window <= 0 {
+		window = time.Second
+	}
+	at := a.At.UTC()
+	windowMS := window.Milliseconds()
+	nowMS := at.UnixMilli()
+	bucket := nowMS / windowMS
+	elapsed := nowMS % windowMS
+	day := at.Format(dayLayout)
+	nextDay := time.Date(at.Year(), at.Month(), at.Day()+1, 0, 0, 0, 0, time.UTC)
+
+	tenant, project := a.TenantID.String(), a.ProjectID.String()
+	keys := make([]string, 0, 9)
+	for _, scope := range []string{"t:" + tenant + ":bytes", "t:" + tenant + ":events", "p:" + project + ":bytes", "p:" + project + ":events"} {
+		keys = append(keys,
+			fmt.Sprintf("%srl:%s:%d", keyPrefix, scope, bucket),
+			fmt.Sprintf("%srl:%s:%d", keyPrefix, scope, bucket-1),
+		)
+	}
+	keys = append(keys, fmt.Sprintf("%sdaily:%s:%s", keyPrefix, tenant, day))
+
+	skip := "0"
+	if a.SkipRateLimits {
+		skip = "1"
+	}
+	res, err := admitScript.Run(ctx, r.client, keys,
+		a.Bytes, a.Events,
+		windowMS, elapsed,
+		perWindow(l.TenantBytesPerSecond, window), perWindow(l.TenantEventsPerSecond, window),
+		perWindow(l.ProjectBytesPerSecond, window), perWindow(l.ProjectEventsPerSecond, window),
+		l.DailyBytes, nextDay.Sub(at).Milliseconds(),
+		int64(usageTTL.Seconds()),
+		skip,
+	).Int64Slice()
+	if err != nil {
+		return domain.Decision{}, fmt.Errorf("admit script: %w", err)
+	}
+	if len(res) != 3 {
+		return domain.Decision{}, fmt.Errorf("admit script: unexpected reply %v", res)
+	}
+	if res[0] == 1 {
+		return domain.Decision{Allowed: true}, nil
+	}
+
+	reason := "unknown"
+	if i := res[1]; i > 0 && int(i) < len(reasons) {
+		reason = reasons[i]
+	}
+	return domain.Decision{Reason: reason, RetryAfter: time.Duration(res[2]) * time.Millisecond}, nil
+}
+
+func (r *RateLimiter) RecordAccepted(ctx context.Context, a domain.Admission) error {
+	return r.recordUsage(ctx, a, map[string]int64{"bytes": a.Bytes, "events": a.Events})
+}
+
+func (r *RateLimiter) RecordDropped(ctx context.Context, a domain.Admission) error {
+	return r.recordUsage(ctx, a, map[string]int64{"dropped": a.Events})
+}
+
+func (r *RateLimiter) RecordRejected(ctx context.Context, a domain.Admission) error {
+	return r.recordUsage(ctx, a, map[string]int64{"rejected": a.Events})
+}
+
+func (r *RateLimiter) recordUsage(ctx context.Context, a domain.Admission, counts map[string]int64) error {
+	tenant, project := a.TenantID.String(), a.ProjectID.String()
+	day := a.At.UTC().Format(dayLayout)
+	key := usageKey(tenant, project, day)
+
+	_, err := r.client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		for field, n := range counts {
+			p.HIncrBy(ctx, key, field, n)
+		}
+		p.Expire(ctx, key, usageTTL)
+		p.SAdd(ctx, dirtySet, usageMember(tenant, project, day))
+		return nil
+	})
+	return err
+}
+
+// DrainDirty pops touched usage rows. A row touched again after being popped
+// is re-added to the dirty set by the next record; a row whose flush fails
+// must be put back with MarkDirty.
+func (r *RateLimiter) DrainDirty(ctx context.Context, max int) ([]domain.Usage, error) {
+	members, err := r.client.SPopN(ctx, dirtySet, int64(max)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]domain.Usage, 0, len(members))
+	for i, m := range members {
+		parts := strings.Split(m, "|")
+		if len(parts) != 3 {
+			continue
+		}
+		tenantID, err1 := uuid.Parse(parts[0])
+		projectID, err2 := uuid.Parse(parts[1])
+		day, err3 := time.Parse(dayLayout, parts[2])
+		if err1 != nil || err2 != nil || err3 != nil {
+			continue
+		}
+
+		fields, err := r.client.HGetAll(ctx, usageKey(parts[0], parts[1], parts[2])).Result()
+		if err != nil {
+			// Put the unread rows back so the next flush retries them.
+			rest := make([]any, 0, len(members)-i)
+			for _, m := range members[i:] {
+				rest = append(rest, m)
+			}
+			r.client.SAdd(ctx, dirtySet, rest...)
+			return out, err
+		}
+		out = append(out, domain.Usage{
+			TenantID:       tenantID,
+			ProjectID:      projectID,
+			Day:            day,
+			Bytes:          parseCounter(fields["bytes"]),
+			Events:         parseCounter(fields["events"]),
+			DroppedEvents:  parseCounter(fields["dropped"]),
+			RejectedEvents: parseCounter(fields["rejected"]),
+		})
+	}
+	return out, nil
+}
+
+func (r *RateLimiter) MarkDirty(ctx context.Context, rows []domain.Usage) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	members := make([]any, 0, len(rows))
+	for _, u := range rows {
+		members = append(members, usageMember(u.TenantID.String(), u.ProjectID.String(), u.Day.UTC().Format(dayLayout)))
+	}
+	return r.client.SAdd(ctx, dirtySet, members...).Err()
+}
+
+// perWindow scales a per-second limit to the window, never rounding an
+// enabled limit down to 0 (which would disable it).
+func perWindow(perSecond int64, window time.Duration) int64 {
+	if perSecond <= 0 {
+		return 0
+	}
+	return max(perSecond*window.Milliseconds()/1000, 1)
+}
+
+func usageKey(tenant, project, day string) string {
+	return keyPrefix + "usage:" + tenant + ":" + project + ":" + day
+}
+
+func usageMember(tenant, project, day string) string {
+	return tenant + "|" + project + "|" + day
+}
+
+func parseCounter(s string) int64 {
+	n, _ := strconv.ParseInt(s, 10, 64)
+	return n
+}
@@ -1,10 +1,15 @@
 package http
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	ingestApplication "github.com/indalyadav56/logify/apps/backend/internal/ingest/application"
+	"github.com/indalyadav56/logify/apps/backend/internal/ingest/domain"
 )
 
 type IngestHandler interface {
@@ -21,13 +26,17 @@ func NewIngestHandler(service ingestApplication.IngestService) IngestHandler {
 
 // CreateLog ingests a single log entry.
 // @Summary      Ingest a log entry
-// @Description  Accept a log entry and publish it to the ingest pipeline.
+// @Description  Accept a log entry for one of the caller's projects and publish it to the ingest pipeline.
 // @Tags         ingest
 // @Accept       json
 // @Produce      json
+// @Security     BearerAuth
 // @Param        request  body      CreateLogRequest  true  "Log payload"
-// @Success      202      {object}  map[string]string "log received"
+// @Success      202      {object}  map[string]string "log received, or dropped in drop mode or by sampling rules"
 // @Failure      400      {object}  map[string]string "invalid request body"
+// @Failure      401      {object}  map[string]string "missing tenant"
+// @Failure      404      {object}  map[string]string "project not found"
+// @Failure      429      {object}  map[string]string "ingest limit exceeded; see Retry-After"
 // @Failure      500      {object}  map[string]string "failed to publish log"
 // @Router       /v1/logs [post]
 func (h *ingestHandler) CreateLog(c *gin.Context) {
@@ -38,9 +47,30 @@ func (h *ingestHandler) CreateLog(c *gin.Context) {
 	}
 
 	if err := h.service.Ingest(c.Request.Context(), req.ToDomain()); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to publish log"})
+		var limitErr *domain.LimitExceededError
+		switch {
+		case errors.As(err, &limitErr):
+			c.Header("Retry-After", retryAfterSeconds(limitErr.RetryAfter))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": limitErr.Error(), "reason": limitErr.Reason})
+		case errors.Is(err, domain.ErrDropped), errors.Is(err, domain.ErrSampledOut):
+			c.JSON(http.StatusAccepted, gin.H{"message": err.Error(), "dropped": true})
+		case errors.Is(err, domain.ErrTenantIDRequired):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrProjectIDRequired):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrProjectNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to publish log"})
+		}
 		return
 	}
 
 	c.JSON(http.StatusAccepted, gin.H{"message": "log received"})
 }
+
+// retryAfterSeconds renders a Retry-After header value, rounding up so the
+// client never retries before the window has moved on.
+func retryAfterSeconds(d time.Duration) string {
+	return strconv.Itoa(max(int(math.Ceil(d.Seconds())), 1))
+}
@@ -2,11 +2,23 @@ package http
 
 import (
 	"github.com/gin-gonic/gin"
+
+	roleDomain "github.com/indalyadav56/logify/apps/backend/internal/role/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
 )
 
+// RegisterRoutes mounts the ingest route. The project is named in the body,
+// so the IngestService checks log.write on it rather than a tenant-level
+// check here, which would shut out project members.
 func RegisterRoutes(router *gin.RouterGroup, handler IngestHandler) {
 	logs := router.Group("/v1/logs")
 	{
 		logs.POST("", handler.CreateLog)
 	}
 }
+
+// RegisterUsageRoutes mounts the usage report. It is kept off the data-plane
+// group so suspended tenants can still see their consumption.
+func RegisterUsageRoutes(router *gin.RouterGroup, handler UsageHandler, authz *middleware.Authorizer) {
+	router.GET("/v1/usage", authz.RequirePermission(roleDomain.ResourceBilling, roleDomain.ActionRead), handler.GetUsage)
+}
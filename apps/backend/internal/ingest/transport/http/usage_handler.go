@@ -0,0 +1,88 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	ingestApplication "github.com/indalyadav56/logify/apps/backend/internal/ingest/application"
+	"github.com/indalyadav56/logify/apps/backend/internal/ingest/domain"
+)
+
+const usageDateLayout = "2006-01-02"
+
+type UsageHandler interface {
+	GetUsage(c *gin.Context)
+}
+
+type usageHandler struct {
+	service ingestApplication.UsageService
+}
+
+func NewUsageHandler(service ingestApplication.UsageService) UsageHandler {
+	return &usageHandler{service: service}
+}
+
+// GetUsage returns the caller's ingest usage.
+// @Summary      Ingest usage
+// @Description  Daily ingest volume, accepted, dropped and rejected events per project for the caller's tenant.
+// @Tags         ingest
+// @Produce      json
+// @Security     BearerAuth
+// @Param        from        query     string  false  "First day, YYYY-MM-DD (default: 30 days ago)"
+// @Param        to          query     string  false  "Last day, YYYY-MM-DD (default: today)"
+// @Param        project_id  query     string  false  "Restrict to one project"
+// @Success      200         {object}  map[string]interface{} "usage rows"
+// @Failure      400         {object}  map[string]string "invalid query"
+// @Failure      401         {object}  map[string]string "missing tenant"
+// @Failure      500         {object}  map[string]string "failed to load usage"
+// @Router       /v1/usage [get]
+func (h *usageHandler) GetUsage(c *gin.Context) {
+	today := time.Now().UTC()
+	from, to := today.AddDate(0, 0, -30), today
+
+	var err error
+	if v := c.Query("from"); v != "" {
+		if from, err = time.Parse(usageDateLayout, v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be YYYY-MM-DD"})
+			return
+		}
+	}
+	if v := c.Query("to"); v != "" {
+		if to, err = time.Parse(usageDateLayout, v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be YYYY-MM-DD"})
+			return
+		}
+	}
+	var projectID *uuid.UUID
+	if v := c.Query("project_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project_id"})
+			return
+		}
+		projectID = &id
+	}
+
+	rows, err := h.service.ListUsage(c.Request.Context(), projectID, from, to)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrTenantIDRequired):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case errors.Is(err, ingestApplication.ErrInvalidUsageRange):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load usage"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":  from.Format(usageDateLayout),
+		"to":    to.Format(usageDateLayout),
+		"usage": rows,
+	})
+}
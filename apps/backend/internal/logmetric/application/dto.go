@@ -0,0 +1,30 @@
+package application
+
+import (
+	"github.com/indalyadav56/logify/apps/backend/internal/logmetric/domain"
+)
+
+// RuleInput defines a metric rule.
+type RuleInput struct {
+	// Name is the exposed metric name, unique in the tenant.
+	Name        string        `json:"name" validate:"required,max=128"`
+	Description string        `json:"description,omitempty"`
+	Kind        domain.Kind   `json:"kind" validate:"required"`
+	Filter      domain.Filter `json:"filter"`
+	// Field is the numeric field a histogram observes.
+	Field string `json:"field,omitempty"`
+	// Buckets default to domain.DefaultBuckets for histograms.
+	Buckets []float64 `json:"buckets,omitempty"`
+	// GroupBy are the fields labelling the series: level, service,
+	// environment, host, namespace, source, attributes.<key> or tags.<key>.
+	GroupBy []string `json:"group_by,omitempty"`
+	// Enabled defaults to true.
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+// UpdateRuleInput changes what a rule may change once created; a rule of
+// another shape is a new rule.
+type UpdateRuleInput struct {
+	Description *string `json:"description,omitempty"`
+	Enabled     *bool   `json:"enabled,omitempty"`
+}
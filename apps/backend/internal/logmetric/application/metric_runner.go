@@ -0,0 +1,214 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/logmetric/domain"
+	"github.com/indalyadav56/logify/apps/backend/pkg/flushloop"
+	"github.com/indalyadav56/logify/apps/backend/pkg/ttlcache"
+)
+
+const (
+	defaultRuleCacheTTL     = 30 * time.Second
+	defaultFlushInterval    = 15 * time.Second
+	defaultMaxSeriesPerRule = 1000
+
+	// maxRuleCacheEntries caps memory; the cache is simply reset when full.
+	maxRuleCacheEntries = 10_000
+)
+
+type cachedRules struct {
+	tenantID uuid.UUID
+	rules    []*domain.Rule
+}
+
+// ruleDeltas are the observations of one rule since the last flush, by
+// label values.
+type ruleDeltas struct {
+	rule   *domain.Rule
+	series map[string]*domain.Series
+}
+
+// MetricRunner evaluates metric rules in the log-processor. It caches each
+// project's enabled rules, including none, for ttl: the API runs in another
+// process, so the cache cannot be told of changes.
+//
+// Observations are kept in memory as deltas and Run adds them to the stored
+// series every interval, so several processors can count the same rule.
+// Each rule keeps at most maxSeries label sets in memory, as in storage;
+// logs of any other are counted in its overflow series.
+type MetricRunner struct {
+	rules     domain.RuleRepository
+	series    domain.SeriesRepository
+	interval  time.Duration
+	maxSeries int
+	logger    *zap.Logger
+	now       func() time.Time
+	cache     *ttlcache.Cache[uuid.UUID, cachedRules]
+
+	deltaMu sync.Mutex
+	deltas  map[uuid.UUID]*ruleDeltas
+}
+
+func NewMetricRunner(rules domain.RuleRepository, series domain.SeriesRepository, ttl, interval time.Duration, maxSeries int, logger *zap.Logger) *MetricRunner {
+	if ttl <= 0 {
+		ttl = defaultRuleCacheTTL
+	}
+	if interval <= 0 {
+		interval = defaultFlushInterval
+	}
+	if maxSeries <= 0 {
+		maxSeries = defaultMaxSeriesPerRule
+	}
+	return &MetricRunner{
+		rules:     rules,
+		series:    series,
+		interval:  interval,
+		maxSeries: maxSeries,
+		logger:    logger.Named("metric_runner"),
+		now:       time.Now,
+		cache:     ttlcache.New[uuid.UUID, cachedRules](ttl, maxRuleCacheEntries),
+		deltas:    make(map[uuid.UUID]*ruleDeltas),
+	}
+}
+
+// Observe counts e in every enabled rule of the project that matches it. It
+// fails only when the rules cannot be loaded and none are cached.
+func (r *MetricRunner) Observe(ctx context.Context, tenantID, projectID uuid.UUID, e *domain.Entry) error {
+	rules, err := r.applicable(ctx, tenantID, projectID)
+	if err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		if !rule.Matches(e) {
+			continue
+		}
+		s := rule.NewSeries(rule.LabelValues(e))
+		if !rule.Observe(s, e) {
+			continue
+		}
+		r.deltaMu.Lock()
+		r.add(rule, s)
+		r.deltaMu.Unlock()
+	}
+	return nil
+}
+
+// add merges s into the deltas of rule. The caller holds deltaMu.
+func (r *MetricRunner) add(rule *domain.Rule, s *domain.Series) {
+	d := r.deltas[rule.ID]
+	if d == nil {
+		d = &ruleDeltas{series: make(map[string]*domain.Series)}
+		r.deltas[rule.ID] = d
+	}
+	// The latest copy of the rule is as good as any: its shape is fixed.
+	d.rule = rule
+
+	key := labelsKey(s.Labels)
+	if existing := d.series[key]; existing != nil {
+		existing.Add(s)
+		return
+	}
+	if len(d.series) >= r.maxSeries {
+		overflow := rule.OverflowLabels()
+		key = labelsKey(overflow)
+		if existing := d.series[key]; existing != nil {
+			existing.Add(s)
+			return
+		}
+		o := rule.NewSeries(overflow)
+		o.Add(s)
+		s = o
+	}
+	d.series[key] = s
+}
+
+func (r *MetricRunner) applicable(ctx context.Context, tenantID, projectID uuid.UUID) ([]*domain.Rule, error) {
+	now := r.now()
+	if entry, ok := r.cache.Get(projectID, now); ok && entry.tenantID == tenantID {
+		return entry.rules, nil
+	}
+
+	rules, err := r.rules.Applicable(ctx, tenantID, projectID)
+	if err != nil {
+		if entry, ok := r.cache.Stale(projectID); ok && entry.tenantID == tenantID {
+			r.logger.Warn("failed to reload metric rules, using expired ones",
+				zap.String("project_id", projectID.String()), zap.Error(err))
+			return entry.rules, nil
+		}
+		return nil, err
+	}
+	// Stored rules were validated on create; one that no longer is must
+	// not stop the others.
+	valid := rules[:0]
+	for _, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			r.logger.Error("skipping invalid metric rule",
+				zap.String("rule_id", rule.ID.String()), zap.Error(err))
+			continue
+		}
+		valid = append(valid, rule)
+	}
+
+	r.cache.Set(projectID, cachedRules{tenantID: tenantID, rules: valid}, now)
+	return valid, nil
+}
+
+// Flush adds the deltas observed since the last flush to the stored series.
+// Deltas of a rule that fail to store are kept for the next flush.
+func (r *MetricRunner) Flush(ctx context.Context) error {
+	r.deltaMu.Lock()
+	deltas := r.deltas
+	r.deltas = make(map[uuid.UUID]*ruleDeltas)
+	r.deltaMu.Unlock()
+
+	var errs []error
+	for _, d := range deltas {
+		series := make([]*domain.Series, 0, len(d.series))
+		for _, s := range d.series {
+			series = append(series, s)
+		}
+		if err := r.series.AddSeries(ctx, d.rule, series, r.maxSeries); err != nil {
+			errs = append(errs, err)
+			r.retain(d)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// retain merges deltas that failed to store back into those observed since.
+// They were capped when observed, so they are merged as they are: capping
+// again could fold a real series into the overflow one.
+func (r *MetricRunner) retain(d *ruleDeltas) {
+	r.deltaMu.Lock()
+	defer r.deltaMu.Unlock()
+	current := r.deltas[d.rule.ID]
+	if current == nil {
+		r.deltas[d.rule.ID] = d
+		return
+	}
+	for key, s := range d.series {
+		if existing := current.series[key]; existing != nil {
+			existing.Add(s)
+		} else {
+			current.series[key] = s
+		}
+	}
+}
+
+// Run flushes on an interval until ctx is cancelled.
+func (r *MetricRunner) Run(ctx context.Context) {
+	flushloop.Run(ctx, r.interval, r.Flush, "metric series", r.logger)
+}
+
+// labelsKey joins label values with a byte log fields do not contain.
+func labelsKey(values []string) string {
+	return strings.Join(values, "\x00")
+}
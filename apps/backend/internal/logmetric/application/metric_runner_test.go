@@ -0,0 +1,64 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/logmetric/domain"
+)
+
+// memRules serves Applicable only; the runner needs nothing else.
+type memRules struct {
+	domain.RuleRepository
+	rules []*domain.Rule
+	loads int
+}
+
+func (r *memRules) Applicable(context.Context, uuid.UUID, uuid.UUID) ([]*domain.Rule, error) {
+	r.loads++
+	return r.rules, nil
+}
+
+type memSeries struct {
+	domain.SeriesRepository
+	added map[string]uint64
+	err   error
+}
+
+func (m *memSeries) AddSeries(_ context.Context, _ *domain.Rule, deltas []*domain.Series, _ int) error {
+	if m.err != nil {
+		return m.err
+	}
+	for _, d := range deltas {
+		m.added[labelsKey(d.Labels)] += d.Count
+	}
+	return nil
+}
+
+func TestMetricRunnerCapsSeriesAndFlushes(t *testing.T) {
+	rule := &domain.Rule{ID: uuid.New(), Name: "requests_total", Kind: domain.KindCounter, GroupBy: []string{"host"}}
+	rules, series := &memRules{rules: []*domain.Rule{rule}}, &memSeries{added: map[string]uint64{}, err: errors.New("down")}
+	runner := NewMetricRunner(rules, series, time.Minute, time.Minute, 2, zap.NewNop())
+	tenantID, projectID := uuid.New(), uuid.New()
+
+	for _, host := range []string{"a", "b", "a", "c", "d"} {
+		require.NoError(t, runner.Observe(context.Background(), tenantID, projectID, &domain.Entry{Host: host, Weight: 2}))
+	}
+	assert.Equal(t, 1, rules.loads, "rules are cached")
+
+	// A failed flush keeps the deltas for the next one.
+	require.Error(t, runner.Flush(context.Background()))
+	series.err = nil
+	require.NoError(t, runner.Flush(context.Background()))
+	assert.Equal(t, map[string]uint64{"a": 4, "b": 2, domain.OverflowValue: 4}, series.added)
+
+	require.NoError(t, runner.Flush(context.Background()))
+	assert.Equal(t, uint64(4), series.added["a"], "flushed deltas are not added twice")
+}
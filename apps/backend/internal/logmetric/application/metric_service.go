@@ -0,0 +1,176 @@
+package application
+
+import (
+	"context"
+	"io"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/logmetric/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
+)
+
+// MetricService manages the metric rules of the caller's tenant and exposes
+// the series the log-processor counted for them.
+type MetricService interface {
+	List(ctx context.Context, projectID uuid.UUID) ([]*domain.Rule, error)
+	Get(ctx context.Context, projectID, id uuid.UUID) (*domain.Rule, error)
+	// Create starts counting from the next logs: rules do not apply to
+	// logs already stored.
+	Create(ctx context.Context, projectID uuid.UUID, input RuleInput) (*domain.Rule, error)
+	Update(ctx context.Context, projectID, id uuid.UUID, input UpdateRuleInput) (*domain.Rule, error)
+	// Delete removes the rule and its series.
+	Delete(ctx context.Context, projectID, id uuid.UUID) error
+
+	// Exposition writes the series of the tenant's rules, or of one
+	// project's when projectID is set, in the Prometheus text format. The
+	// series lag the logs by up to the log-processor's flush interval.
+	Exposition(ctx context.Context, w io.Writer, projectID *uuid.UUID) error
+}
+
+type metricService struct {
+	rules  domain.RuleRepository
+	series domain.SeriesRepository
+	logger *zap.Logger
+}
+
+func NewMetricService(rules domain.RuleRepository, series domain.SeriesRepository, logger *zap.Logger) MetricService {
+	return &metricService{
+		rules:  rules,
+		series: series,
+		logger: logger.Named("metric_service"),
+	}
+}
+
+func (s *metricService) List(ctx context.Context, projectID uuid.UUID) ([]*domain.Rule, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := s.rules.List(ctx, tenantID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if rules == nil {
+		rules = []*domain.Rule{}
+	}
+	return rules, nil
+}
+
+func (s *metricService) Get(ctx context.Context, projectID, id uuid.UUID) (*domain.Rule, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.rules.Get(ctx, tenantID, projectID, id)
+}
+
+func (s *metricService) Create(ctx context.Context, projectID uuid.UUID, input RuleInput) (*domain.Rule, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rule := &domain.Rule{
+		TenantID:    tenantID,
+		ProjectID:   projectID,
+		Name:        input.Name,
+		Description: input.Description,
+		Kind:        input.Kind,
+		Filter:      input.Filter,
+		Field:       input.Field,
+		Buckets:     input.Buckets,
+		GroupBy:     input.GroupBy,
+		Enabled:     input.Enabled == nil || *input.Enabled,
+	}
+	if rule.GroupBy == nil {
+		rule.GroupBy = []string{}
+	}
+	if userID, ok := middleware.UserUUIDFromContext(ctx); ok {
+		rule.CreatedBy = &userID
+	}
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.rules.Create(ctx, rule); err != nil {
+		return nil, err
+	}
+	s.logger.Info("metric rule created",
+		zap.String("tenant_id", tenantID.String()),
+		zap.String("project_id", projectID.String()),
+		zap.String("rule_id", rule.ID.String()),
+		zap.String("name", rule.Name),
+		zap.String("kind", string(rule.Kind)),
+	)
+	return rule, nil
+}
+
+func (s *metricService) Update(ctx context.Context, projectID, id uuid.UUID, input UpdateRuleInput) (*domain.Rule, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rule, err := s.rules.Get(ctx, tenantID, projectID, id)
+	if err != nil {
+		return nil, err
+	}
+	if input.Description != nil {
+		rule.Description = *input.Description
+	}
+	if input.Enabled != nil {
+		rule.Enabled = *input.Enabled
+	}
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.rules.Update(ctx, rule); err != nil {
+		return nil, err
+	}
+	s.logger.Info("metric rule updated",
+		zap.String("tenant_id", tenantID.String()),
+		zap.String("rule_id", rule.ID.String()),
+		zap.Bool("enabled", rule.Enabled),
+	)
+	return rule, nil
+}
+
+func (s *metricService) Delete(ctx context.Context, projectID, id uuid.UUID) error {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if err := s.rules.Delete(ctx, tenantID, projectID, id); err != nil {
+		return err
+	}
+	s.logger.Info("metric rule deleted",
+		zap.String("tenant_id", tenantID.String()),
+		zap.String("rule_id", id.String()),
+	)
+	return nil
+}
+
+func (s *metricService) Exposition(ctx context.Context, w io.Writer, projectID *uuid.UUID) error {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	rules, err := s.rules.ListTenant(ctx, tenantID, projectID)
+	if err != nil {
+		return err
+	}
+	series, err := s.series.ListSeries(ctx, tenantID, projectID)
+	if err != nil {
+		return err
+	}
+	return domain.WriteExposition(w, rules, series)
+}
+
+// tenantFromContext returns the caller's tenant as set by AuthMiddleware. The
+// tenant is never taken from request input.
+func tenantFromContext(ctx context.Context) (uuid.UUID, error) {
+	tenantID, ok := middleware.GetTenantUUIDFromContext(ctx)
+	if !ok {
+		return uuid.Nil, domain.ErrTenantRequired
+	}
+	return tenantID, nil
+}
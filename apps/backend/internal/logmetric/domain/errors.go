@@ -0,0 +1,18 @@
+package domain
+
+import "errors"
+
+// Domain errors. Service / transport layers compare against these with
+// errors.Is to map them onto HTTP status codes.
+var (
+	ErrTenantRequired  = errors.New("tenant is required")
+	ErrProjectNotFound = errors.New("project not found")
+	ErrRuleNotFound    = errors.New("metric rule not found")
+	// ErrNameTaken is a metric name already used in the tenant: every
+	// project's metrics are exposed together.
+	ErrNameTaken = errors.New("metric name already taken")
+	// ErrTooManyRules is returned once the tenant has MaxRulesPerTenant rules.
+	ErrTooManyRules = errors.New("too many metric rules")
+	// ErrInvalidRule is wrapped by every validation error of a rule.
+	ErrInvalidRule = errors.New("invalid metric rule")
+)
@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"bufio"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ExpositionContentType is the content type of WriteExposition's output.
+const ExpositionContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// WriteExposition renders the series of rules in the Prometheus text
+// format, one family per rule in name order. Series of rules not in rules
+// are skipped; rules without series are exposed with HELP and TYPE only.
+func WriteExposition(w io.Writer, rules []*Rule, series []Series) error {
+	byRule := make(map[uuid.UUID][]Series, len(rules))
+	for _, s := range series {
+		byRule[s.RuleID] = append(byRule[s.RuleID], s)
+	}
+	rules = slices.Clone(rules)
+	slices.SortFunc(rules, func(a, b *Rule) int { return strings.Compare(a.Name, b.Name) })
+
+	bw := bufio.NewWriter(w)
+	for _, r := range rules {
+		if r.Description != "" {
+			bw.WriteString("# HELP " + r.Name + " " + escapeHelp(r.Description) + "\n")
+		}
+		bw.WriteString("# TYPE " + r.Name + " " + string(r.Kind) + "\n")
+
+		rs := byRule[r.ID]
+		slices.SortFunc(rs, func(a, b Series) int { return slices.Compare(a.Labels, b.Labels) })
+		for _, s := range rs {
+			labels := r.labelPairs(s.Labels)
+			if r.Kind == KindCounter {
+				writeSample(bw, r.Name, labels, "", formatUint(s.Count))
+				continue
+			}
+			var cumulative uint64
+			for i, bound := range r.Buckets {
+				if i < len(s.Buckets) {
+					cumulative += s.Buckets[i]
+				}
+				writeSample(bw, r.Name+"_bucket", labels, formatFloat(bound), formatUint(cumulative))
+			}
+			writeSample(bw, r.Name+"_bucket", labels, "+Inf", formatUint(s.Count))
+			writeSample(bw, r.Name+"_sum", labels, "", formatFloat(s.Sum))
+			writeSample(bw, r.Name+"_count", labels, "", formatUint(s.Count))
+		}
+	}
+	return bw.Flush()
+}
+
+// labelPairs renders the labels of a series of r, without braces.
+func (r *Rule) labelPairs(values []string) string {
+	var b strings.Builder
+	b.WriteString(LabelTenantID + `="` + r.TenantID.String() + `",`)
+	b.WriteString(LabelProjectID + `="` + r.ProjectID.String() + `"`)
+	for i, f := range r.GroupBy {
+		v := ""
+		if i < len(values) {
+			v = values[i]
+		}
+		b.WriteString("," + LabelName(f) + `="` + escapeLabelValue(v) + `"`)
+	}
+	return b.String()
+}
+
+func writeSample(w *bufio.Writer, name, labels, le, value string) {
+	w.WriteString(name + "{" + labels)
+	if le != "" {
+		w.WriteString(`,le="` + le + `"`)
+	}
+	w.WriteString("} " + value + "\n")
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string       { return helpEscaper.Replace(s) }
+func escapeLabelValue(s string) string { return labelEscaper.Replace(s) }
+
+func formatUint(v uint64) string   { return strconv.FormatUint(v, 10) }
+func formatFloat(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) }
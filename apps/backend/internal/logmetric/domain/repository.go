@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// RuleRepository stores metric rules in Postgres. Every call is scoped to a
+// tenant: a project of another tenant behaves like a missing one
+// (ErrProjectNotFound).
+type RuleRepository interface {
+	// List returns a project's rules by name.
+	List(ctx context.Context, tenantID, projectID uuid.UUID) ([]*Rule, error)
+	// ListTenant returns the rules of every project of the tenant, or of one
+	// project when projectID is set.
+	ListTenant(ctx context.Context, tenantID uuid.UUID, projectID *uuid.UUID) ([]*Rule, error)
+	// Applicable returns the enabled rules of a project, for the
+	// log-processor.
+	Applicable(ctx context.Context, tenantID, projectID uuid.UUID) ([]*Rule, error)
+	Get(ctx context.Context, tenantID, projectID, id uuid.UUID) (*Rule, error)
+	// Create assigns the rule its ID and timestamps. It fails with
+	// ErrTooManyRules or ErrNameTaken.
+	Create(ctx context.Context, rule *Rule) error
+	// Update stores the rule's Description and Enabled; the shape of a
+	// rule does not change.
+	Update(ctx context.Context, rule *Rule) error
+	// Delete removes the rule and its series.
+	Delete(ctx context.Context, tenantID, projectID, id uuid.UUID) error
+}
+
+// SeriesRepository persists the series the log-processor keeps in memory.
+type SeriesRepository interface {
+	// AddSeries adds deltas, all of one rule, to the stored series. A new
+	// series beyond maxSeries of the rule is added to its overflow series
+	// instead. Deltas of a deleted rule are discarded.
+	AddSeries(ctx context.Context, rule *Rule, deltas []*Series, maxSeries int) error
+	// ListSeries returns the series of the tenant's rules, or of one
+	// project's when projectID is set.
+	ListSeries(ctx context.Context, tenantID uuid.UUID, projectID *uuid.UUID) ([]Series, error)
+}
@@ -0,0 +1,337 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxRulesPerTenant bounds the rules of a tenant, and with the series
+	// limit per rule, the series it can expose.
+	MaxRulesPerTenant = 100
+	// MaxGroupBy bounds the labels of a rule.
+	MaxGroupBy = 5
+	// MaxBuckets bounds the buckets of a histogram.
+	MaxBuckets = 30
+	// MaxFilterValues bounds each list of values a filter matches.
+	MaxFilterValues = 50
+	// MaxMessageContains bounds the substring a filter looks for.
+	MaxMessageContains = 256
+	// MaxDescription bounds a rule's description, exposed as HELP.
+	MaxDescription = 512
+)
+
+// OverflowValue is every label value of the series that counts the logs of
+// a rule once it has as many series as allowed.
+const OverflowValue = "__overflow__"
+
+// Labels every series carries besides those of GroupBy.
+const (
+	LabelTenantID  = "tenant_id"
+	LabelProjectID = "project_id"
+)
+
+// Kind is the type of metric a rule produces.
+type Kind string
+
+const (
+	// KindCounter counts the logs a rule matches.
+	KindCounter Kind = "counter"
+	// KindHistogram observes the numeric Field of the logs a rule matches.
+	KindHistogram Kind = "histogram"
+)
+
+// DefaultBuckets are the upper bounds of histograms defined without any,
+// suited to durations in milliseconds.
+var DefaultBuckets = []float64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// Fields a rule may group by or observe besides attributes.<key> and
+// tags.<key>.
+const (
+	FieldLevel       = "level"
+	FieldService     = "service"
+	FieldEnvironment = "environment"
+	FieldHost        = "host"
+	FieldNamespace   = "namespace"
+	FieldSource      = "source"
+	attributesPrefix = "attributes."
+	tagsPrefix       = "tags."
+)
+
+// Filter selects the logs a rule counts. Every condition set must hold;
+// values within one list are alternatives. An empty Filter selects every log.
+type Filter struct {
+	Services []string `json:"services,omitempty"`
+	// Levels are compared case-insensitively.
+	Levels       []string `json:"levels,omitempty"`
+	Environments []string `json:"environments,omitempty"`
+	Hosts        []string `json:"hosts,omitempty"`
+	// MessageContains is a case-sensitive substring of the message.
+	MessageContains string            `json:"message_contains,omitempty"`
+	Attributes      map[string]string `json:"attributes,omitempty"`
+}
+
+// Rule turns a project's logs into a metric. Its shape (Name, Kind, Field,
+// Buckets, GroupBy and Filter) is fixed once created: series of different
+// shapes under one name would not add up.
+type Rule struct {
+	ID          uuid.UUID `json:"id"`
+	TenantID    uuid.UUID `json:"-"`
+	ProjectID   uuid.UUID `json:"project_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Kind        Kind      `json:"kind"`
+	Filter      Filter    `json:"filter"`
+	// Field is the numeric field a histogram observes, e.g.
+	// attributes.duration_ms. Logs without a number in it are skipped.
+	Field string `json:"field,omitempty"`
+	// Buckets are the upper bounds of a histogram, increasing.
+	Buckets []float64 `json:"buckets,omitempty"`
+	// GroupBy are the fields whose values label the series.
+	GroupBy   []string   `json:"group_by"`
+	Enabled   bool       `json:"enabled"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+var (
+	metricName = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]{0,127}$`)
+	fieldKey   = regexp.MustCompile(`^[A-Za-z0-9_.\-@/]{1,128}$`)
+	labelChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+)
+
+// Validate checks a rule's definition and fills in default buckets. Every
+// error wraps ErrInvalidRule.
+func (r *Rule) Validate() error {
+	if err := r.validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidRule, err)
+	}
+	return nil
+}
+
+func (r *Rule) validate() error {
+	if !metricName.MatchString(r.Name) || strings.HasPrefix(r.Name, "__") {
+		return fmt.Errorf("name must be a Prometheus metric name of at most 128 characters")
+	}
+	if len(r.Description) > MaxDescription {
+		return fmt.Errorf("description is longer than %d bytes", MaxDescription)
+	}
+	switch r.Kind {
+	case KindCounter:
+		if r.Field != "" || len(r.Buckets) > 0 {
+			return fmt.Errorf("field and buckets are for histograms only")
+		}
+	case KindHistogram:
+		if !validField(r.Field) {
+			return fmt.Errorf("histograms need a field to observe")
+		}
+		if len(r.Buckets) == 0 {
+			r.Buckets = slices.Clone(DefaultBuckets)
+		}
+		if len(r.Buckets) > MaxBuckets {
+			return fmt.Errorf("more than %d buckets", MaxBuckets)
+		}
+		for i, b := range r.Buckets {
+			if math.IsNaN(b) || math.IsInf(b, 0) || i > 0 && b <= r.Buckets[i-1] {
+				return fmt.Errorf("buckets must be finite and increasing")
+			}
+		}
+	default:
+		return fmt.Errorf("unknown kind %q", r.Kind)
+	}
+
+	if len(r.GroupBy) > MaxGroupBy {
+		return fmt.Errorf("more than %d group_by fields", MaxGroupBy)
+	}
+	seen := map[string]bool{LabelTenantID: true, LabelProjectID: true, "le": true}
+	for _, f := range r.GroupBy {
+		if !validField(f) {
+			return fmt.Errorf("invalid group_by field %q", f)
+		}
+		label := LabelName(f)
+		if seen[label] || strings.HasPrefix(label, "__") {
+			return fmt.Errorf("group_by field %q yields the label %q, which is reserved or used twice", f, label)
+		}
+		seen[label] = true
+	}
+
+	f := r.Filter
+	for _, values := range [][]string{f.Services, f.Levels, f.Environments, f.Hosts} {
+		if len(values) > MaxFilterValues {
+			return fmt.Errorf("more than %d values in one filter list", MaxFilterValues)
+		}
+	}
+	if len(f.Attributes) > MaxFilterValues {
+		return fmt.Errorf("more than %d attributes to filter on", MaxFilterValues)
+	}
+	if len(f.MessageContains) > MaxMessageContains {
+		return fmt.Errorf("message_contains is longer than %d bytes", MaxMessageContains)
+	}
+	return nil
+}
+
+func validField(path string) bool {
+	switch path {
+	case FieldLevel, FieldService, FieldEnvironment, FieldHost, FieldNamespace, FieldSource:
+		return true
+	}
+	if key, ok := strings.CutPrefix(path, attributesPrefix); ok {
+		return fieldKey.MatchString(key)
+	}
+	if key, ok := strings.CutPrefix(path, tagsPrefix); ok {
+		return fieldKey.MatchString(key)
+	}
+	return false
+}
+
+// LabelName is the label a group_by field is exposed as: the attribute or
+// tag key, or the field itself, with characters Prometheus does not allow
+// replaced by '_'.
+func LabelName(field string) string {
+	name := strings.TrimPrefix(strings.TrimPrefix(field, attributesPrefix), tagsPrefix)
+	name = labelChars.ReplaceAllString(name, "_")
+	if name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
+
+// Entry is the part of a log rules look at.
+type Entry struct {
+	Level       string
+	Service     string
+	Environment string
+	Host        string
+	Namespace   string
+	Source      string
+	Message     string
+	Attributes  map[string]string
+	Tags        map[string]string
+	// Weight is the number of logs the entry stands for after sampling.
+	Weight uint32
+}
+
+func (e *Entry) field(path string) string {
+	switch path {
+	case FieldLevel:
+		return e.Level
+	case FieldService:
+		return e.Service
+	case FieldEnvironment:
+		return e.Environment
+	case FieldHost:
+		return e.Host
+	case FieldNamespace:
+		return e.Namespace
+	case FieldSource:
+		return e.Source
+	}
+	if key, ok := strings.CutPrefix(path, attributesPrefix); ok {
+		return e.Attributes[key]
+	}
+	key, _ := strings.CutPrefix(path, tagsPrefix)
+	return e.Tags[key]
+}
+
+// Matches reports whether the rule counts e.
+func (r *Rule) Matches(e *Entry) bool {
+	f := r.Filter
+	if len(f.Services) > 0 && !slices.Contains(f.Services, e.Service) {
+		return false
+	}
+	if len(f.Levels) > 0 && !slices.ContainsFunc(f.Levels, func(l string) bool { return strings.EqualFold(l, e.Level) }) {
+		return false
+	}
+	if len(f.Environments) > 0 && !slices.Contains(f.Environments, e.Environment) {
+		return false
+	}
+	if len(f.Hosts) > 0 && !slices.Contains(f.Hosts, e.Host) {
+		return false
+	}
+	if f.MessageContains != "" && !strings.Contains(e.Message, f.MessageContains) {
+		return false
+	}
+	for k, v := range f.Attributes {
+		if got, ok := e.Attributes[k]; !ok || got != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Observe adds e to s, which must be a series of the rule. It reports
+// false, leaving s untouched, when the rule does not count e.
+func (r *Rule) Observe(s *Series, e *Entry) bool {
+	weight := uint64(max(e.Weight, 1))
+	if r.Kind == KindCounter {
+		s.Count += weight
+		return true
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(e.field(r.Field)), 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return false
+	}
+	s.Count += weight
+	s.Sum += v * float64(weight)
+	// Values above every bound only count towards +Inf, which is Count.
+	if i, _ := slices.BinarySearch(r.Buckets, v); i < len(r.Buckets) {
+		s.Buckets[i] += weight
+	}
+	return true
+}
+
+// LabelValues returns the values of e labelling its series.
+func (r *Rule) LabelValues(e *Entry) []string {
+	values := make([]string, len(r.GroupBy))
+	for i, f := range r.GroupBy {
+		values[i] = e.field(f)
+	}
+	return values
+}
+
+// NewSeries returns an empty series of the rule.
+func (r *Rule) NewSeries(labels []string) *Series {
+	s := &Series{RuleID: r.ID, Labels: labels}
+	if r.Kind == KindHistogram {
+		s.Buckets = make([]uint64, len(r.Buckets))
+	}
+	return s
+}
+
+// OverflowLabels are the label values of the rule's overflow series.
+func (r *Rule) OverflowLabels() []string {
+	labels := make([]string, len(r.GroupBy))
+	for i := range labels {
+		labels[i] = OverflowValue
+	}
+	return labels
+}
+
+// Series is one labelled time series of a rule. Labels are the values of
+// the rule's GroupBy, in order. For a histogram, Buckets[i] counts the
+// observations in (Buckets[i-1], Buckets[i]] of the rule; Count and Sum
+// cover every observation.
+type Series struct {
+	RuleID  uuid.UUID
+	Labels  []string
+	Count   uint64
+	Sum     float64
+	Buckets []uint64
+}
+
+// Add adds the observations of o, a series of the same rule, to s.
+func (s *Series) Add(o *Series) {
+	s.Count += o.Count
+	s.Sum += o.Sum
+	for i := range min(len(s.Buckets), len(o.Buckets)) {
+		s.Buckets[i] += o.Buckets[i]
+	}
+}
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	invalid := map[string]Rule{
+		"bad name":             {Name: "http-errors", Kind: KindCounter},
+		"reserved name":        {Name: "__errors", Kind: KindCounter},
+		"unknown kind":         {Name: "m", Kind: "gauge"},
+		"counter with field":   {Name: "m", Kind: KindCounter, Field: "attributes.ms"},
+		"histogram no field":   {Name: "m", Kind: KindHistogram},
+		"unsorted buckets":     {Name: "m", Kind: KindHistogram, Field: "attributes.ms", Buckets: []float64{10, 5}},
+		"unknown group_by":     {Name: "m", Kind: KindCounter, GroupBy: []string{"message"}},
+		"reserved label":       {Name: "m", Kind: KindCounter, GroupBy: []string{"tags.tenant_id"}},
+		"label used twice":     {Name: "m", Kind: KindCounter, GroupBy: []string{"service", "tags.service"}},
+		"too many group_by":    {Name: "m", Kind: KindCounter, GroupBy: []string{"level", "service", "host", "source", "namespace", "environment"}},
+		"le is the bucket key": {Name: "m", Kind: KindCounter, GroupBy: []string{"attributes.le"}},
+	}
+	for name, rule := range invalid {
+		assert.ErrorIs(t, rule.Validate(), ErrInvalidRule, name)
+	}
+
+	h := Rule{Name: "request_duration_ms", Kind: KindHistogram, Field: "attributes.duration_ms"}
+	require.NoError(t, h.Validate())
+	assert.Equal(t, DefaultBuckets, h.Buckets)
+	assert.Equal(t, "http_status", LabelName("attributes.http.status"))
+}
+
+func TestObserveWeightsBySampleRate(t *testing.T) {
+	counter := &Rule{Name: "errors_total", Kind: KindCounter, Filter: Filter{Levels: []string{"error"}}, GroupBy: []string{"service"}}
+	require.NoError(t, counter.Validate())
+
+	e := &Entry{Level: "ERROR", Service: "api", Weight: 10}
+	require.True(t, counter.Matches(e))
+	s := counter.NewSeries(counter.LabelValues(e))
+	assert.True(t, counter.Observe(s, e))
+	assert.Equal(t, []string{"api"}, s.Labels)
+	assert.Equal(t, uint64(10), s.Count)
+	assert.False(t, counter.Matches(&Entry{Level: "info"}))
+
+	hist := &Rule{Name: "latency_ms", Kind: KindHistogram, Field: "attributes.ms", Buckets: []float64{10, 100}}
+	require.NoError(t, hist.Validate())
+	s = hist.NewSeries(nil)
+	for _, v := range []string{"5", "10", "50", "500"} {
+		assert.True(t, hist.Observe(s, &Entry{Attributes: map[string]string{"ms": v}}))
+	}
+	assert.False(t, hist.Observe(s, &Entry{Attributes: map[string]string{"ms": "slow"}}), "not a number")
+	assert.Equal(t, uint64(4), s.Count)
+	assert.Equal(t, 565.0, s.Sum)
+	assert.Equal(t, []uint64{2, 1}, s.Buckets, "bounds are inclusive; 500 only counts towards +Inf")
+}
+
+func TestWriteExposition(t *testing.T) {
+	tenantID, projectID := uuid.MustParse("00000000-0000-0000-0000-000000000001"), uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	counter := &Rule{ID: uuid.New(), TenantID: tenantID, ProjectID: projectID, Name: "errors_total",
+		Description: "Errors by service.", Kind: KindCounter, GroupBy: []string{"service"}}
+	hist := &Rule{ID: uuid.New(), TenantID: tenantID, ProjectID: projectID, Name: "latency_ms",
+		Kind: KindHistogram, Field: "attributes.ms", Buckets: []float64{10, 100}}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteExposition(&buf, []*Rule{hist, counter}, []Series{
+		{RuleID: counter.ID, Labels: []string{`a"b`}, Count: 3},
+		{RuleID: hist.ID, Count: 4, Sum: 565, Buckets: []uint64{2, 1}},
+		{RuleID: uuid.New(), Count: 1},
+	}))
+
+	labels := `tenant_id="00000000-0000-0000-0000-000000000001",project_id="00000000-0000-0000-0000-000000000002"`
+	assert.Equal(t, `# HELP errors_total Errors by service.
+# TYPE errors_total counter
+errors_total{`+labels+`,service="a\"b"} 3
+# TYPE latency_ms histogram
+latency_ms_bucket{`+labels+`,le="10"} 2
+latency_ms_bucket{`+labels+`,le="100"} 3
+latency_ms_bucket{`+labels+`,le="+Inf"} 4
+latency_ms_sum{`+labels+`} 565
+latency_ms_count{`+labels+`} 4
+`, buf.String())
+}
@@ -0,0 +1,308 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/logmetric/domain"
+)
+
+const pgUniqueViolation = "23505"
+
+// RuleRepository implements domain.RuleRepository and
+// domain.SeriesRepository.
+type RuleRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewRuleRepository(db *pgxpool.Pool) *RuleRepository {
+	return &RuleRepository{db: db}
+}
+
+const selectRule = `
+	SELECT id, tenant_id, project_id, name, description, kind, filter, field, buckets,
+	       group_by, enabled, created_by, created_at, updated_at
+	FROM log_metric_rules
+`
+
+func (r *RuleRepository) List(ctx context.Context, tenantID, projectID uuid.UUID) ([]*domain.Rule, error) {
+	if err := r.projectExists(ctx, tenantID, projectID); err != nil {
+		return nil, err
+	}
+	return r.query(ctx, selectRule+`WHERE tenant_id = $1 AND project_id = $2 ORDER BY name`, tenantID, projectID)
+}
+
+func (r *RuleRepository) ListTenant(ctx context.Context, tenantID uuid.UUID, projectID *uuid.UUID) ([]*domain.Rule, error) {
+	return r.query(ctx, selectRule+`
+		WHERE tenant_id = $1 AND ($2::uuid IS NULL OR project_id = $2)
+		ORDER BY name
+	`, tenantID, projectID)
+}
+
+func (r *RuleRepository) Applicable(ctx context.Context, tenantID, projectID uuid.UUID) ([]*domain.Rule, error) {
+	return r.query(ctx, selectRule+`WHERE tenant_id = $1 AND project_id = $2 AND enabled ORDER BY name`, tenantID, projectID)
+}
+
+func (r *RuleRepository) Get(ctx context.Context, tenantID, projectID, id uuid.UUID) (*domain.Rule, error) {
+	if err := r.projectExists(ctx, tenantID, projectID); err != nil {
+		return nil, err
+	}
+	rule, err := scanRule(r.db.QueryRow(ctx, selectRule+`WHERE id = $1 AND tenant_id = $2 AND project_id = $3`,
+		id, tenantID, projectID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrRuleNotFound
+	}
+	return rule, err
+}
+
+func (r *RuleRepository) Create(ctx context.Context, rule *domain.Rule) error {
+	filter, err := json.Marshal(rule.Filter)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Locking the project serialises creations within it; the tenant row
+	// serialises them across its projects, so the count holds.
+	var one int
+	if err := tx.QueryRow(ctx, `
+		SELECT 1 FROM projects
+		WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL
+		FOR UPDATE
+	`, rule.ProjectID, rule.TenantID).Scan(&one); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrProjectNotFound
+		}
+		return err
+	}
+	if err := tx.QueryRow(ctx, `SELECT 1 FROM tenants WHERE id = $1 FOR UPDATE`, rule.TenantID).Scan(&one); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrTenantRequired
+		}
+		return err
+	}
+	var count int
+	if err := tx.QueryRow(ctx, `SELECT count(*) FROM log_metric_rules WHERE tenant_id = $1`, rule.TenantID).Scan(&count); err != nil {
+		return err
+	}
+	if count >= domain.MaxRulesPerTenant {
+		return domain.ErrTooManyRules
+	}
+
+	now := time.Now().UTC()
+	rule.ID, rule.CreatedAt, rule.UpdatedAt = uuid.New(), now, now
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO log_metric_rules
+			(id, tenant_id, project_id, name, description, kind, filter, field, buckets, group_by,
+			 enabled, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $13)
+	`, rule.ID, rule.TenantID, rule.ProjectID, rule.Name, rule.Description, rule.Kind, filter, rule.Field,
+		orEmpty(rule.Buckets), orEmpty(rule.GroupBy), rule.Enabled, rule.CreatedBy, now); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+			return domain.ErrNameTaken
+		}
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *RuleRepository) Update(ctx context.Context, rule *domain.Rule) error {
+	rule.UpdatedAt = time.Now().UTC()
+	tag, err := r.db.Exec(ctx, `
+		UPDATE log_metric_rules SET description = $4, enabled = $5, updated_at = $6
+		WHERE id = $1 AND tenant_id = $2 AND project_id = $3
+	`, rule.ID, rule.TenantID, rule.ProjectID, rule.Description, rule.Enabled, rule.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrRuleNotFound
+	}
+	return nil
+}
+
+func (r *RuleRepository) Delete(ctx context.Context, tenantID, projectID, id uuid.UUID) error {
+	if err := r.projectExists(ctx, tenantID, projectID); err != nil {
+		return err
+	}
+	tag, err := r.db.Exec(ctx, `
+		DELETE FROM log_metric_rules WHERE id = $1 AND tenant_id = $2 AND project_id = $3
+	`, id, tenantID, projectID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrRuleNotFound
+	}
+	return nil
+}
+
+const (
+	// addSeries adds a delta to a series, creating it only while the rule
+	// has fewer than $8 series. Buckets are added element-wise.
+	addSeries = `
+		INSERT INTO log_metric_series (rule_id, tenant_id, labels, count, sum, buckets, updated_at)
+		SELECT $1, $2, $3::text[], $4::bigint, $5::float8, $6::bigint[], $7
+		WHERE EXISTS (SELECT 1 FROM log_metric_series WHERE rule_id = $1 AND labels = $3::text[])
+		   OR (SELECT count(*) FROM log_metric_series WHERE rule_id = $1) < $8::int
+		ON CONFLICT (rule_id, labels) DO UPDATE SET ` + mergeSeries
+	// addOverflow adds a delta to the overflow series, whatever the limit.
+	addOverflow = `
+		INSERT INTO log_metric_series (rule_id, tenant_id, labels, count, sum, buckets, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (rule_id, labels) DO UPDATE SET ` + mergeSeries
+	mergeSeries = `
+			count = log_metric_series.count + EXCLUDED.count,
+			sum = log_metric_series.sum + EXCLUDED.sum,
+			buckets = COALESCE((
+				SELECT array_agg(a + b ORDER BY i)
+				FROM unnest(log_metric_series.buckets, EXCLUDED.buckets) WITH ORDINALITY AS t(a, b, i)
+			), '{}'),
+			updated_at = EXCLUDED.updated_at
+	`
+)
+
+func (r *RuleRepository) AddSeries(ctx context.Context, rule *domain.Rule, deltas []*domain.Series, maxSeries int) error {
+	if len(deltas) == 0 {
+		return nil
+	}
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Locking the rule serialises flushes of several processors, so the
+	// series limit holds; a deleted rule discards its deltas.
+	var one int
+	if err := tx.QueryRow(ctx, `SELECT 1 FROM log_metric_rules WHERE id = $1 FOR UPDATE`, rule.ID).Scan(&one); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	now := time.Now().UTC()
+	overflow := rule.NewSeries(rule.OverflowLabels())
+	for _, d := range deltas {
+		tag, err := tx.Exec(ctx, addSeries, rule.ID, rule.TenantID, orEmpty(d.Labels), int64(d.Count), d.Sum,
+			toInt64s(d.Buckets), now, maxSeries)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			overflow.Add(d)
+		}
+	}
+	if overflow.Count > 0 {
+		if _, err := tx.Exec(ctx, addOverflow, rule.ID, rule.TenantID, overflow.Labels, int64(overflow.Count),
+			overflow.Sum, toInt64s(overflow.Buckets), now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *RuleRepository) ListSeries(ctx context.Context, tenantID uuid.UUID, projectID *uuid.UUID) ([]domain.Series, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT s.rule_id, s.labels, s.count, s.sum, s.buckets
+		FROM log_metric_series s
+		JOIN log_metric_rules r ON r.id = s.rule_id
+		WHERE s.tenant_id = $1 AND ($2::uuid IS NULL OR r.project_id = $2)
+	`, tenantID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.Series
+	for rows.Next() {
+		var (
+			s       domain.Series
+			count   int64
+			buckets []int64
+		)
+		if err := rows.Scan(&s.RuleID, &s.Labels, &count, &s.Sum, &buckets); err != nil {
+			return nil, err
+		}
+		s.Count = uint64(count)
+		s.Buckets = make([]uint64, len(buckets))
+		for i, b := range buckets {
+			s.Buckets[i] = uint64(b)
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+func (r *RuleRepository) query(ctx context.Context, query string, args ...any) ([]*domain.Rule, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []*domain.Rule
+	for rows.Next() {
+		rule, err := scanRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+func (r *RuleRepository) projectExists(ctx context.Context, tenantID, projectID uuid.UUID) error {
+	var one int
+	err := r.db.QueryRow(ctx, `
+		SELECT 1 FROM projects WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL
+	`, projectID, tenantID).Scan(&one)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrProjectNotFound
+	}
+	return err
+}
+
+func scanRule(row pgx.Row) (*domain.Rule, error) {
+	rule := &domain.Rule{}
+	var filter []byte
+	if err := row.Scan(
+		&rule.ID, &rule.TenantID, &rule.ProjectID, &rule.Name, &rule.Description, &rule.Kind, &filter,
+		&rule.Field, &rule.Buckets, &rule.GroupBy, &rule.Enabled, &rule.CreatedBy, &rule.CreatedAt, &rule.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(filter, &rule.Filter); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func orEmpty[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
+
+func toInt64s(v []uint64) []int64 {
+	out := make([]int64, len(v))
+	for i, n := range v {
+		out[i] = int64(n)
+	}
+	return out
+}
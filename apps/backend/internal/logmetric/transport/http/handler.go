@@ -0,0 +1,250 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/logmetric/application"
+	"github.com/indalyadav56/logify/apps/backend/internal/logmetric/domain"
+	"github.com/indalyadav56/logify/apps/backend/pkg/response"
+	"github.com/indalyadav56/logify/apps/backend/pkg/validator"
+)
+
+type MetricHandler struct {
+	service application.MetricService
+}
+
+func NewMetricHandler(service application.MetricService) *MetricHandler {
+	return &MetricHandler{service: service}
+}
+
+// List returns a project's metric rules.
+// @Summary      List metric rules
+// @Description  Return the project's log-to-metric rules by name.
+// @Tags         metrics
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Project ID (UUID)"
+// @Success      200  {object}  response.APIResponse "Metric rules retrieved successfully"
+// @Failure      400  {object}  response.APIResponse "Invalid id format"
+// @Failure      404  {object}  response.APIResponse "Project not found"
+// @Failure      500  {object}  response.APIResponse "Internal server error"
+// @Router       /v1/projects/{id}/metric-rules [get]
+func (h *MetricHandler) List(c *gin.Context) {
+	projectID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	rules, err := h.service.List(c.Request.Context(), projectID)
+	if err != nil {
+		h.writeError(c, err, "Failed to retrieve metric rules")
+		return
+	}
+	response.OK(c, "Metric rules retrieved successfully", rules)
+}
+
+// Create adds a metric rule to a project.
+// @Summary      Create metric rule
+// @Description  Define a counter of matching logs, or a histogram of a numeric field, labelled by up to five fields. The log-processor counts the project's new logs within a minute; the series are exposed at /metrics.
+// @Tags         metrics
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                 true  "Project ID (UUID)"
+// @Param        request  body      application.RuleInput  true  "Metric rule"
+// @Success      201      {object}  response.APIResponse "Metric rule created successfully"
+// @Failure      400      {object}  response.APIResponse "Invalid request"
+// @Failure      404      {object}  response.APIResponse "Project not found"
+// @Failure      409      {object}  response.APIResponse "Name already taken"
+// @Failure      500      {object}  response.APIResponse "Internal server error"
+// @Router       /v1/projects/{id}/metric-rules [post]
+func (h *MetricHandler) Create(c *gin.Context) {
+	projectID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input application.RuleInput
+	if !validator.ValidateRequest(c, &input) {
+		return
+	}
+
+	rule, err := h.service.Create(c.Request.Context(), projectID, input)
+	if err != nil {
+		h.writeError(c, err, "Failed to create metric rule")
+		return
+	}
+	response.Created(c, "Metric rule created successfully", rule)
+}
+
+// Get returns a metric rule.
+// @Summary      Get metric rule
+// @Tags         metrics
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id      path      string  true  "Project ID (UUID)"
+// @Param        ruleId  path      string  true  "Rule ID (UUID)"
+// @Success      200     {object}  response.APIResponse "Metric rule retrieved successfully"
+// @Failure      400     {object}  response.APIResponse "Invalid id format"
+// @Failure      404     {object}  response.APIResponse "Metric rule not found"
+// @Failure      500     {object}  response.APIResponse "Internal server error"
+// @Router       /v1/projects/{id}/metric-rules/{ruleId} [get]
+func (h *MetricHandler) Get(c *gin.Context) {
+	projectID, id, ok := parseIDs(c)
+	if !ok {
+		return
+	}
+
+	rule, err := h.service.Get(c.Request.Context(), projectID, id)
+	if err != nil {
+		h.writeError(c, err, "Failed to retrieve metric rule")
+		return
+	}
+	response.OK(c, "Metric rule retrieved successfully", rule)
+}
+
+// Update changes a metric rule's description or enables and disables it.
+// @Summary      Update metric rule
+// @Description  Change the description, exposed as HELP, or stop and resume counting. A disabled rule keeps its series. The shape of a rule is fixed; define another rule instead.
+// @Tags         metrics
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                       true  "Project ID (UUID)"
+// @Param        ruleId   path      string                       true  "Rule ID (UUID)"
+// @Param        request  body      application.UpdateRuleInput  true  "Changes"
+// @Success      200      {object}  response.APIResponse "Metric rule updated successfully"
+// @Failure      400      {object}  response.APIResponse "Invalid request"
+// @Failure      404      {object}  response.APIResponse "Metric rule not found"
+// @Failure      500      {object}  response.APIResponse "Internal server error"
+// @Router       /v1/projects/{id}/metric-rules/{ruleId} [put]
+func (h *MetricHandler) Update(c *gin.Context) {
+	projectID, id, ok := parseIDs(c)
+	if !ok {
+		return
+	}
+
+	var input application.UpdateRuleInput
+	if !validator.ValidateRequest(c, &input) {
+		return
+	}
+
+	rule, err := h.service.Update(c.Request.Context(), projectID, id, input)
+	if err != nil {
+		h.writeError(c, err, "Failed to update metric rule")
+		return
+	}
+	response.OK(c, "Metric rule updated successfully", rule)
+}
+
+// Delete removes a metric rule and its series.
+// @Summary      Delete metric rule
+// @Tags         metrics
+// @Security     BearerAuth
+// @Param        id      path  string  true  "Project ID (UUID)"
+// @Param        ruleId  path  string  true  "Rule ID (UUID)"
+// @Success      204     "Metric rule deleted"
+// @Failure      400     {object}  response.APIResponse "Invalid id format"
+// @Failure      404     {object}  response.APIResponse "Metric rule not found"
+// @Failure      500     {object}  response.APIResponse "Internal server error"
+// @Router       /v1/projects/{id}/metric-rules/{ruleId} [delete]
+func (h *MetricHandler) Delete(c *gin.Context) {
+	projectID, id, ok := parseIDs(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), projectID, id); err != nil {
+		h.writeError(c, err, "Failed to delete metric rule")
+		return
+	}
+	response.NoContent(c)
+}
+
+// Metrics exposes the series of every metric rule of the caller's tenant.
+// @Summary      Scrape log metrics
+// @Description  Return the tenant's log-derived metrics in the Prometheus text format, for a Prometheus scrape job authenticating with an access token. Every series is labelled with tenant_id and project_id.
+// @Tags         metrics
+// @Produce      plain
+// @Security     BearerAuth
+// @Success      200  {string}  string "Prometheus exposition"
+// @Failure      500  {object}  response.APIResponse "Internal server error"
+// @Router       /metrics [get]
+func (h *MetricHandler) Metrics(c *gin.Context) {
+	h.writeExposition(c, nil)
+}
+
+// ProjectMetrics exposes the series of a project's metric rules.
+// @Summary      Scrape a project's log metrics
+// @Description  Return the project's log-derived metrics in the Prometheus text format.
+// @Tags         metrics
+// @Produce      plain
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Project ID (UUID)"
+// @Success      200  {string}  string "Prometheus exposition"
+// @Failure      400  {object}  response.APIResponse "Invalid id format"
+// @Failure      500  {object}  response.APIResponse "Internal server error"
+// @Router       /v1/projects/{id}/metrics [get]
+func (h *MetricHandler) ProjectMetrics(c *gin.Context) {
+	projectID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	h.writeExposition(c, &projectID)
+}
+
+// writeExposition renders into a buffer first, so a failure still gets the
+// JSON error envelope.
+func (h *MetricHandler) writeExposition(c *gin.Context, projectID *uuid.UUID) {
+	var buf bytes.Buffer
+	if err := h.service.Exposition(c.Request.Context(), &buf, projectID); err != nil {
+		h.writeError(c, err, "Failed to render metrics")
+		return
+	}
+	c.Data(http.StatusOK, domain.ExpositionContentType, buf.Bytes())
+}
+
+// writeError maps domain errors to HTTP responses with a consistent envelope.
+func (h *MetricHandler) writeError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrProjectNotFound):
+		response.NotFound(c, "Project not found")
+	case errors.Is(err, domain.ErrRuleNotFound):
+		response.NotFound(c, "Metric rule not found")
+	case errors.Is(err, domain.ErrTenantRequired):
+		response.Unauthorized(c, "Tenant ID is required")
+	case errors.Is(err, domain.ErrNameTaken):
+		response.Conflict(c, err.Error())
+	case errors.Is(err, domain.ErrInvalidRule),
+		errors.Is(err, domain.ErrTooManyRules):
+		response.BadRequest(c, err.Error())
+	default:
+		response.InternalServerError(c, fallback)
+	}
+}
+
+func parseIDs(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	projectID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+	id, ok := parseUUIDParam(c, "ruleId")
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+	return projectID, id, true
+}
+
+func parseUUIDParam(c *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		response.BadRequest(c, "Invalid "+name+" format")
+		return uuid.Nil, false
+	}
+	return id, true
+}
@@ -0,0 +1,33 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+
+	roleDomain "github.com/indalyadav56/logify/apps/backend/internal/role/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
+)
+
+// RegisterRoutes mounts the metric rule routes under their project. The
+// group is expected to be already authenticated (see di.RegisterAllRoutes).
+func RegisterRoutes(router *gin.RouterGroup, handler *MetricHandler, authz *middleware.Authorizer) {
+	read := authz.RequireProjectPermission("id", roleDomain.ResourceProject, roleDomain.ActionRead)
+	write := authz.RequireProjectPermission("id", roleDomain.ResourceProject, roleDomain.ActionWrite)
+
+	g := router.Group("/v1/projects/:id/metric-rules")
+	{
+		g.GET("", read, handler.List)
+		g.POST("", write, handler.Create)
+		g.GET("/:ruleId", read, handler.Get)
+		g.PUT("/:ruleId", write, handler.Update)
+		g.DELETE("/:ruleId", write, handler.Delete)
+	}
+}
+
+// RegisterExpositionRoutes mounts the scrape endpoints. The series are log
+// data, so the group is expected to be the data plane, which a suspended
+// tenant cannot read.
+func RegisterExpositionRoutes(router *gin.RouterGroup, handler *MetricHandler, authz *middleware.Authorizer) {
+	router.GET("/metrics", authz.RequirePermission(roleDomain.ResourceLog, roleDomain.ActionRead), handler.Metrics)
+	router.GET("/v1/projects/:id/metrics",
+		authz.RequireProjectPermission("id", roleDomain.ResourceLog, roleDomain.ActionRead), handler.ProjectMetrics)
+}
@@ -2,44 +2,48 @@ package http
 
 import (
 	"github.com/gin-gonic/gin"
+
+	roleDomain "github.com/indalyadav56/logify/apps/backend/internal/role/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
 )
 
 // RegisterRoutes sets up notification channel, alert rule, and notification routes.
-func RegisterRoutes(router *gin.RouterGroup, handler NotificationDashboardHandler, jwtSecret string) {
-	// auth := middleware.AuthMiddleware()
+// The group is expected to be already authenticated (see di.RegisterAllRoutes).
+func RegisterRoutes(router *gin.RouterGroup, handler NotificationDashboardHandler, authz *middleware.Authorizer) {
+	notificationRead := authz.RequirePermission(roleDomain.ResourceNotification, roleDomain.ActionRead)
+	notificationWrite := authz.RequirePermission(roleDomain.ResourceNotification, roleDomain.ActionWrite)
+	alertRead := authz.RequirePermission(roleDomain.ResourceAlert, roleDomain.ActionRead)
+	alertWrite := authz.RequirePermission(roleDomain.ResourceAlert, roleDomain.ActionWrite)
 
 	// Notification channels
-	channels := router.Group("/notification-channels")
-	// channels.Use(auth)
+	channels := router.Group("/v1/notification-channels")
 	{
-		channels.GET("", handler.ListChannels)
-		channels.POST("", handler.CreateChannel)
-		channels.GET("/:id", handler.GetChannel)
-		channels.PATCH("/:id", handler.UpdateChannel)
-		channels.DELETE("/:id", handler.DeleteChannel)
-		channels.POST("/:id/test", handler.TestChannel)
+		channels.GET("", notificationRead, handler.ListChannels)
+		channels.POST("", notificationWrite, handler.CreateChannel)
+		channels.GET("/:id", notificationRead, handler.GetChannel)
+		channels.PATCH("/:id", notificationWrite, handler.UpdateChannel)
+		channels.DELETE("/:id", notificationWrite, handler.DeleteChannel)
+		channels.POST("/:id/test", notificationWrite, handler.TestChannel)
 	}
 
 	// Alert rules
-	rules := router.Group("/alert-rules")
-	// rules.Use(auth)
+	rules := router.Group("/v1/alert-rules")
 	{
-		rules.GET("", handler.ListAlertRules)
-		rules.POST("", handler.CreateAlertRule)
-		rules.GET("/:id", handler.GetAlertRule)
-		rules.PATCH("/:id", handler.UpdateAlertRule)
-		rules.DELETE("/:id", handler.DeleteAlertRule)
-		rules.POST("/:id/enable", handler.EnableAlertRule)
-		rules.POST("/:id/disable", handler.DisableAlertRule)
+		rules.GET("", alertRead, handler.ListAlertRules)
+		rules.POST("", alertWrite, handler.CreateAlertRule)
+		rules.GET("/:id", alertRead, handler.GetAlertRule)
+		rules.PATCH("/:id", alertWrite, handler.UpdateAlertRule)
+		rules.DELETE("/:id", alertWrite, handler.DeleteAlertRule)
+		rules.POST("/:id/enable", alertWrite, handler.EnableAlertRule)
+		rules.POST("/:id/disable", alertWrite, handler.DisableAlertRule)
 	}
 
 	// Notifications
-	notifications := router.Group("/notifications")
-	// notifications.Use(auth)
+	notifications := router.Group("/v1/notifications")
 	{
-		notifications.GET("", handler.ListNotifications)
-		notifications.GET("/:id", handler.GetNotification)
-		notifications.POST("/:id/acknowledge", handler.AcknowledgeNotification)
-		notifications.POST("/:id/resolve", handler.ResolveNotification)
+		notifications.GET("", notificationRead, handler.ListNotifications)
+		notifications.GET("/:id", notificationRead, handler.GetNotification)
+		notifications.POST("/:id/acknowledge", notificationWrite, handler.AcknowledgeNotification)
+		notifications.POST("/:id/resolve", notificationWrite, handler.ResolveNotification)
 	}
 }
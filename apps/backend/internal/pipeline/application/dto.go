@@ -0,0 +1,46 @@
+package application
+
+import (
+	"github.com/indalyadav56/logify/apps/backend/internal/pipeline/domain"
+)
+
+// PipelineInput defines a pipeline. Saving it stores its processors as a
+// new version.
+type PipelineInput struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Description string `json:"description,omitempty" validate:"max=1000"`
+	// Enabled defaults to true.
+	Enabled *bool `json:"enabled,omitempty"`
+	// Position orders the project's pipelines; lower runs first.
+	Position   int                `json:"position" validate:"min=0,max=1000"`
+	Processors []domain.Processor `json:"processors" validate:"required,min=1"`
+}
+
+// RollbackInput names the version whose processors become the latest.
+type RollbackInput struct {
+	Version int `json:"version" validate:"required,min=1"`
+}
+
+// SimulateInput runs processors on sample logs without storing anything.
+// Without Processors, the project's enabled pipelines run, as the
+// log-processor would run them.
+type SimulateInput struct {
+	Processors []domain.Processor `json:"processors,omitempty"`
+	// Lines are raw log lines, each the message of a sample log.
+	Lines []string `json:"lines,omitempty"`
+	// Logs are structured sample logs.
+	Logs []domain.Entry `json:"logs,omitempty"`
+}
+
+// SimulateOutput is the outcome of a simulation, one result per sample:
+// lines first, then logs.
+type SimulateOutput struct {
+	Results []SimulateResult `json:"results"`
+}
+
+type SimulateResult struct {
+	Input  *domain.Entry `json:"input"`
+	Output *domain.Entry `json:"output"`
+	// Errors are the processors that failed; the others still ran.
+	Errors []string `json:"errors,omitempty"`
+}
@@ -0,0 +1,89 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/pipeline/domain"
+	"github.com/indalyadav56/logify/apps/backend/pkg/ttlcache"
+)
+
+const (
+	// defaultChainCacheTTL bounds how long the log-processor runs a project's
+	// pipelines after they changed: the API runs in another process, so the
+	// cache cannot be told.
+	defaultChainCacheTTL = 30 * time.Second
+
+	// maxChainCacheEntries caps memory; the cache is simply reset when full.
+	maxChainCacheEntries = 10_000
+)
+
+type cachedChain struct {
+	tenantID uuid.UUID
+	chain    domain.Chain
+}
+
+// PipelineRunner applies a project's enabled pipelines to its logs. It is
+// used by the log-processor and caches each project's compiled chain,
+// including empty ones, as most projects define no pipeline.
+type PipelineRunner struct {
+	repo   domain.Repository
+	logger *zap.Logger
+	now    func() time.Time
+	cache  *ttlcache.Cache[uuid.UUID, cachedChain]
+}
+
+func NewPipelineRunner(repo domain.Repository, logger *zap.Logger) *PipelineRunner {
+	return &PipelineRunner{
+		repo:   repo,
+		logger: logger.Named("pipeline_runner"),
+		now:    time.Now,
+		cache:  ttlcache.New[uuid.UUID, cachedChain](defaultChainCacheTTL, maxChainCacheEntries),
+	}
+}
+
+// Run applies the project's pipelines to e. It returns the errors of the
+// processors that failed, which leave e as it was, and an error when the
+// pipelines could not be loaded, in which case e is untouched.
+func (r *PipelineRunner) Run(ctx context.Context, tenantID, projectID uuid.UUID, e *domain.Entry) ([]error, error) {
+	chain, err := r.chain(ctx, tenantID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return chain.Run(e), nil
+}
+
+func (r *PipelineRunner) chain(ctx context.Context, tenantID, projectID uuid.UUID) (domain.Chain, error) {
+	now := r.now()
+	if entry, ok := r.cache.Get(projectID, now); ok && entry.tenantID == tenantID {
+		return entry.chain, nil
+	}
+
+	// Logs of a project that is gone are stored as sent, and cached as such.
+	pipelines, err := r.repo.List(ctx, tenantID, projectID, true)
+	if err != nil && !errors.Is(err, domain.ErrProjectNotFound) {
+		return nil, err
+	}
+	// One pipeline the engine no longer accepts must not stop the others.
+	chain := make(domain.Chain, 0, len(pipelines))
+	for _, p := range pipelines {
+		program, err := domain.Compile(p.Processors)
+		if err != nil {
+			r.logger.Error("skipping pipeline that does not compile",
+				zap.String("project_id", projectID.String()),
+				zap.String("pipeline_id", p.ID.String()),
+				zap.Int("version", p.Version),
+				zap.Error(err),
+			)
+			continue
+		}
+		chain = append(chain, domain.Stage{Name: p.Name, Version: p.Version, Program: program})
+	}
+
+	r.cache.Set(projectID, cachedChain{tenantID: tenantID, chain: chain}, now)
+	return chain, nil
+}
@@ -0,0 +1,253 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/pipeline/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
+)
+
+// simulationStage names ad-hoc processors in simulation errors.
+const simulationStage = "simulation"
+
+type PipelineService interface {
+	List(ctx context.Context, projectID uuid.UUID) ([]*domain.Pipeline, error)
+	// Get returns a pipeline at version, or at its latest when version is 0.
+	Get(ctx context.Context, projectID, id uuid.UUID, version int) (*domain.Pipeline, error)
+	Create(ctx context.Context, projectID uuid.UUID, input PipelineInput) (*domain.Pipeline, error)
+	// Update replaces a pipeline's settings; its processors become a new
+	// version.
+	Update(ctx context.Context, projectID, id uuid.UUID, input PipelineInput) (*domain.Pipeline, error)
+	Delete(ctx context.Context, projectID, id uuid.UUID) error
+	Versions(ctx context.Context, projectID, id uuid.UUID) ([]*domain.Version, error)
+	// Rollback stores the processors of an earlier version as a new version.
+	Rollback(ctx context.Context, projectID, id uuid.UUID, input RollbackInput) (*domain.Pipeline, error)
+	// Simulate shows what pipelines do to sample logs.
+	Simulate(ctx context.Context, projectID uuid.UUID, input SimulateInput) (*SimulateOutput, error)
+}
+
+type pipelineService struct {
+	repo   domain.Repository
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+func NewPipelineService(repo domain.Repository, logger *zap.Logger) PipelineService {
+	return &pipelineService{
+		repo:   repo,
+		logger: logger.Named("pipeline_service"),
+		now:    time.Now,
+	}
+}
+
+func (s *pipelineService) List(ctx context.Context, projectID uuid.UUID) ([]*domain.Pipeline, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pipelines, err := s.repo.List(ctx, tenantID, projectID, false)
+	if err != nil {
+		return nil, err
+	}
+	if pipelines == nil {
+		pipelines = []*domain.Pipeline{}
+	}
+	return pipelines, nil
+}
+
+func (s *pipelineService) Get(ctx context.Context, projectID, id uuid.UUID, version int) (*domain.Pipeline, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.Get(ctx, tenantID, projectID, id, version)
+}
+
+func (s *pipelineService) Create(ctx context.Context, projectID uuid.UUID, input PipelineInput) (*domain.Pipeline, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := domain.Compile(input.Processors); err != nil {
+		return nil, err
+	}
+	p := &domain.Pipeline{TenantID: tenantID, ProjectID: projectID, CreatedBy: userFromContext(ctx)}
+	applyInput(p, input)
+	if err := s.repo.Create(ctx, p); err != nil {
+		return nil, err
+	}
+	s.logger.Info("pipeline created",
+		zap.String("project_id", projectID.String()),
+		zap.String("pipeline_id", p.ID.String()),
+		zap.String("name", p.Name),
+	)
+	return p, nil
+}
+
+func (s *pipelineService) Update(ctx context.Context, projectID, id uuid.UUID, input PipelineInput) (*domain.Pipeline, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := domain.Compile(input.Processors); err != nil {
+		return nil, err
+	}
+	p, err := s.repo.Get(ctx, tenantID, projectID, id, 0)
+	if err != nil {
+		return nil, err
+	}
+	applyInput(p, input)
+	return p, s.save(ctx, p)
+}
+
+func (s *pipelineService) Delete(ctx context.Context, projectID, id uuid.UUID) error {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, tenantID, projectID, id); err != nil {
+		return err
+	}
+	s.logger.Info("pipeline deleted",
+		zap.String("project_id", projectID.String()),
+		zap.String("pipeline_id", id.String()),
+	)
+	return nil
+}
+
+func (s *pipelineService) Versions(ctx context.Context, projectID, id uuid.UUID) ([]*domain.Version, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.Versions(ctx, tenantID, projectID, id)
+}
+
+func (s *pipelineService) Rollback(ctx context.Context, projectID, id uuid.UUID, input RollbackInput) (*domain.Pipeline, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	old, err := s.repo.Get(ctx, tenantID, projectID, id, input.Version)
+	if err != nil {
+		return nil, err
+	}
+	// A version was valid when stored, but the processors may have changed
+	// since; never let a rollback store a pipeline the processor rejects.
+	if _, err := domain.Compile(old.Processors); err != nil {
+		return nil, err
+	}
+	p, err := s.repo.Get(ctx, tenantID, projectID, id, 0)
+	if err != nil {
+		return nil, err
+	}
+	p.Processors = old.Processors
+	return p, s.save(ctx, p)
+}
+
+// save stores p's processors as a new version by the caller.
+func (s *pipelineService) save(ctx context.Context, p *domain.Pipeline) error {
+	p.CreatedBy = userFromContext(ctx)
+	if err := s.repo.Update(ctx, p); err != nil {
+		return err
+	}
+	s.logger.Info("pipeline updated",
+		zap.String("project_id", p.ProjectID.String()),
+		zap.String("pipeline_id", p.ID.String()),
+		zap.Int("version", p.Version),
+	)
+	return nil
+}
+
+func (s *pipelineService) Simulate(ctx context.Context, projectID uuid.UUID, input SimulateInput) (*SimulateOutput, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if n := len(input.Lines) + len(input.Logs); n > domain.MaxSimulationSamples {
+		return nil, fmt.Errorf("%w: %d, at most %d", domain.ErrTooManySamples, n, domain.MaxSimulationSamples)
+	}
+
+	var chain domain.Chain
+	if len(input.Processors) > 0 {
+		program, err := domain.Compile(input.Processors)
+		if err != nil {
+			return nil, err
+		}
+		chain = domain.Chain{{Name: simulationStage, Program: program}}
+	} else {
+		pipelines, err := s.repo.List(ctx, tenantID, projectID, true)
+		if err != nil {
+			return nil, err
+		}
+		if chain, err = compileChain(pipelines); err != nil {
+			return nil, err
+		}
+	}
+
+	now := s.now().UTC()
+	samples := make([]domain.Entry, 0, len(input.Lines)+len(input.Logs))
+	for _, line := range input.Lines {
+		samples = append(samples, domain.Entry{Message: line})
+	}
+	samples = append(samples, input.Logs...)
+
+	out := &SimulateOutput{Results: make([]SimulateResult, 0, len(samples))}
+	for i := range samples {
+		in := &samples[i]
+		if in.Timestamp.IsZero() {
+			in.Timestamp = now
+		}
+		result := SimulateResult{Input: in, Output: in.Clone()}
+		for _, err := range chain.Run(result.Output) {
+			result.Errors = append(result.Errors, err.Error())
+		}
+		out.Results = append(out.Results, result)
+	}
+	return out, nil
+}
+
+// compileChain compiles a project's enabled pipelines, in the order they run.
+func compileChain(pipelines []*domain.Pipeline) (domain.Chain, error) {
+	chain := make(domain.Chain, 0, len(pipelines))
+	for _, p := range pipelines {
+		program, err := domain.Compile(p.Processors)
+		if err != nil {
+			return nil, fmt.Errorf("pipeline %q version %d: %w", p.Name, p.Version, err)
+		}
+		chain = append(chain, domain.Stage{Name: p.Name, Version: p.Version, Program: program})
+	}
+	return chain, nil
+}
+
+func applyInput(p *domain.Pipeline, input PipelineInput) {
+	p.Name = input.Name
+	p.Description = input.Description
+	p.Enabled = input.Enabled == nil || *input.Enabled
+	p.Position = input.Position
+	p.Processors = input.Processors
+}
+
+// tenantFromContext returns the caller's tenant as set by AuthMiddleware. The
+// tenant is never taken from request input.
+func tenantFromContext(ctx context.Context) (uuid.UUID, error) {
+	tenantID, ok := middleware.GetTenantUUIDFromContext(ctx)
+	if !ok {
+		return uuid.Nil, domain.ErrTenantRequired
+	}
+	return tenantID, nil
+}
+
+// userFromContext returns the caller, or nil for callers that are not users,
+// such as service accounts.
+func userFromContext(ctx context.Context) *uuid.UUID {
+	if userID, ok := middleware.UserUUIDFromContext(ctx); ok {
+		return &userID
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+package application
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/pipeline/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
+)
+
+// memPipelines mirrors the Postgres repository: every version is kept and
+// projects of other tenants are not found.
+type memPipelines struct {
+	projects  map[uuid.UUID]uuid.UUID // project -> tenant
+	pipelines map[uuid.UUID]domain.Pipeline
+	versions  map[uuid.UUID][]domain.Version
+	lists     int
+}
+
+func (r *memPipelines) List(_ context.Context, tenantID, projectID uuid.UUID, enabledOnly bool) ([]*domain.Pipeline, error) {
+	r.lists++
+	if r.projects[projectID] != tenantID {
+		return nil, domain.ErrProjectNotFound
+	}
+	var out []*domain.Pipeline
+	for _, p := range r.pipelines {
+		if p.ProjectID == projectID && (p.Enabled || !enabledOnly) {
+			out = append(out, &p)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Position < out[j].Position })
+	return out, nil
+}
+
+func (r *memPipelines) Get(_ context.Context, tenantID, projectID, id uuid.UUID, version int) (*domain.Pipeline, error) {
+	if r.projects[projectID] != tenantID {
+		return nil, domain.ErrProjectNotFound
+	}
+	p, ok := r.pipelines[id]
+	if !ok || p.ProjectID != projectID {
+		return nil, domain.ErrPipelineNotFound
+	}
+	if version != 0 {
+		if version > len(r.versions[id]) {
+			return nil, domain.ErrVersionNotFound
+		}
+		p.Version, p.Processors = version, r.versions[id][version-1].Processors
+	}
+	return &p, nil
+}
+
+func (r *memPipelines) Create(_ context.Context, p *domain.Pipeline) error {
+	if r.projects[p.ProjectID] != p.TenantID {
+		return domain.ErrProjectNotFound
+	}
+	for _, other := range r.pipelines {
+		if other.ProjectID == p.ProjectID && other.Name == p.Name {
+			return domain.ErrNameTaken
+		}
+	}
+	p.ID, p.Version = uuid.New(), 0
+	return r.save(p)
+}
+
+func (r *memPipelines) Update(_ context.Context, p *domain.Pipeline) error {
+	if _, ok := r.pipelines[p.ID]; !ok {
+		return domain.ErrPipelineNotFound
+	}
+	p.Version = len(r.versions[p.ID])
+	return r.save(p)
+}
+
+func (r *memPipelines) save(p *domain.Pipeline) error {
+	p.Version++
+	r.pipelines[p.ID] = *p
+	r.versions[p.ID] = append(r.versions[p.ID], domain.Version{
+		PipelineID: p.ID, Version: p.Version, Processors: p.Processors, CreatedBy: p.CreatedBy,
+	})
+	return nil
+}
+
+func (r *memPipelines) Delete(_ context.Context, _, _, id uuid.UUID) error {
+	delete(r.pipelines, id)
+	return nil
+}
+
+func (r *memPipelines) Versions(_ context.Context, _, _, id uuid.UUID) ([]*domain.Version, error) {
+	var out []*domain.Version
+	for i := len(r.versions[id]) - 1; i >= 0; i-- {
+		out = append(out, &r.versions[id][i])
+	}
+	return out, nil
+}
+
+func newTestPipelineService(t *testing.T) (*pipelineService, *memPipelines, context.Context, uuid.UUID, uuid.UUID) {
+	t.Helper()
+	tenantID, projectID := uuid.New(), uuid.New()
+	repo := &memPipelines{
+		projects:  map[uuid.UUID]uuid.UUID{projectID: tenantID},
+		pipelines: map[uuid.UUID]domain.Pipeline{},
+		versions:  map[uuid.UUID][]domain.Version{},
+	}
+	svc := NewPipelineService(repo, zap.NewNop()).(*pipelineService)
+	ctx := middleware.WithClaims(context.Background(), &middleware.Claims{
+		UserID:   uuid.NewString(),
+		TenantID: tenantID.String(),
+	})
+	return svc, repo, ctx, tenantID, projectID
+}
+
+var setEnv = []domain.Processor{{Type: domain.ProcessorSet, Values: map[string]string{"environment": "prod"}}}
+
+func TestCreateValidatesProcessors(t *testing.T) {
+	svc, repo, ctx, _, projectID := newTestPipelineService(t)
+
+	_, err := svc.Create(ctx, projectID, PipelineInput{Name: "bad", Processors: []domain.Processor{{Type: "nope"}}})
+	assert.ErrorIs(t, err, domain.ErrInvalidPipeline)
+	_, err = svc.Create(context.Background(), projectID, PipelineInput{Name: "x", Processors: setEnv})
+	assert.ErrorIs(t, err, domain.ErrTenantRequired)
+	assert.Empty(t, repo.pipelines)
+
+	p, err := svc.Create(ctx, projectID, PipelineInput{Name: "env", Processors: setEnv})
+	require.NoError(t, err)
+	assert.True(t, p.Enabled)
+	assert.Equal(t, 1, p.Version)
+	require.NotNil(t, p.CreatedBy)
+}
+
+func TestUpdateAndRollbackAddVersions(t *testing.T) {
+	svc, _, ctx, _, projectID := newTestPipelineService(t)
+	p, err := svc.Create(ctx, projectID, PipelineInput{Name: "env", Processors: setEnv})
+	require.NoError(t, err)
+
+	drop := []domain.Processor{{Type: domain.ProcessorDrop, Fields: []string{"user_id"}}}
+	disabled := false
+	p, err = svc.Update(ctx, projectID, p.ID, PipelineInput{Name: "env", Enabled: &disabled, Processors: drop})
+	require.NoError(t, err)
+	assert.Equal(t, 2, p.Version)
+	assert.False(t, p.Enabled)
+
+	p, err = svc.Rollback(ctx, projectID, p.ID, RollbackInput{Version: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 3, p.Version)
+	assert.Equal(t, setEnv, p.Processors)
+	assert.False(t, p.Enabled, "rollback restores processors, not settings")
+
+	versions, err := svc.Versions(ctx, projectID, p.ID)
+	require.NoError(t, err)
+	require.Len(t, versions, 3)
+	assert.Equal(t, []int{3, 2, 1}, []int{versions[0].Version, versions[1].Version, versions[2].Version})
+
+	_, err = svc.Rollback(ctx, projectID, p.ID, RollbackInput{Version: 9})
+	assert.ErrorIs(t, err, domain.ErrVersionNotFound)
+	_, err = svc.Update(ctx, uuid.New(), p.ID, PipelineInput{Name: "env", Processors: setEnv})
+	assert.ErrorIs(t, err, domain.ErrProjectNotFound)
+}
+
+func TestSimulate(t *testing.T) {
+	svc, _, ctx, _, projectID := newTestPipelineService(t)
+	svc.now = func() time.Time { return time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC) }
+
+	_, err := svc.Create(ctx, projectID, PipelineInput{Name: "parse", Processors: []domain.Processor{
+		{Type: domain.ProcessorKV},
+		{Type: domain.ProcessorLevel, Field: "lvl"},
+	}})
+	require.NoError(t, err)
+	_, err = svc.Create(ctx, projectID, PipelineInput{Name: "env", Position: 1, Processors: setEnv})
+	require.NoError(t, err)
+
+	out, err := svc.Simulate(ctx, projectID, SimulateInput{
+		Lines: []string{"lvl=warning user=7"},
+		Logs:  []domain.Entry{{Message: "lvl=debug", Environment: "staging"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, out.Results, 2)
+
+	first := out.Results[0]
+	assert.Equal(t, "lvl=warning user=7", first.Input.Message)
+	assert.Empty(t, first.Input.Attributes, "the input is shown as sent")
+	assert.Equal(t, "WARN", first.Output.Level)
+	assert.Equal(t, "7", first.Output.Attributes["user"])
+	assert.Equal(t, "prod", first.Output.Environment)
+	assert.Equal(t, svc.now(), first.Output.Timestamp)
+	assert.Equal(t, "staging", out.Results[1].Output.Environment)
+
+	// Ad-hoc processors replace the project's pipelines.
+	out, err = svc.Simulate(ctx, projectID, SimulateInput{
+		Processors: []domain.Processor{{Type: domain.ProcessorJSON}},
+		Lines:      []string{"{broken"},
+	})
+	require.NoError(t, err)
+	require.Len(t, out.Results[0].Errors, 1)
+	assert.Contains(t, out.Results[0].Errors[0], `pipeline "simulation": processors[0] (json)`)
+
+	_, err = svc.Simulate(ctx, projectID, SimulateInput{Lines: make([]string, domain.MaxSimulationSamples+1)})
+	assert.ErrorIs(t, err, domain.ErrTooManySamples)
+}
+
+func TestRunnerCachesChains(t *testing.T) {
+	svc, repo, ctx, tenantID, projectID := newTestPipelineService(t)
+	runner := NewPipelineRunner(repo, zap.NewNop())
+	now := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	runner.now = func() time.Time { return now }
+
+	e := &domain.Entry{}
+	_, err := runner.Run(context.Background(), tenantID, projectID, e)
+	require.NoError(t, err)
+	assert.Empty(t, e.Environment)
+
+	// Changes apply once the cached, empty chain expires.
+	_, err = svc.Create(ctx, projectID, PipelineInput{Name: "env", Processors: setEnv})
+	require.NoError(t, err)
+	_, err = runner.Run(context.Background(), tenantID, projectID, e)
+	require.NoError(t, err)
+	assert.Empty(t, e.Environment)
+	assert.Equal(t, 1, repo.lists)
+
+	now = now.Add(defaultChainCacheTTL)
+	_, err = runner.Run(context.Background(), tenantID, projectID, e)
+	require.NoError(t, err)
+	assert.Equal(t, "prod", e.Environment)
+
+	// Another tenant's project runs nothing.
+	other := &domain.Entry{}
+	_, err = runner.Run(context.Background(), uuid.New(), projectID, other)
+	require.NoError(t, err)
+	assert.Empty(t, other.Environment)
+}
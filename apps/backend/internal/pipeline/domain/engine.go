@@ -0,0 +1,603 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	retentionDomain "github.com/indalyadav56/logify/apps/backend/internal/retention/domain"
+)
+
+const (
+	// maxJSONAttributes bounds the attributes one json processor writes.
+	maxJSONAttributes = 256
+	// maxJSONDepth bounds how deeply json flattens nested objects; deeper
+	// values are kept as JSON text.
+	maxJSONDepth = 8
+)
+
+// defaultTimestampFormats are tried when a timestamp processor names none.
+var defaultTimestampFormats = []string{
+	"rfc3339",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"unix",
+}
+
+// numericLevels are the numeric levels of pino and bunyan.
+var numericLevels = map[string]string{
+	"10": "trace", "20": "debug", "30": "info", "40": "warn", "50": "error", "60": "fatal",
+}
+
+// Program is a compiled pipeline. It is safe for concurrent use.
+type Program struct {
+	steps []step
+}
+
+// Stage is one pipeline of a project's chain.
+type Stage struct {
+	Name    string
+	Version int
+	Program *Program
+}
+
+// Chain is a project's enabled pipelines, in the order they run.
+type Chain []Stage
+
+// Run applies every pipeline of the chain to e. Processors that fail leave e
+// as it was and the next ones still run; their errors are returned.
+func (c Chain) Run(e *Entry) []error {
+	var errs []error
+	for _, stage := range c {
+		for _, err := range stage.Program.Run(e) {
+			errs = append(errs, fmt.Errorf("pipeline %q: %w", stage.Name, err))
+		}
+	}
+	return errs
+}
+
+// Run applies the program to e and returns the errors of the processors
+// that failed.
+func (p *Program) Run(e *Entry) []error {
+	var errs []error
+	for i := range p.steps {
+		errs = p.steps[i].run(e, errs)
+	}
+	return errs
+}
+
+type step struct {
+	path   string
+	cond   *condition
+	apply  func(e *Entry) error
+	routes []route
+}
+
+type route struct {
+	cond  *condition
+	steps []step
+}
+
+func (s *step) run(e *Entry, errs []error) []error {
+	if s.cond != nil && !s.cond.match(e) {
+		return errs
+	}
+	if s.routes != nil {
+		for _, r := range s.routes {
+			if r.cond != nil && !r.cond.match(e) {
+				continue
+			}
+			for i := range r.steps {
+				errs = r.steps[i].run(e, errs)
+			}
+			break
+		}
+		return errs
+	}
+	if err := s.apply(e); err != nil {
+		errs = append(errs, fmt.Errorf("%s: %w", s.path, err))
+	}
+	return errs
+}
+
+type condition struct {
+	Condition
+	re *regexp.Regexp
+}
+
+func (c *condition) match(e *Entry) bool {
+	v, ok := e.Get(c.Field)
+	switch c.Op {
+	case OpExists:
+		return ok
+	case OpNotExists:
+		return !ok
+	case OpEquals:
+		return v == c.Value
+	case OpNotEquals:
+		return v != c.Value
+	case OpContains:
+		return ok && strings.Contains(v, c.Value)
+	case OpMatches:
+		return ok && c.re.MatchString(v)
+	}
+	return false
+}
+
+// Compile validates processors and prepares them to run. Every definition
+// error wraps ErrInvalidPipeline and names the processor at fault.
+func Compile(processors []Processor) (*Program, error) {
+	c := &compiler{}
+	steps, err := c.compile(processors, "processors", 0)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPipeline, err)
+	}
+	if c.count > MaxProcessors {
+		return nil, fmt.Errorf("%w: more than %d processors", ErrInvalidPipeline, MaxProcessors)
+	}
+	return &Program{steps: steps}, nil
+}
+
+type compiler struct {
+	count int
+}
+
+func (c *compiler) compile(processors []Processor, path string, depth int) ([]step, error) {
+	steps := make([]step, 0, len(processors))
+	for i, p := range processors {
+		c.count++
+		s, err := c.compileOne(p, fmt.Sprintf("%s[%d]", path, i), depth)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, s)
+	}
+	return steps, nil
+}
+
+func (c *compiler) compileOne(p Processor, path string, depth int) (step, error) {
+	s := step{path: fmt.Sprintf("%s (%s)", path, p.Type)}
+	fail := func(format string, args ...any) (step, error) {
+		return step{}, fmt.Errorf("%s: %s", s.path, fmt.Sprintf(format, args...))
+	}
+
+	if p.If != nil {
+		cond, err := compileCondition(*p.If)
+		if err != nil {
+			return fail("if: %v", err)
+		}
+		s.cond = cond
+	}
+	if len(p.Pattern) > MaxPatternLength {
+		return fail("pattern is longer than %d bytes", MaxPatternLength)
+	}
+
+	var err error
+	switch p.Type {
+	case ProcessorRegex:
+		s.apply, err = compileRegex(p)
+	case ProcessorGrok:
+		s.apply, err = compileGrokProcessor(p)
+	case ProcessorJSON:
+		s.apply, err = compileJSON(p)
+	case ProcessorKV:
+		s.apply, err = compileKV(p)
+	case ProcessorLevel:
+		s.apply, err = compileLevel(p)
+	case ProcessorTimestamp:
+		s.apply, err = compileTimestamp(p)
+	case ProcessorRename:
+		s.apply, err = compileRename(p)
+	case ProcessorDrop:
+		s.apply, err = compileDrop(p)
+	case ProcessorSet:
+		s.apply, err = compileSet(p)
+	case ProcessorRoute:
+		// Errors of nested processors carry their own path.
+		if s.routes, err = c.compileRoutes(p, path, depth); err != nil {
+			return step{}, err
+		}
+	default:
+		return fail("unknown processor type")
+	}
+	if err != nil {
+		return fail("%v", err)
+	}
+	return s, nil
+}
+
+func (c *compiler) compileRoutes(p Processor, path string, depth int) ([]route, error) {
+	if depth >= MaxRouteDepth {
+		return nil, fmt.Errorf("%s: routes nest deeper than %d", path, MaxRouteDepth)
+	}
+	if len(p.Routes) == 0 {
+		return nil, fmt.Errorf("%s: routes are required", path)
+	}
+	routes := make([]route, 0, len(p.Routes))
+	for i, r := range p.Routes {
+		var cond *condition
+		if r.If != nil {
+			var err error
+			if cond, err = compileCondition(*r.If); err != nil {
+				return nil, fmt.Errorf("%s.routes[%d].if: %v", path, i, err)
+			}
+		} else if i != len(p.Routes)-1 {
+			return nil, fmt.Errorf("%s.routes[%d] has no condition, so the routes after it never run", path, i)
+		}
+		if len(r.Processors) == 0 {
+			return nil, fmt.Errorf("%s.routes[%d] has no processors", path, i)
+		}
+		steps, err := c.compile(r.Processors, fmt.Sprintf("%s.routes[%d].processors", path, i), depth+1)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, route{cond: cond, steps: steps})
+	}
+	return routes, nil
+}
+
+func compileCondition(cond Condition) (*condition, error) {
+	if !ValidField(cond.Field) {
+		return nil, fmt.Errorf("invalid field %q", cond.Field)
+	}
+	c := &condition{Condition: cond}
+	switch cond.Op {
+	case OpEquals, OpNotEquals, OpExists, OpNotExists:
+	case OpContains:
+		if cond.Value == "" {
+			return nil, errors.New("contains requires a value")
+		}
+	case OpMatches:
+		if len(cond.Value) > MaxPatternLength {
+			return nil, fmt.Errorf("pattern is longer than %d bytes", MaxPatternLength)
+		}
+		re, err := regexp.Compile(cond.Value)
+		if err != nil {
+			return nil, err
+		}
+		c.re = re
+	default:
+		return nil, fmt.Errorf("unknown op %q", cond.Op)
+	}
+	return c, nil
+}
+
+// sourceField returns p.Field, or def when unset, once validated.
+func sourceField(p Processor, def string) (string, error) {
+	field := p.Field
+	if field == "" {
+		field = def
+	}
+	if !ValidField(field) {
+		return "", fmt.Errorf("invalid field %q", field)
+	}
+	return field, nil
+}
+
+func compileRegex(p Processor) (func(*Entry) error, error) {
+	field, err := sourceField(p, "message")
+	if err != nil {
+		return nil, err
+	}
+	re, err := regexp.Compile(p.Pattern)
+	if err != nil {
+		return nil, err
+	}
+	fields := re.SubexpNames()
+	named := false
+	for _, name := range fields {
+		if name == "" {
+			continue
+		}
+		if !ValidField(name) {
+			return nil, fmt.Errorf("group %q does not name a field", name)
+		}
+		named = true
+	}
+	if !named {
+		return nil, errors.New("pattern has no named group, e.g. (?P<status>\\d+)")
+	}
+	return extractor(field, re, fields), nil
+}
+
+func compileGrokProcessor(p Processor) (func(*Entry) error, error) {
+	field, err := sourceField(p, "message")
+	if err != nil {
+		return nil, err
+	}
+	re, fields, err := compileGrok(p.Pattern, p.PatternDefinitions)
+	if err != nil {
+		return nil, err
+	}
+	return extractor(field, re, fields), nil
+}
+
+// extractor writes the groups of re that matched in field to fields[i].
+// A field that does not match is left alone.
+func extractor(field string, re *regexp.Regexp, fields []string) func(*Entry) error {
+	return func(e *Entry) error {
+		v, ok := e.Get(field)
+		if !ok {
+			return nil
+		}
+		m := re.FindStringSubmatchIndex(v)
+		if m == nil {
+			return nil
+		}
+		for i, target := range fields {
+			if target == "" || m[2*i] < 0 {
+				continue
+			}
+			e.Set(target, v[m[2*i]:m[2*i+1]])
+		}
+		return nil
+	}
+}
+
+func compileJSON(p Processor) (func(*Entry) error, error) {
+	field, err := sourceField(p, "message")
+	if err != nil {
+		return nil, err
+	}
+	return func(e *Entry) error {
+		v, ok := e.Get(field)
+		v = strings.TrimSpace(v)
+		if !ok || !strings.HasPrefix(v, "{") {
+			return nil
+		}
+		dec := json.NewDecoder(strings.NewReader(v))
+		dec.UseNumber()
+		var obj map[string]any
+		if err := dec.Decode(&obj); err != nil {
+			return fmt.Errorf("invalid JSON: %v", err)
+		}
+		flat := map[string]string{}
+		flatten(flat, p.Prefix, obj, 0)
+		if len(flat) > maxJSONAttributes {
+			return fmt.Errorf("object has more than %d fields", maxJSONAttributes)
+		}
+		for k, val := range flat {
+			e.Set(attributesPrefix+k, val)
+		}
+		return nil
+	}, nil
+}
+
+// flatten writes the leaves of obj to out, nested keys joined by dots.
+func flatten(out map[string]string, prefix string, obj map[string]any, depth int) {
+	for k, v := range obj {
+		key := prefix + k
+		switch v := v.(type) {
+		case map[string]any:
+			if depth+1 < maxJSONDepth {
+				flatten(out, key+".", v, depth+1)
+				continue
+			}
+			b, _ := json.Marshal(v)
+			out[key] = string(b)
+		case string:
+			out[key] = v
+		case json.Number:
+			out[key] = v.String()
+		case bool:
+			out[key] = strconv.FormatBool(v)
+		case nil:
+			out[key] = ""
+		default:
+			b, _ := json.Marshal(v)
+			out[key] = string(b)
+		}
+	}
+}
+
+func compileKV(p Processor) (func(*Entry) error, error) {
+	field, err := sourceField(p, "message")
+	if err != nil {
+		return nil, err
+	}
+	fieldSplit, valueSplit := p.FieldSplit, p.ValueSplit
+	if fieldSplit == "" {
+		fieldSplit = " "
+	}
+	if valueSplit == "" {
+		valueSplit = "="
+	}
+	if len([]rune(fieldSplit)) != 1 || len([]rune(valueSplit)) != 1 || fieldSplit == valueSplit {
+		return nil, errors.New("field_split and value_split must be two different single characters")
+	}
+	// Written as \x{..} escapes, any character is literal in a class.
+	fs := fmt.Sprintf(`\x{%x}`, []rune(fieldSplit)[0])
+	if fieldSplit == " " {
+		fs = `\s`
+	}
+	vs := fmt.Sprintf(`\x{%x}`, []rune(valueSplit)[0])
+	re := regexp.MustCompile(`([^` + fs + vs + `"]+)` + vs + `("(?:[^"\\]|\\.)*"|[^` + fs + `]*)`)
+
+	return func(e *Entry) error {
+		v, ok := e.Get(field)
+		if !ok {
+			return nil
+		}
+		for _, m := range re.FindAllStringSubmatch(v, maxJSONAttributes) {
+			value := m[2]
+			if strings.HasPrefix(value, `"`) {
+				if u, err := strconv.Unquote(value); err == nil {
+					value = u
+				} else {
+					value = strings.Trim(value, `"`)
+				}
+			}
+			e.Set(attributesPrefix+p.Prefix+m[1], value)
+		}
+		return nil
+	}, nil
+}
+
+func compileLevel(p Processor) (func(*Entry) error, error) {
+	field, err := sourceField(p, "level")
+	if err != nil {
+		return nil, err
+	}
+	mapping := make(map[string]string, len(p.Mapping))
+	for from, to := range p.Mapping {
+		canonical := retentionDomain.CanonicalLevel(to)
+		if canonical == "" {
+			return nil, fmt.Errorf("mapping %q: unknown level %q", from, to)
+		}
+		mapping[strings.ToLower(strings.TrimSpace(from))] = canonical
+	}
+
+	return func(e *Entry) error {
+		v, ok := e.Get(field)
+		if !ok {
+			return nil
+		}
+		v = strings.ToLower(strings.TrimSpace(v))
+		canonical, ok := mapping[v]
+		if !ok {
+			canonical = retentionDomain.CanonicalLevel(v)
+		}
+		if canonical == "" {
+			canonical = numericLevels[v]
+		}
+		if canonical != "" {
+			e.Level = strings.ToUpper(canonical)
+		}
+		return nil
+	}, nil
+}
+
+func compileTimestamp(p Processor) (func(*Entry) error, error) {
+	if p.Field == "" {
+		return nil, errors.New("field is required")
+	}
+	field, err := sourceField(p, "")
+	if err != nil {
+		return nil, err
+	}
+	loc := time.UTC
+	if p.Timezone != "" {
+		if loc, err = time.LoadLocation(p.Timezone); err != nil {
+			return nil, fmt.Errorf("timezone: %v", err)
+		}
+	}
+	formats := p.Formats
+	if len(formats) == 0 {
+		formats = defaultTimestampFormats
+	}
+
+	return func(e *Entry) error {
+		v, ok := e.Get(field)
+		v = strings.TrimSpace(v)
+		if !ok || v == "" {
+			return nil
+		}
+		for _, format := range formats {
+			if t, ok := parseTime(v, format, loc); ok {
+				e.Timestamp = t.UTC()
+				return nil
+			}
+		}
+		return fmt.Errorf("%q matches none of the formats", v)
+	}, nil
+}
+
+func parseTime(v, format string, loc *time.Location) (time.Time, bool) {
+	switch format {
+	case "rfc3339":
+		t, err := time.Parse(time.RFC3339Nano, v)
+		return t, err == nil
+	case "unix":
+		secs, frac, _ := strings.Cut(v, ".")
+		s, err := strconv.ParseInt(secs, 10, 64)
+		if err != nil || len(frac) > 9 {
+			return time.Time{}, false
+		}
+		var ns int64
+		if frac != "" {
+			if ns, err = strconv.ParseInt((frac + "000000000")[:9], 10, 64); err != nil {
+				return time.Time{}, false
+			}
+		}
+		return time.Unix(s, ns), true
+	case "unix_ms", "unix_us", "unix_ns":
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		switch format {
+		case "unix_ms":
+			return time.UnixMilli(n), true
+		case "unix_us":
+			return time.UnixMicro(n), true
+		}
+		return time.Unix(0, n), true
+	}
+	t, err := time.ParseInLocation(format, v, loc)
+	return t, err == nil
+}
+
+func compileRename(p Processor) (func(*Entry) error, error) {
+	if !ValidField(p.From) || !ValidField(p.To) {
+		return nil, fmt.Errorf("from and to must be fields, got %q and %q", p.From, p.To)
+	}
+	if p.From == p.To {
+		return nil, errors.New("from and to are the same field")
+	}
+	return func(e *Entry) error {
+		v, ok := e.Get(p.From)
+		if !ok {
+			return nil
+		}
+		e.Set(p.To, v)
+		e.Delete(p.From)
+		return nil
+	}, nil
+}
+
+func compileDrop(p Processor) (func(*Entry) error, error) {
+	if len(p.Fields) == 0 {
+		return nil, errors.New("fields are required")
+	}
+	for _, f := range p.Fields {
+		if !ValidField(f) {
+			return nil, fmt.Errorf("invalid field %q", f)
+		}
+	}
+	return func(e *Entry) error {
+		for _, f := range p.Fields {
+			e.Delete(f)
+		}
+		return nil
+	}, nil
+}
+
+func compileSet(p Processor) (func(*Entry) error, error) {
+	if len(p.Values) == 0 {
+		return nil, errors.New("values are required")
+	}
+	keys := make([]string, 0, len(p.Values))
+	for k := range p.Values {
+		if !ValidField(k) {
+			return nil, fmt.Errorf("invalid field %q", k)
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return func(e *Entry) error {
+		for _, k := range keys {
+			if !p.Override {
+				if _, ok := e.Get(k); ok {
+					continue
+				}
+			}
+			e.Set(k, p.Values[k])
+		}
+		return nil
+	}, nil
+}
@@ -0,0 +1,175 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func run(t *testing.T, processors []Processor, e *Entry) []error {
+	t.Helper()
+	program, err := Compile(processors)
+	require.NoError(t, err)
+	return program.Run(e)
+}
+
+func TestGrokExtractsFields(t *testing.T) {
+	e := &Entry{Message: `10.1.2.3 - - [19/Oct/2026:10:00:00 +0000] "GET /api/users?id=7 HTTP/1.1" 404`}
+	errs := run(t, []Processor{{
+		Type:    ProcessorGrok,
+		Pattern: `%{IP:client_ip} - - \[%{HTTPDATE:tags.time}\] "%{HTTPMETHOD:method} %{URIPATHPARAM:path} HTTP/%{NUMBER}" %{INT:status:int}`,
+	}}, e)
+
+	assert.Empty(t, errs)
+	assert.Equal(t, map[string]string{
+		"client_ip": "10.1.2.3",
+		"method":    "GET",
+		"path":      "/api/users?id=7",
+		"status":    "404",
+	}, e.Attributes)
+	assert.Equal(t, "19/Oct/2026:10:00:00 +0000", e.Tags["time"])
+}
+
+func TestJSONAndKV(t *testing.T) {
+	e := &Entry{Message: `{"user":{"id":42,"admin":true},"msg":"login"}`, Attributes: map[string]string{
+		"raw": `path=/x status=200 note="two words"`,
+	}}
+	errs := run(t, []Processor{
+		{Type: ProcessorJSON},
+		{Type: ProcessorKV, Field: "raw", Prefix: "kv."},
+		{Type: ProcessorRename, From: "msg", To: "message"},
+	}, e)
+
+	assert.Empty(t, errs)
+	assert.Equal(t, "login", e.Message)
+	assert.Equal(t, "42", e.Attributes["user.id"])
+	assert.Equal(t, "true", e.Attributes["user.admin"])
+	assert.Equal(t, "/x", e.Attributes["kv.path"])
+	assert.Equal(t, "200", e.Attributes["kv.status"])
+	assert.Equal(t, "two words", e.Attributes["kv.note"])
+	assert.NotContains(t, e.Attributes, "msg")
+}
+
+func TestLevelAndTimestamp(t *testing.T) {
+	e := &Entry{Level: "warning", Attributes: map[string]string{"ts": "2026-10-19 12:30:00", "severity": "50"}}
+	errs := run(t, []Processor{
+		{Type: ProcessorLevel},
+		{Type: ProcessorTimestamp, Field: "ts", Formats: []string{"2006-01-02 15:04:05"}, Timezone: "Asia/Kolkata"},
+	}, e)
+	assert.Empty(t, errs)
+	assert.Equal(t, "WARN", e.Level)
+	assert.Equal(t, time.Date(2026, 10, 19, 7, 0, 0, 0, time.UTC), e.Timestamp)
+
+	errs = run(t, []Processor{{Type: ProcessorLevel, Field: "severity"}}, e)
+	assert.Empty(t, errs)
+	assert.Equal(t, "ERROR", e.Level)
+
+	crit := &Entry{Level: "CRIT"}
+	errs = run(t, []Processor{{Type: ProcessorLevel, Mapping: map[string]string{"crit": "fatal"}}}, crit)
+	assert.Empty(t, errs)
+	assert.Equal(t, "FATAL", crit.Level)
+}
+
+func TestFailingProcessorLeavesEntryAndOthersRun(t *testing.T) {
+	e := &Entry{Message: `{not json`, Attributes: map[string]string{"ts": "yesterday"}}
+	errs := run(t, []Processor{
+		{Type: ProcessorJSON},
+		{Type: ProcessorTimestamp, Field: "ts"},
+		{Type: ProcessorSet, Values: map[string]string{"env": "prod", "environment": "staging"}},
+	}, e)
+
+	require.Len(t, errs, 2)
+	assert.Contains(t, errs[0].Error(), "processors[0] (json)")
+	assert.Contains(t, errs[1].Error(), "processors[1] (timestamp)")
+	assert.True(t, e.Timestamp.IsZero())
+	assert.Equal(t, "prod", e.Attributes["env"])
+	assert.Equal(t, "staging", e.Environment)
+}
+
+func TestRoute(t *testing.T) {
+	processors := []Processor{{
+		Type: ProcessorRoute,
+		Routes: []Route{
+			{
+				If:         &Condition{Field: "service", Op: OpEquals, Value: "nginx"},
+				Processors: []Processor{{Type: ProcessorRegex, Pattern: `^(?P<client_ip>\S+) `}},
+			},
+			{
+				If:         &Condition{Field: "message", Op: OpMatches, Value: `^\{`},
+				Processors: []Processor{{Type: ProcessorJSON}},
+			},
+			{
+				Processors: []Processor{{Type: ProcessorSet, Values: map[string]string{"tags.parsed": "false"}}},
+			},
+		},
+	}}
+
+	nginx := &Entry{Service: "nginx", Message: `1.2.3.4 GET /`}
+	structured := &Entry{Message: `{"a":"b"}`}
+	other := &Entry{Message: "plain"}
+	for _, e := range []*Entry{nginx, structured, other} {
+		assert.Empty(t, run(t, processors, e))
+	}
+	assert.Equal(t, map[string]string{"client_ip": "1.2.3.4"}, nginx.Attributes)
+	assert.Equal(t, map[string]string{"a": "b"}, structured.Attributes)
+	assert.Nil(t, structured.Tags)
+	assert.Equal(t, map[string]string{"parsed": "false"}, other.Tags)
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		processors []Processor
+		want       string
+	}{
+		{"unknown type", []Processor{{Type: "split"}}, "processors[0] (split): unknown processor type"},
+		{"bad regex", []Processor{{Type: ProcessorRegex, Pattern: `(?P<x>[`}}, "processors[0] (regex)"},
+		{"regex without groups", []Processor{{Type: ProcessorRegex, Pattern: `\d+`}}, "no named group"},
+		{"unknown grok pattern", []Processor{{Type: ProcessorGrok, Pattern: `%{NOPE:x}`}}, `unknown grok pattern "NOPE"`},
+		{"grok cycle", []Processor{{Type: ProcessorGrok, Pattern: `%{A:x}`, PatternDefinitions: map[string]string{"A": "%{A}"}}}, "cycle"},
+		{"timestamp field", []Processor{{Type: ProcessorTimestamp}}, "field is required"},
+		{"bad timezone", []Processor{{Type: ProcessorTimestamp, Field: "ts", Timezone: "Mars/Olympus"}}, "timezone"},
+		{"bad level mapping", []Processor{{Type: ProcessorLevel, Mapping: map[string]string{"x": "loud"}}}, `unknown level "loud"`},
+		{"rename timestamp", []Processor{{Type: ProcessorRename, From: "timestamp", To: "ts"}}, "from and to"},
+		{"kv same splits", []Processor{{Type: ProcessorKV, FieldSplit: ",", ValueSplit: ","}}, "field_split"},
+		{"bad condition", []Processor{{Type: ProcessorDrop, Fields: []string{"x"}, If: &Condition{Field: "x", Op: "like"}}}, `if: unknown op "like"`},
+		{
+			"unconditional route not last",
+			[]Processor{{Type: ProcessorRoute, Routes: []Route{
+				{Processors: []Processor{{Type: ProcessorJSON}}},
+				{If: &Condition{Field: "x", Op: OpExists}, Processors: []Processor{{Type: ProcessorJSON}}},
+			}}},
+			"processors[0].routes[0] has no condition",
+		},
+		{
+			"nested error path",
+			[]Processor{{Type: ProcessorJSON}, {Type: ProcessorRoute, Routes: []Route{
+				{Processors: []Processor{{Type: ProcessorSet}}},
+			}}},
+			"processors[1].routes[0].processors[0] (set): values are required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.processors)
+			require.ErrorIs(t, err, ErrInvalidPipeline)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+
+	deep := []Processor{{Type: ProcessorJSON}}
+	for range MaxRouteDepth + 1 {
+		deep = []Processor{{Type: ProcessorRoute, Routes: []Route{{Processors: deep}}}}
+	}
+	_, err := Compile(deep)
+	assert.ErrorContains(t, err, "routes nest deeper")
+
+	many := make([]Processor, MaxProcessors+1)
+	for i := range many {
+		many[i] = Processor{Type: ProcessorJSON}
+	}
+	_, err = Compile(many)
+	assert.ErrorContains(t, err, "more than 64 processors")
+}
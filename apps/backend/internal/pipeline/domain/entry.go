@@ -0,0 +1,141 @@
+package domain
+
+import (
+	"maps"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Entry is the log a pipeline transforms: the fields of a stored log that
+// processors may read and write.
+type Entry struct {
+	Timestamp   time.Time         `json:"timestamp"`
+	Level       string            `json:"level"`
+	Message     string            `json:"message"`
+	Service     string            `json:"service,omitempty"`
+	Namespace   string            `json:"namespace,omitempty"`
+	Environment string            `json:"environment,omitempty"`
+	Host        string            `json:"host,omitempty"`
+	Source      string            `json:"source,omitempty"`
+	TraceID     string            `json:"trace_id,omitempty"`
+	SpanID      string            `json:"span_id,omitempty"`
+	RequestID   string            `json:"request_id,omitempty"`
+	UserID      string            `json:"user_id,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Attributes  map[string]string `json:"attributes,omitempty"`
+}
+
+// Field paths. Processors address an entry's fields by name:
+//
+//	message, level, service, ...   the top-level field of that name
+//	tags.<key>                     a tag
+//	attributes.<key>               an attribute
+//	<key>                          any other name is an attribute
+//
+// The timestamp is only set by the timestamp processor.
+const (
+	tagsPrefix       = "tags."
+	attributesPrefix = "attributes."
+)
+
+var attributeKey = regexp.MustCompile(`^[A-Za-z0-9_.\-@/]{1,128}$`)
+
+// ValidField reports whether path names a field processors may write.
+func ValidField(path string) bool {
+	if ptr := (&Entry{}).top(path); ptr != nil {
+		return true
+	}
+	if key, ok := strings.CutPrefix(path, tagsPrefix); ok {
+		return attributeKey.MatchString(key)
+	}
+	key, _ := strings.CutPrefix(path, attributesPrefix)
+	return path != "timestamp" && attributeKey.MatchString(key)
+}
+
+// Get returns the value of path and whether it is set. Top-level fields are
+// set when non-empty.
+func (e *Entry) Get(path string) (string, bool) {
+	if ptr := e.top(path); ptr != nil {
+		return *ptr, *ptr != ""
+	}
+	m, key := e.mapOf(path, false)
+	if m == nil {
+		return "", false
+	}
+	v, ok := m[key]
+	return v, ok
+}
+
+// Set writes value to path.
+func (e *Entry) Set(path, value string) {
+	if ptr := e.top(path); ptr != nil {
+		*ptr = value
+		return
+	}
+	m, key := e.mapOf(path, true)
+	m[key] = value
+}
+
+// Delete unsets path; top-level fields become empty.
+func (e *Entry) Delete(path string) {
+	if ptr := e.top(path); ptr != nil {
+		*ptr = ""
+		return
+	}
+	if m, key := e.mapOf(path, false); m != nil {
+		delete(m, key)
+	}
+}
+
+func (e *Entry) top(path string) *string {
+	switch path {
+	case "message":
+		return &e.Message
+	case "level":
+		return &e.Level
+	case "service":
+		return &e.Service
+	case "namespace":
+		return &e.Namespace
+	case "environment":
+		return &e.Environment
+	case "host":
+		return &e.Host
+	case "source":
+		return &e.Source
+	case "trace_id":
+		return &e.TraceID
+	case "span_id":
+		return &e.SpanID
+	case "request_id":
+		return &e.RequestID
+	case "user_id":
+		return &e.UserID
+	}
+	return nil
+}
+
+// mapOf returns the map holding path and the key within it, creating the map
+// when create is set.
+func (e *Entry) mapOf(path string, create bool) (map[string]string, string) {
+	if key, ok := strings.CutPrefix(path, tagsPrefix); ok {
+		if e.Tags == nil && create {
+			e.Tags = map[string]string{}
+		}
+		return e.Tags, key
+	}
+	key, _ := strings.CutPrefix(path, attributesPrefix)
+	if e.Attributes == nil && create {
+		e.Attributes = map[string]string{}
+	}
+	return e.Attributes, key
+}
+
+// Clone returns a deep copy of e.
+func (e *Entry) Clone() *Entry {
+	c := *e
+	c.Tags = maps.Clone(e.Tags)
+	c.Attributes = maps.Clone(e.Attributes)
+	return &c
+}
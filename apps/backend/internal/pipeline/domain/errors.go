@@ -0,0 +1,18 @@
+package domain
+
+import "errors"
+
+// Domain errors. Service / transport layers compare against these with
+// errors.Is to map them onto HTTP status codes.
+var (
+	ErrTenantRequired   = errors.New("tenant is required")
+	ErrProjectNotFound  = errors.New("project not found")
+	ErrPipelineNotFound = errors.New("pipeline not found")
+	ErrVersionNotFound  = errors.New("pipeline version not found")
+	ErrNameTaken        = errors.New("a pipeline with this name already exists in the project")
+	ErrTooManyPipelines = errors.New("the project has too many pipelines")
+	ErrTooManySamples   = errors.New("too many samples to simulate")
+	// ErrInvalidPipeline wraps every definition error; the message says which
+	// processor is at fault.
+	ErrInvalidPipeline = errors.New("invalid pipeline")
+)
@@ -0,0 +1,138 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// grokPatterns are the built-in grok patterns. They follow the common
+// Logstash library, rewritten for RE2: no lookarounds or backreferences.
+var grokPatterns = map[string]string{
+	"USERNAME":          `[a-zA-Z0-9._-]+`,
+	"USER":              `%{USERNAME}`,
+	"INT":               `[+-]?[0-9]+`,
+	"BASE10NUM":         `[+-]?(?:[0-9]+(?:\.[0-9]+)?|\.[0-9]+)`,
+	"NUMBER":            `%{BASE10NUM}`,
+	"POSINT":            `\b[1-9][0-9]*\b`,
+	"NONNEGINT":         `\b[0-9]+\b`,
+	"WORD":              `\b\w+\b`,
+	"NOTSPACE":          `\S+`,
+	"SPACE":             `\s*`,
+	"DATA":              `.*?`,
+	"GREEDYDATA":        `.*`,
+	"QUOTEDSTRING":      `"(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*'`,
+	"UUID":              `[A-Fa-f0-9]{8}-(?:[A-Fa-f0-9]{4}-){3}[A-Fa-f0-9]{12}`,
+	"IPV4":              `(?:(?:25[0-5]|2[0-4][0-9]|1[0-9][0-9]|[1-9]?[0-9])\.){3}(?:25[0-5]|2[0-4][0-9]|1[0-9][0-9]|[1-9]?[0-9])`,
+	"IPV6":              `(?:[0-9A-Fa-f]{0,4}:){2,7}[0-9A-Fa-f]{0,4}`,
+	"IP":                `%{IPV6}|%{IPV4}`,
+	"HOSTNAME":          `\b[0-9A-Za-z][0-9A-Za-z-]{0,62}(?:\.[0-9A-Za-z][0-9A-Za-z-]{0,62})*\.?`,
+	"IPORHOST":          `%{IP}|%{HOSTNAME}`,
+	"HOSTPORT":          `%{IPORHOST}:%{POSINT}`,
+	"PATH":              `(?:/[^\s]*)+`,
+	"URIPATH":           `(?:/[A-Za-z0-9$.+!*'(){},~:;=@#%&_\-]*)+`,
+	"URIPARAM":          `\?[A-Za-z0-9$.+!*'|(){},~@#%&/=:;_?\-\[\]<>]*`,
+	"URIPATHPARAM":      `%{URIPATH}(?:%{URIPARAM})?`,
+	"URI":               `[A-Za-z][A-Za-z0-9+\-.]*://\S+`,
+	"EMAILADDRESS":      `[a-zA-Z0-9!#$%&'*+/=?^_{|}~.-]+@%{HOSTNAME}`,
+	"HTTPMETHOD":        `GET|HEAD|POST|PUT|DELETE|CONNECT|OPTIONS|TRACE|PATCH`,
+	"LOGLEVEL":          `(?i:alert|trace|debug|notice|info(?:rmation)?|warn(?:ing)?|err(?:or)?|crit(?:ical)?|fatal|severe|emerg(?:ency)?|panic)`,
+	"TIMESTAMP_ISO8601": `\d{4}-\d{2}-\d{2}[T ]\d{2}:?\d{2}(?::?\d{2}(?:[.,]\d+)?)?(?:Z|[+-]\d{2}:?\d{2})?`,
+	"HTTPDATE":          `\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}`,
+	"SYSLOGTIMESTAMP":   `\w{3} +\d{1,2} \d{2}:\d{2}:\d{2}`,
+}
+
+const (
+	// maxGrokDepth bounds pattern references, which also catches cycles.
+	maxGrokDepth = 16
+	// maxGrokExpansion bounds the regular expression a pattern expands to.
+	maxGrokExpansion = 64 << 10
+	// grokGroup prefixes the names of the groups of field captures.
+	grokGroup = "grok_"
+)
+
+var (
+	grokRef     = regexp.MustCompile(`%\{(\w+)(?::([\w.@/\-]+))?(?::\w+)?\}`)
+	grokPattern = regexp.MustCompile(`^\w+$`)
+)
+
+// compileGrok expands a grok pattern into a regular expression. It returns
+// the field written by each capture group, indexed like the groups; groups
+// without a field are "". A %{PATTERN:field:type} type suffix is accepted
+// and ignored: captured values are always strings.
+func compileGrok(pattern string, definitions map[string]string) (*regexp.Regexp, []string, error) {
+	for name := range definitions {
+		if !grokPattern.MatchString(name) {
+			return nil, nil, fmt.Errorf("invalid grok pattern name %q", name)
+		}
+	}
+	lookup := func(name string) (string, bool) {
+		if p, ok := definitions[name]; ok {
+			return p, true
+		}
+		p, ok := grokPatterns[name]
+		return p, ok
+	}
+
+	var fields []string
+	var expand func(p string, depth int) (string, error)
+	expand = func(p string, depth int) (string, error) {
+		if depth > maxGrokDepth {
+			return "", fmt.Errorf("grok patterns nest deeper than %d (cycle?)", maxGrokDepth)
+		}
+		var err error
+		out := grokRef.ReplaceAllStringFunc(p, func(ref string) string {
+			if err != nil {
+				return ""
+			}
+			m := grokRef.FindStringSubmatch(ref)
+			def, ok := lookup(m[1])
+			if !ok {
+				err = fmt.Errorf("unknown grok pattern %q", m[1])
+				return ""
+			}
+			inner, e := expand(def, depth+1)
+			if e != nil {
+				err = e
+				return ""
+			}
+			if m[2] == "" {
+				return "(?:" + inner + ")"
+			}
+			if !ValidField(m[2]) {
+				err = fmt.Errorf("invalid field %q", m[2])
+				return ""
+			}
+			group := grokGroup + strconv.Itoa(len(fields))
+			fields = append(fields, m[2])
+			return "(?P<" + group + ">" + inner + ")"
+		})
+		if err == nil && len(out) > maxGrokExpansion {
+			err = fmt.Errorf("grok pattern expands beyond %d bytes", maxGrokExpansion)
+		}
+		return out, err
+	}
+
+	expr, err := expand(pattern, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(fields) == 0 {
+		return nil, nil, fmt.Errorf("grok pattern captures no field; use %%{PATTERN:field}")
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("grok pattern: %w", err)
+	}
+
+	byGroup := make([]string, re.NumSubexp()+1)
+	for i, name := range re.SubexpNames() {
+		if n, ok := strings.CutPrefix(name, grokGroup); ok {
+			if idx, err := strconv.Atoi(n); err == nil && idx < len(fields) {
+				byGroup[i] = fields[idx]
+			}
+		}
+	}
+	return re, byGroup, nil
+}
@@ -0,0 +1,150 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxPipelinesPerProject bounds the pipelines a project may define.
+	MaxPipelinesPerProject = 20
+	// MaxProcessors bounds the processors of one pipeline, counting those
+	// nested in routes.
+	MaxProcessors = 64
+	// MaxRouteDepth bounds how deeply routes may nest.
+	MaxRouteDepth = 3
+	// MaxPatternLength bounds regex and grok patterns.
+	MaxPatternLength = 4096
+	// MaxSimulationSamples bounds the sample logs of one simulation.
+	MaxSimulationSamples = 100
+)
+
+// Pipeline is an ordered list of processors the log-processor applies to a
+// project's logs before storing them. A project's enabled pipelines run one
+// after the other, by ascending Position.
+//
+// Every change of the processors is kept as a new Version; the pipeline
+// runs its latest one.
+type Pipeline struct {
+	ID          uuid.UUID   `json:"id"`
+	TenantID    uuid.UUID   `json:"-"`
+	ProjectID   uuid.UUID   `json:"project_id"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Enabled     bool        `json:"enabled"`
+	Position    int         `json:"position"`
+	Version     int         `json:"version"`
+	Processors  []Processor `json:"processors"`
+	CreatedBy   *uuid.UUID  `json:"created_by,omitempty"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+// Version is one revision of a pipeline's processors.
+type Version struct {
+	PipelineID uuid.UUID   `json:"pipeline_id"`
+	Version    int         `json:"version"`
+	Processors []Processor `json:"processors"`
+	CreatedBy  *uuid.UUID  `json:"created_by,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+type ProcessorType string
+
+const (
+	// ProcessorRegex extracts the named groups of a regular expression.
+	ProcessorRegex ProcessorType = "regex"
+	// ProcessorGrok extracts fields with a grok pattern.
+	ProcessorGrok ProcessorType = "grok"
+	// ProcessorJSON expands a JSON object into attributes.
+	ProcessorJSON ProcessorType = "json"
+	// ProcessorKV parses key=value pairs into attributes.
+	ProcessorKV ProcessorType = "kv"
+	// ProcessorLevel normalises a level spelling to the canonical one.
+	ProcessorLevel ProcessorType = "level"
+	// ProcessorTimestamp parses a field into the log's timestamp.
+	ProcessorTimestamp ProcessorType = "timestamp"
+	// ProcessorRename moves a field.
+	ProcessorRename ProcessorType = "rename"
+	// ProcessorDrop removes fields.
+	ProcessorDrop ProcessorType = "drop"
+	// ProcessorSet adds static values.
+	ProcessorSet ProcessorType = "set"
+	// ProcessorRoute runs the processors of the first route whose condition
+	// matches.
+	ProcessorRoute ProcessorType = "route"
+)
+
+// Processor is one step of a pipeline. Which options apply depends on Type;
+// fields are addressed by path (see Entry).
+type Processor struct {
+	Type ProcessorType `json:"type"`
+	// If restricts the processor to the logs matching it.
+	If *Condition `json:"if,omitempty"`
+
+	// Field is the source of regex, grok, json, kv (default message),
+	// level (default level) and timestamp (required).
+	Field string `json:"field,omitempty"`
+
+	// Pattern is the regex or grok pattern. Regex named groups and grok
+	// %{PATTERN:field} captures are written to the field of that name.
+	Pattern string `json:"pattern,omitempty"`
+	// PatternDefinitions adds grok patterns or overrides built-in ones.
+	PatternDefinitions map[string]string `json:"pattern_definitions,omitempty"`
+
+	// Prefix is prepended to the attribute keys written by json and kv.
+	Prefix string `json:"prefix,omitempty"`
+	// FieldSplit and ValueSplit are the single characters separating kv
+	// pairs (default space) and a key from its value (default =).
+	FieldSplit string `json:"field_split,omitempty"`
+	ValueSplit string `json:"value_split,omitempty"`
+
+	// Formats are tried in order by timestamp: a Go time layout or one of
+	// rfc3339, unix, unix_ms, unix_us, unix_ns.
+	Formats []string `json:"formats,omitempty"`
+	// Timezone applies to layouts without a zone (default UTC).
+	Timezone string `json:"timezone,omitempty"`
+
+	// Mapping adds level spellings, e.g. {"crit": "fatal"}.
+	Mapping map[string]string `json:"mapping,omitempty"`
+
+	// From and To are the source and destination of rename.
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+
+	// Fields are removed by drop.
+	Fields []string `json:"fields,omitempty"`
+
+	// Values are written by set; existing values are kept unless Override.
+	Values   map[string]string `json:"values,omitempty"`
+	Override bool              `json:"override,omitempty"`
+
+	// Routes are tried in order by route.
+	Routes []Route `json:"routes,omitempty"`
+}
+
+// Route is a branch of a route processor. A route without a condition
+// matches every log and belongs last.
+type Route struct {
+	If         *Condition  `json:"if,omitempty"`
+	Processors []Processor `json:"processors"`
+}
+
+type ConditionOp string
+
+const (
+	OpEquals    ConditionOp = "equals"
+	OpNotEquals ConditionOp = "not_equals"
+	OpContains  ConditionOp = "contains"
+	OpMatches   ConditionOp = "matches"
+	OpExists    ConditionOp = "exists"
+	OpNotExists ConditionOp = "not_exists"
+)
+
+// Condition tests one field of a log.
+type Condition struct {
+	Field string      `json:"field"`
+	Op    ConditionOp `json:"op"`
+	Value string      `json:"value,omitempty"`
+}
@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Repository stores pipelines and their versions in Postgres. Every call is
+// scoped to a tenant and project: a project of another tenant behaves like a
+// missing one (ErrProjectNotFound), a pipeline of another project like a
+// missing one (ErrPipelineNotFound).
+type Repository interface {
+	// List returns the project's pipelines at their latest version, in run
+	// order. With enabledOnly, disabled pipelines are left out.
+	List(ctx context.Context, tenantID, projectID uuid.UUID, enabledOnly bool) ([]*Pipeline, error)
+	// Get returns a pipeline at version, or at its latest when version is 0.
+	Get(ctx context.Context, tenantID, projectID, id uuid.UUID, version int) (*Pipeline, error)
+	// Create stores p as version 1 and sets its ID, Version and timestamps.
+	Create(ctx context.Context, p *Pipeline) error
+	// Update stores p's settings and its processors as a new version, which
+	// it sets on p.
+	Update(ctx context.Context, p *Pipeline) error
+	Delete(ctx context.Context, tenantID, projectID, id uuid.UUID) error
+	// Versions returns every version of a pipeline, newest first.
+	Versions(ctx context.Context, tenantID, projectID, id uuid.UUID) ([]*Version, error)
+}
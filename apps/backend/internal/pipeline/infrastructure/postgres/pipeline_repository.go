@@ -0,0 +1,279 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/pipeline/domain"
+)
+
+const pgUniqueViolation = "23505"
+
+type pipelineRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPipelineRepository(db *pgxpool.Pool) domain.Repository {
+	return &pipelineRepository{db: db}
+}
+
+const selectPipeline = `
+	SELECT p.id, p.tenant_id, p.project_id, p.name, p.description, p.enabled,
+	       p.position, v.version, v.processors, p.created_by, p.created_at, p.updated_at
+	FROM ingest_pipelines p
+	JOIN ingest_pipeline_versions v ON v.pipeline_id = p.id
+`
+
+func (r *pipelineRepository) List(ctx context.Context, tenantID, projectID uuid.UUID, enabledOnly bool) ([]*domain.Pipeline, error) {
+	if err := r.projectExists(ctx, tenantID, projectID); err != nil {
+		return nil, err
+	}
+	query := selectPipeline + `
+		WHERE p.tenant_id = $1 AND p.project_id = $2 AND v.version = p.version
+		  AND (p.enabled OR NOT $3)
+		ORDER BY p.position, p.created_at
+	`
+	rows, err := r.db.Query(ctx, query, tenantID, projectID, enabledOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pipelines []*domain.Pipeline
+	for rows.Next() {
+		p, err := scanPipeline(rows)
+		if err != nil {
+			return nil, err
+		}
+		pipelines = append(pipelines, p)
+	}
+	return pipelines, rows.Err()
+}
+
+func (r *pipelineRepository) Get(ctx context.Context, tenantID, projectID, id uuid.UUID, version int) (*domain.Pipeline, error) {
+	if err := r.projectExists(ctx, tenantID, projectID); err != nil {
+		return nil, err
+	}
+	query := selectPipeline + `
+		WHERE p.id = $1 AND p.tenant_id = $2 AND p.project_id = $3
+		  AND v.version = CASE WHEN $4 = 0 THEN p.version ELSE $4 END
+	`
+	p, err := scanPipeline(r.db.QueryRow(ctx, query, id, tenantID, projectID, version))
+	if errors.Is(err, pgx.ErrNoRows) {
+		if version != 0 {
+			if _, err := r.pipelineVersion(ctx, r.db, tenantID, projectID, id, false); err == nil {
+				return nil, domain.ErrVersionNotFound
+			}
+		}
+		return nil, domain.ErrPipelineNotFound
+	}
+	return p, err
+}
+
+func (r *pipelineRepository) Create(ctx context.Context, p *domain.Pipeline) error {
+	processors, err := json.Marshal(p.Processors)
+	if err != nil {
+		return err
+	}
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// The project lock serialises creations, so the count holds.
+	if err := lockProject(ctx, tx, p.TenantID, p.ProjectID); err != nil {
+		return err
+	}
+	var count int
+	if err := tx.QueryRow(ctx, `SELECT count(*) FROM ingest_pipelines WHERE project_id = $1`, p.ProjectID).Scan(&count); err != nil {
+		return err
+	}
+	if count >= domain.MaxPipelinesPerProject {
+		return domain.ErrTooManyPipelines
+	}
+
+	now := time.Now().UTC()
+	p.ID, p.Version, p.CreatedAt, p.UpdatedAt = uuid.New(), 1, now, now
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO ingest_pipelines
+			(id, tenant_id, project_id, name, description, enabled, position, version, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $10)
+	`, p.ID, p.TenantID, p.ProjectID, p.Name, p.Description, p.Enabled, p.Position, p.Version, p.CreatedBy, now); err != nil {
+		return mapUniqueViolation(err)
+	}
+	if err := insertVersion(ctx, tx, p.ID, p.Version, processors, p.CreatedBy, now); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *pipelineRepository) Update(ctx context.Context, p *domain.Pipeline) error {
+	processors, err := json.Marshal(p.Processors)
+	if err != nil {
+		return err
+	}
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := r.pipelineVersion(ctx, tx, p.TenantID, p.ProjectID, p.ID, true)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	p.Version, p.UpdatedAt = current+1, now
+	if err := insertVersion(ctx, tx, p.ID, p.Version, processors, p.CreatedBy, now); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE ingest_pipelines
+		SET name = $2, description = $3, enabled = $4, position = $5, version = $6, updated_at = $7
+		WHERE id = $1
+	`, p.ID, p.Name, p.Description, p.Enabled, p.Position, p.Version, now); err != nil {
+		return mapUniqueViolation(err)
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *pipelineRepository) Delete(ctx context.Context, tenantID, projectID, id uuid.UUID) error {
+	if err := r.projectExists(ctx, tenantID, projectID); err != nil {
+		return err
+	}
+	tag, err := r.db.Exec(ctx, `
+		DELETE FROM ingest_pipelines WHERE id = $1 AND tenant_id = $2 AND project_id = $3
+	`, id, tenantID, projectID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrPipelineNotFound
+	}
+	return nil
+}
+
+func (r *pipelineRepository) Versions(ctx context.Context, tenantID, projectID, id uuid.UUID) ([]*domain.Version, error) {
+	if err := r.projectExists(ctx, tenantID, projectID); err != nil {
+		return nil, err
+	}
+	if _, err := r.pipelineVersion(ctx, r.db, tenantID, projectID, id, false); err != nil {
+		return nil, err
+	}
+	rows, err := r.db.Query(ctx, `
+		SELECT version, processors, created_by, created_at
+		FROM ingest_pipeline_versions
+		WHERE pipeline_id = $1
+		ORDER BY version DESC
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var versions []*domain.Version
+	for rows.Next() {
+		v := &domain.Version{PipelineID: id}
+		var processors []byte
+		if err := rows.Scan(&v.Version, &processors, &v.CreatedBy, &v.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(processors, &v.Processors); err != nil {
+			return nil, err
+		}
+		versions = append(versions, v)
+	}
+	return versions, rows.Err()
+}
+
+type querier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// pipelineVersion returns the latest version of a pipeline of the project,
+// locking the pipeline when forUpdate is set.
+func (r *pipelineRepository) pipelineVersion(ctx context.Context, q querier, tenantID, projectID, id uuid.UUID, forUpdate bool) (int, error) {
+	query := `
+		SELECT p.version FROM ingest_pipelines p
+		JOIN projects pr ON pr.id = p.project_id AND pr.deleted_at IS NULL
+		WHERE p.id = $1 AND p.tenant_id = $2 AND p.project_id = $3
+	`
+	if forUpdate {
+		query += ` FOR UPDATE OF p`
+	}
+	var version int
+	if err := q.QueryRow(ctx, query, id, tenantID, projectID).Scan(&version); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, domain.ErrPipelineNotFound
+		}
+		return 0, err
+	}
+	return version, nil
+}
+
+func (r *pipelineRepository) projectExists(ctx context.Context, tenantID, projectID uuid.UUID) error {
+	var one int
+	err := r.db.QueryRow(ctx, `
+		SELECT 1 FROM projects WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL
+	`, projectID, tenantID).Scan(&one)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrProjectNotFound
+	}
+	return err
+}
+
+func lockProject(ctx context.Context, tx pgx.Tx, tenantID, projectID uuid.UUID) error {
+	const query = `
+		SELECT 1 FROM projects
+		WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL
+		FOR UPDATE
+	`
+	var one int
+	if err := tx.QueryRow(ctx, query, projectID, tenantID).Scan(&one); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrProjectNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func insertVersion(ctx context.Context, tx pgx.Tx, id uuid.UUID, version int, processors []byte, createdBy *uuid.UUID, now time.Time) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO ingest_pipeline_versions (pipeline_id, version, processors, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, id, version, processors, createdBy, now)
+	return err
+}
+
+func scanPipeline(row pgx.Row) (*domain.Pipeline, error) {
+	p := &domain.Pipeline{}
+	var processors []byte
+	if err := row.Scan(
+		&p.ID, &p.TenantID, &p.ProjectID, &p.Name, &p.Description, &p.Enabled,
+		&p.Position, &p.Version, &processors, &p.CreatedBy, &p.CreatedAt, &p.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(processors, &p.Processors); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// mapUniqueViolation converts a PostgreSQL unique-violation into a domain error.
+func mapUniqueViolation(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return domain.ErrNameTaken
+	}
+	return err
+}
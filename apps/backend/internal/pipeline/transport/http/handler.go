@@ -0,0 +1,315 @@
+package http
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/pipeline/application"
+	"github.com/indalyadav56/logify/apps/backend/internal/pipeline/domain"
+	"github.com/indalyadav56/logify/apps/backend/pkg/response"
+	"github.com/indalyadav56/logify/apps/backend/pkg/validator"
+)
+
+type PipelineHandler struct {
+	service application.PipelineService
+}
+
+func NewPipelineHandler(service application.PipelineService) *PipelineHandler {
+	return &PipelineHandler{service: service}
+}
+
+// List returns a project's pipelines.
+// @Summary      List pipelines
+// @Description  Return the project's ingest pipelines at their latest version, in the order they run.
+// @Tags         pipelines
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Project ID (UUID)"
+// @Success      200  {object}  response.APIResponse "Pipelines retrieved successfully"
+// @Failure      400  {object}  response.APIResponse "Invalid id format"
+// @Failure      404  {object}  response.APIResponse "Project not found"
+// @Failure      500  {object}  response.APIResponse "Internal server error"
+// @Router       /v1/projects/{id}/pipelines [get]
+func (h *PipelineHandler) List(c *gin.Context) {
+	projectID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	pipelines, err := h.service.List(c.Request.Context(), projectID)
+	if err != nil {
+		h.writeError(c, err, "Failed to retrieve pipelines")
+		return
+	}
+	response.OK(c, "Pipelines retrieved successfully", pipelines)
+}
+
+// Create adds a pipeline to a project.
+// @Summary      Create pipeline
+// @Description  Define an ingest pipeline. The log-processor applies it to the project's new logs within a minute.
+// @Tags         pipelines
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                     true  "Project ID (UUID)"
+// @Param        request  body      application.PipelineInput  true  "Pipeline"
+// @Success      201      {object}  response.APIResponse "Pipeline created successfully"
+// @Failure      400      {object}  response.APIResponse "Invalid request"
+// @Failure      404      {object}  response.APIResponse "Project not found"
+// @Failure      409      {object}  response.APIResponse "Name already taken"
+// @Failure      500      {object}  response.APIResponse "Internal server error"
+// @Router       /v1/projects/{id}/pipelines [post]
+func (h *PipelineHandler) Create(c *gin.Context) {
+	projectID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input application.PipelineInput
+	if !validator.ValidateRequest(c, &input) {
+		return
+	}
+
+	pipeline, err := h.service.Create(c.Request.Context(), projectID, input)
+	if err != nil {
+		h.writeError(c, err, "Failed to create pipeline")
+		return
+	}
+	response.Created(c, "Pipeline created successfully", pipeline)
+}
+
+// Get returns a pipeline.
+// @Summary      Get pipeline
+// @Description  Return a pipeline at its latest version, or at the given one.
+// @Tags         pipelines
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string  true   "Project ID (UUID)"
+// @Param        pipelineId  path      string  true   "Pipeline ID (UUID)"
+// @Param        version     query     int     false  "Version (default latest)"
+// @Success      200  {object}  response.APIResponse "Pipeline retrieved successfully"
+// @Failure      400  {object}  response.APIResponse "Invalid request"
+// @Failure      404  {object}  response.APIResponse "Pipeline not found"
+// @Failure      500  {object}  response.APIResponse "Internal server error"
+// @Router       /v1/projects/{id}/pipelines/{pipelineId} [get]
+func (h *PipelineHandler) Get(c *gin.Context) {
+	projectID, id, ok := parseIDs(c)
+	if !ok {
+		return
+	}
+	version := 0
+	if v := c.Query("version"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			response.BadRequest(c, "Invalid version")
+			return
+		}
+		version = n
+	}
+
+	pipeline, err := h.service.Get(c.Request.Context(), projectID, id, version)
+	if err != nil {
+		h.writeError(c, err, "Failed to retrieve pipeline")
+		return
+	}
+	response.OK(c, "Pipeline retrieved successfully", pipeline)
+}
+
+// Update replaces a pipeline.
+// @Summary      Update pipeline
+// @Description  Replace a pipeline's settings and processors. The processors are stored as a new version.
+// @Tags         pipelines
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string                     true  "Project ID (UUID)"
+// @Param        pipelineId  path      string                     true  "Pipeline ID (UUID)"
+// @Param        request     body      application.PipelineInput  true  "Pipeline"
+// @Success      200  {object}  response.APIResponse "Pipeline updated successfully"
+// @Failure      400  {object}  response.APIResponse "Invalid request"
+// @Failure      404  {object}  response.APIResponse "Pipeline not found"
+// @Failure      409  {object}  response.APIResponse "Name already taken"
+// @Failure      500  {object}  response.APIResponse "Internal server error"
+// @Router       /v1/projects/{id}/pipelines/{pipelineId} [put]
+func (h *PipelineHandler) Update(c *gin.Context) {
+	projectID, id, ok := parseIDs(c)
+	if !ok {
+		return
+	}
+
+	var input application.PipelineInput
+	if !validator.ValidateRequest(c, &input) {
+		return
+	}
+
+	pipeline, err := h.service.Update(c.Request.Context(), projectID, id, input)
+	if err != nil {
+		h.writeError(c, err, "Failed to update pipeline")
+		return
+	}
+	response.OK(c, "Pipeline updated successfully", pipeline)
+}
+
+// Delete removes a pipeline and its versions.
+// @Summary      Delete pipeline
+// @Tags         pipelines
+// @Security     BearerAuth
+// @Param        id          path  string  true  "Project ID (UUID)"
+// @Param        pipelineId  path  string  true  "Pipeline ID (UUID)"
+// @Success      204  "Pipeline deleted"
+// @Failure      400  {object}  response.APIResponse "Invalid id format"
+// @Failure      404  {object}  response.APIResponse "Pipeline not found"
+// @Failure      500  {object}  response.APIResponse "Internal server error"
+// @Router       /v1/projects/{id}/pipelines/{pipelineId} [delete]
+func (h *PipelineHandler) Delete(c *gin.Context) {
+	projectID, id, ok := parseIDs(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), projectID, id); err != nil {
+		h.writeError(c, err, "Failed to delete pipeline")
+		return
+	}
+	response.NoContent(c)
+}
+
+// Versions returns the history of a pipeline.
+// @Summary      List pipeline versions
+// @Description  Return every version of a pipeline's processors, newest first.
+// @Tags         pipelines
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string  true  "Project ID (UUID)"
+// @Param        pipelineId  path      string  true  "Pipeline ID (UUID)"
+// @Success      200  {object}  response.APIResponse "Pipeline versions retrieved successfully"
+// @Failure      400  {object}  response.APIResponse "Invalid id format"
+// @Failure      404  {object}  response.APIResponse "Pipeline not found"
+// @Failure      500  {object}  response.APIResponse "Internal server error"
+// @Router       /v1/projects/{id}/pipelines/{pipelineId}/versions [get]
+func (h *PipelineHandler) Versions(c *gin.Context) {
+	projectID, id, ok := parseIDs(c)
+	if !ok {
+		return
+	}
+
+	versions, err := h.service.Versions(c.Request.Context(), projectID, id)
+	if err != nil {
+		h.writeError(c, err, "Failed to retrieve pipeline versions")
+		return
+	}
+	response.OK(c, "Pipeline versions retrieved successfully", versions)
+}
+
+// Rollback restores the processors of an earlier version.
+// @Summary      Roll back pipeline
+// @Description  Store the processors of an earlier version as the pipeline's new latest version.
+// @Tags         pipelines
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string                     true  "Project ID (UUID)"
+// @Param        pipelineId  path      string                     true  "Pipeline ID (UUID)"
+// @Param        request     body      application.RollbackInput  true  "Version to restore"
+// @Success      200  {object}  response.APIResponse "Pipeline rolled back successfully"
+// @Failure      400  {object}  response.APIResponse "Invalid request"
+// @Failure      404  {object}  response.APIResponse "Pipeline or version not found"
+// @Failure      500  {object}  response.APIResponse "Internal server error"
+// @Router       /v1/projects/{id}/pipelines/{pipelineId}/rollback [post]
+func (h *PipelineHandler) Rollback(c *gin.Context) {
+	projectID, id, ok := parseIDs(c)
+	if !ok {
+		return
+	}
+
+	var input application.RollbackInput
+	if !validator.ValidateRequest(c, &input) {
+		return
+	}
+
+	pipeline, err := h.service.Rollback(c.Request.Context(), projectID, id, input)
+	if err != nil {
+		h.writeError(c, err, "Failed to roll back pipeline")
+		return
+	}
+	response.OK(c, "Pipeline rolled back successfully", pipeline)
+}
+
+// Simulate runs pipelines on sample logs.
+// @Summary      Simulate pipelines
+// @Description  Show what the given processors, or the project's enabled pipelines when none are given, do to sample lines and logs. Nothing is stored.
+// @Tags         pipelines
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                     true  "Project ID (UUID)"
+// @Param        request  body      application.SimulateInput  true  "Processors and samples"
+// @Success      200      {object}  response.APIResponse "Simulation completed successfully"
+// @Failure      400      {object}  response.APIResponse "Invalid request"
+// @Failure      404      {object}  response.APIResponse "Project not found"
+// @Failure      500      {object}  response.APIResponse "Internal server error"
+// @Router       /v1/projects/{id}/pipelines/simulate [post]
+func (h *PipelineHandler) Simulate(c *gin.Context) {
+	projectID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input application.SimulateInput
+	if !validator.ValidateRequest(c, &input) {
+		return
+	}
+
+	out, err := h.service.Simulate(c.Request.Context(), projectID, input)
+	if err != nil {
+		h.writeError(c, err, "Failed to simulate pipelines")
+		return
+	}
+	response.OK(c, "Simulation completed successfully", out)
+}
+
+// writeError maps domain errors to HTTP responses with a consistent envelope.
+func (h *PipelineHandler) writeError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrProjectNotFound):
+		response.NotFound(c, "Project not found")
+	case errors.Is(err, domain.ErrPipelineNotFound):
+		response.NotFound(c, "Pipeline not found")
+	case errors.Is(err, domain.ErrVersionNotFound):
+		response.NotFound(c, "Pipeline version not found")
+	case errors.Is(err, domain.ErrTenantRequired):
+		response.Unauthorized(c, "Tenant ID is required")
+	case errors.Is(err, domain.ErrNameTaken):
+		response.Conflict(c, err.Error())
+	case errors.Is(err, domain.ErrInvalidPipeline),
+		errors.Is(err, domain.ErrTooManyPipelines),
+		errors.Is(err, domain.ErrTooManySamples):
+		response.BadRequest(c, err.Error())
+	default:
+		response.InternalServerError(c, fallback)
+	}
+}
+
+func parseIDs(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	projectID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+	id, ok := parseUUIDParam(c, "pipelineId")
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+	return projectID, id, true
+}
+
+func parseUUIDParam(c *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		response.BadRequest(c, "Invalid "+name+" format")
+		return uuid.Nil, false
+	}
+	return id, true
+}
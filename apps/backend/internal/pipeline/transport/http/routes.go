@@ -0,0 +1,27 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+
+	roleDomain "github.com/indalyadav56/logify/apps/backend/internal/role/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
+)
+
+// RegisterRoutes mounts the pipeline routes under their project. The group
+// is expected to be already authenticated (see di.RegisterAllRoutes).
+func RegisterRoutes(router *gin.RouterGroup, handler *PipelineHandler, authz *middleware.Authorizer) {
+	read := authz.RequireProjectPermission("id", roleDomain.ResourceProject, roleDomain.ActionRead)
+	write := authz.RequireProjectPermission("id", roleDomain.ResourceProject, roleDomain.ActionWrite)
+
+	g := router.Group("/v1/projects/:id/pipelines")
+	{
+		g.GET("", read, handler.List)
+		g.POST("", write, handler.Create)
+		g.POST("/simulate", read, handler.Simulate)
+		g.GET("/:pipelineId", read, handler.Get)
+		g.PUT("/:pipelineId", write, handler.Update)
+		g.DELETE("/:pipelineId", write, handler.Delete)
+		g.GET("/:pipelineId/versions", read, handler.Versions)
+		g.POST("/:pipelineId/rollback", write, handler.Rollback)
+	}
+}
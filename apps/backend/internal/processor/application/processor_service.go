@@ -1,13 +1,19 @@
 package application
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 	ingestDomain "github.com/indalyadav56/logify/apps/backend/internal/ingest/domain"
+	logmetricDomain "github.com/indalyadav56/logify/apps/backend/internal/logmetric/domain"
+	pipelineDomain "github.com/indalyadav56/logify/apps/backend/internal/pipeline/domain"
 	"github.com/indalyadav56/logify/apps/backend/internal/processor/domain"
+	redactionDomain "github.com/indalyadav56/logify/apps/backend/internal/redaction/domain"
+	retentionDomain "github.com/indalyadav56/logify/apps/backend/internal/retention/domain"
 	"go.uber.org/zap"
 )
 
@@ -15,16 +21,46 @@ type LogConsumer interface {
 	Consume(ctx context.Context, handler func(ctx context.Context, msg []byte) error) error
 }
 
+// Pipelines applies a project's ingest pipelines to a log before it is
+// stored. It is satisfied by pipeline/application.PipelineRunner.
+type Pipelines interface {
+	Run(ctx context.Context, tenantID, projectID uuid.UUID, e *pipelineDomain.Entry) ([]error, error)
+}
+
+// Redactor removes sensitive values from a log before it is stored. It is
+// satisfied by redaction/application.RedactionRunner.
+type Redactor interface {
+	Redact(ctx context.Context, tenantID, projectID uuid.UUID, rec *redactionDomain.Record) error
+}
+
+// Metrics counts stored logs in the project's log-derived metrics. It is
+// satisfied by logmetric/application.MetricRunner.
+type Metrics interface {
+	Observe(ctx context.Context, tenantID, projectID uuid.UUID, e *logmetricDomain.Entry) error
+}
+
+// pipelineErrorAttribute records on a log why its pipelines partly failed.
+const pipelineErrorAttribute = "pipeline_error"
+
 type ProcessorService struct {
 	consumer      LogConsumer
 	logRepository domain.LogRepository
+	pipelines     Pipelines
+	redactor      Redactor
+	metrics       Metrics
 	logger        *zap.Logger
 }
 
-func NewProcessorService(consumer LogConsumer, logRepository domain.LogRepository, logger *zap.Logger) *ProcessorService {
+// NewProcessorService builds the service; pipelines and redactor may be nil,
+// in which case logs are stored as sent, and metrics may be nil, in which
+// case no log-derived metrics are counted.
+func NewProcessorService(consumer LogConsumer, logRepository domain.LogRepository, pipelines Pipelines, redactor Redactor, metrics Metrics, logger *zap.Logger) *ProcessorService {
 	return &ProcessorService{
 		consumer:      consumer,
 		logRepository: logRepository,
+		pipelines:     pipelines,
+		redactor:      redactor,
+		metrics:       metrics,
 		logger:        logger.Named("processor_service"),
 	}
 }
@@ -36,34 +72,64 @@ func (s *ProcessorService) Start(ctx context.Context) error {
 
 func (s *ProcessorService) handleMessage(ctx context.Context, msg []byte) error {
 	var ingestLog ingestDomain.Log
-	if err := json.Unmarshal(msg, &ingestLog); err != nil {
+	// Numbers are kept as sent, so ids beyond float64 precision survive.
+	dec := json.NewDecoder(bytes.NewReader(msg))
+	dec.UseNumber()
+	if err := dec.Decode(&ingestLog); err != nil {
 		s.logger.Error("failed to unmarshal log message", zap.Error(err))
 		return nil
 	}
+	// Ingest stamps the tenant from the caller's token. A message without one
+	// would be unreachable by search, so drop it instead of storing it.
+	if ingestLog.TenantID == "" {
+		s.logger.Warn("dropping log without tenant_id", zap.String("project_id", ingestLog.ProjectID))
+		return nil
+	}
 
 	log := &domain.Log{
-		ID:          uuid.New(),
-		TenantID:    ingestLog.TenantID,
-		ProjectID:   ingestLog.ProjectID,
-		Level:       ingestLog.Level,
-		Message:     ingestLog.Message,
-		Timestamp:   time.Unix(ingestLog.Timestamp, 0).UTC(),
-		Service:     ingestLog.Service,
-		Namespace:   ingestLog.Namespace,
-		Host:        ingestLog.Hostname,
-		Environment: ingestLog.Environment,
-		TraceID:     ingestLog.TraceID,
-		SpanID:      ingestLog.SpanID,
-		RequestID:   ingestLog.RequestID,
-		UserID:      ingestLog.UserID,
-		Source:      ingestLog.Source,
-		Tags:        ingestLog.Tags,
+		ID:            uuid.New(),
+		TenantID:      ingestLog.TenantID,
+		ProjectID:     ingestLog.ProjectID,
+		Level:         ingestLog.Level,
+		Message:       ingestLog.Message,
+		Timestamp:     time.Unix(ingestLog.Timestamp, 0).UTC(),
+		Service:       ingestLog.Service,
+		Namespace:     ingestLog.Namespace,
+		Host:          ingestLog.Hostname,
+		Environment:   ingestLog.Environment,
+		TraceID:       ingestLog.TraceID,
+		SpanID:        ingestLog.SpanID,
+		RequestID:     ingestLog.RequestID,
+		UserID:        ingestLog.UserID,
+		Source:        ingestLog.Source,
+		Tags:          ingestLog.Tags,
+		Attributes:    metadataAttributes(ingestLog.Metadata),
+		RetentionDays: retentionDays(ingestLog.RetentionDays),
+		SampleRate:    max(ingestLog.SampleRate, 1),
 	}
+	tenantID, tenantErr := uuid.Parse(log.TenantID)
+	projectID, projectErr := uuid.Parse(log.ProjectID)
+	if tenantErr == nil && projectErr == nil {
+		s.runPipelines(ctx, tenantID, projectID, log)
+		// Redaction runs last so it also covers what pipelines extracted.
+		if err := s.redact(ctx, tenantID, projectID, log); err != nil {
+			s.logger.Error("failed to redact log", zap.String("project_id", log.ProjectID), zap.Error(err))
+			return err
+		}
+	}
+
+	// Typed last, so the types follow what pipelines and redaction left.
+	log.AttributesNumber, log.AttributesBool = domain.TypeAttributes(log.Attributes)
 
+	// The archiver exports late logs of a day by their ingestion time.
+	log.IngestionTime = time.Now().UTC()
 	if err := s.logRepository.InsertBatch(ctx, []*domain.Log{log}); err != nil {
 		s.logger.Error("failed to insert log", zap.Error(err))
 		return err
 	}
+	if tenantErr == nil && projectErr == nil {
+		s.observe(ctx, tenantID, projectID, log)
+	}
 
 	s.logger.Info("processed log",
 		zap.String("project_id", log.ProjectID),
@@ -72,3 +138,141 @@ func (s *ProcessorService) handleMessage(ctx context.Context, msg []byte) error
 	)
 	return nil
 }
+
+// runPipelines applies the project's pipelines to log. Pipelines fail open:
+// a log they cannot be loaded for is stored as sent, and the processors that
+// fail on a log are recorded on it while the others still apply.
+func (s *ProcessorService) runPipelines(ctx context.Context, tenantID, projectID uuid.UUID, log *domain.Log) {
+	if s.pipelines == nil {
+		return
+	}
+
+	e := toEntry(log)
+	errs, err := s.pipelines.Run(ctx, tenantID, projectID, e)
+	if err != nil {
+		s.logger.Warn("failed to load pipelines, storing log as sent",
+			zap.String("project_id", log.ProjectID), zap.Error(err))
+		return
+	}
+	fromEntry(log, e)
+	if len(errs) > 0 {
+		if log.Attributes == nil {
+			log.Attributes = map[string]string{}
+		}
+		log.Attributes[pipelineErrorAttribute] = errors.Join(errs...).Error()
+	}
+}
+
+// redact applies the redaction rules to log. Unlike pipelines, redaction
+// fails closed: a log whose rules cannot be loaded is not stored.
+func (s *ProcessorService) redact(ctx context.Context, tenantID, projectID uuid.UUID, log *domain.Log) error {
+	if s.redactor == nil {
+		return nil
+	}
+	rec := &redactionDomain.Record{
+		Message:    log.Message,
+		UserID:     log.UserID,
+		Attributes: log.Attributes,
+		Tags:       log.Tags,
+	}
+	if err := s.redactor.Redact(ctx, tenantID, projectID, rec); err != nil {
+		return err
+	}
+	log.Message, log.UserID, log.Attributes, log.Tags = rec.Message, rec.UserID, rec.Attributes, rec.Tags
+	return nil
+}
+
+// observe counts a stored log in the project's metrics. Metrics fail open:
+// the log is stored already, and redelivering it would store it twice.
+func (s *ProcessorService) observe(ctx context.Context, tenantID, projectID uuid.UUID, log *domain.Log) {
+	if s.metrics == nil {
+		return
+	}
+	err := s.metrics.Observe(ctx, tenantID, projectID, &logmetricDomain.Entry{
+		Level:       log.Level,
+		Service:     log.Service,
+		Environment: log.Environment,
+		Host:        log.Host,
+		Namespace:   log.Namespace,
+		Source:      log.Source,
+		Message:     log.Message,
+		Attributes:  log.Attributes,
+		Tags:        log.Tags,
+		Weight:      log.SampleRate,
+	})
+	if err != nil {
+		s.logger.Warn("failed to count log in metrics", zap.String("project_id", log.ProjectID), zap.Error(err))
+	}
+}
+
+func toEntry(log *domain.Log) *pipelineDomain.Entry {
+	return &pipelineDomain.Entry{
+		Timestamp:   log.Timestamp,
+		Level:       log.Level,
+		Message:     log.Message,
+		Service:     log.Service,
+		Namespace:   log.Namespace,
+		Environment: log.Environment,
+		Host:        log.Host,
+		Source:      log.Source,
+		TraceID:     log.TraceID,
+		SpanID:      log.SpanID,
+		RequestID:   log.RequestID,
+		UserID:      log.UserID,
+		Tags:        log.Tags,
+		Attributes:  log.Attributes,
+	}
+}
+
+// fromEntry copies what pipelines may change back onto log. The tenant,
+// project and retention are not theirs to change.
+func fromEntry(log *domain.Log, e *pipelineDomain.Entry) {
+	log.Timestamp = e.Timestamp
+	log.Level = e.Level
+	log.Message = e.Message
+	log.Service = e.Service
+	log.Namespace = e.Namespace
+	log.Environment = e.Environment
+	log.Host = e.Host
+	log.Source = e.Source
+	log.TraceID = e.TraceID
+	log.SpanID = e.SpanID
+	log.RequestID = e.RequestID
+	log.UserID = e.UserID
+	log.Tags = e.Tags
+	log.Attributes = e.Attributes
+}
+
+// metadataAttributes turns the metadata of an ingested log into attributes.
+// Values that are not strings are kept in their JSON form, which for numbers
+// and booleans is what TypeAttributes types.
+func metadataAttributes(metadata map[string]interface{}) map[string]string {
+	if len(metadata) == 0 {
+		return nil
+	}
+	attributes := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		switch v := v.(type) {
+		case nil:
+		case string:
+			attributes[k] = v
+		default:
+			if b, err := json.Marshal(v); err == nil {
+				attributes[k] = string(b)
+			}
+		}
+	}
+	return attributes
+}
+
+// retentionDays bounds the retention stamped by ingest. Messages produced
+// before retention policies existed carry none and get the default.
+func retentionDays(days int) uint16 {
+	switch {
+	case days <= 0:
+		return retentionDomain.DefaultRetentionDays
+	case days > retentionDomain.MaxRetentionDays:
+		return retentionDomain.MaxRetentionDays
+	}
+	return uint16(days)
+}
@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// numberValue is the syntax of attribute values typed as numbers: decimal,
+// as JSON writes them. It must match the DEFAULT of attributes_number in
+// the ClickHouse migrations, which types the rows the processor did not.
+var numberValue = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?([eE][-+]?[0-9]+)?$`)
+
+// TypeAttributes returns the attributes whose values are finite numbers or
+// the booleans "true" and "false", typed. Every attribute stays in the
+// string map too, so equality filters on the text keep working.
+func TypeAttributes(attributes map[string]string) (map[string]float64, map[string]bool) {
+	numbers, bools := map[string]float64{}, map[string]bool{}
+	for k, v := range attributes {
+		switch {
+		case v == "true" || v == "false":
+			bools[k] = v == "true"
+		case numberValue.MatchString(v):
+			// Out of range values, which would be infinite, stay strings.
+			if n, err := strconv.ParseFloat(v, 64); err == nil {
+				numbers[k] = n
+			}
+		}
+	}
+	return numbers, bools
+}
@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypeAttributes(t *testing.T) {
+	numbers, bools := TypeAttributes(map[string]string{
+		"duration_ms": "12.5",
+		"status":      "503",
+		"offset":      "-1e3",
+		"retried":     "true",
+		"cached":      "false",
+		"region":      "eu-west-1",
+		"version":     "1.2.3",
+		"padded":      " 42",
+		"flag":        "True",
+		"huge":        "1e400",
+	})
+
+	assert.Equal(t, map[string]float64{"duration_ms": 12.5, "status": 503, "offset": -1000}, numbers)
+	assert.Equal(t, map[string]bool{"retried": true, "cached": false}, bools)
+}
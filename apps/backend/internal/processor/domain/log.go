@@ -27,6 +27,13 @@ type Log struct {
 	Tags          map[string]string `ch:"tags"`
 	Attributes    map[string]string `ch:"attributes"`
 	IngestionTime time.Time         `ch:"ingestion_time"`
+	RetentionDays uint16            `ch:"retention_days"`
+	// SampleRate is the number of logs this one stands for after sampling.
+	SampleRate uint32 `ch:"sample_rate"`
+	// AttributesNumber and AttributesBool hold the attributes whose values
+	// are numbers or booleans, typed; see TypeAttributes.
+	AttributesNumber map[string]float64 `ch:"attributes_number"`
+	AttributesBool   map[string]bool    `ch:"attributes_bool"`
 }
 
 // LogFilter holds optional query filters for fetching logs.
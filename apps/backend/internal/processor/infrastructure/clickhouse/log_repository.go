@@ -59,7 +59,7 @@ func (r *LogRepository) Query(ctx context.Context, filter domain.LogFilter) ([]*
 	}
 
 	query := fmt.Sprintf(
-		"SELECT id, tenant_id, project_id, timestamp, level, service, namespace, environment, host, source, trace_id, span_id, request_id, user_id, message, tags, attributes, ingestion_time FROM %s %s ORDER BY timestamp DESC LIMIT %d OFFSET %d",
+		"SELECT id, tenant_id, project_id, timestamp, level, service, namespace, environment, host, source, trace_id, span_id, request_id, user_id, message, tags, attributes, ingestion_time, retention_days, sample_rate FROM %s %s ORDER BY timestamp DESC LIMIT %d OFFSET %d",
 		table, where, limit, offset,
 	)
 
@@ -7,14 +7,15 @@ import (
 )
 
 type CreateProjectInput struct {
-	TenantID    uuid.UUID `json:"tenant_id"`
-	Name        string    `json:"name"                  validate:"required,min=2,max=255"`
-	Description string    `json:"description,omitempty" validate:"omitempty,max=1000"`
+	Name        string `json:"name"                  validate:"required,min=2,max=255"`
+	Description string `json:"description,omitempty" validate:"omitempty,max=1000"`
+	Sensitive   bool   `json:"sensitive,omitempty"`
 }
 
 type UpdateProjectInput struct {
 	Name        *string `json:"name,omitempty"        validate:"omitempty,min=2,max=255"`
 	Description *string `json:"description,omitempty" validate:"omitempty,max=1000"`
+	Sensitive   *bool   `json:"sensitive,omitempty"`
 }
 
 type ProjectOutput struct {
@@ -22,6 +23,7 @@ type ProjectOutput struct {
 	TenantID    uuid.UUID `json:"tenant_id"`
 	Name        string    `json:"name"`
 	Description string    `json:"description"`
+	Sensitive   bool      `json:"sensitive"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
@@ -8,8 +8,11 @@ import (
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 
+	auditDomain "github.com/indalyadav56/logify/apps/backend/internal/audit/domain"
 	"github.com/indalyadav56/logify/apps/backend/internal/project/domain"
+	roleDomain "github.com/indalyadav56/logify/apps/backend/internal/role/domain"
 	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
+	tenantDomain "github.com/indalyadav56/logify/apps/backend/internal/tenant/domain"
 )
 
 type ProjectService interface {
@@ -18,39 +21,62 @@ type ProjectService interface {
 	ListProjects(ctx context.Context) ([]*ProjectOutput, error)
 	UpdateProject(ctx context.Context, id uuid.UUID, input UpdateProjectInput) (*ProjectOutput, error)
 	DeleteProject(ctx context.Context, id uuid.UUID) error
+	// IsSensitive reports whether the tenant's project is marked sensitive.
+	// A missing project is not.
+	IsSensitive(ctx context.Context, tenantID, projectID uuid.UUID) (bool, error)
+}
+
+// ProjectAccess decides which projects the caller may see. It is satisfied by
+// role/application.PermissionResolver.
+type ProjectAccess interface {
+	HasPermission(ctx context.Context, userID, tenantID uuid.UUID, projectID *uuid.UUID, resource, action string) (bool, error)
+}
+
+// ProjectQuota supplies the tenant's project limit. It is satisfied by
+// tenant/application.TenantService.
+type ProjectQuota interface {
+	GetQuota(ctx context.Context, tenantID uuid.UUID) (*tenantDomain.Quota, error)
 }
 
 type projectService struct {
 	repo   domain.ProjectRepository
+	access ProjectAccess
+	quotas ProjectQuota
+	audit  auditDomain.Recorder
 	logger *zap.Logger
 }
 
-func NewProjectService(repo domain.ProjectRepository, logger *zap.Logger) ProjectService {
+func NewProjectService(repo domain.ProjectRepository, access ProjectAccess, quotas ProjectQuota, audit auditDomain.Recorder, logger *zap.Logger) ProjectService {
+	if audit == nil {
+		audit = auditDomain.Discard
+	}
 	return &projectService{
 		repo:   repo,
+		access: access,
+		quotas: quotas,
+		audit:  audit,
 		logger: logger.Named("project_service"),
 	}
 }
 
 func (s *projectService) CreateProject(ctx context.Context, input CreateProjectInput) (*ProjectOutput, error) {
-	tenantIDStr, ok := middleware.TenantIDFromContext(ctx)
-	if !ok {
-		return nil, errors.New("Error getting tenant id")
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
 	}
-
-	tenantID := uuid.MustParse(tenantIDStr)
-
-	userIDStr, ok := middleware.UserIDFromContext(ctx)
+	userID, ok := middleware.UserUUIDFromContext(ctx)
 	if !ok {
-		return nil, errors.New("error getting tenant id")
+		return nil, domain.ErrTenantRequired
+	}
+	if err := s.checkLimit(ctx, tenantID); err != nil {
+		return nil, err
 	}
-
-	userID := uuid.MustParse(userIDStr)
 
 	project := domain.Project{
 		TenantID:    tenantID,
 		Name:        input.Name,
 		Description: input.Description,
+		Sensitive:   input.Sensitive,
 		CreatedBy:   userID,
 	}
 
@@ -67,11 +93,16 @@ func (s *projectService) CreateProject(ctx context.Context, input CreateProjectI
 		zap.String("tenant_id", project.TenantID.String()),
 		zap.String("name", project.Name),
 	)
+	s.record(ctx, auditDomain.ActionProjectCreated, &project, nil, projectState(&project))
 	return toProjectOutput(&project), nil
 }
 
 func (s *projectService) GetProject(ctx context.Context, id uuid.UUID) (*ProjectOutput, error) {
-	ws, err := s.repo.GetByID(ctx, id)
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ws, err := s.repo.GetByID(ctx, tenantID, id)
 	if err != nil {
 		return nil, err
 	}
@@ -79,18 +110,20 @@ func (s *projectService) GetProject(ctx context.Context, id uuid.UUID) (*Project
 }
 
 func (s *projectService) ListProjects(ctx context.Context) ([]*ProjectOutput, error) {
-	tenantIDStr, ok := middleware.TenantIDFromContext(ctx)
-	if !ok {
-		return nil, errors.New("error getting tenant id")
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	tenantID := uuid.MustParse(tenantIDStr)
-
-	items, err := s.repo.List(ctx, &tenantID)
+	items, err := s.repo.List(ctx, tenantID)
 	if err != nil {
 		s.logger.Error("failed to list projects", zap.Error(err))
 		return nil, err
 	}
+	items, err = s.readable(ctx, tenantID, items)
+	if err != nil {
+		return nil, err
+	}
 	out := make([]*ProjectOutput, len(items))
 	for i, w := range items {
 		out[i] = toProjectOutput(w)
@@ -98,11 +131,43 @@ func (s *projectService) ListProjects(ctx context.Context) ([]*ProjectOutput, er
 	return out, nil
 }
 
+// readable narrows items to the projects the caller may read: all of them
+// with a tenant-level grant, otherwise those they are a member of.
+func (s *projectService) readable(ctx context.Context, tenantID uuid.UUID, items []*domain.Project) ([]*domain.Project, error) {
+	userID, ok := middleware.UserUUIDFromContext(ctx)
+	if !ok {
+		return nil, domain.ErrTenantRequired
+	}
+	all, err := s.access.HasPermission(ctx, userID, tenantID, nil, roleDomain.ResourceProject, roleDomain.ActionRead)
+	if err != nil {
+		return nil, err
+	}
+	if all {
+		return items, nil
+	}
+	visible := make([]*domain.Project, 0, len(items))
+	for _, p := range items {
+		ok, err := s.access.HasPermission(ctx, userID, tenantID, &p.ID, roleDomain.ResourceProject, roleDomain.ActionRead)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			visible = append(visible, p)
+		}
+	}
+	return visible, nil
+}
+
 func (s *projectService) UpdateProject(ctx context.Context, id uuid.UUID, input UpdateProjectInput) (*ProjectOutput, error) {
-	ws, err := s.repo.GetByID(ctx, id)
+	tenantID, err := tenantFromContext(ctx)
 	if err != nil {
 		return nil, err
 	}
+	ws, err := s.repo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	before := projectState(ws)
 
 	if input.Name != nil {
 		ws.Name = strings.TrimSpace(*input.Name)
@@ -110,6 +175,9 @@ func (s *projectService) UpdateProject(ctx context.Context, id uuid.UUID, input
 	if input.Description != nil {
 		ws.Description = strings.TrimSpace(*input.Description)
 	}
+	if input.Sensitive != nil {
+		ws.Sensitive = *input.Sensitive
+	}
 
 	if err := s.repo.Update(ctx, ws); err != nil {
 		s.logger.Error("failed to update project",
@@ -118,11 +186,20 @@ func (s *projectService) UpdateProject(ctx context.Context, id uuid.UUID, input
 		)
 		return nil, err
 	}
+	s.record(ctx, auditDomain.ActionProjectUpdated, ws, before, projectState(ws))
 	return toProjectOutput(ws), nil
 }
 
 func (s *projectService) DeleteProject(ctx context.Context, id uuid.UUID) error {
-	if err := s.repo.Delete(ctx, id); err != nil {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	project, err := s.repo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, tenantID, id); err != nil {
 		if !errors.Is(err, domain.ErrProjectNotFound) {
 			s.logger.Error("failed to delete project",
 				zap.Error(err),
@@ -132,15 +209,74 @@ func (s *projectService) DeleteProject(ctx context.Context, id uuid.UUID) error
 		return err
 	}
 	s.logger.Info("project deleted", zap.String("project_id", id.String()))
+	s.record(ctx, auditDomain.ActionProjectDeleted, project, projectState(project), nil)
+	return nil
+}
+
+func (s *projectService) IsSensitive(ctx context.Context, tenantID, projectID uuid.UUID) (bool, error) {
+	p, err := s.repo.GetByID(ctx, tenantID, projectID)
+	if err != nil {
+		if errors.Is(err, domain.ErrProjectNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return p.Sensitive, nil
+}
+
+// checkLimit enforces the tenant's max_projects quota.
+func (s *projectService) checkLimit(ctx context.Context, tenantID uuid.UUID) error {
+	quota, err := s.quotas.GetQuota(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	n, err := s.repo.Count(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if n >= quota.MaxProjects {
+		return domain.ErrProjectLimitReached
+	}
 	return nil
 }
 
+func (s *projectService) record(ctx context.Context, action string, p *domain.Project, before, after map[string]any) {
+	s.audit.Record(ctx, auditDomain.Event{
+		Action:     action,
+		ProjectID:  p.ID,
+		TargetType: "project",
+		TargetID:   p.ID.String(),
+		Before:     before,
+		After:      after,
+	})
+}
+
+// projectState is what an audit record shows of a project.
+func projectState(p *domain.Project) map[string]any {
+	return map[string]any{
+		"name":        p.Name,
+		"description": p.Description,
+		"sensitive":   p.Sensitive,
+	}
+}
+
+// tenantFromContext returns the caller's tenant as set by AuthMiddleware. The
+// tenant is never taken from request input.
+func tenantFromContext(ctx context.Context) (uuid.UUID, error) {
+	tenantID, ok := middleware.GetTenantUUIDFromContext(ctx)
+	if !ok {
+		return uuid.Nil, domain.ErrTenantRequired
+	}
+	return tenantID, nil
+}
+
 func toProjectOutput(w *domain.Project) *ProjectOutput {
 	return &ProjectOutput{
 		ID:          w.ID,
 		TenantID:    w.TenantID,
 		Name:        w.Name,
 		Description: w.Description,
+		Sensitive:   w.Sensitive,
 		CreatedAt:   w.CreatedAt,
 		UpdatedAt:   w.UpdatedAt,
 	}
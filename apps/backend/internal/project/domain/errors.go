@@ -7,4 +7,6 @@ import "errors"
 var (
 	ErrProjectNotFound      = errors.New("project not found")
 	ErrProjectAlreadyExists = errors.New("project with this name already exists in tenant")
+	ErrTenantRequired       = errors.New("tenant is required")
+	ErrProjectLimitReached  = errors.New("tenant project limit reached")
 )
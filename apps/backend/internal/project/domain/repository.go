@@ -9,12 +9,18 @@ import (
 // ProjectRepository is the persistence contract for the project bounded
 // context. All IDs are uuid.UUID — string IDs at the edges (HTTP, etc.) are
 // parsed in transport.
+//
+// Every read and write is scoped to a tenant: a project owned by another
+// tenant behaves exactly like a missing one (ErrProjectNotFound).
 type ProjectRepository interface {
 	Create(ctx context.Context, project *Project) error
-	GetByID(ctx context.Context, id uuid.UUID) (*Project, error)
-	// List returns projects, optionally filtered to a single tenant when
-	// tenantID is non-nil. Results are ordered by created_at DESC.
-	List(ctx context.Context, tenantID *uuid.UUID) ([]*Project, error)
+	GetByID(ctx context.Context, tenantID, id uuid.UUID) (*Project, error)
+	// List returns the tenant's projects ordered by created_at DESC.
+	List(ctx context.Context, tenantID uuid.UUID) ([]*Project, error)
+	// Count returns how many projects the tenant has.
+	Count(ctx context.Context, tenantID uuid.UUID) (int, error)
+	// Update writes name/description/sensitive; project.TenantID must match
+	// the stored row.
 	Update(ctx context.Context, project *Project) error
-	Delete(ctx context.Context, id uuid.UUID) error
+	Delete(ctx context.Context, tenantID, id uuid.UUID) error
 }
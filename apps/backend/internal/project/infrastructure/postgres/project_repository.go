@@ -25,14 +25,15 @@ func NewProjectRepository(db *pgxpool.Pool) domain.ProjectRepository {
 
 func (r *projectRepository) Create(ctx context.Context, project *domain.Project) error {
 	const query = `
-		INSERT INTO projects (tenant_id, name, description, created_by)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO projects (tenant_id, name, description, sensitive, created_by)
+		VALUES ($1, $2, $3, $4, $5)
 		RETURNING id, COALESCE(description, ''), created_at, updated_at
 	`
 	err := r.db.QueryRow(ctx, query,
 		project.TenantID,
 		project.Name,
 		nullableText(project.Description),
+		project.Sensitive,
 		project.CreatedBy,
 	).Scan(
 		&project.ID,
@@ -46,18 +47,19 @@ func (r *projectRepository) Create(ctx context.Context, project *domain.Project)
 	return nil
 }
 
-func (r *projectRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Project, error) {
+func (r *projectRepository) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.Project, error) {
 	const query = `
-		SELECT id, tenant_id, name, COALESCE(description, ''), created_at, updated_at
+		SELECT id, tenant_id, name, COALESCE(description, ''), sensitive, created_at, updated_at
 		FROM projects
-		WHERE id = $1
+		WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL
 	`
 	var ws domain.Project
-	err := r.db.QueryRow(ctx, query, id).Scan(
+	err := r.db.QueryRow(ctx, query, id, tenantID).Scan(
 		&ws.ID,
 		&ws.TenantID,
 		&ws.Name,
 		&ws.Description,
+		&ws.Sensitive,
 		&ws.CreatedAt,
 		&ws.UpdatedAt,
 	)
@@ -70,19 +72,14 @@ func (r *projectRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.
 	return &ws, nil
 }
 
-func (r *projectRepository) List(ctx context.Context, tenantID *uuid.UUID) ([]*domain.Project, error) {
-	const baseQuery = `
-		SELECT id, tenant_id, name, COALESCE(description, ''), created_at, updated_at
+func (r *projectRepository) List(ctx context.Context, tenantID uuid.UUID) ([]*domain.Project, error) {
+	const query = `
+		SELECT id, tenant_id, name, COALESCE(description, ''), sensitive, created_at, updated_at
 		FROM projects
+		WHERE tenant_id = $1 AND deleted_at IS NULL
+		ORDER BY created_at DESC
 	`
-	query := baseQuery + " ORDER BY created_at DESC"
-	args := []any{}
-	if tenantID != nil {
-		query = baseQuery + " WHERE tenant_id = $1 ORDER BY created_at DESC"
-		args = append(args, *tenantID)
-	}
-
-	rows, err := r.db.Query(ctx, query, args...)
+	rows, err := r.db.Query(ctx, query, tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -96,6 +93,7 @@ func (r *projectRepository) List(ctx context.Context, tenantID *uuid.UUID) ([]*d
 			&ws.TenantID,
 			&ws.Name,
 			&ws.Description,
+			&ws.Sensitive,
 			&ws.CreatedAt,
 			&ws.UpdatedAt,
 		); err != nil {
@@ -106,15 +104,25 @@ func (r *projectRepository) List(ctx context.Context, tenantID *uuid.UUID) ([]*d
 	return out, rows.Err()
 }
 
+func (r *projectRepository) Count(ctx context.Context, tenantID uuid.UUID) (int, error) {
+	const query = `SELECT COUNT(*) FROM projects WHERE tenant_id = $1 AND deleted_at IS NULL`
+	var n int
+	if err := r.db.QueryRow(ctx, query, tenantID).Scan(&n); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
 func (r *projectRepository) Update(ctx context.Context, ws *domain.Project) error {
 	const query = `
 		UPDATE projects
 		SET name = $2,
 		    description = $3,
+		    sensitive = $5,
 		    updated_at = (now() AT TIME ZONE 'utc')
-		WHERE id = $1
+		WHERE id = $1 AND tenant_id = $4 AND deleted_at IS NULL
 	`
-	tag, err := r.db.Exec(ctx, query, ws.ID, ws.Name, nullableText(ws.Description))
+	tag, err := r.db.Exec(ctx, query, ws.ID, ws.Name, nullableText(ws.Description), ws.TenantID, ws.Sensitive)
 	if err != nil {
 		return mapUniqueViolation(err)
 	}
@@ -124,9 +132,9 @@ func (r *projectRepository) Update(ctx context.Context, ws *domain.Project) erro
 	return nil
 }
 
-func (r *projectRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	const query = `DELETE FROM projects WHERE id = $1`
-	tag, err := r.db.Exec(ctx, query, id)
+func (r *projectRepository) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	const query = `DELETE FROM projects WHERE id = $1 AND tenant_id = $2`
+	tag, err := r.db.Exec(ctx, query, id, tenantID)
 	if err != nil {
 		return err
 	}
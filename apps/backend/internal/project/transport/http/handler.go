@@ -8,7 +8,6 @@ import (
 
 	"github.com/indalyadav56/logify/apps/backend/internal/project/application"
 	"github.com/indalyadav56/logify/apps/backend/internal/project/domain"
-	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
 	"github.com/indalyadav56/logify/apps/backend/pkg/response"
 	"github.com/indalyadav56/logify/apps/backend/pkg/validator"
 )
@@ -41,13 +40,6 @@ func (h *ProjectHandler) CreateProject(c *gin.Context) {
 		return
 	}
 
-	tenantID, ok := middleware.TenantIDFromContext(c.Request.Context())
-	if !ok {
-		response.Unauthorized(c, "Tenant ID is required")
-		return
-	}
-	input.TenantID = uuid.MustParse(tenantID)
-
 	ws, err := h.service.CreateProject(c.Request.Context(), input)
 	if err != nil {
 		h.writeError(c, err, "Failed to create project")
@@ -82,21 +74,20 @@ func (h *ProjectHandler) GetProject(c *gin.Context) {
 	response.OK(c, "Project retrieved successfully", ws)
 }
 
-// ListProjects lists projects, optionally filtered by tenant.
+// ListProjects lists the caller's tenant projects.
 // @Summary      List projects
-// @Description  Return projects visible to the caller. Optionally filter by tenant_id.
+// @Description  Return the projects of the authenticated tenant.
 // @Tags         projects
 // @Produce      json
 // @Security     BearerAuth
-// @Param        tenant_id  query     string  false  "Filter by tenant UUID"
 // @Success      200        {object}  response.APIResponse "Projects retrieved successfully"
-// @Failure      400        {object}  response.APIResponse "Invalid tenant_id format"
+// @Failure      401        {object}  response.APIResponse "Unauthorized"
 // @Failure      500        {object}  response.APIResponse "Internal server error"
 // @Router       /v1/projects [get]
 func (h *ProjectHandler) ListProjects(c *gin.Context) {
 	items, err := h.service.ListProjects(c.Request.Context())
 	if err != nil {
-		response.InternalServerError(c, "Failed to list projects")
+		h.writeError(c, err, "Failed to list projects")
 		return
 	}
 	response.OK(c, "Projects retrieved successfully", items)
@@ -165,8 +156,12 @@ func (h *ProjectHandler) writeError(c *gin.Context, err error, fallback string)
 	switch {
 	case errors.Is(err, domain.ErrProjectNotFound):
 		response.NotFound(c, "Project not found")
+	case errors.Is(err, domain.ErrTenantRequired):
+		response.Unauthorized(c, "Tenant ID is required")
 	case errors.Is(err, domain.ErrProjectAlreadyExists):
 		response.Conflict(c, "A project with this name already exists in the tenant")
+	case errors.Is(err, domain.ErrProjectLimitReached):
+		response.Forbidden(c, "The project limit of your plan has been reached")
 	default:
 		response.InternalServerError(c, fallback)
 	}
@@ -2,17 +2,22 @@ package http
 
 import (
 	"github.com/gin-gonic/gin"
+
+	roleDomain "github.com/indalyadav56/logify/apps/backend/internal/role/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
 )
 
 // RegisterRoutes mounts the project routes on the given router group. The group
 // is expected to be already authenticated (see di.RegisterAllRoutes).
-func RegisterRoutes(router *gin.RouterGroup, handler *ProjectHandler) {
+func RegisterRoutes(router *gin.RouterGroup, handler *ProjectHandler, authz *middleware.Authorizer) {
 	g := router.Group("/v1/projects")
 	{
+		// Listing is open to project members too; the service narrows the
+		// list to the projects the caller may read.
 		g.GET("", handler.ListProjects)
-		g.POST("", handler.CreateProject)
-		g.GET("/:id", handler.GetProject)
-		g.PUT("/:id", handler.UpdateProject)
-		g.DELETE("/:id", handler.DeleteProject)
+		g.POST("", authz.RequirePermission(roleDomain.ResourceProject, roleDomain.ActionWrite), handler.CreateProject)
+		g.GET("/:id", authz.RequireProjectPermission("id", roleDomain.ResourceProject, roleDomain.ActionRead), handler.GetProject)
+		g.PUT("/:id", authz.RequireProjectPermission("id", roleDomain.ResourceProject, roleDomain.ActionWrite), handler.UpdateProject)
+		g.DELETE("/:id", authz.RequireProjectPermission("id", roleDomain.ResourceProject, roleDomain.ActionDelete), handler.DeleteProject)
 	}
 }
@@ -0,0 +1,19 @@
+package application
+
+import (
+	"github.com/indalyadav56/logify/apps/backend/internal/redaction/domain"
+)
+
+// RuleInput defines a redaction rule.
+type RuleInput struct {
+	Name     string          `json:"name" validate:"required,max=64"`
+	Detector domain.Detector `json:"detector" validate:"required"`
+	// Pattern is required by, and only allowed for, the custom detector.
+	Pattern string        `json:"pattern,omitempty"`
+	Action  domain.Action `json:"action" validate:"required"`
+	// Fields restricts the rule: message, user_id, attributes.<key>,
+	// tags.<key>, attributes.* or tags.*. Empty means every field.
+	Fields []string `json:"fields,omitempty"`
+	// Enabled defaults to true.
+	Enabled *bool `json:"enabled,omitempty"`
+}
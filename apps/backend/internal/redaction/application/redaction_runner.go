@@ -0,0 +1,156 @@
+package application
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/redaction/domain"
+	"github.com/indalyadav56/logify/apps/backend/pkg/flushloop"
+	"github.com/indalyadav56/logify/apps/backend/pkg/ttlcache"
+)
+
+const (
+	defaultRuleCacheTTL  = 30 * time.Second
+	defaultFlushInterval = time.Minute
+
+	// maxRedactorCacheEntries caps memory; the cache is simply reset when full.
+	maxRedactorCacheEntries = 10_000
+)
+
+type cachedRedactor struct {
+	tenantID uuid.UUID
+	redactor *domain.Redactor
+}
+
+// RedactionRunner redacts logs in the workers before they are stored or
+// embedded. It caches each project's compiled rules, including empty ones,
+// for ttl: the API runs in another process, so the cache cannot be told of
+// changes.
+//
+// With a HitRepository it counts what each rule redacted, and Run flushes
+// the counts; without one nothing is counted, so a log that passes through
+// several workers counts once.
+type RedactionRunner struct {
+	rules    domain.RuleRepository
+	hits     domain.HitRepository
+	key      []byte
+	interval time.Duration
+	logger   *zap.Logger
+	now      func() time.Time
+	cache    *ttlcache.Cache[uuid.UUID, cachedRedactor]
+
+	countMu sync.Mutex
+	counts  domain.Hits
+}
+
+func NewRedactionRunner(rules domain.RuleRepository, hits domain.HitRepository, key []byte, ttl, interval time.Duration, logger *zap.Logger) *RedactionRunner {
+	if ttl <= 0 {
+		ttl = defaultRuleCacheTTL
+	}
+	if interval <= 0 {
+		interval = defaultFlushInterval
+	}
+	return &RedactionRunner{
+		rules:    rules,
+		hits:     hits,
+		key:      key,
+		interval: interval,
+		logger:   logger.Named("redaction_runner"),
+		now:      time.Now,
+		cache:    ttlcache.New[uuid.UUID, cachedRedactor](ttl, maxRedactorCacheEntries),
+		counts:   domain.Hits{},
+	}
+}
+
+// Redact applies the rules of the project, and of its tenant, to rec. It
+// fails only when the rules cannot be loaded and none are cached; rec is
+// then untouched and must not be stored as is.
+func (r *RedactionRunner) Redact(ctx context.Context, tenantID, projectID uuid.UUID, rec *domain.Record) error {
+	redactor, err := r.redactor(ctx, tenantID, projectID)
+	if err != nil || redactor.Empty() {
+		return err
+	}
+	if r.hits == nil {
+		redactor.Redact(tenantID, rec, nil)
+		return nil
+	}
+	hits := domain.Hits{}
+	redactor.Redact(tenantID, rec, hits)
+	if len(hits) > 0 {
+		r.countMu.Lock()
+		for id, n := range hits {
+			r.counts[id] += n
+		}
+		r.countMu.Unlock()
+	}
+	return nil
+}
+
+func (r *RedactionRunner) redactor(ctx context.Context, tenantID, projectID uuid.UUID) (*domain.Redactor, error) {
+	now := r.now()
+	if entry, ok := r.cache.Get(projectID, now); ok && entry.tenantID == tenantID {
+		return entry.redactor, nil
+	}
+
+	rules, err := r.rules.Applicable(ctx, tenantID, projectID)
+	if err != nil {
+		// Expired rules still redact better than none.
+		if entry, ok := r.cache.Stale(projectID); ok && entry.tenantID == tenantID {
+			r.logger.Warn("failed to reload redaction rules, using expired ones",
+				zap.String("project_id", projectID.String()), zap.Error(err))
+			return entry.redactor, nil
+		}
+		return nil, err
+	}
+	// One rule the engine no longer accepts must not stop the others.
+	valid := rules[:0]
+	for _, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			r.logger.Error("skipping redaction rule that does not compile",
+				zap.String("rule_id", rule.ID.String()), zap.Error(err))
+			continue
+		}
+		valid = append(valid, rule)
+	}
+	redactor, err := domain.NewRedactor(valid, r.key)
+	if err != nil {
+		return nil, err
+	}
+
+	r.cache.Set(projectID, cachedRedactor{tenantID: tenantID, redactor: redactor}, now)
+	return redactor, nil
+}
+
+// Flush stores the hits counted since the last flush against today. Counts
+// that fail to store are kept for the next flush.
+func (r *RedactionRunner) Flush(ctx context.Context) error {
+	if r.hits == nil {
+		return nil
+	}
+	r.countMu.Lock()
+	counts := r.counts
+	r.counts = domain.Hits{}
+	r.countMu.Unlock()
+	if len(counts) == 0 {
+		return nil
+	}
+
+	if err := r.hits.AddHits(ctx, truncateDay(r.now()), counts); err != nil {
+		r.countMu.Lock()
+		for id, n := range counts {
+			r.counts[id] += n
+		}
+		r.countMu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Run flushes on an interval until ctx is cancelled.
+func (r *RedactionRunner) Run(ctx context.Context) {
+	flushloop.Run(ctx, r.interval, r.Flush, "redaction hits", r.logger)
+}
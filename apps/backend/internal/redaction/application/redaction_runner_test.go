@@ -0,0 +1,71 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/redaction/domain"
+)
+
+// memRules serves Applicable only; the runner needs nothing else.
+type memRules struct {
+	domain.RuleRepository
+	rules []*domain.Rule
+	err   error
+	loads int
+}
+
+func (r *memRules) Applicable(context.Context, uuid.UUID, uuid.UUID) ([]*domain.Rule, error) {
+	r.loads++
+	return r.rules, r.err
+}
+
+type memHits struct {
+	domain.HitRepository
+	added []domain.Hits
+	err   error
+}
+
+func (h *memHits) AddHits(_ context.Context, _ time.Time, hits domain.Hits) error {
+	if h.err != nil {
+		return h.err
+	}
+	h.added = append(h.added, hits)
+	return nil
+}
+
+func TestRedactionRunnerCountsAndFlushes(t *testing.T) {
+	rule := &domain.Rule{ID: uuid.New(), Name: "mail", Detector: domain.DetectorEmail, Action: domain.ActionMask}
+	rules, hits := &memRules{rules: []*domain.Rule{rule}}, &memHits{err: errors.New("down")}
+	runner := NewRedactionRunner(rules, hits, []byte("k"), time.Minute, time.Minute, zap.NewNop())
+	tenantID, projectID := uuid.New(), uuid.New()
+
+	for range 2 {
+		rec := &domain.Record{Message: "a@b.io and c@d.io"}
+		require.NoError(t, runner.Redact(context.Background(), tenantID, projectID, rec))
+		assert.Equal(t, "[REDACTED:mail] and [REDACTED:mail]", rec.Message)
+	}
+	assert.Equal(t, 1, rules.loads, "rules are cached")
+
+	// A failed flush keeps the counts for the next one.
+	require.Error(t, runner.Flush(context.Background()))
+	hits.err = nil
+	require.NoError(t, runner.Flush(context.Background()))
+	assert.Equal(t, []domain.Hits{{rule.ID: 4}}, hits.added)
+}
+
+func TestRedactionRunnerFailsClosed(t *testing.T) {
+	rules := &memRules{err: errors.New("postgres down")}
+	runner := NewRedactionRunner(rules, nil, []byte("k"), time.Minute, time.Minute, zap.NewNop())
+
+	rec := &domain.Record{Message: "a@b.io"}
+	assert.Error(t, runner.Redact(context.Background(), uuid.New(), uuid.New(), rec))
+	assert.Equal(t, "a@b.io", rec.Message)
+}
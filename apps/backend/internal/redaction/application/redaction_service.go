@@ -0,0 +1,173 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/redaction/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
+)
+
+// maxHitsRangeDays bounds the days of one hits query.
+const maxHitsRangeDays = 366
+
+// ErrInvalidHitsRange is returned for an empty or over-long date range.
+var ErrInvalidHitsRange = errors.New("invalid hits date range")
+
+// RedactionService manages the redaction rules of the caller's tenant. Every
+// method takes the rule scope: a project, or nil for the tenant's rules,
+// which apply to all its projects.
+type RedactionService interface {
+	List(ctx context.Context, projectID *uuid.UUID) ([]*domain.Rule, error)
+	Get(ctx context.Context, projectID *uuid.UUID, id uuid.UUID) (*domain.Rule, error)
+	Create(ctx context.Context, projectID *uuid.UUID, input RuleInput) (*domain.Rule, error)
+	Update(ctx context.Context, projectID *uuid.UUID, id uuid.UUID, input RuleInput) (*domain.Rule, error)
+	Delete(ctx context.Context, projectID *uuid.UUID, id uuid.UUID) error
+	// Hits returns how often a rule fired per UTC day from..to, inclusive.
+	// Today's count lags by up to one flush interval.
+	Hits(ctx context.Context, projectID *uuid.UUID, id uuid.UUID, from, to time.Time) ([]domain.RuleHits, error)
+}
+
+type redactionService struct {
+	rules  domain.RuleRepository
+	hits   domain.HitRepository
+	logger *zap.Logger
+}
+
+func NewRedactionService(rules domain.RuleRepository, hits domain.HitRepository, logger *zap.Logger) RedactionService {
+	return &redactionService{
+		rules:  rules,
+		hits:   hits,
+		logger: logger.Named("redaction_service"),
+	}
+}
+
+func (s *redactionService) List(ctx context.Context, projectID *uuid.UUID) ([]*domain.Rule, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := s.rules.List(ctx, tenantID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if rules == nil {
+		rules = []*domain.Rule{}
+	}
+	return rules, nil
+}
+
+func (s *redactionService) Get(ctx context.Context, projectID *uuid.UUID, id uuid.UUID) (*domain.Rule, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.rules.Get(ctx, tenantID, projectID, id)
+}
+
+func (s *redactionService) Create(ctx context.Context, projectID *uuid.UUID, input RuleInput) (*domain.Rule, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rule := &domain.Rule{TenantID: tenantID, ProjectID: projectID}
+	if userID, ok := middleware.UserUUIDFromContext(ctx); ok {
+		rule.CreatedBy = &userID
+	}
+	applyInput(rule, input)
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.rules.Create(ctx, rule); err != nil {
+		return nil, err
+	}
+	s.logger.Info("redaction rule created",
+		zap.String("tenant_id", tenantID.String()),
+		zap.String("rule_id", rule.ID.String()),
+		zap.String("detector", string(rule.Detector)),
+		zap.String("action", string(rule.Action)),
+	)
+	return rule, nil
+}
+
+func (s *redactionService) Update(ctx context.Context, projectID *uuid.UUID, id uuid.UUID, input RuleInput) (*domain.Rule, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rule, err := s.rules.Get(ctx, tenantID, projectID, id)
+	if err != nil {
+		return nil, err
+	}
+	applyInput(rule, input)
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.rules.Update(ctx, rule); err != nil {
+		return nil, err
+	}
+	s.logger.Info("redaction rule updated",
+		zap.String("tenant_id", tenantID.String()),
+		zap.String("rule_id", rule.ID.String()),
+	)
+	return rule, nil
+}
+
+func (s *redactionService) Delete(ctx context.Context, projectID *uuid.UUID, id uuid.UUID) error {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if err := s.rules.Delete(ctx, tenantID, projectID, id); err != nil {
+		return err
+	}
+	s.logger.Info("redaction rule deleted",
+		zap.String("tenant_id", tenantID.String()),
+		zap.String("rule_id", id.String()),
+	)
+	return nil
+}
+
+func (s *redactionService) Hits(ctx context.Context, projectID *uuid.UUID, id uuid.UUID, from, to time.Time) ([]domain.RuleHits, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	from, to = truncateDay(from), truncateDay(to)
+	if to.Before(from) || to.Sub(from) > maxHitsRangeDays*24*time.Hour {
+		return nil, ErrInvalidHitsRange
+	}
+	// The rule must be in the scope the caller was authorised for.
+	if _, err := s.rules.Get(ctx, tenantID, projectID, id); err != nil {
+		return nil, err
+	}
+	return s.hits.ListHits(ctx, tenantID, id, from, to)
+}
+
+func applyInput(rule *domain.Rule, input RuleInput) {
+	rule.Name = input.Name
+	rule.Detector = input.Detector
+	rule.Pattern = input.Pattern
+	rule.Action = input.Action
+	rule.Fields = input.Fields
+	rule.Enabled = input.Enabled == nil || *input.Enabled
+}
+
+// tenantFromContext returns the caller's tenant as set by AuthMiddleware. The
+// tenant is never taken from request input.
+func tenantFromContext(ctx context.Context) (uuid.UUID, error) {
+	tenantID, ok := middleware.GetTenantUUIDFromContext(ctx)
+	if !ok {
+		return uuid.Nil, domain.ErrTenantRequired
+	}
+	return tenantID, nil
+}
+
+func truncateDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,164 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"regexp"
+)
+
+// valueGroup names the part of a match that is redacted, when not all of it.
+const valueGroup = "value"
+
+// detector finds values in a field. valid, when set, rejects matches that
+// only look like the value, such as numbers failing the Luhn check.
+type detector struct {
+	re *regexp.Regexp
+	// groups are the indexes of the groups named "value".
+	groups []int
+	valid  func(s string, start, end int) bool
+}
+
+func newDetector(re *regexp.Regexp, valid func(s string, start, end int) bool) *detector {
+	d := &detector{re: re, valid: valid}
+	for i, name := range re.SubexpNames() {
+		if name == valueGroup {
+			d.groups = append(d.groups, i)
+		}
+	}
+	return d
+}
+
+var builtinDetectors = map[Detector]*detector{
+	DetectorEmail: newDetector(
+		regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}`),
+		nil,
+	),
+	DetectorCreditCard: newDetector(
+		regexp.MustCompile(`\b\d(?:[ \-]?\d){12,18}\b`),
+		func(s string, start, end int) bool { return luhn(s[start:end]) },
+	),
+	DetectorIPv4: newDetector(
+		regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`),
+		func(s string, start, end int) bool {
+			addr, err := netip.ParseAddr(s[start:end])
+			return err == nil && addr.Is4()
+		},
+	),
+	DetectorIPv6: newDetector(
+		regexp.MustCompile(`(?i)(?:[0-9a-f]{1,4}:|:)(?:[0-9a-f]{0,4}:){1,6}(?:[0-9a-f]{1,4}|:)?(?:(?:\d{1,3}\.){3}\d{1,3})?`),
+		func(s string, start, end int) bool {
+			if start > 0 && (isHex(s[start-1]) || s[start-1] == ':') {
+				return false
+			}
+			addr, err := netip.ParseAddr(s[start:end])
+			return err == nil && addr.Is6()
+		},
+	),
+	DetectorJWT: newDetector(
+		regexp.MustCompile(`\beyJ[A-Za-z0-9_\-]{4,}\.eyJ[A-Za-z0-9_\-]{4,}\.[A-Za-z0-9_\-]*`),
+		nil,
+	),
+	// Access key IDs, and secret access keys where they are assigned.
+	DetectorAWSKey: newDetector(
+		regexp.MustCompile(`\b(?P<value>(?:AKIA|ASIA|ABIA|ACCA|AGPA|AIDA|AIPA|ANPA|ANVA|AROA|APKA)[A-Z0-9]{16})\b`+
+			`|(?i:aws_?secret_?(?:access_?)?key["']?\s*[:=]\s*["']?)(?P<value>[A-Za-z0-9/+=]{40})\b`),
+		nil,
+	),
+	DetectorPhone: newDetector(
+		regexp.MustCompile(`(?:\+\d{1,3}[ .\-]?)?(?:\(\d{1,4}\)[ .\-]?)?\d{2,4}(?:[ .\-]?\d{2,4}){2,4}`),
+		func(s string, start, end int) bool {
+			if (start > 0 && isWord(s[start-1])) || (end < len(s) && isWord(s[end])) {
+				return false
+			}
+			n := digits(s[start:end])
+			return n >= 10 && n <= 15
+		},
+	),
+}
+
+// detector returns the detector of the rule, compiling custom patterns.
+func (r *Rule) detector() (*detector, error) {
+	if r.Detector != DetectorCustom {
+		if r.Pattern != "" {
+			return nil, errors.New("pattern is only allowed for the custom detector")
+		}
+		d, ok := builtinDetectors[r.Detector]
+		if !ok {
+			return nil, fmt.Errorf("unknown detector %q", r.Detector)
+		}
+		return d, nil
+	}
+	if r.Pattern == "" {
+		return nil, errors.New("pattern is required for the custom detector")
+	}
+	if len(r.Pattern) > MaxPatternLength {
+		return nil, fmt.Errorf("pattern is longer than %d bytes", MaxPatternLength)
+	}
+	re, err := regexp.Compile(r.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("pattern: %v", err)
+	}
+	if re.MatchString("") {
+		return nil, errors.New("pattern matches the empty string")
+	}
+	return newDetector(re, nil), nil
+}
+
+// spans returns the [start, end) ranges of v to redact.
+func (d *detector) spans(v string) [][2]int {
+	var out [][2]int
+	for _, m := range d.re.FindAllStringSubmatchIndex(v, -1) {
+		start, end := m[0], m[1]
+		for _, g := range d.groups {
+			if m[2*g] >= 0 {
+				start, end = m[2*g], m[2*g+1]
+				break
+			}
+		}
+		if start < 0 || start == end || (d.valid != nil && !d.valid(v, start, end)) {
+			continue
+		}
+		out = append(out, [2]int{start, end})
+	}
+	return out
+}
+
+// luhn reports whether the digits of s, ignoring separators, form a card
+// number with a valid check digit.
+func luhn(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && n <= 19 && sum%10 == 0
+}
+
+func digits(s string) int {
+	n := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] >= '0' && s[i] <= '9' {
+			n++
+		}
+	}
+	return n
+}
+
+func isHex(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func isWord(c byte) bool {
+	return isHex(c) || (c >= 'g' && c <= 'z') || (c >= 'G' && c <= 'Z') || c == '_'
+}
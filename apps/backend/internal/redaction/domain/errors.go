@@ -0,0 +1,16 @@
+package domain
+
+import "errors"
+
+// Domain errors. Service / transport layers compare against these with
+// errors.Is to map them onto HTTP status codes.
+var (
+	ErrTenantRequired  = errors.New("tenant is required")
+	ErrProjectNotFound = errors.New("project not found")
+	ErrRuleNotFound    = errors.New("redaction rule not found")
+	ErrNameTaken       = errors.New("a redaction rule with this name already exists in this scope")
+	ErrTooManyRules    = errors.New("too many redaction rules in this scope")
+	// ErrInvalidRule wraps every definition error; the message says what is
+	// wrong.
+	ErrInvalidRule = errors.New("invalid redaction rule")
+)
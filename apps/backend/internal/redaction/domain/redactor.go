@@ -0,0 +1,198 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Field paths of a Record, as named in Rule.Fields.
+const (
+	FieldMessage     = "message"
+	FieldUserID      = "user_id"
+	attributesPrefix = "attributes."
+	tagsPrefix       = "tags."
+	allAttributes    = "attributes.*"
+	allTags          = "tags.*"
+)
+
+// hashLength is the number of hex digits kept of a keyed hash.
+const hashLength = 16
+
+// Record is the part of a log redaction applies to: the free-text fields
+// where sensitive values end up.
+type Record struct {
+	Message    string
+	UserID     string
+	Attributes map[string]string
+	Tags       map[string]string
+}
+
+// Hits counts the values each rule redacted, by rule ID.
+type Hits map[uuid.UUID]int64
+
+// Redactor applies a list of rules. It is safe for concurrent use.
+type Redactor struct {
+	rules []compiledRule
+	key   []byte
+}
+
+type compiledRule struct {
+	id     uuid.UUID
+	mask   string
+	action Action
+	det    *detector
+	scope  fieldScope
+}
+
+// fieldScope is the set of fields a rule applies to.
+type fieldScope struct {
+	all, message, userID, attributes, tags bool
+	attributeKeys, tagKeys                 map[string]bool
+}
+
+func newFieldScope(fields []string) fieldScope {
+	s := fieldScope{all: len(fields) == 0}
+	for _, f := range fields {
+		switch f {
+		case FieldMessage:
+			s.message = true
+		case FieldUserID:
+			s.userID = true
+		case allAttributes:
+			s.attributes = true
+		case allTags:
+			s.tags = true
+		default:
+			if key, ok := strings.CutPrefix(f, attributesPrefix); ok {
+				if s.attributeKeys == nil {
+					s.attributeKeys = map[string]bool{}
+				}
+				s.attributeKeys[key] = true
+			} else if key, ok := strings.CutPrefix(f, tagsPrefix); ok {
+				if s.tagKeys == nil {
+					s.tagKeys = map[string]bool{}
+				}
+				s.tagKeys[key] = true
+			}
+		}
+	}
+	return s
+}
+
+// NewRedactor compiles rules, which run in order. key makes hashes keyed:
+// without it, hashes of guessable values such as emails could be reversed.
+func NewRedactor(rules []*Rule, key []byte) (*Redactor, error) {
+	r := &Redactor{rules: make([]compiledRule, 0, len(rules)), key: key}
+	for _, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return nil, fmt.Errorf("rule %q: %w", rule.Name, err)
+		}
+		det, _ := rule.detector()
+		r.rules = append(r.rules, compiledRule{
+			id:     rule.ID,
+			mask:   "[REDACTED:" + rule.Name + "]",
+			action: rule.Action,
+			det:    det,
+			scope:  newFieldScope(rule.Fields),
+		})
+	}
+	return r, nil
+}
+
+// Empty reports whether the redactor has no rule.
+func (r *Redactor) Empty() bool {
+	return r == nil || len(r.rules) == 0
+}
+
+// Redact applies the rules to rec in place and adds what each redacted to
+// hits, which may be nil. Hashes are keyed by tenant as well, so equal
+// values only join within a tenant.
+func (r *Redactor) Redact(tenantID uuid.UUID, rec *Record, hits Hits) {
+	if r.Empty() {
+		return
+	}
+	for i := range r.rules {
+		rule := &r.rules[i]
+		var n int64
+		if rule.scope.all || rule.scope.message {
+			if v, c := rule.redactWhole(r, tenantID, rec.Message); c > 0 {
+				rec.Message, n = v, n+c
+			}
+		}
+		if rule.scope.all || rule.scope.userID {
+			if v, c := rule.redactWhole(r, tenantID, rec.UserID); c > 0 {
+				rec.UserID, n = v, n+c
+			}
+		}
+		n += rule.redactMap(r, tenantID, rec.Attributes, rule.scope.all || rule.scope.attributes, rule.scope.attributeKeys)
+		n += rule.redactMap(r, tenantID, rec.Tags, rule.scope.all || rule.scope.tags, rule.scope.tagKeys)
+		if n > 0 && hits != nil {
+			hits[rule.id] += n
+		}
+	}
+}
+
+// redactWhole redacts a field that cannot be removed: drop masks it whole.
+func (c *compiledRule) redactWhole(r *Redactor, tenantID uuid.UUID, v string) (string, int64) {
+	out, n := c.redact(r, tenantID, v)
+	if n > 0 && c.action == ActionDrop {
+		out = c.mask
+	}
+	return out, n
+}
+
+func (c *compiledRule) redactMap(r *Redactor, tenantID uuid.UUID, m map[string]string, all bool, keys map[string]bool) int64 {
+	var total int64
+	for k, v := range m {
+		if !all && !keys[k] {
+			continue
+		}
+		out, n := c.redact(r, tenantID, v)
+		if n == 0 {
+			continue
+		}
+		total += n
+		if c.action == ActionDrop {
+			delete(m, k)
+		} else {
+			m[k] = out
+		}
+	}
+	return total
+}
+
+// redact replaces the values the rule detects in v and counts them.
+func (c *compiledRule) redact(r *Redactor, tenantID uuid.UUID, v string) (string, int64) {
+	if v == "" {
+		return v, 0
+	}
+	spans := c.det.spans(v)
+	if len(spans) == 0 || c.action == ActionDrop {
+		return v, int64(len(spans))
+	}
+	var b strings.Builder
+	last := 0
+	for _, s := range spans {
+		b.WriteString(v[last:s[0]])
+		if c.action == ActionHash {
+			b.WriteString(r.hash(tenantID, v[s[0]:s[1]]))
+		} else {
+			b.WriteString(c.mask)
+		}
+		last = s[1]
+	}
+	b.WriteString(v[last:])
+	return b.String(), int64(len(spans))
+}
+
+func (r *Redactor) hash(tenantID uuid.UUID, value string) string {
+	mac := hmac.New(sha256.New, r.key)
+	mac.Write(tenantID[:])
+	mac.Write([]byte(value))
+	return "[HASH:" + hex.EncodeToString(mac.Sum(nil))[:hashLength] + "]"
+}
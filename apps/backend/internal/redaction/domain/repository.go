@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RuleRepository stores redaction rules in Postgres. A rule's scope is its
+// tenant and project, nil for the tenant's own rules; a project of another
+// tenant behaves like a missing one (ErrProjectNotFound), a rule of another
+// scope like a missing one (ErrRuleNotFound).
+type RuleRepository interface {
+	// List returns the rules of a scope, oldest first.
+	List(ctx context.Context, tenantID uuid.UUID, projectID *uuid.UUID) ([]*Rule, error)
+	// Applicable returns the enabled rules for the logs of a project in the
+	// order they run: the tenant's, then the project's, each oldest first.
+	Applicable(ctx context.Context, tenantID, projectID uuid.UUID) ([]*Rule, error)
+	Get(ctx context.Context, tenantID uuid.UUID, projectID *uuid.UUID, id uuid.UUID) (*Rule, error)
+	// Create stores r and sets its ID and timestamps.
+	Create(ctx context.Context, r *Rule) error
+	Update(ctx context.Context, r *Rule) error
+	Delete(ctx context.Context, tenantID uuid.UUID, projectID *uuid.UUID, id uuid.UUID) error
+}
+
+// HitRepository keeps how often rules fired, per UTC day.
+type HitRepository interface {
+	// AddHits adds hits to the counters of day. Rules deleted meanwhile are
+	// skipped.
+	AddHits(ctx context.Context, day time.Time, hits Hits) error
+	// ListHits returns the counters of a rule of the tenant for the UTC days
+	// from..to, inclusive, skipping days without hits.
+	ListHits(ctx context.Context, tenantID, ruleID uuid.UUID, from, to time.Time) ([]RuleHits, error)
+}
@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxRulesPerScope bounds the rules of a tenant, and of each project.
+	MaxRulesPerScope = 50
+	// MaxPatternLength bounds custom patterns.
+	MaxPatternLength = 1024
+	// MaxFields bounds the fields a rule is restricted to.
+	MaxFields = 32
+)
+
+// Detector finds the values a rule redacts.
+type Detector string
+
+const (
+	DetectorEmail      Detector = "email"
+	DetectorCreditCard Detector = "credit_card"
+	DetectorIPv4       Detector = "ipv4"
+	DetectorIPv6       Detector = "ipv6"
+	DetectorJWT        Detector = "jwt"
+	DetectorAWSKey     Detector = "aws_key"
+	DetectorPhone      Detector = "phone"
+	// DetectorCustom matches Rule.Pattern.
+	DetectorCustom Detector = "custom"
+)
+
+// Action is what a rule does to the values it detects.
+type Action string
+
+const (
+	// ActionMask replaces the value with [REDACTED:<rule name>].
+	ActionMask Action = "mask"
+	// ActionHash replaces the value with a keyed hash, the same for equal
+	// values of a tenant, so logs can still be joined on it.
+	ActionHash Action = "hash"
+	// ActionDrop removes the attribute or tag holding the value. The message
+	// and user_id cannot be removed and are masked whole instead.
+	ActionDrop Action = "drop"
+)
+
+// Rule redacts sensitive values from logs before they are stored or
+// embedded. Rules of a tenant (ProjectID nil) apply to every project and
+// run before the project's own rules.
+type Rule struct {
+	ID        uuid.UUID  `json:"id"`
+	TenantID  uuid.UUID  `json:"-"`
+	ProjectID *uuid.UUID `json:"project_id,omitempty"`
+	Name      string     `json:"name"`
+	Detector  Detector   `json:"detector"`
+	// Pattern is the regular expression of custom rules. When it has a
+	// group named "value", only that group is redacted.
+	Pattern string `json:"pattern,omitempty"`
+	Action  Action `json:"action"`
+	// Fields restricts the rule to some fields (see Record); empty means
+	// every field.
+	Fields    []string   `json:"fields"`
+	Enabled   bool       `json:"enabled"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// RuleHits is how often a rule fired on one UTC day.
+type RuleHits struct {
+	RuleID uuid.UUID `json:"rule_id"`
+	Day    time.Time `json:"day"`
+	Hits   int64     `json:"hits"`
+}
+
+var (
+	ruleName = regexp.MustCompile(`^[a-z0-9][a-z0-9_.\-]{0,63}$`)
+	fieldKey = regexp.MustCompile(`^[A-Za-z0-9_.\-@/]{1,128}$`)
+)
+
+// Validate checks a rule's definition. Every error wraps ErrInvalidRule.
+func (r *Rule) Validate() error {
+	if !ruleName.MatchString(r.Name) {
+		return fmt.Errorf("%w: name must be 1-64 lowercase letters, digits, '_', '.' or '-'", ErrInvalidRule)
+	}
+	switch r.Action {
+	case ActionMask, ActionHash, ActionDrop:
+	default:
+		return fmt.Errorf("%w: unknown action %q", ErrInvalidRule, r.Action)
+	}
+	if len(r.Fields) > MaxFields {
+		return fmt.Errorf("%w: more than %d fields", ErrInvalidRule, MaxFields)
+	}
+	for _, f := range r.Fields {
+		if !ValidField(f) {
+			return fmt.Errorf("%w: invalid field %q", ErrInvalidRule, f)
+		}
+	}
+	if _, err := r.detector(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidRule, err)
+	}
+	return nil
+}
+
+// ValidField reports whether path names fields a rule may be restricted to:
+// message, user_id, attributes.<key>, tags.<key>, or every attribute or tag
+// with attributes.* and tags.*.
+func ValidField(path string) bool {
+	switch path {
+	case FieldMessage, FieldUserID, allAttributes, allTags:
+		return true
+	}
+	if key, ok := strings.CutPrefix(path, attributesPrefix); ok {
+		return fieldKey.MatchString(key)
+	}
+	if key, ok := strings.CutPrefix(path, tagsPrefix); ok {
+		return fieldKey.MatchString(key)
+	}
+	return false
+}
@@ -0,0 +1,262 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/redaction/domain"
+)
+
+const pgUniqueViolation = "23505"
+
+// RuleRepository implements domain.RuleRepository and domain.HitRepository.
+type RuleRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewRuleRepository(db *pgxpool.Pool) *RuleRepository {
+	return &RuleRepository{db: db}
+}
+
+const selectRule = `
+	SELECT id, tenant_id, project_id, name, detector, pattern, action, fields,
+	       enabled, created_by, created_at, updated_at
+	FROM redaction_rules
+`
+
+func (r *RuleRepository) List(ctx context.Context, tenantID uuid.UUID, projectID *uuid.UUID) ([]*domain.Rule, error) {
+	if err := r.projectExists(ctx, tenantID, projectID); err != nil {
+		return nil, err
+	}
+	return r.query(ctx, selectRule+`
+		WHERE tenant_id = $1 AND project_id IS NOT DISTINCT FROM $2
+		ORDER BY created_at, id
+	`, tenantID, projectID)
+}
+
+func (r *RuleRepository) Applicable(ctx context.Context, tenantID, projectID uuid.UUID) ([]*domain.Rule, error) {
+	return r.query(ctx, selectRule+`
+		WHERE tenant_id = $1 AND (project_id IS NULL OR project_id = $2) AND enabled
+		ORDER BY project_id NULLS FIRST, created_at, id
+	`, tenantID, projectID)
+}
+
+func (r *RuleRepository) Get(ctx context.Context, tenantID uuid.UUID, projectID *uuid.UUID, id uuid.UUID) (*domain.Rule, error) {
+	if err := r.projectExists(ctx, tenantID, projectID); err != nil {
+		return nil, err
+	}
+	rule, err := scanRule(r.db.QueryRow(ctx, selectRule+`
+		WHERE id = $1 AND tenant_id = $2 AND project_id IS NOT DISTINCT FROM $3
+	`, id, tenantID, projectID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrRuleNotFound
+	}
+	return rule, err
+}
+
+func (r *RuleRepository) Create(ctx context.Context, rule *domain.Rule) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Locking the scope serialises creations, so the count holds.
+	if err := lockScope(ctx, tx, rule.TenantID, rule.ProjectID); err != nil {
+		return err
+	}
+	var count int
+	if err := tx.QueryRow(ctx, `
+		SELECT count(*) FROM redaction_rules
+		WHERE tenant_id = $1 AND project_id IS NOT DISTINCT FROM $2
+	`, rule.TenantID, rule.ProjectID).Scan(&count); err != nil {
+		return err
+	}
+	if count >= domain.MaxRulesPerScope {
+		return domain.ErrTooManyRules
+	}
+
+	now := time.Now().UTC()
+	rule.ID, rule.CreatedAt, rule.UpdatedAt = uuid.New(), now, now
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO redaction_rules
+			(id, tenant_id, project_id, name, detector, pattern, action, fields, enabled, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $11)
+	`, rule.ID, rule.TenantID, rule.ProjectID, rule.Name, rule.Detector, rule.Pattern, rule.Action,
+		fieldsOrEmpty(rule.Fields), rule.Enabled, rule.CreatedBy, now); err != nil {
+		return mapUniqueViolation(err)
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *RuleRepository) Update(ctx context.Context, rule *domain.Rule) error {
+	rule.UpdatedAt = time.Now().UTC()
+	tag, err := r.db.Exec(ctx, `
+		UPDATE redaction_rules
+		SET name = $4, detector = $5, pattern = $6, action = $7, fields = $8, enabled = $9, updated_at = $10
+		WHERE id = $1 AND tenant_id = $2 AND project_id IS NOT DISTINCT FROM $3
+	`, rule.ID, rule.TenantID, rule.ProjectID, rule.Name, rule.Detector, rule.Pattern, rule.Action,
+		fieldsOrEmpty(rule.Fields), rule.Enabled, rule.UpdatedAt)
+	if err != nil {
+		return mapUniqueViolation(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrRuleNotFound
+	}
+	return nil
+}
+
+func (r *RuleRepository) Delete(ctx context.Context, tenantID uuid.UUID, projectID *uuid.UUID, id uuid.UUID) error {
+	if err := r.projectExists(ctx, tenantID, projectID); err != nil {
+		return err
+	}
+	tag, err := r.db.Exec(ctx, `
+		DELETE FROM redaction_rules
+		WHERE id = $1 AND tenant_id = $2 AND project_id IS NOT DISTINCT FROM $3
+	`, id, tenantID, projectID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrRuleNotFound
+	}
+	return nil
+}
+
+func (r *RuleRepository) AddHits(ctx context.Context, day time.Time, hits domain.Hits) error {
+	if len(hits) == 0 {
+		return nil
+	}
+	// Selecting the rule skips those deleted since the hits were counted.
+	const query = `
+		INSERT INTO redaction_rule_hits (rule_id, day, hits)
+		SELECT id, $2, $3 FROM redaction_rules WHERE id = $1
+		ON CONFLICT (rule_id, day) DO UPDATE
+		SET hits = redaction_rule_hits.hits + EXCLUDED.hits
+	`
+	batch := &pgx.Batch{}
+	for id, n := range hits {
+		batch.Queue(query, id, day, n)
+	}
+	return r.db.SendBatch(ctx, batch).Close()
+}
+
+func (r *RuleRepository) ListHits(ctx context.Context, tenantID, ruleID uuid.UUID, from, to time.Time) ([]domain.RuleHits, error) {
+	var one int
+	err := r.db.QueryRow(ctx, `SELECT 1 FROM redaction_rules WHERE id = $1 AND tenant_id = $2`, ruleID, tenantID).Scan(&one)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrRuleNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT rule_id, day, hits FROM redaction_rule_hits
+		WHERE rule_id = $1 AND day BETWEEN $2 AND $3
+		ORDER BY day DESC
+	`, ruleID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]domain.RuleHits, 0)
+	for rows.Next() {
+		var h domain.RuleHits
+		if err := rows.Scan(&h.RuleID, &h.Day, &h.Hits); err != nil {
+			return nil, err
+		}
+		out = append(out, h)
+	}
+	return out, rows.Err()
+}
+
+func (r *RuleRepository) query(ctx context.Context, query string, args ...any) ([]*domain.Rule, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []*domain.Rule
+	for rows.Next() {
+		rule, err := scanRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+// projectExists checks the project of a project scope; the tenant scope
+// always exists.
+func (r *RuleRepository) projectExists(ctx context.Context, tenantID uuid.UUID, projectID *uuid.UUID) error {
+	if projectID == nil {
+		return nil
+	}
+	var one int
+	err := r.db.QueryRow(ctx, `
+		SELECT 1 FROM projects WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL
+	`, *projectID, tenantID).Scan(&one)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrProjectNotFound
+	}
+	return err
+}
+
+// lockScope locks the project of a project scope, or the tenant.
+func lockScope(ctx context.Context, tx pgx.Tx, tenantID uuid.UUID, projectID *uuid.UUID) error {
+	var one int
+	var err error
+	if projectID != nil {
+		err = tx.QueryRow(ctx, `
+			SELECT 1 FROM projects
+			WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL
+			FOR UPDATE
+		`, *projectID, tenantID).Scan(&one)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrProjectNotFound
+		}
+		return err
+	}
+	err = tx.QueryRow(ctx, `SELECT 1 FROM tenants WHERE id = $1 FOR UPDATE`, tenantID).Scan(&one)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrTenantRequired
+	}
+	return err
+}
+
+func scanRule(row pgx.Row) (*domain.Rule, error) {
+	rule := &domain.Rule{}
+	if err := row.Scan(
+		&rule.ID, &rule.TenantID, &rule.ProjectID, &rule.Name, &rule.Detector, &rule.Pattern,
+		&rule.Action, &rule.Fields, &rule.Enabled, &rule.CreatedBy, &rule.CreatedAt, &rule.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func fieldsOrEmpty(fields []string) []string {
+	if fields == nil {
+		return []string{}
+	}
+	return fields
+}
+
+// mapUniqueViolation converts a PostgreSQL unique-violation into a domain error.
+func mapUniqueViolation(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return domain.ErrNameTaken
+	}
+	return err
+}
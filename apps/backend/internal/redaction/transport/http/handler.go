@@ -0,0 +1,261 @@
+package http
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/redaction/application"
+	"github.com/indalyadav56/logify/apps/backend/internal/redaction/domain"
+	"github.com/indalyadav56/logify/apps/backend/pkg/response"
+	"github.com/indalyadav56/logify/apps/backend/pkg/validator"
+)
+
+const hitsDateLayout = "2006-01-02"
+
+// RedactionHandler serves the tenant's rules under /v1/redaction/rules and a
+// project's under /v1/projects/{id}/redaction/rules; the scope is the
+// project in the path, if any.
+type RedactionHandler struct {
+	service application.RedactionService
+}
+
+func NewRedactionHandler(service application.RedactionService) *RedactionHandler {
+	return &RedactionHandler{service: service}
+}
+
+// List returns the redaction rules of a scope.
+// @Summary      List redaction rules
+// @Description  Return the tenant's redaction rules, or a project's with /v1/projects/{id}/redaction/rules. Tenant rules apply to every project and run first.
+// @Tags         redaction
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.APIResponse "Redaction rules retrieved successfully"
+// @Failure      404  {object}  response.APIResponse "Project not found"
+// @Failure      500  {object}  response.APIResponse "Internal server error"
+// @Router       /v1/redaction/rules [get]
+func (h *RedactionHandler) List(c *gin.Context) {
+	projectID, ok := scope(c)
+	if !ok {
+		return
+	}
+
+	rules, err := h.service.List(c.Request.Context(), projectID)
+	if err != nil {
+		h.writeError(c, err, "Failed to retrieve redaction rules")
+		return
+	}
+	response.OK(c, "Redaction rules retrieved successfully", rules)
+}
+
+// Create adds a redaction rule.
+// @Summary      Create redaction rule
+// @Description  Redact the values a detector finds (email, credit_card, ipv4, ipv6, jwt, aws_key, phone, or a custom pattern) by masking, keyed hashing, or dropping the field. Workers apply it within a minute, before logs are stored or embedded.
+// @Tags         redaction
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      application.RuleInput  true  "Redaction rule"
+// @Success      201      {object}  response.APIResponse "Redaction rule created successfully"
+// @Failure      400      {object}  response.APIResponse "Invalid request"
+// @Failure      404      {object}  response.APIResponse "Project not found"
+// @Failure      409      {object}  response.APIResponse "Name already taken"
+// @Failure      500      {object}  response.APIResponse "Internal server error"
+// @Router       /v1/redaction/rules [post]
+func (h *RedactionHandler) Create(c *gin.Context) {
+	projectID, ok := scope(c)
+	if !ok {
+		return
+	}
+
+	var input application.RuleInput
+	if !validator.ValidateRequest(c, &input) {
+		return
+	}
+
+	rule, err := h.service.Create(c.Request.Context(), projectID, input)
+	if err != nil {
+		h.writeError(c, err, "Failed to create redaction rule")
+		return
+	}
+	response.Created(c, "Redaction rule created successfully", rule)
+}
+
+// Get returns a redaction rule.
+// @Summary      Get redaction rule
+// @Tags         redaction
+// @Produce      json
+// @Security     BearerAuth
+// @Param        ruleId  path      string  true  "Rule ID (UUID)"
+// @Success      200     {object}  response.APIResponse "Redaction rule retrieved successfully"
+// @Failure      400     {object}  response.APIResponse "Invalid id format"
+// @Failure      404     {object}  response.APIResponse "Rule not found"
+// @Failure      500     {object}  response.APIResponse "Internal server error"
+// @Router       /v1/redaction/rules/{ruleId} [get]
+func (h *RedactionHandler) Get(c *gin.Context) {
+	projectID, id, ok := scopeAndRule(c)
+	if !ok {
+		return
+	}
+
+	rule, err := h.service.Get(c.Request.Context(), projectID, id)
+	if err != nil {
+		h.writeError(c, err, "Failed to retrieve redaction rule")
+		return
+	}
+	response.OK(c, "Redaction rule retrieved successfully", rule)
+}
+
+// Update replaces a redaction rule.
+// @Summary      Update redaction rule
+// @Tags         redaction
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        ruleId   path      string                 true  "Rule ID (UUID)"
+// @Param        request  body      application.RuleInput  true  "Redaction rule"
+// @Success      200      {object}  response.APIResponse "Redaction rule updated successfully"
+// @Failure      400      {object}  response.APIResponse "Invalid request"
+// @Failure      404      {object}  response.APIResponse "Rule not found"
+// @Failure      409      {object}  response.APIResponse "Name already taken"
+// @Failure      500      {object}  response.APIResponse "Internal server error"
+// @Router       /v1/redaction/rules/{ruleId} [put]
+func (h *RedactionHandler) Update(c *gin.Context) {
+	projectID, id, ok := scopeAndRule(c)
+	if !ok {
+		return
+	}
+
+	var input application.RuleInput
+	if !validator.ValidateRequest(c, &input) {
+		return
+	}
+
+	rule, err := h.service.Update(c.Request.Context(), projectID, id, input)
+	if err != nil {
+		h.writeError(c, err, "Failed to update redaction rule")
+		return
+	}
+	response.OK(c, "Redaction rule updated successfully", rule)
+}
+
+// Delete removes a redaction rule and its counters.
+// @Summary      Delete redaction rule
+// @Tags         redaction
+// @Security     BearerAuth
+// @Param        ruleId  path  string  true  "Rule ID (UUID)"
+// @Success      204  "Redaction rule deleted"
+// @Failure      400  {object}  response.APIResponse "Invalid id format"
+// @Failure      404  {object}  response.APIResponse "Rule not found"
+// @Failure      500  {object}  response.APIResponse "Internal server error"
+// @Router       /v1/redaction/rules/{ruleId} [delete]
+func (h *RedactionHandler) Delete(c *gin.Context) {
+	projectID, id, ok := scopeAndRule(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), projectID, id); err != nil {
+		h.writeError(c, err, "Failed to delete redaction rule")
+		return
+	}
+	response.NoContent(c)
+}
+
+// Hits returns how often a redaction rule fired.
+// @Summary      Redaction rule hits
+// @Description  Return how many values the rule redacted per UTC day. Today's count lags by up to a minute.
+// @Tags         redaction
+// @Produce      json
+// @Security     BearerAuth
+// @Param        ruleId  path      string  true   "Rule ID (UUID)"
+// @Param        from    query     string  false  "First day, YYYY-MM-DD (default: 30 days ago)"
+// @Param        to      query     string  false  "Last day, YYYY-MM-DD (default: today)"
+// @Success      200     {object}  response.APIResponse "Redaction rule hits retrieved successfully"
+// @Failure      400     {object}  response.APIResponse "Invalid request"
+// @Failure      404     {object}  response.APIResponse "Rule not found"
+// @Failure      500     {object}  response.APIResponse "Internal server error"
+// @Router       /v1/redaction/rules/{ruleId}/hits [get]
+func (h *RedactionHandler) Hits(c *gin.Context) {
+	projectID, id, ok := scopeAndRule(c)
+	if !ok {
+		return
+	}
+	today := time.Now().UTC()
+	from, to := today.AddDate(0, 0, -30), today
+	var err error
+	if v := c.Query("from"); v != "" {
+		if from, err = time.Parse(hitsDateLayout, v); err != nil {
+			response.BadRequest(c, "from must be YYYY-MM-DD")
+			return
+		}
+	}
+	if v := c.Query("to"); v != "" {
+		if to, err = time.Parse(hitsDateLayout, v); err != nil {
+			response.BadRequest(c, "to must be YYYY-MM-DD")
+			return
+		}
+	}
+
+	hits, err := h.service.Hits(c.Request.Context(), projectID, id, from, to)
+	if err != nil {
+		h.writeError(c, err, "Failed to retrieve redaction rule hits")
+		return
+	}
+	response.OK(c, "Redaction rule hits retrieved successfully", hits)
+}
+
+// writeError maps domain errors to HTTP responses with a consistent envelope.
+func (h *RedactionHandler) writeError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrProjectNotFound):
+		response.NotFound(c, "Project not found")
+	case errors.Is(err, domain.ErrRuleNotFound):
+		response.NotFound(c, "Redaction rule not found")
+	case errors.Is(err, domain.ErrTenantRequired):
+		response.Unauthorized(c, "Tenant ID is required")
+	case errors.Is(err, domain.ErrNameTaken):
+		response.Conflict(c, err.Error())
+	case errors.Is(err, domain.ErrInvalidRule),
+		errors.Is(err, domain.ErrTooManyRules),
+		errors.Is(err, application.ErrInvalidHitsRange):
+		response.BadRequest(c, err.Error())
+	default:
+		response.InternalServerError(c, fallback)
+	}
+}
+
+// scope returns the project in the path, or nil on the tenant routes.
+func scope(c *gin.Context) (*uuid.UUID, bool) {
+	if c.Param("id") == "" {
+		return nil, true
+	}
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return nil, false
+	}
+	return &id, true
+}
+
+func scopeAndRule(c *gin.Context) (*uuid.UUID, uuid.UUID, bool) {
+	projectID, ok := scope(c)
+	if !ok {
+		return nil, uuid.Nil, false
+	}
+	id, ok := parseUUIDParam(c, "ruleId")
+	if !ok {
+		return nil, uuid.Nil, false
+	}
+	return projectID, id, true
+}
+
+func parseUUIDParam(c *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		response.BadRequest(c, "Invalid "+name+" format")
+		return uuid.Nil, false
+	}
+	return id, true
+}
@@ -0,0 +1,36 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+
+	roleDomain "github.com/indalyadav56/logify/apps/backend/internal/role/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
+)
+
+// RegisterRoutes mounts the redaction routes: the tenant's rules, which
+// apply to every project, and each project's own. The group is expected to
+// be already authenticated (see di.RegisterAllRoutes).
+func RegisterRoutes(router *gin.RouterGroup, handler *RedactionHandler, authz *middleware.Authorizer) {
+	tenant := router.Group("/v1/redaction/rules")
+	mountRules(tenant,
+		authz.RequirePermission(roleDomain.ResourceTenant, roleDomain.ActionRead),
+		authz.RequirePermission(roleDomain.ResourceTenant, roleDomain.ActionWrite),
+		handler,
+	)
+
+	project := router.Group("/v1/projects/:id/redaction/rules")
+	mountRules(project,
+		authz.RequireProjectPermission("id", roleDomain.ResourceProject, roleDomain.ActionRead),
+		authz.RequireProjectPermission("id", roleDomain.ResourceProject, roleDomain.ActionWrite),
+		handler,
+	)
+}
+
+func mountRules(g *gin.RouterGroup, read, write gin.HandlerFunc, handler *RedactionHandler) {
+	g.GET("", read, handler.List)
+	g.POST("", write, handler.Create)
+	g.GET("/:ruleId", read, handler.Get)
+	g.PUT("/:ruleId", write, handler.Update)
+	g.DELETE("/:ruleId", write, handler.Delete)
+	g.GET("/:ruleId/hits", read, handler.Hits)
+}
@@ -0,0 +1,37 @@
+package application
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/retention/domain"
+)
+
+// PolicyInput sets a project's retention. RetentionDays applies to every
+// level without a rule in Levels; zero keeps the tenant default.
+type PolicyInput struct {
+	RetentionDays int            `json:"retention_days,omitempty" validate:"omitempty,min=1,max=3650"`
+	Levels        map[string]int `json:"levels,omitempty"`
+}
+
+// PolicyOutput is a project's policy as set and as it resolves.
+type PolicyOutput struct {
+	ProjectID uuid.UUID `json:"project_id"`
+	// RetentionDays is the project default; zero when inherited.
+	RetentionDays int            `json:"retention_days"`
+	Levels        map[string]int `json:"levels"`
+	// TenantRetentionDays is the quota: the default and the ceiling.
+	TenantRetentionDays int `json:"tenant_retention_days"`
+	// Effective is the retention of every level after defaults.
+	Effective map[string]int `json:"effective"`
+	UpdatedAt *time.Time     `json:"updated_at,omitempty"`
+}
+
+// PreviewOutput is what a policy would expire of the logs stored now.
+type PreviewOutput struct {
+	Policy     PolicyOutput         `json:"policy"`
+	Levels     []domain.LevelImpact `json:"levels"`
+	TotalRows  uint64               `json:"total_rows"`
+	TotalBytes uint64               `json:"total_bytes"`
+}
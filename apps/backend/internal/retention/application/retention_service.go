@@ -0,0 +1,249 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/retention/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
+	tenantDomain "github.com/indalyadav56/logify/apps/backend/internal/tenant/domain"
+	"github.com/indalyadav56/logify/apps/backend/pkg/ttlcache"
+)
+
+const (
+	// defaultPolicyCacheTTL bounds how long replicas that did not see a
+	// policy change keep stamping new logs with the old retention.
+	defaultPolicyCacheTTL = time.Minute
+
+	// maxPolicyCacheEntries caps memory; the cache is simply reset when full.
+	maxPolicyCacheEntries = 10_000
+)
+
+// QuotaSource supplies the tenant quota, whose retention is the default and
+// the ceiling of every policy. It is satisfied by
+// tenant/application.TenantService.
+type QuotaSource interface {
+	GetQuota(ctx context.Context, tenantID uuid.UUID) (*tenantDomain.Quota, error)
+}
+
+type RetentionService interface {
+	GetPolicy(ctx context.Context, projectID uuid.UUID) (*PolicyOutput, error)
+	// SetPolicy replaces the project's policy and re-applies it to the logs
+	// already stored, which may delete them.
+	SetPolicy(ctx context.Context, projectID uuid.UUID, input PolicyInput) (*PolicyOutput, error)
+	// ResetPolicy drops the project's policy in favour of the tenant default.
+	ResetPolicy(ctx context.Context, projectID uuid.UUID) (*PolicyOutput, error)
+	// Preview reports what SetPolicy with input would delete right now.
+	Preview(ctx context.Context, projectID uuid.UUID, input PolicyInput) (*PreviewOutput, error)
+
+	// RetentionDays returns how long a new log of level is kept. It is on
+	// the ingest path and cached per project.
+	RetentionDays(ctx context.Context, tenantID, projectID uuid.UUID, level string) (int, error)
+}
+
+type retentionService struct {
+	repo   domain.PolicyRepository
+	store  domain.LogStore
+	quotas QuotaSource
+	logger *zap.Logger
+	now    func() time.Time
+	cache  *ttlcache.Cache[uuid.UUID, *domain.Policy]
+}
+
+func NewRetentionService(repo domain.PolicyRepository, store domain.LogStore, quotas QuotaSource, logger *zap.Logger) RetentionService {
+	return &retentionService{
+		repo:   repo,
+		store:  store,
+		quotas: quotas,
+		logger: logger.Named("retention_service"),
+		now:    time.Now,
+		cache:  ttlcache.New[uuid.UUID, *domain.Policy](defaultPolicyCacheTTL, maxPolicyCacheEntries),
+	}
+}
+
+func (s *retentionService) GetPolicy(ctx context.Context, projectID uuid.UUID) (*PolicyOutput, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := s.repo.Get(ctx, tenantID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	tenantDays, err := s.tenantDays(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return toPolicyOutput(policy, tenantDays), nil
+}
+
+func (s *retentionService) SetPolicy(ctx context.Context, projectID uuid.UUID, input PolicyInput) (*PolicyOutput, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	policy, tenantDays, err := s.policyFrom(ctx, tenantID, projectID, input)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Replace(ctx, policy); err != nil {
+		return nil, err
+	}
+	return s.apply(ctx, policy, tenantDays)
+}
+
+func (s *retentionService) ResetPolicy(ctx context.Context, projectID uuid.UUID) (*PolicyOutput, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tenantDays, err := s.tenantDays(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Delete(ctx, tenantID, projectID); err != nil {
+		return nil, err
+	}
+	return s.apply(ctx, &domain.Policy{TenantID: tenantID, ProjectID: projectID}, tenantDays)
+}
+
+// apply makes a stored policy take effect: on this replica's ingest path
+// at once, and on the logs already stored.
+func (s *retentionService) apply(ctx context.Context, policy *domain.Policy, tenantDays int) (*PolicyOutput, error) {
+	s.invalidate(policy.ProjectID)
+	rules := policy.Rules(tenantDays)
+	if err := s.store.Apply(ctx, policy.TenantID, policy.ProjectID, rules); err != nil {
+		// The policy is saved; repeating the request re-applies it.
+		s.logger.Error("apply retention to stored logs failed",
+			zap.String("project_id", policy.ProjectID.String()), zap.Error(err))
+		return nil, fmt.Errorf("apply retention to stored logs: %w", err)
+	}
+	s.logger.Info("retention policy applied",
+		zap.String("tenant_id", policy.TenantID.String()),
+		zap.String("project_id", policy.ProjectID.String()),
+		zap.Int("days", rules.Days),
+		zap.Any("levels", rules.Levels),
+	)
+	return toPolicyOutput(policy, tenantDays), nil
+}
+
+func (s *retentionService) Preview(ctx context.Context, projectID uuid.UUID, input PolicyInput) (*PreviewOutput, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	policy, tenantDays, err := s.policyFrom(ctx, tenantID, projectID, input)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.Get(ctx, tenantID, projectID); err != nil {
+		return nil, err
+	}
+	impact, err := s.store.Preview(ctx, tenantID, projectID, policy.Rules(tenantDays), s.now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	out := &PreviewOutput{Policy: *toPolicyOutput(policy, tenantDays), Levels: impact}
+	for _, l := range impact {
+		out.TotalRows += l.Rows
+		out.TotalBytes += l.Bytes
+	}
+	return out, nil
+}
+
+func (s *retentionService) RetentionDays(ctx context.Context, tenantID, projectID uuid.UUID, level string) (int, error) {
+	policy, err := s.cachedPolicy(ctx, tenantID, projectID)
+	if err != nil {
+		return 0, err
+	}
+	tenantDays, err := s.tenantDays(ctx, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	return policy.Rules(tenantDays).DaysFor(level), nil
+}
+
+// policyFrom validates input as the project's new policy.
+func (s *retentionService) policyFrom(ctx context.Context, tenantID, projectID uuid.UUID, input PolicyInput) (*domain.Policy, int, error) {
+	tenantDays, err := s.tenantDays(ctx, tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+	policy := &domain.Policy{
+		TenantID:  tenantID,
+		ProjectID: projectID,
+		Days:      input.RetentionDays,
+		Levels:    make(map[string]int, len(input.Levels)),
+	}
+	for level, days := range input.Levels {
+		canonical := domain.CanonicalLevel(level)
+		if canonical == "" {
+			return nil, 0, fmt.Errorf("%w: %q", domain.ErrUnknownLevel, level)
+		}
+		policy.Levels[canonical] = days
+	}
+	if err := policy.Validate(tenantDays); err != nil {
+		return nil, 0, err
+	}
+	return policy, tenantDays, nil
+}
+
+func (s *retentionService) tenantDays(ctx context.Context, tenantID uuid.UUID) (int, error) {
+	quota, err := s.quotas.GetQuota(ctx, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	return quota.RetentionDays, nil
+}
+
+func (s *retentionService) cachedPolicy(ctx context.Context, tenantID, projectID uuid.UUID) (*domain.Policy, error) {
+	now := s.now()
+	if policy, ok := s.cache.Get(projectID, now); ok && policy.TenantID == tenantID {
+		return policy, nil
+	}
+
+	policy, err := s.repo.Get(ctx, tenantID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.Set(projectID, policy, now)
+	return policy, nil
+}
+
+func (s *retentionService) invalidate(projectID uuid.UUID) {
+	s.cache.Delete(projectID)
+}
+
+// tenantFromContext returns the caller's tenant as set by AuthMiddleware. The
+// tenant is never taken from request input.
+func tenantFromContext(ctx context.Context) (uuid.UUID, error) {
+	tenantID, ok := middleware.GetTenantUUIDFromContext(ctx)
+	if !ok {
+		return uuid.Nil, domain.ErrTenantRequired
+	}
+	return tenantID, nil
+}
+
+func toPolicyOutput(p *domain.Policy, tenantDays int) *PolicyOutput {
+	rules := p.Rules(tenantDays)
+	effective := make(map[string]int, len(domain.Levels))
+	for _, level := range domain.Levels {
+		effective[level] = rules.DaysFor(level)
+	}
+	levels := p.Levels
+	if levels == nil {
+		levels = map[string]int{}
+	}
+	return &PolicyOutput{
+		ProjectID:           p.ProjectID,
+		RetentionDays:       p.Days,
+		Levels:              levels,
+		TenantRetentionDays: tenantDays,
+		Effective:           effective,
+		UpdatedAt:           p.UpdatedAt,
+	}
+}
@@ -0,0 +1,176 @@
+package application
+
+import (
+	"context"
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/retention/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
+	tenantDomain "github.com/indalyadav56/logify/apps/backend/internal/tenant/domain"
+)
+
+// memPolicies mirrors the Postgres repository: policies are keyed by
+// project and projects of other tenants are not found.
+type memPolicies struct {
+	projects map[uuid.UUID]uuid.UUID // project -> tenant
+	policies map[uuid.UUID]domain.Policy
+	gets     int
+}
+
+func (r *memPolicies) Get(_ context.Context, tenantID, projectID uuid.UUID) (*domain.Policy, error) {
+	r.gets++
+	if r.projects[projectID] != tenantID {
+		return nil, domain.ErrProjectNotFound
+	}
+	p, ok := r.policies[projectID]
+	if !ok {
+		p = domain.Policy{TenantID: tenantID, ProjectID: projectID}
+	}
+	p.Levels = maps.Clone(p.Levels)
+	return &p, nil
+}
+
+func (r *memPolicies) Replace(_ context.Context, p *domain.Policy) error {
+	if r.projects[p.ProjectID] != p.TenantID {
+		return domain.ErrProjectNotFound
+	}
+	r.policies[p.ProjectID] = *p
+	return nil
+}
+
+func (r *memPolicies) Delete(_ context.Context, tenantID, projectID uuid.UUID) error {
+	if r.projects[projectID] != tenantID {
+		return domain.ErrProjectNotFound
+	}
+	delete(r.policies, projectID)
+	return nil
+}
+
+type memStore struct {
+	applied []domain.Rules
+}
+
+func (s *memStore) Preview(_ context.Context, _, _ uuid.UUID, rules domain.Rules, _ time.Time) ([]domain.LevelImpact, error) {
+	return []domain.LevelImpact{
+		{Level: "debug", Days: rules.DaysFor("debug"), Rows: 10, Bytes: 1000},
+		{Level: "error", Days: rules.DaysFor("error"), Rows: 1, Bytes: 50},
+	}, nil
+}
+
+func (s *memStore) Apply(_ context.Context, _, _ uuid.UUID, rules domain.Rules) error {
+	s.applied = append(s.applied, rules)
+	return nil
+}
+
+type fixedQuota int
+
+func (q fixedQuota) GetQuota(_ context.Context, tenantID uuid.UUID) (*tenantDomain.Quota, error) {
+	return &tenantDomain.Quota{TenantID: tenantID, RetentionDays: int(q)}, nil
+}
+
+func newTestRetentionService(t *testing.T) (*retentionService, *memPolicies, *memStore, context.Context, uuid.UUID) {
+	t.Helper()
+	tenantID, projectID := uuid.New(), uuid.New()
+	repo := &memPolicies{
+		projects: map[uuid.UUID]uuid.UUID{projectID: tenantID},
+		policies: map[uuid.UUID]domain.Policy{},
+	}
+	store := &memStore{}
+	svc := NewRetentionService(repo, store, fixedQuota(30), zap.NewNop()).(*retentionService)
+	ctx := middleware.WithClaims(context.Background(), &middleware.Claims{
+		UserID:   uuid.NewString(),
+		TenantID: tenantID.String(),
+	})
+	return svc, repo, store, ctx, projectID
+}
+
+func TestSetPolicyValidatesAgainstQuota(t *testing.T) {
+	svc, _, store, ctx, projectID := newTestRetentionService(t)
+
+	_, err := svc.SetPolicy(ctx, projectID, PolicyInput{RetentionDays: 31})
+	assert.ErrorIs(t, err, domain.ErrExceedsQuota)
+	_, err = svc.SetPolicy(ctx, projectID, PolicyInput{Levels: map[string]int{"verbose": 1}})
+	assert.ErrorIs(t, err, domain.ErrUnknownLevel)
+	_, err = svc.SetPolicy(ctx, projectID, PolicyInput{Levels: map[string]int{"debug": 0}})
+	assert.ErrorIs(t, err, domain.ErrInvalidRetention)
+	_, err = svc.SetPolicy(ctx, uuid.New(), PolicyInput{RetentionDays: 7})
+	assert.ErrorIs(t, err, domain.ErrProjectNotFound)
+	_, err = svc.SetPolicy(context.Background(), projectID, PolicyInput{RetentionDays: 7})
+	assert.ErrorIs(t, err, domain.ErrTenantRequired)
+	assert.Empty(t, store.applied)
+
+	out, err := svc.SetPolicy(ctx, projectID, PolicyInput{
+		RetentionDays: 14,
+		Levels:        map[string]int{"DEBUG": 1, "warning": 30},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"debug": 1, "warn": 30}, out.Levels)
+	assert.Equal(t, 30, out.TenantRetentionDays)
+	assert.Equal(t, 1, out.Effective["debug"])
+	assert.Equal(t, 14, out.Effective["info"])
+	assert.Equal(t, 30, out.Effective["warn"])
+
+	require.Len(t, store.applied, 1)
+	assert.Equal(t, domain.Rules{Days: 14, Levels: map[string]int{"debug": 1, "warn": 30}}, store.applied[0])
+}
+
+func TestResetPolicyFallsBackToTenantRetention(t *testing.T) {
+	svc, _, store, ctx, projectID := newTestRetentionService(t)
+
+	_, err := svc.SetPolicy(ctx, projectID, PolicyInput{RetentionDays: 7})
+	require.NoError(t, err)
+	out, err := svc.ResetPolicy(ctx, projectID)
+	require.NoError(t, err)
+	assert.Zero(t, out.RetentionDays)
+	assert.Equal(t, 30, out.Effective["error"])
+
+	require.Len(t, store.applied, 2)
+	assert.Equal(t, 30, store.applied[1].Days)
+
+	got, err := svc.GetPolicy(ctx, projectID)
+	require.NoError(t, err)
+	assert.Empty(t, got.Levels)
+}
+
+func TestRetentionDaysIsCachedUntilPolicyChanges(t *testing.T) {
+	svc, repo, _, ctx, projectID := newTestRetentionService(t)
+	tenantID := repo.projects[projectID]
+
+	days, err := svc.RetentionDays(ctx, tenantID, projectID, "error")
+	require.NoError(t, err)
+	assert.Equal(t, 30, days)
+	_, err = svc.RetentionDays(ctx, tenantID, projectID, "debug")
+	require.NoError(t, err)
+	assert.Equal(t, 1, repo.gets, "second lookup is served from the cache")
+
+	_, err = svc.SetPolicy(ctx, projectID, PolicyInput{Levels: map[string]int{"debug": 3}})
+	require.NoError(t, err)
+	days, err = svc.RetentionDays(ctx, tenantID, projectID, "DEBUG")
+	require.NoError(t, err)
+	assert.Equal(t, 3, days)
+	days, err = svc.RetentionDays(ctx, tenantID, projectID, "custom")
+	require.NoError(t, err)
+	assert.Equal(t, 30, days, "unknown levels get the project default")
+
+	_, err = svc.RetentionDays(ctx, uuid.New(), projectID, "info")
+	assert.ErrorIs(t, err, domain.ErrProjectNotFound, "the cache does not leak across tenants")
+}
+
+func TestPreviewTotalsImpact(t *testing.T) {
+	svc, _, store, ctx, projectID := newTestRetentionService(t)
+
+	out, err := svc.Preview(ctx, projectID, PolicyInput{Levels: map[string]int{"debug": 1}})
+	require.NoError(t, err)
+	assert.EqualValues(t, 11, out.TotalRows)
+	assert.EqualValues(t, 1050, out.TotalBytes)
+	assert.Equal(t, 1, out.Levels[0].Days)
+	assert.Equal(t, 30, out.Policy.Effective["error"])
+	assert.Empty(t, store.applied, "preview changes nothing")
+}
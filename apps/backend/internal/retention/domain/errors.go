@@ -0,0 +1,14 @@
+package domain
+
+import "errors"
+
+// Domain errors. Service / transport layers compare against these with
+// errors.Is to map them onto HTTP status codes.
+var (
+	ErrTenantRequired   = errors.New("tenant is required")
+	ErrProjectNotFound  = errors.New("project not found")
+	ErrInvalidRetention = errors.New("retention must be between 1 and 3650 days")
+	ErrUnknownLevel     = errors.New("unknown log level")
+	// ErrExceedsQuota is a retention longer than the tenant's plan allows.
+	ErrExceedsQuota = errors.New("retention exceeds the tenant quota")
+)
@@ -0,0 +1,128 @@
+package domain
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxRetentionDays bounds any policy; ClickHouse stores the value as
+	// UInt16.
+	MaxRetentionDays = 3650
+
+	// DefaultRetentionDays applies to logs stored without a retention, which
+	// is what every log had before policies existed.
+	DefaultRetentionDays = 90
+)
+
+// Levels a policy can name. Ingest accepts a few aliases for each; see
+// CanonicalLevel.
+var Levels = []string{"trace", "debug", "info", "warn", "error", "fatal"}
+
+// OtherLevel groups logs whose level is none of Levels; they are kept for
+// a policy's default retention.
+const OtherLevel = "other"
+
+var levelAliases = map[string][]string{
+	"trace": {"trace"},
+	"debug": {"debug"},
+	"info":  {"info", "notice"},
+	"warn":  {"warn", "warning"},
+	"error": {"error"},
+	"fatal": {"fatal", "critical", "panic"},
+}
+
+// CanonicalLevel maps a log level as sent by clients onto one of Levels,
+// or "" when it is none of them.
+func CanonicalLevel(level string) string {
+	level = strings.ToLower(strings.TrimSpace(level))
+	for canonical, aliases := range levelAliases {
+		if slices.Contains(aliases, level) {
+			return canonical
+		}
+	}
+	return ""
+}
+
+// LevelAliases returns every spelling of a canonical level.
+func LevelAliases(level string) []string {
+	return levelAliases[level]
+}
+
+// Policy is a project's retention. Days applies to every level without a
+// rule of its own in Levels; zero defers to the tenant quota.
+type Policy struct {
+	TenantID  uuid.UUID
+	ProjectID uuid.UUID
+	Days      int
+	// Levels maps canonical levels to their retention in days.
+	Levels    map[string]int
+	UpdatedAt *time.Time
+}
+
+// Rules resolves p against the tenant's quota retention.
+func (p Policy) Rules(tenantDays int) Rules {
+	r := Rules{Days: p.Days, Levels: maps.Clone(p.Levels)}
+	if r.Days == 0 {
+		r.Days = tenantDays
+	}
+	if r.Days == 0 {
+		r.Days = DefaultRetentionDays
+	}
+	return r
+}
+
+// Validate checks every retention against the bounds and the tenant quota.
+func (p Policy) Validate(tenantDays int) error {
+	check := func(days int) error {
+		if days < 1 || days > MaxRetentionDays {
+			return ErrInvalidRetention
+		}
+		if tenantDays > 0 && days > tenantDays {
+			return ErrExceedsQuota
+		}
+		return nil
+	}
+	if p.Days != 0 {
+		if err := check(p.Days); err != nil {
+			return err
+		}
+	}
+	for level, days := range p.Levels {
+		if _, ok := levelAliases[level]; !ok {
+			return ErrUnknownLevel
+		}
+		if err := check(days); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Rules is a fully resolved policy: how long logs of each level are kept.
+type Rules struct {
+	Days   int
+	Levels map[string]int
+}
+
+// DaysFor returns the retention of a log of level.
+func (r Rules) DaysFor(level string) int {
+	if days, ok := r.Levels[CanonicalLevel(level)]; ok {
+		return days
+	}
+	return r.Days
+}
+
+// LevelImpact is what a policy would expire of one level's stored logs.
+// Level is OtherLevel for logs whose level is none of Levels.
+type LevelImpact struct {
+	Level  string     `json:"level"`
+	Days   int        `json:"retention_days"`
+	Rows   uint64     `json:"rows"`
+	Bytes  uint64     `json:"bytes"`
+	Oldest *time.Time `json:"oldest,omitempty"`
+}
@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PolicyRepository stores retention policies in Postgres. Every call is
+// scoped to a tenant: a project of another tenant behaves like a missing
+// one (ErrProjectNotFound).
+type PolicyRepository interface {
+	// Get returns the project's policy, with zero Days and no Levels when
+	// none was set.
+	Get(ctx context.Context, tenantID, projectID uuid.UUID) (*Policy, error)
+	// Replace overwrites the project's policy.
+	Replace(ctx context.Context, policy *Policy) error
+	// Delete resets the project to the tenant default.
+	Delete(ctx context.Context, tenantID, projectID uuid.UUID) error
+}
+
+// LogStore enforces retention on the stored logs (ClickHouse).
+type LogStore interface {
+	// Preview reports, per level, the stored logs of the project that rules
+	// would expire at now.
+	Preview(ctx context.Context, tenantID, projectID uuid.UUID, rules Rules, now time.Time) ([]LevelImpact, error)
+	// Apply re-stamps the project's stored logs with rules. Expired rows
+	// are removed by the storage's TTL on its next merge.
+	Apply(ctx context.Context, tenantID, projectID uuid.UUID, rules Rules) error
+}
@@ -0,0 +1,168 @@
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	ch "github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/google/uuid"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/retention/domain"
+)
+
+const (
+	logsTable   = "logify.logs"
+	rollupTable = "logify.logs_rollup_1m"
+	fieldsTable = "logify.log_fields"
+)
+
+// LogStore enforces retention through the retention_days column of
+// logify.logs, which the table's TTL reads. The per-minute rollups carry the
+// same column so that counts expire with the logs they count, and so does
+// the field catalog.
+type LogStore struct {
+	conn ch.Conn
+}
+
+func NewLogStore(conn ch.Conn) *LogStore {
+	return &LogStore{conn: conn}
+}
+
+func (s *LogStore) Preview(ctx context.Context, tenantID, projectID uuid.UUID, rules domain.Rules, now time.Time) ([]domain.LevelImpact, error) {
+	cutoff := func(days int) time.Time {
+		return now.AddDate(0, 0, -days).Truncate(time.Millisecond)
+	}
+
+	var (
+		conds []string
+		args  = []any{tenantID.String(), projectID.String()}
+	)
+	for _, level := range explicitLevels(rules) {
+		conds = append(conds, "(lower(level) IN (?) AND timestamp < ?)")
+		args = append(args, domain.LevelAliases(level), cutoff(rules.Levels[level]))
+	}
+	if aliases := explicitAliases(rules); len(aliases) > 0 {
+		conds = append(conds, "(lower(level) NOT IN (?) AND timestamp < ?)")
+		args = append(args, aliases, cutoff(rules.Days))
+	} else {
+		conds = append(conds, "timestamp < ?")
+		args = append(args, cutoff(rules.Days))
+	}
+
+	query := fmt.Sprintf(`
+		SELECT lower(level) AS lvl, count(), sum(byteSize(message, tags, attributes)), min(timestamp)
+		FROM %s
+		WHERE tenant_id = ? AND project_id = ? AND (%s)
+		GROUP BY lvl`,
+		logsTable, strings.Join(conds, " OR "),
+	)
+	rows, err := s.conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("clickhouse retention preview: %w", err)
+	}
+	defer rows.Close()
+
+	impacts := make([]domain.LevelImpact, 0, len(domain.Levels)+1)
+	for _, level := range domain.Levels {
+		impacts = append(impacts, domain.LevelImpact{Level: level, Days: rules.DaysFor(level)})
+	}
+	impacts = append(impacts, domain.LevelImpact{Level: domain.OtherLevel, Days: rules.Days})
+
+	for rows.Next() {
+		var (
+			level       string
+			count, size uint64
+			oldest      time.Time
+		)
+		if err := rows.Scan(&level, &count, &size, &oldest); err != nil {
+			return nil, fmt.Errorf("clickhouse scan retention preview: %w", err)
+		}
+		if canonical := domain.CanonicalLevel(level); canonical != "" {
+			level = canonical
+		} else {
+			level = domain.OtherLevel
+		}
+		i := slices.IndexFunc(impacts, func(l domain.LevelImpact) bool { return l.Level == level })
+		impact := &impacts[i]
+		impact.Rows += count
+		impact.Bytes += size
+		if impact.Oldest == nil || oldest.Before(*impact.Oldest) {
+			impact.Oldest = &oldest
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("clickhouse retention preview rows: %w", err)
+	}
+	return impacts, nil
+}
+
+// Apply runs asynchronous mutations of the logs, their rollups and the field
+// catalog; ClickHouse recomputes the TTL of the rewritten parts and drops
+// expired rows on a later merge.
+func (s *LogStore) Apply(ctx context.Context, tenantID, projectID uuid.UUID, rules domain.Rules) error {
+	var (
+		expr string
+		args []any
+	)
+	if levels := explicitLevels(rules); len(levels) > 0 {
+		branches := make([]string, 0, len(levels)+1)
+		for _, level := range levels {
+			branches = append(branches, "lower(level) IN (?), toUInt16(?)")
+			args = append(args, domain.LevelAliases(level), rules.Levels[level])
+		}
+		branches = append(branches, "toUInt16(?)")
+		args = append(args, rules.Days)
+		expr = "multiIf(" + strings.Join(branches, ", ") + ")"
+	} else {
+		expr = "toUInt16(?)"
+		args = append(args, rules.Days)
+	}
+	args = append(args, tenantID.String(), projectID.String())
+
+	for _, table := range []string{logsTable, rollupTable} {
+		query := fmt.Sprintf(
+			"ALTER TABLE %s UPDATE retention_days = %s WHERE tenant_id = ? AND project_id = ?",
+			table, expr,
+		)
+		if err := s.conn.Exec(ctx, query, args...); err != nil {
+			return fmt.Errorf("clickhouse retention apply %s: %w", table, err)
+		}
+	}
+
+	// The catalog does not know levels: its fields are kept as long as the
+	// longest kept logs.
+	days := rules.Days
+	for _, d := range rules.Levels {
+		days = max(days, d)
+	}
+	query := fmt.Sprintf(
+		"ALTER TABLE %s UPDATE retention_days = toUInt16(?) WHERE tenant_id = ? AND project_id = ?", fieldsTable,
+	)
+	if err := s.conn.Exec(ctx, query, days, tenantID.String(), projectID.String()); err != nil {
+		return fmt.Errorf("clickhouse retention apply %s: %w", fieldsTable, err)
+	}
+	return nil
+}
+
+// explicitLevels returns the levels with a rule of their own, in a stable
+// order.
+func explicitLevels(rules domain.Rules) []string {
+	levels := make([]string, 0, len(rules.Levels))
+	for _, level := range domain.Levels {
+		if _, ok := rules.Levels[level]; ok {
+			levels = append(levels, level)
+		}
+	}
+	return levels
+}
+
+func explicitAliases(rules domain.Rules) []string {
+	var aliases []string
+	for _, level := range explicitLevels(rules) {
+		aliases = append(aliases, domain.LevelAliases(level)...)
+	}
+	return aliases
+}
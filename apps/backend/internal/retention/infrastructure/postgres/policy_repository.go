@@ -0,0 +1,145 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/retention/domain"
+)
+
+// defaultLevel is the level column of a project's default rule.
+const defaultLevel = ""
+
+type policyRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPolicyRepository(db *pgxpool.Pool) domain.PolicyRepository {
+	return &policyRepository{db: db}
+}
+
+func (r *policyRepository) Get(ctx context.Context, tenantID, projectID uuid.UUID) (*domain.Policy, error) {
+	const query = `
+		SELECT rp.level, rp.retention_days, rp.updated_at
+		FROM projects p
+		LEFT JOIN project_retention_policies rp ON rp.project_id = p.id
+		WHERE p.id = $1 AND p.tenant_id = $2 AND p.deleted_at IS NULL
+	`
+	rows, err := r.db.Query(ctx, query, projectID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	policy := &domain.Policy{TenantID: tenantID, ProjectID: projectID, Levels: map[string]int{}}
+	found := false
+	for rows.Next() {
+		found = true
+		var (
+			level     *string
+			days      *int
+			updatedAt *time.Time
+		)
+		if err := rows.Scan(&level, &days, &updatedAt); err != nil {
+			return nil, err
+		}
+		if level == nil {
+			continue
+		}
+		if *level == defaultLevel {
+			policy.Days = *days
+		} else {
+			policy.Levels[*level] = *days
+		}
+		if updatedAt != nil && (policy.UpdatedAt == nil || updatedAt.After(*policy.UpdatedAt)) {
+			policy.UpdatedAt = updatedAt
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, domain.ErrProjectNotFound
+	}
+	return policy, nil
+}
+
+func (r *policyRepository) Replace(ctx context.Context, policy *domain.Policy) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockProject(ctx, tx, policy.TenantID, policy.ProjectID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM project_retention_policies WHERE project_id = $1`, policy.ProjectID); err != nil {
+		return err
+	}
+
+	rules := make(map[string]int, len(policy.Levels)+1)
+	for level, days := range policy.Levels {
+		rules[level] = days
+	}
+	if policy.Days != 0 {
+		rules[defaultLevel] = policy.Days
+	}
+	now := time.Now().UTC()
+	batch := &pgx.Batch{}
+	for level, days := range rules {
+		batch.Queue(`
+			INSERT INTO project_retention_policies (project_id, tenant_id, level, retention_days, updated_at)
+			VALUES ($1, $2, $3, $4, $5)
+		`, policy.ProjectID, policy.TenantID, level, days, now)
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	if len(rules) > 0 {
+		policy.UpdatedAt = &now
+	}
+	return nil
+}
+
+func (r *policyRepository) Delete(ctx context.Context, tenantID, projectID uuid.UUID) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockProject(ctx, tx, tenantID, projectID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM project_retention_policies WHERE project_id = $1`, projectID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// lockProject checks the project belongs to the tenant and serialises
+// concurrent policy changes to it.
+func lockProject(ctx context.Context, tx pgx.Tx, tenantID, projectID uuid.UUID) error {
+	const query = `
+		SELECT 1 FROM projects
+		WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL
+		FOR UPDATE
+	`
+	var one int
+	if err := tx.QueryRow(ctx, query, projectID, tenantID).Scan(&one); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrProjectNotFound
+		}
+		return err
+	}
+	return nil
+}
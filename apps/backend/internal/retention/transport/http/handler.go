@@ -0,0 +1,164 @@
+package http
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/retention/application"
+	"github.com/indalyadav56/logify/apps/backend/internal/retention/domain"
+	"github.com/indalyadav56/logify/apps/backend/pkg/response"
+	"github.com/indalyadav56/logify/apps/backend/pkg/validator"
+)
+
+type RetentionHandler struct {
+	service application.RetentionService
+}
+
+func NewRetentionHandler(service application.RetentionService) *RetentionHandler {
+	return &RetentionHandler{service: service}
+}
+
+// GetPolicy returns a project's retention policy.
+// @Summary      Get retention policy
+// @Description  Return the project's retention, per level, and how it resolves against the tenant quota.
+// @Tags         retention
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Project ID (UUID)"
+// @Success      200  {object}  response.APIResponse "Retention policy retrieved successfully"
+// @Failure      400  {object}  response.APIResponse "Invalid id format"
+// @Failure      404  {object}  response.APIResponse "Project not found"
+// @Failure      500  {object}  response.APIResponse "Internal server error"
+// @Router       /v1/projects/{id}/retention [get]
+func (h *RetentionHandler) GetPolicy(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	policy, err := h.service.GetPolicy(c.Request.Context(), id)
+	if err != nil {
+		h.writeError(c, err, "Failed to retrieve retention policy")
+		return
+	}
+	response.OK(c, "Retention policy retrieved successfully", policy)
+}
+
+// SetPolicy replaces a project's retention policy.
+// @Summary      Set retention policy
+// @Description  Replace the project's retention. It applies to new logs at once and to stored logs asynchronously; logs older than the new retention are deleted.
+// @Tags         retention
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                   true  "Project ID (UUID)"
+// @Param        request  body      application.PolicyInput  true  "Retention policy"
+// @Success      200      {object}  response.APIResponse "Retention policy updated successfully"
+// @Failure      400      {object}  response.APIResponse "Invalid request"
+// @Failure      404      {object}  response.APIResponse "Project not found"
+// @Failure      500      {object}  response.APIResponse "Internal server error"
+// @Router       /v1/projects/{id}/retention [put]
+func (h *RetentionHandler) SetPolicy(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input application.PolicyInput
+	if !validator.ValidateRequest(c, &input) {
+		return
+	}
+
+	policy, err := h.service.SetPolicy(c.Request.Context(), id, input)
+	if err != nil {
+		h.writeError(c, err, "Failed to update retention policy")
+		return
+	}
+	response.OK(c, "Retention policy updated successfully", policy)
+}
+
+// ResetPolicy drops a project's retention policy.
+// @Summary      Reset retention policy
+// @Description  Drop the project's retention policy; its logs are kept for the tenant quota's retention.
+// @Tags         retention
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Project ID (UUID)"
+// @Success      200  {object}  response.APIResponse "Retention policy reset successfully"
+// @Failure      400  {object}  response.APIResponse "Invalid id format"
+// @Failure      404  {object}  response.APIResponse "Project not found"
+// @Failure      500  {object}  response.APIResponse "Internal server error"
+// @Router       /v1/projects/{id}/retention [delete]
+func (h *RetentionHandler) ResetPolicy(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	policy, err := h.service.ResetPolicy(c.Request.Context(), id)
+	if err != nil {
+		h.writeError(c, err, "Failed to reset retention policy")
+		return
+	}
+	response.OK(c, "Retention policy reset successfully", policy)
+}
+
+// Preview reports what a retention policy would delete.
+// @Summary      Preview retention policy
+// @Description  Count, per level, the stored logs of the project that the given policy would delete now. Nothing is changed.
+// @Tags         retention
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                   true  "Project ID (UUID)"
+// @Param        request  body      application.PolicyInput  true  "Retention policy"
+// @Success      200      {object}  response.APIResponse "Retention preview computed successfully"
+// @Failure      400      {object}  response.APIResponse "Invalid request"
+// @Failure      404      {object}  response.APIResponse "Project not found"
+// @Failure      500      {object}  response.APIResponse "Internal server error"
+// @Router       /v1/projects/{id}/retention/preview [post]
+func (h *RetentionHandler) Preview(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input application.PolicyInput
+	if !validator.ValidateRequest(c, &input) {
+		return
+	}
+
+	preview, err := h.service.Preview(c.Request.Context(), id, input)
+	if err != nil {
+		h.writeError(c, err, "Failed to preview retention policy")
+		return
+	}
+	response.OK(c, "Retention preview computed successfully", preview)
+}
+
+// writeError maps domain errors to HTTP responses with a consistent envelope.
+func (h *RetentionHandler) writeError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrProjectNotFound):
+		response.NotFound(c, "Project not found")
+	case errors.Is(err, domain.ErrTenantRequired):
+		response.Unauthorized(c, "Tenant ID is required")
+	case errors.Is(err, domain.ErrInvalidRetention),
+		errors.Is(err, domain.ErrUnknownLevel),
+		errors.Is(err, domain.ErrExceedsQuota):
+		response.BadRequest(c, err.Error())
+	default:
+		response.InternalServerError(c, fallback)
+	}
+}
+
+func parseUUIDParam(c *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		response.BadRequest(c, "Invalid "+name+" format")
+		return uuid.Nil, false
+	}
+	return id, true
+}
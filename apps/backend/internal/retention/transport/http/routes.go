@@ -0,0 +1,23 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+
+	roleDomain "github.com/indalyadav56/logify/apps/backend/internal/role/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
+)
+
+// RegisterRoutes mounts the retention routes under their project. The group
+// is expected to be already authenticated (see di.RegisterAllRoutes).
+func RegisterRoutes(router *gin.RouterGroup, handler *RetentionHandler, authz *middleware.Authorizer) {
+	read := authz.RequireProjectPermission("id", roleDomain.ResourceProject, roleDomain.ActionRead)
+	write := authz.RequireProjectPermission("id", roleDomain.ResourceProject, roleDomain.ActionWrite)
+
+	g := router.Group("/v1/projects/:id/retention")
+	{
+		g.GET("", read, handler.GetPolicy)
+		g.PUT("", write, handler.SetPolicy)
+		g.DELETE("", write, handler.ResetPolicy)
+		g.POST("/preview", read, handler.Preview)
+	}
+}
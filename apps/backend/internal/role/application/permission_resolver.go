@@ -0,0 +1,130 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/role/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
+	"github.com/indalyadav56/logify/apps/backend/pkg/ttlcache"
+)
+
+const (
+	// defaultPermissionCacheTTL bounds how stale a cached permission set can be
+	// on replicas that never saw the invalidating write.
+	defaultPermissionCacheTTL = time.Minute
+
+	// maxPermissionCacheEntries caps memory; the cache is simply reset when full.
+	maxPermissionCacheEntries = 10_000
+)
+
+// PermissionResolver computes the effective permissions of a user within a
+// tenant and, optionally, a project. When the request was authenticated with
+// an access token of that user, the result is capped by the token's scopes.
+type PermissionResolver interface {
+	Resolve(ctx context.Context, scope domain.PermissionScope) (domain.PermissionSet, error)
+
+	// HasPermission is a convenience over Resolve for single checks. It
+	// satisfies middleware.PermissionChecker.
+	HasPermission(ctx context.Context, userID, tenantID uuid.UUID, projectID *uuid.UUID, resource, action string) (bool, error)
+
+	// InvalidateUser drops every cached set of the user, e.g. after a
+	// membership change.
+	InvalidateUser(userID uuid.UUID)
+
+	// InvalidateAll drops the whole cache, e.g. after a role's permissions
+	// change and any number of users may be affected.
+	InvalidateAll()
+}
+
+type permissionCacheKey struct {
+	userID    uuid.UUID
+	tenantID  uuid.UUID
+	projectID uuid.UUID // uuid.Nil for tenant scope
+}
+
+type permissionResolver struct {
+	repo   domain.PermissionRepository
+	logger *zap.Logger
+	now    func() time.Time
+	cache  *ttlcache.Cache[permissionCacheKey, domain.PermissionSet]
+}
+
+// NewPermissionResolver returns a PermissionResolver with an in-process cache.
+// A non-positive ttl selects the default of one minute.
+func NewPermissionResolver(repo domain.PermissionRepository, ttl time.Duration, logger *zap.Logger) PermissionResolver {
+	if ttl <= 0 {
+		ttl = defaultPermissionCacheTTL
+	}
+	return &permissionResolver{
+		repo:   repo,
+		logger: logger.Named("permission_resolver"),
+		now:    time.Now,
+		cache:  ttlcache.New[permissionCacheKey, domain.PermissionSet](ttl, maxPermissionCacheEntries),
+	}
+}
+
+func (r *permissionResolver) Resolve(ctx context.Context, scope domain.PermissionScope) (domain.PermissionSet, error) {
+	key := permissionCacheKey{userID: scope.UserID, tenantID: scope.TenantID}
+	if scope.ProjectID != nil {
+		key.projectID = *scope.ProjectID
+	}
+
+	if set, ok := r.cache.Get(key, r.now()); ok {
+		return restrictToToken(ctx, scope, set), nil
+	}
+	// A lookup that races with an invalidation must not write its possibly
+	// stale result back.
+	gen := r.cache.Generation()
+
+	perms, err := r.repo.EffectivePermissions(ctx, scope)
+	if err != nil {
+		r.logger.Error("failed to resolve permissions",
+			zap.String("user_id", scope.UserID.String()),
+			zap.String("tenant_id", scope.TenantID.String()),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	set := domain.NewPermissionSet(perms...)
+
+	r.cache.SetIfGeneration(gen, key, set, r.now())
+
+	return restrictToToken(ctx, scope, set), nil
+}
+
+// restrictToToken applies the scopes of the access token the request was
+// authenticated with. Cached sets stay unrestricted since a principal's
+// tokens may carry different scopes.
+func restrictToToken(ctx context.Context, scope domain.PermissionScope, set domain.PermissionSet) domain.PermissionSet {
+	claims, ok := middleware.ClaimsFromContext(ctx)
+	if !ok || claims.TokenID == "" || claims.UserID != scope.UserID.String() {
+		return set
+	}
+	scopes := make([]domain.Permission, 0, len(claims.Scopes))
+	for _, key := range claims.Scopes {
+		if p, ok := domain.ParsePermissionKey(key); ok {
+			scopes = append(scopes, p)
+		}
+	}
+	return set.Restrict(scopes)
+}
+
+func (r *permissionResolver) HasPermission(ctx context.Context, userID, tenantID uuid.UUID, projectID *uuid.UUID, resource, action string) (bool, error) {
+	set, err := r.Resolve(ctx, domain.PermissionScope{UserID: userID, TenantID: tenantID, ProjectID: projectID})
+	if err != nil {
+		return false, err
+	}
+	return set.Allows(resource, action), nil
+}
+
+func (r *permissionResolver) InvalidateUser(userID uuid.UUID) {
+	r.cache.DeleteFunc(func(k permissionCacheKey) bool { return k.userID == userID })
+}
+
+func (r *permissionResolver) InvalidateAll() {
+	r.cache.Clear()
+}
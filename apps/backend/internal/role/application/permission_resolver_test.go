@@ -0,0 +1,92 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/role/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
+)
+
+type countingPermissionRepo struct {
+	perms []domain.Permission
+	calls int
+}
+
+func (r *countingPermissionRepo) EffectivePermissions(context.Context, domain.PermissionScope) ([]domain.Permission, error) {
+	r.calls++
+	return r.perms, nil
+}
+
+func TestPermissionResolverCachesAndInvalidates(t *testing.T) {
+	repo := &countingPermissionRepo{perms: []domain.Permission{{Resource: "log", Action: "read"}}}
+	resolver := NewPermissionResolver(repo, 0, zap.NewNop())
+	ctx := context.Background()
+	userID, tenantID := uuid.New(), uuid.New()
+
+	ok, err := resolver.HasPermission(ctx, userID, tenantID, nil, "log", "read")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = resolver.HasPermission(ctx, userID, tenantID, nil, "log", "write")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, 1, repo.calls, "second check should be served from cache")
+
+	// A project scope is cached separately from the tenant scope.
+	projectID := uuid.New()
+	_, err = resolver.HasPermission(ctx, userID, tenantID, &projectID, "log", "read")
+	require.NoError(t, err)
+	assert.Equal(t, 2, repo.calls)
+
+	repo.perms = append(repo.perms, domain.Permission{Resource: "log", Action: "write"})
+	resolver.InvalidateUser(userID)
+
+	ok, err = resolver.HasPermission(ctx, userID, tenantID, nil, "log", "write")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 3, repo.calls)
+}
+
+func TestPermissionSetManageImpliesAll(t *testing.T) {
+	set := domain.NewPermissionSet(domain.Permission{Resource: "role", Action: "manage"})
+	assert.True(t, set.Allows("role", "read"))
+	assert.True(t, set.Allows("Role", "DELETE"))
+	assert.False(t, set.Allows("project", "read"))
+}
+
+func TestAccessTokenScopesCapPermissions(t *testing.T) {
+	repo := &countingPermissionRepo{perms: []domain.Permission{
+		{Resource: "log", Action: "manage"},
+		{Resource: "project", Action: "read"},
+	}}
+	resolver := NewPermissionResolver(repo, 0, zap.NewNop())
+	userID, tenantID := uuid.New(), uuid.New()
+
+	ctx := middleware.WithClaims(context.Background(), &middleware.Claims{
+		UserID:   userID.String(),
+		TenantID: tenantID.String(),
+		TokenID:  uuid.NewString(),
+		// role.read is not held, so the scope adds nothing.
+		Scopes: []string{"log.read", "role.read"},
+	})
+	for perm, want := range map[string]bool{
+		"log.read": true, "log.write": false, "project.read": false, "role.read": false,
+	} {
+		p, _ := domain.ParsePermissionKey(perm)
+		ok, err := resolver.HasPermission(ctx, userID, tenantID, nil, p.Resource, p.Action)
+		require.NoError(t, err)
+		assert.Equal(t, want, ok, perm)
+	}
+
+	// The cached set is the unrestricted one.
+	ok, err := resolver.HasPermission(context.Background(), userID, tenantID, nil, "log", "write")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 1, repo.calls)
+}
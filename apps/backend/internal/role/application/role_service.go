@@ -3,11 +3,14 @@ package application
 import (
 	"context"
 	"errors"
+	"slices"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 
+	auditDomain "github.com/indalyadav56/logify/apps/backend/internal/audit/domain"
 	"github.com/indalyadav56/logify/apps/backend/internal/role/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
 )
 
 // PermissionInput is the wire representation of a single permission.
@@ -16,9 +19,9 @@ type PermissionInput struct {
 	Action   string `json:"action"   validate:"required,min=1,max=50"`
 }
 
-// CreateRoleInput is the payload accepted by CreateRole.
+// CreateRoleInput is the payload accepted by CreateRole. The role always
+// belongs to the caller's tenant.
 type CreateRoleInput struct {
-	TenantID    *uuid.UUID        `json:"tenant_id,omitempty"     validate:"omitempty"`
 	Name        string            `json:"name"                    validate:"required,min=2,max=100"`
 	Description string            `json:"description,omitempty"   validate:"omitempty,max=500"`
 	Permissions []PermissionInput `json:"permissions,omitempty"   validate:"omitempty,dive"`
@@ -55,23 +58,43 @@ type RoleService interface {
 	AddPermission(ctx context.Context, roleID uuid.UUID, p PermissionInput) error
 	RemovePermission(ctx context.Context, roleID uuid.UUID, p PermissionInput) error
 	ReplacePermissions(ctx context.Context, roleID uuid.UUID, perms []PermissionInput) error
+
+	// EffectivePermissions returns what the caller may do in the scope, for
+	// UI gating. Enforcement itself happens in middleware.RequirePermission.
+	EffectivePermissions(ctx context.Context, scope domain.PermissionScope) ([]PermissionInput, error)
 }
 
 type roleService struct {
 	repo   domain.RoleRepository
+	perms  PermissionResolver
+	audit  auditDomain.Recorder
 	logger *zap.Logger
 }
 
-// NewRoleService returns a RoleService backed by the given repository.
-func NewRoleService(repo domain.RoleRepository, logger *zap.Logger) RoleService {
+// NewRoleService returns a RoleService backed by the given repository. Role
+// mutations invalidate the resolver's cache and are recorded in the audit
+// trail.
+func NewRoleService(repo domain.RoleRepository, perms PermissionResolver, audit auditDomain.Recorder, logger *zap.Logger) RoleService {
+	if audit == nil {
+		audit = auditDomain.Discard
+	}
 	return &roleService{
 		repo:   repo,
+		perms:  perms,
+		audit:  audit,
 		logger: logger.Named("role_service"),
 	}
 }
 
 func (s *roleService) CreateRole(ctx context.Context, input CreateRoleInput) (*RoleOutput, error) {
-	role := domain.NewRole(input.TenantID, input.Name, input.Description)
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	role := domain.NewRole(&tenantID, input.Name, input.Description)
+	if role.Key() == "" {
+		return nil, domain.ErrInvalidRoleName
+	}
 
 	perms, err := toDomainPermissions(input.Permissions)
 	if err != nil {
@@ -87,6 +110,8 @@ func (s *roleService) CreateRole(ctx context.Context, input CreateRoleInput) (*R
 		return nil, err
 	}
 
+	s.perms.InvalidateAll()
+	s.record(ctx, auditDomain.ActionRoleCreated, role.ID, nil, roleState(role))
 	s.logger.Info("role created",
 		zap.String("role_id", role.ID.String()),
 		zap.String("name", role.Name),
@@ -95,7 +120,11 @@ func (s *roleService) CreateRole(ctx context.Context, input CreateRoleInput) (*R
 }
 
 func (s *roleService) GetRole(ctx context.Context, id uuid.UUID) (*RoleOutput, error) {
-	role, err := s.repo.GetByID(ctx, id)
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	role, err := s.repo.GetByID(ctx, tenantID, id)
 	if err != nil {
 		return nil, err
 	}
@@ -103,45 +132,70 @@ func (s *roleService) GetRole(ctx context.Context, id uuid.UUID) (*RoleOutput, e
 }
 
 func (s *roleService) UpdateRole(ctx context.Context, id uuid.UUID, input UpdateRoleInput) (*RoleOutput, error) {
-	role, err := s.repo.GetByID(ctx, id)
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	role, err := s.repo.GetByID(ctx, tenantID, id)
 	if err != nil {
 		return nil, err
 	}
 	if role.IsSystem {
 		return nil, domain.ErrSystemRoleReadOnly
 	}
+	before := roleState(role)
 
 	if input.Name != nil {
 		role.Name = domain.NormalizeName(*input.Name)
+		if role.Key() == "" {
+			return nil, domain.ErrInvalidRoleName
+		}
 	}
 	if input.Description != nil {
 		role.Description = *input.Description
 	}
 
-	if err := s.repo.Update(ctx, role); err != nil {
+	if err := s.repo.Update(ctx, tenantID, role); err != nil {
 		s.logger.Error("failed to update role", zap.Error(err), zap.String("role_id", id.String()))
 		return nil, err
 	}
+	s.perms.InvalidateAll()
+	s.record(ctx, auditDomain.ActionRoleUpdated, role.ID, before, roleState(role))
 	return toRoleOutput(role), nil
 }
 
 func (s *roleService) DeleteRole(ctx context.Context, id uuid.UUID) error {
-	role, err := s.repo.GetByID(ctx, id)
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	role, err := s.repo.GetByID(ctx, tenantID, id)
 	if err != nil {
 		return err
 	}
 	if role.IsSystem {
 		return domain.ErrSystemRoleReadOnly
 	}
-	if err := s.repo.Delete(ctx, id); err != nil {
+	if err := s.repo.Delete(ctx, tenantID, id); err != nil {
+		if errors.Is(err, domain.ErrRoleInUse) {
+			return err
+		}
 		s.logger.Error("failed to delete role", zap.Error(err), zap.String("role_id", id.String()))
 		return err
 	}
+	s.perms.InvalidateAll()
+	s.record(ctx, auditDomain.ActionRoleDeleted, id, roleState(role), nil)
 	s.logger.Info("role deleted", zap.String("role_id", id.String()))
 	return nil
 }
 
 func (s *roleService) ListRoles(ctx context.Context, filter domain.ListFilter) ([]*RoleOutput, int64, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	filter.TenantID = tenantID
+
 	roles, total, err := s.repo.List(ctx, filter)
 	if err != nil {
 		s.logger.Error("failed to list roles", zap.Error(err))
@@ -155,15 +209,25 @@ func (s *roleService) ListRoles(ctx context.Context, filter domain.ListFilter) (
 }
 
 func (s *roleService) ListPermissions(ctx context.Context, roleID uuid.UUID) ([]PermissionInput, error) {
-	perms, err := s.repo.ListPermissions(ctx, roleID)
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// GetByID loads the permission set and tells a missing role apart from
+	// one with no grants.
+	role, err := s.repo.GetByID(ctx, tenantID, roleID)
 	if err != nil {
 		return nil, err
 	}
-	return fromDomainPermissions(perms), nil
+	return fromDomainPermissions(role.Permissions), nil
 }
 
 func (s *roleService) AddPermission(ctx context.Context, roleID uuid.UUID, p PermissionInput) error {
-	role, err := s.repo.GetByID(ctx, roleID)
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	role, err := s.repo.GetByID(ctx, tenantID, roleID)
 	if err != nil {
 		return err
 	}
@@ -174,11 +238,20 @@ func (s *roleService) AddPermission(ctx context.Context, roleID uuid.UUID, p Per
 	if !perm.IsValid() {
 		return domain.ErrInvalidPermission
 	}
-	return s.repo.AddPermission(ctx, roleID, perm)
+	if err := s.repo.AddPermission(ctx, tenantID, roleID, perm); err != nil {
+		return err
+	}
+	s.perms.InvalidateAll()
+	s.recordPermissions(ctx, role, append(slices.Clone(role.Permissions), perm))
+	return nil
 }
 
 func (s *roleService) RemovePermission(ctx context.Context, roleID uuid.UUID, p PermissionInput) error {
-	role, err := s.repo.GetByID(ctx, roleID)
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	role, err := s.repo.GetByID(ctx, tenantID, roleID)
 	if err != nil {
 		return err
 	}
@@ -189,11 +262,22 @@ func (s *roleService) RemovePermission(ctx context.Context, roleID uuid.UUID, p
 	if !perm.IsValid() {
 		return domain.ErrInvalidPermission
 	}
-	return s.repo.RemovePermission(ctx, roleID, perm)
+	if err := s.repo.RemovePermission(ctx, tenantID, roleID, perm); err != nil {
+		return err
+	}
+	s.perms.InvalidateAll()
+	s.recordPermissions(ctx, role, slices.DeleteFunc(slices.Clone(role.Permissions), func(p domain.Permission) bool {
+		return p == perm
+	}))
+	return nil
 }
 
 func (s *roleService) ReplacePermissions(ctx context.Context, roleID uuid.UUID, perms []PermissionInput) error {
-	role, err := s.repo.GetByID(ctx, roleID)
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	role, err := s.repo.GetByID(ctx, tenantID, roleID)
 	if err != nil {
 		return err
 	}
@@ -204,7 +288,66 @@ func (s *roleService) ReplacePermissions(ctx context.Context, roleID uuid.UUID,
 	if err != nil {
 		return err
 	}
-	return s.repo.ReplacePermissions(ctx, roleID, d)
+	if err := s.repo.ReplacePermissions(ctx, tenantID, roleID, d); err != nil {
+		return err
+	}
+	s.perms.InvalidateAll()
+	s.recordPermissions(ctx, role, d)
+	return nil
+}
+
+func (s *roleService) EffectivePermissions(ctx context.Context, scope domain.PermissionScope) ([]PermissionInput, error) {
+	set, err := s.perms.Resolve(ctx, scope)
+	if err != nil {
+		return nil, err
+	}
+	return fromDomainPermissions(set.List()), nil
+}
+
+func (s *roleService) record(ctx context.Context, action string, roleID uuid.UUID, before, after map[string]any) {
+	s.audit.Record(ctx, auditDomain.Event{
+		Action:     action,
+		TargetType: "role",
+		TargetID:   roleID.String(),
+		Before:     before,
+		After:      after,
+	})
+}
+
+// recordPermissions records the grants of role changing to after.
+func (s *roleService) recordPermissions(ctx context.Context, role *domain.Role, after []domain.Permission) {
+	s.record(ctx, auditDomain.ActionRolePermissionsChanged, role.ID,
+		map[string]any{"permissions": permissionKeys(role.Permissions)},
+		map[string]any{"permissions": permissionKeys(after)},
+	)
+}
+
+// roleState is what an audit record shows of a role.
+func roleState(r *domain.Role) map[string]any {
+	return map[string]any{
+		"name":        r.Name,
+		"description": r.Description,
+		"permissions": permissionKeys(r.Permissions),
+	}
+}
+
+func permissionKeys(perms []domain.Permission) []string {
+	keys := make([]string, len(perms))
+	for i, p := range perms {
+		keys[i] = p.Key()
+	}
+	slices.Sort(keys)
+	return slices.Compact(keys)
+}
+
+// tenantFromContext returns the caller's tenant as set by AuthMiddleware. The
+// tenant is never taken from request input.
+func tenantFromContext(ctx context.Context) (uuid.UUID, error) {
+	tenantID, ok := middleware.GetTenantUUIDFromContext(ctx)
+	if !ok {
+		return uuid.Nil, domain.ErrTenantRequired
+	}
+	return tenantID, nil
 }
 
 // --- mapping helpers ---
@@ -10,4 +10,8 @@ var (
 	ErrSystemRoleReadOnly = errors.New("system roles cannot be modified or deleted")
 	ErrInvalidPermission  = errors.New("permission must include both resource and action")
 	ErrPermissionNotFound = errors.New("permission not found on role")
+	ErrUnknownPermission  = errors.New("permission is not in the catalog")
+	ErrTenantRequired     = errors.New("tenant is required")
+	ErrInvalidRoleName    = errors.New("role name must start with a letter")
+	ErrRoleInUse          = errors.New("role is assigned to members")
 )
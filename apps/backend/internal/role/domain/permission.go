@@ -1,6 +1,9 @@
 package domain
 
-import "strings"
+import (
+	"sort"
+	"strings"
+)
 
 // Permission is a single (resource, action) capability granted to a role.
 //
@@ -23,6 +26,16 @@ const (
 	ResourceRole         = "role"
 	ResourceTenant       = "tenant"
 	ResourceBilling      = "billing"
+	ResourceMember       = "member"
+	// ResourceServiceAccount covers service accounts and their tokens.
+	ResourceServiceAccount = "service_account"
+	// ResourcePlatformUsers covers every account on the platform; only
+	// platform roles hold it.
+	ResourcePlatformUsers = "platform.users"
+	// ResourceAudit covers the tenant's audit trail; ResourcePlatformAudit
+	// the trail of every tenant.
+	ResourceAudit         = "audit"
+	ResourcePlatformAudit = "platform.audit"
 )
 
 // Well-known actions.
@@ -31,6 +44,12 @@ const (
 	ActionWrite  = "write"
 	ActionDelete = "delete"
 	ActionManage = "manage" // superset of read/write/delete on the resource
+	ActionExport = "export"
+
+	// Member management actions (see the member.* catalog keys).
+	ActionInvite     = "invite"
+	ActionRemove     = "remove"
+	ActionUpdateRole = "update_role"
 )
 
 // NormalizeResource canonicalizes resource strings for storage / comparison.
@@ -56,3 +75,79 @@ func (p Permission) IsValid() bool {
 	n := p.Normalize()
 	return n.Resource != "" && n.Action != ""
 }
+
+// Key returns the catalog key ("resource.action") stored in auth.permissions.
+func (p Permission) Key() string {
+	return p.Resource + "." + p.Action
+}
+
+// ParsePermissionKey splits a catalog key into resource and action on the last
+// dot, so "platform.users.manage" becomes {platform.users, manage}.
+func ParsePermissionKey(key string) (Permission, bool) {
+	i := strings.LastIndexByte(key, '.')
+	if i <= 0 || i == len(key)-1 {
+		return Permission{}, false
+	}
+	return Permission{Resource: key[:i], Action: key[i+1:]}.Normalize(), true
+}
+
+// PermissionSet is the effective set of permissions a caller holds in some
+// scope. The zero value grants nothing.
+type PermissionSet map[Permission]struct{}
+
+// NewPermissionSet builds a set from the given permissions, normalizing each.
+func NewPermissionSet(perms ...Permission) PermissionSet {
+	s := make(PermissionSet, len(perms))
+	for _, p := range perms {
+		s[p.Normalize()] = struct{}{}
+	}
+	return s
+}
+
+// Allows reports whether the set grants action on resource. Holding
+// ActionManage on a resource allows every action on it.
+func (s PermissionSet) Allows(resource, action string) bool {
+	resource = NormalizeResource(resource)
+	if _, ok := s[Permission{Resource: resource, Action: NormalizeAction(action)}]; ok {
+		return true
+	}
+	_, ok := s[Permission{Resource: resource, Action: ActionManage}]
+	return ok
+}
+
+// Restrict returns the part of the set the scopes cover, for credentials
+// limited to some permissions. A scope keeps its own action when the set
+// allows it; a manage scope keeps everything the set holds on its resource.
+func (s PermissionSet) Restrict(scopes []Permission) PermissionSet {
+	out := make(PermissionSet, len(scopes))
+	for _, sc := range scopes {
+		sc = sc.Normalize()
+		if sc.Action == ActionManage {
+			for p := range s {
+				if p.Resource == sc.Resource {
+					out[p] = struct{}{}
+				}
+			}
+			continue
+		}
+		if s.Allows(sc.Resource, sc.Action) {
+			out[sc] = struct{}{}
+		}
+	}
+	return out
+}
+
+// List returns the permissions sorted by resource then action.
+func (s PermissionSet) List() []Permission {
+	out := make([]Permission, 0, len(s))
+	for p := range s {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Resource != out[j].Resource {
+			return out[i].Resource < out[j].Resource
+		}
+		return out[i].Action < out[j].Action
+	})
+	return out
+}
@@ -8,12 +8,12 @@ import (
 
 // ListFilter holds the filtering / pagination options for role listing.
 type ListFilter struct {
-	// TenantID, when non-nil, restricts results to roles owned by that tenant.
-	// When nil, includes all roles (use IncludeGlobal to control system roles).
-	TenantID *uuid.UUID
+	// TenantID restricts results to roles owned by that tenant. Required;
+	// roles of other tenants are never listed.
+	TenantID uuid.UUID
 
 	// IncludeGlobal returns global / system roles (tenant_id IS NULL) in
-	// addition to any tenant-scoped results.
+	// addition to the tenant's own roles.
 	IncludeGlobal bool
 
 	// Search is a case-insensitive substring filter on the role name.
@@ -31,47 +31,70 @@ type ListFilter struct {
 // DefaultListFilter returns a ListFilter with sensible defaults.
 func DefaultListFilter() ListFilter {
 	return ListFilter{
-		Page:    1,
-		PerPage: 20,
-		SortBy:  "created_at",
-		SortDir: "desc",
+		IncludeGlobal: true,
+		Page:          1,
+		PerPage:       20,
+		SortBy:        "created_at",
+		SortDir:       "desc",
 	}
 }
 
 // RoleRepository defines the persistence contract for roles and their
 // permissions. Implementations MUST be safe for concurrent use.
+//
+// Reads see the tenant's own roles plus global roles; writes only ever touch
+// roles owned by the given tenant. A role of another tenant behaves exactly
+// like a missing one (ErrRoleNotFound).
 type RoleRepository interface {
-	// Create persists a new role together with its initial permissions in a
-	// single transaction.
+	// Create persists a new tenant role together with its initial
+	// permissions in a single transaction.
 	Create(ctx context.Context, role *Role) error
 
 	// GetByID returns a role by ID with its permissions populated.
-	GetByID(ctx context.Context, id uuid.UUID) (*Role, error)
+	GetByID(ctx context.Context, tenantID, id uuid.UUID) (*Role, error)
 
 	// Update modifies mutable role fields (name, description). It does NOT
 	// touch the permission set; use ReplacePermissions / AddPermission /
 	// RemovePermission for that.
-	Update(ctx context.Context, role *Role) error
+	Update(ctx context.Context, tenantID uuid.UUID, role *Role) error
 
 	// Delete removes a role and (via FK cascade) its permissions.
-	Delete(ctx context.Context, id uuid.UUID) error
+	Delete(ctx context.Context, tenantID, id uuid.UUID) error
 
 	// List returns a paginated, filtered slice of roles plus the total count
 	// matching the filter (ignoring pagination).
 	List(ctx context.Context, f ListFilter) ([]*Role, int64, error)
 
 	// ListPermissions returns the permissions currently granted to a role.
-	ListPermissions(ctx context.Context, roleID uuid.UUID) ([]Permission, error)
+	ListPermissions(ctx context.Context, tenantID, roleID uuid.UUID) ([]Permission, error)
 
-	// AddPermission grants a single permission to a role. Idempotent: adding
-	// an existing (role_id, resource, action) is a no-op.
-	AddPermission(ctx context.Context, roleID uuid.UUID, perm Permission) error
+	// AddPermission grants a single catalog permission to a role. Idempotent:
+	// adding an existing grant is a no-op. Returns ErrUnknownPermission when
+	// the permission is not in the catalog.
+	AddPermission(ctx context.Context, tenantID, roleID uuid.UUID, perm Permission) error
 
 	// RemovePermission revokes a permission from a role. Returns
-	// ErrPermissionNotFound if the row doesn't exist.
-	RemovePermission(ctx context.Context, roleID uuid.UUID, perm Permission) error
+	// ErrPermissionNotFound if the grant doesn't exist.
+	RemovePermission(ctx context.Context, tenantID, roleID uuid.UUID, perm Permission) error
 
 	// ReplacePermissions atomically replaces the entire permission set for a
 	// role. Useful for "PUT /roles/:id/permissions" style endpoints.
-	ReplacePermissions(ctx context.Context, roleID uuid.UUID, perms []Permission) error
+	ReplacePermissions(ctx context.Context, tenantID, roleID uuid.UUID, perms []Permission) error
+}
+
+// PermissionScope identifies whose permissions are resolved and where. A nil
+// ProjectID resolves tenant-level permissions only.
+type PermissionScope struct {
+	UserID    uuid.UUID
+	TenantID  uuid.UUID
+	ProjectID *uuid.UUID
+}
+
+// PermissionRepository reads effective permissions out of the role/permission
+// catalog (auth.roles, auth.permissions, auth.role_permissions).
+type PermissionRepository interface {
+	// EffectivePermissions returns the union of every permission granted to
+	// the user in the scope: tenant-level role grants, project membership
+	// grants (when ProjectID is set) and platform super-admin.
+	EffectivePermissions(ctx context.Context, scope PermissionScope) ([]Permission, error)
 }
@@ -39,6 +39,32 @@ func NewRole(tenantID *uuid.UUID, name, description string) *Role {
 	}
 }
 
+// maxKeyLen mirrors the auth.roles.key column width.
+const maxKeyLen = 50
+
+// Key derives the catalog key stored in auth.roles.key from the role name,
+// e.g. "On-call Engineers" -> "on_call_engineers". It is empty when the name
+// has no ASCII letter to start with.
+func (r *Role) Key() string {
+	var b strings.Builder
+	underscore := false
+	for _, c := range NormalizeName(r.Name) {
+		switch {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9' && b.Len() > 0:
+			b.WriteRune(c)
+			underscore = false
+		case b.Len() > 0 && !underscore:
+			b.WriteByte('_')
+			underscore = true
+		}
+	}
+	key := b.String()
+	if len(key) > maxKeyLen {
+		key = key[:maxKeyLen]
+	}
+	return strings.TrimSuffix(key, "_")
+}
+
 // NormalizeName trims whitespace and lower-cases role names for storage.
 // Role names are treated case-insensitively for uniqueness purposes.
 func NormalizeName(name string) string {
@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/role/domain"
+)
+
+// permissionRepository is a PostgreSQL-backed implementation of
+// domain.PermissionRepository.
+type permissionRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewPermissionRepository constructs a permissionRepository backed by the given pool.
+func NewPermissionRepository(db *pgxpool.Pool) domain.PermissionRepository {
+	return &permissionRepository{db: db}
+}
+
+// effectivePermissionsQuery unions the three grant sources:
+//
+//   - tenant role: until tenants are modelled, every user owns the personal
+//     tenant whose id equals their user id (the auth service stamps that as the
+//     tenant_id claim), so that pairing grants the "owner" role.
+//   - project role: an active project_members row on a live project that
+//     belongs to the tenant.
+//   - service account: the tenant-wide role of an enabled service account
+//     of the tenant (service account ids share the principal id space with
+//     users).
+//   - platform: an active super admin holds every permission in the catalog.
+//
+// A project scope whose project does not belong to the tenant grants nothing,
+// not even the tenant-level permissions.
+const effectivePermissionsQuery = `
+	WITH scope_ok AS (
+		SELECT $3::uuid IS NULL OR EXISTS (
+			SELECT 1 FROM projects
+			WHERE id = $3 AND tenant_id = $2 AND deleted_at IS NULL
+		) AS ok
+	),
+	granted_roles AS (
+		SELECT r.id
+		FROM auth.roles r
+		WHERE r.key = 'owner' AND r.tenant_id IS NULL AND $2::uuid = $1::uuid
+		UNION
+		SELECT pm.role_id
+		FROM project_members pm
+		JOIN projects p ON p.id = pm.project_id
+		WHERE pm.user_id = $1
+		  AND pm.project_id = $3
+		  AND pm.status = 'active'
+		  AND p.tenant_id = $2
+		  AND p.deleted_at IS NULL
+		UNION
+		SELECT sa.role_id
+		FROM auth.service_accounts sa
+		WHERE sa.id = $1
+		  AND sa.tenant_id = $2
+		  AND sa.disabled_at IS NULL
+	)
+	SELECT p.key
+	FROM auth.role_permissions rp
+	JOIN auth.permissions p ON p.id = rp.permission_id
+	WHERE rp.role_id IN (SELECT id FROM granted_roles)
+	  AND (SELECT ok FROM scope_ok)
+	UNION
+	SELECT p.key
+	FROM auth.permissions p
+	WHERE (SELECT ok FROM scope_ok)
+	  AND EXISTS (
+		SELECT 1 FROM auth.users u
+		WHERE u.id = $1 AND u.is_super_admin AND u.is_active AND u.deleted_at IS NULL
+	)
+`
+
+func (r *permissionRepository) EffectivePermissions(ctx context.Context, scope domain.PermissionScope) ([]domain.Permission, error) {
+	rows, err := r.db.Query(ctx, effectivePermissionsQuery, scope.UserID, scope.TenantID, scope.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	perms := make([]domain.Permission, 0)
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		if p, ok := domain.ParsePermissionKey(key); ok {
+			perms = append(perms, p)
+		}
+	}
+	return perms, rows.Err()
+}
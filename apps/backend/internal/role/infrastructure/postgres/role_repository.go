@@ -59,15 +59,33 @@ func normalizeListFilter(f domain.ListFilter) (page, perPage int, sortCol, sortD
 	return
 }
 
+// roleColumns is the column list scanned by scanRole.
+const roleColumns = `id, tenant_id, name, COALESCE(description, ''), is_system, created_at, updated_at`
+
+func scanRole(row pgx.Row) (*domain.Role, error) {
+	var role domain.Role
+	if err := row.Scan(
+		&role.ID, &role.TenantID, &role.Name, &role.Description, &role.IsSystem,
+		&role.CreatedAt, &role.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
 func (r *roleRepository) Create(ctx context.Context, role *domain.Role) error {
+	if role.TenantID == nil {
+		return domain.ErrTenantRequired
+	}
 	return withTx(ctx, r.db, func(tx pgx.Tx) error {
 		const insertRole = `
-			INSERT INTO roles (id, tenant_id, name, description, is_system, created_at, updated_at)
-			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			INSERT INTO auth.roles (id, tenant_id, key, name, description, scope, is_system, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, 'workspace', $6, $7, $8)
 		`
 		_, err := tx.Exec(ctx, insertRole,
 			role.ID,
 			role.TenantID,
+			role.Key(),
 			role.Name,
 			nullableText(role.Description),
 			role.IsSystem,
@@ -81,46 +99,42 @@ func (r *roleRepository) Create(ctx context.Context, role *domain.Role) error {
 		if len(role.Permissions) == 0 {
 			return nil
 		}
-		return bulkInsertPermissions(ctx, tx, role.ID, role.Permissions)
+		return insertPermissions(ctx, tx, role.ID, role.Permissions)
 	})
 }
 
-func (r *roleRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Role, error) {
-	const q = `
-		SELECT id, tenant_id, name, COALESCE(description, ''), is_system, created_at, updated_at
-		FROM roles
-		WHERE id = $1
+func (r *roleRepository) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*domain.Role, error) {
+	q := `
+		SELECT ` + roleColumns + `
+		FROM auth.roles
+		WHERE id = $1 AND (tenant_id = $2 OR tenant_id IS NULL)
 	`
-	row := r.db.QueryRow(ctx, q, id)
-
-	var role domain.Role
-	if err := row.Scan(
-		&role.ID, &role.TenantID, &role.Name, &role.Description, &role.IsSystem,
-		&role.CreatedAt, &role.UpdatedAt,
-	); err != nil {
+	role, err := scanRole(r.db.QueryRow(ctx, q, id, tenantID))
+	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, domain.ErrRoleNotFound
 		}
 		return nil, err
 	}
 
-	perms, err := r.ListPermissions(ctx, role.ID)
+	perms, err := r.ListPermissions(ctx, tenantID, role.ID)
 	if err != nil {
 		return nil, err
 	}
 	role.Permissions = perms
-	return &role, nil
+	return role, nil
 }
 
-func (r *roleRepository) Update(ctx context.Context, role *domain.Role) error {
+func (r *roleRepository) Update(ctx context.Context, tenantID uuid.UUID, role *domain.Role) error {
 	const q = `
-		UPDATE roles
-		SET name = $2,
-		    description = $3,
+		UPDATE auth.roles
+		SET key = $3,
+		    name = $4,
+		    description = $5,
 		    updated_at = (now() AT TIME ZONE 'utc')
-		WHERE id = $1
+		WHERE id = $1 AND tenant_id = $2
 	`
-	tag, err := r.db.Exec(ctx, q, role.ID, role.Name, nullableText(role.Description))
+	tag, err := r.db.Exec(ctx, q, role.ID, tenantID, role.Key(), role.Name, nullableText(role.Description))
 	if err != nil {
 		return mapUniqueViolation(err)
 	}
@@ -130,10 +144,13 @@ func (r *roleRepository) Update(ctx context.Context, role *domain.Role) error {
 	return nil
 }
 
-func (r *roleRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	const q = `DELETE FROM roles WHERE id = $1`
-	tag, err := r.db.Exec(ctx, q, id)
+func (r *roleRepository) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	const q = `DELETE FROM auth.roles WHERE id = $1 AND tenant_id = $2`
+	tag, err := r.db.Exec(ctx, q, id, tenantID)
 	if err != nil {
+		if isForeignKeyViolation(err) {
+			return domain.ErrRoleInUse
+		}
 		return err
 	}
 	if tag.RowsAffected() == 0 {
@@ -143,6 +160,9 @@ func (r *roleRepository) Delete(ctx context.Context, id uuid.UUID) error {
 }
 
 func (r *roleRepository) List(ctx context.Context, f domain.ListFilter) ([]*domain.Role, int64, error) {
+	if f.TenantID == uuid.Nil {
+		return nil, 0, domain.ErrTenantRequired
+	}
 	page, perPage, sortCol, sortDir := normalizeListFilter(f)
 	offset := (page - 1) * perPage
 
@@ -155,17 +175,11 @@ func (r *roleRepository) List(ctx context.Context, f domain.ListFilter) ([]*doma
 		conds = append(conds, fmt.Sprintf(cond, len(args)))
 	}
 
-	switch {
-	case f.TenantID != nil && f.IncludeGlobal:
-		add("(tenant_id = $%d OR tenant_id IS NULL)", *f.TenantID)
-	case f.TenantID != nil:
-		add("tenant_id = $%d", *f.TenantID)
-	case !f.IncludeGlobal:
-		// Restrict to tenant-scoped (non-global) roles when caller does not
-		// opt in. By default we surface everything; flip this if you want
-		// stricter isolation.
+	if f.IncludeGlobal {
+		add("(tenant_id = $%d OR tenant_id IS NULL)", f.TenantID)
+	} else {
+		add("tenant_id = $%d", f.TenantID)
 	}
-
 	if s := strings.TrimSpace(f.Search); s != "" {
 		add("LOWER(name) LIKE '%%' || LOWER($%d) || '%%'", s)
 	}
@@ -173,13 +187,10 @@ func (r *roleRepository) List(ctx context.Context, f domain.ListFilter) ([]*doma
 		add("is_system = $%d", *f.IsSystem)
 	}
 
-	where := ""
-	if len(conds) > 0 {
-		where = "WHERE " + strings.Join(conds, " AND ")
-	}
+	where := "WHERE " + strings.Join(conds, " AND ")
 
 	var total int64
-	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM roles %s`, where)
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM auth.roles %s`, where)
 	if err := r.db.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
@@ -189,12 +200,12 @@ func (r *roleRepository) List(ctx context.Context, f domain.ListFilter) ([]*doma
 
 	args = append(args, perPage, offset)
 	listQuery := fmt.Sprintf(`
-		SELECT id, tenant_id, name, COALESCE(description, ''), is_system, created_at, updated_at
-		FROM roles
+		SELECT %s
+		FROM auth.roles
 		%s
 		ORDER BY %s %s
 		LIMIT $%d OFFSET $%d
-	`, where, sortCol, sortDir, len(args)-1, len(args))
+	`, roleColumns, where, sortCol, sortDir, len(args)-1, len(args))
 
 	rows, err := r.db.Query(ctx, listQuery, args...)
 	if err != nil {
@@ -205,14 +216,11 @@ func (r *roleRepository) List(ctx context.Context, f domain.ListFilter) ([]*doma
 	roles := make([]*domain.Role, 0)
 	ids := make([]uuid.UUID, 0)
 	for rows.Next() {
-		var role domain.Role
-		if err := rows.Scan(
-			&role.ID, &role.TenantID, &role.Name, &role.Description,
-			&role.IsSystem, &role.CreatedAt, &role.UpdatedAt,
-		); err != nil {
+		role, err := scanRole(rows)
+		if err != nil {
 			return nil, 0, err
 		}
-		roles = append(roles, &role)
+		roles = append(roles, role)
 		ids = append(ids, role.ID)
 	}
 	if err := rows.Err(); err != nil {
@@ -233,14 +241,16 @@ func (r *roleRepository) List(ctx context.Context, f domain.ListFilter) ([]*doma
 	return roles, total, nil
 }
 
-func (r *roleRepository) ListPermissions(ctx context.Context, roleID uuid.UUID) ([]domain.Permission, error) {
+func (r *roleRepository) ListPermissions(ctx context.Context, tenantID, roleID uuid.UUID) ([]domain.Permission, error) {
 	const q = `
-		SELECT resource, action
-		FROM role_permissions
-		WHERE role_id = $1
-		ORDER BY resource ASC, action ASC
+		SELECT p.key
+		FROM auth.role_permissions rp
+		JOIN auth.permissions p ON p.id = rp.permission_id
+		JOIN auth.roles r ON r.id = rp.role_id
+		WHERE rp.role_id = $1 AND (r.tenant_id = $2 OR r.tenant_id IS NULL)
+		ORDER BY p.key ASC
 	`
-	rows, err := r.db.Query(ctx, q, roleID)
+	rows, err := r.db.Query(ctx, q, roleID, tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -248,36 +258,37 @@ func (r *roleRepository) ListPermissions(ctx context.Context, roleID uuid.UUID)
 
 	perms := make([]domain.Permission, 0)
 	for rows.Next() {
-		var p domain.Permission
-		if err := rows.Scan(&p.Resource, &p.Action); err != nil {
+		var key string
+		if err := rows.Scan(&key); err != nil {
 			return nil, err
 		}
-		perms = append(perms, p)
+		if p, ok := domain.ParsePermissionKey(key); ok {
+			perms = append(perms, p)
+		}
 	}
 	return perms, rows.Err()
 }
 
-func (r *roleRepository) AddPermission(ctx context.Context, roleID uuid.UUID, perm domain.Permission) error {
-	const q = `
-		INSERT INTO role_permissions (role_id, resource, action)
-		VALUES ($1, $2, $3)
-		ON CONFLICT (role_id, resource, action) DO NOTHING
-	`
-	if _, err := r.db.Exec(ctx, q, roleID, perm.Resource, perm.Action); err != nil {
-		if isForeignKeyViolation(err) {
-			return domain.ErrRoleNotFound
+func (r *roleRepository) AddPermission(ctx context.Context, tenantID, roleID uuid.UUID, perm domain.Permission) error {
+	return withTx(ctx, r.db, func(tx pgx.Tx) error {
+		if err := ensureTenantRole(ctx, tx, tenantID, roleID); err != nil {
+			return err
 		}
-		return err
-	}
-	return nil
+		return insertPermissions(ctx, tx, roleID, []domain.Permission{perm})
+	})
 }
 
-func (r *roleRepository) RemovePermission(ctx context.Context, roleID uuid.UUID, perm domain.Permission) error {
+func (r *roleRepository) RemovePermission(ctx context.Context, tenantID, roleID uuid.UUID, perm domain.Permission) error {
 	const q = `
-		DELETE FROM role_permissions
-		WHERE role_id = $1 AND resource = $2 AND action = $3
+		DELETE FROM auth.role_permissions rp
+		USING auth.roles r, auth.permissions p
+		WHERE rp.role_id = r.id
+		  AND rp.permission_id = p.id
+		  AND r.id = $1
+		  AND r.tenant_id = $2
+		  AND p.key = $3
 	`
-	tag, err := r.db.Exec(ctx, q, roleID, perm.Resource, perm.Action)
+	tag, err := r.db.Exec(ctx, q, roleID, tenantID, perm.Key())
 	if err != nil {
 		return err
 	}
@@ -287,36 +298,45 @@ func (r *roleRepository) RemovePermission(ctx context.Context, roleID uuid.UUID,
 	return nil
 }
 
-func (r *roleRepository) ReplacePermissions(ctx context.Context, roleID uuid.UUID, perms []domain.Permission) error {
+func (r *roleRepository) ReplacePermissions(ctx context.Context, tenantID, roleID uuid.UUID, perms []domain.Permission) error {
 	return withTx(ctx, r.db, func(tx pgx.Tx) error {
-		var exists bool
-		if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM roles WHERE id = $1)`, roleID).Scan(&exists); err != nil {
+		if err := ensureTenantRole(ctx, tx, tenantID, roleID); err != nil {
 			return err
 		}
-		if !exists {
-			return domain.ErrRoleNotFound
-		}
-
-		if _, err := tx.Exec(ctx, `DELETE FROM role_permissions WHERE role_id = $1`, roleID); err != nil {
+		if _, err := tx.Exec(ctx, `DELETE FROM auth.role_permissions WHERE role_id = $1`, roleID); err != nil {
 			return err
 		}
 		if len(perms) == 0 {
 			return nil
 		}
-		return bulkInsertPermissions(ctx, tx, roleID, perms)
+		return insertPermissions(ctx, tx, roleID, perms)
 	})
 }
 
 // --- helpers ---
 
+// ensureTenantRole locks the role row and verifies the tenant owns it.
+func ensureTenantRole(ctx context.Context, tx pgx.Tx, tenantID, roleID uuid.UUID) error {
+	var one int
+	const q = `SELECT 1 FROM auth.roles WHERE id = $1 AND tenant_id = $2 FOR UPDATE`
+	if err := tx.QueryRow(ctx, q, roleID, tenantID).Scan(&one); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrRoleNotFound
+		}
+		return err
+	}
+	return nil
+}
+
 // permissionsForRoles bulk-loads permissions for the provided role IDs and
 // returns them grouped by role_id.
 func (r *roleRepository) permissionsForRoles(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID][]domain.Permission, error) {
 	const q = `
-		SELECT role_id, resource, action
-		FROM role_permissions
-		WHERE role_id = ANY($1)
-		ORDER BY role_id, resource, action
+		SELECT rp.role_id, p.key
+		FROM auth.role_permissions rp
+		JOIN auth.permissions p ON p.id = rp.permission_id
+		WHERE rp.role_id = ANY($1)
+		ORDER BY rp.role_id, p.key
 	`
 	rows, err := r.db.Query(ctx, q, ids)
 	if err != nil {
@@ -327,39 +347,52 @@ func (r *roleRepository) permissionsForRoles(ctx context.Context, ids []uuid.UUI
 	out := make(map[uuid.UUID][]domain.Permission, len(ids))
 	for rows.Next() {
 		var (
-			id uuid.UUID
-			p  domain.Permission
+			id  uuid.UUID
+			key string
 		)
-		if err := rows.Scan(&id, &p.Resource, &p.Action); err != nil {
+		if err := rows.Scan(&id, &key); err != nil {
 			return nil, err
 		}
-		out[id] = append(out[id], p)
+		if p, ok := domain.ParsePermissionKey(key); ok {
+			out[id] = append(out[id], p)
+		}
 	}
 	return out, rows.Err()
 }
 
-// bulkInsertPermissions inserts a set of permissions for a role using a single
-// multi-row INSERT. Conflicts on the composite PK are silently ignored so
-// callers may pass duplicates safely.
-func bulkInsertPermissions(ctx context.Context, tx pgx.Tx, roleID uuid.UUID, perms []domain.Permission) error {
-	if len(perms) == 0 {
-		return nil
+// insertPermissions grants catalog permissions to a role in one statement.
+// Only workspace-scoped permissions are grantable to tenant roles; any key
+// missing from that part of the catalog fails with ErrUnknownPermission.
+// Existing grants are silently ignored so callers may pass duplicates safely.
+func insertPermissions(ctx context.Context, tx pgx.Tx, roleID uuid.UUID, perms []domain.Permission) error {
+	keys := make([]string, 0, len(perms))
+	seen := make(map[string]struct{}, len(perms))
+	for _, p := range perms {
+		k := p.Key()
+		if _, dup := seen[k]; dup {
+			continue
+		}
+		seen[k] = struct{}{}
+		keys = append(keys, k)
 	}
-	var (
-		placeholders = make([]string, 0, len(perms))
-		args         = make([]any, 0, len(perms)*3)
-	)
-	for i, p := range perms {
-		base := i * 3
-		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d)", base+1, base+2, base+3))
-		args = append(args, roleID, p.Resource, p.Action)
-	}
-	query := fmt.Sprintf(
-		`INSERT INTO role_permissions (role_id, resource, action) VALUES %s
-		 ON CONFLICT (role_id, resource, action) DO NOTHING`,
-		strings.Join(placeholders, ", "),
-	)
-	_, err := tx.Exec(ctx, query, args...)
+
+	var known int
+	const count = `SELECT COUNT(*) FROM auth.permissions WHERE key = ANY($1) AND scope = 'workspace'`
+	if err := tx.QueryRow(ctx, count, keys).Scan(&known); err != nil {
+		return err
+	}
+	if known != len(keys) {
+		return domain.ErrUnknownPermission
+	}
+
+	const q = `
+		INSERT INTO auth.role_permissions (role_id, permission_id)
+		SELECT $1, p.id
+		FROM auth.permissions p
+		WHERE p.key = ANY($2) AND p.scope = 'workspace'
+		ON CONFLICT (role_id, permission_id) DO NOTHING
+	`
+	_, err := tx.Exec(ctx, q, roleID, keys)
 	return err
 }
 
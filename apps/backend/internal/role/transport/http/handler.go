@@ -10,6 +10,7 @@ import (
 
 	"github.com/indalyadav56/logify/apps/backend/internal/role/application"
 	"github.com/indalyadav56/logify/apps/backend/internal/role/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
 	"github.com/indalyadav56/logify/apps/backend/pkg/response"
 	"github.com/indalyadav56/logify/apps/backend/pkg/validator"
 )
@@ -26,6 +27,8 @@ type RoleHandler interface {
 	AddPermission(c *gin.Context)
 	RemovePermission(c *gin.Context)
 	ReplacePermissions(c *gin.Context)
+
+	EffectivePermissions(c *gin.Context)
 }
 
 type roleHandler struct {
@@ -43,6 +46,14 @@ type replacePermissionsRequest struct {
 	Permissions []application.PermissionInput `json:"permissions" validate:"required,dive"`
 }
 
+// effectivePermissionsResponse is returned by EffectivePermissions so the UI
+// knows which scope the list applies to.
+type effectivePermissionsResponse struct {
+	TenantID    uuid.UUID                     `json:"tenant_id"`
+	ProjectID   *uuid.UUID                    `json:"project_id,omitempty"`
+	Permissions []application.PermissionInput `json:"permissions"`
+}
+
 // CreateRole handles POST /v1/roles
 func (h *roleHandler) CreateRole(c *gin.Context) {
 	var input application.CreateRoleInput
@@ -130,11 +141,6 @@ func (h *roleHandler) ListRoles(c *gin.Context) {
 	if v := c.Query("sort_dir"); v != "" {
 		filter.SortDir = v
 	}
-	if v := c.Query("tenant_id"); v != "" {
-		if id, err := uuid.Parse(v); err == nil {
-			filter.TenantID = &id
-		}
-	}
 	if v := c.Query("is_system"); v != "" {
 		if b, err := strconv.ParseBool(v); err == nil {
 			filter.IsSystem = &b
@@ -148,7 +154,7 @@ func (h *roleHandler) ListRoles(c *gin.Context) {
 
 	roles, total, err := h.service.ListRoles(c.Request.Context(), filter)
 	if err != nil {
-		response.InternalServerError(c, "Failed to list roles")
+		h.writeError(c, err, "Failed to list roles")
 		return
 	}
 	response.Paginated(c, roles, filter.Page, filter.PerPage, total)
@@ -219,6 +225,41 @@ func (h *roleHandler) ReplacePermissions(c *gin.Context) {
 	response.OK(c, "Permissions replaced successfully", body)
 }
 
+// EffectivePermissions handles GET /v1/me/permissions?project_id=
+func (h *roleHandler) EffectivePermissions(c *gin.Context) {
+	userID, ok := middleware.UserUUIDFromContext(c.Request.Context())
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	tenantID, ok := middleware.GetTenantUUIDFromContext(c.Request.Context())
+	if !ok {
+		response.Unauthorized(c, "Tenant not resolved")
+		return
+	}
+
+	scope := domain.PermissionScope{UserID: userID, TenantID: tenantID}
+	if v := c.Query("project_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			response.BadRequest(c, "Invalid project_id format")
+			return
+		}
+		scope.ProjectID = &id
+	}
+
+	perms, err := h.service.EffectivePermissions(c.Request.Context(), scope)
+	if err != nil {
+		response.InternalServerError(c, "Failed to resolve permissions")
+		return
+	}
+	response.OK(c, "Permissions retrieved successfully", effectivePermissionsResponse{
+		TenantID:    tenantID,
+		ProjectID:   scope.ProjectID,
+		Permissions: perms,
+	})
+}
+
 // --- helpers ---
 
 // writeError maps domain errors to HTTP responses with a consistent envelope.
@@ -234,6 +275,14 @@ func (h *roleHandler) writeError(c *gin.Context, err error, fallback string) {
 		response.Forbidden(c, "System roles cannot be modified or deleted")
 	case errors.Is(err, domain.ErrInvalidPermission):
 		response.BadRequest(c, "Permission must include both resource and action")
+	case errors.Is(err, domain.ErrUnknownPermission):
+		response.BadRequest(c, "Permission is not in the catalog")
+	case errors.Is(err, domain.ErrInvalidRoleName):
+		response.BadRequest(c, "Role name must start with a letter")
+	case errors.Is(err, domain.ErrRoleInUse):
+		response.Conflict(c, "Role is assigned to members")
+	case errors.Is(err, domain.ErrTenantRequired):
+		response.Unauthorized(c, "Tenant ID is required")
 	default:
 		response.InternalServerError(c, fallback)
 	}
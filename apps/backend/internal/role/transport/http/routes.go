@@ -2,26 +2,34 @@ package http
 
 import (
 	"github.com/gin-gonic/gin"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/role/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
 )
 
-func RegisterRoutes(router *gin.RouterGroup, handler RoleHandler, jwtSecret string) {
-	// auth := middleware.AuthMiddleware(jwtSecret)
+// RegisterRoutes mounts the role routes on the given router group. The group
+// is expected to be already authenticated (see di.RegisterAllRoutes).
+func RegisterRoutes(router *gin.RouterGroup, handler RoleHandler, authz *middleware.Authorizer) {
+	canRead := authz.RequirePermission(domain.ResourceRole, domain.ActionRead)
+	canManage := authz.RequirePermission(domain.ResourceRole, domain.ActionManage)
+
+	// Every authenticated caller may ask what they themselves can do.
+	router.GET("/v1/me/permissions", handler.EffectivePermissions)
 
-	roles := router.Group("/roles")
-	// roles.Use(auth)
+	roles := router.Group("/v1/roles")
 	{
-		roles.GET("", handler.ListRoles)
-		roles.POST("", handler.CreateRole)
-		roles.GET("/:id", handler.GetRole)
-		roles.PUT("/:id", handler.UpdateRole)
-		roles.DELETE("/:id", handler.DeleteRole)
+		roles.GET("", canRead, handler.ListRoles)
+		roles.POST("", canManage, handler.CreateRole)
+		roles.GET("/:id", canRead, handler.GetRole)
+		roles.PUT("/:id", canManage, handler.UpdateRole)
+		roles.DELETE("/:id", canManage, handler.DeleteRole)
 
 		perms := roles.Group("/:id/permissions")
 		{
-			perms.GET("", handler.ListPermissions)
-			perms.POST("", handler.AddPermission)
-			perms.PUT("", handler.ReplacePermissions)
-			perms.DELETE("/:resource/:action", handler.RemovePermission)
+			perms.GET("", canRead, handler.ListPermissions)
+			perms.POST("", canManage, handler.AddPermission)
+			perms.PUT("", canManage, handler.ReplacePermissions)
+			perms.DELETE("/:resource/:action", canManage, handler.RemovePermission)
 		}
 	}
 }
@@ -0,0 +1,9 @@
+package application
+
+import "github.com/indalyadav56/logify/apps/backend/internal/sampling/domain"
+
+// PolicyInput replaces a project's sampling rules. They are tried in order
+// and the first that matches a log decides; an empty list keeps every log.
+type PolicyInput struct {
+	Rules []domain.Rule `json:"rules"`
+}
@@ -0,0 +1,150 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	ingestDomain "github.com/indalyadav56/logify/apps/backend/internal/ingest/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/sampling/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
+	"github.com/indalyadav56/logify/apps/backend/pkg/ttlcache"
+)
+
+const (
+	// defaultPolicyCacheTTL bounds how long replicas that did not see a
+	// policy change keep sampling with the old rules.
+	defaultPolicyCacheTTL = time.Minute
+
+	// maxPolicyCacheEntries caps memory; the cache is simply reset when full.
+	maxPolicyCacheEntries = 10_000
+)
+
+type SamplingService interface {
+	GetPolicy(ctx context.Context, projectID uuid.UUID) (*domain.Policy, error)
+	// SetPolicy replaces the project's rules. Logs already stored are not
+	// affected.
+	SetPolicy(ctx context.Context, projectID uuid.UUID, input PolicyInput) (*domain.Policy, error)
+	// DeletePolicy removes the project's rules, so every log is kept.
+	DeletePolicy(ctx context.Context, projectID uuid.UUID) error
+
+	// SampleRate returns how many logs a new log stands for: 0 to drop it,
+	// 1 to keep it unsampled, N when one in N such logs is kept. It is on
+	// the ingest path and cached per project.
+	SampleRate(ctx context.Context, tenantID, projectID uuid.UUID, log ingestDomain.Log) (uint32, error)
+}
+
+type samplingService struct {
+	repo   domain.PolicyRepository
+	logger *zap.Logger
+	now    func() time.Time
+	cache  *ttlcache.Cache[uuid.UUID, *domain.Policy]
+}
+
+func NewSamplingService(repo domain.PolicyRepository, logger *zap.Logger) SamplingService {
+	return &samplingService{
+		repo:   repo,
+		logger: logger.Named("sampling_service"),
+		now:    time.Now,
+		cache:  ttlcache.New[uuid.UUID, *domain.Policy](defaultPolicyCacheTTL, maxPolicyCacheEntries),
+	}
+}
+
+func (s *samplingService) GetPolicy(ctx context.Context, projectID uuid.UUID) (*domain.Policy, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.Get(ctx, tenantID, projectID)
+}
+
+func (s *samplingService) SetPolicy(ctx context.Context, projectID uuid.UUID, input PolicyInput) (*domain.Policy, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	policy := &domain.Policy{TenantID: tenantID, ProjectID: projectID, Rules: input.Rules}
+	if policy.Rules == nil {
+		policy.Rules = []domain.Rule{}
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Replace(ctx, policy); err != nil {
+		return nil, err
+	}
+	s.invalidate(projectID)
+	s.logger.Info("sampling policy updated",
+		zap.String("tenant_id", tenantID.String()),
+		zap.String("project_id", projectID.String()),
+		zap.Int("rules", len(policy.Rules)),
+	)
+	return policy, nil
+}
+
+func (s *samplingService) DeletePolicy(ctx context.Context, projectID uuid.UUID) error {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, tenantID, projectID); err != nil {
+		return err
+	}
+	s.invalidate(projectID)
+	s.logger.Info("sampling policy deleted",
+		zap.String("tenant_id", tenantID.String()),
+		zap.String("project_id", projectID.String()),
+	)
+	return nil
+}
+
+func (s *samplingService) SampleRate(ctx context.Context, tenantID, projectID uuid.UUID, log ingestDomain.Log) (uint32, error) {
+	policy, err := s.cachedPolicy(ctx, tenantID, projectID)
+	if err != nil {
+		return 0, err
+	}
+	if len(policy.Rules) == 0 {
+		return 1, nil
+	}
+	return policy.Decide(&domain.Entry{
+		Level:       log.Level,
+		Service:     log.Service,
+		Environment: log.Environment,
+		Host:        log.Hostname,
+		Message:     log.Message,
+		TraceID:     log.TraceID,
+		RequestID:   log.RequestID,
+		UserID:      log.UserID,
+		Tags:        log.Tags,
+	}).SampleRate, nil
+}
+
+func (s *samplingService) cachedPolicy(ctx context.Context, tenantID, projectID uuid.UUID) (*domain.Policy, error) {
+	now := s.now()
+	if policy, ok := s.cache.Get(projectID, now); ok && policy.TenantID == tenantID {
+		return policy, nil
+	}
+
+	policy, err := s.repo.Get(ctx, tenantID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.Set(projectID, policy, now)
+	return policy, nil
+}
+
+func (s *samplingService) invalidate(projectID uuid.UUID) {
+	s.cache.Delete(projectID)
+}
+
+// tenantFromContext returns the caller's tenant as set by AuthMiddleware. The
+// tenant is never taken from request input.
+func tenantFromContext(ctx context.Context) (uuid.UUID, error) {
+	tenantID, ok := middleware.GetTenantUUIDFromContext(ctx)
+	if !ok {
+		return uuid.Nil, domain.ErrTenantRequired
+	}
+	return tenantID, nil
+}
@@ -0,0 +1,12 @@
+package domain
+
+import "errors"
+
+// Domain errors. Service / transport layers compare against these with
+// errors.Is to map them onto HTTP status codes.
+var (
+	ErrTenantRequired  = errors.New("tenant is required")
+	ErrProjectNotFound = errors.New("project not found")
+	// ErrInvalidPolicy is wrapped by every validation error of a policy.
+	ErrInvalidPolicy = errors.New("invalid sampling policy")
+)
@@ -0,0 +1,253 @@
+package domain
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand/v2"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxRules bounds the rules of a policy; every log is tested against
+	// them on the ingest path.
+	MaxRules = 100
+	// MaxMatchValues bounds each list of values a rule matches.
+	MaxMatchValues = 50
+	// MaxMessageContains bounds the substring a rule looks for.
+	MaxMessageContains = 256
+	// MaxSampleRate is the sparsest sampling: one log in a million.
+	MaxSampleRate = 1_000_000
+)
+
+// Action is what a rule does with the logs it matches.
+type Action string
+
+const (
+	// ActionKeep stores every matching log, e.g. errors ahead of a rule
+	// that would sample them.
+	ActionKeep Action = "keep"
+	// ActionDrop discards every matching log.
+	ActionDrop Action = "drop"
+	// ActionSample stores one matching log in SampleRate.
+	ActionSample Action = "sample"
+)
+
+// Fields a rule's Key may name besides tags.<key>.
+const (
+	KeyTraceID   = "trace_id"
+	KeyRequestID = "request_id"
+	KeyUserID    = "user_id"
+	KeyService   = "service"
+	KeyHost      = "host"
+	tagsPrefix   = "tags."
+)
+
+// Match selects logs. Every condition set must hold; values within one list
+// are alternatives. An empty Match selects every log.
+type Match struct {
+	Services []string `json:"services,omitempty"`
+	// Levels are compared case-insensitively.
+	Levels       []string `json:"levels,omitempty"`
+	Environments []string `json:"environments,omitempty"`
+	Hosts        []string `json:"hosts,omitempty"`
+	// MessageContains is a case-sensitive substring of the message.
+	MessageContains string            `json:"message_contains,omitempty"`
+	Tags            map[string]string `json:"tags,omitempty"`
+}
+
+// Rule decides the fate of the logs it matches.
+type Rule struct {
+	Name   string `json:"name"`
+	Match  Match  `json:"match"`
+	Action Action `json:"action"`
+	// SampleRate keeps one log in SampleRate; sample rules only.
+	SampleRate uint32 `json:"sample_rate,omitempty"`
+	// Key names the field whose value decides a sample rule, so logs that
+	// share it are kept or dropped together: trace_id keeps whole traces.
+	// Logs without the field, or rules without a Key, are sampled at random.
+	Key string `json:"key,omitempty"`
+}
+
+// Policy is a project's sampling. The first rule matching a log decides;
+// logs no rule matches are kept.
+type Policy struct {
+	TenantID  uuid.UUID  `json:"-"`
+	ProjectID uuid.UUID  `json:"project_id"`
+	Rules     []Rule     `json:"rules"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// Entry is the part of a log rules look at.
+type Entry struct {
+	Level       string
+	Service     string
+	Environment string
+	Host        string
+	Message     string
+	TraceID     string
+	RequestID   string
+	UserID      string
+	Tags        map[string]string
+}
+
+// Decision is a policy's verdict on a log.
+type Decision struct {
+	// SampleRate is the number of logs the stored one stands for: 1 when
+	// kept unsampled, 0 when dropped.
+	SampleRate uint32
+	// Rule names the deciding rule; empty when none matched.
+	Rule string
+}
+
+// Keep reports whether the log is stored.
+func (d Decision) Keep() bool {
+	return d.SampleRate > 0
+}
+
+var ruleName = regexp.MustCompile(`^[a-z0-9][a-z0-9_.\-]{0,63}$`)
+
+// Validate checks every rule. Every error wraps ErrInvalidPolicy.
+func (p Policy) Validate() error {
+	if len(p.Rules) > MaxRules {
+		return fmt.Errorf("%w: more than %d rules", ErrInvalidPolicy, MaxRules)
+	}
+	seen := make(map[string]bool, len(p.Rules))
+	for i, r := range p.Rules {
+		if err := r.validate(); err != nil {
+			return fmt.Errorf("%w: rule %d: %v", ErrInvalidPolicy, i, err)
+		}
+		if seen[r.Name] {
+			return fmt.Errorf("%w: rule name %q is used twice", ErrInvalidPolicy, r.Name)
+		}
+		seen[r.Name] = true
+	}
+	return nil
+}
+
+func (r Rule) validate() error {
+	if !ruleName.MatchString(r.Name) {
+		return fmt.Errorf("name must be 1-64 lowercase letters, digits, '_', '.' or '-'")
+	}
+	switch r.Action {
+	case ActionKeep, ActionDrop:
+		if r.SampleRate != 0 || r.Key != "" {
+			return fmt.Errorf("sample_rate and key are for sample rules only")
+		}
+	case ActionSample:
+		if r.SampleRate < 2 || r.SampleRate > MaxSampleRate {
+			return fmt.Errorf("sample_rate must be between 2 and %d", MaxSampleRate)
+		}
+		if r.Key != "" && !validKey(r.Key) {
+			return fmt.Errorf("invalid key %q", r.Key)
+		}
+	default:
+		return fmt.Errorf("unknown action %q", r.Action)
+	}
+	m := r.Match
+	for _, values := range [][]string{m.Services, m.Levels, m.Environments, m.Hosts} {
+		if len(values) > MaxMatchValues {
+			return fmt.Errorf("more than %d values in one match list", MaxMatchValues)
+		}
+	}
+	if len(m.Tags) > MaxMatchValues {
+		return fmt.Errorf("more than %d tags to match", MaxMatchValues)
+	}
+	if len(m.MessageContains) > MaxMessageContains {
+		return fmt.Errorf("message_contains is longer than %d bytes", MaxMessageContains)
+	}
+	return nil
+}
+
+func validKey(key string) bool {
+	switch key {
+	case KeyTraceID, KeyRequestID, KeyUserID, KeyService, KeyHost:
+		return true
+	}
+	tag, ok := strings.CutPrefix(key, tagsPrefix)
+	return ok && tag != ""
+}
+
+// Decide applies the policy to e.
+func (p Policy) Decide(e *Entry) Decision {
+	for _, r := range p.Rules {
+		if !r.Match.matches(e) {
+			continue
+		}
+		switch r.Action {
+		case ActionDrop:
+			return Decision{Rule: r.Name}
+		case ActionSample:
+			if !r.sampled(e) {
+				return Decision{Rule: r.Name}
+			}
+			return Decision{SampleRate: r.SampleRate, Rule: r.Name}
+		default:
+			return Decision{SampleRate: 1, Rule: r.Name}
+		}
+	}
+	return Decision{SampleRate: 1}
+}
+
+func (m Match) matches(e *Entry) bool {
+	if len(m.Services) > 0 && !slices.Contains(m.Services, e.Service) {
+		return false
+	}
+	if len(m.Levels) > 0 && !slices.ContainsFunc(m.Levels, func(l string) bool { return strings.EqualFold(l, e.Level) }) {
+		return false
+	}
+	if len(m.Environments) > 0 && !slices.Contains(m.Environments, e.Environment) {
+		return false
+	}
+	if len(m.Hosts) > 0 && !slices.Contains(m.Hosts, e.Host) {
+		return false
+	}
+	if m.MessageContains != "" && !strings.Contains(e.Message, m.MessageContains) {
+		return false
+	}
+	for k, v := range m.Tags {
+		if got, ok := e.Tags[k]; !ok || got != v {
+			return false
+		}
+	}
+	return true
+}
+
+// sampled reports whether e is one of the logs kept. A keyed decision
+// compares the hash of the key against the same threshold for any rate, so
+// a value kept at rate N is also kept at every rate below N.
+func (r Rule) sampled(e *Entry) bool {
+	var h uint64
+	if v := e.field(r.Key); v != "" {
+		f := fnv.New64a()
+		f.Write([]byte(v))
+		h = f.Sum64()
+	} else {
+		h = rand.Uint64()
+	}
+	return h <= math.MaxUint64/uint64(r.SampleRate)
+}
+
+func (e *Entry) field(key string) string {
+	switch key {
+	case "":
+		return ""
+	case KeyTraceID:
+		return e.TraceID
+	case KeyRequestID:
+		return e.RequestID
+	case KeyUserID:
+		return e.UserID
+	case KeyService:
+		return e.Service
+	case KeyHost:
+		return e.Host
+	}
+	tag, _ := strings.CutPrefix(key, tagsPrefix)
+	return e.Tags[tag]
+}
@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func checkoutPolicy() Policy {
+	return Policy{Rules: []Rule{
+		{Name: "errors", Match: Match{Levels: []string{"error", "fatal"}}, Action: ActionKeep},
+		{Name: "checkout-debug", Match: Match{Services: []string{"checkout"}, Environments: []string{"prod"}, Levels: []string{"debug"}}, Action: ActionDrop},
+		{Name: "health", Match: Match{Levels: []string{"info"}, MessageContains: "/healthz"}, Action: ActionSample, SampleRate: 10, Key: KeyTraceID},
+	}}
+}
+
+func TestDecideFirstMatchWins(t *testing.T) {
+	p := checkoutPolicy()
+	require.NoError(t, p.Validate())
+
+	tests := []struct {
+		entry Entry
+		want  Decision
+	}{
+		{Entry{Level: "ERROR", Service: "checkout", Environment: "prod"}, Decision{SampleRate: 1, Rule: "errors"}},
+		{Entry{Level: "debug", Service: "checkout", Environment: "prod"}, Decision{Rule: "checkout-debug"}},
+		{Entry{Level: "debug", Service: "checkout", Environment: "staging"}, Decision{SampleRate: 1}},
+		{Entry{Level: "info", Message: "GET /api/orders 200"}, Decision{SampleRate: 1}},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, p.Decide(&tt.entry), "%+v", tt.entry)
+	}
+}
+
+func TestKeyedSamplingKeepsTracesTogether(t *testing.T) {
+	p := checkoutPolicy()
+	kept := 0
+	for i := range 10_000 {
+		traceID := fmt.Sprintf("trace-%d", i)
+		first := p.Decide(&Entry{Level: "info", Message: "GET /healthz 200", TraceID: traceID, Service: "api"})
+		// Every log of the trace gets the same decision.
+		second := p.Decide(&Entry{Level: "info", Message: "GET /healthz 200", TraceID: traceID, Service: "db"})
+		assert.Equal(t, first, second)
+		if first.Keep() {
+			assert.Equal(t, uint32(10), first.SampleRate)
+			kept++
+		}
+	}
+	assert.InDelta(t, 1000, kept, 150)
+}
+
+func TestKeyedSamplingNests(t *testing.T) {
+	rule := func(rate uint32) Policy {
+		return Policy{Rules: []Rule{{Name: "s", Action: ActionSample, SampleRate: rate, Key: KeyRequestID}}}
+	}
+	for i := range 1000 {
+		e := &Entry{RequestID: fmt.Sprintf("req-%d", i)}
+		if rule(100).Decide(e).Keep() {
+			assert.True(t, rule(10).Decide(e).Keep(), "a value kept at 1/100 is kept at 1/10")
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	invalid := map[string]Rule{
+		"bad name":         {Name: "Bad Name", Action: ActionKeep},
+		"unknown action":   {Name: "r", Action: "archive"},
+		"rate on keep":     {Name: "r", Action: ActionKeep, SampleRate: 10},
+		"rate of one":      {Name: "r", Action: ActionSample, SampleRate: 1},
+		"rate too sparse":  {Name: "r", Action: ActionSample, SampleRate: MaxSampleRate + 1},
+		"unknown key":      {Name: "r", Action: ActionSample, SampleRate: 2, Key: "message"},
+		"empty tag as key": {Name: "r", Action: ActionSample, SampleRate: 2, Key: "tags."},
+	}
+	for name, rule := range invalid {
+		err := Policy{Rules: []Rule{rule}}.Validate()
+		assert.ErrorIs(t, err, ErrInvalidPolicy, name)
+	}
+
+	dup := Policy{Rules: []Rule{{Name: "r", Action: ActionKeep}, {Name: "r", Action: ActionDrop}}}
+	assert.ErrorIs(t, dup.Validate(), ErrInvalidPolicy)
+}
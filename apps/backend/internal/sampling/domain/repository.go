@@ -0,0 +1,19 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// PolicyRepository stores sampling policies in Postgres. Every call is
+// scoped to a tenant: a project of another tenant behaves like a missing
+// one (ErrProjectNotFound).
+type PolicyRepository interface {
+	// Get returns the project's policy, with no rules when none was set.
+	Get(ctx context.Context, tenantID, projectID uuid.UUID) (*Policy, error)
+	// Replace overwrites the project's policy.
+	Replace(ctx context.Context, policy *Policy) error
+	// Delete removes the project's policy, so every log is kept.
+	Delete(ctx context.Context, tenantID, projectID uuid.UUID) error
+}
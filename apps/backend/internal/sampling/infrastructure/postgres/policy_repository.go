@@ -0,0 +1,118 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/sampling/domain"
+)
+
+type policyRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPolicyRepository(db *pgxpool.Pool) domain.PolicyRepository {
+	return &policyRepository{db: db}
+}
+
+func (r *policyRepository) Get(ctx context.Context, tenantID, projectID uuid.UUID) (*domain.Policy, error) {
+	const query = `
+		SELECT sp.rules, sp.updated_at
+		FROM projects p
+		LEFT JOIN project_sampling_policies sp ON sp.project_id = p.id
+		WHERE p.id = $1 AND p.tenant_id = $2 AND p.deleted_at IS NULL
+	`
+	var (
+		rules     []byte
+		updatedAt *time.Time
+	)
+	if err := r.db.QueryRow(ctx, query, projectID, tenantID).Scan(&rules, &updatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrProjectNotFound
+		}
+		return nil, err
+	}
+
+	policy := &domain.Policy{TenantID: tenantID, ProjectID: projectID, Rules: []domain.Rule{}, UpdatedAt: updatedAt}
+	if rules != nil {
+		if err := json.Unmarshal(rules, &policy.Rules); err != nil {
+			return nil, err
+		}
+	}
+	return policy, nil
+}
+
+func (r *policyRepository) Replace(ctx context.Context, policy *domain.Policy) error {
+	rules := policy.Rules
+	if rules == nil {
+		rules = []domain.Rule{}
+	}
+	raw, err := json.Marshal(rules)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockProject(ctx, tx, policy.TenantID, policy.ProjectID); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO project_sampling_policies (project_id, tenant_id, rules, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (project_id) DO UPDATE
+		SET rules = EXCLUDED.rules, updated_at = EXCLUDED.updated_at
+	`, policy.ProjectID, policy.TenantID, raw, now); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	policy.UpdatedAt = &now
+	return nil
+}
+
+func (r *policyRepository) Delete(ctx context.Context, tenantID, projectID uuid.UUID) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockProject(ctx, tx, tenantID, projectID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM project_sampling_policies WHERE project_id = $1`, projectID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// lockProject checks the project belongs to the tenant and serialises
+// concurrent policy changes to it.
+func lockProject(ctx context.Context, tx pgx.Tx, tenantID, projectID uuid.UUID) error {
+	const query = `
+		SELECT 1 FROM projects
+		WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL
+		FOR UPDATE
+	`
+	var one int
+	if err := tx.QueryRow(ctx, query, projectID, tenantID).Scan(&one); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrProjectNotFound
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package http
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/sampling/application"
+	"github.com/indalyadav56/logify/apps/backend/internal/sampling/domain"
+	"github.com/indalyadav56/logify/apps/backend/pkg/response"
+	"github.com/indalyadav56/logify/apps/backend/pkg/validator"
+)
+
+type SamplingHandler struct {
+	service application.SamplingService
+}
+
+func NewSamplingHandler(service application.SamplingService) *SamplingHandler {
+	return &SamplingHandler{service: service}
+}
+
+// GetPolicy returns a project's sampling rules.
+// @Summary      Get sampling policy
+// @Description  Return the project's sampling and drop rules, in the order they are tried.
+// @Tags         sampling
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Project ID (UUID)"
+// @Success      200  {object}  response.APIResponse "Sampling policy retrieved successfully"
+// @Failure      400  {object}  response.APIResponse "Invalid id format"
+// @Failure      404  {object}  response.APIResponse "Project not found"
+// @Failure      500  {object}  response.APIResponse "Internal server error"
+// @Router       /v1/projects/{id}/sampling [get]
+func (h *SamplingHandler) GetPolicy(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	policy, err := h.service.GetPolicy(c.Request.Context(), id)
+	if err != nil {
+		h.writeError(c, err, "Failed to retrieve sampling policy")
+		return
+	}
+	response.OK(c, "Sampling policy retrieved successfully", policy)
+}
+
+// SetPolicy replaces a project's sampling rules.
+// @Summary      Set sampling policy
+// @Description  Replace the project's rules. At ingest, the first rule matching a log keeps, drops or samples it; unmatched logs are kept. Stored logs carry the sample rate they were kept at, so aggregations can be weighted.
+// @Tags         sampling
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                   true  "Project ID (UUID)"
+// @Param        request  body      application.PolicyInput  true  "Sampling policy"
+// @Success      200      {object}  response.APIResponse "Sampling policy updated successfully"
+// @Failure      400      {object}  response.APIResponse "Invalid request"
+// @Failure      404      {object}  response.APIResponse "Project not found"
+// @Failure      500      {object}  response.APIResponse "Internal server error"
+// @Router       /v1/projects/{id}/sampling [put]
+func (h *SamplingHandler) SetPolicy(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input application.PolicyInput
+	if !validator.ValidateRequest(c, &input) {
+		return
+	}
+
+	policy, err := h.service.SetPolicy(c.Request.Context(), id, input)
+	if err != nil {
+		h.writeError(c, err, "Failed to update sampling policy")
+		return
+	}
+	response.OK(c, "Sampling policy updated successfully", policy)
+}
+
+// DeletePolicy removes a project's sampling rules.
+// @Summary      Delete sampling policy
+// @Description  Remove the project's rules; every log is kept again.
+// @Tags         sampling
+// @Security     BearerAuth
+// @Param        id   path  string  true  "Project ID (UUID)"
+// @Success      204  "Sampling policy deleted"
+// @Failure      400  {object}  response.APIResponse "Invalid id format"
+// @Failure      404  {object}  response.APIResponse "Project not found"
+// @Failure      500  {object}  response.APIResponse "Internal server error"
+// @Router       /v1/projects/{id}/sampling [delete]
+func (h *SamplingHandler) DeletePolicy(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeletePolicy(c.Request.Context(), id); err != nil {
+		h.writeError(c, err, "Failed to delete sampling policy")
+		return
+	}
+	response.NoContent(c)
+}
+
+// writeError maps domain errors to HTTP responses with a consistent envelope.
+func (h *SamplingHandler) writeError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrProjectNotFound):
+		response.NotFound(c, "Project not found")
+	case errors.Is(err, domain.ErrTenantRequired):
+		response.Unauthorized(c, "Tenant ID is required")
+	case errors.Is(err, domain.ErrInvalidPolicy):
+		response.BadRequest(c, err.Error())
+	default:
+		response.InternalServerError(c, fallback)
+	}
+}
+
+func parseUUIDParam(c *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		response.BadRequest(c, "Invalid "+name+" format")
+		return uuid.Nil, false
+	}
+	return id, true
+}
@@ -0,0 +1,22 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+
+	roleDomain "github.com/indalyadav56/logify/apps/backend/internal/role/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
+)
+
+// RegisterRoutes mounts the sampling routes under their project. The group
+// is expected to be already authenticated (see di.RegisterAllRoutes).
+func RegisterRoutes(router *gin.RouterGroup, handler *SamplingHandler, authz *middleware.Authorizer) {
+	read := authz.RequireProjectPermission("id", roleDomain.ResourceProject, roleDomain.ActionRead)
+	write := authz.RequireProjectPermission("id", roleDomain.ResourceProject, roleDomain.ActionWrite)
+
+	g := router.Group("/v1/projects/:id/sampling")
+	{
+		g.GET("", read, handler.GetPolicy)
+		g.PUT("", write, handler.SetPolicy)
+		g.DELETE("", write, handler.DeletePolicy)
+	}
+}
@@ -0,0 +1,69 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/search/domain"
+	tenantDomain "github.com/indalyadav56/logify/apps/backend/internal/tenant/domain"
+)
+
+// queryWindow is the window the queries_per_minute quota is counted over.
+const queryWindow = time.Minute
+
+// QuotaSource supplies the tenant quota. It is satisfied by
+// tenant/application.TenantService.
+type QuotaSource interface {
+	GetQuota(ctx context.Context, tenantID uuid.UUID) (*tenantDomain.Quota, error)
+}
+
+// QueryAdmitter decides whether a tenant may run one more query. Admit
+// returns nil to run it or a *domain.QueryLimitError to reject it.
+type QueryAdmitter interface {
+	Admit(ctx context.Context, tenantID uuid.UUID) error
+}
+
+// QueryLimiter enforces the tenant's queries_per_minute quota over fixed
+// one-minute windows.
+//
+// It fails open: when Redis or the quota lookup is unavailable the query
+// runs and the failure is logged, like the ingest limiter.
+type QueryLimiter struct {
+	counter domain.QueryCounter
+	quotas  QuotaSource
+	logger  *zap.Logger
+	now     func() time.Time
+}
+
+func NewQueryLimiter(counter domain.QueryCounter, quotas QuotaSource, logger *zap.Logger) *QueryLimiter {
+	return &QueryLimiter{
+		counter: counter,
+		quotas:  quotas,
+		logger:  logger.Named("query_limiter"),
+		now:     time.Now,
+	}
+}
+
+func (l *QueryLimiter) Admit(ctx context.Context, tenantID uuid.UUID) error {
+	quota, err := l.quotas.GetQuota(ctx, tenantID)
+	if err != nil {
+		l.logger.Warn("quota lookup failed; query limit not applied",
+			zap.String("tenant_id", tenantID.String()), zap.Error(err))
+		return nil
+	}
+	now := l.now()
+	start := now.Truncate(queryWindow)
+	n, err := l.counter.Incr(ctx, tenantID.String(), start, queryWindow)
+	if err != nil {
+		l.logger.Warn("query counter unavailable; admitting query",
+			zap.String("tenant_id", tenantID.String()), zap.Error(err))
+		return nil
+	}
+	if n > int64(quota.QueriesPerMinute) {
+		return &domain.QueryLimitError{RetryAfter: start.Add(queryWindow).Sub(now)}
+	}
+	return nil
+}
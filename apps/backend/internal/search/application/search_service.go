@@ -2,46 +2,384 @@ package application
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"time"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 
+	archiveDomain "github.com/indalyadav56/logify/apps/backend/internal/archive/domain"
+	auditDomain "github.com/indalyadav56/logify/apps/backend/internal/audit/domain"
+	roleDomain "github.com/indalyadav56/logify/apps/backend/internal/role/domain"
 	"github.com/indalyadav56/logify/apps/backend/internal/search/domain"
 	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
 )
 
-const defaultSearchWindow = time.Hour
+const (
+	defaultSearchWindow = time.Hour
+	// defaultFieldsWindow is the time range fields are listed over by
+	// default; the catalog has a granularity of a day anyway.
+	defaultFieldsWindow = 24 * time.Hour
+
+	// Field discovery serves autocomplete, which tolerates slightly stale
+	// results but is called on every keystroke.
+	fieldsCacheTTL = 5 * time.Minute
+	valuesCacheTTL = 30 * time.Second
+)
+
+// ProjectAccess decides whether the caller may read a project's logs. It is
+// satisfied by role/application.PermissionResolver, which also rejects
+// projects outside the caller's tenant.
+type ProjectAccess interface {
+	HasPermission(ctx context.Context, userID, tenantID uuid.UUID, projectID *uuid.UUID, resource, action string) (bool, error)
+}
+
+// SensitiveProjects tells which projects have their searches audited. It is
+// satisfied by project/application.ProjectService.
+type SensitiveProjects interface {
+	IsSensitive(ctx context.Context, tenantID, projectID uuid.UUID) (bool, error)
+}
+
+// Rehydrations resolves a rehydration of archived logs to the table it was
+// loaded into. It is satisfied by archive/application.ArchiveService.
+type Rehydrations interface {
+	Rehydration(ctx context.Context, tenantID, projectID, id uuid.UUID) (*archiveDomain.Job, error)
+}
 
 type SearchService struct {
-	repo domain.Repository
-	log  *zap.Logger
+	repo         domain.Repository
+	cache        domain.FieldCache
+	access       ProjectAccess
+	limits       QueryAdmitter
+	projects     SensitiveProjects
+	rehydrations Rehydrations
+	audit        auditDomain.Recorder
+	log          *zap.Logger
 }
 
-func NewSearchService(repo domain.Repository, log *zap.Logger) *SearchService {
-	return &SearchService{repo: repo, log: log}
+// NewSearchService returns a SearchService. Reads of sensitive projects are
+// recorded on audit; with nil projects nothing is. With nil rehydrations
+// only live logs can be searched. With a nil cache field discovery always
+// queries the repository. With nil limits queries are not rate limited.
+func NewSearchService(repo domain.Repository, cache domain.FieldCache, access ProjectAccess, limits QueryAdmitter, projects SensitiveProjects, rehydrations Rehydrations, audit auditDomain.Recorder, log *zap.Logger) *SearchService {
+	if audit == nil {
+		audit = auditDomain.Discard
+	}
+	return &SearchService{repo: repo, cache: cache, access: access, limits: limits, projects: projects, rehydrations: rehydrations, audit: audit, log: log}
 }
 
 func (s *SearchService) Search(ctx context.Context, q domain.Query) (*domain.SearchResult, error) {
-	tenantID, ok := middleware.TenantIDFromContext(ctx)
-	if !ok {
-		return nil, domain.ErrTenantIDRequired
+	tenantID, err := s.authorizeProject(ctx, q.ProjectID)
+	if err != nil {
+		return nil, err
 	}
 	q.TenantID = tenantID
+	if err := s.resolveRehydration(ctx, &q); err != nil {
+		return nil, err
+	}
 	q.ApplyTimeRangeDefaults(defaultSearchWindow)
 
 	if err := q.Validate(); err != nil {
 		return nil, err
 	}
+	s.auditRead(ctx, q.ProjectID, "search", queryDetails(q))
 	return s.repo.Search(ctx, q)
 }
 
-func (s *SearchService) GetByID(ctx context.Context, tenantID, logID string) (*domain.LogEntry, error) {
-	return s.repo.GetByID(ctx, tenantID, logID)
+// GetByID returns a log of the caller's tenant. Logs of projects the caller
+// cannot read are reported as not found.
+func (s *SearchService) GetByID(ctx context.Context, logID string) (*domain.LogEntry, error) {
+	tenantID, ok := middleware.TenantIDFromContext(ctx)
+	if !ok {
+		return nil, domain.ErrTenantIDRequired
+	}
+	if err := s.admit(ctx); err != nil {
+		return nil, err
+	}
+	entry, err := s.repo.GetByID(ctx, tenantID, logID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.authorizeProject(ctx, entry.ProjectID); err != nil {
+		if isAccessDenied(err) {
+			return nil, domain.ErrLogNotFound
+		}
+		return nil, err
+	}
+	s.auditRead(ctx, entry.ProjectID, "get", map[string]any{"log_id": logID})
+	return entry, nil
 }
 
 func (s *SearchService) Aggregate(ctx context.Context, req domain.AggregationRequest) (*domain.AggregationResult, error) {
+	tenantID, err := s.authorizeProject(ctx, req.Query.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	req.Query.TenantID = tenantID
+	if err := s.resolveRehydration(ctx, &req.Query); err != nil {
+		return nil, err
+	}
+
 	if err := req.Query.Validate(); err != nil {
 		return nil, err
 	}
+	if _, err := req.ValueKey(); err != nil {
+		return nil, err
+	}
+	details := queryDetails(req.Query)
+	details["group_by"], details["interval"], details["weighted"] = req.GroupBy, req.Interval, req.Weighted
+	if req.Func != "" {
+		details["func"], details["field"] = req.Func, req.Field
+	}
+	s.auditRead(ctx, req.Query.ProjectID, "aggregate", details)
 	return s.repo.Aggregate(ctx, req)
 }
+
+// Fields returns the fields of a project's logs seen within the time range
+// of q, which defaults to the last day, for autocomplete. Only the project
+// and time range of q are used.
+func (s *SearchService) Fields(ctx context.Context, q domain.Query) ([]domain.Field, error) {
+	tenantID, err := s.authorizeProject(ctx, q.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	q = domain.Query{TenantID: tenantID, ProjectID: q.ProjectID, From: q.From, To: q.To}
+	q.ApplyTimeRangeDefaults(defaultFieldsWindow)
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	key := cacheKey("fields", q)
+	if s.cache != nil {
+		fields, ok, err := s.cache.GetFields(ctx, key)
+		if err != nil {
+			s.log.Warn("field cache read failed", zap.Error(err))
+		} else if ok {
+			return fields, nil
+		}
+	}
+	fields, err := s.repo.Fields(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	if s.cache != nil {
+		if err := s.cache.SetFields(ctx, key, fields, fieldsCacheTTL); err != nil {
+			s.log.Warn("field cache write failed", zap.Error(err))
+		}
+	}
+	return fields, nil
+}
+
+// FieldValues returns the most frequent values of a field among the logs a
+// search would match.
+func (s *SearchService) FieldValues(ctx context.Context, req domain.FieldValuesRequest) (*domain.FieldValues, error) {
+	tenantID, err := s.authorizeProject(ctx, req.Query.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	req.Query.TenantID = tenantID
+	if err := s.resolveRehydration(ctx, &req.Query); err != nil {
+		return nil, err
+	}
+	req.Query.ApplyTimeRangeDefaults(defaultSearchWindow)
+
+	if err := req.Query.Validate(); err != nil {
+		return nil, err
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	details := queryDetails(req.Query)
+	details["field"] = req.Field
+	s.auditRead(ctx, req.Query.ProjectID, "field_values", details)
+
+	key := cacheKey("values", req)
+	if s.cache != nil {
+		values, ok, err := s.cache.GetValues(ctx, key)
+		if err != nil {
+			s.log.Warn("field cache read failed", zap.Error(err))
+		} else if ok {
+			return values, nil
+		}
+	}
+	values, err := s.repo.FieldValues(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if s.cache != nil {
+		if err := s.cache.SetValues(ctx, key, values, valuesCacheTTL); err != nil {
+			s.log.Warn("field cache write failed", zap.Error(err))
+		}
+	}
+	return values, nil
+}
+
+// cacheKey derives the cache key of a field discovery request, which holds
+// the caller's tenant. Time ranges are keyed to the minute, so the ranges
+// relative to now a UI sends on every keystroke share an entry.
+func cacheKey(kind string, req any) string {
+	switch r := req.(type) {
+	case domain.Query:
+		r.From, r.To = r.From.Truncate(time.Minute), r.To.Truncate(time.Minute)
+		req = r
+	case domain.FieldValuesRequest:
+		r.Query.From, r.Query.To = r.Query.From.Truncate(time.Minute), r.Query.To.Truncate(time.Minute)
+		req = r
+	}
+	// Queries only hold strings, times and filter values, which marshal.
+	b, _ := json.Marshal(req)
+	sum := sha256.Sum256(b)
+	return kind + ":" + hex.EncodeToString(sum[:])
+}
+
+// authorizeProject checks that the caller may read logs of projectID, counts
+// the query against the tenant's rate limit, and returns the caller's tenant
+// id. The tenant always comes from the token, never from the request.
+func (s *SearchService) authorizeProject(ctx context.Context, projectID string) (string, error) {
+	userID, ok := middleware.UserUUIDFromContext(ctx)
+	if !ok {
+		return "", domain.ErrTenantIDRequired
+	}
+	tenantID, ok := middleware.GetTenantUUIDFromContext(ctx)
+	if !ok {
+		return "", domain.ErrTenantIDRequired
+	}
+	if projectID == "" {
+		return "", domain.ErrProjectIDRequired
+	}
+	pid, err := uuid.Parse(projectID)
+	if err != nil {
+		return "", domain.ErrProjectNotFound
+	}
+
+	allowed, err := s.access.HasPermission(ctx, userID, tenantID, &pid, roleDomain.ResourceLog, roleDomain.ActionRead)
+	if err != nil {
+		return "", err
+	}
+	if !allowed {
+		s.log.Warn("project access denied",
+			zap.String("user_id", userID.String()),
+			zap.String("tenant_id", tenantID.String()),
+			zap.String("project_id", projectID),
+		)
+		return "", domain.ErrProjectNotFound
+	}
+	if err := s.admit(ctx); err != nil {
+		return "", err
+	}
+	return tenantID.String(), nil
+}
+
+// admit counts a query of the caller's tenant, returning a
+// *domain.QueryLimitError once its queries_per_minute quota is used up.
+func (s *SearchService) admit(ctx context.Context) error {
+	if s.limits == nil {
+		return nil
+	}
+	tenantID, ok := middleware.GetTenantUUIDFromContext(ctx)
+	if !ok {
+		return domain.ErrTenantIDRequired
+	}
+	return s.limits.Admit(ctx, tenantID)
+}
+
+// resolveRehydration points q at the table of its rehydration, if it names
+// one. An unset time range defaults to the rehydrated range.
+func (s *SearchService) resolveRehydration(ctx context.Context, q *domain.Query) error {
+	if q.RehydrationID == "" {
+		return nil
+	}
+	id, err := uuid.Parse(q.RehydrationID)
+	if err != nil || s.rehydrations == nil {
+		return domain.ErrRehydrationNotFound
+	}
+	tenantID, _ := uuid.Parse(q.TenantID)
+	projectID, _ := uuid.Parse(q.ProjectID)
+	job, err := s.rehydrations.Rehydration(ctx, tenantID, projectID, id)
+	switch {
+	case errors.Is(err, archiveDomain.ErrJobNotFound):
+		return domain.ErrRehydrationNotFound
+	case errors.Is(err, archiveDomain.ErrNotReady):
+		return domain.ErrRehydrationNotReady
+	case err != nil:
+		return err
+	}
+	q.Table = job.Table
+	if q.From.IsZero() && q.To.IsZero() {
+		q.From, q.To = job.From, job.To
+	}
+	return nil
+}
+
+// auditRead records an authorized read of a sensitive project's logs. A read
+// whose sensitivity cannot be looked up is recorded too, noting the failure,
+// so an unavailable project store never leaves a sensitive read unaudited.
+func (s *SearchService) auditRead(ctx context.Context, projectID, operation string, details map[string]any) {
+	if s.projects == nil {
+		return
+	}
+	tenantID, _ := middleware.GetTenantUUIDFromContext(ctx)
+	pid, err := uuid.Parse(projectID)
+	if err != nil {
+		return
+	}
+	sensitive, err := s.projects.IsSensitive(ctx, tenantID, pid)
+	if err != nil {
+		s.log.Error("sensitive project lookup failed", zap.String("project_id", projectID), zap.Error(err))
+		details["sensitivity_unknown"] = true
+	} else if !sensitive {
+		return
+	}
+	details["operation"] = operation
+	s.audit.Record(ctx, auditDomain.Event{
+		Action:     auditDomain.ActionLogSearch,
+		ProjectID:  pid,
+		TargetType: "project",
+		TargetID:   projectID,
+		Metadata:   details,
+	})
+}
+
+// queryDetails is what an audit record keeps of a search: its filters.
+func queryDetails(q domain.Query) map[string]any {
+	details := map[string]any{"from": q.From, "to": q.To}
+	if len(q.Services) > 0 {
+		details["services"] = q.Services
+	}
+	if len(q.Severities) > 0 {
+		details["severities"] = q.Severities
+	}
+	if len(q.Hosts) > 0 {
+		details["hosts"] = q.Hosts
+	}
+	if len(q.Attributes) > 0 {
+		details["attributes"] = q.Attributes
+	}
+	if len(q.Filters) > 0 {
+		filters := make([]map[string]any, len(q.Filters))
+		for i, f := range q.Filters {
+			filters[i] = map[string]any{"field": f.Field, "op": f.Op, "value": f.Value}
+		}
+		details["filters"] = filters
+	}
+	if q.TraceID != "" {
+		details["trace_id"] = q.TraceID
+	}
+	if q.RequestID != "" {
+		details["request_id"] = q.RequestID
+	}
+	if q.BodyContains != "" {
+		details["body_contains"] = q.BodyContains
+	}
+	if q.RehydrationID != "" {
+		details["rehydration_id"] = q.RehydrationID
+	}
+	return details
+}
+
+func isAccessDenied(err error) bool {
+	return errors.Is(err, domain.ErrProjectNotFound) || errors.Is(err, domain.ErrProjectIDRequired)
+}
@@ -1,11 +1,51 @@
 package domain
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AggFunc is what an aggregation computes per bucket besides the count.
+type AggFunc string
+
+const (
+	AggCount AggFunc = "count"
+	AggSum   AggFunc = "sum"
+	AggAvg   AggFunc = "avg"
+	AggMin   AggFunc = "min"
+	AggMax   AggFunc = "max"
+)
 
 type AggregationRequest struct {
 	Query    Query
 	GroupBy  string
 	Interval string
+	// Weighted counts each log as the number it stands for after sampling,
+	// estimating the volume sent rather than the volume stored. Sums and
+	// averages are weighted likewise.
+	Weighted bool
+	// Func, unless empty or AggCount, is applied to the numeric attribute
+	// Field of the logs in each bucket that have one.
+	Func  AggFunc
+	Field string
+}
+
+// ValueKey returns the attribute key Func applies to, or "" when the request
+// only counts. It fails with ErrInvalidAggregation.
+func (r AggregationRequest) ValueKey() (string, error) {
+	switch r.Func {
+	case "", AggCount:
+		return "", nil
+	case AggSum, AggAvg, AggMin, AggMax:
+	default:
+		return "", fmt.Errorf("%w: unknown function %q", ErrInvalidAggregation, r.Func)
+	}
+	key, ok := strings.CutPrefix(r.Field, AttributesPrefix)
+	if !ok || !mapKey.MatchString(key) {
+		return "", fmt.Errorf("%w: %s needs a field attributes.<key>", ErrInvalidAggregation, r.Func)
+	}
+	return key, nil
 }
 
 type AggregationResult struct {
@@ -16,4 +56,7 @@ type AggBucket struct {
 	Timestamp *time.Time
 	Key       string
 	Count     uint64
+	// Value is the result of the request's Func; nil when it only counts or
+	// no log of the bucket has the field.
+	Value *float64
 }
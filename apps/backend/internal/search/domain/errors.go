@@ -1,6 +1,9 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 var (
 	ErrTenantIDRequired  = errors.New("tenant_id is required")
@@ -9,4 +12,34 @@ var (
 	ErrInvalidTimeRange  = errors.New("from must be before to")
 	ErrLimitTooLarge     = errors.New("limit must be <= 1000")
 	ErrLogNotFound       = errors.New("log not found")
+	// ErrProjectNotFound covers both unknown projects and projects the caller
+	// may not read, so other tenants' project ids are not disclosed.
+	ErrProjectNotFound = errors.New("project not found")
+	// ErrRehydrationNotFound also covers rehydrations of other projects.
+	ErrRehydrationNotFound = errors.New("rehydration not found")
+	ErrRehydrationNotReady = errors.New("rehydration is not ready")
+	// ErrInvalidFilter is wrapped by every error of a field filter.
+	ErrInvalidFilter = errors.New("invalid filter")
+	// ErrInvalidAggregation is an aggregate function without a numeric
+	// attribute to apply to, or an unknown one.
+	ErrInvalidAggregation = errors.New("invalid aggregation")
+	// ErrInvalidField is a field values are asked of that filters cannot
+	// name, or a limit out of bounds.
+	ErrInvalidField = errors.New("invalid field")
+	// ErrRateLimited is matched by every QueryLimitError.
+	ErrRateLimited = errors.New("query rate limit exceeded")
 )
+
+// QueryLimitError is returned for a query over the tenant's
+// queries_per_minute quota. It matches ErrRateLimited with errors.Is.
+type QueryLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *QueryLimitError) Error() string {
+	return ErrRateLimited.Error()
+}
+
+func (e *QueryLimitError) Unwrap() error {
+	return ErrRateLimited
+}
@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// FieldType is the type of an attribute's values: attributes typed as
+// numbers or booleans by the log-processor, or strings. Tags are strings.
+type FieldType string
+
+const (
+	FieldString FieldType = "string"
+	FieldNumber FieldType = "number"
+	FieldBool   FieldType = "bool"
+)
+
+// CoreFields are the columns listed among a project's fields besides its
+// attribute and tag keys: those the rollups keep, so listing them is cheap.
+var CoreFields = []string{"service", "level", "environment", "host"}
+
+// Field is a core column, or an attribute or tag key from the field catalog,
+// seen in a project's logs. A key whose values were of several types is a
+// Field per type.
+type Field struct {
+	// Name is a column, attributes.<key> or tags.<key>, as filters name it.
+	Name      string
+	Type      FieldType
+	FirstSeen time.Time
+	LastSeen  time.Time
+	// Logs is the number of stored logs that had the field.
+	Logs uint64
+	// Cardinality estimates its distinct values.
+	Cardinality uint64
+}
+
+// Bounds of FieldValuesRequest.Limit.
+const (
+	DefaultFieldValues = 10
+	MaxFieldValues     = 100
+)
+
+// FieldValuesRequest asks for the most frequent values of Field among the
+// logs Query matches.
+type FieldValuesRequest struct {
+	Query Query
+	// Field is any field a filter may name.
+	Field string
+	Limit int
+}
+
+// Validate checks the field and limit, defaulting the latter. Errors wrap
+// ErrInvalidField.
+func (r *FieldValuesRequest) Validate() error {
+	if err := (FieldFilter{Field: r.Field, Op: OpExists}).validate(); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidField, r.Field, err)
+	}
+	if r.Limit == 0 {
+		r.Limit = DefaultFieldValues
+	}
+	if r.Limit < 0 || r.Limit > MaxFieldValues {
+		return fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidField, MaxFieldValues)
+	}
+	return nil
+}
+
+// FieldValues are the most frequent values of a field among the logs of a
+// query that have it, most frequent first. The ranking and Cardinality are
+// estimates; Logs is exact.
+type FieldValues struct {
+	Field       string
+	Values      []string
+	Cardinality uint64
+	Logs        uint64
+}
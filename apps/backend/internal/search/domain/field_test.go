@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldValuesRequestValidate(t *testing.T) {
+	req := FieldValuesRequest{Field: "attributes.region"}
+	require.NoError(t, req.Validate())
+	assert.Equal(t, DefaultFieldValues, req.Limit)
+
+	invalid := map[string]FieldValuesRequest{
+		"unknown field":  {Field: "message"},
+		"empty tag key":  {Field: "tags."},
+		"negative limit": {Field: "service", Limit: -1},
+		"limit too high": {Field: "service", Limit: MaxFieldValues + 1},
+	}
+	for name, req := range invalid {
+		assert.ErrorIs(t, req.Validate(), ErrInvalidField, name)
+	}
+}
@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// FilterOp compares a field with a filter's value.
+type FilterOp string
+
+const (
+	OpEq  FilterOp = "eq"
+	OpNeq FilterOp = "neq"
+	OpGt  FilterOp = "gt"
+	OpGte FilterOp = "gte"
+	OpLt  FilterOp = "lt"
+	OpLte FilterOp = "lte"
+	// OpExists matches logs that have the field, whatever its value.
+	OpExists FilterOp = "exists"
+)
+
+// MaxFilters bounds the filters of one query.
+const MaxFilters = 20
+
+// Prefixes of the fields of the attributes and tags maps.
+const (
+	AttributesPrefix = "attributes."
+	TagsPrefix       = "tags."
+)
+
+// filterColumns are the columns a filter may name besides attributes and
+// tags, by field name.
+var filterColumns = map[string]string{
+	"level":       "level",
+	"severity":    "level",
+	"service":     "service",
+	"namespace":   "namespace",
+	"environment": "environment",
+	"host":        "host",
+	"source":      "source",
+	"trace_id":    "trace_id",
+	"span_id":     "span_id",
+	"request_id":  "request_id",
+	"user_id":     "user_id",
+}
+
+var mapKey = regexp.MustCompile(`^[A-Za-z0-9_.\-@/]{1,128}$`)
+
+// FieldFilter compares a field with a value. The value's type picks what it
+// is compared with: a number compares the attribute's numeric value, a bool
+// its boolean one and a string its text. Only attributes have numeric and
+// boolean values, and only numbers can be ordered.
+type FieldFilter struct {
+	// Field is a column such as service, or attributes.<key> or tags.<key>.
+	Field string
+	Op    FilterOp
+	// Value is a float64, bool or string; it is ignored by OpExists.
+	Value any
+}
+
+// Column returns the column the filter reads and, for a map column, the
+// key within it. It assumes the filter is valid.
+func (f FieldFilter) Column() (column, key string) {
+	if key, ok := strings.CutPrefix(f.Field, AttributesPrefix); ok {
+		switch f.Value.(type) {
+		case float64:
+			return "attributes_number", key
+		case bool:
+			return "attributes_bool", key
+		}
+		return "attributes", key
+	}
+	if key, ok := strings.CutPrefix(f.Field, TagsPrefix); ok {
+		return "tags", key
+	}
+	return filterColumns[f.Field], ""
+}
+
+// Validate checks the field, operator and value of the filter. Every error
+// wraps ErrInvalidFilter.
+func (f FieldFilter) Validate() error {
+	if err := f.validate(); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidFilter, f.Field, err)
+	}
+	return nil
+}
+
+func (f FieldFilter) validate() error {
+	attribute := false
+	if key, ok := strings.CutPrefix(f.Field, AttributesPrefix); ok {
+		if !mapKey.MatchString(key) {
+			return fmt.Errorf("invalid attribute key")
+		}
+		attribute = true
+	} else if key, ok := strings.CutPrefix(f.Field, TagsPrefix); ok {
+		if !mapKey.MatchString(key) {
+			return fmt.Errorf("invalid tag key")
+		}
+	} else if _, ok := filterColumns[f.Field]; !ok {
+		return fmt.Errorf("unknown field")
+	}
+
+	switch f.Op {
+	case OpExists:
+		return nil
+	case OpEq, OpNeq, OpGt, OpGte, OpLt, OpLte:
+	default:
+		return fmt.Errorf("unknown op %q", f.Op)
+	}
+	switch f.Value.(type) {
+	case float64, bool:
+		if !attribute {
+			return fmt.Errorf("only attributes have numeric or boolean values")
+		}
+	case string:
+	default:
+		return fmt.Errorf("value must be a number, boolean or string")
+	}
+	if _, number := f.Value.(float64); !number && f.Op != OpEq && f.Op != OpNeq {
+		return fmt.Errorf("%s needs a number", f.Op)
+	}
+	return nil
+}
+
+func (q Query) validateFilters() error {
+	if len(q.Filters) > MaxFilters {
+		return fmt.Errorf("%w: more than %d filters", ErrInvalidFilter, MaxFilters)
+	}
+	for _, f := range q.Filters {
+		if err := f.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFieldFilterColumn(t *testing.T) {
+	cases := []struct {
+		filter      FieldFilter
+		column, key string
+	}{
+		{FieldFilter{Field: "severity", Op: OpEq, Value: "error"}, "level", ""},
+		{FieldFilter{Field: "attributes.duration_ms", Op: OpGt, Value: 250.0}, "attributes_number", "duration_ms"},
+		{FieldFilter{Field: "attributes.retried", Op: OpEq, Value: true}, "attributes_bool", "retried"},
+		{FieldFilter{Field: "attributes.region", Op: OpEq, Value: "eu"}, "attributes", "region"},
+		{FieldFilter{Field: "tags.team", Op: OpExists}, "tags", "team"},
+	}
+	for _, c := range cases {
+		assert.NoError(t, c.filter.Validate(), c.filter.Field)
+		column, key := c.filter.Column()
+		assert.Equal(t, c.column, column, c.filter.Field)
+		assert.Equal(t, c.key, key, c.filter.Field)
+	}
+}
+
+func TestFieldFilterValidate(t *testing.T) {
+	invalid := map[string]FieldFilter{
+		"unknown field":      {Field: "message", Op: OpEq, Value: "x"},
+		"empty key":          {Field: "attributes.", Op: OpExists},
+		"unknown op":         {Field: "service", Op: "like", Value: "api"},
+		"number on a column": {Field: "service", Op: OpEq, Value: 1.0},
+		"bool on a tag":      {Field: "tags.canary", Op: OpEq, Value: true},
+		"ordered string":     {Field: "attributes.region", Op: OpGt, Value: "eu"},
+		"ordered bool":       {Field: "attributes.retried", Op: OpLt, Value: false},
+		"unsupported value":  {Field: "attributes.ids", Op: OpEq, Value: []any{1.0}},
+		"missing value":      {Field: "attributes.region", Op: OpEq},
+	}
+	for name, f := range invalid {
+		assert.ErrorIs(t, f.Validate(), ErrInvalidFilter, name)
+	}
+
+	q := Query{Filters: make([]FieldFilter, MaxFilters+1)}
+	for i := range q.Filters {
+		q.Filters[i] = FieldFilter{Field: "service", Op: OpExists}
+	}
+	assert.ErrorIs(t, q.validateFilters(), ErrInvalidFilter)
+}
@@ -17,6 +17,12 @@ type Query struct {
 	Limit        int
 	Cursor       string
 	SortDesc     bool
+	// RehydrationID queries the logs of a rehydration of archived logs
+	// instead of the live table; Table is what the service resolved it to.
+	RehydrationID string
+	Table         string
+	// Filters compare fields, including typed attributes, with values.
+	Filters []FieldFilter
 }
 
 func (q *Query) ApplyTimeRangeDefaults(window time.Duration) {
@@ -47,5 +53,5 @@ func (q Query) Validate() error {
 	if q.Limit > 1000 {
 		return ErrLimitTooLarge
 	}
-	return nil
+	return q.validateFilters()
 }
@@ -1,9 +1,34 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type Repository interface {
 	Search(ctx context.Context, q Query) (*SearchResult, error)
 	GetByID(ctx context.Context, tenantID, logID string) (*LogEntry, error)
 	Aggregate(ctx context.Context, agg AggregationRequest) (*AggregationResult, error)
+	// Fields returns the core fields, then the attribute and tag keys, of
+	// the project of q seen within its time range. Other conditions of q
+	// are ignored; the catalog has a granularity of a day.
+	Fields(ctx context.Context, q Query) ([]Field, error)
+	FieldValues(ctx context.Context, req FieldValuesRequest) (*FieldValues, error)
+}
+
+// FieldCache keeps field discovery results for a short while, by a key the
+// service derives from the request. A miss reports false, not an error.
+type FieldCache interface {
+	GetFields(ctx context.Context, key string) ([]Field, bool, error)
+	SetFields(ctx context.Context, key string, fields []Field, ttl time.Duration) error
+	GetValues(ctx context.Context, key string) (*FieldValues, bool, error)
+	SetValues(ctx context.Context, key string, values *FieldValues, ttl time.Duration) error
+}
+
+// QueryCounter counts the queries of a tenant, shared by every server
+// instance.
+type QueryCounter interface {
+	// Incr counts a query of the tenant in the window starting at start and
+	// returns how many the window holds with it.
+	Incr(ctx context.Context, tenantID string, start time.Time, window time.Duration) (int64, error)
 }
@@ -23,6 +23,8 @@ type LogEntry struct {
 	Tags         map[string]string
 	Attributes   map[string]string
 	IngestionTime time.Time
+	// SampleRate is the number of logs this one stands for after sampling.
+	SampleRate   uint32
 }
 
 // SearchResult is the paginated result of a search query.
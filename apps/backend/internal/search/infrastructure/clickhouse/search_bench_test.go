@@ -0,0 +1,306 @@
+package clickhouse
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	mathrand "math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	ch "github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/google/uuid"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/search/domain"
+	pkgClickhouse "github.com/indalyadav56/logify/apps/backend/pkg/clickhouse"
+)
+
+// The search benchmarks run against a real ClickHouse and are skipped
+// unless LOGIFY_BENCH_CLICKHOUSE_DSN is set, e.g.
+//
+//	LOGIFY_BENCH_CLICKHOUSE_DSN=clickhouse://default:@localhost:9000 \
+//	  go test -run '^$' -bench BenchmarkSearch ./internal/search/infrastructure/clickhouse/
+//
+// They generate LOGIFY_BENCH_ROWS rows (default 2,000,000) into two tables of
+// the logify_bench database, built from the migrations: logs_plain stops
+// before the search indexes, logs_indexed has them. "before" runs the
+// predicates search used before the indexes against logs_plain, "after"
+// runs buildSearchWhere against logs_indexed. rows_read/op is what
+// ClickHouse scanned.
+
+const (
+	benchDatabase   = "logify_bench"
+	benchPlain      = benchDatabase + ".logs_plain"
+	benchIndexed    = benchDatabase + ".logs_indexed"
+	benchTenant     = "bench-tenant-0"
+	benchIndexesSQL = "20260710090000_add_logs_search_indexes.sql"
+	benchRareNeedle = "checksum mismatch on segment"
+)
+
+var (
+	benchOnce  sync.Once
+	benchConn  ch.Conn
+	benchTrace string
+	benchErr   error
+	benchStart = time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	benchEnd   = benchStart.Add(7 * 24 * time.Hour)
+)
+
+func BenchmarkSearch(b *testing.B) {
+	conn := benchSetup(b)
+
+	cases := []struct {
+		name   string
+		query  domain.Query
+		legacy string
+		args   []any
+	}{
+		{
+			name:   "message_words",
+			query:  domain.Query{BodyContains: "failed to connect to"},
+			legacy: "positionCaseInsensitive(message, ?) > 0",
+			args:   []any{"failed to connect to"},
+		},
+		{
+			name:   "message_rare",
+			query:  domain.Query{BodyContains: benchRareNeedle},
+			legacy: "positionCaseInsensitive(message, ?) > 0",
+			args:   []any{benchRareNeedle},
+		},
+		{
+			name:   "trace_id",
+			query:  domain.Query{TraceID: benchTrace},
+			legacy: "trace_id = ?",
+			args:   []any{benchTrace},
+		},
+		{
+			name:   "attribute",
+			query:  domain.Query{Attributes: map[string]string{"customer": "cust-4242"}},
+			legacy: "attributes[?] = ?",
+			args:   []any{"customer", "cust-4242"},
+		},
+	}
+
+	for _, tc := range cases {
+		q := tc.query
+		q.TenantID, q.From, q.To = benchTenant, benchStart, benchEnd
+		where, args, err := buildSearchWhere(q)
+		if err != nil {
+			b.Fatal(err)
+		}
+		before := "WHERE tenant_id = ? AND timestamp >= ? AND timestamp <= ? AND " + tc.legacy
+		beforeArgs := append([]any{benchTenant, benchStart, benchEnd}, tc.args...)
+
+		b.Run(tc.name+"/before", func(b *testing.B) { benchQuery(b, conn, benchPlain, before, beforeArgs) })
+		b.Run(tc.name+"/after", func(b *testing.B) { benchQuery(b, conn, benchIndexed, where, args) })
+	}
+}
+
+func benchQuery(b *testing.B, conn ch.Conn, table, where string, args []any) {
+	query := fmt.Sprintf("SELECT %s FROM %s %s ORDER BY timestamp DESC LIMIT 100", selectCols, table, where)
+
+	var read, matched uint64
+	var mu sync.Mutex
+	ctx := ch.Context(context.Background(), ch.WithProgress(func(p *ch.Progress) {
+		mu.Lock()
+		read += p.Rows
+		mu.Unlock()
+	}))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rows, err := conn.Query(ctx, query, args...)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for rows.Next() {
+			var row logRow
+			if err := rows.ScanStruct(&row); err != nil {
+				b.Fatal(err)
+			}
+			matched++
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+
+	b.ReportMetric(float64(read)/float64(b.N), "rows_read/op")
+	b.ReportMetric(float64(matched)/float64(b.N), "rows/op")
+}
+
+func benchSetup(b *testing.B) ch.Conn {
+	dsn := os.Getenv("LOGIFY_BENCH_CLICKHOUSE_DSN")
+	if dsn == "" {
+		b.Skip("LOGIFY_BENCH_CLICKHOUSE_DSN not set")
+	}
+	benchOnce.Do(func() {
+		benchConn, benchErr = pkgClickhouse.NewClickHouseDB(dsn)
+		if benchErr != nil {
+			return
+		}
+		rows := 2_000_000
+		if v := os.Getenv("LOGIFY_BENCH_ROWS"); v != "" {
+			if rows, benchErr = strconv.Atoi(v); benchErr != nil {
+				return
+			}
+		}
+		benchErr = benchGenerate(context.Background(), benchConn, rows)
+	})
+	if benchErr != nil {
+		b.Fatal(benchErr)
+	}
+	return benchConn
+}
+
+// benchGenerate creates the two tables from the migrations and fills them
+// with the same rows.
+func benchGenerate(ctx context.Context, conn ch.Conn, n int) error {
+	if err := conn.Exec(ctx, "CREATE DATABASE IF NOT EXISTS "+benchDatabase); err != nil {
+		return err
+	}
+	for _, table := range []string{benchPlain, benchIndexed} {
+		if err := conn.Exec(ctx, "DROP TABLE IF EXISTS "+table); err != nil {
+			return err
+		}
+		stmts, err := benchMigrations(table, table == benchIndexed)
+		if err != nil {
+			return err
+		}
+		for _, stmt := range stmts {
+			if err := conn.Exec(ctx, stmt); err != nil {
+				return fmt.Errorf("%s: %w", stmt, err)
+			}
+		}
+	}
+
+	gen := newBenchGenerator()
+	benchTrace = gen.trace
+	const batchSize = 100_000
+	for done := 0; done < n; done += batchSize {
+		batch, err := conn.PrepareBatch(ctx, "INSERT INTO "+benchPlain+" ("+selectCols+")")
+		if err != nil {
+			return err
+		}
+		for i := done; i < n && i < done+batchSize; i++ {
+			row := gen.row(i, n)
+			if err := batch.AppendStruct(&row); err != nil {
+				return err
+			}
+		}
+		if err := batch.Send(); err != nil {
+			return err
+		}
+	}
+	return conn.Exec(ctx, "INSERT INTO "+benchIndexed+" SELECT * FROM "+benchPlain)
+}
+
+// benchMigrations returns the Up statements of the ClickHouse migrations,
+// rewritten for table. The search indexes are included only when indexed.
+func benchMigrations(table string, indexed bool) ([]string, error) {
+	dir := filepath.Join("..", "..", "..", "..", "migrations", "clickhouse")
+	files, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	var stmts []string
+	for _, file := range files {
+		if filepath.Base(file) == benchIndexesSQL && !indexed {
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		up, _, _ := strings.Cut(string(data), "-- +goose Down")
+		for _, block := range strings.Split(up, "-- +goose StatementBegin")[1:] {
+			stmt, _, _ := strings.Cut(block, "-- +goose StatementEnd")
+			stmt = strings.TrimSuffix(strings.TrimSpace(stmt), ";")
+			stmts = append(stmts, strings.ReplaceAll(stmt, "logify.logs", table))
+		}
+	}
+	return stmts, nil
+}
+
+type benchGenerator struct {
+	rnd      *mathrand.Rand
+	trace    string
+	services []string
+	levels   []string
+	words    []string
+}
+
+func newBenchGenerator() *benchGenerator {
+	return &benchGenerator{
+		rnd:      mathrand.New(mathrand.NewSource(1)),
+		trace:    randomHex(16),
+		services: []string{"api", "billing", "checkout", "search", "worker"},
+		levels:   []string{"debug", "info", "info", "info", "warn", "error"},
+		words: []string{
+			"request", "completed", "started", "user", "order", "payment", "cache",
+			"miss", "hit", "retrying", "upstream", "timeout", "queue", "job", "db",
+			"query", "slow", "session", "token", "refreshed", "connection", "pool",
+		},
+	}
+}
+
+// row returns the i-th of n rows: four tenants over a week, with a few
+// hundred rows of one trace and a handful of rare messages spread evenly.
+func (g *benchGenerator) row(i, n int) logRow {
+	r := g.rnd
+	ts := benchStart.Add(time.Duration(int64(i) * int64(benchEnd.Sub(benchStart)) / int64(n)))
+
+	words := make([]string, 6+r.Intn(8))
+	for j := range words {
+		words[j] = g.words[r.Intn(len(g.words))]
+	}
+	message := strings.Join(words, " ")
+	switch {
+	case i%100_003 == 0:
+		message = benchRareNeedle + " " + strconv.Itoa(i)
+	case r.Intn(50) == 0:
+		message = "failed to connect to " + g.words[r.Intn(len(g.words))] + " after 3 attempts"
+	}
+
+	trace := randomHex(16)
+	if i%(n/400+1) == 0 {
+		trace = g.trace
+	}
+
+	return logRow{
+		ID:            uuid.New(),
+		TenantID:      fmt.Sprintf("bench-tenant-%d", i%4),
+		ProjectID:     "bench-project",
+		Timestamp:     ts,
+		Level:         g.levels[r.Intn(len(g.levels))],
+		Service:       g.services[r.Intn(len(g.services))],
+		Environment:   "production",
+		Host:          fmt.Sprintf("host-%02d", r.Intn(32)),
+		Source:        "bench",
+		TraceID:       trace,
+		SpanID:        randomHex(8),
+		RequestID:     uuid.NewString(),
+		UserID:        fmt.Sprintf("user-%d", r.Intn(100_000)),
+		Message:       message,
+		Tags:          map[string]string{},
+		Attributes:    map[string]string{"region": []string{"eu", "us", "ap"}[r.Intn(3)], "customer": fmt.Sprintf("cust-%d", r.Intn(10_000))},
+		IngestionTime: ts,
+	}
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -3,6 +3,8 @@ package clickhouse
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -13,7 +15,18 @@ import (
 	"github.com/indalyadav56/logify/apps/backend/internal/search/domain"
 )
 
-const logsTable = "logify.logs"
+const (
+	logsTable = "logify.logs"
+	// rollupTable holds per-minute counts of logsTable, kept by a
+	// materialized view.
+	rollupTable = "logify.logs_rollup_1m"
+	// fieldsTable is the field catalog of logsTable, kept by materialized
+	// views.
+	fieldsTable = "logify.log_fields"
+
+	// maxFields bounds the fields listed for one project.
+	maxFields = 1000
+)
 
 type logRow struct {
 	ID            uuid.UUID         `ch:"id"`
@@ -34,6 +47,7 @@ type logRow struct {
 	Tags          map[string]string `ch:"tags"`
 	Attributes    map[string]string `ch:"attributes"`
 	IngestionTime time.Time         `ch:"ingestion_time"`
+	SampleRate    uint32            `ch:"sample_rate"`
 }
 
 func fromRow(r logRow) domain.LogEntry {
@@ -56,6 +70,7 @@ func fromRow(r logRow) domain.LogEntry {
 		Tags:          r.Tags,
 		Attributes:    r.Attributes,
 		IngestionTime: r.IngestionTime,
+		SampleRate:    r.SampleRate,
 	}
 }
 
@@ -70,10 +85,17 @@ func NewSearchRepository(conn ch.Conn, log *zap.Logger) *SearchRepository {
 
 const selectCols = `id, tenant_id, project_id, timestamp, level, service, namespace,
 	environment, host, source, trace_id, span_id, request_id, user_id,
-	message, tags, attributes, ingestion_time`
+	message, tags, attributes, ingestion_time, sample_rate`
 
 func (r *SearchRepository) Search(ctx context.Context, q domain.Query) (*domain.SearchResult, error) {
-	where, args := buildSearchWhere(q)
+	where, args, err := buildSearchWhere(q)
+	if err != nil {
+		return nil, err
+	}
+	table, err := tableOf(q)
+	if err != nil {
+		return nil, err
+	}
 
 	limit := q.Limit
 	if limit <= 0 {
@@ -90,7 +112,7 @@ func (r *SearchRepository) Search(ctx context.Context, q domain.Query) (*domain.
 
 	query := fmt.Sprintf(
 		"SELECT %s FROM %s %s ORDER BY timestamp %s LIMIT %d",
-		selectCols, logsTable, where, order, limit,
+		selectCols, table, where, order, limit,
 	)
 
 	start := time.Now()
@@ -120,6 +142,9 @@ func (r *SearchRepository) Search(ctx context.Context, q domain.Query) (*domain.
 }
 
 func (r *SearchRepository) GetByID(ctx context.Context, tenantID, logID string) (*domain.LogEntry, error) {
+	if tenantID == "" {
+		return nil, domain.ErrTenantIDRequired
+	}
 	query := fmt.Sprintf(
 		"SELECT %s FROM %s WHERE tenant_id = ? AND id = ? LIMIT 1",
 		selectCols, logsTable,
@@ -145,21 +170,21 @@ func (r *SearchRepository) GetByID(ctx context.Context, tenantID, logID string)
 }
 
 func (r *SearchRepository) Aggregate(ctx context.Context, req domain.AggregationRequest) (*domain.AggregationResult, error) {
-	where, args := buildSearchWhere(req.Query)
-
-	var query string
-	if req.Interval != "" {
-		fn := timeIntervalFn(req.Interval)
-		query = fmt.Sprintf(
-			"SELECT %s AS bucket, count() AS cnt FROM %s %s GROUP BY bucket ORDER BY bucket ASC",
-			fn, logsTable, where,
-		)
+	valueKey, err := req.ValueKey()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		query string
+		args  []any
+	)
+	if from, to, ok := rollupRange(req); ok {
+		query, args, err = buildRollupAggregate(req, from, to)
 	} else {
-		col := sanitizeGroupBy(req.GroupBy)
-		query = fmt.Sprintf(
-			"SELECT %s AS key, count() AS cnt FROM %s %s GROUP BY key ORDER BY cnt DESC",
-			col, logsTable, where,
-		)
+		query, args, err = buildRawAggregate(req)
+	}
+	if err != nil {
+		return nil, err
 	}
 
 	rows, err := r.conn.Query(ctx, query, args...)
@@ -173,21 +198,23 @@ func (r *SearchRepository) Aggregate(ctx context.Context, req domain.Aggregation
 		var b domain.AggBucket
 		if req.Interval != "" {
 			var t time.Time
-			var cnt uint64
-			if err := rows.Scan(&t, &cnt); err != nil {
+			dest := []any{&t, &b.Count}
+			if valueKey != "" {
+				dest = append(dest, &b.Value)
+			}
+			if err := rows.Scan(dest...); err != nil {
 				return nil, fmt.Errorf("clickhouse scan histogram: %w", err)
 			}
 			b.Timestamp = &t
 			b.Key = t.Format(time.RFC3339)
-			b.Count = cnt
 		} else {
-			var key string
-			var cnt uint64
-			if err := rows.Scan(&key, &cnt); err != nil {
+			dest := []any{&b.Key, &b.Count}
+			if valueKey != "" {
+				dest = append(dest, &b.Value)
+			}
+			if err := rows.Scan(dest...); err != nil {
 				return nil, fmt.Errorf("clickhouse scan group: %w", err)
 			}
-			b.Key = key
-			b.Count = cnt
 		}
 		buckets = append(buckets, b)
 	}
@@ -198,15 +225,179 @@ func (r *SearchRepository) Aggregate(ctx context.Context, req domain.Aggregation
 	return &domain.AggregationResult{Buckets: buckets}, nil
 }
 
-// buildSearchWhere constructs a parameterised WHERE clause from a domain Query.
-func buildSearchWhere(q domain.Query) (string, []any) {
-	var conds []string
-	var args []any
+func buildRawAggregate(req domain.AggregationRequest) (string, []any, error) {
+	where, whereArgs, err := buildSearchWhere(req.Query)
+	if err != nil {
+		return "", nil, err
+	}
+	table, err := tableOf(req.Query)
+	if err != nil {
+		return "", nil, err
+	}
+	valueKey, err := req.ValueKey()
+	if err != nil {
+		return "", nil, err
+	}
+
+	// The value column precedes WHERE, and so do its arguments.
+	var (
+		value string
+		args  []any
+	)
+	if valueKey != "" {
+		value = ", " + valueAggregate(req) + " AS val"
+		args = append(args, valueKey, valueKey)
+	}
+	args = append(args, whereArgs...)
+
+	if req.Interval != "" {
+		return fmt.Sprintf(
+			"SELECT %s AS bucket, %s AS cnt%s FROM %s %s GROUP BY bucket ORDER BY bucket ASC",
+			timeIntervalFn(req.Interval, "timestamp"), rawCount(req), value, table, where,
+		), args, nil
+	}
+	return fmt.Sprintf(
+		"SELECT %s AS key, %s AS cnt%s FROM %s %s GROUP BY key ORDER BY cnt DESC",
+		sanitizeGroupBy(req.GroupBy), rawCount(req), value, table, where,
+	), args, nil
+}
+
+// valueAggregate applies req.Func to a numeric attribute, over the logs that
+// have it, NULL when none does. It takes the attribute key twice.
+func valueAggregate(req domain.AggregationRequest) string {
+	const (
+		value = "attributes_number[?]"
+		has   = "mapContains(attributes_number, ?)"
+	)
+	switch {
+	case req.Func == domain.AggSum && req.Weighted:
+		return "sumOrNullIf(" + value + " * sample_rate, " + has + ")"
+	case req.Func == domain.AggSum:
+		return "sumOrNullIf(" + value + ", " + has + ")"
+	case req.Func == domain.AggAvg && req.Weighted:
+		return "avgWeightedOrNullIf(" + value + ", sample_rate, " + has + ")"
+	case req.Func == domain.AggAvg:
+		return "avgOrNullIf(" + value + ", " + has + ")"
+	case req.Func == domain.AggMin:
+		return "minOrNullIf(" + value + ", " + has + ")"
+	default:
+		return "maxOrNullIf(" + value + ", " + has + ")"
+	}
+}
+
+// rawCount counts the raw logs of an aggregation: rows stored, or, weighted,
+// the logs they stand for. toUInt64 keeps both scanning into a uint64.
+func rawCount(req domain.AggregationRequest) string {
+	if req.Weighted {
+		return "toUInt64(sum(sample_rate))"
+	}
+	return "count()"
+}
+
+// rollupCount is rawCount over the rollups.
+func rollupCount(req domain.AggregationRequest) string {
+	if req.Weighted {
+		return "sum(weighted_logs)"
+	}
+	return "sum(logs)"
+}
+
+// rollupDimensions are the columns the rollups keep besides tenant and
+// project.
+var rollupDimensions = map[string]bool{"service": true, "level": true, "environment": true, "host": true}
+
+// rollupRange reports whether req can be answered from the rollups and, if
+// so, the whole minutes [from, to) of its time range they serve. It must read
+// the live logs, filter only on rollup dimensions and group by one of them
+// or by time, and only count; anything else reads raw logs.
+func rollupRange(req domain.AggregationRequest) (from, to time.Time, ok bool) {
+	q := req.Query
+	if q.Table != "" || q.BodyContains != "" || q.TraceID != "" || q.RequestID != "" || len(q.Attributes) > 0 ||
+		len(q.Filters) > 0 {
+		return from, to, false
+	}
+	if req.Func != "" && req.Func != domain.AggCount {
+		return from, to, false
+	}
+	if req.Interval == "" && !rollupDimensions[sanitizeGroupBy(req.GroupBy)] {
+		return from, to, false
+	}
+	if q.From.IsZero() || q.To.IsZero() {
+		return from, to, false
+	}
+
+	from = q.From.UTC().Truncate(time.Minute)
+	if from.Before(q.From) {
+		from = from.Add(time.Minute)
+	}
+	to = q.To.UTC().Truncate(time.Minute)
+	return from, to, to.After(from)
+}
+
+// buildRollupAggregate counts the whole minutes [from, to) from the rollups
+// and the partial minutes at either end of the range from raw logs, so the
+// result equals buildRawAggregate's.
+func buildRollupAggregate(req domain.AggregationRequest, from, to time.Time) (string, []any, error) {
+	q := req.Query
+	rawWhere, rawArgs, err := buildSearchWhere(q)
+	if err != nil {
+		return "", nil, err
+	}
+	rawWhere += " AND (timestamp < ? OR timestamp >= ?)"
+	rawArgs = append(rawArgs, from, to)
+
+	conds := []string{"tenant_id = ?", "minute >= ?", "minute < ?"}
+	args := []any{q.TenantID, from, to}
+	if q.ProjectID != "" {
+		conds = append(conds, "project_id = ?")
+		args = append(args, q.ProjectID)
+	}
+	if len(q.Services) > 0 {
+		conds = append(conds, "service IN (?)")
+		args = append(args, q.Services)
+	}
+	if len(q.Severities) > 0 {
+		conds = append(conds, "level IN (?)")
+		args = append(args, q.Severities)
+	}
+	if len(q.Hosts) > 0 {
+		conds = append(conds, "host IN (?)")
+		args = append(args, q.Hosts)
+	}
+	args = append(args, rawArgs...)
+
+	// Both sides yield the same key type: DateTime buckets or the column.
+	alias, order := "key", "cnt DESC"
+	rollupKey := sanitizeGroupBy(req.GroupBy)
+	rawKey := rollupKey
+	if req.Interval != "" {
+		alias, order = "bucket", "bucket ASC"
+		rollupKey = fmt.Sprintf("toDateTime(%s, 'UTC')", timeIntervalFn(req.Interval, "minute"))
+		rawKey = fmt.Sprintf("toDateTime(%s, 'UTC')", timeIntervalFn(req.Interval, "timestamp"))
+	}
+
+	query := fmt.Sprintf(
+		"SELECT %[1]s, sum(n) AS cnt FROM ("+
+			"SELECT %[2]s AS %[1]s, %[9]s AS n FROM %[3]s WHERE %[4]s GROUP BY %[1]s "+
+			"UNION ALL "+
+			"SELECT %[5]s AS %[1]s, %[10]s AS n FROM %[6]s %[7]s GROUP BY %[1]s"+
+			") GROUP BY %[1]s ORDER BY %[8]s",
+		alias, rollupKey, rollupTable, strings.Join(conds, " AND "), rawKey, logsTable, rawWhere, order,
+		rollupCount(req), rawCount(req),
+	)
+	return query, args, nil
+}
 
-	if q.TenantID != "" {
-		conds = append(conds, "tenant_id = ?")
-		args = append(args, q.TenantID)
+// buildSearchWhere constructs a parameterised WHERE clause from a domain Query.
+// The tenant predicate is mandatory: a query without a tenant is refused
+// rather than run across every tenant's logs.
+func buildSearchWhere(q domain.Query) (string, []any, error) {
+	if q.TenantID == "" {
+		return "", nil, domain.ErrTenantIDRequired
 	}
+	conds := []string{"tenant_id = ?"}
+	args := []any{q.TenantID}
+
 	if q.ProjectID != "" {
 		conds = append(conds, "project_id = ?")
 		args = append(args, q.ProjectID)
@@ -240,32 +431,271 @@ func buildSearchWhere(q domain.Query) (string, []any) {
 		args = append(args, q.RequestID)
 	}
 	if q.BodyContains != "" {
-		conds = append(conds, "positionCaseInsensitive(message, ?) > 0")
-		args = append(args, q.BodyContains)
+		c, a := messageConds(q.BodyContains)
+		conds = append(conds, c...)
+		args = append(args, a...)
 	}
-	for key, val := range q.Attributes {
+
+	// Sorted so that equal queries produce equal SQL.
+	keys := make([]string, 0, len(q.Attributes))
+	for key := range q.Attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		val := q.Attributes[key]
+		// A missing key reads as "", so only a non-empty value implies the
+		// key and value are present, which the map indexes can check.
+		if val != "" {
+			conds = append(conds, "has(mapKeys(attributes), ?)", "has(mapValues(attributes), ?)")
+			args = append(args, key, val)
+		}
 		conds = append(conds, "attributes[?] = ?")
 		args = append(args, key, val)
 	}
 
-	if len(conds) == 0 {
-		return "", args
+	for _, f := range q.Filters {
+		c, a := filterCond(f)
+		conds = append(conds, c)
+		args = append(args, a...)
+	}
+
+	return "WHERE " + strings.Join(conds, " AND "), args, nil
+}
+
+var filterOps = map[domain.FilterOp]string{
+	domain.OpEq:  "=",
+	domain.OpNeq: "!=",
+	domain.OpGt:  ">",
+	domain.OpGte: ">=",
+	domain.OpLt:  "<",
+	domain.OpLte: "<=",
+}
+
+// filterCond returns the condition of a validated filter. A map field
+// matches only logs that have the key, so a missing attribute is neither 0,
+// false nor "". The column follows the value: attributes_number for a
+// number, attributes_bool for a bool.
+func filterCond(f domain.FieldFilter) (string, []any) {
+	column, key := f.Column()
+	if key == "" {
+		if f.Op == domain.OpExists {
+			return column + " != ''", nil
+		}
+		return column + " " + filterOps[f.Op] + " ?", []any{f.Value}
+	}
+	if f.Op == domain.OpExists {
+		return "mapContains(" + column + ", ?)", []any{key}
+	}
+	cond := fmt.Sprintf("mapContains(%[1]s, ?) AND %[1]s[?] %[2]s ?", column, filterOps[f.Op])
+	args := []any{key, key, f.Value}
+	// The values index serves equality on the text of an attribute.
+	if column == "attributes" && f.Op == domain.OpEq {
+		cond = "has(mapValues(attributes), ?) AND " + cond
+		args = append([]any{f.Value}, args...)
+	}
+	return "(" + cond + ")", args
+}
+
+func (r *SearchRepository) Fields(ctx context.Context, q domain.Query) ([]domain.Field, error) {
+	if q.TenantID == "" {
+		return nil, domain.ErrTenantIDRequired
+	}
+	fields := make([]domain.Field, 0)
+	for _, build := range []func(domain.Query) (string, []any){buildCoreFields, buildCatalogFields} {
+		query, args := build(q)
+		rows, err := r.conn.Query(ctx, query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("clickhouse fields: %w", err)
+		}
+		for rows.Next() {
+			var (
+				f   domain.Field
+				typ string
+			)
+			if err := rows.Scan(&f.Name, &typ, &f.FirstSeen, &f.LastSeen, &f.Logs, &f.Cardinality); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("clickhouse scan field: %w", err)
+			}
+			f.Type = domain.FieldType(typ)
+			fields = append(fields, f)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("clickhouse fields rows: %w", err)
+		}
+	}
+	return fields, nil
+}
+
+// buildCoreFields lists the core fields from the rollups, a row per field
+// with a value in the time range.
+func buildCoreFields(q domain.Query) (string, []any) {
+	names := make([]string, len(domain.CoreFields))
+	for i, f := range domain.CoreFields {
+		names[i] = "'" + f + "'"
+	}
+	query := fmt.Sprintf(`
+		SELECT field, 'string', toDateTime64(min(minute), 3, 'UTC'), toDateTime64(max(minute), 3, 'UTC'),
+			sum(logs), uniq(value)
+		FROM %s
+		ARRAY JOIN [%s] AS field, [%s] AS value
+		WHERE tenant_id = ? AND project_id = ? AND minute >= toStartOfMinute(?) AND minute <= ? AND value != ''
+		GROUP BY field
+		ORDER BY field`,
+		rollupTable, strings.Join(names, ", "), strings.Join(domain.CoreFields, ", "))
+	return query, []any{q.TenantID, q.ProjectID, q.From.UTC(), q.To.UTC()}
+}
+
+// buildCatalogFields lists the attribute and tag keys from the field
+// catalog, over the days of the time range.
+func buildCatalogFields(q domain.Query) (string, []any) {
+	query := fmt.Sprintf(`
+		SELECT field, type, min(first_seen), max(last_seen), sum(logs), uniqMerge(distinct_values)
+		FROM %s
+		WHERE tenant_id = ? AND project_id = ? AND day >= toDate(?) AND day <= toDate(?)
+		GROUP BY field, type
+		ORDER BY field, type
+		LIMIT %d`, fieldsTable, maxFields)
+	return query, []any{q.TenantID, q.ProjectID, q.From.UTC(), q.To.UTC()}
+}
+
+func (r *SearchRepository) FieldValues(ctx context.Context, req domain.FieldValuesRequest) (*domain.FieldValues, error) {
+	query, args, err := buildFieldValues(req)
+	if err != nil {
+		return nil, err
+	}
+	out := &domain.FieldValues{Field: req.Field}
+	if err := r.conn.QueryRow(ctx, query, args...).Scan(&out.Values, &out.Cardinality, &out.Logs); err != nil {
+		return nil, fmt.Errorf("clickhouse field values: %w", err)
+	}
+	return out, nil
+}
+
+// buildFieldValues ranks the values of req.Field among the logs of the query
+// that have it. topK and uniq keep memory bounded whatever the cardinality.
+func buildFieldValues(req domain.FieldValuesRequest) (string, []any, error) {
+	where, whereArgs, err := buildSearchWhere(req.Query)
+	if err != nil {
+		return "", nil, err
+	}
+	table, err := tableOf(req.Query)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Text values are ranked whatever the attribute's type.
+	column, key := domain.FieldFilter{Field: req.Field, Op: domain.OpExists}.Column()
+	value, has := column, column+" != ''"
+	var args []any
+	if key != "" {
+		value, has = column+"[?]", "mapContains("+column+", ?)"
+		args = append(args, key)
+	}
+	args = append(args, whereArgs...)
+	if key != "" {
+		args = append(args, key)
+	}
+
+	query := fmt.Sprintf(
+		"SELECT topK(%d)(value), uniq(value), count() FROM (SELECT %s AS value FROM %s %s AND %s)",
+		req.Limit, value, table, where, has,
+	)
+	return query, args, nil
+}
+
+// maxMessageTokens bounds the hasToken conditions added for one needle.
+const maxMessageTokens = 8
+
+// messageConds matches messages containing needle, ignoring ASCII case, in
+// a shape the message skip indexes can serve. The substring match uses
+// multiSearchAny, which the ngram index supports. Words of the needle that
+// are delimited on both sides within it must appear as whole tokens of any
+// matching message, so each is also required with hasToken, which the token
+// index supports. Both conditions are implied by the substring match: the
+// result is the same as positionCaseInsensitive, only faster.
+func messageConds(needle string) ([]string, []any) {
+	needle = asciiLower(needle)
+	conds := []string{"multiSearchAny(lower(message), [?])"}
+	args := []any{needle}
+
+	seen := map[string]bool{}
+	for _, token := range innerTokens(needle) {
+		if seen[token] || len(seen) == maxMessageTokens {
+			continue
+		}
+		seen[token] = true
+		conds = append(conds, "hasToken(lower(message), ?)")
+		args = append(args, token)
+	}
+	return conds, args
+}
+
+// innerTokens returns the tokens of s that have a separator on both sides.
+// Tokens follow ClickHouse's hasToken: runs of ASCII letters and digits and
+// non-ASCII bytes.
+func innerTokens(s string) []string {
+	var tokens []string
+	start := -1
+	for i := 0; i < len(s); i++ {
+		if isTokenByte(s[i]) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start > 0 {
+			tokens = append(tokens, s[start:i])
+		}
+		start = -1
+	}
+	return tokens
+}
+
+func isTokenByte(b byte) bool {
+	return b >= 0x80 || 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' || '0' <= b && b <= '9'
+}
+
+// asciiLower lowercases like ClickHouse's lower: ASCII letters only.
+func asciiLower(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if 'A' <= c && c <= 'Z' {
+			b[i] = c + ('a' - 'A')
+		}
+	}
+	return string(b)
+}
+
+// rehydrationTable matches the tables archive rehydrations load into.
+var rehydrationTable = regexp.MustCompile(`^logify\.rehydrated_[0-9a-f]{32}$`)
+
+// tableOf returns the table q reads: a rehydration's or the live logs. The
+// name is spliced into SQL, so it is checked first.
+func tableOf(q domain.Query) (string, error) {
+	if q.Table == "" {
+		return logsTable, nil
+	}
+	if !rehydrationTable.MatchString(q.Table) {
+		return "", fmt.Errorf("clickhouse: invalid table %q", q.Table)
 	}
-	return "WHERE " + strings.Join(conds, " AND "), args
+	return q.Table, nil
 }
 
-func timeIntervalFn(interval string) string {
+// timeIntervalFn returns the expression bucketing col by interval.
+func timeIntervalFn(interval, col string) string {
 	switch interval {
 	case "1m":
-		return "toStartOfMinute(timestamp)"
+		return "toStartOfMinute(" + col + ")"
 	case "5m":
-		return "toStartOfFiveMinutes(timestamp)"
+		return "toStartOfFiveMinutes(" + col + ")"
 	case "1h":
-		return "toStartOfHour(timestamp)"
+		return "toStartOfHour(" + col + ")"
 	case "1d":
-		return "toStartOfDay(timestamp)"
+		return "toStartOfDay(" + col + ")"
 	default:
-		return "toStartOfHour(timestamp)"
+		return "toStartOfHour(" + col + ")"
 	}
 }
 
@@ -0,0 +1,208 @@
+package clickhouse
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/search/domain"
+)
+
+func TestInnerTokens(t *testing.T) {
+	cases := map[string][]string{
+		"timeout":                           nil,
+		"connection refused":                nil,
+		"failed to connect to db":           {"to", "connect", "to"},
+		" payment ":                         {"payment"},
+		"user_id=42 not found":              {"id", "42", "not"},
+		"état: erreur critique":             {"erreur"},
+		"GET /api/v1/orders?id=7 returned ": {"api", "v1", "orders", "id", "7", "returned"},
+	}
+	for needle, want := range cases {
+		assert.Equal(t, want, innerTokens(needle), needle)
+	}
+}
+
+func TestBuildSearchWhereMessage(t *testing.T) {
+	where, args, err := buildSearchWhere(domain.Query{
+		TenantID:     "t1",
+		BodyContains: "Failed to Connect to DB",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "WHERE tenant_id = ? AND multiSearchAny(lower(message), [?]) AND "+
+		"hasToken(lower(message), ?) AND hasToken(lower(message), ?)", where)
+	assert.Equal(t, []any{"t1", "failed to connect to db", "to", "connect"}, args)
+}
+
+func TestBuildSearchWhereAttributes(t *testing.T) {
+	where, args, err := buildSearchWhere(domain.Query{
+		TenantID:   "t1",
+		Attributes: map[string]string{"region": "eu", "customer": ""},
+	})
+	require.NoError(t, err)
+
+	// An empty value also matches rows without the key, so it gets no
+	// index conditions.
+	assert.Equal(t, "WHERE tenant_id = ? AND attributes[?] = ? AND "+
+		"has(mapKeys(attributes), ?) AND has(mapValues(attributes), ?) AND attributes[?] = ?", where)
+	assert.Equal(t, []any{"t1", "customer", "", "region", "eu", "region", "eu"}, args)
+}
+
+func TestBuildSearchWhereFilters(t *testing.T) {
+	where, args, err := buildSearchWhere(domain.Query{
+		TenantID: "t1",
+		Filters: []domain.FieldFilter{
+			{Field: "severity", Op: domain.OpNeq, Value: "debug"},
+			{Field: "attributes.duration_ms", Op: domain.OpGte, Value: 250.0},
+			{Field: "attributes.region", Op: domain.OpEq, Value: "eu"},
+			{Field: "tags.team", Op: domain.OpExists},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "WHERE tenant_id = ? AND level != ? AND "+
+		"(mapContains(attributes_number, ?) AND attributes_number[?] >= ?) AND "+
+		"(has(mapValues(attributes), ?) AND mapContains(attributes, ?) AND attributes[?] = ?) AND "+
+		"mapContains(tags, ?)", where)
+	assert.Equal(t, []any{"t1", "debug", "duration_ms", "duration_ms", 250.0, "eu", "region", "region", "eu", "team"}, args)
+}
+
+func TestValueAggregate(t *testing.T) {
+	from := time.Date(2026, 7, 1, 10, 0, 0, 0, time.UTC)
+	req := domain.AggregationRequest{
+		Query:    domain.Query{TenantID: "t1", ProjectID: "p1", From: from, To: from.Add(time.Hour)},
+		GroupBy:  "service",
+		Func:     domain.AggAvg,
+		Field:    "attributes.duration_ms",
+		Weighted: true,
+	}
+
+	query, args, err := buildRawAggregate(req)
+	require.NoError(t, err)
+	assert.Contains(t, query, "SELECT service AS key, toUInt64(sum(sample_rate)) AS cnt, "+
+		"avgWeightedOrNullIf(attributes_number[?], sample_rate, mapContains(attributes_number, ?)) AS val FROM ")
+	assert.Equal(t, []any{"duration_ms", "duration_ms", "t1", "p1"}, args[:4])
+
+	req.Field = "service"
+	_, _, err = buildRawAggregate(req)
+	assert.ErrorIs(t, err, domain.ErrInvalidAggregation)
+}
+
+func TestBuildFieldValues(t *testing.T) {
+	from := time.Date(2026, 7, 1, 10, 0, 0, 0, time.UTC)
+	query := domain.Query{TenantID: "t1", ProjectID: "p1", From: from, To: from.Add(time.Hour)}
+
+	sql, args, err := buildFieldValues(domain.FieldValuesRequest{Query: query, Field: "attributes.region", Limit: 5})
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT topK(5)(value), uniq(value), count() FROM (SELECT attributes[?] AS value FROM logify.logs "+
+		"WHERE tenant_id = ? AND project_id = ? AND timestamp >= ? AND timestamp <= ? AND mapContains(attributes, ?))", sql)
+	assert.Equal(t, []any{"region", "t1", "p1", from, from.Add(time.Hour), "region"}, args)
+
+	sql, args, err = buildFieldValues(domain.FieldValuesRequest{Query: query, Field: "severity", Limit: 10})
+	require.NoError(t, err)
+	assert.Contains(t, sql, "(SELECT level AS value FROM logify.logs WHERE ")
+	assert.Contains(t, sql, " AND level != '')")
+	assert.Equal(t, []any{"t1", "p1", from, from.Add(time.Hour)}, args)
+}
+
+func TestRollupRange(t *testing.T) {
+	from := time.Date(2026, 7, 1, 10, 0, 30, 0, time.UTC)
+	to := time.Date(2026, 7, 31, 10, 0, 15, 0, time.UTC)
+	query := domain.Query{TenantID: "t1", ProjectID: "p1", Severities: []string{"error"}, From: from, To: to}
+
+	rFrom, rTo, ok := rollupRange(domain.AggregationRequest{Query: query, Interval: "1h"})
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2026, 7, 1, 10, 1, 0, 0, time.UTC), rFrom)
+	assert.Equal(t, time.Date(2026, 7, 31, 10, 0, 0, 0, time.UTC), rTo)
+
+	_, _, ok = rollupRange(domain.AggregationRequest{Query: query, GroupBy: "service"})
+	assert.True(t, ok)
+
+	ineligible := map[string]domain.AggregationRequest{
+		"message filter":      {Query: withBody(query, "timeout"), Interval: "1h"},
+		"attribute filter":    {Query: withAttributes(query), Interval: "1h"},
+		"rehydration":         {Query: withTable(query), Interval: "1h"},
+		"field filter":        {Query: withFilter(query), Interval: "1h"},
+		"sum of a field":      {Query: query, Interval: "1h", Func: domain.AggSum, Field: "attributes.bytes"},
+		"group by namespace":  {Query: query, GroupBy: "namespace"},
+		"less than a minute":  {Query: withRange(query, from, from.Add(20*time.Second)), Interval: "1m"},
+		"within one minute":   {Query: withRange(query, from, from.Add(29*time.Second)), Interval: "1m"},
+		"no whole minute yet": {Query: withRange(query, from, from.Add(45*time.Second)), Interval: "1m"},
+	}
+	for name, req := range ineligible {
+		_, _, ok := rollupRange(req)
+		assert.False(t, ok, name)
+	}
+}
+
+func TestBuildRollupAggregate(t *testing.T) {
+	from := time.Date(2026, 7, 1, 10, 0, 30, 0, time.UTC)
+	to := time.Date(2026, 7, 31, 10, 0, 15, 0, time.UTC)
+	req := domain.AggregationRequest{
+		Query:    domain.Query{TenantID: "t1", ProjectID: "p1", Services: []string{"api"}, From: from, To: to},
+		Interval: "1h",
+	}
+	rFrom, rTo, ok := rollupRange(req)
+	require.True(t, ok)
+
+	query, args, err := buildRollupAggregate(req, rFrom, rTo)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT bucket, sum(n) AS cnt FROM ("+
+		"SELECT toDateTime(toStartOfHour(minute), 'UTC') AS bucket, sum(logs) AS n FROM logify.logs_rollup_1m "+
+		"WHERE tenant_id = ? AND minute >= ? AND minute < ? AND project_id = ? AND service IN (?) GROUP BY bucket "+
+		"UNION ALL "+
+		"SELECT toDateTime(toStartOfHour(timestamp), 'UTC') AS bucket, count() AS n FROM logify.logs "+
+		"WHERE tenant_id = ? AND project_id = ? AND timestamp >= ? AND timestamp <= ? AND service IN (?) "+
+		"AND (timestamp < ? OR timestamp >= ?) GROUP BY bucket"+
+		") GROUP BY bucket ORDER BY bucket ASC", query)
+	assert.Equal(t, []any{
+		"t1", rFrom, rTo, "p1", []string{"api"},
+		"t1", "p1", from, to, []string{"api"}, rFrom, rTo,
+	}, args)
+}
+
+func TestWeightedAggregateSumsSampleRates(t *testing.T) {
+	from := time.Date(2026, 7, 1, 10, 0, 0, 0, time.UTC)
+	req := domain.AggregationRequest{
+		Query:    domain.Query{TenantID: "t1", ProjectID: "p1", From: from, To: from.Add(time.Hour)},
+		GroupBy:  "level",
+		Weighted: true,
+	}
+
+	raw, _, err := buildRawAggregate(req)
+	require.NoError(t, err)
+	assert.Contains(t, raw, "SELECT level AS key, toUInt64(sum(sample_rate)) AS cnt FROM logify.logs ")
+
+	rollup, _, err := buildRollupAggregate(req, from, from.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Contains(t, rollup, "sum(weighted_logs) AS n FROM logify.logs_rollup_1m ")
+	assert.Contains(t, rollup, "toUInt64(sum(sample_rate)) AS n FROM logify.logs ")
+}
+
+func withBody(q domain.Query, body string) domain.Query {
+	q.BodyContains = body
+	return q
+}
+
+func withAttributes(q domain.Query) domain.Query {
+	q.Attributes = map[string]string{"region": "eu"}
+	return q
+}
+
+func withFilter(q domain.Query) domain.Query {
+	q.Filters = []domain.FieldFilter{{Field: "attributes.status", Op: domain.OpGte, Value: 500.0}}
+	return q
+}
+
+func withTable(q domain.Query) domain.Query {
+	q.Table = "logify.rehydrated_0123456789abcdef0123456789abcdef"
+	return q
+}
+
+func withRange(q domain.Query, from, to time.Time) domain.Query {
+	q.From, q.To = from, to
+	return q
+}
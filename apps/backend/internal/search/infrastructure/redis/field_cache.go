@@ -0,0 +1,71 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/search/domain"
+)
+
+const fieldCachePrefix = "search:fields:"
+
+// FieldCache implements domain.FieldCache in Redis, so every server instance
+// shares the results.
+type FieldCache struct {
+	client redis.UniversalClient
+}
+
+func NewFieldCache(client redis.UniversalClient) *FieldCache {
+	return &FieldCache{client: client}
+}
+
+func (c *FieldCache) GetFields(ctx context.Context, key string) ([]domain.Field, bool, error) {
+	var fields []domain.Field
+	ok, err := c.get(ctx, key, &fields)
+	return fields, ok, err
+}
+
+func (c *FieldCache) SetFields(ctx context.Context, key string, fields []domain.Field, ttl time.Duration) error {
+	return c.set(ctx, key, fields, ttl)
+}
+
+func (c *FieldCache) GetValues(ctx context.Context, key string) (*domain.FieldValues, bool, error) {
+	var values domain.FieldValues
+	ok, err := c.get(ctx, key, &values)
+	if !ok {
+		return nil, ok, err
+	}
+	return &values, true, nil
+}
+
+func (c *FieldCache) SetValues(ctx context.Context, key string, values *domain.FieldValues, ttl time.Duration) error {
+	return c.set(ctx, key, values, ttl)
+}
+
+func (c *FieldCache) get(ctx context.Context, key string, dst any) (bool, error) {
+	b, err := c.client.Get(ctx, fieldCachePrefix+key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return false, nil
+		}
+		return false, err
+	}
+	// An entry that does not decode, e.g. written by an older version, is a
+	// miss and gets overwritten.
+	if err := json.Unmarshal(b, dst); err != nil {
+		return false, nil
+	}
+	return true, nil
+}
+
+func (c *FieldCache) set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return c.client.Set(ctx, fieldCachePrefix+key, b, ttl).Err()
+}
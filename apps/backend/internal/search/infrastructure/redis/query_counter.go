@@ -0,0 +1,32 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const queryCounterPrefix = "search:queries:"
+
+// QueryCounter implements domain.QueryCounter with one Redis counter per
+// tenant and window, expiring once the window is over.
+type QueryCounter struct {
+	client redis.UniversalClient
+}
+
+func NewQueryCounter(client redis.UniversalClient) *QueryCounter {
+	return &QueryCounter{client: client}
+}
+
+func (c *QueryCounter) Incr(ctx context.Context, tenantID string, start time.Time, window time.Duration) (int64, error) {
+	key := queryCounterPrefix + tenantID + ":" + strconv.FormatInt(start.Unix(), 10)
+	pipe := c.client.TxPipeline()
+	n := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, 2*window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return n.Val(), nil
+}
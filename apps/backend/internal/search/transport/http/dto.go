@@ -14,20 +14,50 @@ type SearchRequest struct {
 	Limit     int       `json:"limit,omitempty"`
 	Cursor    string    `json:"cursor,omitempty"`
 	Sort      Sort      `json:"sort"`
+	// RehydrationID searches a rehydration of archived logs instead.
+	RehydrationID string `json:"rehydration_id,omitempty"`
+	// Filters must all match, e.g. {"field": "attributes.latency_ms",
+	// "op": "gt", "value": 500}.
+	Filters []Filter `json:"filters,omitempty"`
 }
 
 func (r SearchRequest) ToQuery() domain.Query {
 	return domain.Query{
-		ProjectID:    r.ProjectID,
-		BodyContains: r.Query,
-		From:         r.TimeRange.From,
-		To:           r.TimeRange.To,
-		Limit:        r.Limit,
-		Cursor:       r.Cursor,
-		SortDesc:     !strings.EqualFold(r.Sort.Order, "asc"),
+		ProjectID:     r.ProjectID,
+		BodyContains:  r.Query,
+		Filters:       toFilters(r.Filters),
+		From:          r.TimeRange.From,
+		To:            r.TimeRange.To,
+		Limit:         r.Limit,
+		Cursor:        r.Cursor,
+		SortDesc:      !strings.EqualFold(r.Sort.Order, "asc"),
+		RehydrationID: r.RehydrationID,
 	}
 }
 
+// Filter compares a field with a value. Numbers and booleans compare the
+// typed values of attributes, strings their text.
+type Filter struct {
+	// Field is a column (service, level, host, environment, namespace,
+	// source, trace_id, span_id, request_id, user_id), attributes.<key> or
+	// tags.<key>.
+	Field string `json:"field" binding:"required"`
+	// Op is eq, neq, gt, gte, lt, lte or exists.
+	Op    string `json:"op" binding:"required"`
+	Value any    `json:"value,omitempty"`
+}
+
+func toFilters(filters []Filter) []domain.FieldFilter {
+	if len(filters) == 0 {
+		return nil
+	}
+	out := make([]domain.FieldFilter, len(filters))
+	for i, f := range filters {
+		out[i] = domain.FieldFilter{Field: f.Field, Op: domain.FilterOp(f.Op), Value: f.Value}
+	}
+	return out
+}
+
 type TimeRange struct {
 	From time.Time `json:"from"`
 	To   time.Time `json:"to"`
@@ -64,14 +94,24 @@ type LogResponse struct {
 	Tags          map[string]string `json:"tags,omitempty"`
 	Attributes    map[string]string `json:"attributes,omitempty"`
 	IngestionTime time.Time         `json:"ingestion_time"`
+	SampleRate    uint32            `json:"sample_rate"`
 }
 
 type AggregateRequest struct {
-	TenantID string    `json:"tenant_id,omitempty"`
-	GroupBy  string    `json:"group_by,omitempty"`
-	Interval string    `json:"interval,omitempty"` // "1m", "5m", "1h", "1d"
-	From     time.Time `json:"from" binding:"required"`
-	To       time.Time `json:"to"   binding:"required"`
+	ProjectID string    `json:"project_id" binding:"required"`
+	GroupBy   string    `json:"group_by,omitempty"`
+	Interval  string    `json:"interval,omitempty"` // "1m", "5m", "1h", "1d"
+	From      time.Time `json:"from" binding:"required"`
+	To        time.Time `json:"to"   binding:"required"`
+	// RehydrationID aggregates a rehydration of archived logs instead.
+	RehydrationID string `json:"rehydration_id,omitempty"`
+	// Weighted counts sampled logs as the number they stand for.
+	Weighted bool     `json:"weighted,omitempty"`
+	Filters  []Filter `json:"filters,omitempty"`
+	// Func is count (the default), sum, avg, min or max; all but count
+	// apply to the numeric attribute Field, e.g. attributes.latency_ms.
+	Func  string `json:"func,omitempty"`
+	Field string `json:"field,omitempty"`
 }
 
 type AggregateResponse struct {
@@ -82,10 +122,10 @@ type AggBucketResponse struct {
 	Timestamp *time.Time `json:"timestamp,omitempty"`
 	Key       string     `json:"key,omitempty"`
 	Count     uint64     `json:"count"`
+	Value     *float64   `json:"value,omitempty"`
 }
 
 type ExportRequest struct {
-	TenantID     string            `json:"tenant_id,omitempty"`
 	Services     []string          `json:"services,omitempty"`
 	Severities   []string          `json:"severities,omitempty"`
 	BodyContains string            `json:"body_contains,omitempty"`
@@ -141,7 +181,43 @@ func toLogResponse(e domain.LogEntry) LogResponse {
 		Tags:          e.Tags,
 		Attributes:    e.Attributes,
 		IngestionTime: e.IngestionTime,
+		SampleRate:    e.SampleRate,
+	}
+}
+
+// FieldsRequest is the time range of GET /v1/projects/{id}/fields, RFC 3339;
+// it defaults to the last day.
+type FieldsRequest struct {
+	From time.Time `form:"from"`
+	To   time.Time `form:"to"`
+}
+
+type FieldsResponse struct {
+	Fields []FieldResponse `json:"fields"`
+}
+
+type FieldResponse struct {
+	Name        string    `json:"name"`
+	Type        string    `json:"type"`
+	FirstSeen   time.Time `json:"first_seen"`
+	LastSeen    time.Time `json:"last_seen"`
+	Logs        uint64    `json:"logs"`
+	Cardinality uint64    `json:"cardinality"`
+}
+
+func toFieldsResponse(fields []domain.Field) FieldsResponse {
+	out := make([]FieldResponse, len(fields))
+	for i, f := range fields {
+		out[i] = FieldResponse{
+			Name:        f.Name,
+			Type:        string(f.Type),
+			FirstSeen:   f.FirstSeen,
+			LastSeen:    f.LastSeen,
+			Logs:        f.Logs,
+			Cardinality: f.Cardinality,
+		}
 	}
+	return FieldsResponse{Fields: out}
 }
 
 func toAggregateResponse(r *domain.AggregationResult) AggregateResponse {
@@ -151,7 +227,55 @@ func toAggregateResponse(r *domain.AggregationResult) AggregateResponse {
 			Timestamp: b.Timestamp,
 			Key:       b.Key,
 			Count:     b.Count,
+			Value:     b.Value,
 		}
 	}
 	return AggregateResponse{Buckets: buckets}
 }
+
+// FieldValuesRequest asks for the most frequent values of a field among the
+// logs the search it carries would match.
+type FieldValuesRequest struct {
+	ProjectID     string    `json:"project_id" binding:"required"`
+	Query         string    `json:"query,omitempty"`
+	TimeRange     TimeRange `json:"time_range"`
+	RehydrationID string    `json:"rehydration_id,omitempty"`
+	Filters       []Filter  `json:"filters,omitempty"`
+	// Field is a column, attributes.<key> or tags.<key>.
+	Field string `json:"field" binding:"required"`
+	// Limit is the number of values, 10 by default and at most 100.
+	Limit int `json:"limit,omitempty"`
+}
+
+func (r FieldValuesRequest) toDomain() domain.FieldValuesRequest {
+	return domain.FieldValuesRequest{
+		Query: domain.Query{
+			ProjectID:     r.ProjectID,
+			BodyContains:  r.Query,
+			Filters:       toFilters(r.Filters),
+			From:          r.TimeRange.From,
+			To:            r.TimeRange.To,
+			RehydrationID: r.RehydrationID,
+		},
+		Field: r.Field,
+		Limit: r.Limit,
+	}
+}
+
+type FieldValuesResponse struct {
+	Field string `json:"field"`
+	// Values are the most frequent first; the ranking is approximate.
+	Values []string `json:"values"`
+	// Cardinality estimates the field's distinct values.
+	Cardinality uint64 `json:"cardinality"`
+	// Logs is the number of matching logs that have the field.
+	Logs uint64 `json:"logs"`
+}
+
+func toFieldValuesResponse(v *domain.FieldValues) FieldValuesResponse {
+	values := v.Values
+	if values == nil {
+		values = []string{}
+	}
+	return FieldValuesResponse{Field: v.Field, Values: values, Cardinality: v.Cardinality, Logs: v.Logs}
+}
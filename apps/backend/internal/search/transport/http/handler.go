@@ -2,15 +2,19 @@ package http
 
 import (
 	"errors"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 
+	auditDomain "github.com/indalyadav56/logify/apps/backend/internal/audit/domain"
 	"github.com/indalyadav56/logify/apps/backend/internal/search/application"
 	"github.com/indalyadav56/logify/apps/backend/internal/search/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
 )
 
 // Handler handles all search-related HTTP requests.
@@ -23,29 +27,18 @@ func NewHandler(service *application.SearchService, log *zap.Logger) *Handler {
 	return &Handler{service: service, log: log}
 }
 
-// resolveTenantID reads tenant_id from gin context (auth middleware), then falls
-// back to the X-Tenant-ID header, and finally to a field in the request struct.
-func resolveTenantID(c *gin.Context, fromBody string) string {
-	if id := c.GetString("tenant_id"); id != "" {
-		return id
-	}
-	if id := c.GetHeader("X-Tenant-ID"); id != "" {
-		return id
-	}
-	return fromBody
-}
-
 // Search runs a log query against ClickHouse.
 // @Summary      Search logs
-// @Description  Run a structured log search query for a tenant.
+// @Description  Run a structured log search query within one of the caller's projects.
 // @Tags         logs
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
-// @Param        X-Tenant-ID  header    string         false  "Tenant ID override (also resolved from JWT or body)"
 // @Param        request      body      SearchRequest  true   "Search query"
 // @Success      200          {object}  SearchResponse "Search results"
-// @Failure      400          {object}  map[string]string "Invalid request or missing tenant_id"
+// @Failure      400          {object}  map[string]string "Invalid request"
+// @Failure      401          {object}  map[string]string "Missing tenant"
+// @Failure      404          {object}  map[string]string "Project not found"
 // @Failure      500          {object}  map[string]string "Search failed"
 // @Router       /v1/logs/search [post]
 func (h *Handler) Search(c *gin.Context) {
@@ -58,15 +51,7 @@ func (h *Handler) Search(c *gin.Context) {
 
 	result, err := h.service.Search(c.Request.Context(), req.ToQuery())
 	if err != nil {
-		switch {
-		case errors.Is(err, domain.ErrTenantIDRequired):
-			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
-		case errors.Is(err, domain.ErrProjectIDRequired),
-			errors.Is(err, domain.ErrTimeRangeRequired),
-			errors.Is(err, domain.ErrInvalidTimeRange),
-			errors.Is(err, domain.ErrLimitTooLarge):
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		default:
+		if !writeQueryError(c, err) {
 			h.log.Error("search failed", zap.Error(err))
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "search failed"})
 		}
@@ -78,31 +63,27 @@ func (h *Handler) Search(c *gin.Context) {
 
 // GetByID fetches a single log entry by ID.
 // @Summary      Get log by ID
-// @Description  Retrieve a single log entry by its ID for a tenant.
+// @Description  Retrieve a single log entry of the caller's tenant by its ID.
 // @Tags         logs
 // @Produce      json
 // @Security     BearerAuth
 // @Param        id           path      string  true   "Log ID"
-// @Param        tenant_id    query     string  false  "Tenant ID (also resolved from JWT/header)"
-// @Param        X-Tenant-ID  header    string  false  "Tenant ID override"
 // @Success      200          {object}  LogResponse "Log entry"
-// @Failure      400          {object}  map[string]string "Missing tenant_id"
+// @Failure      401          {object}  map[string]string "Missing tenant"
 // @Failure      404          {object}  map[string]string "Log not found"
 // @Failure      500          {object}  map[string]string "Fetch failed"
 // @Router       /v1/logs/{id} [get]
 func (h *Handler) GetByID(c *gin.Context) {
 	logID := c.Param("id")
-	tenantID := resolveTenantID(c, c.Query("tenant_id"))
-	if tenantID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "tenant_id is required"})
-		return
-	}
 
-	entry, err := h.service.GetByID(c.Request.Context(), tenantID, logID)
+	entry, err := h.service.GetByID(c.Request.Context(), logID)
 	if err != nil {
-		if errors.Is(err, domain.ErrLogNotFound) {
+		switch {
+		case errors.Is(err, domain.ErrLogNotFound):
 			c.JSON(http.StatusNotFound, gin.H{"error": "log not found"})
 			return
+		case writeQueryError(c, err):
+			return
 		}
 		h.log.Error("get log by id failed", zap.String("log_id", logID), zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "fetch failed"})
@@ -114,7 +95,7 @@ func (h *Handler) GetByID(c *gin.Context) {
 
 // Aggregate runs a log aggregation query.
 // @Summary      Aggregate logs
-// @Description  Run a grouped/time-bucketed aggregation over logs.
+// @Description  Run a grouped/time-bucketed aggregation over one project's logs. With weighted, sampled logs count as the number they stand for.
 // @Tags         logs
 // @Accept       json
 // @Produce      json
@@ -122,6 +103,8 @@ func (h *Handler) GetByID(c *gin.Context) {
 // @Param        request  body      AggregateRequest  true  "Aggregation query"
 // @Success      200      {object}  AggregateResponse "Aggregation result"
 // @Failure      400      {object}  map[string]string "Invalid request"
+// @Failure      401      {object}  map[string]string "Missing tenant"
+// @Failure      404      {object}  map[string]string "Project not found"
 // @Failure      500      {object}  map[string]string "Aggregate failed"
 // @Router       /v1/logs/aggregate [post]
 func (h *Handler) Aggregate(c *gin.Context) {
@@ -131,30 +114,25 @@ func (h *Handler) Aggregate(c *gin.Context) {
 		return
 	}
 
-	tenantID := resolveTenantID(c, req.TenantID)
-	if tenantID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "tenant_id is required"})
-		return
-	}
-
 	aggReq := domain.AggregationRequest{
 		Query: domain.Query{
-			TenantID: tenantID,
-			From:     req.From,
-			To:       req.To,
+			ProjectID:     req.ProjectID,
+			From:          req.From,
+			To:            req.To,
+			RehydrationID: req.RehydrationID,
+			Filters:       toFilters(req.Filters),
 		},
 		GroupBy:  req.GroupBy,
 		Interval: req.Interval,
+		Weighted: req.Weighted,
+		Func:     domain.AggFunc(req.Func),
+		Field:    req.Field,
 	}
 
 	result, err := h.service.Aggregate(c.Request.Context(), aggReq)
 	if err != nil {
-		switch {
-		case errors.Is(err, domain.ErrInvalidTimeRange),
-			errors.Is(err, domain.ErrTimeRangeRequired):
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		default:
-			h.log.Error("aggregate failed", zap.String("tenant_id", tenantID), zap.Error(err))
+		if !writeQueryError(c, err) {
+			h.log.Error("aggregate failed", zap.String("project_id", req.ProjectID), zap.Error(err))
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "aggregate failed"})
 		}
 		return
@@ -163,6 +141,75 @@ func (h *Handler) Aggregate(c *gin.Context) {
 	c.JSON(http.StatusOK, toAggregateResponse(result))
 }
 
+// Fields lists the fields of a project's logs.
+// @Summary      List fields
+// @Description  Return the core fields (service, level, environment, host) and the attribute and tag keys seen in the project's logs within the time range, with their type, when they were first and last seen, how many logs had them and an estimate of their distinct values. Attributes whose values are numbers or booleans are typed, and can be compared and aggregated as such. Attribute and tag keys are tracked per day.
+// @Tags         logs
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      string  true   "Project ID (UUID)"
+// @Param        from  query     string  false  "Start of the time range (RFC 3339), default a day before to"
+// @Param        to    query     string  false  "End of the time range (RFC 3339), default now"
+// @Success      200   {object}  FieldsResponse "Fields"
+// @Failure      400   {object}  map[string]string "Invalid time range"
+// @Failure      401   {object}  map[string]string "Missing tenant"
+// @Failure      404   {object}  map[string]string "Project not found"
+// @Failure      500   {object}  map[string]string "Fields failed"
+// @Router       /v1/projects/{id}/fields [get]
+func (h *Handler) Fields(c *gin.Context) {
+	projectID := c.Param("id")
+
+	var req FieldsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	fields, err := h.service.Fields(c.Request.Context(), domain.Query{ProjectID: projectID, From: req.From, To: req.To})
+	if err != nil {
+		if !writeQueryError(c, err) {
+			h.log.Error("fields failed", zap.String("project_id", projectID), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "fields failed"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, toFieldsResponse(fields))
+}
+
+// FieldValues returns the most frequent values of a field.
+// @Summary      Field values
+// @Description  Return the most frequent values of a field among the logs the search would match, for autocomplete, with estimates of its distinct values. Rankings are approximate.
+// @Tags         logs
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      FieldValuesRequest  true  "Field and search"
+// @Success      200      {object}  FieldValuesResponse "Values"
+// @Failure      400      {object}  map[string]string "Invalid request"
+// @Failure      401      {object}  map[string]string "Missing tenant"
+// @Failure      404      {object}  map[string]string "Project not found"
+// @Failure      500      {object}  map[string]string "Field values failed"
+// @Router       /v1/logs/field-values [post]
+func (h *Handler) FieldValues(c *gin.Context) {
+	var req FieldValuesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	values, err := h.service.FieldValues(c.Request.Context(), req.toDomain())
+	if err != nil {
+		if !writeQueryError(c, err) {
+			h.log.Error("field values failed", zap.String("project_id", req.ProjectID), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "field values failed"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, toFieldValuesResponse(values))
+}
+
 // Export starts an async log export job.
 // @Summary      Export logs
 // @Description  Start an asynchronous log export job; returns the export job ID.
@@ -175,6 +222,8 @@ func (h *Handler) Aggregate(c *gin.Context) {
 // @Failure      400      {object}  map[string]string "Invalid request"
 // @Router       /v1/logs/export [post]
 func (h *Handler) Export(c *gin.Context) {
+	middleware.AuditAs(c, auditDomain.ActionLogExport)
+
 	var req ExportRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -206,3 +255,38 @@ func (h *Handler) ExportStatus(c *gin.Context) {
 		CreatedAt: time.Now().UTC(),
 	})
 }
+
+// writeQueryError maps the domain errors shared by the query handlers onto
+// a response. It reports false when err is not one of them.
+func writeQueryError(c *gin.Context, err error) bool {
+	var limitErr *domain.QueryLimitError
+	switch {
+	case errors.As(err, &limitErr):
+		c.Header("Retry-After", retryAfterSeconds(limitErr.RetryAfter))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrTenantIDRequired):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrProjectNotFound),
+		errors.Is(err, domain.ErrRehydrationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrRehydrationNotReady):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrProjectIDRequired),
+		errors.Is(err, domain.ErrTimeRangeRequired),
+		errors.Is(err, domain.ErrInvalidTimeRange),
+		errors.Is(err, domain.ErrLimitTooLarge),
+		errors.Is(err, domain.ErrInvalidFilter),
+		errors.Is(err, domain.ErrInvalidAggregation),
+		errors.Is(err, domain.ErrInvalidField):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		return false
+	}
+	return true
+}
+
+// retryAfterSeconds renders a Retry-After header value, rounding up so the
+// client never retries before the window has moved on.
+func retryAfterSeconds(d time.Duration) string {
+	return strconv.Itoa(max(int(math.Ceil(d.Seconds())), 1))
+}
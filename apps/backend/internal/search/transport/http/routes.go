@@ -1,18 +1,32 @@
 package http
 
-import "github.com/gin-gonic/gin"
+import (
+	"github.com/gin-gonic/gin"
+
+	roleDomain "github.com/indalyadav56/logify/apps/backend/internal/role/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
+)
+
+// RegisterRoutes mounts the search routes. The query routes name their
+// project in the body (or, for a single log, not at all), so they are
+// authorized per project by the SearchService rather than here: a
+// tenant-level check would shut out project members.
+func RegisterRoutes(router *gin.RouterGroup, h *Handler, authz *middleware.Authorizer) {
+	canExport := authz.RequirePermission(roleDomain.ResourceLog, roleDomain.ActionExport)
 
-func RegisterRoutes(router *gin.RouterGroup, h *Handler) {
 	logs := router.Group("/v1/logs")
 	{
 		logs.POST("/search", h.Search)
 		logs.GET("/:id", h.GetByID)
 		logs.POST("/aggregate", h.Aggregate)
-		logs.POST("/export", h.Export)
+		logs.POST("/field-values", h.FieldValues)
+		logs.POST("/export", canExport, h.Export)
 	}
 
+	router.GET("/v1/projects/:id/fields", authz.RequireProjectPermission("id", roleDomain.ResourceLog, roleDomain.ActionRead), h.Fields)
+
 	exports := router.Group("/exports")
 	{
-		exports.GET("/:id", h.ExportStatus)
+		exports.GET("/:id", canExport, h.ExportStatus)
 	}
 }
@@ -1,27 +1,84 @@
 package middleware
 
 import (
+	"context"
+	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"go.uber.org/zap"
 )
 
-// AuditLog records every admin action for compliance.
-// Apply only on /admin routes.
-func AuditLog(log *zap.Logger) gin.HandlerFunc {
+// ctxKeyAuditAction names the action AuditLog records for the request.
+const ctxKeyAuditAction = "audit.action"
+
+// AuditedRequest is a completed mutating API call.
+type AuditedRequest struct {
+	// Action is set by the handler through AuditAs; empty for a generic
+	// API call.
+	Action string
+	Method string
+	// Route is the matched route pattern, e.g. /v1/roles/:id.
+	Route    string
+	Params   map[string]string
+	Status   int
+	Duration time.Duration
+}
+
+// RequestAuditor records mutating API calls. It is implemented by
+// audit/application.AuditService.
+type RequestAuditor interface {
+	RecordRequest(ctx context.Context, r AuditedRequest)
+}
+
+// AuditLog records every POST, PUT, PATCH and DELETE once the handler has
+// run, including rejected ones. The caller is read from the request context
+// after the handler, so the middleware may sit before AuthMiddleware. Routes
+// in skip (patterns as registered) are not recorded; they are meant for
+// high-volume data-plane writes and read-only POST queries.
+func AuditLog(auditor RequestAuditor, skip ...string) gin.HandlerFunc {
+	skipped := make(map[string]bool, len(skip))
+	for _, route := range skip {
+		skipped[route] = true
+	}
 	return func(c *gin.Context) {
+		route := c.FullPath()
+		// Unmatched paths are noise, typically scanners.
+		if route == "" || !isMutating(c.Request.Method) || skipped[route] {
+			c.Next()
+			return
+		}
+
 		start := time.Now()
 		c.Next()
 
-		log.Info("admin_action",
-			// zap.String("request_id", c.GetString(ContextKey)),
-			zap.String("actor", c.GetString("admin_user_id")),
-			zap.String("method", c.Request.Method),
-			zap.String("path", c.Request.URL.Path),
-			zap.Int("status", c.Writer.Status()),
-			zap.Duration("duration", time.Since(start)),
-			zap.String("client_ip", c.ClientIP()),
-		)
+		var params map[string]string
+		if len(c.Params) > 0 {
+			params = make(map[string]string, len(c.Params))
+			for _, p := range c.Params {
+				params[p.Key] = p.Value
+			}
+		}
+		auditor.RecordRequest(c.Request.Context(), AuditedRequest{
+			Action:   c.GetString(ctxKeyAuditAction),
+			Method:   c.Request.Method,
+			Route:    route,
+			Params:   params,
+			Status:   c.Writer.Status(),
+			Duration: time.Since(start),
+		})
+	}
+}
+
+// AuditAs names the action AuditLog records for the current request, for
+// handlers whose calls deserve a specific action (e.g. "log.export").
+func AuditAs(c *gin.Context, action string) {
+	c.Set(ctxKeyAuditAction, action)
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
 	}
+	return false
 }
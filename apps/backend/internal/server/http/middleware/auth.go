@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -12,20 +13,41 @@ import (
 )
 
 // Claims is the typed view of the auth-related fields the API cares about.
-// It is populated from the JWT MapClaims returned by pkg/jwt.GetClaims.
+// It is populated from the JWT MapClaims returned by pkg/jwt.GetClaims, or
+// by a TokenAuthenticator for personal access tokens.
 type Claims struct {
-	UserID   string
-	Role     string
-	TenantID string
+	UserID    string
+	Role      string
+	TenantID  string
+	SessionID string
+	// TokenID and Scopes are set for access tokens only. Scopes are
+	// permission keys that cap what the token may do.
+	TokenID string
+	Scopes  []string
+}
+
+// accessTokenPrefix tells personal access tokens apart from JWTs; it matches
+// auth/domain.AccessTokenPrefix.
+const accessTokenPrefix = "lgf_pat_"
+
+// ErrInvalidToken is returned by a TokenAuthenticator for unknown, revoked or
+// expired tokens.
+var ErrInvalidToken = errors.New("invalid access token")
+
+// TokenAuthenticator resolves a personal access token to the claims of the
+// principal it acts as. It is implemented by auth/application.AccessTokenService.
+type TokenAuthenticator interface {
+	Authenticate(ctx context.Context, raw string) (*Claims, error)
 }
 
 // Gin context keys. Exported so handlers may read with c.GetString(...) etc.
 const (
-	CtxKeyClaims   = "auth.claims"
-	CtxKeyUserID   = "auth.user_id"
-	CtxKeyEmail    = "auth.email"
-	CtxKeyRole     = "auth.role"
-	CtxKeyTenantID = "auth.tenant_id"
+	CtxKeyClaims    = "auth.claims"
+	CtxKeyUserID    = "auth.user_id"
+	CtxKeyEmail     = "auth.email"
+	CtxKeyRole      = "auth.role"
+	CtxKeyTenantID  = "auth.tenant_id"
+	CtxKeySessionID = "auth.session_id"
 )
 
 // stdCtxKey is a private type for request-context keys, avoiding collisions
@@ -38,7 +60,9 @@ const (
 	stdCtxKeyTenantID stdCtxKey = "auth.tenant_id"
 )
 
-func AuthMiddleware(j *jwtpkg.JWT) gin.HandlerFunc {
+// AuthMiddleware accepts JWT access tokens and, when tokens is non-nil,
+// personal access tokens.
+func AuthMiddleware(j *jwtpkg.JWT, tokens TokenAuthenticator) gin.HandlerFunc {
 	if j == nil {
 		// Fail loudly at boot rather than silently accepting any token later.
 		panic("auth middleware: jwt helper is required")
@@ -50,6 +74,21 @@ func AuthMiddleware(j *jwtpkg.JWT) gin.HandlerFunc {
 			return
 		}
 
+		if tokens != nil && strings.HasPrefix(raw, accessTokenPrefix) {
+			claims, err := tokens.Authenticate(c.Request.Context(), raw)
+			if err != nil {
+				if errors.Is(err, ErrInvalidToken) {
+					response.Unauthorized(c, "Invalid or expired token")
+					return
+				}
+				response.InternalServerError(c, "Failed to authenticate token")
+				return
+			}
+			setClaims(c, claims)
+			c.Next()
+			return
+		}
+
 		token, err := j.ValidateToken(raw)
 		if err != nil || token == nil || !token.Valid {
 			response.Unauthorized(c, "Invalid or expired token")
@@ -69,30 +108,37 @@ func AuthMiddleware(j *jwtpkg.JWT) gin.HandlerFunc {
 			userID = stringClaim(mapClaims, "user_id")
 		}
 
-		claims := &Claims{
-			UserID:   userID,
-			Role:     stringClaim(mapClaims, "role"),
-			TenantID: stringClaim(mapClaims, "tenant_id"),
-		}
-
-		// Mirror data onto Gin's context for handlers that use c.Get(...).
-		c.Set(CtxKeyClaims, claims)
-		c.Set(CtxKeyUserID, claims.UserID)
-		c.Set(CtxKeyRole, claims.Role)
-		c.Set(CtxKeyTenantID, claims.TenantID)
-
-		// Also propagate onto request.Context() so service/repo layers
-		// (which only see context.Context) can pick the same values up.
-		ctx := c.Request.Context()
-		ctx = context.WithValue(ctx, stdCtxKeyClaims, claims)
-		ctx = context.WithValue(ctx, stdCtxKeyUserID, claims.UserID)
-		ctx = context.WithValue(ctx, stdCtxKeyTenantID, claims.TenantID)
-		c.Request = c.Request.WithContext(ctx)
-
+		setClaims(c, &Claims{
+			UserID:    userID,
+			Role:      stringClaim(mapClaims, "role"),
+			TenantID:  stringClaim(mapClaims, "tenant_id"),
+			SessionID: stringClaim(mapClaims, "sid"),
+		})
 		c.Next()
 	}
 }
 
+func setClaims(c *gin.Context, claims *Claims) {
+	// Mirror data onto Gin's context for handlers that use c.Get(...).
+	c.Set(CtxKeyClaims, claims)
+	c.Set(CtxKeyUserID, claims.UserID)
+	c.Set(CtxKeyRole, claims.Role)
+	c.Set(CtxKeyTenantID, claims.TenantID)
+	c.Set(CtxKeySessionID, claims.SessionID)
+
+	// Also propagate onto request.Context() so service/repo layers
+	// (which only see context.Context) can pick the same values up.
+	c.Request = c.Request.WithContext(WithClaims(c.Request.Context(), claims))
+}
+
+// WithClaims returns a copy of ctx carrying claims, as AuthMiddleware leaves
+// the request context.
+func WithClaims(ctx context.Context, claims *Claims) context.Context {
+	ctx = context.WithValue(ctx, stdCtxKeyClaims, claims)
+	ctx = context.WithValue(ctx, stdCtxKeyUserID, claims.UserID)
+	return context.WithValue(ctx, stdCtxKeyTenantID, claims.TenantID)
+}
+
 // bearerToken extracts and returns the token from a "Bearer <token>"
 // Authorization header. On a malformed/missing header it writes the proper
 // 401 response and returns ok=false.
@@ -149,6 +195,20 @@ func UserUUIDFromContext(ctx context.Context) (uuid.UUID, bool) {
 	return id, true
 }
 
+// SessionUUIDFromContext returns the auth session the access token was issued
+// for. Tokens minted before sessions were tracked carry no "sid" claim.
+func SessionUUIDFromContext(ctx context.Context) (uuid.UUID, bool) {
+	claims, ok := ClaimsFromContext(ctx)
+	if !ok || claims.SessionID == "" {
+		return uuid.Nil, false
+	}
+	id, err := uuid.Parse(claims.SessionID)
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
 // TenantIDFromContext returns the tenant id (string) the caller belongs to.
 func TenantIDFromContext(ctx context.Context) (string, bool) {
 	v, ok := ctx.Value(stdCtxKeyTenantID).(string)
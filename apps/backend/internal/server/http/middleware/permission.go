@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/indalyadav56/logify/apps/backend/pkg/response"
+)
+
+// PermissionChecker answers authorization questions for the Authorizer. It is
+// implemented by role/application.PermissionResolver.
+type PermissionChecker interface {
+	HasPermission(ctx context.Context, userID, tenantID uuid.UUID, projectID *uuid.UUID, resource, action string) (bool, error)
+}
+
+// Authorizer builds per-route permission middleware. It must run after
+// AuthMiddleware, which supplies the user and tenant being checked.
+type Authorizer struct {
+	checker PermissionChecker
+}
+
+func NewAuthorizer(checker PermissionChecker) *Authorizer {
+	if checker == nil {
+		// Same reasoning as AuthMiddleware: never boot with authorization off.
+		panic("authorizer: permission checker is required")
+	}
+	return &Authorizer{checker: checker}
+}
+
+// RequirePermission rejects the request with 403 unless the caller holds
+// action on resource in their tenant.
+func (a *Authorizer) RequirePermission(resource, action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		a.check(c, nil, resource, action)
+	}
+}
+
+// RequireProjectPermission is RequirePermission scoped to the project named by
+// the given path parameter, so project membership grants count as well.
+func (a *Authorizer) RequireProjectPermission(param, resource, action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		projectID, err := uuid.Parse(c.Param(param))
+		if err != nil {
+			response.BadRequest(c, "Invalid "+param+" format")
+			return
+		}
+		a.check(c, &projectID, resource, action)
+	}
+}
+
+func (a *Authorizer) check(c *gin.Context, projectID *uuid.UUID, resource, action string) {
+	ctx := c.Request.Context()
+	userID, ok := UserUUIDFromContext(ctx)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	tenantID, ok := GetTenantUUIDFromContext(ctx)
+	if !ok {
+		response.Forbidden(c, "Tenant not resolved")
+		return
+	}
+
+	allowed, err := a.checker.HasPermission(ctx, userID, tenantID, projectID, resource, action)
+	if err != nil {
+		response.InternalServerError(c, "Failed to check permissions")
+		return
+	}
+	if !allowed {
+		response.Forbidden(c, "Missing permission "+resource+"."+action)
+		return
+	}
+	c.Next()
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware/requestctx"
+)
+
+// RequestContext copies the request id and the caller's address and user
+// agent onto the request context, so services can attribute audit events
+// without seeing the gin.Context. Mount it after requestid.New().
+func RequestContext() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := requestctx.SetRequestID(c.Request.Context(), requestid.Get(c))
+		ctx = requestctx.SetClient(ctx, requestctx.Client{
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -9,6 +9,7 @@ const (
 	LoggerKey    contextKey = "logger"
 	UserIDKey    contextKey = "user_id"
 	TenantIDKey  contextKey = "tenant_id"
+	ClientKey    contextKey = "client"
 )
 
 func SetRequestID(ctx context.Context, requestID string) context.Context {
@@ -23,3 +24,18 @@ func GetRequestID(ctx context.Context) string {
 
 	return val
 }
+
+// Client describes who sent the request, for the audit trail.
+type Client struct {
+	IP        string
+	UserAgent string
+}
+
+func SetClient(ctx context.Context, client Client) context.Context {
+	return context.WithValue(ctx, ClientKey, client)
+}
+
+func GetClient(ctx context.Context) Client {
+	val, _ := ctx.Value(ClientKey).(Client)
+	return val
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/indalyadav56/logify/apps/backend/pkg/response"
+)
+
+// TenantStatusChecker reports whether a tenant may use the data plane. It is
+// implemented by tenant/application.TenantService.
+type TenantStatusChecker interface {
+	IsTenantActive(ctx context.Context, tenantID uuid.UUID) (bool, error)
+}
+
+// RequireActiveTenant rejects requests from suspended or unknown tenants with
+// 403. It must run after AuthMiddleware.
+func RequireActiveTenant(checker TenantStatusChecker) gin.HandlerFunc {
+	if checker == nil {
+		panic("tenant status: checker is required")
+	}
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		tenantID, ok := GetTenantUUIDFromContext(ctx)
+		if !ok {
+			response.Forbidden(c, "Tenant not resolved")
+			return
+		}
+
+		active, err := checker.IsTenantActive(ctx, tenantID)
+		if err != nil {
+			response.InternalServerError(c, "Failed to check tenant status")
+			return
+		}
+		if !active {
+			response.Forbidden(c, "Tenant is suspended")
+			return
+		}
+		c.Next()
+	}
+}
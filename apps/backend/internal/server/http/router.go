@@ -7,6 +7,7 @@ import (
 	"github.com/gin-contrib/requestid"
 	"github.com/gin-gonic/gin"
 	"github.com/indalyadav56/logify/apps/backend/internal/di"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
 	"github.com/indalyadav56/logify/apps/backend/pkg/logger"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -31,6 +32,7 @@ func (r *Router) Setup(engine *gin.Engine) error {
 		MaxAge:          12 * time.Hour,
 	}))
 	engine.Use(requestid.New())
+	engine.Use(middleware.RequestContext())
 	engine.Use(logger.LoggerMiddleware(r.container.Logger))
 
 	// Health checks
@@ -0,0 +1,98 @@
+package application
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/tenant/domain"
+)
+
+type CreateTenantInput struct {
+	Name string `json:"name"           validate:"required,min=2,max=255"`
+	// Slug defaults to a slugified Name.
+	Slug string `json:"slug,omitempty" validate:"omitempty,min=3,max=64"`
+	// Plan defaults to domain.DefaultPlan.
+	Plan string `json:"plan,omitempty" validate:"omitempty,oneof=free pro enterprise"`
+}
+
+type UpdateTenantInput struct {
+	Name *string `json:"name,omitempty" validate:"omitempty,min=2,max=255"`
+	Slug *string `json:"slug,omitempty" validate:"omitempty,min=3,max=64"`
+}
+
+type UpdateStatusInput struct {
+	Status string `json:"status" validate:"required,oneof=active suspended"`
+}
+
+type UpdateSSOInput struct {
+	Enforced *bool `json:"enforced" validate:"required"`
+}
+
+type UpdateMFAInput struct {
+	Required *bool `json:"required" validate:"required"`
+}
+
+type ChangePlanInput struct {
+	Plan string `json:"plan" validate:"required,oneof=free pro enterprise"`
+}
+
+// UpdateQuotaInput overrides individual limits; omitted fields are kept.
+type UpdateQuotaInput struct {
+	DailyIngestGB    *float64 `json:"daily_ingest_gb,omitempty"    validate:"omitempty,gt=0"`
+	RetentionDays    *int     `json:"retention_days,omitempty"     validate:"omitempty,gt=0"`
+	MaxProjects      *int     `json:"max_projects,omitempty"       validate:"omitempty,gt=0"`
+	MaxMembers       *int     `json:"max_members,omitempty"        validate:"omitempty,gt=0"`
+	QueriesPerMinute *int     `json:"queries_per_minute,omitempty" validate:"omitempty,gt=0"`
+}
+
+type QuotaOutput struct {
+	DailyIngestGB    float64   `json:"daily_ingest_gb"`
+	RetentionDays    int       `json:"retention_days"`
+	MaxProjects      int       `json:"max_projects"`
+	MaxMembers       int       `json:"max_members"`
+	QueriesPerMinute int       `json:"queries_per_minute"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+type TenantOutput struct {
+	ID          uuid.UUID    `json:"id"`
+	Name        string       `json:"name"`
+	Slug        string       `json:"slug"`
+	Plan        string       `json:"plan"`
+	Status      string       `json:"status"`
+	SSOEnforced bool         `json:"sso_enforced"`
+	MFARequired bool         `json:"mfa_required"`
+	Quota       *QuotaOutput `json:"quota,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+func toTenantOutput(t *domain.Tenant, q *domain.Quota) *TenantOutput {
+	out := &TenantOutput{
+		ID:          t.ID,
+		Name:        t.Name,
+		Slug:        t.Slug,
+		Plan:        string(t.Plan),
+		Status:      string(t.Status),
+		SSOEnforced: t.SSOEnforced,
+		MFARequired: t.MFARequired,
+		CreatedAt:   t.CreatedAt,
+		UpdatedAt:   t.UpdatedAt,
+	}
+	if q != nil {
+		out.Quota = toQuotaOutput(q)
+	}
+	return out
+}
+
+func toQuotaOutput(q *domain.Quota) *QuotaOutput {
+	return &QuotaOutput{
+		DailyIngestGB:    q.DailyIngestGB,
+		RetentionDays:    q.RetentionDays,
+		MaxProjects:      q.MaxProjects,
+		MaxMembers:       q.MaxMembers,
+		QueriesPerMinute: q.QueriesPerMinute,
+		UpdatedAt:        q.UpdatedAt,
+	}
+}
@@ -1,6 +1,456 @@
 package application
 
-// TenantService handles tenant business logic.
-type TenantService struct {
-	// TODO: Add dependencies
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	auditDomain "github.com/indalyadav56/logify/apps/backend/internal/audit/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
+	"github.com/indalyadav56/logify/apps/backend/internal/tenant/domain"
+	"github.com/indalyadav56/logify/apps/backend/pkg/ttlcache"
+)
+
+// defaultStatusTTL bounds how long a suspension or quota change can take to
+// reach other server instances; the instance that makes it sees it at once.
+const defaultStatusTTL = 30 * time.Second
+
+// TenantService handles tenant business logic. Every method except
+// CurrentTenant, EnsurePersonalTenant and IsTenantActive is an admin
+// operation; the routes guard them with the platform tenant.* permissions.
+type TenantService interface {
+	CreateTenant(ctx context.Context, input CreateTenantInput) (*TenantOutput, error)
+	GetTenant(ctx context.Context, id uuid.UUID) (*TenantOutput, error)
+	ListTenants(ctx context.Context, filter domain.ListFilter) ([]*TenantOutput, int64, error)
+	UpdateTenant(ctx context.Context, id uuid.UUID, input UpdateTenantInput) (*TenantOutput, error)
+	SetStatus(ctx context.Context, id uuid.UUID, status domain.Status) (*TenantOutput, error)
+	// SetSSOEnforced turns password login off (or back on) for the tenant's
+	// members. It takes effect at their next login.
+	SetSSOEnforced(ctx context.Context, id uuid.UUID, enforced bool) (*TenantOutput, error)
+	// SetMFARequired makes (or stops making) a second factor mandatory for
+	// the tenant's members. It takes effect at their next password login.
+	SetMFARequired(ctx context.Context, id uuid.UUID, required bool) (*TenantOutput, error)
+	// ChangePlan switches the plan and resets the quota to the plan defaults.
+	ChangePlan(ctx context.Context, id uuid.UUID, plan domain.Plan) (*TenantOutput, error)
+	UpdateQuota(ctx context.Context, id uuid.UUID, input UpdateQuotaInput) (*QuotaOutput, error)
+	DeleteTenant(ctx context.Context, id uuid.UUID) error
+
+	// CurrentTenant returns the caller's own tenant with its quota.
+	CurrentTenant(ctx context.Context) (*TenantOutput, error)
+	// GetQuota returns the limits in force for a tenant. Like IsTenantActive
+	// it is cached briefly for the ingest path.
+	GetQuota(ctx context.Context, tenantID uuid.UUID) (*domain.Quota, error)
+	// EnsurePersonalTenant provisions the tenant a user owns (id = user id) if
+	// it does not exist yet. It is idempotent.
+	EnsurePersonalTenant(ctx context.Context, userID uuid.UUID, name string) error
+	// IsTenantActive reports whether the tenant exists and is not suspended.
+	// Results are cached briefly since it sits on the ingest and search path.
+	IsTenantActive(ctx context.Context, tenantID uuid.UUID) (bool, error)
+}
+
+type tenantService struct {
+	repo   domain.Repository
+	audit  auditDomain.Recorder
+	logger *zap.Logger
+
+	status *ttlcache.Cache[uuid.UUID, bool]
+	quotas *ttlcache.Cache[uuid.UUID, domain.Quota]
+	now    func() time.Time
+}
+
+func NewTenantService(repo domain.Repository, audit auditDomain.Recorder, logger *zap.Logger) TenantService {
+	if audit == nil {
+		audit = auditDomain.Discard
+	}
+	return &tenantService{
+		repo:   repo,
+		audit:  audit,
+		logger: logger.Named("tenant_service"),
+		status: ttlcache.New[uuid.UUID, bool](defaultStatusTTL, 0),
+		quotas: ttlcache.New[uuid.UUID, domain.Quota](defaultStatusTTL, 0),
+		now:    time.Now,
+	}
+}
+
+func (s *tenantService) CreateTenant(ctx context.Context, input CreateTenantInput) (*TenantOutput, error) {
+	plan := domain.DefaultPlan
+	if input.Plan != "" {
+		plan = domain.Plan(input.Plan)
+	}
+	if !plan.IsValid() {
+		return nil, domain.ErrInvalidPlan
+	}
+
+	slug := strings.TrimSpace(input.Slug)
+	if slug == "" {
+		slug = domain.Slugify(input.Name)
+	}
+	if !domain.IsValidSlug(slug) {
+		return nil, domain.ErrInvalidSlug
+	}
+
+	tenant := &domain.Tenant{
+		Name:   strings.TrimSpace(input.Name),
+		Slug:   slug,
+		Plan:   plan,
+		Status: domain.StatusActive,
+	}
+	quota := domain.DefaultQuota(uuid.Nil, plan)
+	if err := s.repo.Create(ctx, tenant, quota); err != nil {
+		if !errors.Is(err, domain.ErrTenantAlreadyExists) {
+			s.logger.Error("failed to create tenant", zap.String("slug", slug), zap.Error(err))
+		}
+		return nil, err
+	}
+
+	s.logger.Info("tenant created",
+		zap.String("tenant_id", tenant.ID.String()),
+		zap.String("slug", tenant.Slug),
+		zap.String("plan", string(tenant.Plan)),
+	)
+	quota.TenantID = tenant.ID
+	quota.UpdatedAt = tenant.CreatedAt
+	s.record(ctx, auditDomain.ActionTenantCreated, tenant.ID, nil, tenantState(tenant))
+	return toTenantOutput(tenant, &quota), nil
+}
+
+func (s *tenantService) GetTenant(ctx context.Context, id uuid.UUID) (*TenantOutput, error) {
+	tenant, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	quota, err := s.repo.GetQuota(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return toTenantOutput(tenant, quota), nil
+}
+
+func (s *tenantService) ListTenants(ctx context.Context, filter domain.ListFilter) ([]*TenantOutput, int64, error) {
+	if filter.Status != "" && !filter.Status.IsValid() {
+		return nil, 0, domain.ErrInvalidStatus
+	}
+	if filter.Plan != "" && !filter.Plan.IsValid() {
+		return nil, 0, domain.ErrInvalidPlan
+	}
+
+	items, total, err := s.repo.List(ctx, filter)
+	if err != nil {
+		s.logger.Error("failed to list tenants", zap.Error(err))
+		return nil, 0, err
+	}
+	out := make([]*TenantOutput, len(items))
+	for i, t := range items {
+		out[i] = toTenantOutput(t, nil)
+	}
+	return out, total, nil
+}
+
+func (s *tenantService) UpdateTenant(ctx context.Context, id uuid.UUID, input UpdateTenantInput) (*TenantOutput, error) {
+	tenant, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	before := tenantState(tenant)
+	if input.Name != nil {
+		tenant.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.Slug != nil {
+		slug := strings.TrimSpace(*input.Slug)
+		if !domain.IsValidSlug(slug) {
+			return nil, domain.ErrInvalidSlug
+		}
+		tenant.Slug = slug
+	}
+
+	if err := s.repo.Update(ctx, tenant); err != nil {
+		if !errors.Is(err, domain.ErrTenantAlreadyExists) && !errors.Is(err, domain.ErrTenantNotFound) {
+			s.logger.Error("failed to update tenant", zap.String("tenant_id", id.String()), zap.Error(err))
+		}
+		return nil, err
+	}
+	s.record(ctx, auditDomain.ActionTenantUpdated, id, before, tenantState(tenant))
+	return toTenantOutput(tenant, nil), nil
+}
+
+func (s *tenantService) SetStatus(ctx context.Context, id uuid.UUID, status domain.Status) (*TenantOutput, error) {
+	if !status.IsValid() {
+		return nil, domain.ErrInvalidStatus
+	}
+	tenant, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if tenant.Status == status {
+		return toTenantOutput(tenant, nil), nil
+	}
+
+	before := tenantState(tenant)
+	tenant.Status = status
+	if err := s.repo.Update(ctx, tenant); err != nil {
+		s.logger.Error("failed to change tenant status", zap.String("tenant_id", id.String()), zap.Error(err))
+		return nil, err
+	}
+	s.forget(id)
+
+	s.logger.Info("tenant status changed",
+		zap.String("tenant_id", id.String()),
+		zap.String("status", string(status)),
+	)
+	s.record(ctx, auditDomain.ActionTenantStatusChanged, id, before, tenantState(tenant))
+	return toTenantOutput(tenant, nil), nil
+}
+
+func (s *tenantService) SetSSOEnforced(ctx context.Context, id uuid.UUID, enforced bool) (*TenantOutput, error) {
+	tenant, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if tenant.SSOEnforced == enforced {
+		return toTenantOutput(tenant, nil), nil
+	}
+
+	before := tenantState(tenant)
+	tenant.SSOEnforced = enforced
+	if err := s.repo.Update(ctx, tenant); err != nil {
+		s.logger.Error("failed to change tenant sso enforcement", zap.String("tenant_id", id.String()), zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.Info("tenant sso enforcement changed",
+		zap.String("tenant_id", id.String()),
+		zap.Bool("enforced", enforced),
+	)
+	s.record(ctx, auditDomain.ActionTenantUpdated, id, before, tenantState(tenant))
+	return toTenantOutput(tenant, nil), nil
+}
+
+func (s *tenantService) SetMFARequired(ctx context.Context, id uuid.UUID, required bool) (*TenantOutput, error) {
+	tenant, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if tenant.MFARequired == required {
+		return toTenantOutput(tenant, nil), nil
+	}
+
+	before := tenantState(tenant)
+	tenant.MFARequired = required
+	if err := s.repo.Update(ctx, tenant); err != nil {
+		s.logger.Error("failed to change tenant mfa requirement", zap.String("tenant_id", id.String()), zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.Info("tenant mfa requirement changed",
+		zap.String("tenant_id", id.String()),
+		zap.Bool("required", required),
+	)
+	s.record(ctx, auditDomain.ActionTenantUpdated, id, before, tenantState(tenant))
+	return toTenantOutput(tenant, nil), nil
+}
+
+func (s *tenantService) ChangePlan(ctx context.Context, id uuid.UUID, plan domain.Plan) (*TenantOutput, error) {
+	if !plan.IsValid() {
+		return nil, domain.ErrInvalidPlan
+	}
+	tenant, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	old, err := s.repo.GetQuota(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	quota := domain.DefaultQuota(id, plan)
+	if err := s.repo.ChangePlan(ctx, id, plan, quota); err != nil {
+		if !errors.Is(err, domain.ErrTenantNotFound) {
+			s.logger.Error("failed to change tenant plan", zap.String("tenant_id", id.String()), zap.Error(err))
+		}
+		return nil, err
+	}
+	s.forget(id)
+	s.logger.Info("tenant plan changed", zap.String("tenant_id", id.String()), zap.String("plan", string(plan)))
+	s.record(ctx, auditDomain.ActionTenantPlanChanged, id, planState(tenant.Plan, old), planState(plan, &quota))
+	return s.GetTenant(ctx, id)
+}
+
+func (s *tenantService) UpdateQuota(ctx context.Context, id uuid.UUID, input UpdateQuotaInput) (*QuotaOutput, error) {
+	quota, err := s.repo.GetQuota(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	before := quotaState(quota)
+	if input.DailyIngestGB != nil {
+		quota.DailyIngestGB = *input.DailyIngestGB
+	}
+	if input.RetentionDays != nil {
+		quota.RetentionDays = *input.RetentionDays
+	}
+	if input.MaxProjects != nil {
+		quota.MaxProjects = *input.MaxProjects
+	}
+	if input.MaxMembers != nil {
+		quota.MaxMembers = *input.MaxMembers
+	}
+	if input.QueriesPerMinute != nil {
+		quota.QueriesPerMinute = *input.QueriesPerMinute
+	}
+	if err := quota.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateQuota(ctx, quota); err != nil {
+		if !errors.Is(err, domain.ErrTenantNotFound) {
+			s.logger.Error("failed to update tenant quota", zap.String("tenant_id", id.String()), zap.Error(err))
+		}
+		return nil, err
+	}
+	s.forget(id)
+	s.logger.Info("tenant quota updated", zap.String("tenant_id", id.String()))
+	s.record(ctx, auditDomain.ActionTenantQuotaChanged, id, before, quotaState(quota))
+	return toQuotaOutput(quota), nil
+}
+
+func (s *tenantService) DeleteTenant(ctx context.Context, id uuid.UUID) error {
+	tenant, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		if !errors.Is(err, domain.ErrTenantNotFound) {
+			s.logger.Error("failed to delete tenant", zap.String("tenant_id", id.String()), zap.Error(err))
+		}
+		return err
+	}
+	s.forget(id)
+	s.logger.Info("tenant deleted", zap.String("tenant_id", id.String()))
+	s.record(ctx, auditDomain.ActionTenantDeleted, id, tenantState(tenant), nil)
+	return nil
+}
+
+func (s *tenantService) CurrentTenant(ctx context.Context) (*TenantOutput, error) {
+	tenantID, ok := middleware.GetTenantUUIDFromContext(ctx)
+	if !ok {
+		return nil, domain.ErrTenantNotFound
+	}
+	return s.GetTenant(ctx, tenantID)
+}
+
+func (s *tenantService) GetQuota(ctx context.Context, tenantID uuid.UUID) (*domain.Quota, error) {
+	now := s.now()
+	if q, ok := s.quotas.Get(tenantID, now); ok {
+		return &q, nil
+	}
+
+	quota, err := s.repo.GetQuota(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	s.quotas.Set(tenantID, *quota, now)
+	return quota, nil
+}
+
+func (s *tenantService) EnsurePersonalTenant(ctx context.Context, userID uuid.UUID, name string) error {
+	_, err := s.repo.GetByID(ctx, userID)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, domain.ErrTenantNotFound) {
+		return err
+	}
+
+	tenant := &domain.Tenant{
+		ID:     userID,
+		Name:   strings.TrimSpace(name),
+		Slug:   domain.PersonalSlug(userID),
+		Plan:   domain.DefaultPlan,
+		Status: domain.StatusActive,
+	}
+	if tenant.Name == "" {
+		tenant.Name = tenant.Slug
+	}
+	err = s.repo.Create(ctx, tenant, domain.DefaultQuota(userID, domain.DefaultPlan))
+	if errors.Is(err, domain.ErrTenantAlreadyExists) {
+		// Lost a race with a concurrent login, or the tenant was soft-deleted;
+		// either way there is nothing to provision.
+		return nil
+	}
+	if err != nil {
+		s.logger.Error("failed to provision personal tenant", zap.String("user_id", userID.String()), zap.Error(err))
+		return err
+	}
+	s.logger.Info("personal tenant provisioned", zap.String("tenant_id", userID.String()))
+	return nil
+}
+
+func (s *tenantService) IsTenantActive(ctx context.Context, tenantID uuid.UUID) (bool, error) {
+	now := s.now()
+	if active, ok := s.status.Get(tenantID, now); ok {
+		return active, nil
+	}
+
+	active := false
+	tenant, err := s.repo.GetByID(ctx, tenantID)
+	switch {
+	case err == nil:
+		active = tenant.IsActive()
+	case errors.Is(err, domain.ErrTenantNotFound):
+	default:
+		return false, err
+	}
+
+	s.status.Set(tenantID, active, now)
+	return active, nil
+}
+
+func (s *tenantService) forget(id uuid.UUID) {
+	s.status.Delete(id)
+	s.quotas.Delete(id)
+}
+
+// record keeps an admin change to a tenant in that tenant's audit trail, so
+// its own admins see a suspension or quota change made by the platform.
+func (s *tenantService) record(ctx context.Context, action string, id uuid.UUID, before, after map[string]any) {
+	s.audit.Record(ctx, auditDomain.Event{
+		Action:     action,
+		TenantID:   id,
+		TargetType: "tenant",
+		TargetID:   id.String(),
+		Before:     before,
+		After:      after,
+	})
+}
+
+// tenantState is what an audit record shows of a tenant.
+func tenantState(t *domain.Tenant) map[string]any {
+	return map[string]any{
+		"name":         t.Name,
+		"slug":         t.Slug,
+		"plan":         string(t.Plan),
+		"status":       string(t.Status),
+		"sso_enforced": t.SSOEnforced,
+		"mfa_required": t.MFARequired,
+	}
+}
+
+// quotaState is what an audit record shows of a tenant's limits.
+func quotaState(q *domain.Quota) map[string]any {
+	return map[string]any{
+		"daily_ingest_gb":    q.DailyIngestGB,
+		"retention_days":     q.RetentionDays,
+		"max_projects":       q.MaxProjects,
+		"max_members":        q.MaxMembers,
+		"queries_per_minute": q.QueriesPerMinute,
+	}
+}
+
+// planState is quotaState with the plan the limits come from.
+func planState(plan domain.Plan, q *domain.Quota) map[string]any {
+	state := quotaState(q)
+	state["plan"] = string(plan)
+	return state
 }
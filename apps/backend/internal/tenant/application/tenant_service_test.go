@@ -0,0 +1,213 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	auditDomain "github.com/indalyadav56/logify/apps/backend/internal/audit/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/tenant/domain"
+)
+
+type memTenantRepo struct {
+	tenants map[uuid.UUID]domain.Tenant
+	quotas  map[uuid.UUID]domain.Quota
+	gets    int
+}
+
+func newMemTenantRepo() *memTenantRepo {
+	return &memTenantRepo{tenants: map[uuid.UUID]domain.Tenant{}, quotas: map[uuid.UUID]domain.Quota{}}
+}
+
+func (r *memTenantRepo) Create(_ context.Context, t *domain.Tenant, q domain.Quota) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	if _, ok := r.tenants[t.ID]; ok {
+		return domain.ErrTenantAlreadyExists
+	}
+	for _, other := range r.tenants {
+		if other.Slug == t.Slug {
+			return domain.ErrTenantAlreadyExists
+		}
+	}
+	r.tenants[t.ID] = *t
+	q.TenantID = t.ID
+	r.quotas[t.ID] = q
+	return nil
+}
+
+func (r *memTenantRepo) GetByID(_ context.Context, id uuid.UUID) (*domain.Tenant, error) {
+	r.gets++
+	t, ok := r.tenants[id]
+	if !ok {
+		return nil, domain.ErrTenantNotFound
+	}
+	return &t, nil
+}
+
+func (r *memTenantRepo) List(context.Context, domain.ListFilter) ([]*domain.Tenant, int64, error) {
+	out := make([]*domain.Tenant, 0, len(r.tenants))
+	for _, t := range r.tenants {
+		out = append(out, &t)
+	}
+	return out, int64(len(out)), nil
+}
+
+func (r *memTenantRepo) Update(_ context.Context, t *domain.Tenant) error {
+	if _, ok := r.tenants[t.ID]; !ok {
+		return domain.ErrTenantNotFound
+	}
+	r.tenants[t.ID] = *t
+	return nil
+}
+
+func (r *memTenantRepo) ChangePlan(_ context.Context, id uuid.UUID, plan domain.Plan, q domain.Quota) error {
+	t, ok := r.tenants[id]
+	if !ok {
+		return domain.ErrTenantNotFound
+	}
+	t.Plan = plan
+	r.tenants[id] = t
+	r.quotas[id] = q
+	return nil
+}
+
+func (r *memTenantRepo) Delete(_ context.Context, id uuid.UUID) error {
+	if _, ok := r.tenants[id]; !ok {
+		return domain.ErrTenantNotFound
+	}
+	delete(r.tenants, id)
+	return nil
+}
+
+func (r *memTenantRepo) GetQuota(_ context.Context, id uuid.UUID) (*domain.Quota, error) {
+	q, ok := r.quotas[id]
+	if !ok {
+		return nil, domain.ErrTenantNotFound
+	}
+	return &q, nil
+}
+
+func (r *memTenantRepo) UpdateQuota(_ context.Context, q *domain.Quota) error {
+	if _, ok := r.tenants[q.TenantID]; !ok {
+		return domain.ErrTenantNotFound
+	}
+	r.quotas[q.TenantID] = *q
+	return nil
+}
+
+func TestTenantPlanAndQuota(t *testing.T) {
+	repo := newMemTenantRepo()
+	svc := NewTenantService(repo, nil, zap.NewNop())
+	ctx := context.Background()
+
+	created, err := svc.CreateTenant(ctx, CreateTenantInput{Name: "Acme Corp"})
+	require.NoError(t, err)
+	assert.Equal(t, "acme-corp", created.Slug)
+	assert.Equal(t, string(domain.PlanFree), created.Plan)
+	assert.Equal(t, string(domain.StatusActive), created.Status)
+	assert.Equal(t, 3, created.Quota.MaxProjects)
+
+	_, err = svc.CreateTenant(ctx, CreateTenantInput{Name: "Acme, Corp."})
+	assert.ErrorIs(t, err, domain.ErrTenantAlreadyExists)
+
+	retention := 90
+	quota, err := svc.UpdateQuota(ctx, created.ID, UpdateQuotaInput{RetentionDays: &retention})
+	require.NoError(t, err)
+	assert.Equal(t, 90, quota.RetentionDays)
+	assert.Equal(t, 60, quota.QueriesPerMinute, "omitted limits are kept")
+
+	zero := 0
+	_, err = svc.UpdateQuota(ctx, created.ID, UpdateQuotaInput{MaxMembers: &zero})
+	assert.ErrorIs(t, err, domain.ErrInvalidQuota)
+
+	upgraded, err := svc.ChangePlan(ctx, created.ID, domain.PlanPro)
+	require.NoError(t, err)
+	assert.Equal(t, string(domain.PlanPro), upgraded.Plan)
+	assert.Equal(t, 30, upgraded.Quota.RetentionDays, "plan change resets overrides to plan defaults")
+
+	_, err = svc.ChangePlan(ctx, created.ID, "platinum")
+	assert.ErrorIs(t, err, domain.ErrInvalidPlan)
+}
+
+func TestTenantSuspensionIsSeenImmediately(t *testing.T) {
+	repo := newMemTenantRepo()
+	svc := NewTenantService(repo, nil, zap.NewNop()).(*tenantService)
+	now := time.Now()
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	userID := uuid.New()
+	require.NoError(t, svc.EnsurePersonalTenant(ctx, userID, "Ada"))
+	require.NoError(t, svc.EnsurePersonalTenant(ctx, userID, "Ada"), "provisioning is idempotent")
+	assert.Equal(t, domain.PersonalSlug(userID), repo.tenants[userID].Slug)
+
+	active, err := svc.IsTenantActive(ctx, userID)
+	require.NoError(t, err)
+	assert.True(t, active)
+	gets := repo.gets
+	_, _ = svc.IsTenantActive(ctx, userID)
+	assert.Equal(t, gets, repo.gets, "status is served from cache")
+
+	_, err = svc.SetStatus(ctx, userID, domain.StatusSuspended)
+	require.NoError(t, err)
+	active, err = svc.IsTenantActive(ctx, userID)
+	require.NoError(t, err)
+	assert.False(t, active, "suspension must bypass the cache")
+
+	active, err = svc.IsTenantActive(ctx, uuid.New())
+	require.NoError(t, err)
+	assert.False(t, active, "unknown tenants are not active")
+}
+
+type memRecorder struct {
+	events []auditDomain.Event
+}
+
+func (r *memRecorder) Record(_ context.Context, e auditDomain.Event) {
+	r.events = append(r.events, e)
+}
+
+func TestTenantAdminChangesAreAudited(t *testing.T) {
+	repo := newMemTenantRepo()
+	audit := &memRecorder{}
+	svc := NewTenantService(repo, audit, zap.NewNop())
+	ctx := context.Background()
+
+	created, err := svc.CreateTenant(ctx, CreateTenantInput{Name: "Acme"})
+	require.NoError(t, err)
+	_, err = svc.SetStatus(ctx, created.ID, domain.StatusSuspended)
+	require.NoError(t, err)
+	_, err = svc.SetStatus(ctx, created.ID, domain.StatusSuspended)
+	require.NoError(t, err)
+	projects := 10
+	_, err = svc.UpdateQuota(ctx, created.ID, UpdateQuotaInput{MaxProjects: &projects})
+	require.NoError(t, err)
+	_, err = svc.ChangePlan(ctx, created.ID, domain.PlanPro)
+	require.NoError(t, err)
+
+	require.Len(t, audit.events, 4, "an unchanged status is not recorded")
+	for _, e := range audit.events {
+		assert.Equal(t, created.ID, e.TenantID, "changes land in the tenant's own trail")
+		assert.Equal(t, created.ID.String(), e.TargetID)
+	}
+
+	first, suspended, quota, plan := audit.events[0], audit.events[1], audit.events[2], audit.events[3]
+	assert.Equal(t, auditDomain.ActionTenantCreated, first.Action)
+	assert.Equal(t, auditDomain.ActionTenantStatusChanged, suspended.Action)
+	assert.Equal(t, "active", suspended.Before["status"])
+	assert.Equal(t, "suspended", suspended.After["status"])
+	assert.Equal(t, auditDomain.ActionTenantQuotaChanged, quota.Action)
+	assert.Equal(t, 3, quota.Before["max_projects"])
+	assert.Equal(t, 10, quota.After["max_projects"])
+	assert.Equal(t, auditDomain.ActionTenantPlanChanged, plan.Action)
+	assert.Equal(t, "free", plan.Before["plan"])
+	assert.Equal(t, 10, plan.Before["max_projects"])
+	assert.Equal(t, "pro", plan.After["plan"])
+}
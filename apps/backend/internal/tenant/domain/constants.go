@@ -1,6 +1,21 @@
 package domain
 
+// Plan is the commercial plan a tenant is on. It decides the default quota.
+type Plan string
+
+const (
+	PlanFree       Plan = "free"
+	PlanPro        Plan = "pro"
+	PlanEnterprise Plan = "enterprise"
+)
+
 // Constants related to tenants
 const (
-	DefaultPlan = "free"
+	DefaultPlan = PlanFree
 )
+
+// IsValid reports whether p is a known plan.
+func (p Plan) IsValid() bool {
+	_, ok := planQuotas[p]
+	return ok
+}
@@ -4,7 +4,13 @@ import "errors"
 
 // Common tenant errors
 var (
-	ErrTenantNotFound = errors.New("tenant not found")
-	ErrInvalidAPIKey  = errors.New("invalid api key")
-	ErrQuotaExceeded  = errors.New("quota exceeded")
+	ErrTenantNotFound      = errors.New("tenant not found")
+	ErrTenantAlreadyExists = errors.New("tenant with this slug already exists")
+	ErrTenantSuspended     = errors.New("tenant is suspended")
+	ErrInvalidAPIKey       = errors.New("invalid api key")
+	ErrQuotaExceeded       = errors.New("quota exceeded")
+	ErrInvalidPlan         = errors.New("invalid plan")
+	ErrInvalidStatus       = errors.New("invalid tenant status")
+	ErrInvalidSlug         = errors.New("invalid tenant slug")
+	ErrInvalidQuota        = errors.New("quota limits must be positive")
 )
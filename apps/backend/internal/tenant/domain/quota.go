@@ -1,6 +1,63 @@
 package domain
 
-// Quota represents a tenant's resource quota.
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Quota represents a tenant's resource quota. Every limit is a positive
+// number; plans set the defaults and admins may override them per tenant.
 type Quota struct {
-	// TODO: Add fields
+	TenantID         uuid.UUID `json:"tenant_id"`
+	DailyIngestGB    float64   `json:"daily_ingest_gb"`
+	RetentionDays    int       `json:"retention_days"`
+	MaxProjects      int       `json:"max_projects"`
+	MaxMembers       int       `json:"max_members"`
+	QueriesPerMinute int       `json:"queries_per_minute"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// Validate returns ErrInvalidQuota unless every limit is positive.
+func (q Quota) Validate() error {
+	if q.DailyIngestGB <= 0 || q.RetentionDays <= 0 || q.MaxProjects <= 0 ||
+		q.MaxMembers <= 0 || q.QueriesPerMinute <= 0 {
+		return ErrInvalidQuota
+	}
+	return nil
+}
+
+var planQuotas = map[Plan]Quota{
+	PlanFree: {
+		DailyIngestGB:    1,
+		RetentionDays:    7,
+		MaxProjects:      3,
+		MaxMembers:       5,
+		QueriesPerMinute: 60,
+	},
+	PlanPro: {
+		DailyIngestGB:    50,
+		RetentionDays:    30,
+		MaxProjects:      25,
+		MaxMembers:       50,
+		QueriesPerMinute: 600,
+	},
+	PlanEnterprise: {
+		DailyIngestGB:    1000,
+		RetentionDays:    365,
+		MaxProjects:      500,
+		MaxMembers:       1000,
+		QueriesPerMinute: 6000,
+	},
+}
+
+// DefaultQuota returns the quota a tenant on plan starts with. Unknown plans
+// fall back to DefaultPlan.
+func DefaultQuota(tenantID uuid.UUID, plan Plan) Quota {
+	q, ok := planQuotas[plan]
+	if !ok {
+		q = planQuotas[DefaultPlan]
+	}
+	q.TenantID = tenantID
+	return q
 }
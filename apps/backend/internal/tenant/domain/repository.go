@@ -1,6 +1,40 @@
 package domain
 
-// Repository defines the interface for tenant data operations.
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// ListFilter holds the filtering / pagination options for tenant listing.
+type ListFilter struct {
+	Status Status // empty = any
+	Plan   Plan   // empty = any
+	Search string // case-insensitive substring on name or slug
+
+	Page    int
+	PerPage int
+}
+
+// DefaultListFilter returns a ListFilter with sensible defaults.
+func DefaultListFilter() ListFilter {
+	return ListFilter{Page: 1, PerPage: 20}
+}
+
+// Repository defines the interface for tenant data operations. Deleted
+// tenants behave exactly like missing ones (ErrTenantNotFound).
 type Repository interface {
-	// TODO: Add methods
+	// Create inserts the tenant together with its quota. A preset tenant.ID
+	// is kept; otherwise one is generated.
+	Create(ctx context.Context, tenant *Tenant, quota Quota) error
+	GetByID(ctx context.Context, id uuid.UUID) (*Tenant, error)
+	List(ctx context.Context, filter ListFilter) ([]*Tenant, int64, error)
+	// Update writes name, slug, status and SSO enforcement.
+	Update(ctx context.Context, tenant *Tenant) error
+	// ChangePlan switches the plan and replaces the quota in one transaction.
+	ChangePlan(ctx context.Context, id uuid.UUID, plan Plan, quota Quota) error
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	GetQuota(ctx context.Context, tenantID uuid.UUID) (*Quota, error)
+	UpdateQuota(ctx context.Context, quota *Quota) error
 }
@@ -1,6 +1,70 @@
 package domain
 
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status is the lifecycle state of a tenant. A suspended tenant keeps its
+// data but is refused at ingest and search.
+type Status string
+
+const (
+	StatusActive    Status = "active"
+	StatusSuspended Status = "suspended"
+)
+
+// IsValid reports whether s is a known status.
+func (s Status) IsValid() bool {
+	return s == StatusActive || s == StatusSuspended
+}
+
 // Tenant represents a single tenant in the system.
 type Tenant struct {
-	// TODO: Add fields
+	ID     uuid.UUID `json:"id"`
+	Name   string    `json:"name"`
+	Slug   string    `json:"slug"`
+	Plan   Plan      `json:"plan"`
+	Status Status    `json:"status"`
+	// SSOEnforced disables password login for every member of the tenant;
+	// they have to sign in through the identity provider.
+	SSOEnforced bool `json:"sso_enforced"`
+	// MFARequired makes members of the tenant enrol a second factor before
+	// they can finish a password login.
+	MFARequired bool      `json:"mfa_required"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// IsActive reports whether the tenant may ingest and query logs.
+func (t *Tenant) IsActive() bool {
+	return t.Status == StatusActive
+}
+
+var (
+	slugInvalid = regexp.MustCompile(`[^a-z0-9]+`)
+	slugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,62}[a-z0-9]$`)
+)
+
+// Slugify derives a URL-safe slug from a tenant name.
+func Slugify(name string) string {
+	s := slugInvalid.ReplaceAllString(strings.ToLower(strings.TrimSpace(name)), "-")
+	s = strings.Trim(s, "-")
+	if len(s) > 64 {
+		s = strings.TrimRight(s[:64], "-")
+	}
+	return s
+}
+
+// IsValidSlug reports whether s can be used as a tenant slug.
+func IsValidSlug(s string) bool {
+	return slugPattern.MatchString(s)
+}
+
+// PersonalSlug is the slug of the personal tenant provisioned for a user.
+func PersonalSlug(userID uuid.UUID) string {
+	return "u-" + strings.ReplaceAll(userID.String(), "-", "")
 }
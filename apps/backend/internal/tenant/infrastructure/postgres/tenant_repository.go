@@ -1,6 +1,291 @@
 package postgres
 
-// TenantRepository implements the tenant repository using PostgreSQL.
-type TenantRepository struct {
-	// TODO: Add dependencies
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/tenant/domain"
+)
+
+const pgUniqueViolation = "23505"
+
+const tenantColumns = `id, name, slug, plan, status, sso_enforced, mfa_required, created_at, updated_at`
+
+// tenantRepository is a PostgreSQL-backed implementation of domain.Repository.
+type tenantRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewTenantRepository constructs a tenantRepository backed by the given pool.
+func NewTenantRepository(db *pgxpool.Pool) domain.Repository {
+	return &tenantRepository{db: db}
+}
+
+func (r *tenantRepository) Create(ctx context.Context, t *domain.Tenant, q domain.Quota) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return withTx(ctx, r.db, func(tx pgx.Tx) error {
+		const insertTenant = `
+			INSERT INTO tenants (id, name, slug, plan, status)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING created_at, updated_at
+		`
+		err := tx.QueryRow(ctx, insertTenant, t.ID, t.Name, t.Slug, t.Plan, t.Status).
+			Scan(&t.CreatedAt, &t.UpdatedAt)
+		if err != nil {
+			return mapUniqueViolation(err)
+		}
+		q.TenantID = t.ID
+		return upsertQuota(ctx, tx, &q)
+	})
+}
+
+func (r *tenantRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Tenant, error) {
+	query := `SELECT ` + tenantColumns + ` FROM tenants WHERE id = $1 AND deleted_at IS NULL`
+	t, err := scanTenant(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrTenantNotFound
+		}
+		return nil, err
+	}
+	return t, nil
+}
+
+func (r *tenantRepository) List(ctx context.Context, f domain.ListFilter) ([]*domain.Tenant, int64, error) {
+	page, perPage := f.Page, f.PerPage
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 || perPage > 100 {
+		perPage = 20
+	}
+
+	conds := []string{"deleted_at IS NULL"}
+	var args []any
+	add := func(cond string, val any) {
+		args = append(args, val)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if f.Status != "" {
+		add("status = $%d", f.Status)
+	}
+	if f.Plan != "" {
+		add("plan = $%d", f.Plan)
+	}
+	if s := strings.TrimSpace(f.Search); s != "" {
+		add("(LOWER(name) LIKE '%%' || LOWER($%[1]d) || '%%' OR slug LIKE '%%' || LOWER($%[1]d) || '%%')", s)
+	}
+	where := "WHERE " + strings.Join(conds, " AND ")
+
+	var total int64
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM tenants `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return []*domain.Tenant{}, 0, nil
+	}
+
+	args = append(args, perPage, (page-1)*perPage)
+	query := fmt.Sprintf(`
+		SELECT %s FROM tenants %s
+		ORDER BY created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, tenantColumns, where, len(args)-1, len(args))
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	out := make([]*domain.Tenant, 0, perPage)
+	for rows.Next() {
+		t, err := scanTenant(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		out = append(out, t)
+	}
+	return out, total, rows.Err()
+}
+
+func (r *tenantRepository) Update(ctx context.Context, t *domain.Tenant) error {
+	const query = `
+		UPDATE tenants
+		SET name = $2,
+		    slug = $3,
+		    status = $4,
+		    sso_enforced = $5,
+		    mfa_required = $6,
+		    updated_at = (now() AT TIME ZONE 'utc')
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING updated_at
+	`
+	err := r.db.QueryRow(ctx, query, t.ID, t.Name, t.Slug, t.Status, t.SSOEnforced, t.MFARequired).Scan(&t.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrTenantNotFound
+		}
+		return mapUniqueViolation(err)
+	}
+	return nil
+}
+
+func (r *tenantRepository) ChangePlan(ctx context.Context, id uuid.UUID, plan domain.Plan, q domain.Quota) error {
+	return withTx(ctx, r.db, func(tx pgx.Tx) error {
+		const query = `
+			UPDATE tenants
+			SET plan = $2, updated_at = (now() AT TIME ZONE 'utc')
+			WHERE id = $1 AND deleted_at IS NULL
+		`
+		tag, err := tx.Exec(ctx, query, id, plan)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return domain.ErrTenantNotFound
+		}
+		q.TenantID = id
+		return upsertQuota(ctx, tx, &q)
+	})
+}
+
+// Delete soft-deletes the tenant; its projects and logs are left for the
+// retention jobs to reclaim.
+func (r *tenantRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	const query = `
+		UPDATE tenants
+		SET deleted_at = (now() AT TIME ZONE 'utc'), updated_at = (now() AT TIME ZONE 'utc')
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+	tag, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrTenantNotFound
+	}
+	return nil
+}
+
+func (r *tenantRepository) GetQuota(ctx context.Context, tenantID uuid.UUID) (*domain.Quota, error) {
+	const query = `
+		SELECT q.tenant_id, q.daily_ingest_gb, q.retention_days, q.max_projects,
+		       q.max_members, q.queries_per_minute, q.updated_at
+		FROM tenant_quotas q
+		JOIN tenants t ON t.id = q.tenant_id
+		WHERE q.tenant_id = $1 AND t.deleted_at IS NULL
+	`
+	var q domain.Quota
+	err := r.db.QueryRow(ctx, query, tenantID).Scan(
+		&q.TenantID,
+		&q.DailyIngestGB,
+		&q.RetentionDays,
+		&q.MaxProjects,
+		&q.MaxMembers,
+		&q.QueriesPerMinute,
+		&q.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrTenantNotFound
+		}
+		return nil, err
+	}
+	return &q, nil
+}
+
+func (r *tenantRepository) UpdateQuota(ctx context.Context, q *domain.Quota) error {
+	return withTx(ctx, r.db, func(tx pgx.Tx) error {
+		var exists bool
+		const check = `SELECT EXISTS (SELECT 1 FROM tenants WHERE id = $1 AND deleted_at IS NULL)`
+		if err := tx.QueryRow(ctx, check, q.TenantID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return domain.ErrTenantNotFound
+		}
+		return upsertQuota(ctx, tx, q)
+	})
+}
+
+func upsertQuota(ctx context.Context, tx pgx.Tx, q *domain.Quota) error {
+	const query = `
+		INSERT INTO tenant_quotas (
+			tenant_id, daily_ingest_gb, retention_days, max_projects, max_members, queries_per_minute
+		)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (tenant_id) DO UPDATE
+		SET daily_ingest_gb    = EXCLUDED.daily_ingest_gb,
+		    retention_days     = EXCLUDED.retention_days,
+		    max_projects       = EXCLUDED.max_projects,
+		    max_members        = EXCLUDED.max_members,
+		    queries_per_minute = EXCLUDED.queries_per_minute,
+		    updated_at         = (now() AT TIME ZONE 'utc')
+		RETURNING updated_at
+	`
+	return tx.QueryRow(ctx, query,
+		q.TenantID,
+		q.DailyIngestGB,
+		q.RetentionDays,
+		q.MaxProjects,
+		q.MaxMembers,
+		q.QueriesPerMinute,
+	).Scan(&q.UpdatedAt)
+}
+
+func scanTenant(row pgx.Row) (*domain.Tenant, error) {
+	var t domain.Tenant
+	if err := row.Scan(
+		&t.ID,
+		&t.Name,
+		&t.Slug,
+		&t.Plan,
+		&t.Status,
+		&t.SSOEnforced,
+		&t.MFARequired,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// withTx runs fn inside a transaction, committing on success and rolling back
+// on error or panic.
+func withTx(ctx context.Context, db *pgxpool.Pool, fn func(pgx.Tx) error) (err error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback(ctx)
+			panic(p)
+		}
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			return
+		}
+		err = tx.Commit(ctx)
+	}()
+	return fn(tx)
+}
+
+// mapUniqueViolation converts a PostgreSQL unique-violation into a domain error.
+func mapUniqueViolation(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return domain.ErrTenantAlreadyExists
+	}
+	return err
 }
@@ -1 +1,390 @@
 package http
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/tenant/application"
+	"github.com/indalyadav56/logify/apps/backend/internal/tenant/domain"
+	"github.com/indalyadav56/logify/apps/backend/pkg/response"
+	"github.com/indalyadav56/logify/apps/backend/pkg/validator"
+)
+
+type TenantHandler struct {
+	service application.TenantService
+}
+
+func NewTenantHandler(service application.TenantService) *TenantHandler {
+	return &TenantHandler{service: service}
+}
+
+// CurrentTenant returns the caller's own tenant.
+// @Summary      Current tenant
+// @Description  Return the authenticated caller's tenant with its plan, status and quota.
+// @Tags         tenants
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.APIResponse "Tenant retrieved successfully"
+// @Failure      401  {object}  response.APIResponse "Unauthorized"
+// @Failure      404  {object}  response.APIResponse "Tenant not found"
+// @Failure      500  {object}  response.APIResponse "Internal server error"
+// @Router       /v1/tenant [get]
+func (h *TenantHandler) CurrentTenant(c *gin.Context) {
+	tenant, err := h.service.CurrentTenant(c.Request.Context())
+	if err != nil {
+		h.writeError(c, err, "Failed to retrieve tenant")
+		return
+	}
+	response.OK(c, "Tenant retrieved successfully", tenant)
+}
+
+// CreateTenant creates a tenant.
+// @Summary      Create tenant (admin)
+// @Description  Create a tenant on the given plan with the plan's default quota.
+// @Tags         admin-tenants
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      application.CreateTenantInput  true  "Tenant payload"
+// @Success      201      {object}  response.APIResponse "Tenant created successfully"
+// @Failure      400      {object}  response.APIResponse "Invalid request"
+// @Failure      403      {object}  response.APIResponse "Forbidden"
+// @Failure      409      {object}  response.APIResponse "Slug already taken"
+// @Failure      500      {object}  response.APIResponse "Internal server error"
+// @Router       /v1/admin/tenants [post]
+func (h *TenantHandler) CreateTenant(c *gin.Context) {
+	var input application.CreateTenantInput
+	if !validator.ValidateRequest(c, &input) {
+		return
+	}
+
+	tenant, err := h.service.CreateTenant(c.Request.Context(), input)
+	if err != nil {
+		h.writeError(c, err, "Failed to create tenant")
+		return
+	}
+	response.Created(c, "Tenant created successfully", tenant)
+}
+
+// GetTenant retrieves a tenant by ID.
+// @Summary      Get tenant (admin)
+// @Description  Retrieve a tenant with its quota.
+// @Tags         admin-tenants
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Tenant ID (UUID)"
+// @Success      200  {object}  response.APIResponse "Tenant retrieved successfully"
+// @Failure      400  {object}  response.APIResponse "Invalid id format"
+// @Failure      404  {object}  response.APIResponse "Tenant not found"
+// @Failure      500  {object}  response.APIResponse "Internal server error"
+// @Router       /v1/admin/tenants/{id} [get]
+func (h *TenantHandler) GetTenant(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	tenant, err := h.service.GetTenant(c.Request.Context(), id)
+	if err != nil {
+		h.writeError(c, err, "Failed to retrieve tenant")
+		return
+	}
+	response.OK(c, "Tenant retrieved successfully", tenant)
+}
+
+// ListTenants lists tenants.
+// @Summary      List tenants (admin)
+// @Description  List tenants, optionally filtered by status, plan or a name/slug search.
+// @Tags         admin-tenants
+// @Produce      json
+// @Security     BearerAuth
+// @Param        status    query     string  false  "active | suspended"
+// @Param        plan      query     string  false  "free | pro | enterprise"
+// @Param        search    query     string  false  "Substring of name or slug"
+// @Param        page      query     int     false  "Page (default 1)"
+// @Param        per_page  query     int     false  "Page size (default 20, max 100)"
+// @Success      200       {object}  response.APIResponse "Tenants retrieved successfully"
+// @Failure      400       {object}  response.APIResponse "Invalid filter"
+// @Failure      500       {object}  response.APIResponse "Internal server error"
+// @Router       /v1/admin/tenants [get]
+func (h *TenantHandler) ListTenants(c *gin.Context) {
+	filter := domain.DefaultListFilter()
+
+	if v := c.Query("page"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			filter.Page = n
+		}
+	}
+	if v := c.Query("per_page"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 && n <= 100 {
+			filter.PerPage = n
+		}
+	}
+	filter.Status = domain.Status(strings.TrimSpace(c.Query("status")))
+	filter.Plan = domain.Plan(strings.TrimSpace(c.Query("plan")))
+	filter.Search = strings.TrimSpace(c.Query("search"))
+
+	items, total, err := h.service.ListTenants(c.Request.Context(), filter)
+	if err != nil {
+		h.writeError(c, err, "Failed to list tenants")
+		return
+	}
+	response.Paginated(c, items, filter.Page, filter.PerPage, total)
+}
+
+// UpdateTenant updates a tenant's name or slug.
+// @Summary      Update tenant (admin)
+// @Description  Rename a tenant or change its slug.
+// @Tags         admin-tenants
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                         true  "Tenant ID (UUID)"
+// @Param        request  body      application.UpdateTenantInput  true  "Tenant update payload"
+// @Success      200      {object}  response.APIResponse "Tenant updated successfully"
+// @Failure      400      {object}  response.APIResponse "Invalid request"
+// @Failure      404      {object}  response.APIResponse "Tenant not found"
+// @Failure      409      {object}  response.APIResponse "Slug already taken"
+// @Failure      500      {object}  response.APIResponse "Internal server error"
+// @Router       /v1/admin/tenants/{id} [put]
+func (h *TenantHandler) UpdateTenant(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input application.UpdateTenantInput
+	if !validator.ValidateRequest(c, &input) {
+		return
+	}
+
+	tenant, err := h.service.UpdateTenant(c.Request.Context(), id, input)
+	if err != nil {
+		h.writeError(c, err, "Failed to update tenant")
+		return
+	}
+	response.OK(c, "Tenant updated successfully", tenant)
+}
+
+// UpdateStatus suspends or reactivates a tenant.
+// @Summary      Set tenant status (admin)
+// @Description  Suspend or reactivate a tenant. Suspended tenants are rejected at ingest and search.
+// @Tags         admin-tenants
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                         true  "Tenant ID (UUID)"
+// @Param        request  body      application.UpdateStatusInput  true  "New status"
+// @Success      200      {object}  response.APIResponse "Tenant status updated successfully"
+// @Failure      400      {object}  response.APIResponse "Invalid request"
+// @Failure      404      {object}  response.APIResponse "Tenant not found"
+// @Failure      500      {object}  response.APIResponse "Internal server error"
+// @Router       /v1/admin/tenants/{id}/status [put]
+func (h *TenantHandler) UpdateStatus(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input application.UpdateStatusInput
+	if !validator.ValidateRequest(c, &input) {
+		return
+	}
+
+	tenant, err := h.service.SetStatus(c.Request.Context(), id, domain.Status(input.Status))
+	if err != nil {
+		h.writeError(c, err, "Failed to update tenant status")
+		return
+	}
+	response.OK(c, "Tenant status updated successfully", tenant)
+}
+
+// UpdateSSO turns SSO enforcement on or off for a tenant.
+// @Summary      Enforce SSO for a tenant (admin)
+// @Description  When enforced, members of the tenant can no longer log in with a password and must use SSO.
+// @Tags         admin-tenants
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                      true  "Tenant ID (UUID)"
+// @Param        request  body      application.UpdateSSOInput  true  "Enforcement flag"
+// @Success      200      {object}  response.APIResponse "Tenant SSO enforcement updated successfully"
+// @Failure      400      {object}  response.APIResponse "Invalid request"
+// @Failure      404      {object}  response.APIResponse "Tenant not found"
+// @Failure      500      {object}  response.APIResponse "Internal server error"
+// @Router       /v1/admin/tenants/{id}/sso [put]
+func (h *TenantHandler) UpdateSSO(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input application.UpdateSSOInput
+	if !validator.ValidateRequest(c, &input) {
+		return
+	}
+
+	tenant, err := h.service.SetSSOEnforced(c.Request.Context(), id, *input.Enforced)
+	if err != nil {
+		h.writeError(c, err, "Failed to update tenant SSO enforcement")
+		return
+	}
+	response.OK(c, "Tenant SSO enforcement updated successfully", tenant)
+}
+
+// UpdateMFA makes a second factor mandatory (or optional) for a tenant.
+// @Summary      Require MFA for a tenant (admin)
+// @Description  When required, members of the tenant who have not enrolled a second factor must do so before a password login completes.
+// @Tags         admin-tenants
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                      true  "Tenant ID (UUID)"
+// @Param        request  body      application.UpdateMFAInput  true  "Requirement flag"
+// @Success      200      {object}  response.APIResponse "Tenant MFA requirement updated successfully"
+// @Failure      400      {object}  response.APIResponse "Invalid request"
+// @Failure      404      {object}  response.APIResponse "Tenant not found"
+// @Failure      500      {object}  response.APIResponse "Internal server error"
+// @Router       /v1/admin/tenants/{id}/mfa [put]
+func (h *TenantHandler) UpdateMFA(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input application.UpdateMFAInput
+	if !validator.ValidateRequest(c, &input) {
+		return
+	}
+
+	tenant, err := h.service.SetMFARequired(c.Request.Context(), id, *input.Required)
+	if err != nil {
+		h.writeError(c, err, "Failed to update tenant MFA requirement")
+		return
+	}
+	response.OK(c, "Tenant MFA requirement updated successfully", tenant)
+}
+
+// ChangePlan moves a tenant to another plan.
+// @Summary      Change tenant plan (admin)
+// @Description  Switch the plan; the quota is reset to the plan defaults.
+// @Tags         admin-tenants
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                       true  "Tenant ID (UUID)"
+// @Param        request  body      application.ChangePlanInput  true  "New plan"
+// @Success      200      {object}  response.APIResponse "Tenant plan updated successfully"
+// @Failure      400      {object}  response.APIResponse "Invalid request"
+// @Failure      404      {object}  response.APIResponse "Tenant not found"
+// @Failure      500      {object}  response.APIResponse "Internal server error"
+// @Router       /v1/admin/tenants/{id}/plan [put]
+func (h *TenantHandler) ChangePlan(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input application.ChangePlanInput
+	if !validator.ValidateRequest(c, &input) {
+		return
+	}
+
+	tenant, err := h.service.ChangePlan(c.Request.Context(), id, domain.Plan(input.Plan))
+	if err != nil {
+		h.writeError(c, err, "Failed to change tenant plan")
+		return
+	}
+	response.OK(c, "Tenant plan updated successfully", tenant)
+}
+
+// UpdateQuota overrides individual quota limits.
+// @Summary      Update tenant quota (admin)
+// @Description  Override quota limits for a tenant; omitted fields keep their value.
+// @Tags         admin-tenants
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                        true  "Tenant ID (UUID)"
+// @Param        request  body      application.UpdateQuotaInput  true  "Quota overrides"
+// @Success      200      {object}  response.APIResponse "Tenant quota updated successfully"
+// @Failure      400      {object}  response.APIResponse "Invalid request"
+// @Failure      404      {object}  response.APIResponse "Tenant not found"
+// @Failure      500      {object}  response.APIResponse "Internal server error"
+// @Router       /v1/admin/tenants/{id}/quota [put]
+func (h *TenantHandler) UpdateQuota(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var input application.UpdateQuotaInput
+	if !validator.ValidateRequest(c, &input) {
+		return
+	}
+
+	quota, err := h.service.UpdateQuota(c.Request.Context(), id, input)
+	if err != nil {
+		h.writeError(c, err, "Failed to update tenant quota")
+		return
+	}
+	response.OK(c, "Tenant quota updated successfully", quota)
+}
+
+// DeleteTenant soft-deletes a tenant.
+// @Summary      Delete tenant (admin)
+// @Description  Soft-delete a tenant. Its users can no longer ingest or search.
+// @Tags         admin-tenants
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Tenant ID (UUID)"
+// @Success      204  "No content"
+// @Failure      400  {object}  response.APIResponse "Invalid id format"
+// @Failure      404  {object}  response.APIResponse "Tenant not found"
+// @Failure      500  {object}  response.APIResponse "Internal server error"
+// @Router       /v1/admin/tenants/{id} [delete]
+func (h *TenantHandler) DeleteTenant(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteTenant(c.Request.Context(), id); err != nil {
+		h.writeError(c, err, "Failed to delete tenant")
+		return
+	}
+	response.NoContent(c)
+}
+
+// writeError maps domain errors to HTTP responses with a consistent envelope.
+func (h *TenantHandler) writeError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrTenantNotFound):
+		response.NotFound(c, "Tenant not found")
+	case errors.Is(err, domain.ErrTenantAlreadyExists):
+		response.Conflict(c, "A tenant with this slug already exists")
+	case errors.Is(err, domain.ErrInvalidPlan):
+		response.BadRequest(c, "Unknown plan")
+	case errors.Is(err, domain.ErrInvalidStatus):
+		response.BadRequest(c, "Unknown tenant status")
+	case errors.Is(err, domain.ErrInvalidSlug):
+		response.BadRequest(c, "Slug must be 3-64 lowercase letters, digits or dashes")
+	case errors.Is(err, domain.ErrInvalidQuota):
+		response.BadRequest(c, "Quota limits must be positive")
+	default:
+		response.InternalServerError(c, fallback)
+	}
+}
+
+func parseUUIDParam(c *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		response.BadRequest(c, "Invalid "+name+" format")
+		return uuid.Nil, false
+	}
+	return id, true
+}
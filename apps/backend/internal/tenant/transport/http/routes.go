@@ -1 +1,36 @@
 package http
+
+import (
+	"github.com/gin-gonic/gin"
+
+	roleDomain "github.com/indalyadav56/logify/apps/backend/internal/role/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
+)
+
+// RegisterRoutes mounts the tenant routes on the given router group. The group
+// is expected to be already authenticated (see di.RegisterAllRoutes).
+//
+// The admin routes require the platform-scoped tenant.* permissions, which
+// only platform roles (super admin, support) hold; tenant roles cannot be
+// granted them.
+func RegisterRoutes(router *gin.RouterGroup, handler *TenantHandler, authz *middleware.Authorizer) {
+	canRead := authz.RequirePermission(roleDomain.ResourceTenant, roleDomain.ActionRead)
+	canManage := authz.RequirePermission(roleDomain.ResourceTenant, roleDomain.ActionManage)
+
+	// Every authenticated caller may see their own tenant, plan and quota.
+	router.GET("/v1/tenant", handler.CurrentTenant)
+
+	admin := router.Group("/v1/admin/tenants")
+	{
+		admin.GET("", canRead, handler.ListTenants)
+		admin.POST("", canManage, handler.CreateTenant)
+		admin.GET("/:id", canRead, handler.GetTenant)
+		admin.PUT("/:id", canManage, handler.UpdateTenant)
+		admin.DELETE("/:id", canManage, handler.DeleteTenant)
+		admin.PUT("/:id/status", canManage, handler.UpdateStatus)
+		admin.PUT("/:id/sso", canManage, handler.UpdateSSO)
+		admin.PUT("/:id/mfa", canManage, handler.UpdateMFA)
+		admin.PUT("/:id/plan", canManage, handler.ChangePlan)
+		admin.PUT("/:id/quota", canManage, handler.UpdateQuota)
+	}
+}
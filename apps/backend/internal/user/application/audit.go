@@ -0,0 +1,102 @@
+package application
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	auditDomain "github.com/indalyadav56/logify/apps/backend/internal/audit/domain"
+)
+
+// Membership audit actions.
+const (
+	AuditMemberInvited      = "member.invited"
+	AuditInvitationRevoked  = "member.invitation_revoked"
+	AuditInvitationAccepted = "member.invitation_accepted"
+	AuditInvitationFailed   = "member.invitation_failed"
+	AuditMemberRoleChanged  = "member.role_changed"
+	AuditMemberSuspended    = "member.suspended"
+	AuditMemberReactivated  = "member.reactivated"
+	AuditMemberRemoved      = "member.removed"
+	AuditAccountCreated     = "member.account_created"
+)
+
+// AuditEvent records one membership change. Before and After hold the
+// changed attributes only.
+type AuditEvent struct {
+	Action    string
+	ActorID   uuid.UUID
+	TenantID  uuid.UUID
+	ProjectID uuid.UUID
+	// Target is the affected user id, or the invited email when no account
+	// is involved yet.
+	Target string
+	Before map[string]string
+	After  map[string]string
+}
+
+// Auditor receives every membership change.
+type Auditor interface {
+	Record(ctx context.Context, event AuditEvent)
+}
+
+type logAuditor struct {
+	logger *zap.Logger
+}
+
+// NewLogAuditor writes audit events as structured log lines on a dedicated
+// "audit" logger.
+func NewLogAuditor(logger *zap.Logger) Auditor {
+	return &logAuditor{logger: logger.Named("audit")}
+}
+
+func (a *logAuditor) Record(_ context.Context, e AuditEvent) {
+	a.logger.Info(e.Action,
+		zap.String("actor_id", e.ActorID.String()),
+		zap.String("tenant_id", e.TenantID.String()),
+		zap.String("project_id", e.ProjectID.String()),
+		zap.String("target", e.Target),
+		zap.Any("before", e.Before),
+		zap.Any("after", e.After),
+	)
+}
+
+type trailAuditor struct {
+	trail auditDomain.Recorder
+}
+
+// NewTrailAuditor records membership changes in the persistent audit trail
+// (audit/application.AuditService).
+func NewTrailAuditor(trail auditDomain.Recorder) Auditor {
+	return &trailAuditor{trail: trail}
+}
+
+func (a *trailAuditor) Record(ctx context.Context, e AuditEvent) {
+	outcome := auditDomain.OutcomeSuccess
+	if e.Action == AuditInvitationFailed {
+		outcome = auditDomain.OutcomeFailure
+	}
+	a.trail.Record(ctx, auditDomain.Event{
+		Action:     e.Action,
+		Outcome:    outcome,
+		ActorID:    e.ActorID,
+		TenantID:   e.TenantID,
+		ProjectID:  e.ProjectID,
+		TargetType: "member",
+		TargetID:   e.Target,
+		Before:     toAny(e.Before),
+		After:      toAny(e.After),
+	})
+}
+
+func toAny(m map[string]string) map[string]any {
+	if len(m) == 0 {
+		return nil
+	}
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
@@ -1,6 +1,8 @@
 package application
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/indalyadav56/logify/apps/backend/internal/user/domain"
 )
@@ -13,6 +15,13 @@ type CreateUserInput struct {
 	Role     string `json:"role" validate:"omitempty,oneof=admin member viewer"`
 }
 
+// ProvisionUserInput creates an account for a user signing in through an
+// identity provider.
+type ProvisionUserInput struct {
+	Email    string
+	FullName string
+}
+
 // UpdateUserInput holds the data for updating an existing user.
 type UpdateUserInput struct {
 	FullName *string `json:"full_name,omitempty" validate:"omitempty,min=2,max=100"`
@@ -28,3 +37,67 @@ type UserOutput struct {
 	Role     domain.Role `json:"role"`
 	IsActive bool        `json:"is_active"`
 }
+
+// InviteMemberInput invites an email address to a project.
+type InviteMemberInput struct {
+	Email  string    `json:"email" validate:"required,email,max=255"`
+	RoleID uuid.UUID `json:"role_id" validate:"required"`
+}
+
+// AcceptInviteInput accepts an invitation. FullName and Password are only
+// used, and then required, when no account exists for the invited email.
+type AcceptInviteInput struct {
+	Token    string `json:"token" validate:"required"`
+	FullName string `json:"full_name" validate:"omitempty,min=2,max=100"`
+	Password string `json:"password" validate:"omitempty,min=8,max=72"`
+}
+
+// ChangeMemberRoleInput changes a member's project role.
+type ChangeMemberRoleInput struct {
+	RoleID uuid.UUID `json:"role_id" validate:"required"`
+}
+
+// MemberOutput is the response DTO for a project member.
+type MemberOutput struct {
+	UserID    uuid.UUID  `json:"user_id"`
+	ProjectID uuid.UUID  `json:"project_id"`
+	Email     string     `json:"email"`
+	FullName  string     `json:"full_name"`
+	RoleID    uuid.UUID  `json:"role_id"`
+	Role      string     `json:"role"`
+	Status    string     `json:"status"`
+	InvitedBy *uuid.UUID `json:"invited_by,omitempty"`
+	InvitedAt *time.Time `json:"invited_at,omitempty"`
+	JoinedAt  time.Time  `json:"joined_at"`
+}
+
+// InvitationOutput is the response DTO for an invitation. The token is never
+// returned; it only travels by email.
+type InvitationOutput struct {
+	ID        uuid.UUID `json:"id"`
+	ProjectID uuid.UUID `json:"project_id"`
+	Email     string    `json:"email"`
+	RoleID    uuid.UUID `json:"role_id"`
+	Role      string    `json:"role"`
+	State     string    `json:"state"`
+	InvitedBy uuid.UUID `json:"invited_by"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AcceptInviteOutput tells the client which tenant to switch to.
+type AcceptInviteOutput struct {
+	TenantID       uuid.UUID     `json:"tenant_id"`
+	Member         *MemberOutput `json:"member"`
+	AccountCreated bool          `json:"account_created"`
+}
+
+// MembershipOutput is one project the caller belongs to.
+type MembershipOutput struct {
+	TenantID    uuid.UUID `json:"tenant_id"`
+	TenantName  string    `json:"tenant_name"`
+	ProjectID   uuid.UUID `json:"project_id"`
+	ProjectName string    `json:"project_name"`
+	Role        string    `json:"role"`
+	Status      string    `json:"status"`
+}
@@ -0,0 +1,71 @@
+package application
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/user/domain"
+)
+
+// InviteTokens signs and verifies invitation tokens. A token is the
+// invitation id and expiry, base64url encoded, followed by an HMAC-SHA256 of
+// both; whether it can still be used is decided by the invitation row.
+type InviteTokens struct {
+	secret []byte
+}
+
+func NewInviteTokens(secret []byte) *InviteTokens {
+	if len(secret) == 0 {
+		panic("invite tokens: secret is required")
+	}
+	return &InviteTokens{secret: secret}
+}
+
+func (t *InviteTokens) Sign(invitationID uuid.UUID, expiresAt time.Time) string {
+	payload := make([]byte, 24)
+	copy(payload, invitationID[:])
+	binary.BigEndian.PutUint64(payload[16:], uint64(expiresAt.Unix()))
+
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(payload) + "." + enc.EncodeToString(t.mac(payload))
+}
+
+// Verify returns the invitation id of a well-formed, correctly signed and
+// unexpired token.
+func (t *InviteTokens) Verify(token string, now time.Time) (uuid.UUID, error) {
+	enc := base64.RawURLEncoding
+	rawPayload, rawSig, ok := strings.Cut(token, ".")
+	if !ok {
+		return uuid.Nil, domain.ErrInvalidInvitationToken
+	}
+	payload, err := enc.DecodeString(rawPayload)
+	if err != nil || len(payload) != 24 {
+		return uuid.Nil, domain.ErrInvalidInvitationToken
+	}
+	sig, err := enc.DecodeString(rawSig)
+	if err != nil || !hmac.Equal(sig, t.mac(payload)) {
+		return uuid.Nil, domain.ErrInvalidInvitationToken
+	}
+
+	expiresAt := time.Unix(int64(binary.BigEndian.Uint64(payload[16:])), 0)
+	if !now.Before(expiresAt) {
+		return uuid.Nil, domain.ErrInvitationExpired
+	}
+	id, err := uuid.FromBytes(payload[:16])
+	if err != nil {
+		return uuid.Nil, domain.ErrInvalidInvitationToken
+	}
+	return id, nil
+}
+
+func (t *InviteTokens) mac(payload []byte) []byte {
+	h := hmac.New(sha256.New, t.secret)
+	h.Write(payload)
+	return h.Sum(nil)
+}
@@ -0,0 +1,44 @@
+package application
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/user/domain"
+)
+
+func TestInviteTokenRoundTrip(t *testing.T) {
+	tokens := NewInviteTokens([]byte("test-secret"))
+	now := time.Now()
+	id := uuid.New()
+
+	token := tokens.Sign(id, now.Add(time.Hour))
+	got, err := tokens.Verify(token, now)
+	require.NoError(t, err)
+	assert.Equal(t, id, got)
+
+	_, err = tokens.Verify(token, now.Add(2*time.Hour))
+	assert.ErrorIs(t, err, domain.ErrInvitationExpired)
+
+	_, err = NewInviteTokens([]byte("other-secret")).Verify(token, now)
+	assert.ErrorIs(t, err, domain.ErrInvalidInvitationToken)
+}
+
+func TestInviteTokenRejectsTampering(t *testing.T) {
+	tokens := NewInviteTokens([]byte("test-secret"))
+	now := time.Now()
+	token := tokens.Sign(uuid.New(), now.Add(time.Hour))
+
+	// Swapping in another invitation's payload must not verify.
+	other := tokens.Sign(uuid.New(), now.Add(time.Hour))
+	forged := other[:len(other)-43] + token[len(token)-43:]
+
+	for _, bad := range []string{"", "no-dot", token + "x", "." + token, forged} {
+		_, err := tokens.Verify(bad, now)
+		assert.ErrorIs(t, err, domain.ErrInvalidInvitationToken, bad)
+	}
+}
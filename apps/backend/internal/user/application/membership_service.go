@@ -0,0 +1,520 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
+	tenantDomain "github.com/indalyadav56/logify/apps/backend/internal/tenant/domain"
+	"github.com/indalyadav56/logify/apps/backend/internal/user/domain"
+	"github.com/indalyadav56/logify/apps/backend/pkg/mailer"
+)
+
+// defaultInviteTTL is used when the config leaves invites.ttl unset.
+const defaultInviteTTL = 7 * 24 * time.Hour
+
+// MembershipService manages project members and their invitations. Every
+// management call acts on the caller's tenant as set by AuthMiddleware.
+type MembershipService interface {
+	Invite(ctx context.Context, projectID uuid.UUID, input InviteMemberInput) (*InvitationOutput, error)
+	ListInvitations(ctx context.Context, projectID uuid.UUID) ([]*InvitationOutput, error)
+	RevokeInvitation(ctx context.Context, projectID, invitationID uuid.UUID) error
+	// AcceptInvite needs no authentication: the signed token proves the
+	// invitee received the email. An account is created when none exists.
+	AcceptInvite(ctx context.Context, input AcceptInviteInput) (*AcceptInviteOutput, error)
+
+	ListMembers(ctx context.Context, projectID uuid.UUID) ([]*MemberOutput, error)
+	ChangeRole(ctx context.Context, projectID, userID uuid.UUID, input ChangeMemberRoleInput) (*MemberOutput, error)
+	SuspendMember(ctx context.Context, projectID, userID uuid.UUID) (*MemberOutput, error)
+	ReactivateMember(ctx context.Context, projectID, userID uuid.UUID) (*MemberOutput, error)
+	RemoveMember(ctx context.Context, projectID, userID uuid.UUID) error
+
+	// ListMyMemberships returns the caller's memberships across tenants.
+	ListMyMemberships(ctx context.Context) ([]*MembershipOutput, error)
+	// IsTenantMember satisfies auth/application.TenantMembership.
+	IsTenantMember(ctx context.Context, userID, tenantID uuid.UUID) (bool, error)
+}
+
+// SeatQuota supplies the tenant's member limit. It is satisfied by
+// tenant/application.TenantService.
+type SeatQuota interface {
+	GetQuota(ctx context.Context, tenantID uuid.UUID) (*tenantDomain.Quota, error)
+}
+
+// PermissionInvalidator drops cached permissions after a membership change.
+// It is satisfied by role/application.PermissionResolver.
+type PermissionInvalidator interface {
+	InvalidateUser(userID uuid.UUID)
+}
+
+// InviteConfig configures invitation tokens and emails.
+type InviteConfig struct {
+	// Secret signs invitation tokens. It must be used for nothing else.
+	Secret []byte
+	TTL    time.Duration
+	// AcceptURL is the page the emailed link opens; the token is appended
+	// as the "token" query parameter.
+	AcceptURL string
+}
+
+type membershipService struct {
+	repo    domain.MemberRepository
+	users   UserService
+	tokens  *InviteTokens
+	mail    mailer.Mailer
+	quotas  SeatQuota
+	perms   PermissionInvalidator
+	auditor Auditor
+	cfg     InviteConfig
+	logger  *zap.Logger
+	now     func() time.Time
+}
+
+func NewMembershipService(
+	repo domain.MemberRepository,
+	users UserService,
+	mail mailer.Mailer,
+	quotas SeatQuota,
+	perms PermissionInvalidator,
+	auditor Auditor,
+	cfg InviteConfig,
+	logger *zap.Logger,
+) MembershipService {
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaultInviteTTL
+	}
+	return &membershipService{
+		repo:    repo,
+		users:   users,
+		tokens:  NewInviteTokens(cfg.Secret),
+		mail:    mail,
+		quotas:  quotas,
+		perms:   perms,
+		auditor: auditor,
+		cfg:     cfg,
+		logger:  logger.Named("membership_service"),
+		now:     time.Now,
+	}
+}
+
+func (s *membershipService) Invite(ctx context.Context, projectID uuid.UUID, input InviteMemberInput) (*InvitationOutput, error) {
+	actorID, tenantID, err := callerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	projectName, err := s.repo.ProjectName(ctx, tenantID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	roleKey, err := s.repo.AssignableRole(ctx, tenantID, input.RoleID)
+	if err != nil {
+		return nil, err
+	}
+	isMember, err := s.repo.HasMemberWithEmail(ctx, tenantID, projectID, input.Email)
+	if err != nil {
+		return nil, err
+	}
+	if isMember {
+		return nil, domain.ErrAlreadyMember
+	}
+
+	now := s.now()
+	if err := s.checkSeats(ctx, tenantID, now); err != nil {
+		return nil, err
+	}
+
+	inv := domain.NewInvitation(tenantID, projectID, input.RoleID, actorID, input.Email, now, s.cfg.TTL)
+	inv.ProjectName, inv.RoleKey = projectName, roleKey
+	if err := s.repo.CreateInvitation(ctx, inv); err != nil {
+		if !errors.Is(err, domain.ErrInvitationPending) {
+			s.logger.Error("failed to create invitation", zap.String("project_id", projectID.String()), zap.Error(err))
+		}
+		return nil, err
+	}
+
+	if err := s.sendInvitation(ctx, inv, actorID); err != nil {
+		s.logger.Error("failed to send invitation", zap.String("invitation_id", inv.ID.String()), zap.Error(err))
+		// Close the invitation so the address can be invited again.
+		if rerr := s.repo.RevokeInvitation(ctx, tenantID, projectID, inv.ID); rerr != nil {
+			s.logger.Error("failed to revoke undelivered invitation", zap.String("invitation_id", inv.ID.String()), zap.Error(rerr))
+		}
+		s.auditor.Record(ctx, AuditEvent{
+			Action:    AuditInvitationFailed,
+			ActorID:   actorID,
+			TenantID:  tenantID,
+			ProjectID: projectID,
+			Target:    inv.Email,
+			After:     map[string]string{"invitation_id": inv.ID.String(), "error": err.Error()},
+		})
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvitationDelivery, err)
+	}
+
+	s.auditor.Record(ctx, AuditEvent{
+		Action:    AuditMemberInvited,
+		ActorID:   actorID,
+		TenantID:  tenantID,
+		ProjectID: projectID,
+		Target:    inv.Email,
+		After: map[string]string{
+			"invitation_id": inv.ID.String(),
+			"role":          roleKey,
+			"expires_at":    inv.ExpiresAt.Format(time.RFC3339),
+		},
+	})
+	return toInvitationOutput(inv, now), nil
+}
+
+func (s *membershipService) ListInvitations(ctx context.Context, projectID uuid.UUID) ([]*InvitationOutput, error) {
+	_, tenantID, err := callerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.ProjectName(ctx, tenantID, projectID); err != nil {
+		return nil, err
+	}
+	invs, err := s.repo.ListInvitations(ctx, tenantID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	out := make([]*InvitationOutput, len(invs))
+	for i, inv := range invs {
+		out[i] = toInvitationOutput(inv, now)
+	}
+	return out, nil
+}
+
+func (s *membershipService) RevokeInvitation(ctx context.Context, projectID, invitationID uuid.UUID) error {
+	actorID, tenantID, err := callerFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.RevokeInvitation(ctx, tenantID, projectID, invitationID); err != nil {
+		return err
+	}
+	s.auditor.Record(ctx, AuditEvent{
+		Action:    AuditInvitationRevoked,
+		ActorID:   actorID,
+		TenantID:  tenantID,
+		ProjectID: projectID,
+		Target:    invitationID.String(),
+	})
+	return nil
+}
+
+func (s *membershipService) AcceptInvite(ctx context.Context, input AcceptInviteInput) (*AcceptInviteOutput, error) {
+	now := s.now()
+	id, err := s.tokens.Verify(input.Token, now)
+	if err != nil {
+		return nil, err
+	}
+	inv, err := s.repo.GetInvitation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	switch inv.State(now) {
+	case domain.InvitationAccepted, domain.InvitationRevoked:
+		return nil, domain.ErrInvitationClosed
+	case domain.InvitationExpired:
+		return nil, domain.ErrInvitationExpired
+	}
+
+	user, created, err := s.inviteeAccount(ctx, inv, input)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := s.repo.AcceptInvitation(ctx, inv, user.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	s.perms.InvalidateUser(user.ID)
+
+	s.auditor.Record(ctx, AuditEvent{
+		Action:    AuditInvitationAccepted,
+		ActorID:   user.ID,
+		TenantID:  inv.TenantID,
+		ProjectID: inv.ProjectID,
+		Target:    user.ID.String(),
+		After: map[string]string{
+			"invitation_id": inv.ID.String(),
+			"role":          member.RoleKey,
+			"status":        string(member.Status),
+		},
+	})
+	s.logger.Info("invitation accepted",
+		zap.String("invitation_id", inv.ID.String()),
+		zap.String("user_id", user.ID.String()),
+	)
+	return &AcceptInviteOutput{
+		TenantID:       inv.TenantID,
+		Member:         toMemberOutput(member),
+		AccountCreated: created,
+	}, nil
+}
+
+// inviteeAccount returns the account the invitation was sent to, creating it
+// from the accept input when the invitee has none yet.
+func (s *membershipService) inviteeAccount(ctx context.Context, inv *domain.Invitation, input AcceptInviteInput) (*UserOutput, bool, error) {
+	user, err := s.users.GetUserByEmail(ctx, inv.Email)
+	if err == nil {
+		return user, false, nil
+	}
+	if !errors.Is(err, domain.ErrUserNotFound) {
+		return nil, false, err
+	}
+
+	if input.FullName == "" || input.Password == "" {
+		return nil, false, domain.ErrAccountDetailsRequired
+	}
+	user, err = s.users.CreateUser(ctx, CreateUserInput{
+		Email:    inv.Email,
+		FullName: input.FullName,
+		Password: input.Password,
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	s.auditor.Record(ctx, AuditEvent{
+		Action:    AuditAccountCreated,
+		ActorID:   user.ID,
+		TenantID:  inv.TenantID,
+		ProjectID: inv.ProjectID,
+		Target:    user.ID.String(),
+		After:     map[string]string{"email": user.Email, "invitation_id": inv.ID.String()},
+	})
+	return user, true, nil
+}
+
+func (s *membershipService) ListMembers(ctx context.Context, projectID uuid.UUID) ([]*MemberOutput, error) {
+	_, tenantID, err := callerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.ProjectName(ctx, tenantID, projectID); err != nil {
+		return nil, err
+	}
+	members, err := s.repo.ListMembers(ctx, tenantID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*MemberOutput, len(members))
+	for i, m := range members {
+		out[i] = toMemberOutput(m)
+	}
+	return out, nil
+}
+
+func (s *membershipService) ChangeRole(ctx context.Context, projectID, userID uuid.UUID, input ChangeMemberRoleInput) (*MemberOutput, error) {
+	actorID, tenantID, err := callerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if actorID == userID {
+		return nil, domain.ErrCannotModifySelf
+	}
+
+	member, err := s.repo.GetMember(ctx, tenantID, projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+	roleKey, err := s.repo.AssignableRole(ctx, tenantID, input.RoleID)
+	if err != nil {
+		return nil, err
+	}
+	if member.RoleID == input.RoleID {
+		return toMemberOutput(member), nil
+	}
+
+	if err := s.repo.UpdateMemberRole(ctx, tenantID, projectID, userID, input.RoleID); err != nil {
+		return nil, err
+	}
+	s.perms.InvalidateUser(userID)
+
+	s.auditor.Record(ctx, AuditEvent{
+		Action:    AuditMemberRoleChanged,
+		ActorID:   actorID,
+		TenantID:  tenantID,
+		ProjectID: projectID,
+		Target:    userID.String(),
+		Before:    map[string]string{"role": member.RoleKey},
+		After:     map[string]string{"role": roleKey},
+	})
+	member.RoleID, member.RoleKey = input.RoleID, roleKey
+	return toMemberOutput(member), nil
+}
+
+func (s *membershipService) SuspendMember(ctx context.Context, projectID, userID uuid.UUID) (*MemberOutput, error) {
+	return s.setStatus(ctx, projectID, userID, domain.MemberSuspended, AuditMemberSuspended)
+}
+
+func (s *membershipService) ReactivateMember(ctx context.Context, projectID, userID uuid.UUID) (*MemberOutput, error) {
+	return s.setStatus(ctx, projectID, userID, domain.MemberActive, AuditMemberReactivated)
+}
+
+func (s *membershipService) RemoveMember(ctx context.Context, projectID, userID uuid.UUID) error {
+	_, err := s.setStatus(ctx, projectID, userID, domain.MemberLeft, AuditMemberRemoved)
+	return err
+}
+
+func (s *membershipService) setStatus(ctx context.Context, projectID, userID uuid.UUID, status domain.MemberStatus, action string) (*MemberOutput, error) {
+	actorID, tenantID, err := callerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if actorID == userID {
+		return nil, domain.ErrCannotModifySelf
+	}
+
+	member, err := s.repo.GetMember(ctx, tenantID, projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member.Status == status {
+		return toMemberOutput(member), nil
+	}
+
+	if err := s.repo.UpdateMemberStatus(ctx, tenantID, projectID, userID, status); err != nil {
+		return nil, err
+	}
+	s.perms.InvalidateUser(userID)
+
+	s.auditor.Record(ctx, AuditEvent{
+		Action:    action,
+		ActorID:   actorID,
+		TenantID:  tenantID,
+		ProjectID: projectID,
+		Target:    userID.String(),
+		Before:    map[string]string{"status": string(member.Status)},
+		After:     map[string]string{"status": string(status)},
+	})
+	member.Status = status
+	return toMemberOutput(member), nil
+}
+
+func (s *membershipService) ListMyMemberships(ctx context.Context) ([]*MembershipOutput, error) {
+	userID, ok := middleware.UserUUIDFromContext(ctx)
+	if !ok {
+		return nil, domain.ErrTenantRequired
+	}
+	memberships, err := s.repo.ListMemberships(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*MembershipOutput, len(memberships))
+	for i, m := range memberships {
+		out[i] = &MembershipOutput{
+			TenantID:    m.TenantID,
+			TenantName:  m.TenantName,
+			ProjectID:   m.ProjectID,
+			ProjectName: m.ProjectName,
+			Role:        m.RoleKey,
+			Status:      string(m.Status),
+		}
+	}
+	return out, nil
+}
+
+func (s *membershipService) IsTenantMember(ctx context.Context, userID, tenantID uuid.UUID) (bool, error) {
+	return s.repo.IsTenantMember(ctx, userID, tenantID)
+}
+
+// checkSeats enforces the tenant's max_members quota. Members and open
+// invitations both take a seat.
+func (s *membershipService) checkSeats(ctx context.Context, tenantID uuid.UUID, now time.Time) error {
+	quota, err := s.quotas.GetQuota(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	seats, err := s.repo.CountSeats(ctx, tenantID, now)
+	if err != nil {
+		return err
+	}
+	if seats >= quota.MaxMembers {
+		return domain.ErrMemberLimitReached
+	}
+	return nil
+}
+
+func (s *membershipService) sendInvitation(ctx context.Context, inv *domain.Invitation, actorID uuid.UUID) error {
+	inviter := "A teammate"
+	if u, err := s.users.GetUser(ctx, actorID); err == nil && u.FullName != "" {
+		inviter = u.FullName
+	}
+
+	link := acceptLink(s.cfg.AcceptURL, s.tokens.Sign(inv.ID, inv.ExpiresAt))
+	body := fmt.Sprintf(
+		"%s invited you to join the project %q on Logify as %s.\n\n"+
+			"Accept the invitation:\n%s\n\n"+
+			"The invitation expires on %s. If you were not expecting it, you can ignore this email.\n",
+		inviter, inv.ProjectName, inv.RoleKey, link, inv.ExpiresAt.UTC().Format("2 Jan 2006 15:04 MST"),
+	)
+	return s.mail.Send(ctx, mailer.Message{
+		To:      inv.Email,
+		Subject: fmt.Sprintf("%s invited you to %s on Logify", inviter, inv.ProjectName),
+		Body:    body,
+	})
+}
+
+// acceptLink appends the token to the accept page, or returns the bare token
+// when no page is configured.
+func acceptLink(base, token string) string {
+	u, err := url.Parse(base)
+	if base == "" || err != nil {
+		return token
+	}
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// callerFromContext returns the authenticated user and tenant set by
+// AuthMiddleware. The tenant is never taken from request input.
+func callerFromContext(ctx context.Context) (uuid.UUID, uuid.UUID, error) {
+	userID, ok := middleware.UserUUIDFromContext(ctx)
+	if !ok {
+		return uuid.Nil, uuid.Nil, domain.ErrTenantRequired
+	}
+	tenantID, ok := middleware.GetTenantUUIDFromContext(ctx)
+	if !ok {
+		return uuid.Nil, uuid.Nil, domain.ErrTenantRequired
+	}
+	return userID, tenantID, nil
+}
+
+func toMemberOutput(m *domain.Member) *MemberOutput {
+	return &MemberOutput{
+		UserID:    m.UserID,
+		ProjectID: m.ProjectID,
+		Email:     m.Email,
+		FullName:  m.FullName,
+		RoleID:    m.RoleID,
+		Role:      m.RoleKey,
+		Status:    string(m.Status),
+		InvitedBy: m.InvitedBy,
+		InvitedAt: m.InvitedAt,
+		JoinedAt:  m.JoinedAt,
+	}
+}
+
+func toInvitationOutput(inv *domain.Invitation, now time.Time) *InvitationOutput {
+	return &InvitationOutput{
+		ID:        inv.ID,
+		ProjectID: inv.ProjectID,
+		Email:     inv.Email,
+		RoleID:    inv.RoleID,
+		Role:      inv.RoleKey,
+		State:     string(inv.State(now)),
+		InvitedBy: inv.InvitedBy,
+		ExpiresAt: inv.ExpiresAt,
+		CreatedAt: inv.CreatedAt,
+	}
+}
@@ -12,10 +12,13 @@ import (
 
 type UserService interface {
 	CreateUser(ctx context.Context, input CreateUserInput) (*UserOutput, error)
+	// ProvisionUser creates an account without a password, for users that
+	// sign in through SSO. Password login never succeeds for it.
+	ProvisionUser(ctx context.Context, input ProvisionUserInput) (*UserOutput, error)
 	GetUser(ctx context.Context, id uuid.UUID) (*UserOutput, error)
 	// Authenticate verifies email/password and returns the user on success.
 	Authenticate(ctx context.Context, email, password string) (*UserOutput, error)
-	// GetUserByEmail(ctx context.Context, email string) (*domain.User, error)
+	GetUserByEmail(ctx context.Context, email string) (*UserOutput, error)
 	// UpdateUser(ctx context.Context, id uuid.UUID, input UpdateUserInput) (*UserOutput, error)
 	// DeleteUser(ctx context.Context, id uuid.UUID) error
 	// ListUsers(ctx context.Context, params domain.ListParams) ([]*UserOutput, int64, error)
@@ -72,6 +75,27 @@ func (s *userService) CreateUser(ctx context.Context, input CreateUserInput) (*U
 	return toUserOutput(user), nil
 }
 
+func (s *userService) ProvisionUser(ctx context.Context, input ProvisionUserInput) (*UserOutput, error) {
+	existing, err := s.repo.GetByEmail(ctx, input.Email)
+	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+		s.logger.Error("failed to check existing user", zap.Error(err))
+		return nil, err
+	}
+	if existing != nil {
+		return nil, domain.ErrUserAlreadyExists
+	}
+
+	user := domain.NewUser(input.Email, input.FullName, "")
+	if err := s.repo.Create(ctx, user); err != nil {
+		s.logger.Error("failed to provision user", zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.Info("user provisioned", zap.String("user_id", user.ID.String()))
+
+	return toUserOutput(user), nil
+}
+
 func (s *userService) GetUser(ctx context.Context, id uuid.UUID) (*UserOutput, error) {
 	user, err := s.repo.GetByID(ctx, id)
 	if err != nil {
@@ -88,7 +112,8 @@ func (s *userService) Authenticate(ctx context.Context, email, password string)
 		}
 		return nil, err
 	}
-	if !user.IsActive {
+	// Provisioned accounts have no password.
+	if !user.IsActive || user.PasswordHash == "" {
 		return nil, domain.ErrInvalidCredentials
 	}
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
@@ -97,9 +122,13 @@ func (s *userService) Authenticate(ctx context.Context, email, password string)
 	return toUserOutput(user), nil
 }
 
-// func (s *userService) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
-// 	return s.repo.GetByEmail(ctx, email)
-// }
+func (s *userService) GetUserByEmail(ctx context.Context, email string) (*UserOutput, error) {
+	user, err := s.repo.GetByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	return toUserOutput(user), nil
+}
 
 // func (s *userService) UpdateUser(ctx context.Context, id uuid.UUID, input UpdateUserInput) (*UserOutput, error) {
 // 	user, err := s.repo.GetByID(ctx, id)
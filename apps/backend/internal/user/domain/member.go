@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrTenantRequired         = errors.New("tenant is required")
+	ErrProjectNotFound        = errors.New("project not found")
+	ErrMemberNotFound         = errors.New("member not found")
+	ErrAlreadyMember          = errors.New("user is already a member of the project")
+	ErrRoleNotAssignable      = errors.New("role cannot be assigned to project members")
+	ErrCannotModifySelf       = errors.New("members cannot change their own membership")
+	ErrMemberLimitReached     = errors.New("tenant member limit reached")
+	ErrInvitationNotFound     = errors.New("invitation not found")
+	ErrInvitationPending      = errors.New("an invitation for this email is already pending")
+	ErrInvitationExpired      = errors.New("invitation expired")
+	ErrInvitationClosed       = errors.New("invitation already accepted or revoked")
+	ErrInvalidInvitationToken = errors.New("invalid invitation token")
+	ErrInvitationDelivery     = errors.New("invitation email could not be sent")
+	ErrAccountDetailsRequired = errors.New("full name and password are required to create an account")
+)
+
+// MemberStatus mirrors the project_members.status check constraint.
+type MemberStatus string
+
+const (
+	MemberActive    MemberStatus = "active"
+	MemberSuspended MemberStatus = "suspended"
+	// MemberLeft marks a removed member; the row is kept for its invite
+	// history and reactivated if the user is invited again.
+	MemberLeft MemberStatus = "left"
+)
+
+// Member is a user's membership in a project. The role is a project-level
+// grant resolved by the permission resolver.
+type Member struct {
+	ID        uuid.UUID    `json:"id"`
+	ProjectID uuid.UUID    `json:"project_id"`
+	UserID    uuid.UUID    `json:"user_id"`
+	Email     string       `json:"email"`
+	FullName  string       `json:"full_name"`
+	RoleID    uuid.UUID    `json:"role_id"`
+	RoleKey   string       `json:"role_key"`
+	Status    MemberStatus `json:"status"`
+	InvitedBy *uuid.UUID   `json:"invited_by,omitempty"`
+	InvitedAt *time.Time   `json:"invited_at,omitempty"`
+	JoinedAt  time.Time    `json:"joined_at"`
+}
+
+// Membership is one project the user belongs to, with the tenant that owns it.
+type Membership struct {
+	TenantID    uuid.UUID    `json:"tenant_id"`
+	TenantName  string       `json:"tenant_name"`
+	ProjectID   uuid.UUID    `json:"project_id"`
+	ProjectName string       `json:"project_name"`
+	RoleKey     string       `json:"role_key"`
+	Status      MemberStatus `json:"status"`
+}
+
+// InvitationState is derived from the invitation timestamps.
+type InvitationState string
+
+const (
+	InvitationPending  InvitationState = "pending"
+	InvitationAccepted InvitationState = "accepted"
+	InvitationRevoked  InvitationState = "revoked"
+	InvitationExpired  InvitationState = "expired"
+)
+
+// Invitation invites an email address to a project with a role.
+type Invitation struct {
+	ID          uuid.UUID
+	TenantID    uuid.UUID
+	ProjectID   uuid.UUID
+	ProjectName string
+	Email       string
+	RoleID      uuid.UUID
+	RoleKey     string
+	InvitedBy   uuid.UUID
+	ExpiresAt   time.Time
+	AcceptedAt  *time.Time
+	AcceptedBy  *uuid.UUID
+	RevokedAt   *time.Time
+	CreatedAt   time.Time
+}
+
+func NewInvitation(tenantID, projectID, roleID, invitedBy uuid.UUID, email string, now time.Time, ttl time.Duration) *Invitation {
+	return &Invitation{
+		ID:        uuid.New(),
+		TenantID:  tenantID,
+		ProjectID: projectID,
+		Email:     strings.TrimSpace(email),
+		RoleID:    roleID,
+		InvitedBy: invitedBy,
+		ExpiresAt: now.Add(ttl).UTC().Truncate(time.Second),
+		CreatedAt: now.UTC(),
+	}
+}
+
+func (i *Invitation) State(now time.Time) InvitationState {
+	switch {
+	case i.AcceptedAt != nil:
+		return InvitationAccepted
+	case i.RevokedAt != nil:
+		return InvitationRevoked
+	case !now.Before(i.ExpiresAt):
+		return InvitationExpired
+	}
+	return InvitationPending
+}
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -42,3 +43,55 @@ func DefaultListParams() ListParams {
 		SortDir: "desc",
 	}
 }
+
+// MemberRepository persists project memberships and invitations. Every
+// tenant-scoped call treats a project of another tenant like a missing one
+// (ErrProjectNotFound / ErrMemberNotFound / ErrInvitationNotFound).
+type MemberRepository interface {
+	// ProjectName returns the name of a live project owned by the tenant.
+	ProjectName(ctx context.Context, tenantID, projectID uuid.UUID) (string, error)
+
+	// AssignableRole returns the key of a role that may be granted on a
+	// project of the tenant: a global workspace role other than owner, or one
+	// of the tenant's own roles. Anything else is ErrRoleNotAssignable.
+	AssignableRole(ctx context.Context, tenantID, roleID uuid.UUID) (string, error)
+
+	// ListMembers returns the project's active and suspended members.
+	ListMembers(ctx context.Context, tenantID, projectID uuid.UUID) ([]*Member, error)
+	GetMember(ctx context.Context, tenantID, projectID, userID uuid.UUID) (*Member, error)
+	// HasMemberWithEmail reports whether an active or suspended member of
+	// the project uses the address.
+	HasMemberWithEmail(ctx context.Context, tenantID, projectID uuid.UUID, email string) (bool, error)
+	UpdateMemberRole(ctx context.Context, tenantID, projectID, userID, roleID uuid.UUID) error
+	// UpdateMemberStatus moves an active or suspended member to status.
+	UpdateMemberStatus(ctx context.Context, tenantID, projectID, userID uuid.UUID, status MemberStatus) error
+
+	// CountSeats counts the distinct users holding an active or suspended
+	// membership in any of the tenant's projects plus its open invitations.
+	CountSeats(ctx context.Context, tenantID uuid.UUID, now time.Time) (int, error)
+
+	// ListMemberships returns every project the user is an active or
+	// suspended member of, across tenants.
+	ListMemberships(ctx context.Context, userID uuid.UUID) ([]*Membership, error)
+	// IsTenantMember reports whether the user is an active member of at
+	// least one live project of the tenant.
+	IsTenantMember(ctx context.Context, userID, tenantID uuid.UUID) (bool, error)
+
+	// CreateInvitation returns ErrInvitationPending when an open invitation
+	// for the address and project already exists.
+	CreateInvitation(ctx context.Context, inv *Invitation) error
+	// GetInvitation looks an invitation up by id alone; the caller proved
+	// access with a signed token.
+	GetInvitation(ctx context.Context, id uuid.UUID) (*Invitation, error)
+	// ListInvitations returns the project's open (not accepted or revoked)
+	// invitations, newest first, including expired ones.
+	ListInvitations(ctx context.Context, tenantID, projectID uuid.UUID) ([]*Invitation, error)
+	// RevokeInvitation closes an open invitation; ErrInvitationNotFound
+	// when none matches.
+	RevokeInvitation(ctx context.Context, tenantID, projectID, id uuid.UUID) error
+	// AcceptInvitation closes the invitation and adds (or reactivates) the
+	// membership in one transaction. Returns ErrInvitationClosed when the
+	// invitation was used or revoked meanwhile and ErrAlreadyMember when the
+	// user already holds an active or suspended membership.
+	AcceptInvitation(ctx context.Context, inv *Invitation, userID uuid.UUID, at time.Time) (*Member, error)
+}
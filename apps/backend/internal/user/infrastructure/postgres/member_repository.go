@@ -0,0 +1,439 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/user/domain"
+)
+
+type memberRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewMemberRepository(db *pgxpool.Pool) domain.MemberRepository {
+	return &memberRepository{db: db}
+}
+
+// tenantProject restricts a query to a live project of the tenant; $1 is the
+// tenant and $2 the project.
+const tenantProject = `EXISTS (
+	SELECT 1 FROM projects p
+	WHERE p.id = $2 AND p.tenant_id = $1 AND p.deleted_at IS NULL
+)`
+
+const memberColumns = `
+	pm.id, pm.project_id, pm.user_id, u.email, u.full_name, pm.role_id, r.key,
+	pm.status, pm.invited_by, pm.invited_at, pm.joined_at
+`
+
+const memberFrom = `
+	FROM project_members pm
+	JOIN auth.users u ON u.id = pm.user_id
+	JOIN auth.roles r ON r.id = pm.role_id
+`
+
+func (r *memberRepository) ProjectName(ctx context.Context, tenantID, projectID uuid.UUID) (string, error) {
+	const query = `
+		SELECT name FROM projects
+		WHERE id = $2 AND tenant_id = $1 AND deleted_at IS NULL
+	`
+	var name string
+	if err := r.db.QueryRow(ctx, query, tenantID, projectID).Scan(&name); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", domain.ErrProjectNotFound
+		}
+		return "", err
+	}
+	return name, nil
+}
+
+func (r *memberRepository) AssignableRole(ctx context.Context, tenantID, roleID uuid.UUID) (string, error) {
+	const query = `
+		SELECT key FROM auth.roles
+		WHERE id = $2
+		  AND (
+			tenant_id = $1
+			OR (tenant_id IS NULL AND scope = 'workspace' AND key <> 'owner')
+		  )
+	`
+	var key string
+	if err := r.db.QueryRow(ctx, query, tenantID, roleID).Scan(&key); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", domain.ErrRoleNotAssignable
+		}
+		return "", err
+	}
+	return key, nil
+}
+
+func (r *memberRepository) ListMembers(ctx context.Context, tenantID, projectID uuid.UUID) ([]*domain.Member, error) {
+	query := `
+		SELECT ` + memberColumns + memberFrom + `
+		WHERE pm.project_id = $2
+		  AND pm.status <> 'left'
+		  AND ` + tenantProject + `
+		ORDER BY pm.joined_at
+	`
+	rows, err := r.db.Query(ctx, query, tenantID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]*domain.Member, 0)
+	for rows.Next() {
+		m, err := scanMember(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
+
+func (r *memberRepository) GetMember(ctx context.Context, tenantID, projectID, userID uuid.UUID) (*domain.Member, error) {
+	query := `
+		SELECT ` + memberColumns + memberFrom + `
+		WHERE pm.project_id = $2
+		  AND pm.user_id = $3
+		  AND pm.status <> 'left'
+		  AND ` + tenantProject
+	m, err := scanMember(r.db.QueryRow(ctx, query, tenantID, projectID, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrMemberNotFound
+		}
+		return nil, err
+	}
+	return m, nil
+}
+
+func (r *memberRepository) HasMemberWithEmail(ctx context.Context, tenantID, projectID uuid.UUID, email string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM project_members pm
+			JOIN auth.users u ON u.id = pm.user_id
+			WHERE pm.project_id = $2
+			  AND pm.status <> 'left'
+			  AND lower(u.email) = lower($3)
+		) AND ` + tenantProject
+	var exists bool
+	err := r.db.QueryRow(ctx, query, tenantID, projectID, email).Scan(&exists)
+	return exists, err
+}
+
+func (r *memberRepository) UpdateMemberRole(ctx context.Context, tenantID, projectID, userID, roleID uuid.UUID) error {
+	query := `
+		UPDATE project_members pm
+		SET role_id = $4, updated_at = now()
+		WHERE pm.project_id = $2
+		  AND pm.user_id = $3
+		  AND pm.status <> 'left'
+		  AND ` + tenantProject
+	return expectOne(r.db.Exec(ctx, query, tenantID, projectID, userID, roleID))
+}
+
+func (r *memberRepository) UpdateMemberStatus(ctx context.Context, tenantID, projectID, userID uuid.UUID, status domain.MemberStatus) error {
+	query := `
+		UPDATE project_members pm
+		SET status = $4, updated_at = now()
+		WHERE pm.project_id = $2
+		  AND pm.user_id = $3
+		  AND pm.status <> 'left'
+		  AND ` + tenantProject
+	return expectOne(r.db.Exec(ctx, query, tenantID, projectID, userID, string(status)))
+}
+
+func (r *memberRepository) CountSeats(ctx context.Context, tenantID uuid.UUID, now time.Time) (int, error) {
+	const query = `
+		SELECT
+			(SELECT count(DISTINCT pm.user_id)
+			 FROM project_members pm
+			 JOIN projects p ON p.id = pm.project_id
+			 WHERE p.tenant_id = $1 AND p.deleted_at IS NULL AND pm.status <> 'left')
+			+
+			(SELECT count(DISTINCT lower(email))
+			 FROM project_invitations
+			 WHERE tenant_id = $1 AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > $2)
+	`
+	var n int
+	err := r.db.QueryRow(ctx, query, tenantID, now).Scan(&n)
+	return n, err
+}
+
+func (r *memberRepository) ListMemberships(ctx context.Context, userID uuid.UUID) ([]*domain.Membership, error) {
+	const query = `
+		SELECT p.tenant_id, COALESCE(t.name, ''), p.id, p.name, r.key, pm.status
+		FROM project_members pm
+		JOIN projects p ON p.id = pm.project_id
+		JOIN auth.roles r ON r.id = pm.role_id
+		LEFT JOIN tenants t ON t.id = p.tenant_id
+		WHERE pm.user_id = $1
+		  AND pm.status <> 'left'
+		  AND p.deleted_at IS NULL
+		ORDER BY t.name, p.name
+	`
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]*domain.Membership, 0)
+	for rows.Next() {
+		var m domain.Membership
+		var status string
+		if err := rows.Scan(&m.TenantID, &m.TenantName, &m.ProjectID, &m.ProjectName, &m.RoleKey, &status); err != nil {
+			return nil, err
+		}
+		m.Status = domain.MemberStatus(status)
+		out = append(out, &m)
+	}
+	return out, rows.Err()
+}
+
+func (r *memberRepository) IsTenantMember(ctx context.Context, userID, tenantID uuid.UUID) (bool, error) {
+	const query = `
+		SELECT EXISTS (
+			SELECT 1
+			FROM project_members pm
+			JOIN projects p ON p.id = pm.project_id
+			WHERE pm.user_id = $1
+			  AND p.tenant_id = $2
+			  AND pm.status = 'active'
+			  AND p.deleted_at IS NULL
+		)
+	`
+	var ok bool
+	err := r.db.QueryRow(ctx, query, userID, tenantID).Scan(&ok)
+	return ok, err
+}
+
+func (r *memberRepository) CreateInvitation(ctx context.Context, inv *domain.Invitation) error {
+	const query = `
+		INSERT INTO project_invitations (id, tenant_id, project_id, email, role_id, invited_by, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := r.db.Exec(ctx, query,
+		inv.ID,
+		inv.TenantID,
+		inv.ProjectID,
+		inv.Email,
+		inv.RoleID,
+		inv.InvitedBy,
+		inv.ExpiresAt,
+		inv.CreatedAt,
+	)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return domain.ErrInvitationPending
+	}
+	return err
+}
+
+const invitationColumns = `
+	i.id, i.tenant_id, i.project_id, p.name, i.email, i.role_id, r.key, i.invited_by,
+	i.expires_at, i.accepted_at, i.accepted_by, i.revoked_at, i.created_at
+`
+
+const invitationFrom = `
+	FROM project_invitations i
+	JOIN projects p ON p.id = i.project_id
+	JOIN auth.roles r ON r.id = i.role_id
+`
+
+func (r *memberRepository) GetInvitation(ctx context.Context, id uuid.UUID) (*domain.Invitation, error) {
+	query := `SELECT ` + invitationColumns + invitationFrom + ` WHERE i.id = $1 AND p.deleted_at IS NULL`
+	inv, err := scanInvitation(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrInvitationNotFound
+		}
+		return nil, err
+	}
+	return inv, nil
+}
+
+func (r *memberRepository) ListInvitations(ctx context.Context, tenantID, projectID uuid.UUID) ([]*domain.Invitation, error) {
+	query := `
+		SELECT ` + invitationColumns + invitationFrom + `
+		WHERE i.tenant_id = $1
+		  AND i.project_id = $2
+		  AND i.accepted_at IS NULL
+		  AND i.revoked_at IS NULL
+		  AND p.deleted_at IS NULL
+		ORDER BY i.created_at DESC
+	`
+	rows, err := r.db.Query(ctx, query, tenantID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]*domain.Invitation, 0)
+	for rows.Next() {
+		inv, err := scanInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, inv)
+	}
+	return out, rows.Err()
+}
+
+func (r *memberRepository) RevokeInvitation(ctx context.Context, tenantID, projectID, id uuid.UUID) error {
+	const query = `
+		UPDATE project_invitations
+		SET revoked_at = now()
+		WHERE id = $3
+		  AND tenant_id = $1
+		  AND project_id = $2
+		  AND accepted_at IS NULL
+		  AND revoked_at IS NULL
+	`
+	tag, err := r.db.Exec(ctx, query, tenantID, projectID, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrInvitationNotFound
+	}
+	return nil
+}
+
+func (r *memberRepository) AcceptInvitation(ctx context.Context, inv *domain.Invitation, userID uuid.UUID, at time.Time) (*domain.Member, error) {
+	var member *domain.Member
+	err := withTx(ctx, r.db, func(tx pgx.Tx) error {
+		const closeInvitation = `
+			UPDATE project_invitations
+			SET accepted_at = $2, accepted_by = $3
+			WHERE id = $1 AND accepted_at IS NULL AND revoked_at IS NULL
+		`
+		tag, err := tx.Exec(ctx, closeInvitation, inv.ID, at, userID)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return domain.ErrInvitationClosed
+		}
+
+		// A member who left is reactivated with the new role; an existing
+		// active or suspended membership is left untouched.
+		const upsertMember = `
+			INSERT INTO project_members (project_id, user_id, role_id, invited_by, invited_at, joined_at, status)
+			VALUES ($1, $2, $3, $4, $5, $6, 'active')
+			ON CONFLICT (project_id, user_id) DO UPDATE
+			SET role_id = EXCLUDED.role_id,
+			    invited_by = EXCLUDED.invited_by,
+			    invited_at = EXCLUDED.invited_at,
+			    joined_at = EXCLUDED.joined_at,
+			    status = 'active',
+			    source = 'manual',
+			    updated_at = now()
+			WHERE project_members.status = 'left'
+			RETURNING id
+		`
+		var id uuid.UUID
+		err = tx.QueryRow(ctx, upsertMember, inv.ProjectID, userID, inv.RoleID, inv.InvitedBy, inv.CreatedAt, at).Scan(&id)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return domain.ErrAlreadyMember
+			}
+			return err
+		}
+
+		query := `SELECT ` + memberColumns + memberFrom + ` WHERE pm.id = $1`
+		member, err = scanMember(tx.QueryRow(ctx, query, id))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+func scanMember(row pgx.Row) (*domain.Member, error) {
+	var m domain.Member
+	var status string
+	err := row.Scan(
+		&m.ID,
+		&m.ProjectID,
+		&m.UserID,
+		&m.Email,
+		&m.FullName,
+		&m.RoleID,
+		&m.RoleKey,
+		&status,
+		&m.InvitedBy,
+		&m.InvitedAt,
+		&m.JoinedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	m.Status = domain.MemberStatus(status)
+	return &m, nil
+}
+
+func scanInvitation(row pgx.Row) (*domain.Invitation, error) {
+	var inv domain.Invitation
+	err := row.Scan(
+		&inv.ID,
+		&inv.TenantID,
+		&inv.ProjectID,
+		&inv.ProjectName,
+		&inv.Email,
+		&inv.RoleID,
+		&inv.RoleKey,
+		&inv.InvitedBy,
+		&inv.ExpiresAt,
+		&inv.AcceptedAt,
+		&inv.AcceptedBy,
+		&inv.RevokedAt,
+		&inv.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// expectOne turns an Exec result into ErrMemberNotFound when no row matched.
+func expectOne(tag pgconn.CommandTag, err error) error {
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrMemberNotFound
+	}
+	return nil
+}
+
+// withTx runs fn inside a transaction, committing on success and rolling
+// back on error or panic.
+func withTx(ctx context.Context, db *pgxpool.Pool, fn func(pgx.Tx) error) (err error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback(ctx)
+			panic(p)
+		}
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			return
+		}
+		err = tx.Commit(ctx)
+	}()
+	return fn(tx)
+}
@@ -1,56 +1,346 @@
 package http
 
 import (
+	"errors"
+	"net/http"
+
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/indalyadav56/logify/apps/backend/internal/user/application"
+	"github.com/indalyadav56/logify/apps/backend/internal/user/domain"
 	"github.com/indalyadav56/logify/apps/backend/pkg/response"
+	"github.com/indalyadav56/logify/apps/backend/pkg/validator"
 )
 
-// UserManagementHandler handles invite/role management endpoints.
+// UserManagementHandler handles project membership and invitation endpoints.
 type UserManagementHandler interface {
 	InviteUser(c *gin.Context)
-	ChangeRole(c *gin.Context)
+	ListInvitations(c *gin.Context)
+	RevokeInvitation(c *gin.Context)
 	AcceptInvite(c *gin.Context)
+
+	ListMembers(c *gin.Context)
+	ChangeRole(c *gin.Context)
+	SuspendMember(c *gin.Context)
+	ReactivateMember(c *gin.Context)
+	RemoveMember(c *gin.Context)
+
+	MyMemberships(c *gin.Context)
 }
 
-type userManagementHandler struct{}
+type userManagementHandler struct {
+	service application.MembershipService
+}
 
-func NewUserManagementHandler() UserManagementHandler {
-	return &userManagementHandler{}
+func NewUserManagementHandler(service application.MembershipService) UserManagementHandler {
+	return &userManagementHandler{service: service}
 }
 
-// InviteUser handles POST /v1/users/invite
+// InviteUser handles POST /v1/projects/:id/invitations
+// @Summary      Invite a member
+// @Description  Email an expiring invitation to join the project with the given role.
+// @Tags         members
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                         true  "Project ID (UUID)"
+// @Param        request  body      application.InviteMemberInput  true  "Invitation payload"
+// @Success      201      {object}  response.APIResponse{data=application.InvitationOutput} "Invitation sent successfully"
+// @Failure      400      {object}  response.APIResponse "Invalid request or role"
+// @Failure      403      {object}  response.APIResponse "Missing permission or member limit reached"
+// @Failure      404      {object}  response.APIResponse "Project not found"
+// @Failure      409      {object}  response.APIResponse "Already a member or invitation pending"
+// @Failure      502      {object}  response.APIResponse "Invitation email could not be sent"
+// @Router       /v1/projects/{id}/invitations [post]
 func (h *userManagementHandler) InviteUser(c *gin.Context) {
-	response.Created(c, "Invitation sent successfully", gin.H{
-		"id":         "inv_01jv4abc123",
-		"email":      "newuser@example.com",
-		"role":       "member",
-		"invited_by": "usr_01jv4kqz8p3e5f7g9m2n",
-		"created_at": "2026-05-07T10:00:00Z",
-	})
+	projectID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var input application.InviteMemberInput
+	if !validator.ValidateRequest(c, &input) {
+		return
+	}
+
+	inv, err := h.service.Invite(c.Request.Context(), projectID, input)
+	if err != nil {
+		writeMembershipError(c, err, "Failed to send invitation")
+		return
+	}
+	response.Created(c, "Invitation sent successfully", inv)
 }
 
-// ChangeRole handles POST /v1/users/:id/role
-func (h *userManagementHandler) ChangeRole(c *gin.Context) {
-	id := c.Param("id")
-	response.OK(c, "User role updated successfully", gin.H{
-		"id":         id,
-		"role":       "member",
-		"updated_at": "2026-05-07T10:00:00Z",
-	})
+// ListInvitations handles GET /v1/projects/:id/invitations
+// @Summary      List open invitations
+// @Description  Return the project's invitations that were neither accepted nor revoked, including expired ones.
+// @Tags         members
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Project ID (UUID)"
+// @Success      200  {object}  response.APIResponse{data=[]application.InvitationOutput} "Invitations retrieved successfully"
+// @Failure      404  {object}  response.APIResponse "Project not found"
+// @Router       /v1/projects/{id}/invitations [get]
+func (h *userManagementHandler) ListInvitations(c *gin.Context) {
+	projectID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	invs, err := h.service.ListInvitations(c.Request.Context(), projectID)
+	if err != nil {
+		writeMembershipError(c, err, "Failed to list invitations")
+		return
+	}
+	response.OK(c, "Invitations retrieved successfully", invs)
+}
+
+// RevokeInvitation handles DELETE /v1/projects/:id/invitations/:invitation_id
+// @Summary      Revoke an invitation
+// @Tags         members
+// @Security     BearerAuth
+// @Param        id             path  string  true  "Project ID (UUID)"
+// @Param        invitation_id  path  string  true  "Invitation ID (UUID)"
+// @Success      204  "No content"
+// @Failure      404  {object}  response.APIResponse "Invitation not found"
+// @Router       /v1/projects/{id}/invitations/{invitation_id} [delete]
+func (h *userManagementHandler) RevokeInvitation(c *gin.Context) {
+	projectID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	invitationID, ok := parseUUIDParam(c, "invitation_id")
+	if !ok {
+		return
+	}
+	if err := h.service.RevokeInvitation(c.Request.Context(), projectID, invitationID); err != nil {
+		writeMembershipError(c, err, "Failed to revoke invitation")
+		return
+	}
+	response.NoContent(c)
 }
 
 // AcceptInvite handles POST /v1/invites/accept
+// @Summary      Accept an invitation
+// @Description  Join the project with the emailed token. Invitees without an account must also send full_name and password; the account is created for the invited email. Afterwards, switch to the returned tenant with POST /v1/auth/switch-tenant.
+// @Tags         members
+// @Accept       json
+// @Produce      json
+// @Param        request  body      application.AcceptInviteInput  true  "Accept payload"
+// @Success      200      {object}  response.APIResponse{data=application.AcceptInviteOutput} "Invitation accepted successfully"
+// @Failure      400      {object}  response.APIResponse "Invalid token or missing account details"
+// @Failure      404      {object}  response.APIResponse "Invitation not found"
+// @Failure      409      {object}  response.APIResponse "Already a member"
+// @Failure      410      {object}  response.APIResponse "Invitation expired, accepted or revoked"
+// @Router       /v1/invites/accept [post]
 func (h *userManagementHandler) AcceptInvite(c *gin.Context) {
-	response.OK(c, "Invitation accepted successfully", gin.H{
-		"user": gin.H{
-			"id":        "usr_01jv4new123",
-			"email":     "newuser@example.com",
-			"full_name": "New User",
-			"role":      "member",
-		},
-		"access_token":  "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.mock",
-		"refresh_token": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.mock-refresh",
-		"token_type":    "Bearer",
-		"expires_in":    3600,
-	})
+	var input application.AcceptInviteInput
+	if !validator.ValidateRequest(c, &input) {
+		return
+	}
+
+	out, err := h.service.AcceptInvite(c.Request.Context(), input)
+	if err != nil {
+		writeMembershipError(c, err, "Failed to accept invitation")
+		return
+	}
+	response.OK(c, "Invitation accepted successfully", out)
+}
+
+// ListMembers handles GET /v1/projects/:id/members
+// @Summary      List project members
+// @Tags         members
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Project ID (UUID)"
+// @Success      200  {object}  response.APIResponse{data=[]application.MemberOutput} "Members retrieved successfully"
+// @Failure      404  {object}  response.APIResponse "Project not found"
+// @Router       /v1/projects/{id}/members [get]
+func (h *userManagementHandler) ListMembers(c *gin.Context) {
+	projectID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	members, err := h.service.ListMembers(c.Request.Context(), projectID)
+	if err != nil {
+		writeMembershipError(c, err, "Failed to list members")
+		return
+	}
+	response.OK(c, "Members retrieved successfully", members)
+}
+
+// ChangeRole handles PUT /v1/projects/:id/members/:user_id/role
+// @Summary      Change a member's role
+// @Tags         members
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                             true  "Project ID (UUID)"
+// @Param        user_id  path      string                             true  "Member user ID (UUID)"
+// @Param        request  body      application.ChangeMemberRoleInput  true  "New role"
+// @Success      200      {object}  response.APIResponse{data=application.MemberOutput} "Member role updated successfully"
+// @Failure      400      {object}  response.APIResponse "Invalid role"
+// @Failure      403      {object}  response.APIResponse "Cannot change own role"
+// @Failure      404      {object}  response.APIResponse "Member not found"
+// @Router       /v1/projects/{id}/members/{user_id}/role [put]
+func (h *userManagementHandler) ChangeRole(c *gin.Context) {
+	projectID, userID, ok := memberParams(c)
+	if !ok {
+		return
+	}
+	var input application.ChangeMemberRoleInput
+	if !validator.ValidateRequest(c, &input) {
+		return
+	}
+
+	member, err := h.service.ChangeRole(c.Request.Context(), projectID, userID, input)
+	if err != nil {
+		writeMembershipError(c, err, "Failed to update member role")
+		return
+	}
+	response.OK(c, "Member role updated successfully", member)
+}
+
+// SuspendMember handles POST /v1/projects/:id/members/:user_id/suspend
+// @Summary      Suspend a member
+// @Description  A suspended member keeps the membership but loses every project permission until reactivated.
+// @Tags         members
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string  true  "Project ID (UUID)"
+// @Param        user_id  path      string  true  "Member user ID (UUID)"
+// @Success      200      {object}  response.APIResponse{data=application.MemberOutput} "Member suspended successfully"
+// @Failure      403      {object}  response.APIResponse "Cannot suspend oneself"
+// @Failure      404      {object}  response.APIResponse "Member not found"
+// @Router       /v1/projects/{id}/members/{user_id}/suspend [post]
+func (h *userManagementHandler) SuspendMember(c *gin.Context) {
+	projectID, userID, ok := memberParams(c)
+	if !ok {
+		return
+	}
+	member, err := h.service.SuspendMember(c.Request.Context(), projectID, userID)
+	if err != nil {
+		writeMembershipError(c, err, "Failed to suspend member")
+		return
+	}
+	response.OK(c, "Member suspended successfully", member)
+}
+
+// ReactivateMember handles POST /v1/projects/:id/members/:user_id/reactivate
+// @Summary      Reactivate a suspended member
+// @Tags         members
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string  true  "Project ID (UUID)"
+// @Param        user_id  path      string  true  "Member user ID (UUID)"
+// @Success      200      {object}  response.APIResponse{data=application.MemberOutput} "Member reactivated successfully"
+// @Failure      404      {object}  response.APIResponse "Member not found"
+// @Router       /v1/projects/{id}/members/{user_id}/reactivate [post]
+func (h *userManagementHandler) ReactivateMember(c *gin.Context) {
+	projectID, userID, ok := memberParams(c)
+	if !ok {
+		return
+	}
+	member, err := h.service.ReactivateMember(c.Request.Context(), projectID, userID)
+	if err != nil {
+		writeMembershipError(c, err, "Failed to reactivate member")
+		return
+	}
+	response.OK(c, "Member reactivated successfully", member)
+}
+
+// RemoveMember handles DELETE /v1/projects/:id/members/:user_id
+// @Summary      Remove a member
+// @Tags         members
+// @Security     BearerAuth
+// @Param        id       path  string  true  "Project ID (UUID)"
+// @Param        user_id  path  string  true  "Member user ID (UUID)"
+// @Success      204  "No content"
+// @Failure      403  {object}  response.APIResponse "Cannot remove oneself"
+// @Failure      404  {object}  response.APIResponse "Member not found"
+// @Router       /v1/projects/{id}/members/{user_id} [delete]
+func (h *userManagementHandler) RemoveMember(c *gin.Context) {
+	projectID, userID, ok := memberParams(c)
+	if !ok {
+		return
+	}
+	if err := h.service.RemoveMember(c.Request.Context(), projectID, userID); err != nil {
+		writeMembershipError(c, err, "Failed to remove member")
+		return
+	}
+	response.NoContent(c)
+}
+
+// MyMemberships handles GET /v1/me/memberships
+// @Summary      List my memberships
+// @Description  Return every project the caller belongs to, across tenants, so a client can offer a tenant switch.
+// @Tags         members
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.APIResponse{data=[]application.MembershipOutput} "Memberships retrieved successfully"
+// @Router       /v1/me/memberships [get]
+func (h *userManagementHandler) MyMemberships(c *gin.Context) {
+	memberships, err := h.service.ListMyMemberships(c.Request.Context())
+	if err != nil {
+		writeMembershipError(c, err, "Failed to list memberships")
+		return
+	}
+	response.OK(c, "Memberships retrieved successfully", memberships)
+}
+
+func writeMembershipError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrTenantRequired):
+		response.Forbidden(c, "Tenant not resolved")
+	case errors.Is(err, domain.ErrProjectNotFound):
+		response.NotFound(c, "Project not found")
+	case errors.Is(err, domain.ErrMemberNotFound):
+		response.NotFound(c, "Member not found")
+	case errors.Is(err, domain.ErrInvitationNotFound):
+		response.NotFound(c, "Invitation not found")
+	case errors.Is(err, domain.ErrRoleNotAssignable):
+		response.BadRequest(c, "Role cannot be assigned to project members")
+	case errors.Is(err, domain.ErrCannotModifySelf):
+		response.Forbidden(c, "You cannot change your own membership")
+	case errors.Is(err, domain.ErrMemberLimitReached):
+		response.Forbidden(c, "The member limit of your plan has been reached")
+	case errors.Is(err, domain.ErrAlreadyMember):
+		response.Conflict(c, "User is already a member of this project")
+	case errors.Is(err, domain.ErrInvitationPending):
+		response.Conflict(c, "An invitation for this email is already pending")
+	case errors.Is(err, domain.ErrUserAlreadyExists):
+		response.Conflict(c, "A user with this email already exists")
+	case errors.Is(err, domain.ErrInvalidInvitationToken):
+		response.BadRequest(c, "Invalid invitation token")
+	case errors.Is(err, domain.ErrAccountDetailsRequired):
+		response.BadRequest(c, "full_name and password are required to create your account")
+	case errors.Is(err, domain.ErrInvitationExpired):
+		response.Error(c, http.StatusGone, "GONE", "Invitation has expired")
+	case errors.Is(err, domain.ErrInvitationClosed):
+		response.Error(c, http.StatusGone, "GONE", "Invitation was already accepted or revoked")
+	case errors.Is(err, domain.ErrInvitationDelivery):
+		response.Error(c, http.StatusBadGateway, "BAD_GATEWAY", "Invitation email could not be sent")
+	default:
+		response.InternalServerError(c, fallback)
+	}
+}
+
+func memberParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	projectID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+	userID, ok := parseUUIDParam(c, "user_id")
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+	return projectID, userID, true
+}
+
+func parseUUIDParam(c *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		response.BadRequest(c, "Invalid "+name+" format")
+		return uuid.Nil, false
+	}
+	return id, true
 }
@@ -2,12 +2,14 @@ package http
 
 import (
 	"github.com/gin-gonic/gin"
+
+	roleDomain "github.com/indalyadav56/logify/apps/backend/internal/role/domain"
 	"github.com/indalyadav56/logify/apps/backend/internal/server/http/middleware"
 	"github.com/indalyadav56/logify/apps/backend/pkg/jwt"
 )
 
 func RegisterRoutes(router *gin.RouterGroup, handler *UserHandler, jwt *jwt.JWT) {
-	auth := middleware.AuthMiddleware(jwt)
+	auth := middleware.AuthMiddleware(jwt, nil)
 
 	users := router.Group("/users")
 	users.Use(auth)
@@ -21,17 +23,32 @@ func RegisterRoutes(router *gin.RouterGroup, handler *UserHandler, jwt *jwt.JWT)
 	}
 }
 
-// // RegisterManagementRoutes sets up user invite and role management routes.
-// func RegisterManagementRoutes(router *gin.RouterGroup, handler UserManagementHandler, jwtSecret string) {
-// 	auth := middleware.AuthMiddleware(jwtSecret)
+// RegisterManagementRoutes mounts project membership routes. The group is
+// expected to be already authenticated (see di.RegisterAllRoutes).
+func RegisterManagementRoutes(router *gin.RouterGroup, handler UserManagementHandler, authz *middleware.Authorizer) {
+	canRead := authz.RequireProjectPermission("id", roleDomain.ResourceMember, roleDomain.ActionRead)
+	canInvite := authz.RequireProjectPermission("id", roleDomain.ResourceMember, roleDomain.ActionInvite)
+	canUpdateRole := authz.RequireProjectPermission("id", roleDomain.ResourceMember, roleDomain.ActionUpdateRole)
+	canRemove := authz.RequireProjectPermission("id", roleDomain.ResourceMember, roleDomain.ActionRemove)
+
+	router.GET("/v1/me/memberships", handler.MyMemberships)
 
-// 	users := router.Group("/users")
-// 	users.Use(auth)
-// 	{
-// 		users.POST("/invite", handler.InviteUser)
-// 		users.POST("/:id/role", handler.ChangeRole)
-// 	}
+	project := router.Group("/v1/projects/:id")
+	{
+		project.GET("/members", canRead, handler.ListMembers)
+		project.PUT("/members/:user_id/role", canUpdateRole, handler.ChangeRole)
+		project.POST("/members/:user_id/suspend", canRemove, handler.SuspendMember)
+		project.POST("/members/:user_id/reactivate", canRemove, handler.ReactivateMember)
+		project.DELETE("/members/:user_id", canRemove, handler.RemoveMember)
 
-// 	// No auth required for invite acceptance
-// 	router.POST("/invites/accept", handler.AcceptInvite)
-// }
+		project.GET("/invitations", canRead, handler.ListInvitations)
+		project.POST("/invitations", canInvite, handler.InviteUser)
+		project.DELETE("/invitations/:invitation_id", canInvite, handler.RevokeInvitation)
+	}
+}
+
+// RegisterInviteRoutes mounts invitation acceptance, which needs no token:
+// the signed invitation token authenticates the request.
+func RegisterInviteRoutes(router *gin.RouterGroup, handler UserManagementHandler) {
+	router.POST("/v1/invites/accept", handler.AcceptInvite)
+}
@@ -0,0 +1,23 @@
+-- +goose Up
+-- Retention becomes a per-row value stamped at ingest from the project's
+-- policy. Rows written before this migration read the column default, 90
+-- days, which is exactly the TTL they had, so nothing expires earlier than
+-- it would have. Applying a policy re-stamps a project's stored rows.
+-- +goose StatementBegin
+ALTER TABLE logify.logs
+    ADD COLUMN IF NOT EXISTS retention_days UInt16 DEFAULT 90 CODEC (ZSTD (1)) AFTER ingestion_time;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE logify.logs
+    MODIFY TTL toDateTime (timestamp) + toIntervalDay (retention_days);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE logify.logs MODIFY TTL timestamp + INTERVAL 90 DAY;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE logify.logs DROP COLUMN IF EXISTS retention_days;
+-- +goose StatementEnd
@@ -0,0 +1,66 @@
+-- +goose Up
+-- Per-minute log counts by the dimensions dashboards group and filter on.
+-- Histograms over days read these instead of re-scanning raw logs. Rows of
+-- a key written by different inserts are summed on merge, so readers must
+-- still sum(logs) ... GROUP BY.
+--
+-- retention_days follows the raw rows: the largest retention of the rows
+-- counted, and retention policy changes update it as they do logify.logs.
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS logify.logs_rollup_1m (
+    tenant_id LowCardinality (String),
+    project_id LowCardinality (String),
+    minute DateTime ('UTC'),
+    service LowCardinality (String),
+    level LowCardinality (String),
+    environment LowCardinality (String),
+    host LowCardinality (String),
+    logs SimpleAggregateFunction (sum, UInt64),
+    retention_days SimpleAggregateFunction (max, UInt16)
+) ENGINE = AggregatingMergeTree
+PARTITION BY
+    toYYYYMM (minute)
+ORDER BY (
+        tenant_id,
+        project_id,
+        minute,
+        service,
+        level,
+        environment,
+        host
+    ) TTL minute + toIntervalDay (retention_days);
+-- +goose StatementEnd
+
+-- Counts every insert into logify.logs from now on. Logs already stored
+-- are counted by `migrator -driver clickhouse backfill-rollups`.
+-- +goose StatementBegin
+CREATE MATERIALIZED VIEW IF NOT EXISTS logify.logs_rollup_1m_mv TO logify.logs_rollup_1m AS
+SELECT
+    tenant_id,
+    project_id,
+    toStartOfMinute (timestamp) AS minute,
+    service,
+    level,
+    environment,
+    host,
+    count() AS logs,
+    max(retention_days) AS retention_days
+FROM logify.logs
+GROUP BY
+    tenant_id,
+    project_id,
+    minute,
+    service,
+    level,
+    environment,
+    host;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP VIEW IF EXISTS logify.logs_rollup_1m_mv;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS logify.logs_rollup_1m;
+-- +goose StatementEnd
@@ -0,0 +1,72 @@
+-- +goose Up
+-- sample_rate is stamped at ingest from the project's sampling rules: the
+-- number of logs a stored row stands for. Rows written before sampling read
+-- the default, 1, which is exactly what they stand for.
+-- +goose StatementBegin
+ALTER TABLE logify.logs
+    ADD COLUMN IF NOT EXISTS sample_rate UInt32 DEFAULT 1 CODEC (ZSTD (1)) AFTER retention_days;
+-- +goose StatementEnd
+
+-- The rollups count the logs stood for next to the rows stored, so weighted
+-- aggregations are served from them too. Counts already rolled up were of
+-- unsampled rows, so weighted_logs defaults to logs.
+-- +goose StatementBegin
+ALTER TABLE logify.logs_rollup_1m
+    ADD COLUMN IF NOT EXISTS weighted_logs SimpleAggregateFunction (sum, UInt64) DEFAULT logs;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE logify.logs_rollup_1m_mv MODIFY QUERY
+SELECT
+    tenant_id,
+    project_id,
+    toStartOfMinute (timestamp) AS minute,
+    service,
+    level,
+    environment,
+    host,
+    count() AS logs,
+    max(retention_days) AS retention_days,
+    sum(sample_rate) AS weighted_logs
+FROM logify.logs
+GROUP BY
+    tenant_id,
+    project_id,
+    minute,
+    service,
+    level,
+    environment,
+    host;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE logify.logs_rollup_1m_mv MODIFY QUERY
+SELECT
+    tenant_id,
+    project_id,
+    toStartOfMinute (timestamp) AS minute,
+    service,
+    level,
+    environment,
+    host,
+    count() AS logs,
+    max(retention_days) AS retention_days
+FROM logify.logs
+GROUP BY
+    tenant_id,
+    project_id,
+    minute,
+    service,
+    level,
+    environment,
+    host;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE logify.logs_rollup_1m DROP COLUMN IF EXISTS weighted_logs;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE logify.logs DROP COLUMN IF EXISTS sample_rate;
+-- +goose StatementEnd
@@ -0,0 +1,131 @@
+-- +goose Up
+-- attributes holds every value as a string, so numbers can neither be
+-- compared nor summed. The log-processor now also writes the attributes
+-- whose values are numbers or booleans, typed, to parallel maps. Their
+-- DEFAULT types a row by the same rules (processor/domain.TypeAttributes),
+-- so rows stored before this migration, and rehydrated ones, read typed too.
+-- +goose StatementBegin
+ALTER TABLE logify.logs
+    ADD COLUMN IF NOT EXISTS attributes_number Map (String, Float64) DEFAULT CAST(
+        mapApply((k, v) -> (k, toFloat64(v)), mapFilter((k, v) ->
+            match(v, '^-?[0-9]+(\\.[0-9]+)?([eE][-+]?[0-9]+)?$') AND isFinite(toFloat64OrZero(v)), attributes)),
+        'Map(String, Float64)'
+    ) CODEC (ZSTD (1)) AFTER attributes;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE logify.logs
+    ADD COLUMN IF NOT EXISTS attributes_bool Map (String, Bool) DEFAULT CAST(
+        mapApply((k, v) -> (k, v = 'true'), mapFilter((k, v) -> v IN ('true', 'false'), attributes)),
+        'Map(String, Bool)'
+    ) CODEC (ZSTD (1)) AFTER attributes_number;
+-- +goose StatementEnd
+
+-- Numeric filters first check the key is present: a missing key reads 0.
+-- +goose StatementBegin
+ALTER TABLE logify.logs
+    ADD INDEX IF NOT EXISTS idx_attribute_number_keys mapKeys(attributes_number) TYPE bloom_filter (0.01) GRANULARITY 1;
+-- +goose StatementEnd
+
+-- The field catalog: per project and UTC day, every attribute and tag key
+-- seen, with its type, when it was first and last seen, how many logs had
+-- it and a sketch of its distinct values. A key whose values are of several
+-- types has a row per type. Rows of a key written by different inserts are
+-- merged, so readers must still aggregate ... GROUP BY.
+--
+-- retention_days follows the raw rows, as for the rollups. Logs stored before
+-- this migration are catalogued by `migrator -driver clickhouse backfill-fields`.
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS logify.log_fields (
+    tenant_id LowCardinality (String),
+    project_id LowCardinality (String),
+    day Date,
+    field String,
+    type LowCardinality (String),
+    first_seen SimpleAggregateFunction (min, DateTime64 (3, 'UTC')),
+    last_seen SimpleAggregateFunction (max, DateTime64 (3, 'UTC')),
+    logs SimpleAggregateFunction (sum, UInt64),
+    distinct_values AggregateFunction (uniq, String),
+    retention_days SimpleAggregateFunction (max, UInt16)
+) ENGINE = AggregatingMergeTree
+PARTITION BY
+    toYYYYMM (day)
+ORDER BY (
+        tenant_id,
+        project_id,
+        field,
+        type,
+        day
+    ) TTL day + toIntervalDay (retention_days);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE MATERIALIZED VIEW IF NOT EXISTS logify.log_fields_attributes_mv TO logify.log_fields AS
+SELECT
+    tenant_id,
+    project_id,
+    toDate (timestamp) AS day,
+    concat('attributes.', key) AS field,
+    multiIf(mapContains(attributes_number, key), 'number', mapContains(attributes_bool, key), 'bool', 'string') AS type,
+    min(timestamp) AS first_seen,
+    max(timestamp) AS last_seen,
+    count() AS logs,
+    uniqState (value) AS distinct_values,
+    max(retention_days) AS retention_days
+FROM logify.logs
+ARRAY JOIN mapKeys(attributes) AS key, mapValues(attributes) AS value
+GROUP BY
+    tenant_id,
+    project_id,
+    day,
+    field,
+    type;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE MATERIALIZED VIEW IF NOT EXISTS logify.log_fields_tags_mv TO logify.log_fields AS
+SELECT
+    tenant_id,
+    project_id,
+    toDate (timestamp) AS day,
+    concat('tags.', key) AS field,
+    'string' AS type,
+    min(timestamp) AS first_seen,
+    max(timestamp) AS last_seen,
+    count() AS logs,
+    uniqState (value) AS distinct_values,
+    max(retention_days) AS retention_days
+FROM logify.logs
+ARRAY JOIN mapKeys(tags) AS key, mapValues(tags) AS value
+GROUP BY
+    tenant_id,
+    project_id,
+    day,
+    field,
+    type;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP VIEW IF EXISTS logify.log_fields_tags_mv;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP VIEW IF EXISTS logify.log_fields_attributes_mv;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS logify.log_fields;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE logify.logs DROP INDEX IF EXISTS idx_attribute_number_keys;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE logify.logs DROP COLUMN IF EXISTS attributes_bool;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE logify.logs DROP COLUMN IF EXISTS attributes_number;
+-- +goose StatementEnd
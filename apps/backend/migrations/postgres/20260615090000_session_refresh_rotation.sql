@@ -0,0 +1,37 @@
+-- +goose Up
+ALTER TABLE auth.sessions
+    ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS revoked_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user_active ON auth.sessions (user_id)
+WHERE
+    revoked_at IS NULL;
+
+-- A session is one refresh-token family: every rotation stays on the same
+-- session_id and records the hash it replaced in `parent`.
+ALTER TABLE auth.refresh_tokens
+    ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS used_at TIMESTAMPTZ;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refresh_tokens_token ON auth.refresh_tokens (token);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_session_id ON auth.refresh_tokens (session_id);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON auth.refresh_tokens (user_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS auth.idx_refresh_tokens_user_id;
+
+DROP INDEX IF EXISTS auth.idx_refresh_tokens_session_id;
+
+DROP INDEX IF EXISTS auth.idx_refresh_tokens_token;
+
+ALTER TABLE auth.refresh_tokens
+    DROP COLUMN IF EXISTS used_at,
+    DROP COLUMN IF EXISTS expires_at;
+
+DROP INDEX IF EXISTS auth.idx_sessions_user_active;
+
+ALTER TABLE auth.sessions
+    DROP COLUMN IF EXISTS revoked_at,
+    DROP COLUMN IF EXISTS expires_at;
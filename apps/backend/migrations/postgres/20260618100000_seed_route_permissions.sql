@@ -0,0 +1,118 @@
+-- +goose Up
+-- Permission keys checked by middleware.RequirePermission. Keys are
+-- "<resource>.<action>" using the vocabulary in role/domain/permission.go;
+-- holding "<resource>.manage" implies every action on the resource.
+INSERT INTO
+    auth.permissions (key, description, scope)
+VALUES
+    ('log.read', 'Search and aggregate logs', 'workspace'),
+    ('log.write', 'Ingest logs', 'workspace'),
+    ('log.export', 'Export logs', 'workspace'),
+    ('project.read', 'View projects', 'workspace'),
+    ('project.write', 'Create and update projects', 'workspace'),
+    ('project.delete', 'Delete projects', 'workspace'),
+    ('role.read', 'View roles and their permissions', 'workspace'),
+    ('role.manage', 'Create, modify and delete roles', 'workspace'),
+    ('alert.read', 'View alert rules', 'workspace'),
+    ('alert.write', 'Create and modify alert rules', 'workspace'),
+    ('notification.read', 'View notification channels and notifications', 'workspace'),
+    ('notification.write', 'Manage notification channels and acknowledge notifications', 'workspace')
+ON CONFLICT (key) DO NOTHING;
+
+-- super_admin: everything
+INSERT INTO
+    auth.role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM auth.roles r
+    CROSS JOIN auth.permissions p
+WHERE
+    r.key = 'super_admin'
+ON CONFLICT DO NOTHING;
+
+-- owner, admin: all of the above
+INSERT INTO
+    auth.role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM auth.roles r
+    CROSS JOIN auth.permissions p
+WHERE
+    r.key IN ('owner', 'admin')
+    AND p.key IN (
+        'log.read',
+        'log.write',
+        'log.export',
+        'project.read',
+        'project.write',
+        'project.delete',
+        'role.read',
+        'role.manage',
+        'alert.read',
+        'alert.write',
+        'notification.read',
+        'notification.write'
+    )
+ON CONFLICT DO NOTHING;
+
+-- member: day-to-day work, no deletes or role management
+INSERT INTO
+    auth.role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM auth.roles r
+    CROSS JOIN auth.permissions p
+WHERE
+    r.key = 'member'
+    AND p.key IN (
+        'log.read',
+        'log.write',
+        'log.export',
+        'project.read',
+        'project.write',
+        'role.read',
+        'alert.read',
+        'alert.write',
+        'notification.read',
+        'notification.write'
+    )
+ON CONFLICT DO NOTHING;
+
+-- viewer: read only
+INSERT INTO
+    auth.role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM auth.roles r
+    CROSS JOIN auth.permissions p
+WHERE
+    r.key = 'viewer'
+    AND p.key IN (
+        'log.read',
+        'project.read',
+        'role.read',
+        'alert.read',
+        'notification.read'
+    )
+ON CONFLICT DO NOTHING;
+
+-- Effective-permission lookups filter project_members by user and project.
+CREATE INDEX IF NOT EXISTS idx_project_members_user_project ON project_members (user_id, project_id)
+WHERE
+    status = 'active';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_project_members_user_project;
+
+DELETE FROM auth.permissions
+WHERE
+    key IN (
+        'log.read',
+        'log.write',
+        'log.export',
+        'project.read',
+        'project.write',
+        'project.delete',
+        'role.read',
+        'role.manage',
+        'alert.read',
+        'alert.write',
+        'notification.read',
+        'notification.write'
+    );
@@ -0,0 +1,25 @@
+-- +goose Up
+-- System roles stay global (tenant_id IS NULL); custom roles belong to the
+-- tenant that created them and are invisible to every other tenant.
+ALTER TABLE auth.roles ADD COLUMN IF NOT EXISTS tenant_id UUID;
+
+ALTER TABLE auth.roles DROP CONSTRAINT IF EXISTS roles_key_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_roles_global_key ON auth.roles (key)
+WHERE
+    tenant_id IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_roles_tenant_key ON auth.roles (tenant_id, key)
+WHERE
+    tenant_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS auth.idx_roles_tenant_key;
+
+DROP INDEX IF EXISTS auth.idx_roles_global_key;
+
+DELETE FROM auth.roles WHERE tenant_id IS NOT NULL;
+
+ALTER TABLE auth.roles DROP COLUMN IF EXISTS tenant_id;
+
+ALTER TABLE auth.roles ADD CONSTRAINT roles_key_key UNIQUE (key);
@@ -0,0 +1,88 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS tenants (
+    id UUID PRIMARY KEY DEFAULT uuidv7 (),
+    name VARCHAR(255) NOT NULL,
+    slug VARCHAR(64) NOT NULL UNIQUE CHECK (
+        slug ~ '^[a-z0-9][a-z0-9-]{1,62}[a-z0-9]$'
+    ),
+    plan VARCHAR(20) NOT NULL DEFAULT 'free' CHECK (
+        plan IN ('free', 'pro', 'enterprise')
+    ),
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (
+        status IN ('active', 'suspended')
+    ),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_tenants_status ON tenants (status)
+WHERE
+    deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS tenant_quotas (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants (id) ON DELETE CASCADE,
+    daily_ingest_gb NUMERIC(12, 3) NOT NULL CHECK (daily_ingest_gb > 0),
+    retention_days INT NOT NULL CHECK (retention_days > 0),
+    max_projects INT NOT NULL CHECK (max_projects > 0),
+    max_members INT NOT NULL CHECK (max_members > 0),
+    queries_per_minute INT NOT NULL CHECK (queries_per_minute > 0),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT (now() AT TIME ZONE 'utc')
+);
+
+-- Every existing user owns the personal tenant whose id equals their user id
+-- (the tenant_id claim); give those tenants a row on the free plan. Defaults
+-- mirror tenant/domain/quota.go.
+INSERT INTO
+    tenants (id, name, slug)
+SELECT u.id, u.full_name, 'u-' || replace(u.id::text, '-', '')
+FROM auth.users u
+WHERE
+    u.deleted_at IS NULL
+ON CONFLICT DO NOTHING;
+
+INSERT INTO
+    tenant_quotas (
+        tenant_id,
+        daily_ingest_gb,
+        retention_days,
+        max_projects,
+        max_members,
+        queries_per_minute
+    )
+SELECT t.id, 1, 7, 3, 5, 60
+FROM tenants t
+ON CONFLICT DO NOTHING;
+
+-- Platform permissions for the tenant admin API. Platform-scoped keys cannot
+-- be granted to tenant roles.
+INSERT INTO
+    auth.permissions (key, description, scope)
+VALUES
+    ('tenant.read', 'View all tenants, plans and quotas', 'platform'),
+    ('tenant.manage', 'Create, suspend and delete tenants; change plans and quotas', 'platform')
+ON CONFLICT (key) DO NOTHING;
+
+INSERT INTO
+    auth.role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM auth.roles r
+    CROSS JOIN auth.permissions p
+WHERE (
+        r.key = 'super_admin'
+        AND p.key IN ('tenant.read', 'tenant.manage')
+    )
+    OR (
+        r.key = 'support_agent'
+        AND p.key = 'tenant.read'
+    )
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DELETE FROM auth.permissions
+WHERE
+    key IN ('tenant.read', 'tenant.manage');
+
+DROP TABLE IF EXISTS tenant_quotas;
+
+DROP TABLE IF EXISTS tenants;
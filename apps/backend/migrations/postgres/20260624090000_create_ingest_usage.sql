@@ -0,0 +1,19 @@
+-- +goose Up
+-- Daily ingest accounting per project, rolled up from the Redis counters the
+-- ingest rate limiter maintains. Values are cumulative for the UTC day.
+CREATE TABLE IF NOT EXISTS ingest_usage_daily (
+    tenant_id UUID NOT NULL,
+    project_id UUID NOT NULL,
+    day DATE NOT NULL,
+    bytes BIGINT NOT NULL DEFAULT 0,
+    events BIGINT NOT NULL DEFAULT 0,
+    dropped_events BIGINT NOT NULL DEFAULT 0,
+    rejected_events BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    PRIMARY KEY (tenant_id, project_id, day)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ingest_usage_daily_tenant_day ON ingest_usage_daily (tenant_id, day DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS ingest_usage_daily;
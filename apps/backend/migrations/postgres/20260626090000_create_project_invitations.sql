@@ -0,0 +1,54 @@
+-- +goose Up
+-- One membership row per (project, user). Removing a member flips the row to
+-- 'left' so a later invitation can reactivate it.
+DELETE FROM project_members pm
+USING project_members newer
+WHERE
+    pm.project_id = newer.project_id
+    AND pm.user_id = newer.user_id
+    AND pm.id < newer.id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_project_members_project_user ON project_members (project_id, user_id);
+
+ALTER TABLE project_members
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+-- Invitations are addressed to an email, not a user: the invitee may not have
+-- an account yet. The emailed token is signed and carries the invitation id;
+-- this row decides whether it can still be used.
+CREATE TABLE IF NOT EXISTS project_invitations (
+    id UUID PRIMARY KEY DEFAULT uuidv7 (),
+    tenant_id UUID NOT NULL REFERENCES tenants (id) ON DELETE CASCADE,
+    project_id UUID NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    role_id UUID NOT NULL REFERENCES auth.roles (id) ON DELETE RESTRICT,
+    invited_by UUID NOT NULL REFERENCES auth.users (id),
+    expires_at TIMESTAMPTZ NOT NULL,
+    accepted_at TIMESTAMPTZ,
+    accepted_by UUID REFERENCES auth.users (id),
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- At most one open invitation per address and project.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_project_invitations_open ON project_invitations (project_id, lower(email))
+WHERE
+    accepted_at IS NULL
+    AND revoked_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_project_invitations_tenant ON project_invitations (tenant_id)
+WHERE
+    accepted_at IS NULL
+    AND revoked_at IS NULL;
+
+-- The tenant a session acts in. NULL is the user's personal tenant.
+ALTER TABLE auth.sessions ADD COLUMN IF NOT EXISTS tenant_id UUID;
+
+-- +goose Down
+ALTER TABLE auth.sessions DROP COLUMN IF EXISTS tenant_id;
+
+DROP TABLE IF EXISTS project_invitations;
+
+ALTER TABLE project_members DROP COLUMN IF EXISTS updated_at;
+
+DROP INDEX IF EXISTS idx_project_members_project_user;
@@ -0,0 +1,31 @@
+-- +goose Up
+-- Links between accounts and subjects at an OpenID provider. The subject is
+-- stable at its issuer; the email is informational only.
+CREATE TABLE IF NOT EXISTS auth.user_identities (
+    id UUID PRIMARY KEY DEFAULT uuidv7 (),
+    user_id UUID NOT NULL REFERENCES auth.users (id) ON DELETE CASCADE,
+    issuer TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    email VARCHAR(255),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    last_login_at TIMESTAMPTZ,
+    CONSTRAINT user_identities_issuer_subject_key UNIQUE (issuer, subject)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_identities_user ON auth.user_identities (user_id);
+
+-- Memberships granted from identity provider groups are resynced at every
+-- SSO login; hand-made ones are left alone.
+ALTER TABLE project_members
+ADD COLUMN IF NOT EXISTS source VARCHAR(20) NOT NULL DEFAULT 'manual' CHECK (source IN ('manual', 'sso'));
+
+-- Tenants enforcing SSO refuse password logins of their members.
+ALTER TABLE tenants
+ADD COLUMN IF NOT EXISTS sso_enforced BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE tenants DROP COLUMN IF EXISTS sso_enforced;
+
+ALTER TABLE project_members DROP COLUMN IF EXISTS source;
+
+DROP TABLE IF EXISTS auth.user_identities;
@@ -0,0 +1,71 @@
+-- +goose Up
+-- Non-human principals. A service account's id is used as the principal id
+-- in claims and permission checks, like a user id; it holds one
+-- tenant-wide role.
+CREATE TABLE IF NOT EXISTS auth.service_accounts (
+    id UUID PRIMARY KEY DEFAULT uuidv7 (),
+    tenant_id UUID NOT NULL REFERENCES tenants (id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    role_id UUID NOT NULL REFERENCES auth.roles (id) ON DELETE RESTRICT,
+    created_by UUID NOT NULL,
+    disabled_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT (now() AT TIME ZONE 'utc')
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_service_accounts_tenant_name ON auth.service_accounts (tenant_id, lower(name));
+
+-- Personal access tokens of users and service accounts. Only the SHA-256 of
+-- the token is stored.
+CREATE TABLE IF NOT EXISTS auth.access_tokens (
+    id UUID PRIMARY KEY DEFAULT uuidv7 (),
+    tenant_id UUID NOT NULL REFERENCES tenants (id) ON DELETE CASCADE,
+    user_id UUID REFERENCES auth.users (id) ON DELETE CASCADE,
+    service_account_id UUID REFERENCES auth.service_accounts (id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    token_prefix VARCHAR(16) NOT NULL,
+    scopes TEXT[] NOT NULL CHECK (cardinality(scopes) > 0),
+    created_by UUID NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    CONSTRAINT access_tokens_one_owner CHECK ((user_id IS NULL) <> (service_account_id IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_access_tokens_user ON auth.access_tokens (user_id)
+WHERE
+    user_id IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_access_tokens_service_account ON auth.access_tokens (service_account_id)
+WHERE
+    service_account_id IS NOT NULL;
+
+INSERT INTO
+    auth.permissions (key, description, scope)
+VALUES
+    ('service_account.read', 'View service accounts and their tokens', 'workspace'),
+    ('service_account.manage', 'Create, modify and delete service accounts and their tokens', 'workspace')
+ON CONFLICT (key) DO NOTHING;
+
+INSERT INTO
+    auth.role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM auth.roles r
+    CROSS JOIN auth.permissions p
+WHERE
+    r.key IN ('super_admin', 'owner', 'admin')
+    AND r.tenant_id IS NULL
+    AND p.key IN ('service_account.read', 'service_account.manage')
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DELETE FROM auth.permissions
+WHERE
+    key IN ('service_account.read', 'service_account.manage');
+
+DROP TABLE IF EXISTS auth.access_tokens;
+
+DROP TABLE IF EXISTS auth.service_accounts;
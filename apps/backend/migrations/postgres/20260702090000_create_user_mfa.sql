@@ -0,0 +1,32 @@
+-- +goose Up
+-- TOTP second factor. The secret is encrypted by the application; an
+-- enrolment stays pending (enabled_at NULL) until the first code verifies.
+-- last_used_step stops a code from being accepted twice.
+CREATE TABLE IF NOT EXISTS auth.user_mfa (
+    user_id UUID PRIMARY KEY REFERENCES auth.users (id) ON DELETE CASCADE,
+    secret BYTEA NOT NULL,
+    enabled_at TIMESTAMPTZ,
+    last_used_step BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT (now() AT TIME ZONE 'utc')
+);
+
+-- One-time recovery codes, stored as SHA-256 hashes.
+CREATE TABLE IF NOT EXISTS auth.mfa_recovery_codes (
+    id UUID PRIMARY KEY DEFAULT uuidv7 (),
+    user_id UUID NOT NULL REFERENCES auth.users (id) ON DELETE CASCADE,
+    code_hash CHAR(64) NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    UNIQUE (user_id, code_hash)
+);
+
+ALTER TABLE tenants
+ADD COLUMN IF NOT EXISTS mfa_required BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE tenants DROP COLUMN IF EXISTS mfa_required;
+
+DROP TABLE IF EXISTS auth.mfa_recovery_codes;
+
+DROP TABLE IF EXISTS auth.user_mfa;
@@ -0,0 +1,102 @@
+-- +goose Up
+CREATE SCHEMA IF NOT EXISTS audit;
+
+-- Audit trail of API calls and security events. Rows are never updated;
+-- only the retention purge deletes them (see audit.events_append_only).
+-- tenant_id and actor_id carry no foreign keys so records outlive the
+-- tenants and users they mention.
+CREATE TABLE IF NOT EXISTS audit.events (
+    id UUID PRIMARY KEY DEFAULT uuidv7 (),
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    action VARCHAR(100) NOT NULL,
+    outcome VARCHAR(20) NOT NULL,
+    actor_type VARCHAR(20) NOT NULL,
+    actor_id UUID,
+    tenant_id UUID,
+    project_id UUID,
+    target_type VARCHAR(50) NOT NULL DEFAULT '',
+    target_id VARCHAR(255) NOT NULL DEFAULT '',
+    before JSONB,
+    after JSONB,
+    metadata JSONB,
+    ip_address INET,
+    user_agent TEXT NOT NULL DEFAULT '',
+    request_id VARCHAR(100) NOT NULL DEFAULT '',
+    method VARCHAR(10) NOT NULL DEFAULT '',
+    route VARCHAR(255) NOT NULL DEFAULT '',
+    status INT
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_tenant ON audit.events (tenant_id, occurred_at DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor ON audit.events (actor_id, occurred_at DESC)
+WHERE
+    actor_id IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_target ON audit.events (target_type, target_id);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_occurred_at ON audit.events (occurred_at);
+
+-- Deletes are allowed only inside a transaction that opted in with
+-- SET LOCAL audit.allow_purge = 'on', which the retention purge does.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION audit.events_append_only () RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'DELETE' AND current_setting('audit.allow_purge', true) = 'on' THEN
+        RETURN OLD;
+    END IF;
+    RAISE EXCEPTION 'audit.events is append-only (% rejected)', TG_OP;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER audit_events_append_only
+BEFORE UPDATE OR DELETE ON audit.events
+FOR EACH ROW EXECUTE FUNCTION audit.events_append_only ();
+
+CREATE TRIGGER audit_events_no_truncate
+BEFORE TRUNCATE ON audit.events
+FOR EACH STATEMENT EXECUTE FUNCTION audit.events_append_only ();
+
+-- Searches of sensitive projects are audited.
+ALTER TABLE projects
+ADD COLUMN IF NOT EXISTS sensitive BOOLEAN NOT NULL DEFAULT false;
+
+INSERT INTO
+    auth.permissions (key, description, scope)
+VALUES
+    ('audit.read', 'View the audit trail of the workspace', 'workspace'),
+    ('platform.audit.read', 'View the audit trail of every tenant', 'platform')
+ON CONFLICT (key) DO NOTHING;
+
+INSERT INTO
+    auth.role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM auth.roles r
+    CROSS JOIN auth.permissions p
+WHERE
+    r.tenant_id IS NULL
+    AND (
+        (
+            r.key IN ('super_admin', 'owner', 'admin')
+            AND p.key = 'audit.read'
+        )
+        OR (
+            r.key IN ('super_admin', 'support_agent')
+            AND p.key = 'platform.audit.read'
+        )
+    )
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DELETE FROM auth.permissions
+WHERE
+    key IN ('audit.read', 'platform.audit.read');
+
+ALTER TABLE projects DROP COLUMN IF EXISTS sensitive;
+
+DROP TABLE IF EXISTS audit.events;
+
+DROP FUNCTION IF EXISTS audit.events_append_only ();
+
+DROP SCHEMA IF EXISTS audit;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Per-project log retention. level '' is the project default; other rows
+-- override it for one canonical level (trace, debug, info, warn, error,
+-- fatal). Projects without rows keep logs for the tenant quota's
+-- retention_days, which also caps every row here.
+CREATE TABLE IF NOT EXISTS project_retention_policies (
+    project_id UUID NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL,
+    level VARCHAR(20) NOT NULL DEFAULT '',
+    retention_days INT NOT NULL CHECK (retention_days BETWEEN 1 AND 3650),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    PRIMARY KEY (project_id, level)
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_retention_policies_tenant ON project_retention_policies (tenant_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS project_retention_policies;
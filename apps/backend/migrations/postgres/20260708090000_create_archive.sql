@@ -0,0 +1,66 @@
+-- +goose Up
+-- Index of the logs archived to object storage: one segment per tenant and
+-- UTC day. Segments outlive the ClickHouse rows they were exported from.
+CREATE TABLE IF NOT EXISTS archive_segments (
+    tenant_id UUID NOT NULL,
+    day DATE NOT NULL,
+    object_key TEXT NOT NULL,
+    manifest_key TEXT NOT NULL,
+    format VARCHAR(20) NOT NULL,
+    rows BIGINT NOT NULL,
+    bytes BIGINT NOT NULL,
+    sha256 CHAR(64) NOT NULL,
+    min_time TIMESTAMPTZ,
+    max_time TIMESTAMPTZ,
+    projects TEXT[] NOT NULL DEFAULT '{}',
+    job_id UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    PRIMARY KEY (tenant_id, day)
+);
+
+-- Archive and rehydration jobs. No foreign keys: the worker must still find
+-- the rehydrated tables of deleted tenants and projects to drop them.
+CREATE TABLE IF NOT EXISTS archive_jobs (
+    id UUID PRIMARY KEY DEFAULT uuidv7 (),
+    tenant_id UUID NOT NULL,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('archive', 'rehydrate')),
+    status VARCHAR(20) NOT NULL CHECK (
+        status IN ('pending', 'running', 'succeeded', 'failed', 'expired')
+    ),
+    project_id UUID,
+    range_from TIMESTAMPTZ NOT NULL,
+    range_to TIMESTAMPTZ NOT NULL,
+    table_name VARCHAR(100) NOT NULL DEFAULT '',
+    rows BIGINT NOT NULL DEFAULT 0,
+    bytes BIGINT NOT NULL DEFAULT 0,
+    segments INT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    attempts INT NOT NULL DEFAULT 0,
+    requested_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ
+);
+
+-- One archive job per tenant-day; claiming it is an upsert on this index.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_archive_jobs_day ON archive_jobs (tenant_id, range_from)
+WHERE
+    kind = 'archive';
+
+CREATE INDEX IF NOT EXISTS idx_archive_jobs_tenant ON archive_jobs (tenant_id, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_archive_jobs_pending ON archive_jobs (created_at)
+WHERE
+    kind = 'rehydrate'
+    AND status = 'pending';
+
+CREATE INDEX IF NOT EXISTS idx_archive_jobs_expiry ON archive_jobs (expires_at)
+WHERE
+    kind = 'rehydrate'
+    AND status = 'succeeded';
+
+-- +goose Down
+DROP TABLE IF EXISTS archive_jobs;
+
+DROP TABLE IF EXISTS archive_segments;
@@ -0,0 +1,34 @@
+-- +goose Up
+-- Per-project ingest pipelines, applied by the log-processor before logs
+-- are stored. A project's enabled pipelines run by ascending position.
+-- version is the latest row of ingest_pipeline_versions, which the
+-- processor runs; older versions are kept for history and rollback.
+CREATE TABLE IF NOT EXISTS ingest_pipelines (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    project_id UUID NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    position INT NOT NULL DEFAULT 0,
+    version INT NOT NULL DEFAULT 1,
+    created_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    UNIQUE (project_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ingest_pipelines_tenant ON ingest_pipelines (tenant_id);
+
+CREATE TABLE IF NOT EXISTS ingest_pipeline_versions (
+    pipeline_id UUID NOT NULL REFERENCES ingest_pipelines (id) ON DELETE CASCADE,
+    version INT NOT NULL,
+    processors JSONB NOT NULL,
+    created_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    PRIMARY KEY (pipeline_id, version)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS ingest_pipeline_versions;
+DROP TABLE IF EXISTS ingest_pipelines;
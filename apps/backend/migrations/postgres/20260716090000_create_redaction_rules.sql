@@ -0,0 +1,33 @@
+-- +goose Up
+-- Redaction rules, applied by the log-processor and the embedding worker
+-- before logs are stored or embedded. Rules without a project_id are the
+-- tenant's and apply to every project.
+CREATE TABLE IF NOT EXISTS redaction_rules (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    project_id UUID REFERENCES projects (id) ON DELETE CASCADE,
+    name VARCHAR(64) NOT NULL,
+    detector VARCHAR(32) NOT NULL,
+    pattern TEXT NOT NULL DEFAULT '',
+    action VARCHAR(16) NOT NULL,
+    fields TEXT[] NOT NULL DEFAULT '{}',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    UNIQUE NULLS NOT DISTINCT (tenant_id, project_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_redaction_rules_scope ON redaction_rules (tenant_id, project_id);
+
+-- How often each rule fired, per UTC day, flushed by the log-processor.
+CREATE TABLE IF NOT EXISTS redaction_rule_hits (
+    rule_id UUID NOT NULL REFERENCES redaction_rules (id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    hits BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (rule_id, day)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS redaction_rule_hits;
+DROP TABLE IF EXISTS redaction_rules;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Per-project sampling and drop rules, evaluated in order at ingest. Rules
+-- are small and always read and written together, so a policy is one row.
+CREATE TABLE IF NOT EXISTS project_sampling_policies (
+    project_id UUID PRIMARY KEY REFERENCES projects (id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL,
+    rules JSONB NOT NULL DEFAULT '[]',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT (now() AT TIME ZONE 'utc')
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_sampling_policies_tenant ON project_sampling_policies (tenant_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS project_sampling_policies;
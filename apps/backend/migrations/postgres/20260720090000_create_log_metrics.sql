@@ -0,0 +1,44 @@
+-- +goose Up
+-- Log-to-metric rules, evaluated by the log-processor on every stored log.
+-- Names are unique per tenant: the metrics of all its projects are exposed
+-- together. The shape of a rule (kind, field, buckets, group_by, filter)
+-- never changes, so stored series always match it.
+CREATE TABLE IF NOT EXISTS log_metric_rules (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    project_id UUID NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+    name VARCHAR(128) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    kind VARCHAR(20) NOT NULL,
+    filter JSONB NOT NULL DEFAULT '{}',
+    field VARCHAR(140) NOT NULL DEFAULT '',
+    buckets DOUBLE PRECISION[] NOT NULL DEFAULT '{}',
+    group_by TEXT[] NOT NULL DEFAULT '{}',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    UNIQUE (tenant_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_log_metric_rules_project ON log_metric_rules (project_id);
+
+-- The series of each rule, as persisted by the log-processor from memory.
+-- labels are the values of the rule's group_by, in order; buckets count
+-- the observations per histogram bucket, not cumulatively.
+CREATE TABLE IF NOT EXISTS log_metric_series (
+    rule_id UUID NOT NULL REFERENCES log_metric_rules (id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL,
+    labels TEXT[] NOT NULL,
+    count BIGINT NOT NULL DEFAULT 0,
+    sum DOUBLE PRECISION NOT NULL DEFAULT 0,
+    buckets BIGINT[] NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    PRIMARY KEY (rule_id, labels)
+);
+
+CREATE INDEX IF NOT EXISTS idx_log_metric_series_tenant ON log_metric_series (tenant_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS log_metric_series;
+DROP TABLE IF EXISTS log_metric_rules;
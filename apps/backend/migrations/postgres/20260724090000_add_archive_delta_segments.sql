@@ -0,0 +1,28 @@
+-- +goose Up
+-- Logs that reach an archived day late are archived as further segments of
+-- the day instead of re-exporting it: the logs exported first may have
+-- expired from ClickHouse since. A job records how far, by ingestion time,
+-- its day has been exported.
+ALTER TABLE archive_segments ADD COLUMN IF NOT EXISTS seq INT NOT NULL DEFAULT 0;
+
+ALTER TABLE archive_segments DROP CONSTRAINT IF EXISTS archive_segments_pkey;
+
+ALTER TABLE archive_segments ADD PRIMARY KEY (tenant_id, day, seq);
+
+ALTER TABLE archive_jobs ADD COLUMN IF NOT EXISTS ingested_through TIMESTAMPTZ;
+
+-- Days archived so far hold everything ingested before their job finished.
+UPDATE archive_jobs
+SET ingested_through = finished_at
+WHERE kind = 'archive' AND status = 'succeeded';
+
+-- +goose Down
+ALTER TABLE archive_jobs DROP COLUMN IF EXISTS ingested_through;
+
+DELETE FROM archive_segments WHERE seq > 0;
+
+ALTER TABLE archive_segments DROP CONSTRAINT IF EXISTS archive_segments_pkey;
+
+ALTER TABLE archive_segments ADD PRIMARY KEY (tenant_id, day);
+
+ALTER TABLE archive_segments DROP COLUMN IF EXISTS seq;
@@ -0,0 +1,36 @@
+// Package flushloop runs the periodic flush of the counters the services
+// accumulate in memory, such as usage, redaction hits and metric series.
+package flushloop
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// finalFlushTimeout bounds the flush made on shutdown.
+const finalFlushTimeout = 5 * time.Second
+
+// Run calls flush every interval until ctx is cancelled, then once more so
+// a clean shutdown loses nothing. Failures are logged as a flush of what,
+// e.g. "usage"; flush is expected to keep what it failed to store.
+func Run(ctx context.Context, interval time.Duration, flush func(context.Context) error, what string, logger *zap.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), finalFlushTimeout)
+			if err := flush(flushCtx); err != nil {
+				logger.Warn("final "+what+" flush failed", zap.Error(err))
+			}
+			cancel()
+			return
+		case <-ticker.C:
+			if err := flush(ctx); err != nil && ctx.Err() == nil {
+				logger.Warn(what+" flush failed", zap.Error(err))
+			}
+		}
+	}
+}
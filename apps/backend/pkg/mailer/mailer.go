@@ -0,0 +1,112 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+var ErrInvalidMessage = errors.New("mailer: message needs a recipient and a subject")
+
+type Config struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	From     string
+}
+
+// Message is a plain-text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New returns an SMTP mailer, or a mailer that only logs messages when no
+// host is configured so local setups work without a mail server.
+func New(cfg Config, logger *zap.Logger) Mailer {
+	if cfg.Host == "" {
+		logger.Warn("smtp host not configured; emails will be logged, not sent")
+		return &logMailer{logger: logger.Named("mailer")}
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+	return &smtpMailer{cfg: cfg}
+}
+
+type smtpMailer struct {
+	cfg Config
+}
+
+// Send delivers through net/smtp, which upgrades to STARTTLS whenever the
+// server offers it. Credentials are only sent when a user is configured.
+func (m *smtpMailer) Send(ctx context.Context, msg Message) error {
+	if msg.To == "" || msg.Subject == "" {
+		return ErrInvalidMessage
+	}
+
+	addr := net.JoinHostPort(m.cfg.Host, strconv.Itoa(m.cfg.Port))
+	var auth smtp.Auth
+	if m.cfg.User != "" {
+		auth = smtp.PlainAuth("", m.cfg.User, m.cfg.Password, m.cfg.Host)
+	}
+
+	// net/smtp has no context support; run it aside so a hung server cannot
+	// outlive the caller's deadline.
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(addr, auth, m.cfg.From, []string{msg.To}, m.build(msg))
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("smtp send: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (m *smtpMailer) build(msg Message) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + m.cfg.From + "\r\n")
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", msg.Subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+type logMailer struct {
+	logger *zap.Logger
+}
+
+func (m *logMailer) Send(_ context.Context, msg Message) error {
+	if msg.To == "" || msg.Subject == "" {
+		return ErrInvalidMessage
+	}
+	m.logger.Info("email not sent (no smtp host)",
+		zap.String("to", msg.To),
+		zap.String("subject", msg.Subject),
+		zap.String("body", msg.Body),
+	)
+	return nil
+}
@@ -0,0 +1,311 @@
+// Package objectstore is a small client for S3-compatible object storage
+// (AWS S3, MinIO). It covers what archival needs: putting, reading and
+// inspecting objects in one bucket, signed with AWS Signature Version 4.
+// Uploads are signed over their SHA-256, so the server rejects a body that
+// was corrupted in transit.
+package objectstore
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotFound = errors.New("objectstore: object not found")
+	ErrConfig   = errors.New("objectstore: endpoint, bucket and credentials are required")
+)
+
+const (
+	// emptySHA256 is the payload hash of requests without a body.
+	emptySHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+	// metaSHA256 keeps the checksum of an object next to it, so it can be
+	// verified without the index.
+	metaSHA256 = "x-amz-meta-sha256"
+
+	maxErrorBody = 4 << 10
+)
+
+type Config struct {
+	// Endpoint is the service URL, e.g. http://minio:9000 or
+	// https://s3.eu-west-1.amazonaws.com. Buckets are addressed path-style.
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// HTTPClient defaults to a client with a 5 minute timeout.
+	HTTPClient *http.Client
+}
+
+// ObjectInfo describes a stored object.
+type ObjectInfo struct {
+	Key  string
+	Size int64
+	// SHA256 is the hex checksum recorded when the object was put; empty
+	// for objects written by other tools.
+	SHA256      string
+	ContentType string
+}
+
+// Client talks to one bucket. It is safe for concurrent use.
+type Client struct {
+	cfg      Config
+	endpoint *url.URL
+	client   *http.Client
+	now      func() time.Time
+}
+
+func New(cfg Config) (*Client, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" || cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return nil, ErrConfig
+	}
+	endpoint, err := url.Parse(strings.TrimRight(cfg.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("objectstore: invalid endpoint %q", cfg.Endpoint)
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Minute}
+	}
+	return &Client{cfg: cfg, endpoint: endpoint, client: client, now: time.Now}, nil
+}
+
+// EnsureBucket creates the bucket unless it exists.
+func (c *Client) EnsureBucket(ctx context.Context) error {
+	resp, err := c.do(ctx, http.MethodHead, "", nil, 0, emptySHA256, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("objectstore: head bucket: %s", resp.Status)
+	}
+
+	resp, err = c.do(ctx, http.MethodPut, "", nil, 0, emptySHA256, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// A concurrent creator wins the race; that is fine.
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusConflict {
+		return responseError("create bucket", resp)
+	}
+	return nil
+}
+
+// Put uploads size bytes of body under key. sha256Hex must be the checksum
+// of the body: the request is signed over it and the server verifies it.
+func (c *Client) Put(ctx context.Context, key string, body io.Reader, size int64, sha256Hex, contentType string) error {
+	header := http.Header{}
+	header.Set(metaSHA256, sha256Hex)
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	resp, err := c.do(ctx, http.MethodPut, key, body, size, sha256Hex, header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return responseError("put "+key, resp)
+	}
+	return nil
+}
+
+// Head returns what is stored under key, or ErrNotFound.
+func (c *Client) Head(ctx context.Context, key string) (*ObjectInfo, error) {
+	resp, err := c.do(ctx, http.MethodHead, key, nil, 0, emptySHA256, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("objectstore: head %s: %s", key, resp.Status)
+	}
+	return objectInfo(key, resp), nil
+}
+
+// Get opens the object stored under key. The caller closes the reader.
+func (c *Client) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	resp, err := c.do(ctx, http.MethodGet, key, nil, 0, emptySHA256, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, nil, responseError("get "+key, resp)
+	}
+	return resp.Body, objectInfo(key, resp), nil
+}
+
+func objectInfo(key string, resp *http.Response) *ObjectInfo {
+	size, _ := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+	return &ObjectInfo{
+		Key:         key,
+		Size:        size,
+		SHA256:      resp.Header.Get(metaSHA256),
+		ContentType: resp.Header.Get("Content-Type"),
+	}
+}
+
+func (c *Client) do(ctx context.Context, method, key string, body io.Reader, size int64, payloadHash string, header http.Header) (*http.Response, error) {
+	u := *c.endpoint
+	u.Path = c.endpoint.Path + "/" + c.cfg.Bucket
+	if key != "" {
+		u.Path += "/" + strings.TrimLeft(key, "/")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	c.sign(req, payloadHash, c.now().UTC())
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("objectstore: %s %s: %w", method, key, err)
+	}
+	return resp, nil
+}
+
+// sign adds an AWS Signature Version 4 Authorization header. The host, range
+// and every x-amz-* header are signed.
+func (c *Client) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		if strings.HasPrefix(name, "x-amz-") || name == "range" || name == "content-type" {
+			headers[name] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		escapePath(req.URL.Path),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + c.cfg.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hashHex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+c.cfg.SecretKey), date)
+	key = hmacSHA256(key, c.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.cfg.AccessKey, scope, signedHeaders, signature,
+	))
+}
+
+// escapePath URI-encodes every path segment as SigV4 requires.
+func escapePath(path string) string {
+	if path == "" {
+		return "/"
+	}
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		segments[i] = escape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+func canonicalQuery(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		values := append([]string(nil), q[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, escape(k)+"="+escape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// escape percent-encodes everything but the unreserved characters.
+func escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if 'A' <= ch && ch <= 'Z' || 'a' <= ch && ch <= 'z' || '0' <= ch && ch <= '9' ||
+			ch == '-' || ch == '_' || ch == '.' || ch == '~' {
+			b.WriteByte(ch)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", ch)
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func hashHex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func responseError(op string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	return fmt.Errorf("objectstore: %s: %s: %s", op, resp.Status, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,94 @@
+package oidc_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/indalyadav56/logify/apps/backend/pkg/oidc"
+	"github.com/indalyadav56/logify/apps/backend/pkg/oidc/oidctest"
+)
+
+func TestCodeFlowWithPKCE(t *testing.T) {
+	idp := oidctest.New(t)
+	idp.SignIn(map[string]any{
+		"sub":            "user-1",
+		"email":          "jane@example.com",
+		"email_verified": true,
+		"groups":         []string{"logify-admins", "staff"},
+	})
+	p := oidc.New(idp.Config())
+	ctx := context.Background()
+
+	authURL, err := p.AuthCodeURL(ctx, "state-1", "nonce-1", "verifier-1")
+	require.NoError(t, err)
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+	assert.Equal(t, oidc.Challenge("verifier-1"), u.Query().Get("code_challenge"))
+	assert.Contains(t, u.Query().Get("scope"), "openid")
+
+	code, state := idp.Authorize(authURL)
+	assert.Equal(t, "state-1", state)
+
+	// The provider refuses a code presented with the wrong verifier, and a
+	// code is only good once.
+	_, err = p.Exchange(ctx, code, "verifier-2")
+	assert.ErrorIs(t, err, oidc.ErrExchange)
+
+	code, _ = idp.Authorize(authURL)
+	tok, err := p.Exchange(ctx, code, "verifier-1")
+	require.NoError(t, err)
+
+	_, err = p.Verify(ctx, tok.IDToken, "other-nonce")
+	assert.ErrorIs(t, err, oidc.ErrNonceMismatch)
+
+	id, err := p.Verify(ctx, tok.IDToken, "nonce-1")
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", id.Subject)
+	assert.Equal(t, idp.URL, id.Issuer)
+	assert.Equal(t, "jane@example.com", id.Email)
+	assert.True(t, id.EmailVerified)
+	assert.Equal(t, []string{"logify-admins", "staff"}, id.Strings("groups"))
+}
+
+func TestVerifyRejectsForeignTokens(t *testing.T) {
+	idp := oidctest.New(t)
+	p := oidc.New(idp.Config())
+	ctx := context.Background()
+	now := time.Now()
+
+	valid := map[string]any{
+		"iss": idp.URL, "aud": oidctest.ClientID, "sub": "user-1",
+		"iat": now.Unix(), "exp": now.Add(time.Minute).Unix(),
+	}
+	with := func(k string, v any) map[string]any {
+		c := map[string]any{}
+		for key, val := range valid {
+			c[key] = val
+		}
+		c[k] = v
+		return c
+	}
+
+	_, err := p.Verify(ctx, idp.IDToken(valid), "")
+	require.NoError(t, err)
+
+	for name, claims := range map[string]map[string]any{
+		"issuer":   with("iss", "https://evil.example.com"),
+		"audience": with("aud", "someone-else"),
+		"expired":  with("exp", now.Add(-time.Hour).Unix()),
+		"azp":      with("aud", []string{oidctest.ClientID, "someone-else"}),
+	} {
+		_, err := p.Verify(ctx, idp.IDToken(claims), "")
+		assert.ErrorIs(t, err, oidc.ErrInvalidToken, name)
+	}
+
+	// Signed by a different provider's key.
+	other := oidctest.New(t)
+	_, err = p.Verify(ctx, other.IDToken(valid), "")
+	assert.ErrorIs(t, err, oidc.ErrInvalidToken)
+}
@@ -0,0 +1,224 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// clockSkew is the leeway allowed on exp, iat and nbf.
+const clockSkew = time.Minute
+
+// IDToken holds the verified claims of an ID token.
+type IDToken struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	// Claims is the full claim set, for provider-specific claims such as
+	// groups.
+	Claims map[string]any
+}
+
+// Strings returns a claim holding a string or a list of strings, as the
+// groups claim of most providers does. Anything else yields nil.
+func (t *IDToken) Strings(claim string) []string {
+	switch v := t.Claims[claim].(type) {
+	case string:
+		return []string{v}
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// Verify checks the signature of a raw ID token against the provider's keys
+// and validates issuer, audience, expiry and nonce.
+func (p *Provider) Verify(ctx context.Context, raw, nonce string) (*IDToken, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(raw, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.key(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}),
+		jwt.WithIssuer(p.cfg.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(clockSkew),
+		jwt.WithTimeFunc(p.now),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	// With several audiences the token must have been issued to us.
+	if aud, _ := claims.GetAudience(); len(aud) > 1 {
+		if azp, _ := claims["azp"].(string); azp != p.cfg.ClientID {
+			return nil, fmt.Errorf("%w: authorized party %q", ErrInvalidToken, azp)
+		}
+	}
+	if got, _ := claims["nonce"].(string); got != nonce {
+		return nil, ErrNonceMismatch
+	}
+
+	tok := &IDToken{Claims: claims}
+	tok.Issuer, _ = claims.GetIssuer()
+	tok.Subject, _ = claims.GetSubject()
+	if tok.Subject == "" {
+		return nil, fmt.Errorf("%w: missing sub", ErrInvalidToken)
+	}
+	tok.Email, _ = claims["email"].(string)
+	tok.Name, _ = claims["name"].(string)
+	switch v := claims["email_verified"].(type) {
+	case bool:
+		tok.EmailVerified = v
+	case string:
+		// Some providers send the flag as a string.
+		tok.EmailVerified = v == "true"
+	}
+	return tok, nil
+}
+
+// key returns the verification key with the given id, refetching the JWKS
+// when the cache is stale or the id is unknown (the provider rotated keys).
+func (p *Provider) key(ctx context.Context, kid string) (any, error) {
+	p.mu.Lock()
+	keys, fresh := p.keys, p.now().Sub(p.keysAt) < p.cfg.CacheTTL
+	canRefresh := p.now().Sub(p.keysAt) >= jwksMinRefresh
+	p.mu.Unlock()
+
+	if k, ok := lookupKey(keys, kid); ok && fresh {
+		return k, nil
+	}
+	if keys != nil && !canRefresh {
+		if k, ok := lookupKey(keys, kid); ok {
+			return k, nil
+		}
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+
+	meta, err := p.metadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var set jwks
+	if err := p.getJSON(ctx, meta.JWKSURI, &set); err != nil {
+		if k, ok := lookupKey(keys, kid); ok {
+			return k, nil
+		}
+		return nil, fmt.Errorf("fetch jwks: %w", err)
+	}
+	keys = set.parse()
+
+	p.mu.Lock()
+	p.keys, p.keysAt = keys, p.now()
+	p.mu.Unlock()
+
+	if k, ok := lookupKey(keys, kid); ok {
+		return k, nil
+	}
+	return nil, fmt.Errorf("unknown key id %q", kid)
+}
+
+// lookupKey finds a key by id; a token without kid is accepted only when the
+// set holds exactly one key.
+func lookupKey(keys map[string]any, kid string) (any, bool) {
+	if kid == "" && len(keys) == 1 {
+		for _, k := range keys {
+			return k, true
+		}
+	}
+	k, ok := keys[kid]
+	return k, ok
+}
+
+type jwks struct {
+	Keys []jwk `json:"keys"`
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parse keeps the signing keys it understands and skips the rest.
+func (s jwks) parse() map[string]any {
+	keys := make(map[string]any, len(s.Keys))
+	for _, k := range s.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		if pub, err := k.publicKey(); err == nil {
+			keys[k.Kid] = pub
+		}
+	}
+	return keys
+}
+
+func (k jwk) publicKey() (any, error) {
+	dec := base64.RawURLEncoding
+	switch k.Kty {
+	case "RSA":
+		n, err := dec.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := dec.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		exp := new(big.Int).SetBytes(e)
+		if !exp.IsInt64() || exp.Int64() < 3 {
+			return nil, errors.New("bad rsa exponent")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := dec.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := dec.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		pub := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, errors.New("ec point not on curve")
+		}
+		return pub, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
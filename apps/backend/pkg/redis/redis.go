@@ -3,15 +3,21 @@ package cache
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
 type Config struct {
-	Host     string
-	Port     int
-	Password string
-	DB       int
+	Host         string
+	Port         int
+	Password     string
+	DB           int
+	DialTimeout  time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	PoolSize     int
+	MinIdleConns int
 }
 
 func DefaultConfig() Config {
@@ -23,19 +29,32 @@ func DefaultConfig() Config {
 	}
 }
 
+func (c Config) Addr() string {
+	return fmt.Sprintf("%s:%d", c.Host, c.Port)
+}
+
 func NewRedisClient(cfg ...Config) (*redis.Client, error) {
 	if len(cfg) == 0 {
 		cfg = []Config{DefaultConfig()}
 	}
+	c := cfg[0]
 
+	// Zero durations and sizes fall back to the go-redis defaults.
 	client := redis.NewClient(&redis.Options{
-		// Addr:     cfg.Addr(),
-		// Password: cfg.Password,
-		// DB:       cfg.DB,
+		Addr:         c.Addr(),
+		Password:     c.Password,
+		DB:           c.DB,
+		DialTimeout:  c.DialTimeout,
+		ReadTimeout:  c.ReadTimeout,
+		WriteTimeout: c.WriteTimeout,
+		PoolSize:     c.PoolSize,
+		MinIdleConns: c.MinIdleConns,
 	})
 
-	ctx := context.Background()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
 		return nil, fmt.Errorf("failed to connect to redis: %w", err)
 	}
 
@@ -0,0 +1,59 @@
+// Package secretbox encrypts small secrets at rest with AES-256-GCM. Sealed
+// values carry their nonce, so a Box only needs the key to open them.
+package secretbox
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+)
+
+// ErrOpen is returned for values that were tampered with or sealed under
+// another key.
+var ErrOpen = errors.New("secretbox: cannot open value")
+
+type Box struct {
+	aead cipher.AEAD
+}
+
+// New returns a Box keyed by the SHA-256 of passphrase, so any non-empty
+// configuration string can serve as the key.
+func New(passphrase string) (*Box, error) {
+	if passphrase == "" {
+		return nil, errors.New("secretbox: key is required")
+	}
+	key := sha256.Sum256([]byte(passphrase))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Box{aead: aead}, nil
+}
+
+// Seal encrypts plain and returns nonce || ciphertext.
+func (b *Box) Seal(plain []byte) ([]byte, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return b.aead.Seal(nonce, nonce, plain, nil), nil
+}
+
+// Open reverses Seal.
+func (b *Box) Open(sealed []byte) ([]byte, error) {
+	n := b.aead.NonceSize()
+	if len(sealed) < n {
+		return nil, ErrOpen
+	}
+	plain, err := b.aead.Open(nil, sealed[:n], sealed[n:], nil)
+	if err != nil {
+		return nil, ErrOpen
+	}
+	return plain, nil
+}
@@ -0,0 +1,114 @@
+// Package ttlcache is the in-process cache the services keep what they load
+// on hot paths in: values expire ttl after they are set, and a full cache is
+// simply reset, which caps memory without bookkeeping.
+//
+// Callers pass the time in, so the services that own a clock keep control of
+// expiry in their tests.
+package ttlcache
+
+import (
+	"sync"
+	"time"
+)
+
+type entry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// Cache maps keys to values that expire. It is safe for concurrent use.
+type Cache[K comparable, V any] struct {
+	ttl time.Duration
+	max int
+
+	mu      sync.RWMutex
+	entries map[K]entry[V]
+	gen     uint64
+}
+
+// New returns a cache whose values expire ttl after they are set and which
+// is reset once it holds max entries. A max of zero does not cap it.
+func New[K comparable, V any](ttl time.Duration, max int) *Cache[K, V] {
+	return &Cache[K, V]{ttl: ttl, max: max, entries: make(map[K]entry[V])}
+}
+
+// Get returns the value of key and whether it is set and unexpired at now.
+func (c *Cache[K, V]) Get(key K, now time.Time) (V, bool) {
+	c.mu.RLock()
+	e, ok := c.entries[key]
+	c.mu.RUnlock()
+	if !ok || !now.Before(e.expiresAt) {
+		var zero V
+		return zero, false
+	}
+	return e.value, true
+}
+
+// Stale returns the value of key even if it has expired, for callers that
+// prefer an old value to none when reloading fails.
+func (c *Cache[K, V]) Stale(key K) (V, bool) {
+	c.mu.RLock()
+	e, ok := c.entries[key]
+	c.mu.RUnlock()
+	return e.value, ok
+}
+
+// Set stores v for key until ttl after now.
+func (c *Cache[K, V]) Set(key K, v V, now time.Time) {
+	c.mu.Lock()
+	c.set(key, v, now)
+	c.mu.Unlock()
+}
+
+// Generation counts the deletions so far. A caller that reads it before
+// loading a value can store the value with SetIfGeneration, so a load that
+// raced with an invalidation does not write its stale result back.
+func (c *Cache[K, V]) Generation() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.gen
+}
+
+// SetIfGeneration is Set, unless anything was deleted since gen.
+func (c *Cache[K, V]) SetIfGeneration(gen uint64, key K, v V, now time.Time) {
+	c.mu.Lock()
+	if c.gen == gen {
+		c.set(key, v, now)
+	}
+	c.mu.Unlock()
+}
+
+func (c *Cache[K, V]) set(key K, v V, now time.Time) {
+	if c.max > 0 && len(c.entries) >= c.max {
+		c.entries = make(map[K]entry[V])
+	}
+	c.entries[key] = entry[V]{value: v, expiresAt: now.Add(c.ttl)}
+}
+
+// Delete drops the value of key.
+func (c *Cache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	delete(c.entries, key)
+	c.gen++
+	c.mu.Unlock()
+}
+
+// DeleteFunc drops the values of the keys del reports true for.
+func (c *Cache[K, V]) DeleteFunc(del func(K) bool) {
+	c.mu.Lock()
+	for k := range c.entries {
+		if del(k) {
+			delete(c.entries, k)
+		}
+	}
+	c.gen++
+	c.mu.Unlock()
+}
+
+// Clear drops every value.
+func (c *Cache[K, V]) Clear() {
+	c.mu.Lock()
+	c.entries = make(map[K]entry[V])
+	c.gen++
+	c.mu.Unlock()
+}
@@ -0,0 +1,51 @@
+package ttlcache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache(t *testing.T) {
+	now := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	c := New[string, int](time.Minute, 2)
+
+	c.Set("a", 1, now)
+	v, ok := c.Get("a", now.Add(59*time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+
+	_, ok = c.Get("a", now.Add(time.Minute))
+	assert.False(t, ok, "expired")
+	v, ok = c.Stale("a")
+	assert.True(t, ok, "expired values are kept for Stale")
+	assert.Equal(t, 1, v)
+
+	c.Set("b", 2, now)
+	c.Set("c", 3, now)
+	_, ok = c.Stale("a")
+	assert.False(t, ok, "a full cache is reset")
+	_, ok = c.Get("c", now)
+	assert.True(t, ok)
+}
+
+func TestCacheSetIfGeneration(t *testing.T) {
+	now := time.Now()
+	c := New[string, int](time.Minute, 0)
+
+	gen := c.Generation()
+	c.Delete("a") // an invalidation racing with a load
+	c.SetIfGeneration(gen, "a", 1, now)
+	_, ok := c.Get("a", now)
+	assert.False(t, ok, "a load older than an invalidation is not stored")
+
+	gen = c.Generation()
+	c.SetIfGeneration(gen, "a", 1, now)
+	_, ok = c.Get("a", now)
+	assert.True(t, ok)
+
+	c.DeleteFunc(func(k string) bool { return k == "a" })
+	_, ok = c.Stale("a")
+	assert.False(t, ok)
+}
@@ -22,7 +22,7 @@ type Client struct {
 	endpoint string
 
 	// async machinery (nil/zero when async is disabled)
-	queue chan Entry
+	queue chan queued
 	wg    sync.WaitGroup
 
 	// mu guards closed and serialises enqueue against Close so we never send
@@ -69,7 +69,7 @@ func New(opts ...Option) (*Client, error) {
 	}
 
 	if cfg.async {
-		c.queue = make(chan Entry, cfg.bufferSize)
+		c.queue = make(chan queued, cfg.bufferSize)
 		for i := 0; i < cfg.workers; i++ {
 			c.wg.Add(1)
 			go c.worker()
@@ -85,13 +85,34 @@ func New(opts ...Option) (*Client, error) {
 // In async mode it blocks until the entry is buffered (or ctx is done) and
 // returns nil; delivery happens in the background.
 func (c *Client) Send(ctx context.Context, entry Entry) error {
+	return c.SendWithAck(ctx, entry, nil)
+}
+
+// AckFunc receives the outcome of delivering one entry: nil once the backend
+// accepted it, otherwise the error that made the attempt fail.
+type AckFunc func(err error)
+
+// SendWithAck is Send, reporting the outcome of the delivery to ack, which
+// may be nil. When SendWithAck returns an error the entry was not sent and
+// ack is not called; otherwise ack is called exactly once: before
+// SendWithAck returns in synchronous mode, from a worker in async mode.
+//
+// It lets callers that checkpoint their own progress, such as a log
+// shipper, only move past an entry once it is delivered.
+func (c *Client) SendWithAck(ctx context.Context, entry Entry, ack AckFunc) error {
 	entry = c.applyDefaults(entry)
 	if entry.Level == "" {
 		return fmt.Errorf("logify: entry level is required")
 	}
 
 	if !c.cfg.async {
-		return c.send(ctx, entry)
+		if err := c.send(ctx, entry); err != nil {
+			return err
+		}
+		if ack != nil {
+			ack(nil)
+		}
+		return nil
 	}
 
 	c.mu.RLock()
@@ -100,7 +121,7 @@ func (c *Client) Send(ctx context.Context, entry Entry) error {
 		return ErrClosed
 	}
 	select {
-	case c.queue <- entry:
+	case c.queue <- queued{entry: entry, ack: ack}:
 		return nil
 	case <-ctx.Done():
 		return ctx.Err()
@@ -124,7 +145,7 @@ func (c *Client) TrySend(entry Entry) error {
 		return ErrClosed
 	}
 	select {
-	case c.queue <- entry:
+	case c.queue <- queued{entry: entry}:
 		return nil
 	default:
 		return ErrBufferFull
@@ -238,17 +259,26 @@ func (c *Client) applyDefaults(e Entry) Entry {
 	return e
 }
 
+// queued is a buffered entry and who to tell how its delivery went.
+type queued struct {
+	entry Entry
+	ack   AckFunc
+}
+
 // worker consumes buffered entries until the queue is closed and drained.
 func (c *Client) worker() {
 	defer c.wg.Done()
-	for entry := range c.queue {
+	for q := range c.queue {
 		// Per-send timeout independent of any request context, since the
 		// original caller has long returned.
 		ctx, cancel := context.WithTimeout(context.Background(), c.cfg.httpClient.Timeout)
-		err := c.send(ctx, entry)
+		err := c.send(ctx, q.entry)
 		cancel()
 		if err != nil && c.cfg.onError != nil {
-			c.cfg.onError(entry, err)
+			c.cfg.onError(q.entry, err)
+		}
+		if q.ack != nil {
+			q.ack(err)
 		}
 	}
 }
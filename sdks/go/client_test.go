@@ -150,6 +150,61 @@ func TestAsyncDeliveryAndClose(t *testing.T) {
 	}
 }
 
+func TestSendWithAckReportsDelivery(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Every other request fails.
+		if atomic.AddInt32(&calls, 1)%2 == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	for _, async := range []bool{false, true} {
+		atomic.StoreInt32(&calls, 0)
+		c, err := New(WithBaseURL(srv.URL), WithAsync(async), WithWorkers(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var (
+			mu     sync.Mutex
+			acked  int
+			failed int
+		)
+		ack := func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed++
+				return
+			}
+			acked++
+		}
+		for i := 0; i < 4; i++ {
+			// Synchronous failures are returned instead of acknowledged.
+			if err := c.SendWithAck(context.Background(), Entry{Level: LevelInfo}, ack); err != nil && async {
+				t.Fatalf("async SendWithAck: %v", err)
+			}
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := c.Close(ctx); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+		cancel()
+
+		wantFailed := 0
+		if async {
+			wantFailed = 2
+		}
+		if acked != 2 || failed != wantFailed {
+			t.Errorf("async=%v: acked %d, failed %d; want 2 and %d", async, acked, failed, wantFailed)
+		}
+	}
+}
+
 func TestTrySendBufferFull(t *testing.T) {
 	block := make(chan struct{})
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {